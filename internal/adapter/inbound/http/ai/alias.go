@@ -0,0 +1,204 @@
+package ai
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/uniedit/server/internal/domain/ai"
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// AliasHandler implements inbound.AIModelAliasHttpPort.
+type AliasHandler struct {
+	domain ai.AIDomain
+}
+
+// NewAliasHandler creates a new alias handler.
+func NewAliasHandler(domain ai.AIDomain) *AliasHandler {
+	return &AliasHandler{domain: domain}
+}
+
+// ListAliases handles GET /admin/ai/aliases.
+func (h *AliasHandler) ListAliases(c *gin.Context) {
+	aliases, err := h.domain.ListAliases(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"object": "list",
+		"data":   aliases,
+	})
+}
+
+// GetAlias handles GET /admin/ai/aliases/:id.
+func (h *AliasHandler) GetAlias(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "alias id required"})
+		return
+	}
+
+	alias, err := h.domain.GetAlias(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, alias)
+}
+
+// CreateAliasRequest represents an alias creation request.
+type CreateAliasRequest struct {
+	ID                 string     `json:"id" binding:"required"`
+	Description        string     `json:"description,omitempty"`
+	TargetModelID      string     `json:"target_model_id,omitempty"`
+	TargetGroupID      string     `json:"target_group_id,omitempty"`
+	NextTargetModelID  string     `json:"next_target_model_id,omitempty"`
+	NextTargetGroupID  string     `json:"next_target_group_id,omitempty"`
+	CutoverAt          *time.Time `json:"cutover_at,omitempty"`
+	Deprecated         bool       `json:"deprecated"`
+	DeprecationMessage string     `json:"deprecation_message,omitempty"`
+	SunsetAt           *time.Time `json:"sunset_at,omitempty"`
+	Enabled            *bool      `json:"enabled,omitempty"`
+}
+
+// CreateAlias handles POST /admin/ai/aliases.
+func (h *AliasHandler) CreateAlias(c *gin.Context) {
+	var req CreateAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alias := &model.AIModelAlias{
+		ID:                 req.ID,
+		Description:        req.Description,
+		TargetModelID:      req.TargetModelID,
+		TargetGroupID:      req.TargetGroupID,
+		NextTargetModelID:  req.NextTargetModelID,
+		NextTargetGroupID:  req.NextTargetGroupID,
+		CutoverAt:          req.CutoverAt,
+		Deprecated:         req.Deprecated,
+		DeprecationMessage: req.DeprecationMessage,
+		SunsetAt:           req.SunsetAt,
+		Enabled:            true,
+	}
+	if req.Enabled != nil {
+		alias.Enabled = *req.Enabled
+	}
+
+	if err := h.domain.CreateAlias(c.Request.Context(), alias); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, alias)
+}
+
+// UpdateAliasRequest represents an alias update request.
+// Setting a target clears the other kind of target.
+type UpdateAliasRequest struct {
+	Description        *string    `json:"description,omitempty"`
+	TargetModelID      *string    `json:"target_model_id,omitempty"`
+	TargetGroupID      *string    `json:"target_group_id,omitempty"`
+	NextTargetModelID  *string    `json:"next_target_model_id,omitempty"`
+	NextTargetGroupID  *string    `json:"next_target_group_id,omitempty"`
+	CutoverAt          *time.Time `json:"cutover_at,omitempty"`
+	CancelCutover      bool       `json:"cancel_cutover,omitempty"`
+	Deprecated         *bool      `json:"deprecated,omitempty"`
+	DeprecationMessage *string    `json:"deprecation_message,omitempty"`
+	SunsetAt           *time.Time `json:"sunset_at,omitempty"`
+	Enabled            *bool      `json:"enabled,omitempty"`
+}
+
+// UpdateAlias handles PUT /admin/ai/aliases/:id.
+func (h *AliasHandler) UpdateAlias(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "alias id required"})
+		return
+	}
+
+	var req UpdateAliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	alias, err := h.domain.GetAlias(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	// Apply updates
+	if req.Description != nil {
+		alias.Description = *req.Description
+	}
+	if req.TargetModelID != nil {
+		alias.TargetModelID = *req.TargetModelID
+		alias.TargetGroupID = ""
+	}
+	if req.TargetGroupID != nil {
+		alias.TargetGroupID = *req.TargetGroupID
+		alias.TargetModelID = ""
+	}
+	if req.NextTargetModelID != nil {
+		alias.NextTargetModelID = *req.NextTargetModelID
+		alias.NextTargetGroupID = ""
+	}
+	if req.NextTargetGroupID != nil {
+		alias.NextTargetGroupID = *req.NextTargetGroupID
+		alias.NextTargetModelID = ""
+	}
+	if req.CutoverAt != nil {
+		alias.CutoverAt = req.CutoverAt
+	}
+	if req.CancelCutover {
+		alias.CutoverAt = nil
+		alias.NextTargetModelID = ""
+		alias.NextTargetGroupID = ""
+	}
+	if req.Deprecated != nil {
+		alias.Deprecated = *req.Deprecated
+	}
+	if req.DeprecationMessage != nil {
+		alias.DeprecationMessage = *req.DeprecationMessage
+	}
+	if req.SunsetAt != nil {
+		alias.SunsetAt = req.SunsetAt
+	}
+	if req.Enabled != nil {
+		alias.Enabled = *req.Enabled
+	}
+
+	if err := h.domain.UpdateAlias(c.Request.Context(), alias); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, alias)
+}
+
+// DeleteAlias handles DELETE /admin/ai/aliases/:id.
+func (h *AliasHandler) DeleteAlias(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "alias id required"})
+		return
+	}
+
+	if err := h.domain.DeleteAlias(c.Request.Context(), id); err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "alias deleted"})
+}
+
+// Compile-time interface check
+var _ inbound.AIModelAliasHttpPort = (*AliasHandler)(nil)
@@ -37,6 +37,7 @@ func (h *ChatHandler) Chat(c *gin.Context) {
 		return
 	}
 	req.UserID = userID
+	setRequestMeta(c, &req)
 
 	// Validate request
 	if len(req.Messages) == 0 {
@@ -50,6 +51,7 @@ func (h *ChatHandler) Chat(c *gin.Context) {
 		handleError(c, err)
 		return
 	}
+	setDeprecationHeaders(c, resp.Routing)
 
 	c.JSON(http.StatusOK, resp)
 }
@@ -69,6 +71,7 @@ func (h *ChatHandler) ChatStream(c *gin.Context) {
 		return
 	}
 	req.UserID = userID
+	setRequestMeta(c, &req)
 
 	// Validate request
 	if len(req.Messages) == 0 {
@@ -87,6 +90,7 @@ func (h *ChatHandler) ChatStream(c *gin.Context) {
 	}
 
 	// Set SSE headers
+	setDeprecationHeaders(c, routingInfo)
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
 	c.Header("Connection", "keep-alive")
@@ -99,14 +103,19 @@ func (h *ChatHandler) ChatStream(c *gin.Context) {
 		c.Writer.Flush()
 	}
 
-	// Stream chunks
+	// Stream chunks; thinking deltas get their own event type so clients
+	// that only read the default event never see them.
 	for chunk := range chunks {
 		data, err := json.Marshal(chunk)
 		if err != nil {
 			continue
 		}
 
-		fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+		if chunk.Delta != nil && chunk.Delta.Reasoning != "" {
+			fmt.Fprintf(c.Writer, "event: thinking\ndata: %s\n\n", data)
+		} else {
+			fmt.Fprintf(c.Writer, "data: %s\n\n", data)
+		}
 		c.Writer.Flush()
 
 		// Check if client disconnected
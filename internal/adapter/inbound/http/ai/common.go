@@ -2,12 +2,15 @@ package ai
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	aiDomain "github.com/uniedit/server/internal/domain/ai"
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/utils/middleware"
 )
 
 // Common errors
@@ -39,6 +42,39 @@ func getUserID(c *gin.Context) (uuid.UUID, error) {
 	return uuid.Nil, ErrUnauthorized
 }
 
+// setRequestMeta copies request ID and API key ID from context onto a chat request.
+func setRequestMeta(c *gin.Context, req *model.AIChatRequest) {
+	req.RequestID = middleware.GetRequestID(c)
+
+	if keyID, exists := c.Get("api_key_id"); exists {
+		switch id := keyID.(type) {
+		case uuid.UUID:
+			req.APIKeyID = &id
+		case string:
+			if parsed, err := uuid.Parse(id); err == nil {
+				req.APIKeyID = &parsed
+			}
+		}
+	}
+}
+
+// setDeprecationHeaders reports alias deprecations and pending cut-overs
+// using the Deprecation, Sunset and Warning response headers.
+func setDeprecationHeaders(c *gin.Context, info *model.AIRoutingInfo) {
+	if info == nil || info.Deprecation == nil {
+		return
+	}
+
+	notice := info.Deprecation
+	if notice.Deprecated {
+		c.Header("Deprecation", "true")
+	}
+	if notice.SunsetAt != nil {
+		c.Header("Sunset", notice.SunsetAt.UTC().Format(http.TimeFormat))
+	}
+	c.Header("Warning", fmt.Sprintf("299 - %q", notice.Message))
+}
+
 // handleError handles errors and returns appropriate HTTP response.
 func handleError(c *gin.Context, err error) {
 	if err == nil {
@@ -50,18 +86,32 @@ func handleError(c *gin.Context, err error) {
 	case errors.Is(err, aiDomain.ErrProviderNotFound),
 		errors.Is(err, aiDomain.ErrModelNotFound),
 		errors.Is(err, aiDomain.ErrAccountNotFound),
-		errors.Is(err, aiDomain.ErrGroupNotFound):
+		errors.Is(err, aiDomain.ErrGroupNotFound),
+		errors.Is(err, aiDomain.ErrRequestLogNotFound),
+		errors.Is(err, aiDomain.ErrAliasNotFound):
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	case errors.Is(err, aiDomain.ErrInvalidRequest),
 		errors.Is(err, aiDomain.ErrEmptyMessages),
-		errors.Is(err, aiDomain.ErrEmptyInput):
+		errors.Is(err, aiDomain.ErrEmptyInput),
+		errors.Is(err, aiDomain.ErrInvalidAliasTarget),
+		errors.Is(err, aiDomain.ErrInvalidReasoningConfig),
+		errors.Is(err, aiDomain.ErrInvalidImage),
+		errors.Is(err, aiDomain.ErrUnsupportedImageType),
+		errors.Is(err, aiDomain.ErrImageFetchFailed):
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	case errors.Is(err, aiDomain.ErrRateLimitExceeded),
 		errors.Is(err, aiDomain.ErrQuotaExceeded):
 		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
 		return
+	case errors.Is(err, aiDomain.ErrImageTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, aiDomain.ErrRequestLoggingDisabled),
+		errors.Is(err, aiDomain.ErrAliasConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
 	case errors.Is(err, aiDomain.ErrNoAvailableModels),
 		errors.Is(err, aiDomain.ErrProviderUnhealthy),
 		errors.Is(err, aiDomain.ErrAccountUnhealthy):
@@ -62,8 +62,12 @@ type CreateModelRequest struct {
 	MaxOutputTokens int                  `json:"max_output_tokens,omitempty"`
 	InputCostPer1K  float64              `json:"input_cost_per_1k,omitempty"`
 	OutputCostPer1K float64              `json:"output_cost_per_1k,omitempty"`
-	Options         map[string]any       `json:"options,omitempty"`
-	Enabled         bool                 `json:"enabled"`
+	// Prompt cache pricing; zero bills cached tokens at the input rate.
+	CachedInputCostPer1K float64        `json:"cached_input_cost_per_1k,omitempty"`
+	CacheWriteCostPer1K  float64        `json:"cache_write_cost_per_1k,omitempty"`
+	ReasoningCostPer1K   float64        `json:"reasoning_cost_per_1k,omitempty"`
+	Options              map[string]any `json:"options,omitempty"`
+	Enabled              bool           `json:"enabled"`
 }
 
 // CreateModel handles POST /admin/ai/models.
@@ -81,16 +85,19 @@ func (h *ModelAdminHandler) CreateModel(c *gin.Context) {
 	}
 
 	m := &model.AIModel{
-		ID:              req.ID,
-		ProviderID:      req.ProviderID,
-		Name:            req.Name,
-		Capabilities:    caps,
-		ContextWindow:   req.ContextWindow,
-		MaxOutputTokens: req.MaxOutputTokens,
-		InputCostPer1K:  req.InputCostPer1K,
-		OutputCostPer1K: req.OutputCostPer1K,
-		Options:         req.Options,
-		Enabled:         req.Enabled,
+		ID:                   req.ID,
+		ProviderID:           req.ProviderID,
+		Name:                 req.Name,
+		Capabilities:         caps,
+		ContextWindow:        req.ContextWindow,
+		MaxOutputTokens:      req.MaxOutputTokens,
+		InputCostPer1K:       req.InputCostPer1K,
+		OutputCostPer1K:      req.OutputCostPer1K,
+		CachedInputCostPer1K: req.CachedInputCostPer1K,
+		CacheWriteCostPer1K:  req.CacheWriteCostPer1K,
+		ReasoningCostPer1K:   req.ReasoningCostPer1K,
+		Options:              req.Options,
+		Enabled:              req.Enabled,
 	}
 
 	if err := h.domain.CreateModel(c.Request.Context(), m); err != nil {
@@ -103,14 +110,17 @@ func (h *ModelAdminHandler) CreateModel(c *gin.Context) {
 
 // UpdateModelRequest represents a model update request.
 type UpdateModelRequest struct {
-	Name            *string               `json:"name,omitempty"`
-	Capabilities    *[]model.AICapability `json:"capabilities,omitempty"`
-	ContextWindow   *int                  `json:"context_window,omitempty"`
-	MaxOutputTokens *int                  `json:"max_output_tokens,omitempty"`
-	InputCostPer1K  *float64              `json:"input_cost_per_1k,omitempty"`
-	OutputCostPer1K *float64              `json:"output_cost_per_1k,omitempty"`
-	Options         *map[string]any       `json:"options,omitempty"`
-	Enabled         *bool                 `json:"enabled,omitempty"`
+	Name                 *string               `json:"name,omitempty"`
+	Capabilities         *[]model.AICapability `json:"capabilities,omitempty"`
+	ContextWindow        *int                  `json:"context_window,omitempty"`
+	MaxOutputTokens      *int                  `json:"max_output_tokens,omitempty"`
+	InputCostPer1K       *float64              `json:"input_cost_per_1k,omitempty"`
+	OutputCostPer1K      *float64              `json:"output_cost_per_1k,omitempty"`
+	CachedInputCostPer1K *float64              `json:"cached_input_cost_per_1k,omitempty"`
+	CacheWriteCostPer1K  *float64              `json:"cache_write_cost_per_1k,omitempty"`
+	ReasoningCostPer1K   *float64              `json:"reasoning_cost_per_1k,omitempty"`
+	Options              *map[string]any       `json:"options,omitempty"`
+	Enabled              *bool                 `json:"enabled,omitempty"`
 }
 
 // UpdateModel handles PUT /admin/ai/models/:id.
@@ -156,6 +166,15 @@ func (h *ModelAdminHandler) UpdateModel(c *gin.Context) {
 	if req.OutputCostPer1K != nil {
 		m.OutputCostPer1K = *req.OutputCostPer1K
 	}
+	if req.CachedInputCostPer1K != nil {
+		m.CachedInputCostPer1K = *req.CachedInputCostPer1K
+	}
+	if req.CacheWriteCostPer1K != nil {
+		m.CacheWriteCostPer1K = *req.CacheWriteCostPer1K
+	}
+	if req.ReasoningCostPer1K != nil {
+		m.ReasoningCostPer1K = *req.ReasoningCostPer1K
+	}
 	if req.Options != nil {
 		m.Options = *req.Options
 	}
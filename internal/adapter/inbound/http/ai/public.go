@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/uniedit/server/internal/domain/ai"
+	"github.com/uniedit/server/internal/model"
 	"github.com/uniedit/server/internal/port/inbound"
 )
 
@@ -20,11 +21,31 @@ func NewPublicHandler(domain ai.AIDomain) *PublicHandler {
 }
 
 // ModelObject represents a model in OpenAI-compatible format.
+// Aliases carry the extra alias_for and deprecation fields.
 type ModelObject struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	OwnedBy string `json:"owned_by"`
+	ID          string                     `json:"id"`
+	Object      string                     `json:"object"`
+	Created     int64                      `json:"created"`
+	OwnedBy     string                     `json:"owned_by"`
+	AliasFor    string                     `json:"alias_for,omitempty"`
+	Deprecation *model.AIDeprecationNotice `json:"deprecation,omitempty"`
+}
+
+// newAliasObject converts an alias to its model object representation.
+func newAliasObject(alias *model.AIModelAlias, now time.Time) *ModelObject {
+	modelID, groupID := alias.Resolve(now)
+	target := modelID
+	if target == "" {
+		target = groupID
+	}
+	return &ModelObject{
+		ID:          alias.ID,
+		Object:      "model",
+		Created:     alias.CreatedAt.Unix(),
+		OwnedBy:     "uniedit",
+		AliasFor:    target,
+		Deprecation: alias.Notice(now),
+	}
 }
 
 // ModelsResponse represents the list models response (OpenAI compatible).
@@ -41,14 +62,25 @@ func (h *PublicHandler) ListModels(c *gin.Context) {
 		return
 	}
 
-	data := make([]*ModelObject, len(models))
-	for i, m := range models {
-		data[i] = &ModelObject{
+	aliases, err := h.domain.ListEnabledAliases(c.Request.Context())
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	data := make([]*ModelObject, 0, len(models)+len(aliases))
+	for _, m := range models {
+		data = append(data, &ModelObject{
 			ID:      m.ID,
 			Object:  "model",
 			Created: m.CreatedAt.Unix(),
 			OwnedBy: "uniedit",
-		}
+		})
+	}
+
+	now := time.Now()
+	for _, a := range aliases {
+		data = append(data, newAliasObject(a, now))
 	}
 
 	c.JSON(http.StatusOK, &ModelsResponse{
@@ -71,6 +103,16 @@ func (h *PublicHandler) GetModel(c *gin.Context) {
 		return
 	}
 
+	if m == nil {
+		alias, err := h.domain.GetAlias(c.Request.Context(), id)
+		if err != nil || !alias.Enabled {
+			c.JSON(http.StatusNotFound, gin.H{"error": "model not found"})
+			return
+		}
+		c.JSON(http.StatusOK, newAliasObject(alias, time.Now()))
+		return
+	}
+
 	if !m.Enabled {
 		c.JSON(http.StatusNotFound, gin.H{"error": "model not found"})
 		return
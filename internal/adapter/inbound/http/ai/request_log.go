@@ -0,0 +1,132 @@
+package ai
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/uniedit/server/internal/domain/ai"
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// RequestLogHandler implements inbound.AIRequestLogHttpPort.
+type RequestLogHandler struct {
+	domain ai.AIDomain
+}
+
+// NewRequestLogHandler creates a new request log handler.
+func NewRequestLogHandler(domain ai.AIDomain) *RequestLogHandler {
+	return &RequestLogHandler{domain: domain}
+}
+
+// ListRequestLogsQuery represents request log search parameters.
+type ListRequestLogsQuery struct {
+	Pagination
+	RequestID string     `form:"request_id"`
+	UserID    string     `form:"user_id"`
+	APIKeyID  string     `form:"api_key_id"`
+	Model     string     `form:"model"`
+	Since     *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until     *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+}
+
+// ListRequestLogs handles GET /admin/ai/request-logs.
+func (h *RequestLogHandler) ListRequestLogs(c *gin.Context) {
+	var query ListRequestLogsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter := &model.AIRequestLogFilter{
+		RequestID: query.RequestID,
+		ModelID:   query.Model,
+		Since:     query.Since,
+		Until:     query.Until,
+		Offset:    query.GetOffset(),
+		Limit:     query.GetLimit(),
+	}
+	if query.UserID != "" {
+		id, err := uuid.Parse(query.UserID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return
+		}
+		filter.UserID = &id
+	}
+	if query.APIKeyID != "" {
+		id, err := uuid.Parse(query.APIKeyID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid api_key_id"})
+			return
+		}
+		filter.APIKeyID = &id
+	}
+
+	logs, total, err := h.domain.SearchRequestLogs(c.Request.Context(), filter)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, NewPagedResponse(logs, query.Page, query.PageSize, total))
+}
+
+// GetRequestLog handles GET /admin/ai/request-logs/:id.
+func (h *RequestLogHandler) GetRequestLog(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request log id"})
+		return
+	}
+
+	entry, err := h.domain.GetRequestLog(c.Request.Context(), id)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// ReplayRequestBody represents a replay request.
+type ReplayRequestBody struct {
+	// Model to replay against. Defaults to the originally used model.
+	Model string `json:"model"`
+}
+
+// ReplayRequest handles POST /admin/ai/request-logs/:id/replay.
+func (h *RequestLogHandler) ReplayRequest(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request log id"})
+		return
+	}
+
+	adminID, err := getUserID(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var body ReplayRequestBody
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	result, err := h.domain.ReplayRequest(c.Request.Context(), adminID, id, body.Model)
+	if err != nil {
+		handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// Compile-time check
+var _ inbound.AIRequestLogHttpPort = (*RequestLogHandler)(nil)
@@ -59,6 +59,7 @@ func (h *SubscriptionHandler) ListPlans(c *gin.Context) {
 			"monthly_embedding_tokens": plan.MonthlyEmbeddingTokens,
 			"git_storage_mb":           plan.GitStorageMB,
 			"lfs_storage_mb":           plan.LFSStorageMB,
+			"media_storage_mb":         plan.MediaStorageMB,
 			"max_team_members":         plan.MaxTeamMembers,
 		}
 	}
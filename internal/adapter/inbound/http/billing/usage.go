@@ -75,9 +75,12 @@ func (h *UsageHandler) RecordUsage(c *gin.Context) {
 		ModelID      string    `json:"model_id" binding:"required"`
 		InputTokens  int       `json:"input_tokens"`
 		OutputTokens int       `json:"output_tokens"`
-		CostUSD      float64   `json:"cost_usd"`
-		LatencyMs    int       `json:"latency_ms"`
-		Success      bool      `json:"success"`
+		// Prompt cache breakdown of input_tokens
+		CacheReadTokens  int     `json:"cache_read_tokens"`
+		CacheWriteTokens int     `json:"cache_write_tokens"`
+		CostUSD          float64 `json:"cost_usd"`
+		LatencyMs        int     `json:"latency_ms"`
+		Success          bool    `json:"success"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -85,15 +88,17 @@ func (h *UsageHandler) RecordUsage(c *gin.Context) {
 	}
 
 	input := &billing.RecordUsageInput{
-		RequestID:    req.RequestID,
-		TaskType:     req.TaskType,
-		ProviderID:   req.ProviderID,
-		ModelID:      req.ModelID,
-		InputTokens:  req.InputTokens,
-		OutputTokens: req.OutputTokens,
-		CostUSD:      req.CostUSD,
-		LatencyMs:    req.LatencyMs,
-		Success:      req.Success,
+		RequestID:        req.RequestID,
+		TaskType:         req.TaskType,
+		ProviderID:       req.ProviderID,
+		ModelID:          req.ModelID,
+		InputTokens:      req.InputTokens,
+		OutputTokens:     req.OutputTokens,
+		CacheReadTokens:  req.CacheReadTokens,
+		CacheWriteTokens: req.CacheWriteTokens,
+		CostUSD:          req.CostUSD,
+		LatencyMs:        req.LatencyMs,
+		Success:          req.Success,
 	}
 
 	if err := h.billingDomain.RecordUsage(c.Request.Context(), userID, input); err != nil {
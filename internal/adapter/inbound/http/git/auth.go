@@ -0,0 +1,81 @@
+package githttp
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// basicAuthRealm is the Basic auth realm sent to git and git-lfs clients.
+const basicAuthRealm = "UniEdit Git"
+
+// errorWriter writes an error response in the format the client expects.
+type errorWriter func(c *gin.Context, status int, message string)
+
+// repoAuth authorizes git and git-lfs clients. They address a repository
+// by owner and slug in the URL and authenticate with HTTP Basic.
+type repoAuth struct {
+	domain      inbound.GitDomain
+	credentials inbound.GitCredentialValidator
+}
+
+// authorize resolves the repository from the URL and checks that the
+// caller has the required permission on it. The returned user is nil for
+// anonymous callers. On failure the response has already been written.
+func (a *repoAuth) authorize(c *gin.Context, required model.GitPermission, fail errorWriter) (*model.GitRepo, *uuid.UUID, bool) {
+	ctx := c.Request.Context()
+
+	ownerID, err := uuid.Parse(c.Param("owner"))
+	if err != nil {
+		fail(c, http.StatusNotFound, "repository not found")
+		return nil, nil, false
+	}
+
+	slug := strings.TrimSuffix(c.Param("repo"), ".git")
+	repo, err := a.domain.GetRepoByOwnerAndSlug(ctx, ownerID, slug)
+	if err != nil {
+		if err.Error() == "repository not found" {
+			fail(c, http.StatusNotFound, "repository not found")
+		} else {
+			fail(c, http.StatusInternalServerError, "internal server error")
+		}
+		return nil, nil, false
+	}
+
+	var userID *uuid.UUID
+	if username, password, ok := c.Request.BasicAuth(); ok {
+		id, err := a.credentials.ValidateGitCredentials(ctx, username, password)
+		if err != nil {
+			requireAuth(c, fail)
+			return nil, nil, false
+		}
+		userID = &id
+	}
+
+	allowed, err := a.domain.CanAccess(ctx, repo.ID, userID, required)
+	if err != nil {
+		fail(c, http.StatusInternalServerError, "internal server error")
+		return nil, nil, false
+	}
+	if !allowed {
+		if userID == nil {
+			requireAuth(c, fail)
+		} else {
+			fail(c, http.StatusForbidden, "access denied")
+		}
+		return nil, nil, false
+	}
+
+	return repo, userID, true
+}
+
+// requireAuth asks the client for credentials.
+func requireAuth(c *gin.Context, fail errorWriter) {
+	c.Header("WWW-Authenticate", `Basic realm="`+basicAuthRealm+`"`)
+	fail(c, http.StatusUnauthorized, "authentication required")
+}
@@ -0,0 +1,123 @@
+package githttp
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// BranchProtectionRequest represents a create or replace branch protection
+// request. Pattern is a branch name or a glob such as release/*.
+type BranchProtectionRequest struct {
+	Pattern              string `json:"pattern" binding:"required,max=255"`
+	BlockForcePush       bool   `json:"block_force_push"`
+	BlockDeletion        bool   `json:"block_deletion"`
+	RequirePullRequest   bool   `json:"require_pull_request"`
+	RequiredApprovals    int    `json:"required_approvals" binding:"min=0,max=10"`
+	RestrictPushToAdmins bool   `json:"restrict_push_to_admins"`
+}
+
+// CreateBranchProtection adds a branch protection rule.
+// POST /repos/:id/branch-protections
+func (h *Handler) CreateBranchProtection(c *gin.Context) {
+	repoID, userID, ok := browseParams(c)
+	if !ok {
+		return
+	}
+
+	var req BranchProtectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.domain.CreateBranchProtection(c.Request.Context(), repoID, userID, toBranchProtectionInput(req))
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// ListBranchProtections lists the branch protection rules of a repository.
+// GET /repos/:id/branch-protections
+func (h *Handler) ListBranchProtections(c *gin.Context) {
+	repoID, userID, ok := browseParams(c)
+	if !ok {
+		return
+	}
+
+	rules, err := h.domain.ListBranchProtections(c.Request.Context(), repoID, userID)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"branch_protections": rules})
+}
+
+// UpdateBranchProtection replaces a branch protection rule.
+// PUT /repos/:id/branch-protections/:rule_id
+func (h *Handler) UpdateBranchProtection(c *gin.Context) {
+	repoID, userID, ok := browseParams(c)
+	if !ok {
+		return
+	}
+
+	ruleID, err := uuid.Parse(c.Param("rule_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule ID"})
+		return
+	}
+
+	var req BranchProtectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := h.domain.UpdateBranchProtection(c.Request.Context(), repoID, ruleID, userID, toBranchProtectionInput(req))
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteBranchProtection removes a branch protection rule.
+// DELETE /repos/:id/branch-protections/:rule_id
+func (h *Handler) DeleteBranchProtection(c *gin.Context) {
+	repoID, userID, ok := browseParams(c)
+	if !ok {
+		return
+	}
+
+	ruleID, err := uuid.Parse(c.Param("rule_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid rule ID"})
+		return
+	}
+
+	if err := h.domain.DeleteBranchProtection(c.Request.Context(), repoID, ruleID, userID); err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func toBranchProtectionInput(req BranchProtectionRequest) *inbound.GitBranchProtectionInput {
+	return &inbound.GitBranchProtectionInput{
+		Pattern:              req.Pattern,
+		BlockForcePush:       req.BlockForcePush,
+		BlockDeletion:        req.BlockDeletion,
+		RequirePullRequest:   req.RequirePullRequest,
+		RequiredApprovals:    req.RequiredApprovals,
+		RestrictPushToAdmins: req.RestrictPushToAdmins,
+	}
+}
@@ -0,0 +1,180 @@
+package githttp
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// ===== Browsing Handlers =====
+
+// ListBranches lists the branches of a repository.
+// GET /repos/:id/branches
+func (h *Handler) ListBranches(c *gin.Context) {
+	h.listRefs(c, model.GitRefTypeBranch)
+}
+
+// ListTags lists the tags of a repository.
+// GET /repos/:id/tags
+func (h *Handler) ListTags(c *gin.Context) {
+	h.listRefs(c, model.GitRefTypeTag)
+}
+
+func (h *Handler) listRefs(c *gin.Context, refType model.GitRefType) {
+	repoID, userID, ok := browseParams(c)
+	if !ok {
+		return
+	}
+
+	refs, err := h.domain.ListRefs(c.Request.Context(), repoID, userID, refType)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refs": refs})
+}
+
+// GetTree lists a directory at a ref.
+// GET /repos/:id/tree?ref=main&path=src
+func (h *Handler) GetTree(c *gin.Context) {
+	repoID, userID, ok := browseParams(c)
+	if !ok {
+		return
+	}
+
+	tree, err := h.domain.GetTree(c.Request.Context(), repoID, userID, c.Query("ref"), c.Query("path"))
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
+// GetBlob streams the raw content of a file at a ref. Files tracked by LFS
+// are served from LFS storage.
+// GET /repos/:id/blob?ref=main&path=README.md
+func (h *Handler) GetBlob(c *gin.Context) {
+	repoID, userID, ok := browseParams(c)
+	if !ok {
+		return
+	}
+
+	path := c.Query("path")
+	if path == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path is required"})
+		return
+	}
+
+	blob, err := h.domain.GetBlob(c.Request.Context(), repoID, userID, c.Query("ref"), path)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+	defer blob.Content.Close()
+
+	headers := map[string]string{"X-Git-Blob-SHA": blob.SHA}
+	if blob.LFSOID != "" {
+		headers["X-Git-LFS-OID"] = blob.LFSOID
+	}
+	c.DataFromReader(http.StatusOK, blob.Size, "application/octet-stream", blob.Content, headers)
+}
+
+// ListCommits lists the history of a ref, optionally limited to a path.
+// GET /repos/:id/commits?ref=main&path=src&page=1&page_size=30
+func (h *Handler) ListCommits(c *gin.Context) {
+	repoID, userID, ok := browseParams(c)
+	if !ok {
+		return
+	}
+
+	filter := &inbound.GitCommitFilter{
+		Ref:      c.Query("ref"),
+		Path:     c.Query("path"),
+		Page:     1,
+		PageSize: 30,
+	}
+	if p, err := strconv.Atoi(c.Query("page")); err == nil && p > 0 {
+		filter.Page = p
+	}
+	if ps, err := strconv.Atoi(c.Query("page_size")); err == nil && ps > 0 && ps <= 100 {
+		filter.PageSize = ps
+	}
+
+	commits, hasMore, err := h.domain.ListCommits(c.Request.Context(), repoID, userID, filter)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"commits":   commits,
+		"page":      filter.Page,
+		"page_size": filter.PageSize,
+		"has_more":  hasMore,
+	})
+}
+
+// GetCommit returns a commit and the files it changed.
+// GET /repos/:id/commits/:sha
+func (h *Handler) GetCommit(c *gin.Context) {
+	repoID, userID, ok := browseParams(c)
+	if !ok {
+		return
+	}
+
+	commit, err := h.domain.GetCommit(c.Request.Context(), repoID, userID, c.Param("sha"))
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, commit)
+}
+
+// Compare returns the unified diff between two refs.
+// GET /repos/:id/compare?base=main&head=feature
+func (h *Handler) Compare(c *gin.Context) {
+	repoID, userID, ok := browseParams(c)
+	if !ok {
+		return
+	}
+
+	base, head := c.Query("base"), c.Query("head")
+	if base == "" || head == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "base and head are required"})
+		return
+	}
+
+	diff, err := h.domain.Diff(c.Request.Context(), repoID, userID, base, head)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// browseParams parses the repository ID and the current user of a
+// browsing request, writing the error response if either is missing.
+func browseParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	repoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid repository ID"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return repoID, userID, true
+}
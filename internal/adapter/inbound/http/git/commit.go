@@ -0,0 +1,93 @@
+package githttp
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// maxCommitRequestBytes bounds a commit request body. Files above the LFS
+// threshold are moved to LFS storage by the domain.
+const maxCommitRequestBytes = 128 << 20
+
+// CommitFilesRequest represents a commit files request.
+type CommitFilesRequest struct {
+	Branch            string                 `json:"branch" binding:"max=255"`
+	ExpectedParentSHA string                 `json:"expected_parent_sha" binding:"omitempty,len=40,hexadecimal"`
+	Message           string                 `json:"message" binding:"required,max=10000"`
+	Operations        []FileOperationRequest `json:"operations" binding:"required,min=1,max=1000,dive"`
+}
+
+// FileOperationRequest represents one file change of a commit request.
+type FileOperationRequest struct {
+	Action   string  `json:"action" binding:"required,oneof=create update delete move"`
+	Path     string  `json:"path" binding:"required,max=4096"`
+	FromPath string  `json:"from_path" binding:"required_if=Action move,max=4096"`
+	Content  *string `json:"content"`
+	Encoding string  `json:"encoding" binding:"omitempty,oneof=utf-8 base64"`
+}
+
+// CommitFiles writes a commit from a set of file operations.
+// POST /repos/:id/commits
+func (h *Handler) CommitFiles(c *gin.Context) {
+	repoID, userID, ok := browseParams(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxCommitRequestBytes)
+	var req CommitFilesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ops := make([]*inbound.GitFileOperation, len(req.Operations))
+	for i, op := range req.Operations {
+		content, err := decodeContent(op)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid base64 content for " + op.Path})
+			return
+		}
+		ops[i] = &inbound.GitFileOperation{
+			Action:   model.GitFileAction(op.Action),
+			Path:     op.Path,
+			FromPath: op.FromPath,
+			Content:  content,
+		}
+	}
+
+	email := getUserEmail(c)
+	input := &inbound.GitCommitFilesInput{
+		Branch:            req.Branch,
+		ExpectedParentSHA: req.ExpectedParentSHA,
+		Message:           req.Message,
+		AuthorName:        email,
+		AuthorEmail:       email,
+		Operations:        ops,
+	}
+
+	commit, err := h.domain.CommitFiles(c.Request.Context(), repoID, userID, input)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, commit)
+}
+
+// decodeContent returns the content of an operation. Operations without
+// content return nil, so a move keeps the file as it is.
+func decodeContent(op FileOperationRequest) ([]byte, error) {
+	if op.Content == nil {
+		return nil, nil
+	}
+	if op.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(*op.Content)
+	}
+	return []byte(*op.Content), nil
+}
@@ -1,6 +1,7 @@
 package githttp
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -44,6 +45,7 @@ func (h *Handler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerF
 		repos.GET("/:id", h.GetRepo)
 		repos.PUT("/:id", h.UpdateRepo)
 		repos.DELETE("/:id", h.DeleteRepo)
+		repos.POST("/:id/fork", h.ForkRepo)
 
 		// Collaborators
 		repos.POST("/:id/collaborators", h.AddCollaborator)
@@ -51,13 +53,41 @@ func (h *Handler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerF
 		repos.PUT("/:id/collaborators/:user_id", h.UpdateCollaborator)
 		repos.DELETE("/:id/collaborators/:user_id", h.RemoveCollaborator)
 
+		// Browsing
+		repos.GET("/:id/branches", h.ListBranches)
+		repos.GET("/:id/tags", h.ListTags)
+		repos.GET("/:id/tree", h.GetTree)
+		repos.GET("/:id/blob", h.GetBlob)
+		repos.GET("/:id/commits", h.ListCommits)
+		repos.POST("/:id/commits", h.CommitFiles)
+		repos.GET("/:id/commits/:sha", h.GetCommit)
+		repos.GET("/:id/compare", h.Compare)
+
+		// Branch protection
+		repos.GET("/:id/branch-protections", h.ListBranchProtections)
+		repos.POST("/:id/branch-protections", h.CreateBranchProtection)
+		repos.PUT("/:id/branch-protections/:rule_id", h.UpdateBranchProtection)
+		repos.DELETE("/:id/branch-protections/:rule_id", h.DeleteBranchProtection)
+
 		// Pull requests
 		repos.POST("/:id/pulls", h.CreatePR)
 		repos.GET("/:id/pulls", h.ListPRs)
 		repos.GET("/:id/pulls/:number", h.GetPR)
 		repos.PUT("/:id/pulls/:number", h.UpdatePR)
+		repos.GET("/:id/pulls/:number/mergeability", h.CheckMergeability)
 		repos.POST("/:id/pulls/:number/merge", h.MergePR)
 
+		// Reviews
+		repos.POST("/:id/pulls/:number/comments", h.CreatePRComment)
+		repos.GET("/:id/pulls/:number/comments", h.ListPRComments)
+		repos.PUT("/:id/pulls/:number/comments/:comment_id", h.UpdatePRComment)
+		repos.DELETE("/:id/pulls/:number/comments/:comment_id", h.DeletePRComment)
+		repos.POST("/:id/pulls/:number/reviews", h.SubmitReview)
+		repos.GET("/:id/pulls/:number/reviews", h.ListReviews)
+		repos.POST("/:id/pulls/:number/requested_reviewers", h.RequestReviewers)
+		repos.GET("/:id/pulls/:number/requested_reviewers", h.ListReviewRequests)
+		repos.DELETE("/:id/pulls/:number/requested_reviewers/:user_id", h.RemoveReviewRequest)
+
 		// Storage stats
 		repos.GET("/:id/storage", h.GetStorageStats)
 	}
@@ -172,6 +202,8 @@ type UpdateRepoRequest struct {
 	Visibility    string `json:"visibility" binding:"omitempty,oneof=public private"`
 	DefaultBranch string `json:"default_branch" binding:"omitempty,min=1,max=255"`
 	LFSEnabled    *bool  `json:"lfs_enabled"`
+	// Approvals needed before a pull request can be merged
+	RequiredApprovals *int `json:"required_approvals" binding:"omitempty,min=0,max=10"`
 }
 
 // UpdateRepo updates a repository.
@@ -195,11 +227,12 @@ func (h *Handler) UpdateRepo(c *gin.Context) {
 	}
 
 	input := &inbound.GitUpdateRepoInput{
-		Name:          req.Name,
-		Description:   req.Description,
-		Visibility:    model.GitVisibility(req.Visibility),
-		DefaultBranch: req.DefaultBranch,
-		LFSEnabled:    req.LFSEnabled,
+		Name:              req.Name,
+		Description:       req.Description,
+		Visibility:        model.GitVisibility(req.Visibility),
+		DefaultBranch:     req.DefaultBranch,
+		LFSEnabled:        req.LFSEnabled,
+		RequiredApprovals: req.RequiredApprovals,
 	}
 
 	repo, err := h.domain.UpdateRepo(c.Request.Context(), id, userID, input)
@@ -233,6 +266,46 @@ func (h *Handler) DeleteRepo(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// ForkRepoRequest represents a fork repository request.
+type ForkRepoRequest struct {
+	Name   string     `json:"name" binding:"max=255"`
+	TeamID *uuid.UUID `json:"team_id"`
+}
+
+// ForkRepo forks a repository into the user's account or one of their teams.
+func (h *Handler) ForkRepo(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid repository ID"})
+		return
+	}
+
+	var req ForkRepoRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	repo, err := h.domain.ForkRepo(c.Request.Context(), id, userID, &inbound.GitForkRepoInput{
+		Name:   req.Name,
+		TeamID: req.TeamID,
+	})
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, toRepoResponse(repo, h.baseURL))
+}
+
 // ===== Collaborator Handlers =====
 
 // AddCollaboratorRequest represents an add collaborator request.
@@ -354,10 +427,11 @@ func (h *Handler) RemoveCollaborator(c *gin.Context) {
 
 // CreatePRRequest represents a create pull request request.
 type CreatePRRequest struct {
-	Title        string `json:"title" binding:"required,min=1,max=255"`
-	Description  string `json:"description" binding:"max=10000"`
-	SourceBranch string `json:"source_branch" binding:"required,min=1,max=255"`
-	TargetBranch string `json:"target_branch" binding:"required,min=1,max=255"`
+	Title        string     `json:"title" binding:"required,min=1,max=255"`
+	Description  string     `json:"description" binding:"max=10000"`
+	SourceRepoID *uuid.UUID `json:"source_repo_id"` // a fork to merge from
+	SourceBranch string     `json:"source_branch" binding:"required,min=1,max=255"`
+	TargetBranch string     `json:"target_branch" binding:"required,min=1,max=255"`
 }
 
 // CreatePR creates a new pull request.
@@ -383,6 +457,7 @@ func (h *Handler) CreatePR(c *gin.Context) {
 	input := &inbound.GitCreatePRInput{
 		Title:        req.Title,
 		Description:  req.Description,
+		SourceRepoID: req.SourceRepoID,
 		SourceBranch: req.SourceBranch,
 		TargetBranch: req.TargetBranch,
 	}
@@ -496,6 +571,13 @@ func (h *Handler) UpdatePR(c *gin.Context) {
 	c.JSON(http.StatusOK, pr)
 }
 
+// MergePRRequest represents a merge pull request request.
+type MergePRRequest struct {
+	Strategy          string `json:"strategy" binding:"omitempty,oneof=merge squash fast-forward"`
+	CommitMessage     string `json:"commit_message" binding:"max=10000"`
+	ExpectedTargetSHA string `json:"expected_target_sha" binding:"omitempty,len=40,hexadecimal"`
+}
+
 // MergePR merges a pull request.
 func (h *Handler) MergePR(c *gin.Context) {
 	repoID, err := uuid.Parse(c.Param("id"))
@@ -510,14 +592,37 @@ func (h *Handler) MergePR(c *gin.Context) {
 		return
 	}
 
+	// The body is optional; an empty one merges with a merge commit
+	var req MergePRRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	userID := getUserID(c)
 	if userID == uuid.Nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
 		return
 	}
 
-	pr, err := h.domain.MergePR(c.Request.Context(), repoID, number, userID)
+	email := getUserEmail(c)
+	input := &inbound.GitMergePRInput{
+		Strategy:          model.GitMergeStrategy(req.Strategy),
+		CommitMessage:     req.CommitMessage,
+		ExpectedTargetSHA: req.ExpectedTargetSHA,
+		AuthorName:        email,
+		AuthorEmail:       email,
+	}
+
+	pr, err := h.domain.MergePR(c.Request.Context(), repoID, number, userID, input)
 	if err != nil {
+		var conflict interface{ ConflictPaths() []string }
+		if errors.As(err, &conflict) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "conflicts": conflict.ConflictPaths()})
+			return
+		}
 		handleGitError(c, err)
 		return
 	}
@@ -525,6 +630,36 @@ func (h *Handler) MergePR(c *gin.Context) {
 	c.JSON(http.StatusOK, pr)
 }
 
+// CheckMergeability reports whether a pull request can be merged and which
+// paths conflict.
+func (h *Handler) CheckMergeability(c *gin.Context) {
+	repoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid repository ID"})
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pull request number"})
+		return
+	}
+
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	result, err := h.domain.CheckMergeability(c.Request.Context(), repoID, number, userID)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, toMergeabilityResponse(result))
+}
+
 // ===== Storage Handlers =====
 
 // GetStorageStats gets storage statistics for a repository.
@@ -555,6 +690,15 @@ func getUserID(c *gin.Context) uuid.UUID {
 	return uuid.Nil
 }
 
+func getUserEmail(c *gin.Context) string {
+	if email, exists := c.Get("email"); exists {
+		if e, ok := email.(string); ok {
+			return e
+		}
+	}
+	return ""
+}
+
 func parseRepoFilter(c *gin.Context) *inbound.GitRepoFilter {
 	filter := &inbound.GitRepoFilter{
 		Search:   c.Query("search"),
@@ -585,15 +729,29 @@ func parseRepoFilter(c *gin.Context) *inbound.GitRepoFilter {
 
 func handleGitError(c *gin.Context, err error) {
 	switch err.Error() {
-	case "repository not found", "pull request not found":
+	case "repository not found", "pull request not found", "branch not found",
+		"ref not found", "path not found", "commit not found", "LFS object not found",
+		"comment not found", "branch protection not found":
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-	case "access denied", "not repository owner", "not a collaborator":
+	case "access denied", "not repository owner", "not a collaborator", "not a team admin",
+		"not the comment author", "protected branch is restricted to admins", "protected branch requires a pull request",
+		"cannot force-push to a protected branch", "cannot delete a protected branch":
 		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
-	case "repository already exists", "invalid repository name", "source and target branches are the same":
+	case "repository already exists", "invalid repository name", "source and target branches are the same",
+		"invalid merge strategy", "path is not a directory", "path is not a file", "invalid branch",
+		"commit has no changes", "commit message is required", "invalid file path", "invalid file action",
+		"source repository is not a fork of this repository", "invalid review state", "cannot review your own pull request",
+		"reviewer must have write access", "comment body is required", "invalid comment line", "invalid required approvals",
+		"invalid branch pattern", "branch protection already exists":
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	case "storage quota exceeded":
 		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
-	case "pull request is already merged", "pull request is already closed":
+	case "file size exceeds maximum allowed":
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case "pull request is already merged", "pull request is already closed",
+		"pull request has merge conflicts", "fast-forward merge is not possible", "source branch has no new commits",
+		"branches have no common history", "target branch has changed", "file already exists",
+		"pull request needs more approvals", "changes have been requested":
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
@@ -602,52 +760,100 @@ func handleGitError(c *gin.Context, err error) {
 
 // RepoResponse represents a repository response.
 type RepoResponse struct {
-	ID            uuid.UUID `json:"id"`
-	OwnerID       uuid.UUID `json:"owner_id"`
-	Name          string    `json:"name"`
-	Slug          string    `json:"slug"`
-	RepoType      string    `json:"repo_type"`
-	Visibility    string    `json:"visibility"`
-	Description   string    `json:"description,omitempty"`
-	DefaultBranch string    `json:"default_branch"`
-	SizeBytes     int64     `json:"size_bytes"`
-	LFSEnabled    bool      `json:"lfs_enabled"`
-	LFSSizeBytes  int64     `json:"lfs_size_bytes"`
-	TotalSize     int64     `json:"total_size"`
-	StarsCount    int       `json:"stars_count"`
-	ForksCount    int       `json:"forks_count"`
-	CloneURL      string    `json:"clone_url,omitempty"`
-	LFSURL        string    `json:"lfs_url,omitempty"`
+	ID                uuid.UUID  `json:"id"`
+	OwnerID           uuid.UUID  `json:"owner_id"`
+	TeamID            *uuid.UUID `json:"team_id,omitempty"`
+	Name              string     `json:"name"`
+	Slug              string     `json:"slug"`
+	RepoType          string     `json:"repo_type"`
+	Visibility        string     `json:"visibility"`
+	Description       string     `json:"description,omitempty"`
+	DefaultBranch     string     `json:"default_branch"`
+	SizeBytes         int64      `json:"size_bytes"`
+	LFSEnabled        bool       `json:"lfs_enabled"`
+	LFSSizeBytes      int64      `json:"lfs_size_bytes"`
+	TotalSize         int64      `json:"total_size"`
+	StarsCount        int        `json:"stars_count"`
+	ForksCount        int        `json:"forks_count"`
+	ForkedFrom        *uuid.UUID `json:"forked_from,omitempty"`
+	RequiredApprovals int        `json:"required_approvals"`
+	CloneURL          string     `json:"clone_url,omitempty"`
+	LFSURL            string     `json:"lfs_url,omitempty"`
 }
 
 func toRepoResponse(repo *model.GitRepo, baseURL string) *RepoResponse {
 	resp := &RepoResponse{
-		ID:            repo.ID,
-		OwnerID:       repo.OwnerID,
-		Name:          repo.Name,
-		Slug:          repo.Slug,
-		RepoType:      string(repo.RepoType),
-		Visibility:    string(repo.Visibility),
-		Description:   repo.Description,
-		DefaultBranch: repo.DefaultBranch,
-		SizeBytes:     repo.SizeBytes,
-		LFSEnabled:    repo.LFSEnabled,
-		LFSSizeBytes:  repo.LFSSizeBytes,
-		TotalSize:     repo.TotalSize(),
-		StarsCount:    repo.StarsCount,
-		ForksCount:    repo.ForksCount,
+		ID:                repo.ID,
+		OwnerID:           repo.OwnerID,
+		TeamID:            repo.TeamID,
+		Name:              repo.Name,
+		Slug:              repo.Slug,
+		RepoType:          string(repo.RepoType),
+		Visibility:        string(repo.Visibility),
+		Description:       repo.Description,
+		DefaultBranch:     repo.DefaultBranch,
+		SizeBytes:         repo.SizeBytes,
+		LFSEnabled:        repo.LFSEnabled,
+		LFSSizeBytes:      repo.LFSSizeBytes,
+		TotalSize:         repo.TotalSize(),
+		StarsCount:        repo.StarsCount,
+		ForksCount:        repo.ForksCount,
+		ForkedFrom:        repo.ForkedFrom,
+		RequiredApprovals: repo.RequiredApprovals,
 	}
 
 	if baseURL != "" {
-		resp.CloneURL = baseURL + "/git/" + repo.OwnerID.String() + "/" + repo.Slug + ".git"
+		resp.CloneURL = cloneURL(baseURL, repo)
 		if repo.LFSEnabled {
-			resp.LFSURL = baseURL + "/lfs/" + repo.OwnerID.String() + "/" + repo.Slug
+			resp.LFSURL = lfsURL(baseURL, repo)
 		}
 	}
 
 	return resp
 }
 
+// cloneURL returns the smart HTTP URL of a repository.
+func cloneURL(baseURL string, repo *model.GitRepo) string {
+	return baseURL + "/git/" + repo.OwnerID.String() + "/" + repo.Slug + ".git"
+}
+
+// lfsURL returns the LFS API URL of a repository, which git-lfs derives
+// from the clone URL.
+func lfsURL(baseURL string, repo *model.GitRepo) string {
+	return cloneURL(baseURL, repo) + "/info/lfs"
+}
+
+// MergeabilityResponse represents a pull request mergeability response.
+type MergeabilityResponse struct {
+	Mergeable         bool     `json:"mergeable"`
+	CanFastForward    bool     `json:"can_fast_forward"`
+	Conflicts         []string `json:"conflicts"`
+	BaseSHA           string   `json:"base_sha"`
+	SourceSHA         string   `json:"source_sha"`
+	TargetSHA         string   `json:"target_sha"`
+	Approvals         int      `json:"approvals"`
+	RequiredApprovals int      `json:"required_approvals"`
+	ChangesRequested  bool     `json:"changes_requested"`
+}
+
+func toMergeabilityResponse(result *inbound.GitMergeability) *MergeabilityResponse {
+	conflicts := result.Conflicts
+	if conflicts == nil {
+		conflicts = []string{}
+	}
+	return &MergeabilityResponse{
+		Mergeable:         result.Mergeable,
+		CanFastForward:    result.CanFastForward,
+		Conflicts:         conflicts,
+		BaseSHA:           result.BaseSHA,
+		SourceSHA:         result.SourceSHA,
+		TargetSHA:         result.TargetSHA,
+		Approvals:         result.Approvals,
+		RequiredApprovals: result.RequiredApprovals,
+		ChangesRequested:  result.ChangesRequested,
+	}
+}
+
 func toRepoResponses(repos []*model.GitRepo, baseURL string) []*RepoResponse {
 	result := make([]*RepoResponse, len(repos))
 	for i, repo := range repos {
@@ -0,0 +1,497 @@
+package githttp
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// lfsMediaType is the content type of Git LFS API requests and responses.
+const lfsMediaType = "application/vnd.git-lfs+json"
+
+// oidPattern matches a SHA-256 LFS object ID.
+var oidPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// LFSHandler serves the Git LFS API of hosted repositories: the batch API,
+// the basic transfer adapter and file locking.
+type LFSHandler struct {
+	repoAuth
+	lfsDomain  inbound.GitLFSDomain
+	lockDomain inbound.GitLFSLockDomain
+	baseURL    string
+}
+
+// NewLFSHandler creates a new Git LFS handler.
+func NewLFSHandler(
+	domain inbound.GitDomain,
+	lfsDomain inbound.GitLFSDomain,
+	lockDomain inbound.GitLFSLockDomain,
+	credentials inbound.GitCredentialValidator,
+	baseURL string,
+) *LFSHandler {
+	return &LFSHandler{
+		repoAuth:   repoAuth{domain: domain, credentials: credentials},
+		lfsDomain:  lfsDomain,
+		lockDomain: lockDomain,
+		baseURL:    baseURL,
+	}
+}
+
+// RegisterRoutes registers the LFS routes below the clone URL, where
+// git-lfs looks for them. Like the smart HTTP routes they use HTTP Basic.
+func (h *LFSHandler) RegisterRoutes(r gin.IRouter) {
+	lfs := r.Group("/git/:owner/:repo/info/lfs")
+	{
+		lfs.POST("/objects/batch", h.Batch)
+		lfs.PUT("/objects/:oid", h.UploadObject)
+		lfs.GET("/objects/:oid", h.DownloadObject)
+		lfs.POST("/objects/:oid/verify", h.VerifyObject)
+
+		lfs.POST("/locks", h.CreateLock)
+		lfs.GET("/locks", h.ListLocks)
+		lfs.POST("/locks/verify", h.VerifyLocks)
+		lfs.POST("/locks/:id/unlock", h.DeleteLock)
+	}
+}
+
+// ===== Object Handlers =====
+
+// Batch returns the transfer actions for a set of objects.
+// POST /git/:owner/:repo/info/lfs/objects/batch
+func (h *LFSHandler) Batch(c *gin.Context) {
+	var req model.GitLFSBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		writeLFSError(c, http.StatusUnprocessableEntity, "invalid batch request")
+		return
+	}
+
+	var required model.GitPermission
+	switch req.Operation {
+	case "download":
+		required = model.GitPermissionRead
+	case "upload":
+		required = model.GitPermissionWrite
+	default:
+		writeLFSError(c, http.StatusUnprocessableEntity, "invalid operation")
+		return
+	}
+
+	repo, userID, ok := h.authorize(c, required, writeLFSError)
+	if !ok {
+		return
+	}
+
+	resp, err := h.lfsDomain.ProcessBatch(c.Request.Context(), repo.ID, userOrNil(userID), &req)
+	if err != nil {
+		handleLFSError(c, err)
+		return
+	}
+
+	h.authenticateActions(c, repo, resp)
+	writeLFS(c, http.StatusOK, resp)
+}
+
+// UploadObject stores an object sent through the server.
+// PUT /git/:owner/:repo/info/lfs/objects/:oid
+func (h *LFSHandler) UploadObject(c *gin.Context) {
+	oid, ok := objectID(c)
+	if !ok {
+		return
+	}
+
+	repo, ok := h.authorizeLFS(c, model.GitPermissionWrite)
+	if !ok {
+		return
+	}
+
+	size := c.Request.ContentLength
+	if size < 0 {
+		writeLFSError(c, http.StatusLengthRequired, "content length required")
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// Objects are shared between repositories. One that is already stored
+	// is only linked, so an upload can't replace content others rely on.
+	if err := h.lfsDomain.VerifyObject(ctx, oid, size); err == nil {
+		if err := h.lfsDomain.LinkObject(ctx, repo.ID, oid); err != nil {
+			handleLFSError(c, err)
+			return
+		}
+		c.Status(http.StatusOK)
+		return
+	}
+
+	if err := h.lfsDomain.Upload(ctx, repo.ID, oid, c.Request.Body, size); err != nil {
+		handleLFSError(c, err)
+		return
+	}
+	if err := h.lfsDomain.CreateObject(ctx, repo.ID, oid, size); err != nil {
+		handleLFSError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// DownloadObject streams an object through the server.
+// GET /git/:owner/:repo/info/lfs/objects/:oid
+func (h *LFSHandler) DownloadObject(c *gin.Context) {
+	oid, ok := objectID(c)
+	if !ok {
+		return
+	}
+
+	if _, ok := h.authorizeLFS(c, model.GitPermissionRead); !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	if _, err := h.lfsDomain.GetObject(ctx, oid); err != nil {
+		handleLFSError(c, err)
+		return
+	}
+
+	reader, size, err := h.lfsDomain.Download(ctx, oid)
+	if err != nil {
+		handleLFSError(c, err)
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, size, "application/octet-stream", reader, nil)
+}
+
+// VerifyObject confirms that an upload completed. Presigned uploads go to a
+// staging key named by the upload query parameter; they are checked and
+// recorded here. Proxied uploads were checked when they were received.
+// POST /git/:owner/:repo/info/lfs/objects/:oid/verify?upload=
+func (h *LFSHandler) VerifyObject(c *gin.Context) {
+	oid, ok := objectID(c)
+	if !ok {
+		return
+	}
+
+	var req model.GitLFSPointer
+	if err := c.ShouldBindJSON(&req); err != nil || req.OID != oid || req.Size < 0 {
+		writeLFSError(c, http.StatusUnprocessableEntity, "invalid verify request")
+		return
+	}
+
+	repo, ok := h.authorizeLFS(c, model.GitPermissionWrite)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	var err error
+	if uploadID := c.Query("upload"); uploadID != "" {
+		err = h.lfsDomain.VerifyUpload(ctx, repo.ID, oid, uploadID)
+	} else {
+		err = h.lfsDomain.VerifyObject(ctx, oid, req.Size)
+	}
+	if err != nil {
+		handleLFSError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// ===== Lock Handlers =====
+
+// CreateLock locks a file.
+// POST /git/:owner/:repo/info/lfs/locks
+func (h *LFSHandler) CreateLock(c *gin.Context) {
+	var req model.GitLFSLockRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Path == "" {
+		writeLFSError(c, http.StatusUnprocessableEntity, "invalid lock request")
+		return
+	}
+
+	repo, userID, ok := h.authorizeLocks(c, model.GitPermissionWrite)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	lock, err := h.lockDomain.CreateLock(ctx, repo.ID, *userID, req.Path)
+	if err != nil {
+		if err.Error() == "file is already locked" {
+			h.writeLockConflict(c, repo, req.Path, err)
+			return
+		}
+		handleLFSError(c, err)
+		return
+	}
+
+	writeLFS(c, http.StatusCreated, &model.GitLFSLockResponse{Lock: toLFSLockInfo(lock)})
+}
+
+// ListLocks lists the locks of a repository.
+// GET /git/:owner/:repo/info/lfs/locks?path=&id=&limit=
+func (h *LFSHandler) ListLocks(c *gin.Context) {
+	repo, _, ok := h.authorizeLocks(c, model.GitPermissionRead)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	resp := &model.GitLFSLocksResponse{Locks: make([]*model.GitLFSLockInfo, 0)}
+
+	if idParam := c.Query("id"); idParam != "" {
+		id, err := uuid.Parse(idParam)
+		if err != nil {
+			writeLFS(c, http.StatusOK, resp)
+			return
+		}
+		lock, err := h.lockDomain.GetLock(ctx, id)
+		if err != nil && err.Error() != "lock not found" {
+			handleLFSError(c, err)
+			return
+		}
+		if lock != nil && lock.RepoID == repo.ID {
+			resp.Locks = append(resp.Locks, toLFSLockInfo(lock))
+		}
+		writeLFS(c, http.StatusOK, resp)
+		return
+	}
+
+	limit := 0
+	if l, err := strconv.Atoi(c.Query("limit")); err == nil && l > 0 {
+		limit = l
+	}
+
+	locks, err := h.lockDomain.ListLocks(ctx, repo.ID, c.Query("path"), limit)
+	if err != nil {
+		handleLFSError(c, err)
+		return
+	}
+	for _, lock := range locks {
+		resp.Locks = append(resp.Locks, toLFSLockInfo(lock))
+	}
+
+	writeLFS(c, http.StatusOK, resp)
+}
+
+// VerifyLocks splits the locks of a repository into the caller's and
+// everyone else's, which git-lfs checks before a push.
+// POST /git/:owner/:repo/info/lfs/locks/verify
+func (h *LFSHandler) VerifyLocks(c *gin.Context) {
+	repo, userID, ok := h.authorizeLocks(c, model.GitPermissionWrite)
+	if !ok {
+		return
+	}
+
+	result, err := h.lockDomain.VerifyLocks(c.Request.Context(), repo.ID, *userID)
+	if err != nil {
+		handleLFSError(c, err)
+		return
+	}
+
+	writeLFS(c, http.StatusOK, &model.GitLFSVerifyLocksResponse{
+		Ours:       toLFSLockInfos(result.Ours),
+		Theirs:     toLFSLockInfos(result.Theirs),
+		NextCursor: result.NextCursor,
+	})
+}
+
+// DeleteLockRequest represents an unlock request.
+type DeleteLockRequest struct {
+	Force bool `json:"force"`
+}
+
+// DeleteLock releases a lock. Locks of other users can only be released
+// with force by a repository admin.
+// POST /git/:owner/:repo/info/lfs/locks/:id/unlock
+func (h *LFSHandler) DeleteLock(c *gin.Context) {
+	var req DeleteLockRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeLFSError(c, http.StatusUnprocessableEntity, "invalid unlock request")
+			return
+		}
+	}
+
+	repo, userID, ok := h.authorizeLocks(c, model.GitPermissionWrite)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		writeLFSError(c, http.StatusNotFound, "lock not found")
+		return
+	}
+
+	lock, err := h.lockDomain.GetLock(ctx, id)
+	if err != nil {
+		handleLFSError(c, err)
+		return
+	}
+	if lock.RepoID != repo.ID {
+		writeLFSError(c, http.StatusNotFound, "lock not found")
+		return
+	}
+
+	if req.Force {
+		isAdmin, err := h.domain.CanAccess(ctx, repo.ID, userID, model.GitPermissionAdmin)
+		if err != nil {
+			handleLFSError(c, err)
+			return
+		}
+		if !isAdmin {
+			writeLFSError(c, http.StatusForbidden, "force unlock requires admin permission")
+			return
+		}
+	}
+
+	if err := h.lockDomain.DeleteLock(ctx, id, *userID, req.Force); err != nil {
+		handleLFSError(c, err)
+		return
+	}
+
+	writeLFS(c, http.StatusOK, &model.GitLFSLockResponse{Lock: toLFSLockInfo(lock)})
+}
+
+// ===== Helpers =====
+
+// authorizeLFS authorizes an object transfer on a repository with LFS
+// enabled.
+func (h *LFSHandler) authorizeLFS(c *gin.Context, required model.GitPermission) (*model.GitRepo, bool) {
+	repo, _, ok := h.authorize(c, required, writeLFSError)
+	if !ok {
+		return nil, false
+	}
+	if !repo.LFSEnabled {
+		writeLFSError(c, http.StatusNotFound, "LFS is not enabled for this repository")
+		return nil, false
+	}
+	return repo, true
+}
+
+// authorizeLocks authorizes a lock operation. Locks belong to users, so
+// anonymous callers are asked to authenticate.
+func (h *LFSHandler) authorizeLocks(c *gin.Context, required model.GitPermission) (*model.GitRepo, *uuid.UUID, bool) {
+	repo, userID, ok := h.authorize(c, required, writeLFSError)
+	if !ok {
+		return nil, nil, false
+	}
+	if userID == nil {
+		requireAuth(c, writeLFSError)
+		return nil, nil, false
+	}
+	return repo, userID, true
+}
+
+// authenticateActions passes the caller's credentials on to the actions
+// served by this handler, so proxied transfers don't prompt again.
+// Presigned storage URLs carry their own authorization.
+func (h *LFSHandler) authenticateActions(c *gin.Context, repo *model.GitRepo, resp *model.GitLFSBatchResponse) {
+	authorization := c.GetHeader("Authorization")
+	if authorization == "" {
+		return
+	}
+
+	prefix := lfsURL(h.baseURL, repo)
+	for _, obj := range resp.Objects {
+		for _, action := range obj.Actions {
+			if !strings.HasPrefix(action.Href, prefix) {
+				continue
+			}
+			if action.Header == nil {
+				action.Header = make(map[string]string)
+			}
+			action.Header["Authorization"] = authorization
+		}
+	}
+}
+
+// writeLockConflict answers a lock request for a path that is already
+// locked with the existing lock, as the LFS locking API requires.
+func (h *LFSHandler) writeLockConflict(c *gin.Context, repo *model.GitRepo, path string, conflict error) {
+	resp := gin.H{"message": conflict.Error()}
+
+	locks, err := h.lockDomain.ListLocks(c.Request.Context(), repo.ID, path, 1)
+	if err == nil && len(locks) > 0 {
+		resp["lock"] = toLFSLockInfo(locks[0])
+	}
+
+	writeLFS(c, http.StatusConflict, resp)
+}
+
+// objectID returns the oid URL parameter, rejecting anything that isn't a
+// SHA-256 hash.
+func objectID(c *gin.Context) (string, bool) {
+	oid := c.Param("oid")
+	if !oidPattern.MatchString(oid) {
+		writeLFSError(c, http.StatusUnprocessableEntity, "invalid object ID")
+		return "", false
+	}
+	return oid, true
+}
+
+// userOrNil returns the user ID, or uuid.Nil for anonymous callers.
+func userOrNil(userID *uuid.UUID) uuid.UUID {
+	if userID == nil {
+		return uuid.Nil
+	}
+	return *userID
+}
+
+func writeLFS(c *gin.Context, status int, body any) {
+	c.Header("Content-Type", lfsMediaType)
+	c.JSON(status, body)
+}
+
+func writeLFSError(c *gin.Context, status int, message string) {
+	writeLFS(c, status, gin.H{"message": message})
+}
+
+func handleLFSError(c *gin.Context, err error) {
+	switch msg := err.Error(); {
+	case msg == "repository not found", msg == "LFS is not enabled for this repository",
+		msg == "LFS object not found", msg == "lock not found":
+		writeLFSError(c, http.StatusNotFound, msg)
+	case msg == "access denied", msg == "lock is owned by another user":
+		writeLFSError(c, http.StatusForbidden, msg)
+	case msg == "LFS object content does not match its oid", strings.HasPrefix(msg, "size mismatch"):
+		writeLFSError(c, http.StatusUnprocessableEntity, msg)
+	case msg == "file size exceeds maximum allowed":
+		writeLFSError(c, http.StatusRequestEntityTooLarge, msg)
+	case msg == "LFS storage quota exceeded":
+		writeLFSError(c, http.StatusInsufficientStorage, msg)
+	default:
+		writeLFSError(c, http.StatusInternalServerError, "internal server error")
+	}
+}
+
+func toLFSLockInfo(lock *model.GitLFSLock) *model.GitLFSLockInfo {
+	return &model.GitLFSLockInfo{
+		ID:       lock.ID.String(),
+		Path:     lock.Path,
+		LockedAt: lock.LockedAt,
+		Owner:    &model.GitLFSOwner{Name: lock.OwnerID.String()},
+	}
+}
+
+func toLFSLockInfos(locks []*model.GitLFSLock) []*model.GitLFSLockInfo {
+	result := make([]*model.GitLFSLockInfo, len(locks))
+	for i, lock := range locks {
+		result[i] = toLFSLockInfo(lock)
+	}
+	return result
+}
@@ -0,0 +1,312 @@
+package githttp
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	gitdomain "github.com/uniedit/server/internal/domain/git"
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/outbound"
+)
+
+// --- Fakes ---
+
+type fakeRepoDB struct {
+	outbound.GitRepoDatabasePort
+	repo *model.GitRepo
+}
+
+func (f *fakeRepoDB) FindByID(ctx context.Context, id uuid.UUID) (*model.GitRepo, error) {
+	if id != f.repo.ID {
+		return nil, nil
+	}
+	return f.repo, nil
+}
+
+type fakeAccessControl struct {
+	outbound.GitAccessControlPort
+}
+
+func (f *fakeAccessControl) CheckAccess(ctx context.Context, userID, repoID uuid.UUID, required model.GitPermission) (*model.GitAccessResult, error) {
+	return &model.GitAccessResult{Allowed: true}, nil
+}
+
+// fakeLFSObjectDB keeps LFS object records and repository links in memory.
+type fakeLFSObjectDB struct {
+	outbound.GitLFSObjectDatabasePort
+	mu      sync.Mutex
+	objects map[string]*model.GitLFSObject
+	links   map[string]bool
+}
+
+func newFakeLFSObjectDB() *fakeLFSObjectDB {
+	return &fakeLFSObjectDB{objects: make(map[string]*model.GitLFSObject), links: make(map[string]bool)}
+}
+
+func (f *fakeLFSObjectDB) Create(ctx context.Context, obj *model.GitLFSObject) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.objects[obj.OID]; !ok {
+		f.objects[obj.OID] = obj
+	}
+	return nil
+}
+
+func (f *fakeLFSObjectDB) FindByOID(ctx context.Context, oid string) (*model.GitLFSObject, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.objects[oid], nil
+}
+
+func (f *fakeLFSObjectDB) Link(ctx context.Context, repoID uuid.UUID, oid string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.links[repoID.String()+"/"+oid] = true
+	return nil
+}
+
+// memLFSStorage is an LFS object store whose presigned upload URLs point
+// at a fake storage host; tests write to the staged keys directly.
+type memLFSStorage struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemLFSStorage() *memLFSStorage {
+	return &memLFSStorage{objects: make(map[string][]byte)}
+}
+
+func (s *memLFSStorage) put(key string, data []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+}
+
+func (s *memLFSStorage) get(key string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	return data, ok
+}
+
+func (s *memLFSStorage) Upload(ctx context.Context, key string, reader io.Reader, size int64) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	s.put(key, data)
+	return nil
+}
+
+func (s *memLFSStorage) Download(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	data, ok := s.get(key)
+	if !ok {
+		return nil, 0, errors.New("object not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), int64(len(data)), nil
+}
+
+func (s *memLFSStorage) Exists(ctx context.Context, key string) (bool, error) {
+	_, ok := s.get(key)
+	return ok, nil
+}
+
+func (s *memLFSStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+func (s *memLFSStorage) Copy(ctx context.Context, src, dst string) error {
+	data, ok := s.get(src)
+	if !ok {
+		return errors.New("object not found")
+	}
+	s.put(dst, data)
+	return nil
+}
+
+func (s *memLFSStorage) GenerateUploadURL(ctx context.Context, key string, size int64, expiry time.Duration) (*outbound.GitPresignedURL, error) {
+	return &outbound.GitPresignedURL{URL: "https://storage.test/" + key, Method: http.MethodPut, ExpiresAt: time.Now().Add(expiry)}, nil
+}
+
+func (s *memLFSStorage) GenerateDownloadURL(ctx context.Context, key string, expiry time.Duration) (*outbound.GitPresignedURL, error) {
+	return &outbound.GitPresignedURL{URL: "https://storage.test/" + key, Method: http.MethodGet, ExpiresAt: time.Now().Add(expiry)}, nil
+}
+
+// --- Helpers ---
+
+type lfsTestServer struct {
+	srv     *httptest.Server
+	repo    *model.GitRepo
+	storage *memLFSStorage
+	objects *fakeLFSObjectDB
+}
+
+// newLFSServer serves the LFS API of an LFS enabled repository with
+// presigned transfers.
+func newLFSServer(t *testing.T) *lfsTestServer {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	repo := &model.GitRepo{ID: uuid.New(), OwnerID: uuid.New(), Slug: "test", LFSEnabled: true}
+	storage := newMemLFSStorage()
+	objects := newFakeLFSObjectDB()
+
+	cfg := gitdomain.DefaultConfig()
+	cfg.BaseURL = srv.URL
+	lfsDomain := gitdomain.NewLFSDomain(&fakeRepoDB{repo: repo}, objects, storage, &fakeAccessControl{}, nil, cfg, zap.NewNop())
+
+	domain := &fakeGitDomain{repo: repo}
+	NewLFSHandler(domain, lfsDomain, nil, &fakeCredentials{userID: uuid.New()}, srv.URL).RegisterRoutes(r)
+
+	return &lfsTestServer{srv: srv, repo: repo, storage: storage, objects: objects}
+}
+
+// lfsRequest sends an LFS API request the way git-lfs does and decodes the
+// response into out.
+func lfsRequest(t *testing.T, method, url string, header map[string]string, body, out any) *http.Response {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	require.NoError(t, err)
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	req.SetBasicAuth("test", testPassword)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	if out != nil {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp
+}
+
+// batchUpload requests the upload actions of an object.
+func (s *lfsTestServer) batchUpload(t *testing.T, oid string, size int64) *model.GitLFSObjectResponse {
+	var batch model.GitLFSBatchResponse
+	resp := lfsRequest(t, http.MethodPost, lfsURL(s.srv.URL, s.repo)+"/objects/batch", nil, &model.GitLFSBatchRequest{
+		Operation: "upload",
+		Transfers: []string{"basic"},
+		Objects:   []*model.GitLFSPointer{{OID: oid, Size: size}},
+	}, &batch)
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, lfsMediaType, resp.Header.Get("Content-Type"))
+	require.Len(t, batch.Objects, 1)
+	require.Nil(t, batch.Objects[0].Error)
+	return batch.Objects[0]
+}
+
+// uploadStaged writes content where the presigned upload action points.
+func (s *lfsTestServer) uploadStaged(t *testing.T, obj *model.GitLFSObjectResponse, content []byte) {
+	upload := obj.Actions["upload"]
+	require.NotNil(t, upload)
+	require.True(t, strings.HasPrefix(upload.Href, "https://storage.test/"))
+	s.storage.put(strings.TrimPrefix(upload.Href, "https://storage.test/"), content)
+}
+
+func (s *lfsTestServer) verify(t *testing.T, obj *model.GitLFSObjectResponse, size int64, out any) *http.Response {
+	verify := obj.Actions["verify"]
+	require.NotNil(t, verify)
+	return lfsRequest(t, http.MethodPost, verify.Href, verify.Header, &model.GitLFSPointer{OID: obj.OID, Size: size}, out)
+}
+
+func lfsOID(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// --- Tests ---
+
+func TestLFS_BatchUploadVerify(t *testing.T) {
+	s := newLFSServer(t)
+	content := []byte("large binary content")
+	oid := lfsOID(content)
+
+	obj := s.batchUpload(t, oid, int64(len(content)))
+
+	// The upload goes to a staging key; only the verify action is served
+	// here, with the caller's credentials passed on.
+	verify := obj.Actions["verify"]
+	require.NotNil(t, verify)
+	assert.True(t, strings.HasPrefix(verify.Href, lfsURL(s.srv.URL, s.repo)+"/objects/"+oid+"/verify?upload="))
+	assert.NotEmpty(t, verify.Header["Authorization"])
+	assert.NotContains(t, obj.Actions["upload"].Href, oid)
+
+	s.uploadStaged(t, obj, content)
+
+	// The declared size is ignored; the record has the stored size
+	resp := s.verify(t, obj, 0, nil)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	stored, ok := s.storage.get(oid)
+	require.True(t, ok)
+	assert.Equal(t, content, stored)
+	record, _ := s.objects.FindByOID(context.Background(), oid)
+	require.NotNil(t, record)
+	assert.Equal(t, int64(len(content)), record.Size)
+	assert.True(t, s.objects.links[s.repo.ID.String()+"/"+oid])
+	assert.Len(t, s.storage.objects, 1, "the staged upload is removed")
+}
+
+func TestLFS_VerifyRejectsContentNotMatchingOID(t *testing.T) {
+	s := newLFSServer(t)
+	content := []byte("large binary content")
+	oid := lfsOID(content)
+
+	obj := s.batchUpload(t, oid, int64(len(content)))
+	s.uploadStaged(t, obj, []byte("something else entirely"))
+
+	var errResp struct {
+		Message string `json:"message"`
+	}
+	resp := s.verify(t, obj, int64(len(content)), &errResp)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	assert.Equal(t, lfsMediaType, resp.Header.Get("Content-Type"))
+	assert.Equal(t, "LFS object content does not match its oid", errResp.Message)
+	_, ok := s.storage.get(oid)
+	assert.False(t, ok)
+	record, _ := s.objects.FindByOID(context.Background(), oid)
+	assert.Nil(t, record)
+}
+
+func TestLFS_VerifyUnknownUpload(t *testing.T) {
+	s := newLFSServer(t)
+	oid := lfsOID([]byte("never uploaded"))
+
+	obj := s.batchUpload(t, oid, 14)
+
+	resp := s.verify(t, obj, 14, nil)
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, lfsMediaType, resp.Header.Get("Content-Type"))
+}
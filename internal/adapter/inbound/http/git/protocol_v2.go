@@ -0,0 +1,300 @@
+package githttp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/pktline"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/sideband"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// go-git only speaks protocol v0, so the v2 commands are implemented here
+// on top of its storage and pack encoder.
+
+// Packet kinds returned by pktReader.
+const (
+	pktData = iota
+	pktFlush
+	pktDelim
+)
+
+// delimPkt separates sections of a v2 request or response.
+var delimPkt = []byte("0001")
+
+// writeV2Capabilities writes the v2 capability advertisement.
+func writeV2Capabilities(w io.Writer) error {
+	e := pktline.NewEncoder(w)
+	if err := e.EncodeString(
+		"version 2\n",
+		"agent="+capability.DefaultAgent()+"\n",
+		"ls-refs\n",
+		"fetch\n",
+		"object-format=sha1\n",
+	); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// v2Request is a decoded v2 command request.
+type v2Request struct {
+	command string
+	args    []string
+}
+
+// serveV2 runs one v2 command.
+func serveV2(c *gin.Context, st *filesystem.Storage, body io.Reader) {
+	req, err := readV2Request(body)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	setNoCache(c)
+	c.Header("Content-Type", "application/x-git-upload-pack-result")
+
+	switch req.command {
+	case "ls-refs":
+		err = lsRefs(c.Writer, st, req.args)
+	case "fetch":
+		err = fetch(c, st, req.args)
+	default:
+		err = fmt.Errorf("unknown command %q", req.command)
+	}
+	if err != nil {
+		_ = pktline.NewEncoder(c.Writer).Encodef("ERR %s\n", err)
+	}
+}
+
+// readV2Request reads the command, the capability lines and the arguments
+// of a v2 request.
+func readV2Request(r io.Reader) (*v2Request, error) {
+	pr := &pktReader{r: r}
+	req := &v2Request{}
+
+	inArgs := false
+	for {
+		kind, line, err := pr.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch kind {
+		case pktFlush:
+			if req.command == "" {
+				return nil, fmt.Errorf("missing command")
+			}
+			return req, nil
+		case pktDelim:
+			inArgs = true
+		default:
+			if inArgs {
+				req.args = append(req.args, line)
+			} else if cmd, ok := strings.CutPrefix(line, "command="); ok {
+				req.command = cmd
+			}
+		}
+	}
+}
+
+// lsRefs lists the refs of the repository.
+func lsRefs(w io.Writer, st *filesystem.Storage, args []string) error {
+	var symrefs, peel bool
+	var prefixes []string
+	for _, arg := range args {
+		switch {
+		case arg == "symrefs":
+			symrefs = true
+		case arg == "peel":
+			peel = true
+		case strings.HasPrefix(arg, "ref-prefix "):
+			prefixes = append(prefixes, strings.TrimPrefix(arg, "ref-prefix "))
+		}
+	}
+
+	refs, err := listRefs(st)
+	if err != nil {
+		return err
+	}
+
+	e := pktline.NewEncoder(w)
+	for _, ref := range refs {
+		if !hasAnyPrefix(ref.Name().String(), prefixes) {
+			continue
+		}
+
+		resolved, err := storer.ResolveReference(st, ref.Name())
+		if err != nil {
+			// Unborn HEAD of an empty repository
+			continue
+		}
+
+		line := resolved.Hash().String() + " " + ref.Name().String()
+		if symrefs && ref.Type() == plumbing.SymbolicReference {
+			line += " symref-target:" + ref.Target().String()
+		}
+		if peel && ref.Name().IsTag() {
+			if tag, err := object.GetTag(st, resolved.Hash()); err == nil {
+				line += " peeled:" + tag.Target.String()
+			}
+		}
+
+		if err := e.EncodeString(line + "\n"); err != nil {
+			return err
+		}
+	}
+
+	return e.Flush()
+}
+
+// listRefs returns HEAD followed by the other refs in name order.
+func listRefs(st *filesystem.Storage) ([]*plumbing.Reference, error) {
+	iter, err := st.IterReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []*plumbing.Reference
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name() != plumbing.HEAD {
+			refs = append(refs, ref)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return refs[i].Name() < refs[j].Name()
+	})
+
+	if head, err := st.Reference(plumbing.HEAD); err == nil {
+		refs = append([]*plumbing.Reference{head}, refs...)
+	}
+
+	return refs, nil
+}
+
+func hasAnyPrefix(name string, prefixes []string) bool {
+	if len(prefixes) == 0 {
+		return true
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// fetch negotiates with the client and sends the pack once it is done or
+// a common commit was found.
+func fetch(c *gin.Context, st *filesystem.Storage, args []string) error {
+	req := packp.NewUploadPackRequest()
+	var haves []plumbing.Hash
+	done := false
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "want "):
+			req.Wants = append(req.Wants, plumbing.NewHash(strings.TrimPrefix(arg, "want ")))
+		case strings.HasPrefix(arg, "have "):
+			haves = append(haves, plumbing.NewHash(strings.TrimPrefix(arg, "have ")))
+		case arg == "done":
+			done = true
+		}
+	}
+	req.Haves = commonObjects(st, haves)
+
+	w := c.Writer
+	e := pktline.NewEncoder(w)
+
+	if !done {
+		if err := e.EncodeString("acknowledgments\n"); err != nil {
+			return err
+		}
+		if len(req.Haves) == 0 {
+			if err := e.EncodeString("NAK\n"); err != nil {
+				return err
+			}
+			// The client sends more haves or gives up with done
+			return e.Flush()
+		}
+		for _, h := range req.Haves {
+			if err := e.Encodef("ACK %s\n", h); err != nil {
+				return err
+			}
+		}
+		if err := e.EncodeString("ready\n"); err != nil {
+			return err
+		}
+		if _, err := w.Write(delimPkt); err != nil {
+			return err
+		}
+	}
+
+	sess, err := uploadSession(st)
+	if err != nil {
+		return err
+	}
+	resp, err := sess.UploadPack(c.Request.Context(), req)
+	if err != nil {
+		return err
+	}
+	defer resp.Close()
+
+	if err := e.EncodeString("packfile\n"); err != nil {
+		return err
+	}
+	if _, err := io.Copy(sideband.NewMuxer(sideband.Sideband64k, w), resp); err != nil {
+		return err
+	}
+	return e.Flush()
+}
+
+// pktReader reads pkt-lines. Unlike pktline.Scanner it tells flush and
+// delim packets apart, which v2 requests need.
+type pktReader struct {
+	r io.Reader
+}
+
+// next returns the kind of the next packet and, for data packets, its
+// payload without the trailing newline.
+func (p *pktReader) next() (int, string, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(p.r, size[:]); err != nil {
+		return 0, "", err
+	}
+
+	n, err := strconv.ParseUint(string(size[:]), 16, 16)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid pkt-line length %q", size)
+	}
+
+	switch {
+	case n == 0:
+		return pktFlush, "", nil
+	case n == 1:
+		return pktDelim, "", nil
+	case n < 4:
+		return 0, "", fmt.Errorf("invalid pkt-line length %q", size)
+	}
+
+	payload := make([]byte, n-4)
+	if _, err := io.ReadFull(p.r, payload); err != nil {
+		return 0, "", err
+	}
+
+	return pktData, string(bytes.TrimSuffix(payload, []byte("\n"))), nil
+}
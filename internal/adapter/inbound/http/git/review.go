@@ -0,0 +1,271 @@
+package githttp
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// PRCommentRequest represents a pull request comment request. Path and line
+// anchor the comment to a line of a file; commit_sha defaults to the head of
+// the pull request.
+type PRCommentRequest struct {
+	Body      string `json:"body" binding:"required,max=65536"`
+	Path      string `json:"path" binding:"max=4096"`
+	Line      int    `json:"line" binding:"required_with=Path,omitempty,min=1"`
+	CommitSHA string `json:"commit_sha" binding:"omitempty,len=40,hexadecimal"`
+}
+
+// UpdatePRCommentRequest represents an update pull request comment request.
+type UpdatePRCommentRequest struct {
+	Body string `json:"body" binding:"required,max=65536"`
+}
+
+// SubmitReviewRequest represents a submit review request.
+type SubmitReviewRequest struct {
+	State    string             `json:"state" binding:"required,oneof=approved changes_requested commented"`
+	Body     string             `json:"body" binding:"max=65536"`
+	Comments []PRCommentRequest `json:"comments" binding:"max=500,dive"`
+}
+
+// RequestReviewersRequest represents a request reviewers request.
+type RequestReviewersRequest struct {
+	Reviewers []uuid.UUID `json:"reviewers" binding:"required,min=1,max=50"`
+}
+
+// ===== Comment Handlers =====
+
+// CreatePRComment comments on a pull request.
+// POST /repos/:id/pulls/:number/comments
+func (h *Handler) CreatePRComment(c *gin.Context) {
+	repoID, number, userID, ok := prParams(c)
+	if !ok {
+		return
+	}
+
+	var req PRCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.domain.CreatePRComment(c.Request.Context(), repoID, number, userID, toPRCommentInput(req))
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, comment)
+}
+
+// ListPRComments lists the comments of a pull request.
+// GET /repos/:id/pulls/:number/comments
+func (h *Handler) ListPRComments(c *gin.Context) {
+	repoID, number, userID, ok := prParams(c)
+	if !ok {
+		return
+	}
+
+	comments, err := h.domain.ListPRComments(c.Request.Context(), repoID, number, userID)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comments": comments})
+}
+
+// UpdatePRComment edits a pull request comment.
+// PUT /repos/:id/pulls/:number/comments/:comment_id
+func (h *Handler) UpdatePRComment(c *gin.Context) {
+	repoID, number, userID, ok := prParams(c)
+	if !ok {
+		return
+	}
+
+	commentID, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	var req UpdatePRCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.domain.UpdatePRComment(c.Request.Context(), repoID, number, commentID, userID, req.Body)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+// DeletePRComment deletes a pull request comment.
+// DELETE /repos/:id/pulls/:number/comments/:comment_id
+func (h *Handler) DeletePRComment(c *gin.Context) {
+	repoID, number, userID, ok := prParams(c)
+	if !ok {
+		return
+	}
+
+	commentID, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment ID"})
+		return
+	}
+
+	if err := h.domain.DeletePRComment(c.Request.Context(), repoID, number, commentID, userID); err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ===== Review Handlers =====
+
+// SubmitReview approves, requests changes on, or comments on a pull request.
+// POST /repos/:id/pulls/:number/reviews
+func (h *Handler) SubmitReview(c *gin.Context) {
+	repoID, number, userID, ok := prParams(c)
+	if !ok {
+		return
+	}
+
+	var req SubmitReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	input := &inbound.GitSubmitReviewInput{
+		State:    model.GitPRReviewState(req.State),
+		Body:     req.Body,
+		Comments: make([]*inbound.GitPRCommentInput, len(req.Comments)),
+	}
+	for i, comment := range req.Comments {
+		input.Comments[i] = toPRCommentInput(comment)
+	}
+
+	review, err := h.domain.SubmitReview(c.Request.Context(), repoID, number, userID, input)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, review)
+}
+
+// ListReviews lists the reviews of a pull request.
+// GET /repos/:id/pulls/:number/reviews
+func (h *Handler) ListReviews(c *gin.Context) {
+	repoID, number, userID, ok := prParams(c)
+	if !ok {
+		return
+	}
+
+	reviews, err := h.domain.ListReviews(c.Request.Context(), repoID, number, userID)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reviews": reviews})
+}
+
+// RequestReviewers asks users to review a pull request.
+// POST /repos/:id/pulls/:number/requested_reviewers
+func (h *Handler) RequestReviewers(c *gin.Context) {
+	repoID, number, userID, ok := prParams(c)
+	if !ok {
+		return
+	}
+
+	var req RequestReviewersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	requests, err := h.domain.RequestReviewers(c.Request.Context(), repoID, number, userID, req.Reviewers)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"requested_reviewers": requests})
+}
+
+// ListReviewRequests lists the pending review requests of a pull request.
+// GET /repos/:id/pulls/:number/requested_reviewers
+func (h *Handler) ListReviewRequests(c *gin.Context) {
+	repoID, number, userID, ok := prParams(c)
+	if !ok {
+		return
+	}
+
+	requests, err := h.domain.ListReviewRequests(c.Request.Context(), repoID, number, userID)
+	if err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requested_reviewers": requests})
+}
+
+// RemoveReviewRequest withdraws a review request.
+// DELETE /repos/:id/pulls/:number/requested_reviewers/:user_id
+func (h *Handler) RemoveReviewRequest(c *gin.Context) {
+	repoID, number, userID, ok := prParams(c)
+	if !ok {
+		return
+	}
+
+	reviewerID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.domain.RemoveReviewRequest(c.Request.Context(), repoID, number, userID, reviewerID); err != nil {
+		handleGitError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// prParams parses the repository ID and pull request number from the path
+// and the authenticated user, writing the error response when one is missing.
+func prParams(c *gin.Context) (uuid.UUID, int, uuid.UUID, bool) {
+	repoID, userID, ok := browseParams(c)
+	if !ok {
+		return uuid.Nil, 0, uuid.Nil, false
+	}
+
+	number, err := strconv.Atoi(c.Param("number"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid pull request number"})
+		return uuid.Nil, 0, uuid.Nil, false
+	}
+
+	return repoID, number, userID, true
+}
+
+func toPRCommentInput(req PRCommentRequest) *inbound.GitPRCommentInput {
+	return &inbound.GitPRCommentInput{
+		Body:      req.Body,
+		Path:      req.Path,
+		Line:      req.Line,
+		CommitSHA: req.CommitSHA,
+	}
+}
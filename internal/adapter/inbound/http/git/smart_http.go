@@ -0,0 +1,513 @@
+package githttp
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/format/pktline"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/server"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// errStaleInfo is the status of a push command whose ref moved since the
+// client last fetched it.
+var errStaleInfo = errors.New("stale info")
+
+// SmartHTTPHandler serves the Git smart HTTP transport, so hosted
+// repositories can be cloned, fetched and pushed with a stock git client.
+type SmartHTTPHandler struct {
+	repoAuth
+	locks inbound.GitLFSLockDomain
+}
+
+// NewSmartHTTPHandler creates a new Git smart HTTP handler.
+func NewSmartHTTPHandler(domain inbound.GitDomain, locks inbound.GitLFSLockDomain, credentials inbound.GitCredentialValidator) *SmartHTTPHandler {
+	return &SmartHTTPHandler{
+		repoAuth: repoAuth{domain: domain, credentials: credentials},
+		locks:    locks,
+	}
+}
+
+// RegisterRoutes registers the smart HTTP routes. Git clients authenticate
+// with HTTP Basic, so these routes don't use the JWT middleware.
+func (h *SmartHTTPHandler) RegisterRoutes(r gin.IRouter) {
+	repo := r.Group("/git/:owner/:repo")
+	{
+		repo.GET("/info/refs", h.InfoRefs)
+		repo.POST("/git-upload-pack", h.UploadPack)
+		repo.POST("/git-receive-pack", h.ReceivePack)
+	}
+}
+
+// InfoRefs advertises the refs and capabilities of a service.
+// GET /git/:owner/:repo/info/refs?service=git-upload-pack
+func (h *SmartHTTPHandler) InfoRefs(c *gin.Context) {
+	service := model.GitService(c.Query("service"))
+	if !service.IsValid() {
+		c.String(http.StatusForbidden, "only the smart HTTP protocol is supported")
+		return
+	}
+
+	repo, _, ok := h.authorize(c, servicePermission(service), writeGitError)
+	if !ok {
+		return
+	}
+
+	st, err := h.openStorage(c, repo)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	setNoCache(c)
+	c.Header("Content-Type", "application/x-"+string(service)+"-advertisement")
+
+	if service == model.GitServiceUploadPack && protocolVersion(c) == 2 {
+		_ = writeV2Capabilities(c.Writer)
+		return
+	}
+
+	adv, err := advertisedReferences(c, st, service)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	adv.Prefix = [][]byte{[]byte("# service=" + string(service)), pktline.Flush}
+	_ = adv.Encode(c.Writer)
+}
+
+// UploadPack sends the objects a client asks for.
+// POST /git/:owner/:repo/git-upload-pack
+func (h *SmartHTTPHandler) UploadPack(c *gin.Context) {
+	repo, _, ok := h.authorize(c, model.GitPermissionRead, writeGitError)
+	if !ok {
+		return
+	}
+
+	body, err := requestBody(c)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	st, err := h.openStorage(c, repo)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	if protocolVersion(c) == 2 {
+		serveV2(c, st, body)
+		return
+	}
+
+	req := packp.NewUploadPackRequest()
+	if err := req.UploadRequest.Decode(body); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	haves, done, err := readHaves(body)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	req.Haves = commonObjects(st, haves)
+
+	setNoCache(c)
+	c.Header("Content-Type", "application/x-git-upload-pack-result")
+
+	// Negotiation rounds are stateless: acknowledge what we have in common
+	// and let the client come back with done.
+	if !done {
+		srvResp := packp.ServerResponse{ACKs: firstHash(req.Haves)}
+		_ = srvResp.Encode(c.Writer, false)
+		return
+	}
+
+	sess, err := uploadSession(st)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	resp, err := sess.UploadPack(c.Request.Context(), req)
+	if err != nil {
+		_ = pktline.NewEncoder(c.Writer).Encodef("ERR %s\n", err)
+		return
+	}
+	resp.ServerResponse.ACKs = firstHash(req.Haves)
+	_ = resp.Encode(c.Writer)
+}
+
+// ReceivePack applies pushed ref updates and stores the received pack.
+// POST /git/:owner/:repo/git-receive-pack
+func (h *SmartHTTPHandler) ReceivePack(c *gin.Context) {
+	repo, userID, ok := h.authorize(c, model.GitPermissionWrite, writeGitError)
+	if !ok {
+		return
+	}
+
+	body, err := requestBody(c)
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	st, err := h.openStorage(c, repo)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	req := packp.NewReferenceUpdateRequest()
+	if err := req.Decode(body); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stale := rejectStaleCommands(st, req)
+	if onlyDeletes(req) {
+		// Delete-only pushes carry no pack
+		req.Packfile = nil
+	}
+
+	// The pre-receive checks look at the pushed commits, so the pack is
+	// stored before any ref moves. Objects of rejected commands stay
+	// unreferenced. A pack over the owner's storage quota fails to unpack.
+	if req.Packfile != nil {
+		pack, err := h.domain.LimitPushPack(c.Request.Context(), repo.ID, req.Packfile)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "internal server error")
+			return
+		}
+		if err := packfile.UpdateObjectStorage(st, pack); err != nil {
+			writeUnpackError(c, req, err)
+			return
+		}
+		req.Packfile = nil
+	}
+
+	locks, err := h.locks.VerifyLocks(c.Request.Context(), repo.ID, *userID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "internal server error")
+		return
+	}
+	rejected := h.rejectCommands(c, repo.ID, *userID, locks.Theirs, req)
+
+	status := packp.NewReportStatus()
+	status.UnpackStatus = "ok"
+	status.CommandStatuses = updateReferences(st, req)
+
+	if updates := appliedUpdates(req, status); len(updates) > 0 {
+		// The refs already moved, so bookkeeping failures don't fail the push
+		_ = h.domain.ProcessPush(c.Request.Context(), repo.ID, *userID, updates)
+	}
+
+	setNoCache(c)
+	c.Header("Content-Type", "application/x-git-receive-pack-result")
+	if req.Capabilities.Supports(capability.ReportStatus) {
+		status.CommandStatuses = append(status.CommandStatuses, stale...)
+		status.CommandStatuses = append(status.CommandStatuses, rejected...)
+		_ = status.Encode(c.Writer)
+	}
+}
+
+// ===== Helpers =====
+
+// openStorage opens the object and ref storage of a repository.
+func (h *SmartHTTPHandler) openStorage(c *gin.Context, repo *model.GitRepo) (*filesystem.Storage, error) {
+	fs, err := h.domain.GetFilesystem(c.Request.Context(), repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	return filesystem.NewStorage(fs, cache.NewObjectLRUDefault()), nil
+}
+
+// servicePermission returns the permission a service needs.
+func servicePermission(service model.GitService) model.GitPermission {
+	if service.IsWrite() {
+		return model.GitPermissionWrite
+	}
+	return model.GitPermissionRead
+}
+
+func writeGitError(c *gin.Context, status int, message string) {
+	c.String(status, message)
+}
+
+func setNoCache(c *gin.Context) {
+	c.Header("Cache-Control", "no-cache")
+}
+
+// requestBody returns the request body, decompressing it if the client
+// sent it gzipped.
+func requestBody(c *gin.Context) (io.Reader, error) {
+	if c.GetHeader("Content-Encoding") == "gzip" {
+		return gzip.NewReader(c.Request.Body)
+	}
+	return c.Request.Body, nil
+}
+
+// protocolVersion returns the wire protocol version requested through the
+// Git-Protocol header.
+func protocolVersion(c *gin.Context) int {
+	for _, param := range strings.Split(c.GetHeader("Git-Protocol"), ":") {
+		if param == "version=2" {
+			return 2
+		}
+	}
+	return 0
+}
+
+// advertisedReferences lists the refs of a repository for the v0 protocol.
+func advertisedReferences(c *gin.Context, st *filesystem.Storage, service model.GitService) (*packp.AdvRefs, error) {
+	if service == model.GitServiceReceivePack {
+		sess, err := receiveSession(st)
+		if err != nil {
+			return nil, err
+		}
+		return sess.AdvertisedReferencesContext(c.Request.Context())
+	}
+
+	sess, err := uploadSession(st)
+	if err != nil {
+		return nil, err
+	}
+	return sess.AdvertisedReferencesContext(c.Request.Context())
+}
+
+// readHaves reads the have lines that follow the wants of a v0 upload-pack
+// request.
+func readHaves(r io.Reader) ([]plumbing.Hash, bool, error) {
+	var haves []plumbing.Hash
+	done := false
+
+	s := pktline.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSuffix(string(s.Bytes()), "\n")
+		switch {
+		case strings.HasPrefix(line, "have "):
+			haves = append(haves, plumbing.NewHash(strings.TrimPrefix(line, "have ")))
+		case line == "done":
+			done = true
+		}
+	}
+
+	return haves, done, s.Err()
+}
+
+// commonObjects returns the haves that exist in the repository. go-git
+// walks every have, so unknown ones must not reach it.
+func commonObjects(st storer.EncodedObjectStorer, haves []plumbing.Hash) []plumbing.Hash {
+	var common []plumbing.Hash
+	for _, h := range haves {
+		if st.HasEncodedObject(h) == nil {
+			common = append(common, h)
+		}
+	}
+	return common
+}
+
+func firstHash(hashes []plumbing.Hash) []plumbing.Hash {
+	if len(hashes) == 0 {
+		return nil
+	}
+	return hashes[:1]
+}
+
+// rejectStaleCommands drops the commands whose old value no longer matches
+// the ref, so a push can't overwrite changes it hasn't seen. It returns a
+// failed status for each dropped command. The refs can still move before
+// the push is applied; updateReferences catches that.
+func rejectStaleCommands(st storer.ReferenceStorer, req *packp.ReferenceUpdateRequest) []*packp.CommandStatus {
+	var rejected []*packp.CommandStatus
+
+	commands := req.Commands[:0]
+	for _, cmd := range req.Commands {
+		current := plumbing.ZeroHash
+		ref, err := st.Reference(cmd.Name)
+		switch {
+		case err == nil:
+			current = ref.Hash()
+		case err != plumbing.ErrReferenceNotFound:
+			rejected = append(rejected, &packp.CommandStatus{ReferenceName: cmd.Name, Status: err.Error()})
+			continue
+		}
+
+		if current != cmd.Old {
+			rejected = append(rejected, &packp.CommandStatus{ReferenceName: cmd.Name, Status: errStaleInfo.Error()})
+			continue
+		}
+		commands = append(commands, cmd)
+	}
+	req.Commands = commands
+
+	return rejected
+}
+
+// updateReferences applies the accepted commands of a push and returns
+// their statuses. A ref only moves if it still has the old value the client
+// sent, so of two concurrent pushes to a branch the later one fails with
+// stale info instead of overwriting the other.
+func updateReferences(st storer.ReferenceStorer, req *packp.ReferenceUpdateRequest) []*packp.CommandStatus {
+	statuses := make([]*packp.CommandStatus, 0, len(req.Commands))
+	for _, cmd := range req.Commands {
+		status := &packp.CommandStatus{ReferenceName: cmd.Name, Status: "ok"}
+		if err := updateReference(st, cmd); err != nil {
+			status.Status = err.Error()
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// updateReference applies a single command. Updates are compare-and-swap
+// on the ref; go-git has no equivalent for creating or deleting a ref, so
+// those check the ref right before changing it.
+func updateReference(st storer.ReferenceStorer, cmd *packp.Command) error {
+	ref := plumbing.NewHashReference(cmd.Name, cmd.New)
+
+	switch cmd.Action() {
+	case packp.Create:
+		_, err := st.Reference(cmd.Name)
+		if err == nil {
+			return errStaleInfo
+		}
+		if err != plumbing.ErrReferenceNotFound {
+			return err
+		}
+		return st.CheckAndSetReference(ref, nil)
+	case packp.Delete:
+		current, err := st.Reference(cmd.Name)
+		if err == plumbing.ErrReferenceNotFound {
+			return errStaleInfo
+		}
+		if err != nil {
+			return err
+		}
+		if current.Hash() != cmd.Old {
+			return errStaleInfo
+		}
+		return st.RemoveReference(cmd.Name)
+	default:
+		err := st.CheckAndSetReference(ref, plumbing.NewHashReference(cmd.Name, cmd.Old))
+		if errors.Is(err, storage.ErrReferenceHasChanged) || errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return errStaleInfo
+		}
+		return err
+	}
+}
+
+// rejectCommands drops the commands the pre-receive checks refuse: those
+// the branch protection rules deny the user, and those whose commits add
+// oversized files or touch files other users have locked. It returns a
+// failed status for each dropped command.
+func (h *SmartHTTPHandler) rejectCommands(c *gin.Context, repoID, userID uuid.UUID, locks []*model.GitLFSLock, req *packp.ReferenceUpdateRequest) []*packp.CommandStatus {
+	ctx := c.Request.Context()
+	var rejected []*packp.CommandStatus
+
+	commands := req.Commands[:0]
+	for _, cmd := range req.Commands {
+		update := &inbound.GitRefUpdate{
+			Ref:    cmd.Name.String(),
+			OldSHA: cmd.Old.String(),
+			NewSHA: cmd.New.String(),
+		}
+		err := h.domain.CheckPush(ctx, repoID, userID, update)
+		if err == nil {
+			err = h.domain.CheckPushContent(ctx, repoID, update, locks)
+		}
+		if err != nil {
+			rejected = append(rejected, &packp.CommandStatus{ReferenceName: cmd.Name, Status: err.Error()})
+			continue
+		}
+		commands = append(commands, cmd)
+	}
+	req.Commands = commands
+
+	return rejected
+}
+
+// writeUnpackError answers a push whose pack couldn't be stored, the way
+// go-git's receive-pack session does.
+func writeUnpackError(c *gin.Context, req *packp.ReferenceUpdateRequest, err error) {
+	if !req.Capabilities.Supports(capability.ReportStatus) {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	status := packp.NewReportStatus()
+	status.UnpackStatus = err.Error()
+
+	setNoCache(c)
+	c.Header("Content-Type", "application/x-git-receive-pack-result")
+	_ = status.Encode(c.Writer)
+}
+
+func onlyDeletes(req *packp.ReferenceUpdateRequest) bool {
+	for _, cmd := range req.Commands {
+		if cmd.Action() != packp.Delete {
+			return false
+		}
+	}
+	return true
+}
+
+// appliedUpdates returns the commands of a push that were applied.
+func appliedUpdates(req *packp.ReferenceUpdateRequest, status *packp.ReportStatus) []*inbound.GitRefUpdate {
+	ok := make(map[plumbing.ReferenceName]bool)
+	for _, cs := range status.CommandStatuses {
+		if cs.Error() == nil {
+			ok[cs.ReferenceName] = true
+		}
+	}
+
+	var updates []*inbound.GitRefUpdate
+	for _, cmd := range req.Commands {
+		if ok[cmd.Name] {
+			updates = append(updates, &inbound.GitRefUpdate{
+				Ref:    cmd.Name.String(),
+				OldSHA: cmd.Old.String(),
+				NewSHA: cmd.New.String(),
+			})
+		}
+	}
+	return updates
+}
+
+// ===== go-git Sessions =====
+
+// storageLoader hands go-git's server the storage opened for the request.
+type storageLoader struct {
+	storer storer.Storer
+}
+
+func (l storageLoader) Load(*transport.Endpoint) (storer.Storer, error) {
+	return l.storer, nil
+}
+
+func uploadSession(st storer.Storer) (transport.UploadPackSession, error) {
+	return server.NewServer(storageLoader{storer: st}).NewUploadPackSession(nil, nil)
+}
+
+func receiveSession(st storer.Storer) (transport.ReceivePackSession, error) {
+	return server.NewServer(storageLoader{storer: st}).NewReceivePackSession(nil, nil)
+}
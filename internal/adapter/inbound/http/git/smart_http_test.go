@@ -0,0 +1,333 @@
+package githttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/osfs"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/format/packfile"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp"
+	"github.com/go-git/go-git/v5/plumbing/protocol/packp/capability"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	gitclient "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+const testPassword = "secret"
+
+// --- Fakes ---
+
+// fakeGitDomain serves a single repository from a filesystem and records
+// the pushes it is told about.
+type fakeGitDomain struct {
+	inbound.GitDomain
+	repo      *model.GitRepo
+	fs        billy.Filesystem
+	checkPush func(update *inbound.GitRefUpdate) error
+	pushed    []*inbound.GitRefUpdate
+}
+
+func (d *fakeGitDomain) GetRepoByOwnerAndSlug(ctx context.Context, ownerID uuid.UUID, slug string) (*model.GitRepo, error) {
+	if ownerID != d.repo.OwnerID || slug != d.repo.Slug {
+		return nil, errors.New("repository not found")
+	}
+	return d.repo, nil
+}
+
+func (d *fakeGitDomain) CanAccess(ctx context.Context, repoID uuid.UUID, userID *uuid.UUID, required model.GitPermission) (bool, error) {
+	return userID != nil, nil
+}
+
+func (d *fakeGitDomain) GetFilesystem(ctx context.Context, repoID uuid.UUID) (billy.Filesystem, error) {
+	return d.fs, nil
+}
+
+func (d *fakeGitDomain) LimitPushPack(ctx context.Context, repoID uuid.UUID, pack io.ReadCloser) (io.ReadCloser, error) {
+	return pack, nil
+}
+
+func (d *fakeGitDomain) CheckPush(ctx context.Context, repoID, userID uuid.UUID, update *inbound.GitRefUpdate) error {
+	if d.checkPush != nil {
+		return d.checkPush(update)
+	}
+	return nil
+}
+
+func (d *fakeGitDomain) CheckPushContent(ctx context.Context, repoID uuid.UUID, update *inbound.GitRefUpdate, locks []*model.GitLFSLock) error {
+	return nil
+}
+
+func (d *fakeGitDomain) ProcessPush(ctx context.Context, repoID, pusherID uuid.UUID, updates []*inbound.GitRefUpdate) error {
+	d.pushed = append(d.pushed, updates...)
+	return nil
+}
+
+type fakeLockDomain struct {
+	inbound.GitLFSLockDomain
+}
+
+func (d *fakeLockDomain) VerifyLocks(ctx context.Context, repoID, userID uuid.UUID) (*inbound.GitVerifyLocksResult, error) {
+	return &inbound.GitVerifyLocksResult{}, nil
+}
+
+// fakeCredentials accepts any username with testPassword.
+type fakeCredentials struct {
+	userID uuid.UUID
+}
+
+func (f *fakeCredentials) ValidateGitCredentials(ctx context.Context, username, password string) (uuid.UUID, error) {
+	if password != testPassword {
+		return uuid.Nil, errors.New("invalid credentials")
+	}
+	return f.userID, nil
+}
+
+// --- Helpers ---
+
+// newSmartHTTPServer serves an empty bare repository over smart HTTP and
+// returns the server, its fake domain and the clone URL.
+func newSmartHTTPServer(t *testing.T) (*httptest.Server, *fakeGitDomain, string) {
+	gin.SetMode(gin.TestMode)
+
+	fs := osfs.New(t.TempDir())
+	st := filesystem.NewStorage(fs, cache.NewObjectLRUDefault())
+	_, err := gogit.Init(st, nil)
+	require.NoError(t, err)
+	require.NoError(t, st.SetReference(plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/main")))
+
+	domain := &fakeGitDomain{
+		repo: &model.GitRepo{ID: uuid.New(), OwnerID: uuid.New(), Slug: "test"},
+		fs:   fs,
+	}
+	handler := NewSmartHTTPHandler(domain, &fakeLockDomain{}, &fakeCredentials{userID: uuid.New()})
+
+	r := gin.New()
+	handler.RegisterRoutes(r)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	return srv, domain, cloneURL(srv.URL, domain.repo)
+}
+
+func testAuth() *gitclient.BasicAuth {
+	return &gitclient.BasicAuth{Username: "test", Password: testPassword}
+}
+
+// newClientRepo returns an empty in-memory repository with origin set to
+// url.
+func newClientRepo(t *testing.T, url string) *gogit.Repository {
+	repo, err := gogit.Init(memory.NewStorage(), memfs.New())
+	require.NoError(t, err)
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{url}})
+	require.NoError(t, err)
+	return repo
+}
+
+// commitFile commits a file on the checked out branch of repo.
+func commitFile(t *testing.T, repo *gogit.Repository, name, content string) plumbing.Hash {
+	wt, err := repo.Worktree()
+	require.NoError(t, err)
+
+	f, err := wt.Filesystem.Create(name)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	_, err = wt.Add(name)
+	require.NoError(t, err)
+	hash, err := wt.Commit("update "+name, &gogit.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash
+}
+
+func push(t *testing.T, repo *gogit.Repository, refspec string) {
+	err := repo.Push(&gogit.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{config.RefSpec(refspec)},
+		Auth:       testAuth(),
+	})
+	require.NoError(t, err)
+}
+
+// serverRef returns the hash a ref of the served repository points to.
+func serverRef(t *testing.T, domain *fakeGitDomain, name string) plumbing.Hash {
+	st := filesystem.NewStorage(domain.fs, cache.NewObjectLRUDefault())
+	ref, err := st.Reference(plumbing.ReferenceName(name))
+	require.NoError(t, err)
+	return ref.Hash()
+}
+
+// receivePack sends a push of commands carrying every object of src, the
+// way git does, and returns the raw response body.
+func receivePack(t *testing.T, url string, src storer.EncodedObjectStorer, commands ...*packp.Command) string {
+	var hashes []plumbing.Hash
+	iter, err := src.IterEncodedObjects(plumbing.AnyObject)
+	require.NoError(t, err)
+	require.NoError(t, iter.ForEach(func(obj plumbing.EncodedObject) error {
+		hashes = append(hashes, obj.Hash())
+		return nil
+	}))
+
+	var pack bytes.Buffer
+	_, err = packfile.NewEncoder(&pack, src, false).Encode(hashes, 10)
+	require.NoError(t, err)
+
+	req := packp.NewReferenceUpdateRequest()
+	require.NoError(t, req.Capabilities.Set(capability.ReportStatus))
+	req.Commands = commands
+	req.Packfile = io.NopCloser(&pack)
+
+	var body bytes.Buffer
+	require.NoError(t, req.Encode(&body))
+
+	httpReq, err := http.NewRequest(http.MethodPost, url+"/git-receive-pack", &body)
+	require.NoError(t, err)
+	httpReq.Header.Set("Content-Type", "application/x-git-receive-pack-request")
+	httpReq.SetBasicAuth("test", testPassword)
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-git-receive-pack-result", resp.Header.Get("Content-Type"))
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(data)
+}
+
+// --- Tests ---
+
+func TestSmartHTTP_PushCloneFetch(t *testing.T) {
+	_, domain, url := newSmartHTTPServer(t)
+
+	local := newClientRepo(t, url)
+	first := commitFile(t, local, "README.md", "hello")
+	push(t, local, "refs/heads/master:refs/heads/main")
+
+	assert.Equal(t, first, serverRef(t, domain, "refs/heads/main"))
+	require.Len(t, domain.pushed, 1)
+	assert.Equal(t, &inbound.GitRefUpdate{
+		Ref:    "refs/heads/main",
+		OldSHA: plumbing.ZeroHash.String(),
+		NewSHA: first.String(),
+	}, domain.pushed[0])
+
+	clone, err := gogit.Clone(memory.NewStorage(), nil, &gogit.CloneOptions{URL: url, Auth: testAuth()})
+	require.NoError(t, err)
+	head, err := clone.Head()
+	require.NoError(t, err)
+	assert.Equal(t, first, head.Hash())
+
+	second := commitFile(t, local, "README.md", "hello again")
+	push(t, local, "refs/heads/master:refs/heads/main")
+
+	err = clone.Fetch(&gogit.FetchOptions{Auth: testAuth()})
+	require.NoError(t, err)
+	fetched, err := clone.Reference(plumbing.NewRemoteReferenceName("origin", "main"), true)
+	require.NoError(t, err)
+	assert.Equal(t, second, fetched.Hash())
+	_, err = clone.CommitObject(second)
+	assert.NoError(t, err)
+}
+
+func TestSmartHTTP_InfoRefsRequiresAuth(t *testing.T) {
+	srv, _, url := newSmartHTTPServer(t)
+
+	resp, err := srv.Client().Get(url + "/info/refs?service=git-upload-pack")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "Basic")
+}
+
+func TestSmartHTTP_ReceivePackRejections(t *testing.T) {
+	setup := func(t *testing.T) (*fakeGitDomain, string, *gogit.Repository, plumbing.Hash, plumbing.Hash, plumbing.Hash) {
+		_, domain, url := newSmartHTTPServer(t)
+
+		local := newClientRepo(t, url)
+		base := commitFile(t, local, "a.txt", "1")
+		push(t, local, "refs/heads/master:refs/heads/main")
+		other := commitFile(t, local, "a.txt", "2")
+		push(t, local, "refs/heads/master:refs/heads/other")
+		next := commitFile(t, local, "a.txt", "3")
+
+		return domain, url, local, base, other, next
+	}
+
+	t.Run("stale_old_value", func(t *testing.T) {
+		domain, url, local, base, _, next := setup(t)
+
+		body := receivePack(t, url, local.Storer, &packp.Command{
+			Name: "refs/heads/main",
+			Old:  plumbing.NewHash(strings.Repeat("1", 40)),
+			New:  next,
+		})
+
+		assert.Contains(t, body, "ng refs/heads/main stale info")
+		assert.Equal(t, base, serverRef(t, domain, "refs/heads/main"))
+	})
+
+	t.Run("ref_moved_during_push", func(t *testing.T) {
+		domain, url, local, base, other, next := setup(t)
+
+		// Another push lands after the stale check but before the update
+		domain.checkPush = func(update *inbound.GitRefUpdate) error {
+			st := filesystem.NewStorage(domain.fs, cache.NewObjectLRUDefault())
+			return st.SetReference(plumbing.NewHashReference("refs/heads/main", other))
+		}
+
+		body := receivePack(t, url, local.Storer, &packp.Command{Name: "refs/heads/main", Old: base, New: next})
+
+		assert.Contains(t, body, "ng refs/heads/main stale info")
+		assert.Equal(t, other, serverRef(t, domain, "refs/heads/main"))
+		assert.Len(t, domain.pushed, 2) // the setup pushes only
+	})
+
+	t.Run("protected_branch", func(t *testing.T) {
+		domain, url, local, base, other, next := setup(t)
+
+		domain.checkPush = func(update *inbound.GitRefUpdate) error {
+			if update.Ref == "refs/heads/main" {
+				return errors.New("branch is protected")
+			}
+			return nil
+		}
+
+		body := receivePack(t, url, local.Storer,
+			&packp.Command{Name: "refs/heads/main", Old: base, New: next},
+			&packp.Command{Name: "refs/heads/other", Old: other, New: next},
+		)
+
+		assert.Contains(t, body, "ng refs/heads/main branch is protected")
+		assert.Contains(t, body, "ok refs/heads/other")
+		assert.Equal(t, base, serverRef(t, domain, "refs/heads/main"))
+		assert.Equal(t, next, serverRef(t, domain, "refs/heads/other"))
+	})
+}
@@ -1,8 +1,12 @@
 package mediahttp
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -29,6 +33,9 @@ func (h *Handler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerF
 	{
 		// Image generation
 		mediaGroup.POST("/images/generations", h.GenerateImage)
+		mediaGroup.POST("/images/edits", h.EditImage)
+		mediaGroup.POST("/images/variations", h.CreateImageVariation)
+		mediaGroup.POST("/images/upscales", h.UpscaleImage)
 
 		// Video generation
 		mediaGroup.POST("/videos/generations", h.GenerateVideo)
@@ -38,6 +45,15 @@ func (h *Handler) RegisterRoutes(r *gin.RouterGroup, authMiddleware gin.HandlerF
 		mediaGroup.GET("/tasks", h.ListTasks)
 		mediaGroup.GET("/tasks/:task_id", h.GetTask)
 		mediaGroup.DELETE("/tasks/:task_id", h.CancelTask)
+
+		// Asset library
+		mediaGroup.GET("/assets", h.ListAssets)
+		mediaGroup.GET("/assets/:asset_id", h.GetAsset)
+		mediaGroup.PATCH("/assets/:asset_id", h.UpdateAsset)
+		mediaGroup.DELETE("/assets/:asset_id", h.DeleteAsset)
+		mediaGroup.POST("/assets/:asset_id/restore", h.RestoreAsset)
+		mediaGroup.PUT("/assets/:asset_id/share", h.ShareAsset)
+		mediaGroup.DELETE("/assets/:asset_id/share", h.UnshareAsset)
 	}
 }
 
@@ -74,6 +90,72 @@ func (h *Handler) GenerateImage(c *gin.Context) {
 	c.JSON(http.StatusOK, output)
 }
 
+// EditImage handles image edit and inpainting requests.
+func (h *Handler) EditImage(c *gin.Context) {
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input inbound.MediaImageEditInput
+	if !bindImageRequest(c, &input, map[string]*[]byte{"image": &input.Image, "mask": &input.Mask}) {
+		return
+	}
+
+	output, err := h.domain.EditImage(c.Request.Context(), userID, &input)
+	if err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// CreateImageVariation handles image variation requests.
+func (h *Handler) CreateImageVariation(c *gin.Context) {
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input inbound.MediaImageVariationInput
+	if !bindImageRequest(c, &input, map[string]*[]byte{"image": &input.Image}) {
+		return
+	}
+
+	output, err := h.domain.CreateImageVariation(c.Request.Context(), userID, &input)
+	if err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// UpscaleImage handles image upscale requests.
+func (h *Handler) UpscaleImage(c *gin.Context) {
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input inbound.MediaImageUpscaleInput
+	if !bindImageRequest(c, &input, map[string]*[]byte{"image": &input.Image}) {
+		return
+	}
+
+	output, err := h.domain.UpscaleImage(c.Request.Context(), userID, &input)
+	if err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
 // GenerateVideo handles video generation requests.
 func (h *Handler) GenerateVideo(c *gin.Context) {
 	userID := getUserID(c)
@@ -182,6 +264,214 @@ func (h *Handler) CancelTask(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// ListAssets handles asset listing requests.
+func (h *Handler) ListAssets(c *gin.Context) {
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	input := &inbound.MediaAssetListInput{
+		Type:    model.MediaCapability(c.Query("type")),
+		Model:   c.Query("model"),
+		Query:   c.Query("q"),
+		Deleted: c.Query("deleted") == "true",
+		Limit:   limit,
+		Offset:  offset,
+	}
+
+	if teamID := c.Query("team_id"); teamID != "" {
+		id, err := uuid.Parse(teamID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid team id"})
+			return
+		}
+		input.TeamID = &id
+	}
+	if tags := c.Query("tags"); tags != "" {
+		input.Tags = strings.Split(tags, ",")
+	}
+	if folder, ok := c.GetQuery("folder"); ok {
+		input.Folder = &folder
+	}
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since time"})
+			return
+		}
+		input.Since = &t
+	}
+	if until := c.Query("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid until time"})
+			return
+		}
+		input.Until = &t
+	}
+
+	output, err := h.domain.ListAssets(c.Request.Context(), userID, input)
+	if err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// GetAsset handles asset retrieval requests.
+func (h *Handler) GetAsset(c *gin.Context) {
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	assetID, err := uuid.Parse(c.Param("asset_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid asset id"})
+		return
+	}
+
+	asset, err := h.domain.GetAsset(c.Request.Context(), userID, assetID)
+	if err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, asset)
+}
+
+// UpdateAsset handles asset metadata updates.
+func (h *Handler) UpdateAsset(c *gin.Context) {
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	assetID, err := uuid.Parse(c.Param("asset_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid asset id"})
+		return
+	}
+
+	var input inbound.MediaAssetUpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	asset, err := h.domain.UpdateAsset(c.Request.Context(), userID, assetID, &input)
+	if err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, asset)
+}
+
+// ShareAsset handles sharing an asset with a team.
+func (h *Handler) ShareAsset(c *gin.Context) {
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	assetID, err := uuid.Parse(c.Param("asset_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid asset id"})
+		return
+	}
+
+	var input inbound.MediaAssetShareInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	asset, err := h.domain.ShareAsset(c.Request.Context(), userID, assetID, &input.TeamID)
+	if err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, asset)
+}
+
+// UnshareAsset handles removing an asset from its team.
+func (h *Handler) UnshareAsset(c *gin.Context) {
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	assetID, err := uuid.Parse(c.Param("asset_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid asset id"})
+		return
+	}
+
+	asset, err := h.domain.ShareAsset(c.Request.Context(), userID, assetID, nil)
+	if err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, asset)
+}
+
+// DeleteAsset handles moving an asset to the trash.
+func (h *Handler) DeleteAsset(c *gin.Context) {
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	assetID, err := uuid.Parse(c.Param("asset_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid asset id"})
+		return
+	}
+
+	if err := h.domain.DeleteAsset(c.Request.Context(), userID, assetID); err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreAsset handles restoring an asset from the trash.
+func (h *Handler) RestoreAsset(c *gin.Context) {
+	userID := getUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	assetID, err := uuid.Parse(c.Param("asset_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid asset id"})
+		return
+	}
+
+	asset, err := h.domain.RestoreAsset(c.Request.Context(), userID, assetID)
+	if err != nil {
+		handleMediaError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, asset)
+}
+
 // ListProviders handles provider listing requests.
 func (h *Handler) ListProviders(c *gin.Context) {
 	providers, err := h.domain.ListProviders(c.Request.Context())
@@ -249,9 +539,9 @@ func (h *Handler) GetModelsByCapability(c *gin.Context) {
 // handleMediaError handles media domain errors.
 func handleMediaError(c *gin.Context, err error) {
 	switch err {
-	case media.ErrProviderNotFound, media.ErrModelNotFound, media.ErrTaskNotFound:
+	case media.ErrProviderNotFound, media.ErrModelNotFound, media.ErrTaskNotFound, media.ErrAssetNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-	case media.ErrTaskNotOwned:
+	case media.ErrTaskNotOwned, media.ErrAssetAccessDenied:
 		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 	case media.ErrInvalidInput:
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -259,13 +549,63 @@ func handleMediaError(c *gin.Context, err error) {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
 	case media.ErrCapabilityNotSupported:
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-	case media.ErrTaskAlreadyCompleted, media.ErrTaskAlreadyCancelled:
+	case media.ErrTaskAlreadyCompleted, media.ErrTaskAlreadyCancelled, media.ErrAssetNotDeleted:
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case media.ErrVideoQuotaExceeded, media.ErrStorageQuotaExceeded:
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
 	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 	}
 }
 
+// maxImageUploadBytes bounds a multipart image request body. The domain
+// enforces its own per-image limit.
+const maxImageUploadBytes = 64 << 20
+
+// bindImageRequest binds a JSON body that references stored assets, or a
+// multipart form with uploaded files read into files by field name. It
+// writes the error response and returns false on failure.
+func bindImageRequest(c *gin.Context, input any, files map[string]*[]byte) bool {
+	if c.ContentType() != "multipart/form-data" {
+		if err := c.ShouldBindJSON(input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+		return true
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImageUploadBytes)
+	if err := c.ShouldBind(input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	for field, dst := range files {
+		header, err := c.FormFile(field)
+		if errors.Is(err, http.ErrMissingFile) {
+			continue
+		}
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+
+		file, err := header.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return false
+		}
+		*dst = data
+	}
+	return true
+}
+
 func getUserID(c *gin.Context) uuid.UUID {
 	if userID, exists := c.Get("user_id"); exists {
 		if id, ok := userID.(uuid.UUID); ok {
@@ -0,0 +1,332 @@
+package taskhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/net/websocket"
+
+	"github.com/uniedit/server/internal/domain/media"
+	"github.com/uniedit/server/internal/infra/task"
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/utils/middleware"
+)
+
+const (
+	// taskPollInterval is how often a watched task is re-read when there is
+	// no event bus.
+	taskPollInterval = 2 * time.Second
+	// taskReconcileInterval is how often a watched task is re-read anyway,
+	// in case an event was lost. Unchanged reads keep the stream alive.
+	taskReconcileInterval = 15 * time.Second
+	// maxSocketSubscriptions caps the tasks one socket watches at once.
+	maxSocketSubscriptions = 100
+)
+
+// taskSocketRequest is a message from a task events socket client.
+type taskSocketRequest struct {
+	Action string `json:"action"` // subscribe or unsubscribe
+	TaskID string `json:"task_id"`
+}
+
+// taskSocketMessage is a message to a task events socket client.
+type taskSocketMessage struct {
+	Type   string           `json:"type"` // event or error
+	TaskID string           `json:"task_id"`
+	Event  *model.TaskEvent `json:"event,omitempty"`
+	Error  string           `json:"error,omitempty"`
+}
+
+// socketWatch is one task watched over a socket.
+type socketWatch struct {
+	stop context.CancelFunc
+}
+
+// StreamTaskEvents handles GET /tasks/:id/events.
+// It sends the task's state as server-sent events, starting with the
+// current state, until the task finishes.
+func (h *Handler) StreamTaskEvents(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	started := false
+	err = h.watchTask(ctx, userID, id, func(event *model.TaskEvent) bool {
+		if !started {
+			c.Header("Content-Type", "text/event-stream")
+			c.Header("Cache-Control", "no-cache")
+			c.Header("Connection", "keep-alive")
+			c.Header("X-Accel-Buffering", "no")
+			c.Status(http.StatusOK)
+			started = true
+		}
+
+		if event == nil {
+			fmt.Fprint(c.Writer, ": keepalive\n\n")
+		} else {
+			data, err := json.Marshal(event)
+			if err != nil {
+				return false
+			}
+			fmt.Fprintf(c.Writer, "event: task\ndata: %s\n\n", data)
+		}
+		c.Writer.Flush()
+		return ctx.Err() == nil
+	})
+	if err == nil {
+		return
+	}
+
+	if !started {
+		handleTaskError(c, err)
+		return
+	}
+	if ctx.Err() == nil {
+		data, _ := json.Marshal(gin.H{"error": err.Error()})
+		fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", data)
+		c.Writer.Flush()
+	}
+}
+
+// TaskEventsSocket handles GET /tasks/events.
+// After the WebSocket upgrade the client sends subscribe and unsubscribe
+// messages with a task ID, and receives the current state of each
+// subscribed task followed by its changes. A subscription ends by itself
+// once its task finishes.
+func (h *Handler) TaskEventsSocket(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	// Clients authenticate with a bearer token rather than a cookie, so
+	// connections from other origins are accepted.
+	server := websocket.Server{
+		Handler: func(conn *websocket.Conn) {
+			h.serveTaskSocket(conn, userID)
+		},
+	}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+// serveTaskSocket reads subscription requests until the client disconnects.
+// Messages to the client are written by a single goroutine.
+func (h *Handler) serveTaskSocket(conn *websocket.Conn, userID uuid.UUID) {
+	ctx, cancel := context.WithCancel(conn.Request().Context())
+	defer cancel()
+
+	out := make(chan *taskSocketMessage, 32)
+	go func() {
+		defer cancel()
+		for {
+			select {
+			case msg := <-out:
+				if err := websocket.JSON.Send(conn, msg); err != nil {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	send := func(msg *taskSocketMessage) bool {
+		select {
+		case out <- msg:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var mu sync.Mutex
+	watching := make(map[uuid.UUID]*socketWatch)
+
+	for {
+		var req taskSocketRequest
+		if err := websocket.JSON.Receive(conn, &req); err != nil {
+			return
+		}
+
+		taskID, err := uuid.Parse(req.TaskID)
+		if err != nil {
+			send(&taskSocketMessage{Type: "error", TaskID: req.TaskID, Error: "invalid task id"})
+			continue
+		}
+
+		mu.Lock()
+		current := watching[taskID]
+		switch req.Action {
+		case "subscribe":
+			if current != nil {
+				break
+			}
+			if len(watching) >= maxSocketSubscriptions {
+				send(&taskSocketMessage{Type: "error", TaskID: req.TaskID, Error: "too many subscriptions"})
+				break
+			}
+
+			watchCtx, stop := context.WithCancel(ctx)
+			w := &socketWatch{stop: stop}
+			watching[taskID] = w
+			go func() {
+				defer func() {
+					stop()
+					mu.Lock()
+					if watching[taskID] == w {
+						delete(watching, taskID)
+					}
+					mu.Unlock()
+				}()
+
+				err := h.watchTask(watchCtx, userID, taskID, func(event *model.TaskEvent) bool {
+					if event == nil {
+						return true
+					}
+					return send(&taskSocketMessage{Type: "event", TaskID: req.TaskID, Event: event})
+				})
+				if err != nil && watchCtx.Err() == nil {
+					send(&taskSocketMessage{Type: "error", TaskID: req.TaskID, Error: err.Error()})
+				}
+			}()
+		case "unsubscribe":
+			if current != nil {
+				current.stop()
+				delete(watching, taskID)
+			}
+		default:
+			send(&taskSocketMessage{Type: "error", TaskID: req.TaskID, Error: "unknown action"})
+		}
+		mu.Unlock()
+	}
+}
+
+// watchTask calls send with the task's current state and then with each
+// change, until the task finishes, ctx is done or send returns false. send
+// gets nil when a re-read finds nothing new, so streams can keep the
+// connection alive. An error finding the task is returned before send is
+// first called.
+func (h *Handler) watchTask(ctx context.Context, userID, taskID uuid.UUID, send func(*model.TaskEvent) bool) error {
+	// Subscribe before reading the task so no change in between is missed.
+	var events <-chan *model.TaskEvent
+	interval := h.pollInterval
+	if h.events != nil {
+		ch, unsubscribe, err := h.events.Subscribe(ctx, taskID)
+		if err != nil {
+			return err
+		}
+		defer unsubscribe()
+		events = ch
+		interval = h.reconcileInterval
+	}
+
+	current, err := h.loadTaskEvent(ctx, userID, taskID)
+	if err != nil {
+		return err
+	}
+	if !send(current) || current.IsTerminal() {
+		return nil
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var next *model.TaskEvent
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				// The bus went away; fall back to polling.
+				events = nil
+				ticker.Reset(h.pollInterval)
+				continue
+			}
+			if !taskEventChanged(current, event) {
+				continue
+			}
+			next = event
+		case <-ticker.C:
+			event, err := h.loadTaskEvent(ctx, userID, taskID)
+			if err != nil {
+				return err
+			}
+			if taskEventChanged(current, event) {
+				next = event
+			}
+		}
+
+		if next == nil {
+			if !send(nil) {
+				return nil
+			}
+			continue
+		}
+		if !send(next) || next.IsTerminal() {
+			return nil
+		}
+		current = next
+	}
+}
+
+// loadTaskEvent returns the current state of a task the user owns, looking
+// first in the task manager's store and then in media tasks.
+func (h *Handler) loadTaskEvent(ctx context.Context, userID, taskID uuid.UUID) (*model.TaskEvent, error) {
+	t, err := h.manager.Get(ctx, taskID)
+	if err == nil {
+		if t.OwnerID != userID {
+			return nil, task.ErrTaskNotFound
+		}
+		return t.Event(), nil
+	}
+	if !errors.Is(err, task.ErrTaskNotFound) || h.media == nil {
+		return nil, err
+	}
+
+	mediaTask, err := h.media.GetTask(ctx, userID, taskID)
+	if errors.Is(err, media.ErrTaskNotFound) || errors.Is(err, media.ErrTaskNotOwned) {
+		return nil, task.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &model.TaskEvent{
+		TaskID:    mediaTask.ID,
+		OwnerID:   mediaTask.OwnerID,
+		Source:    model.TaskEventSourceMedia,
+		Type:      mediaTask.Type,
+		Status:    string(mediaTask.Status),
+		Progress:  mediaTask.Progress,
+		Error:     mediaTask.Error,
+		UpdatedAt: time.Unix(mediaTask.UpdatedAt, 0),
+	}, nil
+}
+
+// taskEventChanged reports whether next is newer than what was last sent.
+// Events published just before the task was first read arrive after it
+// and are skipped.
+func taskEventChanged(current, next *model.TaskEvent) bool {
+	if next.UpdatedAt.Before(current.UpdatedAt) {
+		return false
+	}
+	return next.UpdatedAt.After(current.UpdatedAt) ||
+		next.Status != current.Status ||
+		next.Progress != current.Progress
+}
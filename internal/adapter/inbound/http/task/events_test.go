@@ -0,0 +1,553 @@
+package taskhttp
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/uniedit/server/internal/domain/media"
+	"github.com/uniedit/server/internal/infra/task"
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+	"github.com/uniedit/server/internal/utils/middleware"
+)
+
+// --- Fakes ---
+
+// callLog records the order of calls across fakes.
+type callLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *callLog) add(call string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, call)
+}
+
+func (l *callLog) list() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.calls...)
+}
+
+// fakeTaskRepo serves tasks from memory to a task manager.
+type fakeTaskRepo struct {
+	task.Repository
+	log   *callLog
+	mu    sync.Mutex
+	tasks map[uuid.UUID]*task.Task
+	err   error
+}
+
+func (r *fakeTaskRepo) Get(ctx context.Context, id uuid.UUID) (*task.Task, error) {
+	r.log.add("get")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	t, ok := r.tasks[id]
+	if !ok {
+		return nil, task.ErrTaskNotFound
+	}
+	copied := *t
+	return &copied, nil
+}
+
+func (r *fakeTaskRepo) put(t *task.Task) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tasks[t.ID] = t
+}
+
+// fakeTaskEvents is an in-memory task event bus.
+type fakeTaskEvents struct {
+	log          *callLog
+	mu           sync.Mutex
+	channels     map[uuid.UUID]chan *model.TaskEvent
+	unsubscribed map[uuid.UUID]bool
+	err          error
+}
+
+func (e *fakeTaskEvents) Publish(ctx context.Context, event *model.TaskEvent) error {
+	e.channel(event.TaskID) <- event
+	return nil
+}
+
+func (e *fakeTaskEvents) Subscribe(ctx context.Context, taskID uuid.UUID) (<-chan *model.TaskEvent, func(), error) {
+	e.log.add("subscribe")
+	if e.err != nil {
+		return nil, nil, e.err
+	}
+	unsubscribe := func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.unsubscribed[taskID] = true
+	}
+	return e.channel(taskID), unsubscribe, nil
+}
+
+func (e *fakeTaskEvents) channel(taskID uuid.UUID) chan *model.TaskEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	ch, ok := e.channels[taskID]
+	if !ok {
+		ch = make(chan *model.TaskEvent, 16)
+		e.channels[taskID] = ch
+	}
+	return ch
+}
+
+func (e *fakeTaskEvents) isUnsubscribed(taskID uuid.UUID) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.unsubscribed[taskID]
+}
+
+// fakeMediaDomain serves media tasks from memory.
+type fakeMediaDomain struct {
+	inbound.MediaDomain
+	tasks map[uuid.UUID]*inbound.MediaTaskOutput
+}
+
+func (d *fakeMediaDomain) GetTask(ctx context.Context, userID, taskID uuid.UUID) (*inbound.MediaTaskOutput, error) {
+	t, ok := d.tasks[taskID]
+	if !ok {
+		return nil, media.ErrTaskNotFound
+	}
+	if t.OwnerID != userID {
+		return nil, media.ErrTaskNotOwned
+	}
+	return t, nil
+}
+
+// --- Helpers ---
+
+type eventsFixture struct {
+	handler *Handler
+	repo    *fakeTaskRepo
+	events  *fakeTaskEvents
+	media   *fakeMediaDomain
+	log     *callLog
+	userID  uuid.UUID
+}
+
+func newEventsFixture() *eventsFixture {
+	log := &callLog{}
+	f := &eventsFixture{
+		repo: &fakeTaskRepo{log: log, tasks: make(map[uuid.UUID]*task.Task)},
+		events: &fakeTaskEvents{
+			log:          log,
+			channels:     make(map[uuid.UUID]chan *model.TaskEvent),
+			unsubscribed: make(map[uuid.UUID]bool),
+		},
+		media:  &fakeMediaDomain{tasks: make(map[uuid.UUID]*inbound.MediaTaskOutput)},
+		log:    log,
+		userID: uuid.New(),
+	}
+	f.handler = NewHandler(task.NewManager(f.repo, nil, nil), f.media, f.events)
+	return f
+}
+
+// addTask stores a running task owned by the fixture's user.
+func (f *eventsFixture) addTask(progress int) *task.Task {
+	t := &task.Task{
+		ID:        uuid.New(),
+		OwnerID:   f.userID,
+		Type:      "test",
+		Status:    task.StatusRunning,
+		Progress:  progress,
+		UpdatedAt: time.Now(),
+	}
+	f.repo.put(t)
+	return t
+}
+
+func eventFor(t *task.Task, status task.Status, progress int, at time.Time) *model.TaskEvent {
+	event := t.Event()
+	event.Status = string(status)
+	event.Progress = progress
+	event.UpdatedAt = at
+	return event
+}
+
+// watch runs watchTask in the background and returns the events it sends.
+func (f *eventsFixture) watch(ctx context.Context, taskID uuid.UUID) (<-chan *model.TaskEvent, <-chan error) {
+	sent := make(chan *model.TaskEvent, 16)
+	done := make(chan error, 1)
+	go func() {
+		done <- f.handler.watchTask(ctx, f.userID, taskID, func(event *model.TaskEvent) bool {
+			sent <- event
+			return true
+		})
+	}()
+	return sent, done
+}
+
+func receive[T any](t *testing.T, ch <-chan T) T {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("timed out")
+		var zero T
+		return zero
+	}
+}
+
+// receiveChange returns the next event sent, skipping keepalives.
+func receiveChange(t *testing.T, sent <-chan *model.TaskEvent) *model.TaskEvent {
+	t.Helper()
+	for {
+		if event := receive(t, sent); event != nil {
+			return event
+		}
+	}
+}
+
+// --- watchTask ---
+
+func TestWatchTask_SubscribesBeforeReading(t *testing.T) {
+	f := newEventsFixture()
+	tk := f.addTask(0)
+	tk.Status = task.StatusCompleted
+
+	sent, done := f.watch(context.Background(), tk.ID)
+
+	assert.Equal(t, string(task.StatusCompleted), receive(t, sent).Status)
+	assert.NoError(t, receive(t, done))
+	assert.Equal(t, []string{"subscribe", "get"}, f.log.list())
+	assert.True(t, f.events.isUnsubscribed(tk.ID))
+}
+
+func TestWatchTask_StreamsChangesUntilTerminal(t *testing.T) {
+	f := newEventsFixture()
+	tk := f.addTask(10)
+	ch := f.events.channel(tk.ID)
+
+	sent, done := f.watch(context.Background(), tk.ID)
+	assert.Equal(t, 10, receive(t, sent).Progress)
+
+	// Published before the read, and a repeat of the current state
+	ch <- eventFor(tk, task.StatusRunning, 5, tk.UpdatedAt.Add(-time.Second))
+	ch <- eventFor(tk, task.StatusRunning, 10, tk.UpdatedAt)
+	ch <- eventFor(tk, task.StatusRunning, 50, tk.UpdatedAt.Add(time.Second))
+	ch <- eventFor(tk, task.StatusCompleted, 100, tk.UpdatedAt.Add(2*time.Second))
+
+	assert.Equal(t, 50, receive(t, sent).Progress)
+	assert.Equal(t, string(task.StatusCompleted), receive(t, sent).Status)
+	assert.NoError(t, receive(t, done))
+	assert.Empty(t, sent)
+}
+
+func TestWatchTask_ReconcilesMissedEvents(t *testing.T) {
+	f := newEventsFixture()
+	f.handler.reconcileInterval = 10 * time.Millisecond
+	tk := f.addTask(10)
+
+	sent, done := f.watch(context.Background(), tk.ID)
+	assert.Equal(t, 10, receive(t, sent).Progress)
+
+	// Unchanged reads keep the stream alive
+	assert.Nil(t, receive(t, sent))
+
+	updated := *tk
+	updated.Status = task.StatusFailed
+	updated.UpdatedAt = tk.UpdatedAt.Add(time.Second)
+	f.repo.put(&updated)
+
+	assert.Equal(t, string(task.StatusFailed), receiveChange(t, sent).Status)
+	assert.NoError(t, receive(t, done))
+}
+
+func TestWatchTask_FallsBackToPollingWhenBusCloses(t *testing.T) {
+	f := newEventsFixture()
+	f.handler.pollInterval = 10 * time.Millisecond
+	f.handler.reconcileInterval = time.Hour
+	tk := f.addTask(10)
+
+	sent, done := f.watch(context.Background(), tk.ID)
+	assert.Equal(t, 10, receive(t, sent).Progress)
+
+	close(f.events.channel(tk.ID))
+	updated := *tk
+	updated.Status = task.StatusCompleted
+	updated.UpdatedAt = tk.UpdatedAt.Add(time.Second)
+	f.repo.put(&updated)
+
+	assert.Equal(t, string(task.StatusCompleted), receiveChange(t, sent).Status)
+	assert.NoError(t, receive(t, done))
+}
+
+func TestWatchTask_PollsWithoutEventBus(t *testing.T) {
+	f := newEventsFixture()
+	f.handler.events = nil
+	f.handler.pollInterval = 10 * time.Millisecond
+	tk := f.addTask(10)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sent, done := f.watch(ctx, tk.ID)
+	assert.Equal(t, 10, receive(t, sent).Progress)
+
+	updated := *tk
+	updated.Progress = 60
+	f.repo.put(&updated)
+
+	assert.Equal(t, 60, receiveChange(t, sent).Progress)
+	assert.NotContains(t, f.log.list(), "subscribe")
+
+	cancel()
+	assert.NoError(t, receive(t, done))
+}
+
+func TestWatchTask_Errors(t *testing.T) {
+	t.Run("subscribe fails", func(t *testing.T) {
+		f := newEventsFixture()
+		tk := f.addTask(0)
+		f.events.err = errors.New("bus down")
+
+		_, done := f.watch(context.Background(), tk.ID)
+
+		assert.EqualError(t, receive(t, done), "bus down")
+	})
+
+	t.Run("task not found", func(t *testing.T) {
+		f := newEventsFixture()
+
+		sent, done := f.watch(context.Background(), uuid.New())
+
+		assert.ErrorIs(t, receive(t, done), task.ErrTaskNotFound)
+		assert.Empty(t, sent)
+	})
+
+	t.Run("stops when context is done", func(t *testing.T) {
+		f := newEventsFixture()
+		tk := f.addTask(0)
+		ctx, cancel := context.WithCancel(context.Background())
+
+		sent, done := f.watch(ctx, tk.ID)
+		receive(t, sent)
+		cancel()
+
+		assert.NoError(t, receive(t, done))
+	})
+}
+
+// --- loadTaskEvent ---
+
+func TestLoadTaskEvent(t *testing.T) {
+	t.Run("task owned by the user", func(t *testing.T) {
+		f := newEventsFixture()
+		tk := f.addTask(30)
+
+		event, err := f.handler.loadTaskEvent(context.Background(), f.userID, tk.ID)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.TaskEventSourceTask, event.Source)
+		assert.Equal(t, 30, event.Progress)
+	})
+
+	t.Run("task owned by someone else", func(t *testing.T) {
+		f := newEventsFixture()
+		tk := f.addTask(30)
+
+		_, err := f.handler.loadTaskEvent(context.Background(), uuid.New(), tk.ID)
+
+		assert.ErrorIs(t, err, task.ErrTaskNotFound)
+	})
+
+	t.Run("media task", func(t *testing.T) {
+		f := newEventsFixture()
+		id := uuid.New()
+		f.media.tasks[id] = &inbound.MediaTaskOutput{
+			ID:        id,
+			OwnerID:   f.userID,
+			Type:      "video_generation",
+			Status:    model.MediaTaskStatusRunning,
+			Progress:  40,
+			UpdatedAt: 1700000000,
+		}
+
+		event, err := f.handler.loadTaskEvent(context.Background(), f.userID, id)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.TaskEventSourceMedia, event.Source)
+		assert.Equal(t, "running", event.Status)
+		assert.Equal(t, 40, event.Progress)
+		assert.Equal(t, time.Unix(1700000000, 0), event.UpdatedAt)
+	})
+
+	t.Run("media task owned by someone else", func(t *testing.T) {
+		f := newEventsFixture()
+		id := uuid.New()
+		f.media.tasks[id] = &inbound.MediaTaskOutput{ID: id, OwnerID: uuid.New()}
+
+		_, err := f.handler.loadTaskEvent(context.Background(), f.userID, id)
+
+		assert.ErrorIs(t, err, task.ErrTaskNotFound)
+	})
+
+	t.Run("missing everywhere", func(t *testing.T) {
+		f := newEventsFixture()
+
+		_, err := f.handler.loadTaskEvent(context.Background(), f.userID, uuid.New())
+
+		assert.ErrorIs(t, err, task.ErrTaskNotFound)
+	})
+
+	t.Run("store error is not masked by media lookup", func(t *testing.T) {
+		f := newEventsFixture()
+		f.repo.err = errors.New("db down")
+
+		_, err := f.handler.loadTaskEvent(context.Background(), f.userID, uuid.New())
+
+		assert.EqualError(t, err, "db down")
+	})
+}
+
+func TestTaskEventChanged(t *testing.T) {
+	now := time.Now()
+	current := &model.TaskEvent{Status: "running", Progress: 10, UpdatedAt: now}
+
+	tests := []struct {
+		name string
+		next *model.TaskEvent
+		want bool
+	}{
+		{"same state", &model.TaskEvent{Status: "running", Progress: 10, UpdatedAt: now}, false},
+		{"older", &model.TaskEvent{Status: "completed", Progress: 100, UpdatedAt: now.Add(-time.Second)}, false},
+		{"newer", &model.TaskEvent{Status: "running", Progress: 10, UpdatedAt: now.Add(time.Second)}, true},
+		{"progress at same time", &model.TaskEvent{Status: "running", Progress: 20, UpdatedAt: now}, true},
+		{"status at same time", &model.TaskEvent{Status: "completed", Progress: 10, UpdatedAt: now}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, taskEventChanged(current, tt.next))
+		})
+	}
+}
+
+// --- Socket ---
+
+// dialTaskSocket serves the fixture's handler and connects to its socket.
+func dialTaskSocket(t *testing.T, f *eventsFixture) *websocket.Conn {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, f.userID)
+	})
+	f.handler.RegisterRoutes(r.Group(""))
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	conn, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/tasks/events", "", srv.URL)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func sendSocket(t *testing.T, conn *websocket.Conn, action, taskID string) {
+	t.Helper()
+	require.NoError(t, websocket.JSON.Send(conn, &taskSocketRequest{Action: action, TaskID: taskID}))
+}
+
+func receiveSocket(t *testing.T, conn *websocket.Conn) *taskSocketMessage {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	var msg taskSocketMessage
+	require.NoError(t, websocket.JSON.Receive(conn, &msg))
+	return &msg
+}
+
+func TestTaskEventsSocket_Subscribe(t *testing.T) {
+	f := newEventsFixture()
+	tk := f.addTask(10)
+	conn := dialTaskSocket(t, f)
+
+	sendSocket(t, conn, "subscribe", tk.ID.String())
+	msg := receiveSocket(t, conn)
+	assert.Equal(t, "event", msg.Type)
+	assert.Equal(t, tk.ID.String(), msg.TaskID)
+	assert.Equal(t, 10, msg.Event.Progress)
+
+	// A repeated subscribe does not watch the task twice. The invalid
+	// request after it is answered once the subscribe was handled.
+	sendSocket(t, conn, "subscribe", tk.ID.String())
+	sendSocket(t, conn, "subscribe", "")
+	assert.Equal(t, "invalid task id", receiveSocket(t, conn).Error)
+	f.events.channel(tk.ID) <- eventFor(tk, task.StatusCompleted, 100, tk.UpdatedAt.Add(time.Second))
+	msg = receiveSocket(t, conn)
+	assert.Equal(t, "completed", msg.Event.Status)
+
+	assert.Eventually(t, func() bool { return f.events.isUnsubscribed(tk.ID) }, time.Second, 5*time.Millisecond)
+}
+
+func TestTaskEventsSocket_Unsubscribe(t *testing.T) {
+	f := newEventsFixture()
+	tk := f.addTask(10)
+	other := f.addTask(0)
+	conn := dialTaskSocket(t, f)
+
+	sendSocket(t, conn, "subscribe", tk.ID.String())
+	receiveSocket(t, conn)
+	sendSocket(t, conn, "unsubscribe", tk.ID.String())
+	assert.Eventually(t, func() bool { return f.events.isUnsubscribed(tk.ID) }, time.Second, 5*time.Millisecond)
+
+	// Only the other task's events arrive from here on
+	f.events.channel(tk.ID) <- eventFor(tk, task.StatusRunning, 90, tk.UpdatedAt.Add(time.Second))
+	sendSocket(t, conn, "subscribe", other.ID.String())
+	msg := receiveSocket(t, conn)
+	assert.Equal(t, other.ID.String(), msg.TaskID)
+}
+
+func TestTaskEventsSocket_Errors(t *testing.T) {
+	f := newEventsFixture()
+	conn := dialTaskSocket(t, f)
+
+	sendSocket(t, conn, "subscribe", "not-a-uuid")
+	msg := receiveSocket(t, conn)
+	assert.Equal(t, "error", msg.Type)
+	assert.Equal(t, "invalid task id", msg.Error)
+
+	sendSocket(t, conn, "watch", uuid.NewString())
+	msg = receiveSocket(t, conn)
+	assert.Equal(t, "unknown action", msg.Error)
+
+	missing := uuid.NewString()
+	sendSocket(t, conn, "subscribe", missing)
+	msg = receiveSocket(t, conn)
+	assert.Equal(t, "error", msg.Type)
+	assert.Equal(t, missing, msg.TaskID)
+	assert.Equal(t, task.ErrTaskNotFound.Error(), msg.Error)
+}
+
+func TestTaskEventsSocket_SubscriptionLimit(t *testing.T) {
+	f := newEventsFixture()
+	conn := dialTaskSocket(t, f)
+
+	for range maxSocketSubscriptions {
+		tk := f.addTask(0)
+		sendSocket(t, conn, "subscribe", tk.ID.String())
+		assert.Equal(t, "event", receiveSocket(t, conn).Type)
+	}
+
+	tk := f.addTask(0)
+	sendSocket(t, conn, "subscribe", tk.ID.String())
+	msg := receiveSocket(t, conn)
+	assert.Equal(t, "error", msg.Type)
+	assert.Equal(t, "too many subscriptions", msg.Error)
+}
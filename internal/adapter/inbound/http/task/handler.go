@@ -0,0 +1,105 @@
+package taskhttp
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/infra/task"
+	"github.com/uniedit/server/internal/port/inbound"
+	"github.com/uniedit/server/internal/port/outbound"
+)
+
+// Handler handles task HTTP requests.
+type Handler struct {
+	manager *task.Manager
+	media   inbound.MediaDomain
+	events  outbound.TaskEventPort // nil: watched tasks are polled
+
+	pollInterval      time.Duration
+	reconcileInterval time.Duration
+}
+
+// NewHandler creates a new task handler.
+func NewHandler(manager *task.Manager, media inbound.MediaDomain, events outbound.TaskEventPort) *Handler {
+	return &Handler{
+		manager:           manager,
+		media:             media,
+		events:            events,
+		pollInterval:      taskPollInterval,
+		reconcileInterval: taskReconcileInterval,
+	}
+}
+
+// RegisterRoutes registers task routes for authenticated users.
+func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	taskGroup := r.Group("/tasks")
+	{
+		taskGroup.GET("/events", h.TaskEventsSocket)
+		taskGroup.GET("/:id/events", h.StreamTaskEvents)
+	}
+}
+
+// RegisterAdminRoutes registers task admin routes.
+func (h *Handler) RegisterAdminRoutes(r *gin.RouterGroup) {
+	taskGroup := r.Group("/admin/tasks")
+	{
+		taskGroup.GET("/dead", h.ListDeadTasks)
+		taskGroup.POST("/:id/requeue", h.RequeueTask)
+	}
+}
+
+// ListDeadTasks handles GET /admin/tasks/dead.
+func (h *Handler) ListDeadTasks(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	filter := &task.Filter{
+		Limit:  min(max(limit, 1), 100),
+		Offset: max(offset, 0),
+	}
+	if taskType := c.Query("type"); taskType != "" {
+		filter.Type = &taskType
+	}
+
+	tasks, err := h.manager.ListDead(c.Request.Context(), filter)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, tasks)
+}
+
+// RequeueTask handles POST /admin/tasks/:id/requeue.
+func (h *Handler) RequeueTask(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+		return
+	}
+
+	t, err := h.manager.Requeue(c.Request.Context(), id)
+	if err != nil {
+		handleTaskError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, t)
+}
+
+// handleTaskError handles task manager errors.
+func handleTaskError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, task.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, task.ErrTaskNotDead):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,245 @@
+package webhookhttp
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/domain/webhook"
+	"github.com/uniedit/server/internal/port/inbound"
+	"github.com/uniedit/server/internal/utils/middleware"
+)
+
+// Handler handles webhook endpoint HTTP requests.
+type Handler struct {
+	domain inbound.WebhookDomain
+}
+
+// NewHandler creates a new webhook endpoint handler.
+func NewHandler(domain inbound.WebhookDomain) *Handler {
+	return &Handler{domain: domain}
+}
+
+// RegisterRoutes registers webhook endpoint routes for authenticated users.
+func (h *Handler) RegisterRoutes(r *gin.RouterGroup) {
+	endpoints := r.Group("/webhook-endpoints")
+	{
+		endpoints.POST("", h.CreateEndpoint)
+		endpoints.GET("", h.ListEndpoints)
+		endpoints.GET("/:id", h.GetEndpoint)
+		endpoints.PATCH("/:id", h.UpdateEndpoint)
+		endpoints.DELETE("/:id", h.DeleteEndpoint)
+		endpoints.POST("/:id/rotate-secret", h.RotateSecret)
+
+		// Delivery log
+		endpoints.GET("/:id/deliveries", h.ListDeliveries)
+		endpoints.GET("/:id/deliveries/:delivery_id", h.GetDelivery)
+		endpoints.POST("/:id/deliveries/:delivery_id/redeliver", h.Redeliver)
+	}
+}
+
+// CreateEndpoint handles POST /webhook-endpoints.
+// The signing secret is only returned in this response and on rotation.
+func (h *Handler) CreateEndpoint(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var input inbound.WebhookEndpointCreateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	output, err := h.domain.CreateEndpoint(c.Request.Context(), userID, &input)
+	if err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, output)
+}
+
+// ListEndpoints handles GET /webhook-endpoints.
+func (h *Handler) ListEndpoints(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	endpoints, err := h.domain.ListEndpoints(c.Request.Context(), userID)
+	if err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"endpoints": endpoints})
+}
+
+// GetEndpoint handles GET /webhook-endpoints/:id.
+func (h *Handler) GetEndpoint(c *gin.Context) {
+	userID, endpointID, ok := parseEndpointRequest(c)
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.domain.GetEndpoint(c.Request.Context(), userID, endpointID)
+	if err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// UpdateEndpoint handles PATCH /webhook-endpoints/:id.
+func (h *Handler) UpdateEndpoint(c *gin.Context) {
+	userID, endpointID, ok := parseEndpointRequest(c)
+	if !ok {
+		return
+	}
+
+	var input inbound.WebhookEndpointUpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	endpoint, err := h.domain.UpdateEndpoint(c.Request.Context(), userID, endpointID, &input)
+	if err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, endpoint)
+}
+
+// DeleteEndpoint handles DELETE /webhook-endpoints/:id.
+func (h *Handler) DeleteEndpoint(c *gin.Context) {
+	userID, endpointID, ok := parseEndpointRequest(c)
+	if !ok {
+		return
+	}
+
+	if err := h.domain.DeleteEndpoint(c.Request.Context(), userID, endpointID); err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RotateSecret handles POST /webhook-endpoints/:id/rotate-secret.
+func (h *Handler) RotateSecret(c *gin.Context) {
+	userID, endpointID, ok := parseEndpointRequest(c)
+	if !ok {
+		return
+	}
+
+	output, err := h.domain.RotateSecret(c.Request.Context(), userID, endpointID)
+	if err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// ListDeliveries handles GET /webhook-endpoints/:id/deliveries.
+func (h *Handler) ListDeliveries(c *gin.Context) {
+	userID, endpointID, ok := parseEndpointRequest(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+
+	output, err := h.domain.ListDeliveries(c.Request.Context(), userID, endpointID, limit, offset)
+	if err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, output)
+}
+
+// GetDelivery handles GET /webhook-endpoints/:id/deliveries/:delivery_id.
+func (h *Handler) GetDelivery(c *gin.Context) {
+	userID, endpointID, ok := parseEndpointRequest(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery id"})
+		return
+	}
+
+	delivery, err := h.domain.GetDelivery(c.Request.Context(), userID, endpointID, deliveryID)
+	if err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Redeliver handles POST /webhook-endpoints/:id/deliveries/:delivery_id/redeliver.
+func (h *Handler) Redeliver(c *gin.Context) {
+	userID, endpointID, ok := parseEndpointRequest(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery id"})
+		return
+	}
+
+	delivery, err := h.domain.Redeliver(c.Request.Context(), userID, endpointID, deliveryID)
+	if err != nil {
+		handleWebhookError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// parseEndpointRequest reads the user and endpoint ID of a request, writing
+// the error response and returning false if either is missing.
+func parseEndpointRequest(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID := middleware.GetUserID(c)
+	if userID == uuid.Nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	endpointID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid endpoint id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, endpointID, true
+}
+
+func handleWebhookError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, webhook.ErrEndpointNotFound), errors.Is(err, webhook.ErrDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, webhook.ErrInvalidURL), errors.Is(err, webhook.ErrInvalidEventType):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, webhook.ErrEndpointDisabled), errors.Is(err, webhook.ErrTooManyEndpoints):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// Compile-time interface check
+var _ inbound.WebhookEndpointHttpPort = (*Handler)(nil)
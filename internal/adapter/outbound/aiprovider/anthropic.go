@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/uniedit/server/internal/model"
 	"github.com/uniedit/server/internal/port/outbound"
@@ -77,25 +78,28 @@ func (a *AnthropicAdapter) Chat(ctx context.Context, req *model.AIChatRequest, m
 		Role    string `json:"role"`
 		Model   string `json:"model"`
 		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text,omitempty"`
+			Type     string `json:"type"`
+			Text     string `json:"text,omitempty"`
+			Thinking string `json:"thinking,omitempty"`
 		} `json:"content"`
-		StopReason string `json:"stop_reason"`
-		Usage      struct {
-			InputTokens  int `json:"input_tokens"`
-			OutputTokens int `json:"output_tokens"`
-		} `json:"usage"`
+		StopReason string         `json:"stop_reason"`
+		Usage      anthropicUsage `json:"usage"`
 	}
 
 	if err := json.NewDecoder(respBody).Decode(&anthropicResp); err != nil {
 		return nil, fmt.Errorf("decode response: %w", err)
 	}
 
-	// Extract text content
-	var content string
+	usage := anthropicResp.Usage.toModel()
+
+	// Extract text and thinking content
+	var content, reasoning string
 	for _, c := range anthropicResp.Content {
-		if c.Type == "text" {
+		switch c.Type {
+		case "text":
 			content += c.Text
+		case "thinking":
+			reasoning += c.Thinking
 		}
 	}
 
@@ -107,14 +111,35 @@ func (a *AnthropicAdapter) Chat(ctx context.Context, req *model.AIChatRequest, m
 			Content: content,
 		},
 		FinishReason: a.mapStopReason(anthropicResp.StopReason),
-		Usage: &model.AIUsage{
-			PromptTokens:     anthropicResp.Usage.InputTokens,
-			CompletionTokens: anthropicResp.Usage.OutputTokens,
-			TotalTokens:      anthropicResp.Usage.InputTokens + anthropicResp.Usage.OutputTokens,
-		},
+		Reasoning:    reasoning,
+		Usage:        usage,
 	}, nil
 }
 
+// anthropicUsage is the Anthropic usage object.
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+// toModel converts the usage object. Anthropic reports cached tokens
+// separately from input_tokens.
+func (u *anthropicUsage) toModel() *model.AIUsage {
+	if u == nil {
+		return nil
+	}
+	promptTokens := u.InputTokens + u.CacheReadInputTokens + u.CacheCreationInputTokens
+	return &model.AIUsage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      promptTokens + u.OutputTokens,
+		CacheReadTokens:  u.CacheReadInputTokens,
+		CacheWriteTokens: u.CacheCreationInputTokens,
+	}
+}
+
 // ChatStream performs a streaming chat completion.
 func (a *AnthropicAdapter) ChatStream(ctx context.Context, req *model.AIChatRequest, m *model.AIModel, p *model.AIProvider, apiKey string) (<-chan *model.AIChatChunk, error) {
 	body := a.buildRequest(req, m)
@@ -131,6 +156,10 @@ func (a *AnthropicAdapter) ChatStream(ctx context.Context, req *model.AIChatRequ
 		defer close(chunks)
 		defer respBody.Close()
 
+		// Prompt usage arrives in message_start; it is merged into the
+		// final chunk once the output tokens are known.
+		var promptUsage *model.AIUsage
+
 		parser := NewSSEParser(respBody)
 		for {
 			event, err := parser.Next()
@@ -152,6 +181,13 @@ func (a *AnthropicAdapter) ChatStream(ctx context.Context, req *model.AIChatRequ
 			if chunk == nil {
 				continue
 			}
+			if chunk.Usage != nil {
+				if chunk.Delta == nil && chunk.FinishReason == "" {
+					promptUsage = chunk.Usage
+					continue
+				}
+				chunk.Usage = mergeStreamUsage(promptUsage, chunk.Usage)
+			}
 
 			select {
 			case <-ctx.Done():
@@ -164,28 +200,138 @@ func (a *AnthropicAdapter) ChatStream(ctx context.Context, req *model.AIChatRequ
 	return chunks, nil
 }
 
+// mergeStreamUsage combines the prompt usage of message_start with the
+// usage of message_delta, which may only report output tokens.
+func mergeStreamUsage(prompt, final *model.AIUsage) *model.AIUsage {
+	if prompt == nil || final.PromptTokens > 0 {
+		return final
+	}
+	usage := *prompt
+	usage.CompletionTokens = final.CompletionTokens
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	return &usage
+}
+
 // Embed is not supported by Anthropic.
 func (a *AnthropicAdapter) Embed(ctx context.Context, req *model.AIEmbedRequest, m *model.AIModel, p *model.AIProvider, apiKey string) (*model.AIEmbedResponse, error) {
 	return nil, fmt.Errorf("embedding not supported by Anthropic")
 }
 
+// anthropicContent converts OpenAI-style content parts to Anthropic blocks.
+// Images must already be inlined as base64 data URLs.
+func anthropicContent(content any) any {
+	parts, ok := content.([]any)
+	if !ok {
+		return content
+	}
+
+	blocks := make([]any, 0, len(parts))
+	for _, part := range parts {
+		p, ok := part.(map[string]any)
+		if !ok {
+			blocks = append(blocks, part)
+			continue
+		}
+
+		switch p["type"] {
+		case "text":
+			block := map[string]any{"type": "text", "text": p["text"]}
+			if cc, ok := p["cache_control"]; ok {
+				block["cache_control"] = cc
+			}
+			blocks = append(blocks, block)
+		case "image_url":
+			var rawURL string
+			switch v := p["image_url"].(type) {
+			case string:
+				rawURL = v
+			case map[string]any:
+				rawURL, _ = v["url"].(string)
+			}
+			header, data, ok := strings.Cut(strings.TrimPrefix(rawURL, "data:"), ";base64,")
+			if !ok || !strings.HasPrefix(rawURL, "data:") {
+				// Anthropic also accepts URL sources directly.
+				blocks = append(blocks, map[string]any{
+					"type":   "image",
+					"source": map[string]any{"type": "url", "url": rawURL},
+				})
+				continue
+			}
+			block := map[string]any{
+				"type": "image",
+				"source": map[string]any{
+					"type":       "base64",
+					"media_type": header,
+					"data":       data,
+				},
+			}
+			if cc, ok := p["cache_control"]; ok {
+				block["cache_control"] = cc
+			}
+			blocks = append(blocks, block)
+		default:
+			blocks = append(blocks, p)
+		}
+	}
+	return blocks
+}
+
+// withCacheControl places a cache breakpoint on the last content block,
+// converting plain string content to a text block.
+func withCacheControl(content any, cc *model.AICacheControl) any {
+	var blocks []any
+	switch v := content.(type) {
+	case string:
+		blocks = []any{map[string]any{"type": "text", "text": v}}
+	case []any:
+		blocks = v
+	default:
+		return content
+	}
+	if len(blocks) == 0 {
+		return content
+	}
+
+	last, ok := blocks[len(blocks)-1].(map[string]any)
+	if !ok {
+		return content
+	}
+	marked := make(map[string]any, len(last)+1)
+	for k, v := range last {
+		marked[k] = v
+	}
+	marked["cache_control"] = cc
+
+	out := make([]any, len(blocks))
+	copy(out, blocks)
+	out[len(out)-1] = marked
+	return out
+}
+
 // buildRequest builds the Anthropic request body.
 func (a *AnthropicAdapter) buildRequest(req *model.AIChatRequest, m *model.AIModel) map[string]any {
 	// Extract system message and convert messages
 	var system string
 	messages := make([]map[string]any, 0, len(req.Messages))
 
+	var systemCache *model.AICacheControl
 	for _, msg := range req.Messages {
 		if msg.Role == "system" {
 			if s, ok := msg.Content.(string); ok {
 				system = s
 			}
+			systemCache = msg.CacheControl
 			continue
 		}
 
+		content := anthropicContent(msg.Content)
+		if msg.CacheControl != nil {
+			content = withCacheControl(content, msg.CacheControl)
+		}
+
 		mm := map[string]any{
 			"role":    msg.Role,
-			"content": msg.Content,
+			"content": content,
 		}
 		messages = append(messages, mm)
 	}
@@ -196,7 +342,11 @@ func (a *AnthropicAdapter) buildRequest(req *model.AIChatRequest, m *model.AIMod
 	}
 
 	if system != "" {
-		body["system"] = system
+		if systemCache != nil {
+			body["system"] = withCacheControl(system, systemCache)
+		} else {
+			body["system"] = system
+		}
 	}
 
 	maxTokens := req.MaxTokens
@@ -206,14 +356,27 @@ func (a *AnthropicAdapter) buildRequest(req *model.AIChatRequest, m *model.AIMod
 	if maxTokens <= 0 {
 		maxTokens = 4096
 	}
-	body["max_tokens"] = maxTokens
 
-	if req.Temperature != nil {
-		body["temperature"] = *req.Temperature
-	}
-	if req.TopP != nil {
-		body["top_p"] = *req.TopP
+	if budget := thinkingBudget(req); budget > 0 {
+		// max_tokens must leave room for the answer after thinking.
+		if maxTokens <= budget {
+			maxTokens += budget
+		}
+		body["thinking"] = map[string]any{
+			"type":          "enabled",
+			"budget_tokens": budget,
+		}
+	} else {
+		// Sampling parameters are rejected while thinking is enabled.
+		if req.Temperature != nil {
+			body["temperature"] = *req.Temperature
+		}
+		if req.TopP != nil {
+			body["top_p"] = *req.TopP
+		}
 	}
+	body["max_tokens"] = maxTokens
+
 	if len(req.Stop) > 0 {
 		body["stop_sequences"] = req.Stop
 	}
@@ -0,0 +1,151 @@
+package aiprovider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/uniedit/server/internal/port/outbound"
+)
+
+const (
+	defaultImageFetchTimeout = 10 * time.Second
+	maxImageFetchRedirects   = 3
+)
+
+// errForbiddenAddress is returned when a fetch resolves to a non-public address.
+var errForbiddenAddress = errors.New("image URL resolves to a forbidden address")
+
+// imageFetcher implements outbound.AIImageFetcherPort.
+//
+// SSRF protection is enforced at dial time, so it also covers redirects and
+// DNS names that resolve to internal addresses.
+type imageFetcher struct {
+	client *http.Client
+}
+
+// NewImageFetcher creates an image fetcher with the given overall timeout.
+func NewImageFetcher(timeout time.Duration) outbound.AIImageFetcherPort {
+	if timeout <= 0 {
+		timeout = defaultImageFetchTimeout
+	}
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIP(ip) {
+				return errForbiddenAddress
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // never route through an environment proxy
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &imageFetcher{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= maxImageFetchRedirects {
+					return fmt.Errorf("too many redirects")
+				}
+				return checkImageURL(req.URL)
+			},
+		},
+	}
+}
+
+// Fetch downloads an image, enforcing the size limit while reading.
+func (f *imageFetcher) Fetch(ctx context.Context, rawURL string, maxBytes int64) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("parse image URL: %w", err)
+	}
+	if err := checkImageURL(u); err != nil {
+		return nil, "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "image/*")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("fetch image: status %d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxBytes {
+		return nil, "", fmt.Errorf("image exceeds %d bytes", maxBytes)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("read image: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("image exceeds %d bytes", maxBytes)
+	}
+
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+// checkImageURL rejects non-HTTP schemes and literal non-public hosts.
+func checkImageURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported image URL scheme %q", u.Scheme)
+	}
+	if u.User != nil {
+		return fmt.Errorf("image URL must not contain credentials")
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !isPublicIP(ip) {
+		return errForbiddenAddress
+	}
+	return nil
+}
+
+// isPublicIP reports whether ip is a globally routable unicast address.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		// 100.64.0.0/10 carrier-grade NAT and 0.0.0.0/8
+		if ip4[0] == 100 && ip4[1]&0xc0 == 64 {
+			return false
+		}
+		if ip4[0] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Compile-time check
+var _ outbound.AIImageFetcherPort = (*imageFetcher)(nil)
@@ -78,11 +78,14 @@ func (a *OpenAIAdapter) Chat(ctx context.Context, req *model.AIChatRequest, m *m
 		ID      string `json:"id"`
 		Model   string `json:"model"`
 		Choices []struct {
-			Index        int                 `json:"index"`
-			Message      *model.AIChatMessage `json:"message"`
-			FinishReason string              `json:"finish_reason"`
+			Index   int `json:"index"`
+			Message *struct {
+				model.AIChatMessage
+				ReasoningContent string `json:"reasoning_content,omitempty"` // OpenAI-compatible reasoning providers
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
 		} `json:"choices"`
-		Usage *model.AIUsage `json:"usage"`
+		Usage *openAIUsage `json:"usage"`
 	}
 
 	if err := json.NewDecoder(respBody).Decode(&openaiResp); err != nil {
@@ -93,13 +96,19 @@ func (a *OpenAIAdapter) Chat(ctx context.Context, req *model.AIChatRequest, m *m
 		return nil, fmt.Errorf("no choices in response")
 	}
 
-	return &model.AIChatResponse{
+	choice := openaiResp.Choices[0]
+	resp := &model.AIChatResponse{
 		ID:           openaiResp.ID,
 		Model:        openaiResp.Model,
-		Message:      openaiResp.Choices[0].Message,
-		FinishReason: openaiResp.Choices[0].FinishReason,
-		Usage:        openaiResp.Usage,
-	}, nil
+		FinishReason: choice.FinishReason,
+		Usage:        openaiResp.Usage.toModel(),
+	}
+	if choice.Message != nil {
+		resp.Message = &choice.Message.AIChatMessage
+		resp.Reasoning = choice.Message.ReasoningContent
+	}
+
+	return resp, nil
 }
 
 // ChatStream performs a streaming chat completion.
@@ -107,6 +116,7 @@ func (a *OpenAIAdapter) ChatStream(ctx context.Context, req *model.AIChatRequest
 	// Build request body with streaming enabled
 	body := a.buildChatRequest(req, m)
 	body["stream"] = true
+	body["stream_options"] = map[string]any{"include_usage": true}
 
 	// Make API request
 	respBody, err := a.doRequest(ctx, p, apiKey, "/chat/completions", body)
@@ -190,11 +200,103 @@ func (a *OpenAIAdapter) Embed(ctx context.Context, req *model.AIEmbedRequest, m
 	}, nil
 }
 
+// openAIUsage is the OpenAI usage object, including cached prompt tokens.
+type openAIUsage struct {
+	PromptTokens        int `json:"prompt_tokens"`
+	CompletionTokens    int `json:"completion_tokens"`
+	TotalTokens         int `json:"total_tokens"`
+	PromptTokensDetails *struct {
+		CachedTokens int `json:"cached_tokens"`
+	} `json:"prompt_tokens_details,omitempty"`
+	CompletionTokensDetails *struct {
+		ReasoningTokens int `json:"reasoning_tokens"`
+	} `json:"completion_tokens_details,omitempty"`
+}
+
+// toModel converts the usage object. OpenAI caches automatically, so there
+// are never cache-write tokens.
+func (u *openAIUsage) toModel() *model.AIUsage {
+	if u == nil {
+		return nil
+	}
+	usage := &model.AIUsage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+	if u.PromptTokensDetails != nil {
+		usage.CacheReadTokens = u.PromptTokensDetails.CachedTokens
+	}
+	if u.CompletionTokensDetails != nil {
+		usage.ReasoningTokens = u.CompletionTokensDetails.ReasoningTokens
+	}
+	return usage
+}
+
+// openAIMessages strips cache breakpoints, which OpenAI does not accept.
+// The input messages are not modified.
+func openAIMessages(messages []*model.AIChatMessage) []*model.AIChatMessage {
+	out := messages
+	copied := false
+
+	for i, msg := range messages {
+		content, stripped := stripPartCacheControl(msg.Content)
+		if msg.CacheControl == nil && !stripped {
+			continue
+		}
+		if !copied {
+			out = make([]*model.AIChatMessage, len(messages))
+			copy(out, messages)
+			copied = true
+		}
+		m := *msg
+		m.CacheControl = nil
+		m.Content = content
+		out[i] = &m
+	}
+	return out
+}
+
+// stripPartCacheControl removes cache_control from content parts.
+func stripPartCacheControl(content any) (any, bool) {
+	parts, ok := content.([]any)
+	if !ok {
+		return content, false
+	}
+
+	var out []any
+	for i, part := range parts {
+		p, ok := part.(map[string]any)
+		if !ok {
+			continue
+		}
+		if _, has := p["cache_control"]; !has {
+			continue
+		}
+		if out == nil {
+			out = make([]any, len(parts))
+			copy(out, parts)
+		}
+		cleaned := make(map[string]any, len(p))
+		for k, v := range p {
+			if k != "cache_control" {
+				cleaned[k] = v
+			}
+		}
+		out[i] = cleaned
+	}
+
+	if out == nil {
+		return content, false
+	}
+	return out, true
+}
+
 // buildChatRequest builds the OpenAI chat request body.
 func (a *OpenAIAdapter) buildChatRequest(req *model.AIChatRequest, m *model.AIModel) map[string]any {
 	body := map[string]any{
 		"model":    m.ID,
-		"messages": req.Messages,
+		"messages": openAIMessages(req.Messages),
 	}
 
 	if req.MaxTokens > 0 {
@@ -216,6 +318,17 @@ func (a *OpenAIAdapter) buildChatRequest(req *model.AIChatRequest, m *model.AIMo
 		}
 	}
 
+	if effort := reasoningEffort(req); effort != "" {
+		// Reasoning models take max_completion_tokens and fixed sampling.
+		body["reasoning_effort"] = effort
+		if v, ok := body["max_tokens"]; ok {
+			delete(body, "max_tokens")
+			body["max_completion_tokens"] = v
+		}
+		delete(body, "temperature")
+		delete(body, "top_p")
+	}
+
 	return body
 }
 
@@ -0,0 +1,42 @@
+package aiprovider
+
+import "github.com/uniedit/server/internal/model"
+
+// Thinking budgets used when a request only specifies an effort level.
+var effortBudgets = map[model.AIReasoningEffort]int{
+	model.AIReasoningEffortLow:    2048,
+	model.AIReasoningEffortMedium: 8192,
+	model.AIReasoningEffortHigh:   24576,
+}
+
+// thinkingBudget returns the token budget for budget-based providers,
+// or 0 if the request does not ask for reasoning.
+func thinkingBudget(req *model.AIChatRequest) int {
+	if req.Thinking != nil {
+		return max(req.Thinking.BudgetTokens, model.AIMinThinkingBudget)
+	}
+	if budget, ok := effortBudgets[req.ReasoningEffort]; ok {
+		return budget
+	}
+	return 0
+}
+
+// reasoningEffort returns the effort level for effort-based providers,
+// or "" if the request does not ask for reasoning.
+func reasoningEffort(req *model.AIChatRequest) model.AIReasoningEffort {
+	if req.ReasoningEffort != "" {
+		return req.ReasoningEffort
+	}
+	if req.Thinking == nil {
+		return ""
+	}
+
+	switch budget := req.Thinking.BudgetTokens; {
+	case budget <= effortBudgets[model.AIReasoningEffortLow]:
+		return model.AIReasoningEffortLow
+	case budget <= effortBudgets[model.AIReasoningEffortMedium]:
+		return model.AIReasoningEffortMedium
+	default:
+		return model.AIReasoningEffortHigh
+	}
+}
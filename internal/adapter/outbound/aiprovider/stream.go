@@ -97,13 +97,14 @@ type OpenAIStreamChunk struct {
 	Choices []struct {
 		Index int `json:"index"`
 		Delta struct {
-			Role      string              `json:"role,omitempty"`
-			Content   string              `json:"content,omitempty"`
-			ToolCalls []*model.AIToolCall `json:"tool_calls,omitempty"`
+			Role             string              `json:"role,omitempty"`
+			Content          string              `json:"content,omitempty"`
+			ReasoningContent string              `json:"reasoning_content,omitempty"` // OpenAI-compatible reasoning providers
+			ToolCalls        []*model.AIToolCall `json:"tool_calls,omitempty"`
 		} `json:"delta"`
 		FinishReason string `json:"finish_reason,omitempty"`
 	} `json:"choices"`
-	Usage *model.AIUsage `json:"usage,omitempty"`
+	Usage *openAIUsage `json:"usage,omitempty"`
 }
 
 // ParseOpenAIChunk parses an OpenAI streaming chunk.
@@ -118,9 +119,11 @@ func ParseOpenAIChunk(data string) (*model.AIChatChunk, error) {
 	}
 
 	if len(chunk.Choices) == 0 {
+		// The final chunk carries usage and no choices.
 		return &model.AIChatChunk{
 			ID:    chunk.ID,
 			Model: chunk.Model,
+			Usage: chunk.Usage.toModel(),
 		}, nil
 	}
 
@@ -131,24 +134,30 @@ func ParseOpenAIChunk(data string) (*model.AIChatChunk, error) {
 		Delta: &model.AIDelta{
 			Role:      choice.Delta.Role,
 			Content:   choice.Delta.Content,
+			Reasoning: choice.Delta.ReasoningContent,
 			ToolCalls: choice.Delta.ToolCalls,
 		},
 		FinishReason: choice.FinishReason,
+		Usage:        chunk.Usage.toModel(),
 	}, nil
 }
 
 // AnthropicStreamEvent represents an Anthropic streaming event.
 type AnthropicStreamEvent struct {
-	Type  string          `json:"type"`
-	Index int             `json:"index,omitempty"`
-	Delta json.RawMessage `json:"delta,omitempty"`
-	Usage *model.AIUsage  `json:"usage,omitempty"`
+	Type    string          `json:"type"`
+	Index   int             `json:"index,omitempty"`
+	Delta   json.RawMessage `json:"delta,omitempty"`
+	Usage   *anthropicUsage `json:"usage,omitempty"`
+	Message *struct {
+		Usage *anthropicUsage `json:"usage,omitempty"`
+	} `json:"message,omitempty"`
 }
 
 // AnthropicContentDelta represents an Anthropic content delta.
 type AnthropicContentDelta struct {
-	Type string `json:"type"`
-	Text string `json:"text,omitempty"`
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Thinking string `json:"thinking,omitempty"`
 }
 
 // ParseAnthropicEvent parses an Anthropic streaming event.
@@ -159,11 +168,31 @@ func ParseAnthropicEvent(eventType, data string) (*model.AIChatChunk, error) {
 	}
 
 	switch event.Type {
+	case "message_start":
+		// Prompt usage; output tokens follow in message_delta.
+		if event.Message == nil || event.Message.Usage == nil {
+			return nil, nil
+		}
+		return &model.AIChatChunk{
+			Usage: event.Message.Usage.toModel(),
+		}, nil
+
 	case "content_block_delta":
 		var delta AnthropicContentDelta
 		if err := json.Unmarshal(event.Delta, &delta); err != nil {
 			return nil, fmt.Errorf("parse anthropic delta: %w", err)
 		}
+		switch delta.Type {
+		case "thinking_delta":
+			return &model.AIChatChunk{
+				Delta: &model.AIDelta{
+					Reasoning: delta.Thinking,
+				},
+			}, nil
+		case "signature_delta":
+			// Thinking signatures are not exposed to clients.
+			return nil, nil
+		}
 		return &model.AIChatChunk{
 			Delta: &model.AIDelta{
 				Content: delta.Text,
@@ -172,9 +201,13 @@ func ParseAnthropicEvent(eventType, data string) (*model.AIChatChunk, error) {
 
 	case "message_delta":
 		// Message completed
-		return &model.AIChatChunk{
+		chunk := &model.AIChatChunk{
 			FinishReason: "stop",
-		}, nil
+		}
+		if event.Usage != nil {
+			chunk.Usage = event.Usage.toModel()
+		}
+		return chunk, nil
 
 	case "message_stop":
 		return nil, io.EOF
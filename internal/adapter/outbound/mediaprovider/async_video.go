@@ -0,0 +1,515 @@
+package mediaprovider
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/outbound"
+)
+
+// AsyncVideoAdapter implements the MediaVendorAdapterPort for video APIs that
+// accept a job, return a task ID and are polled until the video is ready.
+// Endpoints and JSON fields come from MediaProvider.Options, so most vendors
+// need configuration rather than a dedicated adapter.
+type AsyncVideoAdapter struct {
+	client *http.Client
+}
+
+// NewAsyncVideoAdapter creates a new generic async video adapter with the given HTTP client.
+func NewAsyncVideoAdapter(client *http.Client) *AsyncVideoAdapter {
+	return &AsyncVideoAdapter{
+		client: client,
+	}
+}
+
+// Type returns the provider type.
+func (a *AsyncVideoAdapter) Type() model.MediaProviderType {
+	return model.MediaProviderTypeGeneric
+}
+
+// SupportsCapability checks if the adapter supports a capability.
+func (a *AsyncVideoAdapter) SupportsCapability(cap model.MediaCapability) bool {
+	return cap == model.MediaCapabilityVideo
+}
+
+// asyncVideoOptions maps a vendor's job API. Field options are dot-separated
+// paths into the JSON documents, with numeric segments indexing arrays
+// (e.g. "data.videos.0.url").
+type asyncVideoOptions struct {
+	// SubmitPath receives a POST with the job. StatusPath and ResultPath are
+	// fetched with GET; "{task_id}" is replaced with the vendor's task ID.
+	SubmitPath string `json:"submit_path"`
+	StatusPath string `json:"status_path"`
+	ResultPath string `json:"result_path"` // optional, when the status lacks the video
+	HealthPath string `json:"health_path"` // optional; no check without it
+
+	// AuthHeader carries AuthPrefix followed by the API key. AuthPrefix
+	// defaults to "Bearer " for the Authorization header and is empty otherwise.
+	AuthHeader string  `json:"auth_header"`
+	AuthPrefix *string `json:"auth_prefix"`
+
+	// RequestFields maps VideoRequest JSON names (prompt, input_image,
+	// input_video, duration, aspect_ratio, resolution, fps, model, seed) to
+	// paths in the submitted body. Unmapped fields keep their names; fields
+	// mapped to "" are not sent. RequestDefaults is merged in first.
+	RequestFields   map[string]string `json:"request_fields"`
+	RequestDefaults map[string]any    `json:"request_defaults"`
+
+	TaskIDField   string `json:"task_id_field"`
+	StatusField   string `json:"status_field"`
+	ProgressField string `json:"progress_field"`
+	ErrorField    string `json:"error_field"`
+	VideoURLField string `json:"video_url_field"`
+	DurationField string `json:"duration_field"`
+	WidthField    string `json:"width_field"`
+	HeightField   string `json:"height_field"`
+	FPSField      string `json:"fps_field"`
+
+	// StatusValues maps vendor states (case-insensitive) to ours, on top of
+	// common defaults. Unknown states count as processing.
+	StatusValues map[string]model.VideoState `json:"status_values"`
+
+	// ProgressScale converts the vendor's progress to percent, e.g. 100 for
+	// a 0-1 fraction.
+	ProgressScale float64 `json:"progress_scale"`
+}
+
+// defaultAsyncVideoOptions returns the mapping used for unset options.
+func defaultAsyncVideoOptions() *asyncVideoOptions {
+	return &asyncVideoOptions{
+		SubmitPath:    "/v1/videos/generations",
+		StatusPath:    "/v1/videos/generations/{task_id}",
+		AuthHeader:    "Authorization",
+		TaskIDField:   "task_id",
+		StatusField:   "status",
+		ProgressField: "progress",
+		ErrorField:    "error",
+		VideoURLField: "video.url",
+		DurationField: "video.duration",
+		WidthField:    "video.width",
+		HeightField:   "video.height",
+		FPSField:      "video.fps",
+		StatusValues: map[string]model.VideoState{
+			"pending":     model.VideoStatePending,
+			"queued":      model.VideoStatePending,
+			"submitted":   model.VideoStatePending,
+			"processing":  model.VideoStateProcessing,
+			"running":     model.VideoStateProcessing,
+			"in_progress": model.VideoStateProcessing,
+			"generating":  model.VideoStateProcessing,
+			"completed":   model.VideoStateCompleted,
+			"succeeded":   model.VideoStateCompleted,
+			"success":     model.VideoStateCompleted,
+			"done":        model.VideoStateCompleted,
+			"failed":      model.VideoStateFailed,
+			"error":       model.VideoStateFailed,
+			"cancelled":   model.VideoStateFailed,
+			"canceled":    model.VideoStateFailed,
+		},
+		ProgressScale: 1,
+	}
+}
+
+// asyncVideoOptionsFor reads a provider's options over the defaults.
+func asyncVideoOptionsFor(prov *model.MediaProvider) (*asyncVideoOptions, error) {
+	opts := defaultAsyncVideoOptions()
+	if len(prov.Options) > 0 {
+		raw, err := json.Marshal(prov.Options)
+		if err != nil {
+			return nil, fmt.Errorf("marshal provider options: %w", err)
+		}
+		if err := json.Unmarshal(raw, opts); err != nil {
+			return nil, fmt.Errorf("invalid provider options: %w", err)
+		}
+	}
+
+	// Vendor states are matched case-insensitively.
+	values := make(map[string]model.VideoState, len(opts.StatusValues))
+	for k, v := range opts.StatusValues {
+		values[strings.ToLower(k)] = v
+	}
+	opts.StatusValues = values
+	if opts.ProgressScale == 0 {
+		opts.ProgressScale = 1
+	}
+	return opts, nil
+}
+
+// GenerateVideo submits a video generation job.
+func (a *AsyncVideoAdapter) GenerateVideo(ctx context.Context, req *model.VideoRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.VideoResponse, error) {
+	opts, err := asyncVideoOptionsFor(prov)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := buildAsyncVideoBody(req, m, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := a.do(ctx, http.MethodPost, prov.BaseURL+opts.SubmitPath, body, opts, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	value, ok := lookupPath(doc, opts.TaskIDField)
+	taskID := stringValue(value)
+	if !ok || taskID == "" {
+		return nil, fmt.Errorf("submit response has no %q", opts.TaskIDField)
+	}
+
+	status := model.VideoStatePending
+	if raw, ok := lookupPath(doc, opts.StatusField); ok {
+		status = opts.videoState(stringValue(raw))
+	}
+
+	return &model.VideoResponse{
+		TaskID:    taskID,
+		Status:    status,
+		CreatedAt: time.Now().Unix(),
+	}, nil
+}
+
+// GetVideoStatus polls a submitted job, fetching the result once it completes.
+func (a *AsyncVideoAdapter) GetVideoStatus(ctx context.Context, taskID string, prov *model.MediaProvider, apiKey string) (*model.VideoStatus, error) {
+	opts, err := asyncVideoOptionsFor(prov)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := a.do(ctx, http.MethodGet, prov.BaseURL+taskPath(opts.StatusPath, taskID), nil, opts, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, ok := lookupPath(doc, opts.StatusField)
+	if !ok {
+		return nil, fmt.Errorf("status response has no %q", opts.StatusField)
+	}
+
+	status := &model.VideoStatus{
+		TaskID:    taskID,
+		Status:    opts.videoState(stringValue(raw)),
+		UpdatedAt: time.Now().Unix(),
+	}
+
+	switch status.Status {
+	case model.VideoStateFailed:
+		status.Error = errorMessage(doc, opts.ErrorField)
+		if status.Error == "" {
+			status.Error = fmt.Sprintf("provider reported %q", stringValue(raw))
+		}
+
+	case model.VideoStateCompleted:
+		status.Progress = 100
+		status.Video = opts.video(doc)
+		if status.Video == nil && opts.ResultPath != "" {
+			result, err := a.do(ctx, http.MethodGet, prov.BaseURL+taskPath(opts.ResultPath, taskID), nil, opts, apiKey)
+			if err != nil {
+				return nil, fmt.Errorf("fetch result: %w", err)
+			}
+			status.Video = opts.video(result)
+		}
+		if status.Video == nil {
+			return nil, fmt.Errorf("completed job has no %q", opts.VideoURLField)
+		}
+
+	default:
+		if v, ok := lookupPath(doc, opts.ProgressField); ok {
+			if p, ok := floatValue(v); ok {
+				status.Progress = min(max(int(p*opts.ProgressScale), 0), 99)
+			}
+		}
+	}
+
+	return status, nil
+}
+
+// GenerateImage is not supported by the async video adapter.
+func (a *AsyncVideoAdapter) GenerateImage(ctx context.Context, req *model.ImageRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+	return nil, fmt.Errorf("image generation not supported by async video adapter")
+}
+
+// EditImage is not supported by the async video adapter.
+func (a *AsyncVideoAdapter) EditImage(ctx context.Context, req *model.ImageEditRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+	return nil, fmt.Errorf("image editing not supported by async video adapter")
+}
+
+// CreateImageVariation is not supported by the async video adapter.
+func (a *AsyncVideoAdapter) CreateImageVariation(ctx context.Context, req *model.ImageVariationRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+	return nil, fmt.Errorf("image variations not supported by async video adapter")
+}
+
+// UpscaleImage is not supported by the async video adapter.
+func (a *AsyncVideoAdapter) UpscaleImage(ctx context.Context, req *model.ImageUpscaleRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+	return nil, fmt.Errorf("image upscaling not supported by async video adapter")
+}
+
+// HealthCheck performs a health check against the configured health path.
+// Providers without one are assumed healthy.
+func (a *AsyncVideoAdapter) HealthCheck(ctx context.Context, prov *model.MediaProvider, apiKey string) error {
+	opts, err := asyncVideoOptionsFor(prov)
+	if err != nil {
+		return err
+	}
+	if opts.HealthPath == "" {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, prov.BaseURL+opts.HealthPath, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	opts.authorize(req, apiKey)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("health check failed: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// do sends a request and decodes the JSON response body.
+func (a *AsyncVideoAdapter) do(ctx context.Context, method, rawURL string, body any, opts *asyncVideoOptions, apiKey string) (any, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	opts.authorize(req, apiKey)
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &outbound.MediaVendorStatusError{StatusCode: resp.StatusCode, Message: truncate(string(respBody), 512)}
+	}
+
+	var doc any
+	if err := json.Unmarshal(respBody, &doc); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+	return doc, nil
+}
+
+// authorize sets the API key header.
+func (o *asyncVideoOptions) authorize(req *http.Request, apiKey string) {
+	prefix := ""
+	if o.AuthPrefix != nil {
+		prefix = *o.AuthPrefix
+	} else if strings.EqualFold(o.AuthHeader, "Authorization") {
+		prefix = "Bearer "
+	}
+	req.Header.Set(o.AuthHeader, prefix+apiKey)
+}
+
+// videoState maps a vendor state to ours.
+func (o *asyncVideoOptions) videoState(vendor string) model.VideoState {
+	if state, ok := o.StatusValues[strings.ToLower(vendor)]; ok {
+		return state
+	}
+	return model.VideoStateProcessing
+}
+
+// video extracts the generated video, or nil if the document has no URL.
+func (o *asyncVideoOptions) video(doc any) *model.GeneratedVideo {
+	value, _ := lookupPath(doc, o.VideoURLField)
+	videoURL := stringValue(value)
+	if videoURL == "" {
+		return nil
+	}
+
+	video := &model.GeneratedVideo{URL: videoURL}
+	for path, dst := range map[string]*int{
+		o.DurationField: &video.Duration,
+		o.WidthField:    &video.Width,
+		o.HeightField:   &video.Height,
+		o.FPSField:      &video.FPS,
+	} {
+		if v, ok := lookupPath(doc, path); ok {
+			if f, ok := floatValue(v); ok {
+				*dst = int(f)
+			}
+		}
+	}
+	return video
+}
+
+// buildAsyncVideoBody maps a video request onto the vendor's body layout.
+func buildAsyncVideoBody(req *model.VideoRequest, m *model.MediaModel, opts *asyncVideoOptions) (map[string]any, error) {
+	body := map[string]any{}
+	if len(opts.RequestDefaults) > 0 {
+		// Copy so requests never share nested maps with the options.
+		raw, err := json.Marshal(opts.RequestDefaults)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request defaults: %w", err)
+		}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			return nil, fmt.Errorf("unmarshal request defaults: %w", err)
+		}
+	}
+
+	videoReq := *req
+	videoReq.Model = m.ID
+	raw, err := json.Marshal(&videoReq)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("unmarshal request: %w", err)
+	}
+
+	for name, value := range fields {
+		path := name
+		if mapped, ok := opts.RequestFields[name]; ok {
+			if mapped == "" {
+				continue
+			}
+			path = mapped
+		}
+		if err := setPath(body, path, value); err != nil {
+			return nil, err
+		}
+	}
+	return body, nil
+}
+
+// taskPath substitutes the task ID into a path template.
+func taskPath(template, taskID string) string {
+	return strings.ReplaceAll(template, "{task_id}", url.PathEscape(taskID))
+}
+
+// lookupPath resolves a dot-separated path in a decoded JSON document.
+func lookupPath(doc any, path string) (any, bool) {
+	if path == "" {
+		return nil, false
+	}
+	cur := doc
+	for _, segment := range strings.Split(path, ".") {
+		switch node := cur.(type) {
+		case map[string]any:
+			next, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			cur = next
+		case []any:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			cur = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return cur, cur != nil
+}
+
+// setPath sets a dot-separated path in a JSON object, creating objects as needed.
+func setPath(doc map[string]any, path string, value any) error {
+	segments := strings.Split(path, ".")
+	cur := doc
+	for _, segment := range segments[:len(segments)-1] {
+		next, ok := cur[segment]
+		if !ok {
+			child := map[string]any{}
+			cur[segment] = child
+			cur = child
+			continue
+		}
+		child, ok := next.(map[string]any)
+		if !ok {
+			return fmt.Errorf("request field %q conflicts with %q", path, segment)
+		}
+		cur = child
+	}
+	cur[segments[len(segments)-1]] = value
+	return nil
+}
+
+// errorMessage extracts an error string, or an object's "message".
+func errorMessage(doc any, path string) string {
+	value, ok := lookupPath(doc, path)
+	if !ok {
+		return ""
+	}
+	if obj, ok := value.(map[string]any); ok {
+		msg, _ := lookupPath(obj, "message")
+		return stringValue(msg)
+	}
+	return stringValue(value)
+}
+
+// stringValue renders a JSON scalar as a string.
+func stringValue(v any) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(val)
+	default:
+		return ""
+	}
+}
+
+// floatValue reads a JSON number, accepting numeric strings.
+func floatValue(v any) (float64, bool) {
+	switch val := v.(type) {
+	case float64:
+		return val, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSuffix(val, "%"), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// truncate shortens s to at most n bytes for error messages.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// Compile-time interface check
+var _ outbound.MediaVendorAdapterPort = (*AsyncVideoAdapter)(nil)
@@ -0,0 +1,184 @@
+package mediaprovider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+// fakeVideoAPI is a vendor that nests its fields, uses an API key header and
+// needs a separate result request.
+type fakeVideoAPI struct {
+	t *testing.T
+
+	mu        sync.Mutex
+	submitted map[string]any
+	polls     int
+}
+
+func (f *fakeVideoAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("X-API-Key") != "sk-test" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/api/jobs":
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&f.submitted))
+		writeJSON(w, `{"data":{"job_id":"job-42","state":"QUEUED"}}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/jobs/job-42":
+		f.polls++
+		if f.polls == 1 {
+			writeJSON(w, `{"data":{"state":"RUNNING","percent":0.4}}`)
+			return
+		}
+		writeJSON(w, `{"data":{"state":"SUCCEED"}}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/jobs/job-42/result":
+		writeJSON(w, `{"videos":[{"url":"https://cdn.example.com/job-42.mp4","seconds":5,"w":1280,"h":720}]}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(body))
+}
+
+func TestAsyncVideoAdapter_MappedProvider(t *testing.T) {
+	api := &fakeVideoAPI{t: t}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	prov := &model.MediaProvider{
+		Type:    model.MediaProviderTypeGeneric,
+		BaseURL: server.URL,
+		Options: map[string]any{
+			"submit_path": "/api/jobs",
+			"status_path": "/api/jobs/{task_id}",
+			"result_path": "/api/jobs/{task_id}/result",
+			"auth_header": "X-API-Key",
+			"request_fields": map[string]any{
+				"prompt":       "input.text",
+				"duration":     "params.seconds",
+				"aspect_ratio": "params.ratio",
+				"model":        "model_name",
+				"fps":          "",
+			},
+			"request_defaults": map[string]any{"params": map[string]any{"watermark": false}},
+			"task_id_field":    "data.job_id",
+			"status_field":     "data.state",
+			"status_values":    map[string]any{"SUCCEED": "completed"},
+			"progress_field":   "data.percent",
+			"progress_scale":   100,
+			"video_url_field":  "videos.0.url",
+			"duration_field":   "videos.0.seconds",
+			"width_field":      "videos.0.w",
+			"height_field":     "videos.0.h",
+		},
+	}
+	m := &model.MediaModel{ID: "vendor-video-1"}
+	adapter := NewAsyncVideoAdapter(server.Client())
+	ctx := context.Background()
+
+	resp, err := adapter.GenerateVideo(ctx, &model.VideoRequest{
+		Prompt:      "a cat surfing",
+		Duration:    5,
+		AspectRatio: "16:9",
+		FPS:         24,
+	}, m, prov, "sk-test")
+	require.NoError(t, err)
+	assert.Equal(t, "job-42", resp.TaskID)
+	assert.Equal(t, model.VideoStatePending, resp.Status)
+	assert.Equal(t, map[string]any{
+		"input":      map[string]any{"text": "a cat surfing"},
+		"params":     map[string]any{"seconds": float64(5), "ratio": "16:9", "watermark": false},
+		"model_name": "vendor-video-1",
+	}, api.submitted)
+
+	status, err := adapter.GetVideoStatus(ctx, resp.TaskID, prov, "sk-test")
+	require.NoError(t, err)
+	assert.Equal(t, model.VideoStateProcessing, status.Status)
+	assert.Equal(t, 40, status.Progress)
+
+	status, err = adapter.GetVideoStatus(ctx, resp.TaskID, prov, "sk-test")
+	require.NoError(t, err)
+	assert.Equal(t, model.VideoStateCompleted, status.Status)
+	assert.Equal(t, 100, status.Progress)
+	assert.Equal(t, &model.GeneratedVideo{
+		URL:      "https://cdn.example.com/job-42.mp4",
+		Duration: 5,
+		Width:    1280,
+		Height:   720,
+	}, status.Video)
+}
+
+func TestAsyncVideoAdapter_DefaultMapping(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer sk-test", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/v1/videos/generations":
+			writeJSON(w, `{"task_id":"t1","status":"queued"}`)
+		case "/v1/videos/generations/t1":
+			writeJSON(w, `{"status":"failed","error":{"message":"content policy violation"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	prov := &model.MediaProvider{Type: model.MediaProviderTypeGeneric, BaseURL: server.URL}
+	adapter := NewAsyncVideoAdapter(server.Client())
+
+	resp, err := adapter.GenerateVideo(context.Background(), &model.VideoRequest{Prompt: "x"}, &model.MediaModel{ID: "m"}, prov, "sk-test")
+	require.NoError(t, err)
+	assert.Equal(t, "t1", resp.TaskID)
+
+	status, err := adapter.GetVideoStatus(context.Background(), "t1", prov, "sk-test")
+	require.NoError(t, err)
+	assert.Equal(t, model.VideoStateFailed, status.Status)
+	assert.Equal(t, "content policy violation", status.Error)
+}
+
+func TestAsyncVideoAdapter_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/videos/generations":
+			writeJSON(w, `{"id":"missing-task-id-field"}`)
+		case "/v1/videos/generations/done":
+			writeJSON(w, `{"status":"completed"}`)
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte("upstream down"))
+		}
+	}))
+	defer server.Close()
+
+	prov := &model.MediaProvider{Type: model.MediaProviderTypeGeneric, BaseURL: server.URL}
+	adapter := NewAsyncVideoAdapter(server.Client())
+	ctx := context.Background()
+
+	_, err := adapter.GenerateVideo(ctx, &model.VideoRequest{Prompt: "x"}, &model.MediaModel{ID: "m"}, prov, "k")
+	assert.ErrorContains(t, err, `no "task_id"`)
+
+	_, err = adapter.GetVideoStatus(ctx, "done", prov, "k")
+	assert.ErrorContains(t, err, `no "video.url"`)
+
+	_, err = adapter.GetVideoStatus(ctx, "other", prov, "k")
+	assert.ErrorContains(t, err, "502: upstream down")
+
+	badProv := &model.MediaProvider{BaseURL: server.URL, Options: map[string]any{"status_values": "not-a-map"}}
+	_, err = adapter.GetVideoStatus(ctx, "done", badProv, "k")
+	assert.ErrorContains(t, err, "invalid provider options")
+}
@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"strconv"
 
 	"github.com/uniedit/server/internal/model"
 	"github.com/uniedit/server/internal/port/outbound"
@@ -32,7 +35,7 @@ func (a *OpenAIAdapter) Type() model.MediaProviderType {
 // SupportsCapability checks if the adapter supports a capability.
 func (a *OpenAIAdapter) SupportsCapability(cap model.MediaCapability) bool {
 	switch cap {
-	case model.MediaCapabilityImage:
+	case model.MediaCapabilityImage, model.MediaCapabilityImageEdit, model.MediaCapabilityImageVariation:
 		return true
 	default:
 		return false
@@ -104,6 +107,58 @@ func (a *OpenAIAdapter) GenerateImage(ctx context.Context, req *model.ImageReque
 	httpReq.Header.Set("Content-Type", "application/json")
 	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
 
+	return a.doImageRequest(httpReq, m)
+}
+
+// EditImage edits an image using the OpenAI image edits endpoint.
+func (a *OpenAIAdapter) EditImage(ctx context.Context, req *model.ImageEditRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+	form := newImageForm()
+	form.file("image", req.Image)
+	if len(req.Mask) > 0 {
+		form.file("mask", req.Mask)
+	}
+	form.field("model", m.ID)
+	form.field("prompt", req.Prompt)
+	form.imageOptions(req.N, req.Size, req.ResponseFormat)
+
+	return a.postImageForm(ctx, prov.BaseURL+"/v1/images/edits", form, m, apiKey)
+}
+
+// CreateImageVariation creates image variations using the OpenAI variations endpoint.
+func (a *OpenAIAdapter) CreateImageVariation(ctx context.Context, req *model.ImageVariationRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+	form := newImageForm()
+	form.file("image", req.Image)
+	form.field("model", m.ID)
+	form.imageOptions(req.N, req.Size, req.ResponseFormat)
+
+	return a.postImageForm(ctx, prov.BaseURL+"/v1/images/variations", form, m, apiKey)
+}
+
+// UpscaleImage is not supported; OpenAI has no upscaling endpoint.
+func (a *OpenAIAdapter) UpscaleImage(ctx context.Context, req *model.ImageUpscaleRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+	return nil, fmt.Errorf("image upscaling not supported by OpenAI adapter")
+}
+
+// postImageForm sends a multipart image request.
+func (a *OpenAIAdapter) postImageForm(ctx context.Context, url string, form *imageForm, m *model.MediaModel, apiKey string) (*model.ImageResponse, error) {
+	body, contentType, err := form.close()
+	if err != nil {
+		return nil, fmt.Errorf("build form: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", contentType)
+	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+
+	return a.doImageRequest(httpReq, m)
+}
+
+// doImageRequest executes an image request and converts the response.
+func (a *OpenAIAdapter) doImageRequest(httpReq *http.Request, m *model.MediaModel) (*model.ImageResponse, error) {
 	// Execute request
 	resp, err := a.client.Do(httpReq)
 	if err != nil {
@@ -118,19 +173,23 @@ func (a *OpenAIAdapter) GenerateImage(ctx context.Context, req *model.ImageReque
 
 	// Parse response
 	var openAIResp openAIImageResponse
-	if err := json.Unmarshal(respBody, &openAIResp); err != nil {
-		return nil, fmt.Errorf("unmarshal response: %w", err)
-	}
+	unmarshalErr := json.Unmarshal(respBody, &openAIResp)
 
 	// Check for errors
+	if resp.StatusCode != http.StatusOK {
+		message := truncate(string(respBody), 512)
+		if unmarshalErr == nil && openAIResp.Error != nil {
+			message = "openai error: " + openAIResp.Error.Message
+		}
+		return nil, &outbound.MediaVendorStatusError{StatusCode: resp.StatusCode, Message: message}
+	}
+	if unmarshalErr != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", unmarshalErr)
+	}
 	if openAIResp.Error != nil {
 		return nil, fmt.Errorf("openai error: %s", openAIResp.Error.Message)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
 	// Convert to ImageResponse
 	images := make([]*model.GeneratedImage, len(openAIResp.Data))
 	for i, data := range openAIResp.Data {
@@ -151,6 +210,80 @@ func (a *OpenAIAdapter) GenerateImage(ctx context.Context, req *model.ImageReque
 	}, nil
 }
 
+// imageForm builds a multipart body for the image edit and variation endpoints.
+// The first write error is kept and reported by close.
+type imageForm struct {
+	buf bytes.Buffer
+	w   *multipart.Writer
+	err error
+}
+
+func newImageForm() *imageForm {
+	f := &imageForm{}
+	f.w = multipart.NewWriter(&f.buf)
+	return f
+}
+
+func (f *imageForm) field(name, value string) {
+	if f.err != nil || value == "" {
+		return
+	}
+	f.err = f.w.WriteField(name, value)
+}
+
+// file writes an image part. OpenAI infers the format from the filename,
+// so the extension follows the sniffed content type.
+func (f *imageForm) file(name string, data []byte) {
+	if f.err != nil {
+		return
+	}
+	contentType := http.DetectContentType(data)
+	ext := ".png"
+	switch contentType {
+	case "image/jpeg":
+		ext = ".jpg"
+	case "image/webp":
+		ext = ".webp"
+	}
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s%s"`, name, name, ext))
+	h.Set("Content-Type", contentType)
+	part, err := f.w.CreatePart(h)
+	if err != nil {
+		f.err = err
+		return
+	}
+	_, f.err = part.Write(data)
+}
+
+// imageOptions writes the options shared by the image endpoints, with the
+// same defaults as GenerateImage.
+func (f *imageForm) imageOptions(n int, size, responseFormat string) {
+	if n == 0 {
+		n = 1
+	}
+	if size == "" {
+		size = "1024x1024"
+	}
+	if responseFormat == "" {
+		responseFormat = "url"
+	}
+	f.field("n", strconv.Itoa(n))
+	f.field("size", size)
+	f.field("response_format", responseFormat)
+}
+
+func (f *imageForm) close() (io.Reader, string, error) {
+	if f.err != nil {
+		return nil, "", f.err
+	}
+	if err := f.w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &f.buf, f.w.FormDataContentType(), nil
+}
+
 // GenerateVideo is not supported by OpenAI image adapter.
 func (a *OpenAIAdapter) GenerateVideo(ctx context.Context, req *model.VideoRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.VideoResponse, error) {
 	return nil, fmt.Errorf("video generation not supported by OpenAI adapter")
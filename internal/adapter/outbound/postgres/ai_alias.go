@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/outbound"
+	"gorm.io/gorm"
+)
+
+// aiModelAliasAdapter implements outbound.AIModelAliasDatabasePort.
+type aiModelAliasAdapter struct {
+	db *gorm.DB
+}
+
+// NewAIModelAliasAdapter creates a new AI model alias database adapter.
+func NewAIModelAliasAdapter(db *gorm.DB) outbound.AIModelAliasDatabasePort {
+	return &aiModelAliasAdapter{db: db}
+}
+
+func (a *aiModelAliasAdapter) Create(ctx context.Context, alias *model.AIModelAlias) error {
+	return a.db.WithContext(ctx).Create(alias).Error
+}
+
+func (a *aiModelAliasAdapter) FindByID(ctx context.Context, id string) (*model.AIModelAlias, error) {
+	var alias model.AIModelAlias
+	err := a.db.WithContext(ctx).First(&alias, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &alias, nil
+}
+
+func (a *aiModelAliasAdapter) FindAll(ctx context.Context) ([]*model.AIModelAlias, error) {
+	var aliases []*model.AIModelAlias
+	err := a.db.WithContext(ctx).Order("id").Find(&aliases).Error
+	return aliases, err
+}
+
+func (a *aiModelAliasAdapter) FindEnabled(ctx context.Context) ([]*model.AIModelAlias, error) {
+	var aliases []*model.AIModelAlias
+	err := a.db.WithContext(ctx).
+		Where("enabled = ?", true).
+		Order("id").
+		Find(&aliases).Error
+	return aliases, err
+}
+
+func (a *aiModelAliasAdapter) Update(ctx context.Context, alias *model.AIModelAlias) error {
+	return a.db.WithContext(ctx).Save(alias).Error
+}
+
+func (a *aiModelAliasAdapter) Delete(ctx context.Context, id string) error {
+	return a.db.WithContext(ctx).Delete(&model.AIModelAlias{}, "id = ?", id).Error
+}
+
+// Compile-time check
+var _ outbound.AIModelAliasDatabasePort = (*aiModelAliasAdapter)(nil)
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/outbound"
+	"gorm.io/gorm"
+)
+
+// aiRequestLogAdapter implements outbound.AIRequestLogDatabasePort.
+type aiRequestLogAdapter struct {
+	db *gorm.DB
+}
+
+// NewAIRequestLogAdapter creates a new AI request log database adapter.
+func NewAIRequestLogAdapter(db *gorm.DB) outbound.AIRequestLogDatabasePort {
+	return &aiRequestLogAdapter{db: db}
+}
+
+func (a *aiRequestLogAdapter) Create(ctx context.Context, log *model.AIRequestLog) error {
+	return a.db.WithContext(ctx).Create(log).Error
+}
+
+func (a *aiRequestLogAdapter) FindByID(ctx context.Context, id uuid.UUID) (*model.AIRequestLog, error) {
+	var log model.AIRequestLog
+	err := a.db.WithContext(ctx).First(&log, "id = ?", id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+func (a *aiRequestLogAdapter) Find(ctx context.Context, filter *model.AIRequestLogFilter) ([]*model.AIRequestLog, int64, error) {
+	query := a.db.WithContext(ctx).Model(&model.AIRequestLog{}).
+		Where("expires_at > ?", time.Now())
+
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if filter.APIKeyID != nil {
+		query = query.Where("api_key_id = ?", *filter.APIKeyID)
+	}
+	if filter.ModelID != "" {
+		query = query.Where("model_id = ?", filter.ModelID)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var logs []*model.AIRequestLog
+	err := query.
+		Order("created_at DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&logs).Error
+	return logs, total, err
+}
+
+func (a *aiRequestLogAdapter) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := a.db.WithContext(ctx).
+		Where("expires_at <= ?", before).
+		Delete(&model.AIRequestLog{})
+	return result.RowsAffected, result.Error
+}
+
+// Compile-time check
+var _ outbound.AIRequestLogDatabasePort = (*aiRequestLogAdapter)(nil)
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/outbound"
+)
+
+// GitBranchProtectionDatabaseAdapter implements GitBranchProtectionDatabasePort using GORM.
+type GitBranchProtectionDatabaseAdapter struct {
+	db *gorm.DB
+}
+
+// NewGitBranchProtectionDatabaseAdapter creates a new branch protection database adapter.
+func NewGitBranchProtectionDatabaseAdapter(db *gorm.DB) *GitBranchProtectionDatabaseAdapter {
+	return &GitBranchProtectionDatabaseAdapter{db: db}
+}
+
+// Create creates a rule.
+func (a *GitBranchProtectionDatabaseAdapter) Create(ctx context.Context, rule *model.GitBranchProtection) error {
+	return a.db.WithContext(ctx).Create(rule).Error
+}
+
+// FindByID finds a rule by ID.
+func (a *GitBranchProtectionDatabaseAdapter) FindByID(ctx context.Context, id uuid.UUID) (*model.GitBranchProtection, error) {
+	var rule model.GitBranchProtection
+	err := a.db.WithContext(ctx).First(&rule, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// FindByRepo lists the rules of a repository ordered by pattern.
+func (a *GitBranchProtectionDatabaseAdapter) FindByRepo(ctx context.Context, repoID uuid.UUID) ([]*model.GitBranchProtection, error) {
+	var rules []*model.GitBranchProtection
+	err := a.db.WithContext(ctx).
+		Where("repo_id = ?", repoID).
+		Order("pattern ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+// Update updates a rule.
+func (a *GitBranchProtectionDatabaseAdapter) Update(ctx context.Context, rule *model.GitBranchProtection) error {
+	return a.db.WithContext(ctx).Save(rule).Error
+}
+
+// Delete deletes a rule.
+func (a *GitBranchProtectionDatabaseAdapter) Delete(ctx context.Context, id uuid.UUID) error {
+	return a.db.WithContext(ctx).Delete(&model.GitBranchProtection{}, "id = ?", id).Error
+}
+
+// Compile-time check
+var _ outbound.GitBranchProtectionDatabasePort = (*GitBranchProtectionDatabaseAdapter)(nil)
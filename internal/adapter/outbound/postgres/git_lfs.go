@@ -58,6 +58,16 @@ func (a *GitLFSObjectDatabaseAdapter) Link(ctx context.Context, repoID uuid.UUID
 		Create(&link).Error
 }
 
+// IsLinked checks if an LFS object is linked to a repository.
+func (a *GitLFSObjectDatabaseAdapter) IsLinked(ctx context.Context, repoID uuid.UUID, oid string) (bool, error) {
+	var count int64
+	err := a.db.WithContext(ctx).
+		Model(&model.GitLFSRepoObject{}).
+		Where("repo_id = ? AND oid = ?", repoID, oid).
+		Count(&count).Error
+	return count > 0, err
+}
+
 // Unlink unlinks an LFS object from a repository.
 func (a *GitLFSObjectDatabaseAdapter) Unlink(ctx context.Context, repoID uuid.UUID, oid string) error {
 	return a.db.WithContext(ctx).
@@ -82,6 +82,19 @@ func (a *GitPullRequestDatabaseAdapter) FindByRepo(ctx context.Context, repoID u
 	return prs, total, err
 }
 
+// FindOpenBySourceBranch lists the open pull requests whose source is a
+// branch of a repository. Pull requests opened within a repository leave
+// source_repo_id unset.
+func (a *GitPullRequestDatabaseAdapter) FindOpenBySourceBranch(ctx context.Context, sourceRepoID uuid.UUID, branch string) ([]*model.GitPullRequest, error) {
+	var prs []*model.GitPullRequest
+	err := a.db.WithContext(ctx).
+		Where("status = ? AND source_branch = ?", model.GitPRStatusOpen, branch).
+		Where("source_repo_id = ? OR (source_repo_id IS NULL AND repo_id = ?)", sourceRepoID, sourceRepoID).
+		Order("created_at").
+		Find(&prs).Error
+	return prs, err
+}
+
 // Update updates a pull request.
 func (a *GitPullRequestDatabaseAdapter) Update(ctx context.Context, pr *model.GitPullRequest) error {
 	return a.db.WithContext(ctx).Save(pr).Error
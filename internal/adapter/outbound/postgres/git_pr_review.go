@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/outbound"
+)
+
+// GitPRReviewDatabaseAdapter implements GitPRReviewDatabasePort using GORM.
+type GitPRReviewDatabaseAdapter struct {
+	db *gorm.DB
+}
+
+// NewGitPRReviewDatabaseAdapter creates a new pull request review database adapter.
+func NewGitPRReviewDatabaseAdapter(db *gorm.DB) *GitPRReviewDatabaseAdapter {
+	return &GitPRReviewDatabaseAdapter{db: db}
+}
+
+// CreateReview creates a review and its inline comments in one transaction.
+func (a *GitPRReviewDatabaseAdapter) CreateReview(ctx context.Context, review *model.GitPRReview, comments []*model.GitPRComment) error {
+	return a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(review).Error; err != nil {
+			return err
+		}
+		if len(comments) == 0 {
+			return nil
+		}
+		return tx.Create(&comments).Error
+	})
+}
+
+// FindReviews lists the reviews of a pull request, oldest first.
+func (a *GitPRReviewDatabaseAdapter) FindReviews(ctx context.Context, prID uuid.UUID) ([]*model.GitPRReview, error) {
+	var reviews []*model.GitPRReview
+	err := a.db.WithContext(ctx).
+		Where("pr_id = ?", prID).
+		Order("created_at ASC").
+		Find(&reviews).Error
+	return reviews, err
+}
+
+// CreateComment creates a comment.
+func (a *GitPRReviewDatabaseAdapter) CreateComment(ctx context.Context, comment *model.GitPRComment) error {
+	return a.db.WithContext(ctx).Create(comment).Error
+}
+
+// FindCommentByID finds a comment by ID.
+func (a *GitPRReviewDatabaseAdapter) FindCommentByID(ctx context.Context, id uuid.UUID) (*model.GitPRComment, error) {
+	var comment model.GitPRComment
+	err := a.db.WithContext(ctx).First(&comment, "id = ?", id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// FindComments lists the comments of a pull request, oldest first.
+func (a *GitPRReviewDatabaseAdapter) FindComments(ctx context.Context, prID uuid.UUID) ([]*model.GitPRComment, error) {
+	var comments []*model.GitPRComment
+	err := a.db.WithContext(ctx).
+		Where("pr_id = ?", prID).
+		Order("created_at ASC").
+		Find(&comments).Error
+	return comments, err
+}
+
+// UpdateComment updates a comment.
+func (a *GitPRReviewDatabaseAdapter) UpdateComment(ctx context.Context, comment *model.GitPRComment) error {
+	return a.db.WithContext(ctx).Save(comment).Error
+}
+
+// DeleteComment deletes a comment.
+func (a *GitPRReviewDatabaseAdapter) DeleteComment(ctx context.Context, id uuid.UUID) error {
+	return a.db.WithContext(ctx).Delete(&model.GitPRComment{}, "id = ?", id).Error
+}
+
+// AddReviewRequest requests a review (idempotent).
+func (a *GitPRReviewDatabaseAdapter) AddReviewRequest(ctx context.Context, request *model.GitPRReviewRequest) error {
+	return a.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(request).Error
+}
+
+// RemoveReviewRequest removes a pending review request.
+func (a *GitPRReviewDatabaseAdapter) RemoveReviewRequest(ctx context.Context, prID, reviewerID uuid.UUID) error {
+	return a.db.WithContext(ctx).
+		Where("pr_id = ? AND reviewer_id = ?", prID, reviewerID).
+		Delete(&model.GitPRReviewRequest{}).Error
+}
+
+// FindReviewRequests lists the pending review requests of a pull request.
+func (a *GitPRReviewDatabaseAdapter) FindReviewRequests(ctx context.Context, prID uuid.UUID) ([]*model.GitPRReviewRequest, error) {
+	var requests []*model.GitPRReviewRequest
+	err := a.db.WithContext(ctx).
+		Where("pr_id = ?", prID).
+		Order("created_at ASC").
+		Find(&requests).Error
+	return requests, err
+}
+
+// Compile-time check
+var _ outbound.GitPRReviewDatabasePort = (*GitPRReviewDatabaseAdapter)(nil)
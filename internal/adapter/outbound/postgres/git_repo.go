@@ -157,6 +157,20 @@ func (a *GitRepoDatabaseAdapter) UpdateSize(ctx context.Context, id uuid.UUID, s
 		}).Error
 }
 
+// GetOwnerStorage sums the git and LFS sizes of a user's repositories.
+func (a *GitRepoDatabaseAdapter) GetOwnerStorage(ctx context.Context, ownerID uuid.UUID) (int64, int64, error) {
+	var totals struct {
+		SizeBytes    int64
+		LFSSizeBytes int64
+	}
+	err := a.db.WithContext(ctx).
+		Model(&model.GitRepo{}).
+		Select("COALESCE(SUM(size_bytes), 0) AS size_bytes, COALESCE(SUM(lfs_size_bytes), 0) AS lfs_size_bytes").
+		Where("owner_id = ?", ownerID).
+		Scan(&totals).Error
+	return totals.SizeBytes, totals.LFSSizeBytes, err
+}
+
 // IncrementStars increments the star count.
 func (a *GitRepoDatabaseAdapter) IncrementStars(ctx context.Context, id uuid.UUID, delta int) error {
 	return a.db.WithContext(ctx).
@@ -3,9 +3,12 @@ package postgres
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/uniedit/server/internal/model"
 	"github.com/uniedit/server/internal/port/outbound"
@@ -168,10 +171,10 @@ func (a *MediaTaskDBAdapter) FindByOwner(ctx context.Context, ownerID uuid.UUID,
 	return tasks, nil
 }
 
-func (a *MediaTaskDBAdapter) FindPending(ctx context.Context, limit int) ([]*model.MediaTask, error) {
+func (a *MediaTaskDBAdapter) FindPending(ctx context.Context, limit int, lease time.Duration) ([]*model.MediaTask, error) {
 	var tasks []*model.MediaTask
 	if err := a.db.WithContext(ctx).
-		Where("status = ?", model.MediaTaskStatusPending).
+		Where(claimableTask(lease)).
 		Order("created_at ASC").
 		Limit(limit).
 		Find(&tasks).Error; err != nil {
@@ -180,11 +183,78 @@ func (a *MediaTaskDBAdapter) FindPending(ctx context.Context, limit int) ([]*mod
 	return tasks, nil
 }
 
+func (a *MediaTaskDBAdapter) Claim(ctx context.Context, id uuid.UUID, lease time.Duration) (bool, error) {
+	result := a.db.WithContext(ctx).
+		Model(&model.MediaTask{}).
+		Where("id = ?", id).
+		Where(claimableTask(lease)).
+		Updates(map[string]interface{}{
+			"status":     model.MediaTaskStatusRunning,
+			"updated_at": gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (a *MediaTaskDBAdapter) Heartbeat(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := a.db.WithContext(ctx).
+		Model(&model.MediaTask{}).
+		Where("id = ? AND status = ?", id, model.MediaTaskStatusRunning).
+		Update("updated_at", gorm.Expr("NOW()"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// claimableTask matches pending tasks and running tasks whose worker has not
+// sent a heartbeat within lease. Staleness is judged by the database clock
+// so replicas with skewed clocks agree.
+func claimableTask(lease time.Duration) clause.Expr {
+	return gorm.Expr("(status = ? OR (status = ? AND updated_at < NOW() - make_interval(secs => ?)))",
+		model.MediaTaskStatusPending, model.MediaTaskStatusRunning, lease.Seconds())
+}
+
 func (a *MediaTaskDBAdapter) Update(ctx context.Context, task *model.MediaTask) error {
 	return a.db.WithContext(ctx).Save(task).Error
 }
 
 func (a *MediaTaskDBAdapter) UpdateStatus(ctx context.Context, id uuid.UUID, status model.MediaTaskStatus, progress int, output, errMsg string) error {
+	return a.db.WithContext(ctx).Model(&model.MediaTask{}).Where("id = ?", id).Updates(statusUpdates(status, progress, output, errMsg)).Error
+}
+
+func (a *MediaTaskDBAdapter) TransitionStatus(ctx context.Context, id uuid.UUID, from []model.MediaTaskStatus, status model.MediaTaskStatus, progress int, output, errMsg string) (bool, error) {
+	result := a.db.WithContext(ctx).
+		Model(&model.MediaTask{}).
+		Where("id = ? AND status IN ?", id, from).
+		Updates(statusUpdates(status, progress, output, errMsg))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (a *MediaTaskDBAdapter) RecordExternalTask(ctx context.Context, id uuid.UUID, modelID, externalTaskID string, progress int) (bool, error) {
+	result := a.db.WithContext(ctx).
+		Model(&model.MediaTask{}).
+		Where("id = ? AND status = ?", id, model.MediaTaskStatusRunning).
+		Updates(map[string]interface{}{
+			"model_id":         modelID,
+			"external_task_id": externalTaskID,
+			"progress":         progress,
+			"updated_at":       gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// statusUpdates returns the columns a status update writes. Empty output
+// and error keep their current values.
+func statusUpdates(status model.MediaTaskStatus, progress int, output, errMsg string) map[string]interface{} {
 	updates := map[string]interface{}{
 		"status":     status,
 		"progress":   progress,
@@ -196,7 +266,7 @@ func (a *MediaTaskDBAdapter) UpdateStatus(ctx context.Context, id uuid.UUID, sta
 	if errMsg != "" {
 		updates["error"] = errMsg
 	}
-	return a.db.WithContext(ctx).Model(&model.MediaTask{}).Where("id = ?", id).Updates(updates).Error
+	return updates
 }
 
 func (a *MediaTaskDBAdapter) Delete(ctx context.Context, id uuid.UUID) error {
@@ -204,3 +274,114 @@ func (a *MediaTaskDBAdapter) Delete(ctx context.Context, id uuid.UUID) error {
 }
 
 var _ outbound.MediaTaskDatabasePort = (*MediaTaskDBAdapter)(nil)
+
+// --- Asset Database Adapter ---
+
+// MediaAssetDBAdapter implements MediaAssetDatabasePort.
+type MediaAssetDBAdapter struct {
+	db *gorm.DB
+}
+
+// NewMediaAssetDBAdapter creates a new media asset database adapter.
+func NewMediaAssetDBAdapter(db *gorm.DB) *MediaAssetDBAdapter {
+	return &MediaAssetDBAdapter{db: db}
+}
+
+func (a *MediaAssetDBAdapter) Create(ctx context.Context, asset *model.MediaAsset) error {
+	return a.db.WithContext(ctx).Create(asset).Error
+}
+
+func (a *MediaAssetDBAdapter) FindByID(ctx context.Context, id uuid.UUID) (*model.MediaAsset, error) {
+	var asset model.MediaAsset
+	if err := a.db.WithContext(ctx).First(&asset, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (a *MediaAssetDBAdapter) FindByOwnerAndHash(ctx context.Context, ownerID uuid.UUID, contentHash string) (*model.MediaAsset, error) {
+	var asset model.MediaAsset
+	if err := a.db.WithContext(ctx).
+		First(&asset, "owner_id = ? AND content_hash = ?", ownerID, contentHash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (a *MediaAssetDBAdapter) Find(ctx context.Context, filter *model.MediaAssetFilter) ([]*model.MediaAsset, int64, error) {
+	query := a.db.WithContext(ctx).Model(&model.MediaAsset{})
+
+	if filter.OwnerID != nil {
+		query = query.Where("owner_id = ?", *filter.OwnerID)
+	}
+	if filter.TeamID != nil {
+		query = query.Where("team_id = ?", *filter.TeamID)
+	}
+	if filter.Type != "" {
+		query = query.Where("type = ?", filter.Type)
+	}
+	if filter.ModelID != "" {
+		query = query.Where("model_id = ?", filter.ModelID)
+	}
+	if filter.Search != "" {
+		query = query.Where("name ILIKE ? OR prompt ILIKE ?",
+			"%"+filter.Search+"%", "%"+filter.Search+"%")
+	}
+	if len(filter.Tags) > 0 {
+		query = query.Where("tags @> ?", pq.StringArray(filter.Tags))
+	}
+	if filter.Folder != nil {
+		query = query.Where("folder = ?", *filter.Folder)
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+	if filter.Deleted {
+		query = query.Where("deleted_at IS NOT NULL")
+	} else {
+		query = query.Where("deleted_at IS NULL")
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	var assets []*model.MediaAsset
+	if err := query.Order("created_at DESC").Find(&assets).Error; err != nil {
+		return nil, 0, err
+	}
+	return assets, total, nil
+}
+
+func (a *MediaAssetDBAdapter) Update(ctx context.Context, asset *model.MediaAsset) error {
+	return a.db.WithContext(ctx).Save(asset).Error
+}
+
+func (a *MediaAssetDBAdapter) SumSizeByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	var total int64
+	err := a.db.WithContext(ctx).
+		Model(&model.MediaAsset{}).
+		Where("owner_id = ?", ownerID).
+		Select("COALESCE(SUM(size_bytes), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+var _ outbound.MediaAssetDatabasePort = (*MediaAssetDBAdapter)(nil)
@@ -127,5 +127,15 @@ func (a *usageRecordAdapter) GetDailyRequests(ctx context.Context, userID uuid.U
 	return int(count), err
 }
 
+func (a *usageRecordAdapter) GetVideoSeconds(ctx context.Context, userID uuid.UUID, start time.Time) (int64, error) {
+	var total int64
+	err := a.db.WithContext(ctx).
+		Model(&model.UsageRecord{}).
+		Select("COALESCE(SUM(video_seconds), 0)").
+		Where("user_id = ? AND timestamp >= ? AND success = ?", userID, start, true).
+		Scan(&total).Error
+	return total, err
+}
+
 // Compile-time check
 var _ outbound.UsageRecordDatabasePort = (*usageRecordAdapter)(nil)
@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/outbound"
+)
+
+// --- Endpoint Database Adapter ---
+
+// WebhookEndpointDBAdapter implements WebhookEndpointDatabasePort.
+type WebhookEndpointDBAdapter struct {
+	db *gorm.DB
+}
+
+// NewWebhookEndpointDBAdapter creates a new webhook endpoint database adapter.
+func NewWebhookEndpointDBAdapter(db *gorm.DB) *WebhookEndpointDBAdapter {
+	return &WebhookEndpointDBAdapter{db: db}
+}
+
+func (a *WebhookEndpointDBAdapter) Create(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+	return a.db.WithContext(ctx).Create(endpoint).Error
+}
+
+func (a *WebhookEndpointDBAdapter) FindByID(ctx context.Context, id uuid.UUID) (*model.WebhookEndpoint, error) {
+	var endpoint model.WebhookEndpoint
+	if err := a.db.WithContext(ctx).First(&endpoint, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &endpoint, nil
+}
+
+func (a *WebhookEndpointDBAdapter) FindByOwner(ctx context.Context, ownerID uuid.UUID) ([]*model.WebhookEndpoint, error) {
+	var endpoints []*model.WebhookEndpoint
+	err := a.db.WithContext(ctx).
+		Where("owner_id = ?", ownerID).
+		Order("created_at DESC").
+		Find(&endpoints).Error
+	if err != nil {
+		return nil, err
+	}
+	return endpoints, nil
+}
+
+func (a *WebhookEndpointDBAdapter) CountByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	var count int64
+	err := a.db.WithContext(ctx).
+		Model(&model.WebhookEndpoint{}).
+		Where("owner_id = ?", ownerID).
+		Count(&count).Error
+	return count, err
+}
+
+func (a *WebhookEndpointDBAdapter) Update(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+	return a.db.WithContext(ctx).Save(endpoint).Error
+}
+
+func (a *WebhookEndpointDBAdapter) Delete(ctx context.Context, id uuid.UUID) error {
+	return a.db.WithContext(ctx).Delete(&model.WebhookEndpoint{}, "id = ?", id).Error
+}
+
+func (a *WebhookEndpointDBAdapter) RecordSuccess(ctx context.Context, id uuid.UUID) error {
+	return a.db.WithContext(ctx).
+		Model(&model.WebhookEndpoint{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"consecutive_failures": 0,
+			"last_delivery_at":     time.Now(),
+		}).Error
+}
+
+func (a *WebhookEndpointDBAdapter) RecordFailure(ctx context.Context, id uuid.UUID) (int, error) {
+	// Increment in the database so concurrent deliveries all count.
+	var failures int
+	err := a.db.WithContext(ctx).
+		Raw(`UPDATE webhook_endpoints
+			SET consecutive_failures = consecutive_failures + 1, updated_at = NOW()
+			WHERE id = ?
+			RETURNING consecutive_failures`, id).
+		Scan(&failures).Error
+	return failures, err
+}
+
+func (a *WebhookEndpointDBAdapter) Disable(ctx context.Context, id uuid.UUID, reason string) error {
+	return a.db.WithContext(ctx).
+		Model(&model.WebhookEndpoint{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"active":          false,
+			"disabled_reason": reason,
+		}).Error
+}
+
+var _ outbound.WebhookEndpointDatabasePort = (*WebhookEndpointDBAdapter)(nil)
+
+// --- Delivery Database Adapter ---
+
+// WebhookDeliveryDBAdapter implements WebhookDeliveryDatabasePort.
+type WebhookDeliveryDBAdapter struct {
+	db *gorm.DB
+}
+
+// NewWebhookDeliveryDBAdapter creates a new webhook delivery database adapter.
+func NewWebhookDeliveryDBAdapter(db *gorm.DB) *WebhookDeliveryDBAdapter {
+	return &WebhookDeliveryDBAdapter{db: db}
+}
+
+func (a *WebhookDeliveryDBAdapter) Create(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+	// The unique index on (endpoint_id, event_id) for first deliveries makes
+	// a repeated event a no-op.
+	result := a.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(delivery)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (a *WebhookDeliveryDBAdapter) FindByID(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	if err := a.db.WithContext(ctx).First(&delivery, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (a *WebhookDeliveryDBAdapter) FindByEndpoint(ctx context.Context, endpointID uuid.UUID, limit, offset int) ([]*model.WebhookDelivery, int64, error) {
+	query := a.db.WithContext(ctx).
+		Model(&model.WebhookDelivery{}).
+		Where("endpoint_id = ?", endpointID)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var deliveries []*model.WebhookDelivery
+	err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+func (a *WebhookDeliveryDBAdapter) Update(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return a.db.WithContext(ctx).Save(delivery).Error
+}
+
+var _ outbound.WebhookDeliveryDatabasePort = (*WebhookDeliveryDBAdapter)(nil)
@@ -12,8 +12,10 @@ import (
 )
 
 const (
-	mediaHealthKeyPrefix = "media:health:"
-	mediaHealthTTL       = 5 * time.Minute
+	mediaHealthKeyPrefix   = "media:health:"
+	mediaFailuresKeyPrefix = "media:failures:"
+	mediaHealthTTL         = 5 * time.Minute
+	mediaFailureWindow     = time.Minute
 )
 
 // MediaHealthCacheAdapter implements MediaProviderHealthCachePort.
@@ -48,8 +50,28 @@ func (a *MediaHealthCacheAdapter) SetHealth(ctx context.Context, providerID uuid
 	if err := a.client.Set(ctx, key, val, mediaHealthTTL).Err(); err != nil {
 		return fmt.Errorf("set health: %w", err)
 	}
+	if healthy {
+		if err := a.client.Del(ctx, mediaFailuresKeyPrefix+providerID.String()).Err(); err != nil {
+			return fmt.Errorf("clear failures: %w", err)
+		}
+	}
 	return nil
 }
 
+// RecordFailure counts failures in a window that starts at the first one.
+func (a *MediaHealthCacheAdapter) RecordFailure(ctx context.Context, providerID uuid.UUID) (int64, error) {
+	key := mediaFailuresKeyPrefix + providerID.String()
+	failures, err := a.client.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("record failure: %w", err)
+	}
+	if failures == 1 {
+		if err := a.client.Expire(ctx, key, mediaFailureWindow).Err(); err != nil {
+			return 0, fmt.Errorf("record failure: %w", err)
+		}
+	}
+	return failures, nil
+}
+
 // Compile-time interface check
 var _ outbound.MediaProviderHealthCachePort = (*MediaHealthCacheAdapter)(nil)
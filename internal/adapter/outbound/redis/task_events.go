@@ -0,0 +1,76 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/outbound"
+)
+
+const (
+	taskEventChannelPrefix = "task:events:"
+	taskEventBufferSize    = 16
+)
+
+// TaskEventAdapter implements TaskEventPort over Redis pub/sub, one channel
+// per task.
+type TaskEventAdapter struct {
+	client redis.UniversalClient
+}
+
+// NewTaskEventAdapter creates a new task event adapter.
+func NewTaskEventAdapter(client redis.UniversalClient) *TaskEventAdapter {
+	return &TaskEventAdapter{client: client}
+}
+
+func (a *TaskEventAdapter) Publish(ctx context.Context, event *model.TaskEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal task event: %w", err)
+	}
+	if err := a.client.Publish(ctx, taskEventChannelPrefix+event.TaskID.String(), data).Err(); err != nil {
+		return fmt.Errorf("publish task event: %w", err)
+	}
+	return nil
+}
+
+func (a *TaskEventAdapter) Subscribe(ctx context.Context, taskID uuid.UUID) (<-chan *model.TaskEvent, func(), error) {
+	pubsub := a.client.Subscribe(ctx, taskEventChannelPrefix+taskID.String())
+
+	// Wait for the confirmation so no event published from here on is missed.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, nil, fmt.Errorf("subscribe task events: %w", err)
+	}
+
+	events := make(chan *model.TaskEvent, taskEventBufferSize)
+	go func() {
+		defer close(events)
+		for msg := range pubsub.Channel() {
+			var event model.TaskEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				continue
+			}
+			select {
+			case events <- &event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() { pubsub.Close() })
+	}
+	return events, unsubscribe, nil
+}
+
+// Compile-time interface check
+var _ outbound.TaskEventPort = (*TaskEventAdapter)(nil)
@@ -0,0 +1,226 @@
+package redis
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+// pubSubServer is a minimal Redis server speaking RESP2 that supports
+// SUBSCRIBE and PUBLISH.
+type pubSubServer struct {
+	ln          net.Listener
+	mu          sync.Mutex
+	subscribers map[string][]net.Conn
+}
+
+func newPubSubServer(t *testing.T) *pubSubServer {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &pubSubServer{ln: ln, subscribers: make(map[string][]net.Conn)}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *pubSubServer) client(t *testing.T) *redis.Client {
+	client := redis.NewClient(&redis.Options{Addr: s.ln.Addr().String(), DisableIndentity: true})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func (s *pubSubServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			s.drop(conn)
+			return
+		}
+
+		switch strings.ToUpper(args[0]) {
+		case "HELLO":
+			s.write(conn, "-ERR unknown command\r\n")
+		case "SUBSCRIBE":
+			s.mu.Lock()
+			s.subscribers[args[1]] = append(s.subscribers[args[1]], conn)
+			s.mu.Unlock()
+			s.write(conn, "*3\r\n"+bulk("subscribe")+bulk(args[1])+":1\r\n")
+		case "PUBLISH":
+			s.mu.Lock()
+			subscribers := append([]net.Conn(nil), s.subscribers[args[1]]...)
+			s.mu.Unlock()
+			for _, sub := range subscribers {
+				s.write(sub, "*3\r\n"+bulk("message")+bulk(args[1])+bulk(args[2]))
+			}
+			s.write(conn, fmt.Sprintf(":%d\r\n", len(subscribers)))
+		default:
+			s.write(conn, "+OK\r\n")
+		}
+	}
+}
+
+// write sends a reply. Replies and published messages to a connection are
+// not interleaved.
+func (s *pubSubServer) write(conn net.Conn, reply string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	io.WriteString(conn, reply)
+}
+
+func (s *pubSubServer) drop(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for channel, conns := range s.subscribers {
+		for i, c := range conns {
+			if c == conn {
+				s.subscribers[channel] = append(conns[:i], conns[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, n)
+	for i := range args {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(line[1:]))
+		if err != nil {
+			return nil, err
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func receiveEvent(t *testing.T, events <-chan *model.TaskEvent) (*model.TaskEvent, bool) {
+	t.Helper()
+	select {
+	case event, ok := <-events:
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for task event")
+		return nil, false
+	}
+}
+
+func TestTaskEventAdapter_PublishSubscribe(t *testing.T) {
+	server := newPubSubServer(t)
+	adapter := NewTaskEventAdapter(server.client(t))
+	ctx := context.Background()
+	taskID := uuid.New()
+
+	events, unsubscribe, err := adapter.Subscribe(ctx, taskID)
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	// Events of other tasks are not delivered
+	require.NoError(t, adapter.Publish(ctx, &model.TaskEvent{TaskID: uuid.New(), Status: "running"}))
+
+	published := &model.TaskEvent{
+		TaskID:    taskID,
+		OwnerID:   uuid.New(),
+		Source:    model.TaskEventSourceTask,
+		Status:    "running",
+		Progress:  40,
+		UpdatedAt: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	require.NoError(t, adapter.Publish(ctx, published))
+
+	event, ok := receiveEvent(t, events)
+	require.True(t, ok)
+	assert.Equal(t, published, event)
+}
+
+func TestTaskEventAdapter_SkipsMalformedEvents(t *testing.T) {
+	server := newPubSubServer(t)
+	client := server.client(t)
+	adapter := NewTaskEventAdapter(client)
+	ctx := context.Background()
+	taskID := uuid.New()
+
+	events, unsubscribe, err := adapter.Subscribe(ctx, taskID)
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	require.NoError(t, client.Publish(ctx, taskEventChannelPrefix+taskID.String(), "not json").Err())
+	require.NoError(t, adapter.Publish(ctx, &model.TaskEvent{TaskID: taskID, Status: "completed"}))
+
+	event, ok := receiveEvent(t, events)
+	require.True(t, ok)
+	assert.Equal(t, "completed", event.Status)
+}
+
+func TestTaskEventAdapter_UnsubscribeClosesEvents(t *testing.T) {
+	server := newPubSubServer(t)
+	adapter := NewTaskEventAdapter(server.client(t))
+
+	events, unsubscribe, err := adapter.Subscribe(context.Background(), uuid.New())
+	require.NoError(t, err)
+
+	unsubscribe()
+	unsubscribe()
+
+	_, ok := receiveEvent(t, events)
+	assert.False(t, ok)
+}
+
+func TestTaskEventAdapter_SubscribeFails(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	ln.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+	defer client.Close()
+	adapter := NewTaskEventAdapter(client)
+
+	_, _, err = adapter.Subscribe(context.Background(), uuid.New())
+
+	assert.ErrorContains(t, err, "subscribe task events")
+}
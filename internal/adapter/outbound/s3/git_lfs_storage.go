@@ -105,6 +105,20 @@ func (a *GitLFSStorageAdapter) Delete(ctx context.Context, oid string) error {
 	return nil
 }
 
+// Copy copies an LFS object server-side.
+func (a *GitLFSStorageAdapter) Copy(ctx context.Context, src, dst string) error {
+	_, err := a.client.CopyObject(ctx, &s3.CopyObjectInput{
+		Bucket:     aws.String(a.bucket),
+		CopySource: aws.String(copySource(a.bucket, a.key(src))),
+		Key:        aws.String(a.key(dst)),
+	})
+	if err != nil {
+		return fmt.Errorf("copy LFS object: %w", err)
+	}
+
+	return nil
+}
+
 // GenerateUploadURL generates a presigned upload URL.
 func (a *GitLFSStorageAdapter) GenerateUploadURL(ctx context.Context, oid string, size int64, expiry time.Duration) (*outbound.GitPresignedURL, error) {
 	req, err := a.presigner.PresignPutObject(ctx, &s3.PutObjectInput{
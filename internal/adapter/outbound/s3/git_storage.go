@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"path"
 	"sort"
@@ -114,6 +115,47 @@ func (a *GitStorageAdapter) GetRepositorySize(ctx context.Context, storagePath s
 	return totalSize, nil
 }
 
+// CopyRepository copies every object of a repository to another prefix.
+// Objects are copied server-side, so no content passes through this process.
+func (a *GitStorageAdapter) CopyRepository(ctx context.Context, srcPath, dstPath string) error {
+	paginator := s3.NewListObjectsV2Paginator(a.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(a.bucket),
+		Prefix: aws.String(srcPath),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("list objects: %w", err)
+		}
+
+		for _, obj := range page.Contents {
+			key := aws.ToString(obj.Key)
+			_, err := a.client.CopyObject(ctx, &s3.CopyObjectInput{
+				Bucket:     aws.String(a.bucket),
+				CopySource: aws.String(copySource(a.bucket, key)),
+				Key:        aws.String(dstPath + strings.TrimPrefix(key, srcPath)),
+			})
+			if err != nil {
+				return fmt.Errorf("copy object %s: %w", key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// copySource returns the CopySource of an object. S3 expects it URL-encoded,
+// and keys may hold characters like '+', '%' or spaces from ref names. '+'
+// is escaped too, as some S3 implementations decode it as a space.
+func copySource(bucket, key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+	}
+	return url.PathEscape(bucket) + "/" + strings.Join(segments, "/")
+}
+
 // Compile-time check
 var _ outbound.GitStoragePort = (*GitStorageAdapter)(nil)
 
@@ -300,7 +342,7 @@ func (fs *R2Filesystem) Rename(oldpath, newpath string) error {
 	// Copy to new location
 	_, err := fs.client.CopyObject(ctx, &s3.CopyObjectInput{
 		Bucket:     aws.String(fs.bucket),
-		CopySource: aws.String(fs.bucket + "/" + oldKey),
+		CopySource: aws.String(copySource(fs.bucket, oldKey)),
 		Key:        aws.String(newKey),
 	})
 	if err != nil {
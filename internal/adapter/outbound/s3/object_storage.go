@@ -0,0 +1,118 @@
+package s3
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/uniedit/server/internal/port/outbound"
+)
+
+// ObjectStorageAdapter implements StoragePort using R2/S3.
+type ObjectStorageAdapter struct {
+	client    *s3.Client
+	presigner *s3.PresignClient
+	bucket    string
+}
+
+// NewObjectStorageAdapter creates a new object storage adapter.
+func NewObjectStorageAdapter(client *s3.Client, bucket string) *ObjectStorageAdapter {
+	return &ObjectStorageAdapter{
+		client:    client,
+		presigner: s3.NewPresignClient(client),
+		bucket:    bucket,
+	}
+}
+
+// NewClient creates an S3 client for an S3-compatible endpoint with static
+// credentials. An empty endpoint uses AWS.
+func NewClient(endpoint, region, accessKeyID, secretAccessKey string) *s3.Client {
+	if region == "" {
+		region = "auto"
+	}
+	creds := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: secretAccessKey,
+		}, nil
+	})
+
+	return s3.New(s3.Options{
+		Region:       region,
+		Credentials:  aws.NewCredentialsCache(creds),
+		UsePathStyle: endpoint != "",
+	}, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+}
+
+// Put uploads an object.
+func (a *ObjectStorageAdapter) Put(ctx context.Context, key string, reader io.Reader, size int64) error {
+	_, err := a.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:        aws.String(a.bucket),
+		Key:           aws.String(key),
+		Body:          reader,
+		ContentLength: aws.Int64(size),
+	})
+	if err != nil {
+		return fmt.Errorf("put object: %w", err)
+	}
+
+	return nil
+}
+
+// Get retrieves an object.
+func (a *ObjectStorageAdapter) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	result, err := a.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var nsk *types.NoSuchKey
+		if errors.As(err, &nsk) {
+			return nil, ErrObjectNotFound
+		}
+		return nil, fmt.Errorf("get object: %w", err)
+	}
+
+	return result.Body, nil
+}
+
+// Delete removes an object.
+func (a *ObjectStorageAdapter) Delete(ctx context.Context, key string) error {
+	_, err := a.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("delete object: %w", err)
+	}
+
+	return nil
+}
+
+// GetPresignedURL generates a presigned download URL.
+func (a *ObjectStorageAdapter) GetPresignedURL(ctx context.Context, key string, duration time.Duration) (string, error) {
+	req, err := a.presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(a.bucket),
+		Key:    aws.String(key),
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = duration
+	})
+	if err != nil {
+		return "", fmt.Errorf("presign download: %w", err)
+	}
+
+	return req.URL, nil
+}
+
+// Compile-time check
+var _ outbound.StoragePort = (*ObjectStorageAdapter)(nil)
@@ -0,0 +1,145 @@
+// Package webhook sends webhook deliveries to user-supplied URLs.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/uniedit/server/internal/port/outbound"
+)
+
+const (
+	defaultSendTimeout = 10 * time.Second
+	// maxResponseBytes is how much of a response is read; receivers only
+	// need to acknowledge, so anything longer is logged truncated.
+	maxResponseBytes = 4096
+)
+
+// errForbiddenAddress is returned when a URL resolves to a non-public address.
+var errForbiddenAddress = errors.New("webhook URL resolves to a forbidden address")
+
+// Sender implements outbound.WebhookSenderPort.
+//
+// Endpoint URLs are chosen by users, so requests are only made to public
+// addresses. The check runs at dial time, covering DNS names that resolve
+// to internal addresses. Redirects are not followed.
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender creates a webhook sender with the given per-request timeout.
+func NewSender(timeout time.Duration) *Sender {
+	if timeout <= 0 {
+		timeout = defaultSendTimeout
+	}
+
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !isPublicIP(ip) {
+				return errForbiddenAddress
+			}
+			return nil
+		},
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // never route through an environment proxy
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          50,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	return &Sender{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Send posts body to rawURL and returns the response status and the start
+// of the response body.
+func (s *Sender) Send(ctx context.Context, rawURL string, headers map[string]string, body []byte) (int, []byte, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, nil, fmt.Errorf("parse webhook URL: %w", err)
+	}
+	if err := checkURL(u); err != nil {
+		return 0, nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, fmt.Errorf("create request: %w", err)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	// Drain a little more so the connection can be reused.
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	return resp.StatusCode, data, nil
+}
+
+// checkURL rejects non-HTTP schemes and literal non-public hosts.
+func checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported webhook URL scheme %q", u.Scheme)
+	}
+	if u.User != nil {
+		return fmt.Errorf("webhook URL must not contain credentials")
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !isPublicIP(ip) {
+		return errForbiddenAddress
+	}
+	return nil
+}
+
+// isPublicIP reports whether ip is a globally routable unicast address.
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		// 100.64.0.0/10 carrier-grade NAT and 0.0.0.0/8
+		if ip4[0] == 100 && ip4[1]&0xc0 == 64 {
+			return false
+		}
+		if ip4[0] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Compile-time check
+var _ outbound.WebhookSenderPort = (*Sender)(nil)
@@ -2,7 +2,10 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -15,6 +18,7 @@ import (
 	"github.com/uniedit/server/internal/domain/ai"
 	"github.com/uniedit/server/internal/domain/auth"
 	"github.com/uniedit/server/internal/domain/billing"
+	"github.com/uniedit/server/internal/domain/git"
 	"github.com/uniedit/server/internal/domain/order"
 	"github.com/uniedit/server/internal/domain/payment"
 	"github.com/uniedit/server/internal/domain/user"
@@ -28,15 +32,20 @@ import (
 	mediahttp "github.com/uniedit/server/internal/adapter/inbound/http/media"
 	orderhttp "github.com/uniedit/server/internal/adapter/inbound/http/order"
 	paymenthttp "github.com/uniedit/server/internal/adapter/inbound/http/payment"
+	taskhttp "github.com/uniedit/server/internal/adapter/inbound/http/task"
 	userhttp "github.com/uniedit/server/internal/adapter/inbound/http/user"
+	webhookhttp "github.com/uniedit/server/internal/adapter/inbound/http/webhook"
 
 	// Inbound ports
+	"github.com/uniedit/server/internal/model"
 	"github.com/uniedit/server/internal/port/inbound"
 	"github.com/uniedit/server/internal/port/outbound"
 
 	// Infrastructure
 	"github.com/uniedit/server/internal/infra/config"
 	"github.com/uniedit/server/internal/infra/database"
+	"github.com/uniedit/server/internal/infra/events"
+	"github.com/uniedit/server/internal/infra/task"
 
 	// Utils
 	"github.com/uniedit/server/internal/utils/logger"
@@ -71,12 +80,16 @@ type App struct {
 	gitDomain           inbound.GitDomain
 	collaborationDomain inbound.CollaborationDomain
 	mediaDomain         inbound.MediaDomain
+	taskManager         *task.Manager
+	webhookDomain       inbound.WebhookDomain
 
 	// AI HTTP handlers
 	aiChatHandler          *aihttp.ChatHandler
 	aiProviderAdminHandler *aihttp.ProviderAdminHandler
 	aiModelAdminHandler    *aihttp.ModelAdminHandler
 	aiPublicHandler        *aihttp.PublicHandler
+	aiAliasHandler         *aihttp.AliasHandler
+	aiRequestLogHandler    *aihttp.RequestLogHandler
 
 	// Auth HTTP handlers
 	oauthHandler        *authhttp.OAuthHandler
@@ -104,7 +117,9 @@ type App struct {
 	webhookHandler *paymenthttp.WebhookHandler
 
 	// Git HTTP handlers
-	gitHandler *githttp.Handler
+	gitHandler          *githttp.Handler
+	gitSmartHTTPHandler *githttp.SmartHTTPHandler
+	gitLFSHandler       *githttp.LFSHandler
 
 	// Collaboration HTTP handlers
 	collaborationHandler *collaborationhttp.Handler
@@ -112,6 +127,12 @@ type App struct {
 	// Media HTTP handlers
 	mediaHandler *mediahttp.Handler
 
+	// Task HTTP handlers
+	taskHandler *taskhttp.Handler
+
+	// Webhook endpoint HTTP handlers
+	webhookEndpointHandler *webhookhttp.Handler
+
 	// Cleanup functions
 	cleanupFuncs []func()
 }
@@ -141,11 +162,15 @@ func New(cfg *config.Config) (*App, error) {
 		gitDomain:           deps.GitDomain,
 		collaborationDomain: deps.CollaborationDomain,
 		mediaDomain:         deps.MediaDomain,
+		taskManager:         deps.TaskManager,
+		webhookDomain:       deps.WebhookDomain,
 		// AI HTTP handlers
 		aiChatHandler:          deps.AIChatHandler,
 		aiProviderAdminHandler: deps.AIProviderAdminHandler,
 		aiModelAdminHandler:    deps.AIModelAdminHandler,
 		aiPublicHandler:        deps.AIPublicHandler,
+		aiAliasHandler:         deps.AIAliasHandler,
+		aiRequestLogHandler:    deps.AIRequestLogHandler,
 		// Auth HTTP handlers
 		oauthHandler:         deps.OAuthHandler,
 		apiKeyHandler:        deps.APIKeyHandler,
@@ -163,9 +188,14 @@ func New(cfg *config.Config) (*App, error) {
 		refundHandler:        deps.RefundHandler,
 		webhookHandler:       deps.WebhookHandler,
 		gitHandler:           deps.GitHandler,
+		gitSmartHTTPHandler:  deps.GitSmartHTTPHandler,
+		gitLFSHandler:        deps.GitLFSHandler,
 		collaborationHandler: deps.CollaborationHandler,
 		mediaHandler:         deps.MediaHandler,
-		cleanupFuncs:         []func(){cleanup},
+		taskHandler:          deps.TaskHandler,
+		// Webhook endpoint HTTP handlers
+		webhookEndpointHandler: deps.WebhookEndpointHandler,
+		cleanupFuncs:           []func(){cleanup},
 	}
 
 	// Initialize router
@@ -178,6 +208,19 @@ func New(cfg *config.Config) (*App, error) {
 		app.aiDomain.StopHealthMonitor()
 	})
 
+	// Start executing queued media tasks. The worker must stop before the
+	// database closes so in-flight tasks can be requeued.
+	app.mediaDomain.StartWorker(ctx)
+	app.cleanupFuncs = append([]func(){app.mediaDomain.StopWorker}, app.cleanupFuncs...)
+
+	// Start claiming generic async tasks; Stop releases leases, so it too
+	// must run before the database closes.
+	if err := app.taskManager.Start(ctx); err != nil {
+		app.Stop()
+		return nil, fmt.Errorf("start task manager: %w", err)
+	}
+	app.cleanupFuncs = append([]func(){app.taskManager.Stop}, app.cleanupFuncs...)
+
 	// Register routes
 	app.registerRoutes()
 
@@ -336,6 +379,14 @@ func (a *App) registerRoutes() {
 		a.gitHandler.RegisterRoutes(v1, authMiddleware)
 	}
 
+	// Git smart HTTP transport, served at the clone URL outside /api/v1
+	if a.gitSmartHTTPHandler != nil {
+		a.gitSmartHTTPHandler.RegisterRoutes(a.router)
+	}
+	if a.gitLFSHandler != nil {
+		a.gitLFSHandler.RegisterRoutes(a.router)
+	}
+
 	// Collaboration routes
 	if a.collaborationHandler != nil {
 		a.collaborationHandler.RegisterRoutes(v1, authMiddleware)
@@ -346,10 +397,19 @@ func (a *App) registerRoutes() {
 		a.mediaHandler.RegisterRoutes(v1, authMiddleware)
 	}
 
+	// Task event streams
+	if a.taskHandler != nil {
+		a.taskHandler.RegisterRoutes(protectedRouter)
+	}
+
+	// Webhook endpoints
+	if a.webhookEndpointHandler != nil {
+		a.webhookEndpointHandler.RegisterRoutes(protectedRouter)
+	}
+
 	// ===== Admin Routes (requires admin auth) =====
-	// TODO: Add admin middleware when available
 	adminRouter := protectedRouter.Group("")
-	// adminRouter.Use(middleware.RequireAdmin())
+	adminRouter.Use(middleware.RequireAdmin(middleware.NewUserDomainAdminChecker(a.userDomain.GetUser)))
 
 	// User admin routes
 	if a.userAdminHandler != nil {
@@ -396,6 +456,33 @@ func (a *App) registerRoutes() {
 			aiAdminGroup.DELETE("/models/:id", a.aiModelAdminHandler.DeleteModel)
 		}
 	}
+
+	// AI model alias admin routes
+	if a.aiAliasHandler != nil {
+		aiAdminGroup := adminRouter.Group("/admin/ai")
+		{
+			aiAdminGroup.GET("/aliases", a.aiAliasHandler.ListAliases)
+			aiAdminGroup.POST("/aliases", a.aiAliasHandler.CreateAlias)
+			aiAdminGroup.GET("/aliases/:id", a.aiAliasHandler.GetAlias)
+			aiAdminGroup.PUT("/aliases/:id", a.aiAliasHandler.UpdateAlias)
+			aiAdminGroup.DELETE("/aliases/:id", a.aiAliasHandler.DeleteAlias)
+		}
+	}
+
+	// AI request log admin routes
+	if a.aiRequestLogHandler != nil {
+		aiAdminGroup := adminRouter.Group("/admin/ai")
+		{
+			aiAdminGroup.GET("/request-logs", a.aiRequestLogHandler.ListRequestLogs)
+			aiAdminGroup.GET("/request-logs/:id", a.aiRequestLogHandler.GetRequestLog)
+			aiAdminGroup.POST("/request-logs/:id/replay", a.aiRequestLogHandler.ReplayRequest)
+		}
+	}
+
+	// Task admin routes (dead-letter inspection and requeue)
+	if a.taskHandler != nil {
+		a.taskHandler.RegisterAdminRoutes(adminRouter)
+	}
 }
 
 // Router returns the HTTP router.
@@ -528,13 +615,476 @@ func (a *billingReaderAdapter) AddCredits(ctx context.Context, userID uuid.UUID,
 	return a.domain.AddCredits(ctx, userID, amount, source)
 }
 
-// noOpEventPublisher is a no-op implementation of outbound.EventPublisherPort.
-type noOpEventPublisher struct{}
+// mediaVideoQuotaAdapter adapts BillingDomain to outbound.MediaVideoQuotaPort.
+type mediaVideoQuotaAdapter struct {
+	domain billing.BillingDomain
+}
+
+func newMediaVideoQuotaAdapter(domain billing.BillingDomain) outbound.MediaVideoQuotaPort {
+	return &mediaVideoQuotaAdapter{domain: domain}
+}
+
+func (a *mediaVideoQuotaAdapter) GetVideoSecondsRemaining(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return a.domain.GetVideoSecondsRemaining(ctx, userID)
+}
+
+func (a *mediaVideoQuotaAdapter) RecordVideoUsage(ctx context.Context, userID, taskID, providerID uuid.UUID, modelID string, seconds int) error {
+	return a.domain.RecordUsage(ctx, userID, &billing.RecordUsageInput{
+		RequestID:    taskID.String(),
+		TaskType:     "video",
+		ProviderID:   providerID,
+		ModelID:      modelID,
+		VideoSeconds: seconds,
+		Success:      true,
+	})
+}
+
+// teamAccessAdapter adapts CollaborationDomain to the team access ports of
+// the media and Git domains.
+type teamAccessAdapter struct {
+	domain inbound.CollaborationDomain
+}
+
+func newTeamAccessAdapter(domain inbound.CollaborationDomain) *teamAccessAdapter {
+	return &teamAccessAdapter{domain: domain}
+}
 
-func newNoOpEventPublisher() outbound.EventPublisherPort {
-	return &noOpEventPublisher{}
+func (a *teamAccessAdapter) GetTeamRole(ctx context.Context, teamID, userID uuid.UUID) (model.TeamRole, error) {
+	member, err := a.domain.GetMember(ctx, teamID, userID)
+	if err != nil {
+		if errors.Is(err, outbound.ErrMemberNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// taskEventPublishTimeout bounds publishing one task event, so a slow bus
+// does not hold up the events queued behind it.
+const taskEventPublishTimeout = 2 * time.Second
+
+// newTaskEventPublisher returns a task manager subscriber that publishes
+// every task change as a task event.
+func newTaskEventPublisher(events outbound.TaskEventPort, logger *zap.Logger) func(*task.Task) {
+	return func(t *task.Task) {
+		ctx, cancel := context.WithTimeout(context.Background(), taskEventPublishTimeout)
+		defer cancel()
+		if err := events.Publish(ctx, t.Event()); err != nil {
+			logger.Warn("Failed to publish task event",
+				zap.String("task_id", t.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
 }
 
-func (p *noOpEventPublisher) Publish(ctx context.Context, event interface{}) error {
+// mediaTaskEventsAdapter publishes a task event whenever a media task is
+// written through it, and reports finished tasks to webhooks. Either
+// target may be nil.
+type mediaTaskEventsAdapter struct {
+	outbound.MediaTaskDatabasePort
+	events   outbound.TaskEventPort
+	webhooks inbound.WebhookDomain
+	logger   *zap.Logger
+}
+
+func newMediaTaskEventsAdapter(taskDB outbound.MediaTaskDatabasePort, events outbound.TaskEventPort, webhooks inbound.WebhookDomain, logger *zap.Logger) outbound.MediaTaskDatabasePort {
+	return &mediaTaskEventsAdapter{MediaTaskDatabasePort: taskDB, events: events, webhooks: webhooks, logger: logger}
+}
+
+func (a *mediaTaskEventsAdapter) Create(ctx context.Context, t *model.MediaTask) error {
+	if err := a.MediaTaskDatabasePort.Create(ctx, t); err != nil {
+		return err
+	}
+	a.publish(ctx, t)
+	return nil
+}
+
+func (a *mediaTaskEventsAdapter) Update(ctx context.Context, t *model.MediaTask) error {
+	if err := a.MediaTaskDatabasePort.Update(ctx, t); err != nil {
+		return err
+	}
+	a.publish(ctx, t)
+	return nil
+}
+
+func (a *mediaTaskEventsAdapter) UpdateStatus(ctx context.Context, id uuid.UUID, status model.MediaTaskStatus, progress int, output, errMsg string) error {
+	if err := a.MediaTaskDatabasePort.UpdateStatus(ctx, id, status, progress, output, errMsg); err != nil {
+		return err
+	}
+	a.publishStored(ctx, id)
 	return nil
 }
+
+func (a *mediaTaskEventsAdapter) TransitionStatus(ctx context.Context, id uuid.UUID, from []model.MediaTaskStatus, status model.MediaTaskStatus, progress int, output, errMsg string) (bool, error) {
+	updated, err := a.MediaTaskDatabasePort.TransitionStatus(ctx, id, from, status, progress, output, errMsg)
+	if err != nil || !updated {
+		return updated, err
+	}
+	a.publishStored(ctx, id)
+	return true, nil
+}
+
+func (a *mediaTaskEventsAdapter) RecordExternalTask(ctx context.Context, id uuid.UUID, modelID, externalTaskID string, progress int) (bool, error) {
+	updated, err := a.MediaTaskDatabasePort.RecordExternalTask(ctx, id, modelID, externalTaskID, progress)
+	if err != nil || !updated {
+		return updated, err
+	}
+	a.publishStored(ctx, id)
+	return true, nil
+}
+
+// publishStored publishes a task written by ID. The event carries the owner
+// and type, which only the stored row has.
+func (a *mediaTaskEventsAdapter) publishStored(ctx context.Context, id uuid.UUID) {
+	t, err := a.MediaTaskDatabasePort.FindByID(ctx, id)
+	if err != nil || t == nil {
+		a.logger.Warn("Failed to load media task for event",
+			zap.String("task_id", id.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	a.publish(ctx, t)
+}
+
+func (a *mediaTaskEventsAdapter) publish(ctx context.Context, t *model.MediaTask) {
+	event := t.Event()
+
+	if a.events != nil {
+		publishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), taskEventPublishTimeout)
+		if err := a.events.Publish(publishCtx, event); err != nil {
+			a.logger.Warn("Failed to publish media task event",
+				zap.String("task_id", t.ID.String()),
+				zap.Error(err),
+			)
+		}
+		cancel()
+	}
+
+	if a.webhooks != nil && event.IsTerminal() {
+		dispatchCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), webhookDispatchTimeout)
+		if err := a.webhooks.HandleTaskEvent(dispatchCtx, event); err != nil {
+			a.logger.Warn("Failed to dispatch media task webhook",
+				zap.String("task_id", t.ID.String()),
+				zap.Error(err),
+			)
+		}
+		cancel()
+	}
+}
+
+// busEventPublisher implements outbound.EventPublisherPort on the event bus.
+// The payment and git domains publish their own event structs, which are
+// converted to bus events here.
+type busEventPublisher struct {
+	bus *events.Bus
+}
+
+func newBusEventPublisher(bus *events.Bus) outbound.EventPublisherPort {
+	return &busEventPublisher{bus: bus}
+}
+
+func (p *busEventPublisher) Publish(ctx context.Context, event interface{}) error {
+	switch e := event.(type) {
+	case *payment.PaymentSucceededEvent:
+		p.bus.Publish(events.NewPaymentSucceededEvent(
+			e.PaymentID, e.OrderID, e.UserID,
+			e.Amount,
+			e.Currency, e.Provider, e.OrderType,
+			e.CreditsAmount,
+			e.PlanID,
+		))
+	case *payment.PaymentFailedEvent:
+		p.bus.Publish(events.NewPaymentFailedEvent(
+			e.PaymentID, e.OrderID, e.UserID,
+			e.FailureCode, e.FailureMessage, e.Provider,
+		))
+	case *git.RepoPushedEvent:
+		refs := make([]events.RepoRefChange, len(e.Refs))
+		for i, ref := range e.Refs {
+			refs[i] = events.RepoRefChange{Ref: ref.Ref, OldSHA: ref.OldSHA, NewSHA: ref.NewSHA}
+		}
+		p.bus.Publish(events.NewRepoPushedEvent(e.RepoID, e.OwnerID, e.PusherID, refs))
+	case events.Event:
+		p.bus.Publish(e)
+	default:
+		return fmt.Errorf("unsupported event type %T", event)
+	}
+	return nil
+}
+
+// billingEventsAdapter publishes a SubscriptionUpdated event whenever a
+// subscription changes through the billing domain.
+type billingEventsAdapter struct {
+	billing.BillingDomain
+	subscriptionDB outbound.SubscriptionDatabasePort
+	bus            *events.Bus
+	logger         *zap.Logger
+}
+
+func newBillingEventsAdapter(domain billing.BillingDomain, subscriptionDB outbound.SubscriptionDatabasePort, bus *events.Bus, logger *zap.Logger) billing.BillingDomain {
+	return &billingEventsAdapter{BillingDomain: domain, subscriptionDB: subscriptionDB, bus: bus, logger: logger}
+}
+
+func (a *billingEventsAdapter) CreateSubscription(ctx context.Context, userID uuid.UUID, planID string) (*model.Subscription, error) {
+	sub, err := a.BillingDomain.CreateSubscription(ctx, userID, planID)
+	if err != nil {
+		return nil, err
+	}
+	a.publish(sub)
+	return sub, nil
+}
+
+func (a *billingEventsAdapter) CancelSubscription(ctx context.Context, userID uuid.UUID, immediately bool) (*model.Subscription, error) {
+	sub, err := a.BillingDomain.CancelSubscription(ctx, userID, immediately)
+	if err != nil {
+		return nil, err
+	}
+	a.publish(sub)
+	return sub, nil
+}
+
+func (a *billingEventsAdapter) UpdateSubscriptionFromStripe(ctx context.Context, stripeSubID string, status model.SubscriptionStatus, periodStart, periodEnd time.Time, cancelAtPeriodEnd bool) error {
+	if err := a.BillingDomain.UpdateSubscriptionFromStripe(ctx, stripeSubID, status, periodStart, periodEnd, cancelAtPeriodEnd); err != nil {
+		return err
+	}
+
+	sub, err := a.subscriptionDB.GetByStripeID(ctx, stripeSubID)
+	if err != nil || sub == nil {
+		a.logger.Warn("Failed to load subscription for event",
+			zap.String("stripe_subscription_id", stripeSubID),
+			zap.Error(err),
+		)
+		return nil
+	}
+	a.publish(sub)
+	return nil
+}
+
+func (a *billingEventsAdapter) publish(sub *model.Subscription) {
+	a.bus.Publish(events.NewSubscriptionUpdatedEvent(
+		sub.ID, sub.UserID,
+		sub.PlanID, string(sub.Status),
+		sub.CurrentPeriodEnd,
+		sub.CancelAtPeriodEnd,
+	))
+}
+
+// webhookDeliveryTaskType is the task manager type that runs webhook
+// delivery attempts.
+const webhookDeliveryTaskType = "webhook.delivery"
+
+// webhookDispatchTimeout bounds queueing the deliveries for one event.
+const webhookDispatchTimeout = 5 * time.Second
+
+// webhookQueueAdapter implements outbound.WebhookQueuePort with the task
+// manager, which retries failed attempts with backoff.
+type webhookQueueAdapter struct {
+	manager *task.Manager
+}
+
+func newWebhookQueueAdapter(manager *task.Manager) outbound.WebhookQueuePort {
+	return &webhookQueueAdapter{manager: manager}
+}
+
+func (a *webhookQueueAdapter) Enqueue(ctx context.Context, deliveryID uuid.UUID) error {
+	// Deliveries are system tasks, not owned by the user they notify.
+	_, err := a.manager.Submit(ctx, uuid.Nil, &task.SubmitRequest{
+		Type:    webhookDeliveryTaskType,
+		Payload: map[string]any{"delivery_id": deliveryID.String()},
+	})
+	return err
+}
+
+// newWebhookDeliveryExecutor returns the task executor that makes one
+// delivery attempt.
+func newWebhookDeliveryExecutor(domain inbound.WebhookDomain) task.Executor {
+	return func(ctx context.Context, t *task.Task, _ func(int, map[string]any)) error {
+		raw, _ := t.Input["delivery_id"].(string)
+		deliveryID, err := uuid.Parse(raw)
+		if err != nil {
+			return task.Permanent(fmt.Errorf("invalid delivery id %q", raw))
+		}
+		return domain.Deliver(ctx, deliveryID)
+	}
+}
+
+// newWebhookTaskSubscriber returns a task manager subscriber that reports
+// finished tasks to webhooks. Delivery tasks are skipped so webhooks never
+// trigger more webhooks.
+func newWebhookTaskSubscriber(domain inbound.WebhookDomain, logger *zap.Logger) func(*task.Task) {
+	return func(t *task.Task) {
+		if t.Type == webhookDeliveryTaskType {
+			return
+		}
+		event := t.Event()
+		if !event.IsTerminal() {
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), webhookDispatchTimeout)
+		defer cancel()
+		if err := domain.HandleTaskEvent(ctx, event); err != nil {
+			logger.Warn("Failed to dispatch task webhook",
+				zap.String("task_id", t.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// newWebhookEventHandler returns an event bus handler that dispatches
+// payment and subscription events to webhooks.
+func newWebhookEventHandler(domain inbound.WebhookDomain) events.Handler {
+	types := []string{
+		events.PaymentSucceededType,
+		events.PaymentFailedType,
+		events.SubscriptionUpdatedType,
+	}
+	return events.NewHandlerFunc(types, func(e events.Event) error {
+		var event *model.AccountEvent
+		switch e := e.(type) {
+		case *events.PaymentSucceededEvent:
+			event = &model.AccountEvent{
+				Type:    model.AccountEventPaymentSucceeded,
+				OwnerID: e.UserID,
+				Data: map[string]any{
+					"payment_id":     e.PaymentID,
+					"order_id":       e.OrderID,
+					"amount":         e.Amount,
+					"currency":       e.Currency,
+					"provider":       e.Provider,
+					"order_type":     e.OrderType,
+					"credits_amount": e.CreditsAmount,
+					"plan_id":        e.PlanID,
+				},
+			}
+		case *events.PaymentFailedEvent:
+			event = &model.AccountEvent{
+				Type:    model.AccountEventPaymentFailed,
+				OwnerID: e.UserID,
+				Data: map[string]any{
+					"payment_id":      e.PaymentID,
+					"order_id":        e.OrderID,
+					"failure_code":    e.FailureCode,
+					"failure_message": e.FailureMessage,
+					"provider":        e.Provider,
+				},
+			}
+		case *events.SubscriptionUpdatedEvent:
+			event = &model.AccountEvent{
+				Type:    model.AccountEventSubscriptionUpdated,
+				OwnerID: e.UserID,
+				Data: map[string]any{
+					"subscription_id":      e.SubscriptionID,
+					"plan_id":              e.PlanID,
+					"status":               e.Status,
+					"current_period_end":   e.CurrentPeriodEnd,
+					"cancel_at_period_end": e.CancelAtPeriodEnd,
+				},
+			}
+		default:
+			return nil
+		}
+		event.ID = e.EventID()
+		event.CreatedAt = e.OccurredAt()
+
+		ctx, cancel := context.WithTimeout(context.Background(), webhookDispatchTimeout)
+		defer cancel()
+		return domain.Dispatch(ctx, event)
+	})
+}
+
+// gitCredentialAdapter implements inbound.GitCredentialValidator with the
+// auth domain. Git clients send a system API key or an access token as the
+// Basic auth password; the username is ignored.
+type gitCredentialAdapter struct {
+	authDomain auth.AuthDomain
+}
+
+func newGitCredentialAdapter(authDomain auth.AuthDomain) inbound.GitCredentialValidator {
+	return &gitCredentialAdapter{authDomain: authDomain}
+}
+
+func (a *gitCredentialAdapter) ValidateGitCredentials(ctx context.Context, username, password string) (uuid.UUID, error) {
+	if strings.HasPrefix(password, "sk-") {
+		key, err := a.authDomain.ValidateSystemAPIKey(ctx, password)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return key.UserID, nil
+	}
+
+	claims, err := a.authDomain.ValidateAccessToken(password)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return claims.UserID, nil
+}
+
+// gitStorageQuotaAdapter implements inbound.GitStorageQuotaChecker with the
+// storage allowances of the user's plan and the sizes of their repositories.
+type gitStorageQuotaAdapter struct {
+	billing billing.BillingDomain
+	repoDB  outbound.GitRepoDatabasePort
+}
+
+func newGitStorageQuotaAdapter(billing billing.BillingDomain, repoDB outbound.GitRepoDatabasePort) inbound.GitStorageQuotaChecker {
+	return &gitStorageQuotaAdapter{billing: billing, repoDB: repoDB}
+}
+
+func (a *gitStorageQuotaAdapter) GetStorageQuota(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return a.billing.GetGitStorageLimit(ctx, userID)
+}
+
+func (a *gitStorageQuotaAdapter) GetStorageUsed(ctx context.Context, userID uuid.UUID) (int64, error) {
+	size, _, err := a.repoDB.GetOwnerStorage(ctx, userID)
+	return size, err
+}
+
+func (a *gitStorageQuotaAdapter) GetLFSStorageQuota(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return a.billing.GetLFSStorageLimit(ctx, userID)
+}
+
+func (a *gitStorageQuotaAdapter) GetLFSStorageUsed(ctx context.Context, userID uuid.UUID) (int64, error) {
+	_, lfsSize, err := a.repoDB.GetOwnerStorage(ctx, userID)
+	return lfsSize, err
+}
+
+// gitAccessControlAdapter implements outbound.GitAccessControlPort with the
+// Git domain, so the LFS domains share its repository permission rules.
+type gitAccessControlAdapter struct {
+	domain inbound.GitDomain
+}
+
+func newGitAccessControlAdapter(domain inbound.GitDomain) outbound.GitAccessControlPort {
+	return &gitAccessControlAdapter{domain: domain}
+}
+
+// CheckAccess checks a user's permission. uuid.Nil stands for an anonymous
+// caller, who can only read public repositories.
+func (a *gitAccessControlAdapter) CheckAccess(ctx context.Context, userID, repoID uuid.UUID, requiredPermission model.GitPermission) (*model.GitAccessResult, error) {
+	var user *uuid.UUID
+	if userID != uuid.Nil {
+		user = &userID
+	}
+
+	allowed, err := a.domain.CanAccess(ctx, repoID, user, requiredPermission)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return model.NewGitAccessDenied("access denied"), nil
+	}
+	return model.NewGitAccessAllowed(requiredPermission), nil
+}
+
+func (a *gitAccessControlAdapter) CheckPublicAccess(ctx context.Context, repoID uuid.UUID) (bool, error) {
+	repo, err := a.domain.GetRepo(ctx, repoID)
+	if err != nil {
+		return false, err
+	}
+	return repo.IsPublic(), nil
+}
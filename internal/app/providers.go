@@ -1,7 +1,9 @@
 package app
 
 import (
+	"errors"
 	"net/http"
+	"time"
 
 	"github.com/google/wire"
 	goredis "github.com/redis/go-redis/v9"
@@ -18,6 +20,7 @@ import (
 	"github.com/uniedit/server/internal/domain/order"
 	"github.com/uniedit/server/internal/domain/payment"
 	"github.com/uniedit/server/internal/domain/user"
+	"github.com/uniedit/server/internal/domain/webhook"
 
 	// Inbound adapters
 	aihttp "github.com/uniedit/server/internal/adapter/inbound/http/ai"
@@ -28,7 +31,9 @@ import (
 	mediahttp "github.com/uniedit/server/internal/adapter/inbound/http/media"
 	orderhttp "github.com/uniedit/server/internal/adapter/inbound/http/order"
 	paymenthttp "github.com/uniedit/server/internal/adapter/inbound/http/payment"
+	taskhttp "github.com/uniedit/server/internal/adapter/inbound/http/task"
 	userhttp "github.com/uniedit/server/internal/adapter/inbound/http/user"
+	webhookhttp "github.com/uniedit/server/internal/adapter/inbound/http/webhook"
 
 	// Ports
 	"github.com/uniedit/server/internal/port/inbound"
@@ -40,12 +45,16 @@ import (
 	"github.com/uniedit/server/internal/adapter/outbound/oauth"
 	"github.com/uniedit/server/internal/adapter/outbound/postgres"
 	redisadapter "github.com/uniedit/server/internal/adapter/outbound/redis"
+	s3adapter "github.com/uniedit/server/internal/adapter/outbound/s3"
+	webhookadapter "github.com/uniedit/server/internal/adapter/outbound/webhook"
 
 	// Infrastructure
 	"github.com/uniedit/server/internal/infra/cache"
 	"github.com/uniedit/server/internal/infra/config"
 	"github.com/uniedit/server/internal/infra/database"
+	"github.com/uniedit/server/internal/infra/events"
 	"github.com/uniedit/server/internal/infra/httpclient"
+	"github.com/uniedit/server/internal/infra/task"
 
 	// Utils
 	"github.com/uniedit/server/internal/utils/logger"
@@ -63,6 +72,7 @@ var InfraSet = wire.NewSet(
 	ProvideLogger,
 	ProvideZapLogger,
 	ProvideMetrics,
+	ProvideEventBus,
 )
 
 // ProvideDatabase creates a database connection.
@@ -120,6 +130,11 @@ func ProvideMetrics() *metrics.Metrics {
 	return metrics.New("uniedit")
 }
 
+// ProvideEventBus creates the in-process domain event bus.
+func ProvideEventBus(zapLog *zap.Logger) *events.Bus {
+	return events.NewBus(zapLog)
+}
+
 // ===== User Domain Providers =====
 
 // UserSet provides user domain dependencies.
@@ -253,21 +268,24 @@ func ProvideQuotaCache(redis goredis.UniversalClient) outbound.QuotaCachePort {
 	return nil
 }
 
-// ProvideBillingDomain creates the billing domain.
+// ProvideBillingDomain creates the billing domain. Subscription changes are
+// published to the event bus.
 func ProvideBillingDomain(
 	planDB outbound.PlanDatabasePort,
 	subscriptionDB outbound.SubscriptionDatabasePort,
 	usageDB outbound.UsageRecordDatabasePort,
 	quotaCache outbound.QuotaCachePort,
+	bus *events.Bus,
 	zapLog *zap.Logger,
 ) billing.BillingDomain {
-	return billing.NewBillingDomain(
+	domain := billing.NewBillingDomain(
 		planDB,
 		subscriptionDB,
 		usageDB,
 		quotaCache,
 		zapLog,
 	)
+	return newBillingEventsAdapter(domain, subscriptionDB, bus, zapLog)
 }
 
 // ===== Order Domain Providers =====
@@ -319,9 +337,10 @@ func ProvideBillingReaderAdapter(domain billing.BillingDomain) outbound.BillingR
 	return newBillingReaderAdapter(domain)
 }
 
-// ProvideEventPublisher creates the event publisher.
-func ProvideEventPublisher() outbound.EventPublisherPort {
-	return newNoOpEventPublisher()
+// ProvideEventPublisher creates the event publisher, which forwards domain
+// events to the event bus.
+func ProvideEventPublisher(bus *events.Bus) outbound.EventPublisherPort {
+	return newBusEventPublisher(bus)
 }
 
 // ProvidePaymentDomain creates the payment domain.
@@ -358,10 +377,13 @@ var AISet = wire.NewSet(
 	postgres.NewAIModelAdapter,
 	postgres.NewAIProviderAccountAdapter,
 	postgres.NewAIModelGroupAdapter,
+	postgres.NewAIRequestLogAdapter,
+	postgres.NewAIModelAliasAdapter,
 	ProvideAIHealthCache,
 	ProvideAIEmbeddingCache,
 	ProvideVendorRegistry,
 	ProvideAICryptoAdapter,
+	ProvideAIImageFetcher,
 	ProvideAIDomain,
 )
 
@@ -397,29 +419,53 @@ func ProvideAICryptoAdapter(cfg *config.Config) outbound.AICryptoPort {
 	return aiprovider.NewCryptoAdapter(cfg.Auth.MasterKey)
 }
 
+// ProvideAIImageFetcher creates the fetcher for remote image inputs.
+func ProvideAIImageFetcher(cfg *config.Config) outbound.AIImageFetcherPort {
+	return aiprovider.NewImageFetcher(cfg.AI.ImageFetchTimeout)
+}
+
 // ProvideAIDomain creates the AI domain.
 func ProvideAIDomain(
 	providerDB outbound.AIProviderDatabasePort,
 	modelDB outbound.AIModelDatabasePort,
 	accountDB outbound.AIProviderAccountDatabasePort,
 	groupDB outbound.AIModelGroupDatabasePort,
+	aliasDB outbound.AIModelAliasDatabasePort,
 	healthCache outbound.AIProviderHealthCachePort,
 	embeddingCache outbound.AIEmbeddingCachePort,
 	vendorRegistry outbound.AIVendorRegistryPort,
 	crypto outbound.AICryptoPort,
+	requestLogDB outbound.AIRequestLogDatabasePort,
+	imageFetcher outbound.AIImageFetcherPort,
+	cfg *config.Config,
 	zapLog *zap.Logger,
 ) ai.AIDomain {
+	aiConfig := ai.DefaultConfig()
+	if cfg.AI.HealthCheckInterval > 0 {
+		aiConfig.HealthCheckInterval = cfg.AI.HealthCheckInterval
+	}
+	aiConfig.RequestLogEnabled = cfg.AI.RequestLogEnabled
+	if cfg.AI.RequestLogRetention > 0 {
+		aiConfig.RequestLogRetention = cfg.AI.RequestLogRetention
+	}
+	if cfg.AI.ImageMaxBytes > 0 {
+		aiConfig.ImageMaxBytes = cfg.AI.ImageMaxBytes
+	}
+
 	return ai.NewAIDomain(
 		providerDB,
 		modelDB,
 		accountDB,
 		groupDB,
+		aliasDB,
 		healthCache,
 		embeddingCache,
 		vendorRegistry,
 		crypto,
 		nil, // usageRecorder
-		nil, // config
+		requestLogDB,
+		imageFetcher,
+		aiConfig,
 		zapLog,
 	)
 }
@@ -434,41 +480,151 @@ var GitSet = wire.NewSet(
 	wire.Bind(new(outbound.GitCollaboratorDatabasePort), new(*postgres.GitCollaboratorDatabaseAdapter)),
 	postgres.NewGitPullRequestDatabaseAdapter,
 	wire.Bind(new(outbound.GitPullRequestDatabasePort), new(*postgres.GitPullRequestDatabaseAdapter)),
+	postgres.NewGitPRReviewDatabaseAdapter,
+	wire.Bind(new(outbound.GitPRReviewDatabasePort), new(*postgres.GitPRReviewDatabaseAdapter)),
+	postgres.NewGitBranchProtectionDatabaseAdapter,
+	wire.Bind(new(outbound.GitBranchProtectionDatabasePort), new(*postgres.GitBranchProtectionDatabaseAdapter)),
 	postgres.NewGitLFSObjectDatabaseAdapter,
 	wire.Bind(new(outbound.GitLFSObjectDatabasePort), new(*postgres.GitLFSObjectDatabaseAdapter)),
 	postgres.NewGitLFSLockDatabaseAdapter,
 	wire.Bind(new(outbound.GitLFSLockDatabasePort), new(*postgres.GitLFSLockDatabaseAdapter)),
+	ProvideGitStorage,
+	ProvideGitLFSStorage,
+	ProvideGitTeamAccess,
+	ProvideGitStorageQuota,
 	ProvideGitDomain,
+	ProvideGitAccessControl,
+	ProvideGitLFSDomain,
+	ProvideGitLFSLockDomain,
 )
 
+// ProvideGitStorage creates the R2 storage backing Git repositories.
+func ProvideGitStorage(cfg *config.Config) outbound.GitStoragePort {
+	if cfg.Storage.Bucket == "" {
+		return nil
+	}
+	client := s3adapter.NewClient(cfg.Storage.Endpoint, cfg.Storage.Region, cfg.Storage.AccessKeyID, cfg.Storage.SecretAccessKey)
+	return s3adapter.NewGitStorageAdapter(client, cfg.Storage.Bucket)
+}
+
+// ProvideGitLFSStorage creates the R2 storage backing Git LFS objects.
+func ProvideGitLFSStorage(cfg *config.Config) outbound.GitLFSStoragePort {
+	if cfg.Storage.Bucket == "" {
+		return nil
+	}
+	client := s3adapter.NewClient(cfg.Storage.Endpoint, cfg.Storage.Region, cfg.Storage.AccessKeyID, cfg.Storage.SecretAccessKey)
+	return s3adapter.NewGitLFSStorageAdapter(client, cfg.Storage.Bucket, cfg.Git.LFSPrefix)
+}
+
+// ProvideGitTeamAccess resolves team roles for team repositories.
+func ProvideGitTeamAccess(domain inbound.CollaborationDomain) outbound.GitTeamAccessPort {
+	return newTeamAccessAdapter(domain)
+}
+
 // ProvideGitDomain creates the Git domain.
 func ProvideGitDomain(
 	repoDB outbound.GitRepoDatabasePort,
 	collabDB outbound.GitCollaboratorDatabasePort,
 	prDB outbound.GitPullRequestDatabasePort,
+	reviewDB outbound.GitPRReviewDatabasePort,
+	protectionDB outbound.GitBranchProtectionDatabasePort,
 	lfsObjDB outbound.GitLFSObjectDatabasePort,
 	lfsLockDB outbound.GitLFSLockDatabasePort,
+	storage outbound.GitStoragePort,
+	lfsStorage outbound.GitLFSStoragePort,
+	quotaChecker inbound.GitStorageQuotaChecker,
+	teamAccess outbound.GitTeamAccessPort,
+	eventPublisher outbound.EventPublisherPort,
 	cfg *config.Config,
 	zapLog *zap.Logger,
 ) inbound.GitDomain {
-	gitCfg := git.DefaultConfig()
-	if cfg.Git.RepoPrefix != "" {
-		gitCfg.RepoPrefix = cfg.Git.RepoPrefix
-	}
 	return git.NewDomain(
 		repoDB,
 		collabDB,
 		prDB,
+		reviewDB,
+		protectionDB,
 		lfsObjDB,
 		lfsLockDB,
-		nil, // storage
-		nil, // lfsStorage
-		nil, // quotaChecker
-		gitCfg,
+		storage,
+		lfsStorage,
+		quotaChecker,
+		teamAccess,
+		eventPublisher,
+		gitDomainConfig(cfg),
 		zapLog,
 	)
 }
 
+// ProvideGitStorageQuota exposes the plan's git and LFS storage limits to
+// the Git domains.
+func ProvideGitStorageQuota(domain billing.BillingDomain, repoDB outbound.GitRepoDatabasePort) inbound.GitStorageQuotaChecker {
+	return newGitStorageQuotaAdapter(domain, repoDB)
+}
+
+// ProvideGitAccessControl exposes the repository permission checks of the
+// Git domain to the LFS domains.
+func ProvideGitAccessControl(domain inbound.GitDomain) outbound.GitAccessControlPort {
+	return newGitAccessControlAdapter(domain)
+}
+
+// ProvideGitLFSDomain creates the Git LFS domain.
+func ProvideGitLFSDomain(
+	repoDB outbound.GitRepoDatabasePort,
+	lfsObjDB outbound.GitLFSObjectDatabasePort,
+	lfsStorage outbound.GitLFSStoragePort,
+	accessCtrl outbound.GitAccessControlPort,
+	quotaChecker inbound.GitStorageQuotaChecker,
+	cfg *config.Config,
+	zapLog *zap.Logger,
+) inbound.GitLFSDomain {
+	return git.NewLFSDomain(repoDB, lfsObjDB, lfsStorage, accessCtrl, quotaChecker, gitDomainConfig(cfg), zapLog)
+}
+
+// ProvideGitLFSLockDomain creates the Git LFS lock domain.
+func ProvideGitLFSLockDomain(
+	repoDB outbound.GitRepoDatabasePort,
+	lfsLockDB outbound.GitLFSLockDatabasePort,
+	accessCtrl outbound.GitAccessControlPort,
+	zapLog *zap.Logger,
+) inbound.GitLFSLockDomain {
+	return git.NewLFSLockDomain(repoDB, lfsLockDB, accessCtrl, zapLog)
+}
+
+// gitDomainConfig maps the Git settings onto the Git domain configuration.
+func gitDomainConfig(cfg *config.Config) *git.Config {
+	gitCfg := git.DefaultConfig()
+	if cfg.Git.RepoPrefix != "" {
+		gitCfg.RepoPrefix = cfg.Git.RepoPrefix
+	}
+	if cfg.Git.LFSPrefix != "" {
+		gitCfg.LFSPrefix = cfg.Git.LFSPrefix
+	}
+	if cfg.Git.LFSMaxFileSize > 0 {
+		gitCfg.MaxLFSFileSize = cfg.Git.LFSMaxFileSize
+	}
+	if cfg.Git.LFSURLExpiry > 0 {
+		gitCfg.PresignedURLExpiry = cfg.Git.LFSURLExpiry
+	}
+	if cfg.Git.LFSThreshold > 0 {
+		gitCfg.LFSThreshold = cfg.Git.LFSThreshold
+	}
+	if cfg.Git.MaxFileSize > 0 {
+		gitCfg.MaxFileSize = cfg.Git.MaxFileSize
+	}
+	gitCfg.LFSProxyTransfer = cfg.Git.LFSProxy
+	gitCfg.BaseURL = gitBaseURL(cfg)
+	return gitCfg
+}
+
+// gitBaseURL returns the public base URL that clone and LFS URLs are built on.
+func gitBaseURL(cfg *config.Config) string {
+	if cfg.Email.BaseURL != "" {
+		return cfg.Email.BaseURL
+	}
+	return cfg.Server.Address
+}
+
 // ===== Collaboration Domain Providers =====
 
 // CollaborationSet provides collaboration domain dependencies.
@@ -519,14 +675,27 @@ var MediaSet = wire.NewSet(
 	wire.Bind(new(outbound.MediaProviderDatabasePort), new(*postgres.MediaProviderDBAdapter)),
 	postgres.NewMediaModelDBAdapter,
 	wire.Bind(new(outbound.MediaModelDatabasePort), new(*postgres.MediaModelDBAdapter)),
-	postgres.NewMediaTaskDBAdapter,
-	wire.Bind(new(outbound.MediaTaskDatabasePort), new(*postgres.MediaTaskDBAdapter)),
+	ProvideMediaTaskDB,
+	postgres.NewMediaAssetDBAdapter,
+	wire.Bind(new(outbound.MediaAssetDatabasePort), new(*postgres.MediaAssetDBAdapter)),
 	ProvideMediaHealthCache,
 	ProvideMediaVendorRegistry,
 	ProvideMediaCryptoAdapter,
+	ProvideMediaVideoQuota,
+	ProvideMediaStorage,
+	ProvideMediaFetcher,
+	ProvideMediaStorageQuota,
+	ProvideMediaTeamAccess,
 	ProvideMediaDomain,
 )
 
+// ProvideMediaTaskDB creates media task persistence that also publishes
+// each task change to watching clients and reports finished tasks to
+// webhooks.
+func ProvideMediaTaskDB(db *gorm.DB, events outbound.TaskEventPort, webhooks inbound.WebhookDomain, zapLog *zap.Logger) outbound.MediaTaskDatabasePort {
+	return newMediaTaskEventsAdapter(postgres.NewMediaTaskDBAdapter(db), events, webhooks, zapLog)
+}
+
 // ProvideMediaHealthCache creates the media health cache.
 func ProvideMediaHealthCache(redis goredis.UniversalClient) outbound.MediaProviderHealthCachePort {
 	if redis == nil {
@@ -544,9 +713,46 @@ func ProvideMediaCryptoAdapter(cfg *config.Config) outbound.MediaCryptoPort {
 func ProvideMediaVendorRegistry(client *http.Client) outbound.MediaVendorRegistryPort {
 	registry := mediaprovider.NewRegistry()
 	registry.Register(mediaprovider.NewOpenAIAdapter(client))
+	registry.Register(mediaprovider.NewAsyncVideoAdapter(client))
 	return registry
 }
 
+// ProvideMediaVideoQuota creates the video minute accounting adapter backed by billing.
+func ProvideMediaVideoQuota(domain billing.BillingDomain) outbound.MediaVideoQuotaPort {
+	return newMediaVideoQuotaAdapter(domain)
+}
+
+// mediaFetchTimeout bounds downloading a generated file from a provider;
+// videos can be hundreds of megabytes.
+const mediaFetchTimeout = 5 * time.Minute
+
+// ProvideMediaStorage creates object storage for generated media.
+// Returns nil when no bucket is configured, in which case vendor URLs are
+// returned to clients unchanged.
+func ProvideMediaStorage(cfg *config.Config) outbound.StoragePort {
+	if cfg.Storage.Bucket == "" {
+		return nil
+	}
+	client := s3adapter.NewClient(cfg.Storage.Endpoint, cfg.Storage.Region, cfg.Storage.AccessKeyID, cfg.Storage.SecretAccessKey)
+	return s3adapter.NewObjectStorageAdapter(client, cfg.Storage.Bucket)
+}
+
+// ProvideMediaFetcher creates the downloader for generated media, sharing
+// the SSRF-safe fetcher used for AI image inputs.
+func ProvideMediaFetcher() outbound.MediaFetcherPort {
+	return aiprovider.NewImageFetcher(mediaFetchTimeout)
+}
+
+// ProvideMediaStorageQuota exposes the plan's media storage limit.
+func ProvideMediaStorageQuota(domain billing.BillingDomain) outbound.MediaStorageQuotaPort {
+	return domain
+}
+
+// ProvideMediaTeamAccess resolves team roles for shared media assets.
+func ProvideMediaTeamAccess(domain inbound.CollaborationDomain) outbound.MediaTeamAccessPort {
+	return newTeamAccessAdapter(domain)
+}
+
 // ProvideMediaDomain creates the media domain.
 func ProvideMediaDomain(
 	providerDB outbound.MediaProviderDatabasePort,
@@ -555,6 +761,12 @@ func ProvideMediaDomain(
 	healthCache outbound.MediaProviderHealthCachePort,
 	vendorRegistry outbound.MediaVendorRegistryPort,
 	crypto outbound.MediaCryptoPort,
+	videoQuota outbound.MediaVideoQuotaPort,
+	assetDB outbound.MediaAssetDatabasePort,
+	storage outbound.StoragePort,
+	fetcher outbound.MediaFetcherPort,
+	storageQuota outbound.MediaStorageQuotaPort,
+	teamAccess outbound.MediaTeamAccessPort,
 	zapLog *zap.Logger,
 ) inbound.MediaDomain {
 	return media.NewDomain(
@@ -564,11 +776,109 @@ func ProvideMediaDomain(
 		healthCache,
 		vendorRegistry,
 		crypto,
+		videoQuota,
+		assetDB,
+		storage,
+		fetcher,
+		storageQuota,
+		teamAccess,
 		media.DefaultConfig(),
 		zapLog,
 	)
 }
 
+// ===== Task Providers =====
+
+// TaskSet provides the generic async task manager and task events.
+var TaskSet = wire.NewSet(
+	ProvideTaskEvents,
+	ProvideTaskManager,
+)
+
+// ProvideTaskEvents creates the task event bus shared by all replicas.
+// Returns nil without Redis, in which case watched tasks are polled.
+func ProvideTaskEvents(redis goredis.UniversalClient) outbound.TaskEventPort {
+	if redis == nil {
+		return nil
+	}
+	return redisadapter.NewTaskEventAdapter(redis)
+}
+
+// ProvideTaskManager creates the task manager backed by the tasks table.
+func ProvideTaskManager(db *gorm.DB, events outbound.TaskEventPort, zapLog *zap.Logger) *task.Manager {
+	manager := task.NewManager(task.NewRepository(db), zapLog, task.DefaultConfig())
+	if events != nil {
+		manager.SubscribeAll(newTaskEventPublisher(events, zapLog))
+	}
+	return manager
+}
+
+// ===== Webhook Providers =====
+
+// WebhookSet provides user webhook endpoints and delivery.
+var WebhookSet = wire.NewSet(
+	postgres.NewWebhookEndpointDBAdapter,
+	wire.Bind(new(outbound.WebhookEndpointDatabasePort), new(*postgres.WebhookEndpointDBAdapter)),
+	postgres.NewWebhookDeliveryDBAdapter,
+	wire.Bind(new(outbound.WebhookDeliveryDatabasePort), new(*postgres.WebhookDeliveryDBAdapter)),
+	ProvideWebhookSender,
+	ProvideWebhookCrypto,
+	ProvideWebhookDomain,
+)
+
+// webhookSendTimeout bounds one webhook request.
+const webhookSendTimeout = 10 * time.Second
+
+// ProvideWebhookSender creates the SSRF-safe client for webhook requests.
+func ProvideWebhookSender() outbound.WebhookSenderPort {
+	return webhookadapter.NewSender(webhookSendTimeout)
+}
+
+// ProvideWebhookCrypto creates the encryption for endpoint signing secrets.
+func ProvideWebhookCrypto(cfg *config.Config) outbound.WebhookCryptoPort {
+	return aiprovider.NewCryptoAdapter(cfg.Auth.MasterKey)
+}
+
+// ProvideWebhookDomain creates the webhook domain. Deliveries run as task
+// manager tasks, retried with backoff, and the domain is fed from task
+// manager transitions and the event bus.
+func ProvideWebhookDomain(
+	endpointDB outbound.WebhookEndpointDatabasePort,
+	deliveryDB outbound.WebhookDeliveryDatabasePort,
+	manager *task.Manager,
+	sender outbound.WebhookSenderPort,
+	crypto outbound.WebhookCryptoPort,
+	bus *events.Bus,
+	zapLog *zap.Logger,
+) inbound.WebhookDomain {
+	webhookCfg := webhook.DefaultConfig()
+	domain := webhook.NewDomain(
+		endpointDB,
+		deliveryDB,
+		newWebhookQueueAdapter(manager),
+		sender,
+		crypto,
+		webhookCfg,
+		zapLog,
+	)
+
+	manager.RegisterExecutor(webhookDeliveryTaskType, newWebhookDeliveryExecutor(domain))
+	manager.RegisterRetryPolicy(webhookDeliveryTaskType, &task.RetryPolicy{
+		MaxAttempts:    webhookCfg.MaxAttempts,
+		InitialBackoff: webhookCfg.RetryBackoff,
+		MaxBackoff:     webhookCfg.MaxRetryBackoff,
+		Multiplier:     2,
+		Jitter:         0.2,
+		Retryable: func(err error) bool {
+			return errors.Is(err, webhook.ErrDeliveryFailed)
+		},
+	})
+	manager.SubscribeAll(newWebhookTaskSubscriber(domain, zapLog))
+	bus.Register(newWebhookEventHandler(domain))
+
+	return domain
+}
+
 // ===== HTTP Handler Providers =====
 
 // AuthHandlerSet provides auth HTTP handlers.
@@ -609,16 +919,35 @@ var PaymentHandlerSet = wire.NewSet(
 // GitHandlerSet provides Git HTTP handlers.
 var GitHandlerSet = wire.NewSet(
 	ProvideGitHandler,
+	ProvideGitCredentialValidator,
+	githttp.NewSmartHTTPHandler,
+	ProvideGitLFSHandler,
 )
 
 // ProvideGitHandler creates the Git HTTP handler.
-func ProvideGitHandler(domain inbound.GitDomain, cfg *config.Config) *githttp.Handler {
-	baseURL := cfg.Server.Address
-	if cfg.Email.BaseURL != "" {
-		baseURL = cfg.Email.BaseURL
-	}
-	// LFS domains are optional - pass nil for now
-	return githttp.NewHandler(domain, nil, nil, baseURL)
+func ProvideGitHandler(
+	domain inbound.GitDomain,
+	lfsDomain inbound.GitLFSDomain,
+	lockDomain inbound.GitLFSLockDomain,
+	cfg *config.Config,
+) *githttp.Handler {
+	return githttp.NewHandler(domain, lfsDomain, lockDomain, gitBaseURL(cfg))
+}
+
+// ProvideGitLFSHandler creates the Git LFS HTTP handler.
+func ProvideGitLFSHandler(
+	domain inbound.GitDomain,
+	lfsDomain inbound.GitLFSDomain,
+	lockDomain inbound.GitLFSLockDomain,
+	credentials inbound.GitCredentialValidator,
+	cfg *config.Config,
+) *githttp.LFSHandler {
+	return githttp.NewLFSHandler(domain, lfsDomain, lockDomain, credentials, gitBaseURL(cfg))
+}
+
+// ProvideGitCredentialValidator checks the credentials git clients send.
+func ProvideGitCredentialValidator(authDomain auth.AuthDomain) inbound.GitCredentialValidator {
+	return newGitCredentialAdapter(authDomain)
 }
 
 // CollaborationHandlerSet provides collaboration HTTP handlers.
@@ -653,6 +982,16 @@ func ProvideMediaHandler(domain inbound.MediaDomain) *mediahttp.Handler {
 	return nil
 }
 
+// TaskHandlerSet provides task admin HTTP handlers.
+var TaskHandlerSet = wire.NewSet(
+	taskhttp.NewHandler,
+)
+
+// WebhookHandlerSet provides webhook endpoint HTTP handlers.
+var WebhookHandlerSet = wire.NewSet(
+	webhookhttp.NewHandler,
+)
+
 // ProvideAIProviderAdminHandler creates the AI Provider admin HTTP handler.
 func ProvideAIProviderAdminHandler(domain ai.AIDomain) *aihttp.ProviderAdminHandler {
 	return aihttp.NewProviderAdminHandler(domain)
@@ -668,12 +1007,24 @@ func ProvideAIPublicHandler(domain ai.AIDomain) *aihttp.PublicHandler {
 	return aihttp.NewPublicHandler(domain)
 }
 
+// ProvideAIAliasHandler creates the AI model alias admin HTTP handler.
+func ProvideAIAliasHandler(domain ai.AIDomain) *aihttp.AliasHandler {
+	return aihttp.NewAliasHandler(domain)
+}
+
+// ProvideAIRequestLogHandler creates the AI request log admin HTTP handler.
+func ProvideAIRequestLogHandler(domain ai.AIDomain) *aihttp.RequestLogHandler {
+	return aihttp.NewRequestLogHandler(domain)
+}
+
 // AIHandlerSet provides AI HTTP handlers.
 var AIHandlerSet = wire.NewSet(
 	aihttp.NewChatHandler,
 	ProvideAIProviderAdminHandler,
 	ProvideAIModelAdminHandler,
 	ProvideAIPublicHandler,
+	ProvideAIAliasHandler,
+	ProvideAIRequestLogHandler,
 )
 
 // HandlerSet provides all HTTP handlers.
@@ -687,6 +1038,8 @@ var HandlerSet = wire.NewSet(
 	GitHandlerSet,
 	CollaborationHandlerSet,
 	MediaHandlerSet,
+	TaskHandlerSet,
+	WebhookHandlerSet,
 )
 
 // ===== Master Set =====
@@ -703,5 +1056,7 @@ var AppSet = wire.NewSet(
 	GitSet,
 	CollaborationSet,
 	MediaSet,
+	TaskSet,
+	WebhookSet,
 	HandlerSet,
 )
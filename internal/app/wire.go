@@ -28,7 +28,9 @@ import (
 	mediahttp "github.com/uniedit/server/internal/adapter/inbound/http/media"
 	orderhttp "github.com/uniedit/server/internal/adapter/inbound/http/order"
 	paymenthttp "github.com/uniedit/server/internal/adapter/inbound/http/payment"
+	taskhttp "github.com/uniedit/server/internal/adapter/inbound/http/task"
 	userhttp "github.com/uniedit/server/internal/adapter/inbound/http/user"
+	webhookhttp "github.com/uniedit/server/internal/adapter/inbound/http/webhook"
 
 	// Ports
 	"github.com/uniedit/server/internal/port/inbound"
@@ -36,6 +38,7 @@ import (
 
 	// Infrastructure
 	"github.com/uniedit/server/internal/infra/config"
+	"github.com/uniedit/server/internal/infra/task"
 
 	// Utils
 	"github.com/uniedit/server/internal/utils/logger"
@@ -63,12 +66,16 @@ type Dependencies struct {
 	GitDomain           inbound.GitDomain
 	CollaborationDomain inbound.CollaborationDomain
 	MediaDomain         inbound.MediaDomain
+	TaskManager         *task.Manager
+	WebhookDomain       inbound.WebhookDomain
 
 	// AI HTTP Handlers
 	AIChatHandler          *aihttp.ChatHandler
 	AIProviderAdminHandler *aihttp.ProviderAdminHandler
 	AIModelAdminHandler    *aihttp.ModelAdminHandler
 	AIPublicHandler        *aihttp.PublicHandler
+	AIAliasHandler         *aihttp.AliasHandler
+	AIRequestLogHandler    *aihttp.RequestLogHandler
 
 	// Auth HTTP Handlers
 	OAuthHandler          *authhttp.OAuthHandler
@@ -96,13 +103,21 @@ type Dependencies struct {
 	WebhookHandler *paymenthttp.WebhookHandler
 
 	// Git HTTP Handlers
-	GitHandler *githttp.Handler
+	GitHandler          *githttp.Handler
+	GitSmartHTTPHandler *githttp.SmartHTTPHandler
+	GitLFSHandler       *githttp.LFSHandler
 
 	// Collaboration HTTP Handlers
 	CollaborationHandler *collaborationhttp.Handler
 
 	// Media HTTP Handlers
 	MediaHandler *mediahttp.Handler
+
+	// Task HTTP Handlers
+	TaskHandler *taskhttp.Handler
+
+	// Webhook HTTP Handlers
+	WebhookEndpointHandler *webhookhttp.Handler
 }
 
 // InitializeDependencies creates all dependencies using Wire.
@@ -18,7 +18,9 @@ import (
 	mediahttp "github.com/uniedit/server/internal/adapter/inbound/http/media"
 	orderhttp "github.com/uniedit/server/internal/adapter/inbound/http/order"
 	paymenthttp "github.com/uniedit/server/internal/adapter/inbound/http/payment"
+	taskhttp "github.com/uniedit/server/internal/adapter/inbound/http/task"
 	userhttp "github.com/uniedit/server/internal/adapter/inbound/http/user"
+	webhookhttp "github.com/uniedit/server/internal/adapter/inbound/http/webhook"
 	"github.com/uniedit/server/internal/adapter/outbound/postgres"
 	ai2 "github.com/uniedit/server/internal/domain/ai"
 	"github.com/uniedit/server/internal/domain/auth"
@@ -27,6 +29,7 @@ import (
 	"github.com/uniedit/server/internal/domain/payment"
 	"github.com/uniedit/server/internal/domain/user"
 	"github.com/uniedit/server/internal/infra/config"
+	"github.com/uniedit/server/internal/infra/task"
 	"github.com/uniedit/server/internal/port/inbound"
 	"github.com/uniedit/server/internal/port/outbound"
 	"github.com/uniedit/server/internal/utils/logger"
@@ -52,6 +55,7 @@ func InitializeDependencies(cfg *config.Config) (*Dependencies, func(), error) {
 	rateLimiterPort := ProvideRateLimiter(universalClient)
 	loggerLogger := ProvideLogger(cfg)
 	metrics := ProvideMetrics()
+	bus := ProvideEventBus(logger)
 	userDatabasePort := postgres.NewUserAdapter(db)
 	verificationDatabasePort := postgres.NewVerificationAdapter(db)
 	userDomain := ProvideUserDomain(userDatabasePort, verificationDatabasePort, logger)
@@ -67,7 +71,7 @@ func InitializeDependencies(cfg *config.Config) (*Dependencies, func(), error) {
 	subscriptionDatabasePort := postgres.NewSubscriptionAdapter(db)
 	usageRecordDatabasePort := postgres.NewUsageRecordAdapter(db)
 	quotaCachePort := ProvideQuotaCache(universalClient)
-	billingDomain := ProvideBillingDomain(planDatabasePort, subscriptionDatabasePort, usageRecordDatabasePort, quotaCachePort, logger)
+	billingDomain := ProvideBillingDomain(planDatabasePort, subscriptionDatabasePort, usageRecordDatabasePort, quotaCachePort, bus, logger)
 	orderDatabasePort := postgres.NewOrderAdapter(db)
 	orderItemDatabasePort := postgres.NewOrderItemAdapter(db)
 	invoiceDatabasePort := postgres.NewInvoiceAdapter(db)
@@ -76,40 +80,67 @@ func InitializeDependencies(cfg *config.Config) (*Dependencies, func(), error) {
 	webhookEventDatabasePort := postgres.NewWebhookEventAdapter(db)
 	orderReaderPort := ProvideOrderReaderAdapter(orderDomain)
 	billingReaderPort := ProvideBillingReaderAdapter(billingDomain)
-	eventPublisherPort := ProvideEventPublisher()
+	eventPublisherPort := ProvideEventPublisher(bus)
 	paymentDomain := ProvidePaymentDomain(paymentDatabasePort, webhookEventDatabasePort, orderReaderPort, billingReaderPort, eventPublisherPort, cfg, logger)
 	aiProviderDatabasePort := postgres.NewAIProviderAdapter(db)
 	aiModelDatabasePort := postgres.NewAIModelAdapter(db)
 	aiProviderAccountDatabasePort := postgres.NewAIProviderAccountAdapter(db)
 	aiModelGroupDatabasePort := postgres.NewAIModelGroupAdapter(db)
+	aiRequestLogDatabasePort := postgres.NewAIRequestLogAdapter(db)
+	aiModelAliasDatabasePort := postgres.NewAIModelAliasAdapter(db)
 	aiProviderHealthCachePort := ProvideAIHealthCache(universalClient)
 	aiEmbeddingCachePort := ProvideAIEmbeddingCache(universalClient)
 	aiVendorRegistryPort := ProvideVendorRegistry(client)
 	aiCryptoPort := ProvideAICryptoAdapter(cfg)
-	aiDomain := ProvideAIDomain(aiProviderDatabasePort, aiModelDatabasePort, aiProviderAccountDatabasePort, aiModelGroupDatabasePort, aiProviderHealthCachePort, aiEmbeddingCachePort, aiVendorRegistryPort, aiCryptoPort, logger)
-	gitRepoDatabaseAdapter := postgres.NewGitRepoDatabaseAdapter(db)
-	gitCollaboratorDatabaseAdapter := postgres.NewGitCollaboratorDatabaseAdapter(db)
-	gitPullRequestDatabaseAdapter := postgres.NewGitPullRequestDatabaseAdapter(db)
-	gitLFSObjectDatabaseAdapter := postgres.NewGitLFSObjectDatabaseAdapter(db)
-	gitLFSLockDatabaseAdapter := postgres.NewGitLFSLockDatabaseAdapter(db)
-	gitDomain := ProvideGitDomain(gitRepoDatabaseAdapter, gitCollaboratorDatabaseAdapter, gitPullRequestDatabaseAdapter, gitLFSObjectDatabaseAdapter, gitLFSLockDatabaseAdapter, cfg, logger)
+	aiImageFetcherPort := ProvideAIImageFetcher(cfg)
+	aiDomain := ProvideAIDomain(aiProviderDatabasePort, aiModelDatabasePort, aiProviderAccountDatabasePort, aiModelGroupDatabasePort, aiModelAliasDatabasePort, aiProviderHealthCachePort, aiEmbeddingCachePort, aiVendorRegistryPort, aiCryptoPort, aiRequestLogDatabasePort, aiImageFetcherPort, cfg, logger)
 	teamAdapter := postgres.NewTeamAdapter(db)
 	teamMemberAdapter := postgres.NewTeamMemberAdapter(db)
 	teamInvitationAdapter := postgres.NewTeamInvitationAdapter(db)
 	collaborationUserLookupAdapter := postgres.NewCollaborationUserLookupAdapter(db)
 	collaborationTransactionAdapter := postgres.NewCollaborationTransactionAdapter(db)
 	collaborationDomain := ProvideCollaborationDomain(teamAdapter, teamMemberAdapter, teamInvitationAdapter, collaborationUserLookupAdapter, collaborationTransactionAdapter, cfg, logger)
+	gitRepoDatabaseAdapter := postgres.NewGitRepoDatabaseAdapter(db)
+	gitCollaboratorDatabaseAdapter := postgres.NewGitCollaboratorDatabaseAdapter(db)
+	gitPullRequestDatabaseAdapter := postgres.NewGitPullRequestDatabaseAdapter(db)
+	gitPRReviewDatabaseAdapter := postgres.NewGitPRReviewDatabaseAdapter(db)
+	gitBranchProtectionDatabaseAdapter := postgres.NewGitBranchProtectionDatabaseAdapter(db)
+	gitLFSObjectDatabaseAdapter := postgres.NewGitLFSObjectDatabaseAdapter(db)
+	gitLFSLockDatabaseAdapter := postgres.NewGitLFSLockDatabaseAdapter(db)
+	gitStoragePort := ProvideGitStorage(cfg)
+	gitLFSStoragePort := ProvideGitLFSStorage(cfg)
+	gitTeamAccessPort := ProvideGitTeamAccess(collaborationDomain)
+	gitStorageQuotaChecker := ProvideGitStorageQuota(billingDomain, gitRepoDatabaseAdapter)
+	gitDomain := ProvideGitDomain(gitRepoDatabaseAdapter, gitCollaboratorDatabaseAdapter, gitPullRequestDatabaseAdapter, gitPRReviewDatabaseAdapter, gitBranchProtectionDatabaseAdapter, gitLFSObjectDatabaseAdapter, gitLFSLockDatabaseAdapter, gitStoragePort, gitLFSStoragePort, gitStorageQuotaChecker, gitTeamAccessPort, eventPublisherPort, cfg, logger)
+	gitAccessControlPort := ProvideGitAccessControl(gitDomain)
+	gitLFSDomain := ProvideGitLFSDomain(gitRepoDatabaseAdapter, gitLFSObjectDatabaseAdapter, gitLFSStoragePort, gitAccessControlPort, gitStorageQuotaChecker, cfg, logger)
+	gitLFSLockDomain := ProvideGitLFSLockDomain(gitRepoDatabaseAdapter, gitLFSLockDatabaseAdapter, gitAccessControlPort, logger)
 	mediaProviderDBAdapter := postgres.NewMediaProviderDBAdapter(db)
 	mediaModelDBAdapter := postgres.NewMediaModelDBAdapter(db)
-	mediaTaskDBAdapter := postgres.NewMediaTaskDBAdapter(db)
+	taskEventPort := ProvideTaskEvents(universalClient)
+	manager := ProvideTaskManager(db, taskEventPort, logger)
+	webhookEndpointDBAdapter := postgres.NewWebhookEndpointDBAdapter(db)
+	webhookDeliveryDBAdapter := postgres.NewWebhookDeliveryDBAdapter(db)
+	webhookSenderPort := ProvideWebhookSender()
+	webhookCryptoPort := ProvideWebhookCrypto(cfg)
+	webhookDomain := ProvideWebhookDomain(webhookEndpointDBAdapter, webhookDeliveryDBAdapter, manager, webhookSenderPort, webhookCryptoPort, bus, logger)
+	mediaTaskDatabasePort := ProvideMediaTaskDB(db, taskEventPort, webhookDomain, logger)
 	mediaProviderHealthCachePort := ProvideMediaHealthCache(universalClient)
 	mediaVendorRegistryPort := ProvideMediaVendorRegistry(client)
 	mediaCryptoPort := ProvideMediaCryptoAdapter(cfg)
-	mediaDomain := ProvideMediaDomain(mediaProviderDBAdapter, mediaModelDBAdapter, mediaTaskDBAdapter, mediaProviderHealthCachePort, mediaVendorRegistryPort, mediaCryptoPort, logger)
+	mediaVideoQuotaPort := ProvideMediaVideoQuota(billingDomain)
+	mediaAssetDBAdapter := postgres.NewMediaAssetDBAdapter(db)
+	storagePort := ProvideMediaStorage(cfg)
+	mediaFetcherPort := ProvideMediaFetcher()
+	mediaStorageQuotaPort := ProvideMediaStorageQuota(billingDomain)
+	mediaTeamAccessPort := ProvideMediaTeamAccess(collaborationDomain)
+	mediaDomain := ProvideMediaDomain(mediaProviderDBAdapter, mediaModelDBAdapter, mediaTaskDatabasePort, mediaProviderHealthCachePort, mediaVendorRegistryPort, mediaCryptoPort, mediaVideoQuotaPort, mediaAssetDBAdapter, storagePort, mediaFetcherPort, mediaStorageQuotaPort, mediaTeamAccessPort, logger)
 	chatHandler := ai.NewChatHandler(aiDomain)
 	providerAdminHandler := ProvideAIProviderAdminHandler(aiDomain)
 	modelAdminHandler := ProvideAIModelAdminHandler(aiDomain)
 	publicHandler := ProvideAIPublicHandler(aiDomain)
+	aliasHandler := ProvideAIAliasHandler(aiDomain)
+	requestLogHandler := ProvideAIRequestLogHandler(aiDomain)
 	oAuthHandler := authhttp.NewOAuthHandler(authDomain)
 	apiKeyHandler := authhttp.NewAPIKeyHandler(authDomain)
 	systemAPIKeyHandler := authhttp.NewSystemAPIKeyHandler(authDomain)
@@ -125,9 +156,14 @@ func InitializeDependencies(cfg *config.Config) (*Dependencies, func(), error) {
 	paymentHandler := paymenthttp.NewPaymentHandler(paymentDomain)
 	refundHandler := paymenthttp.NewRefundHandler(paymentDomain)
 	webhookHandler := paymenthttp.NewWebhookHandler(paymentDomain)
-	handler := ProvideGitHandler(gitDomain, cfg)
+	handler := ProvideGitHandler(gitDomain, gitLFSDomain, gitLFSLockDomain, cfg)
+	gitCredentialValidator := ProvideGitCredentialValidator(authDomain)
+	smartHTTPHandler := githttp.NewSmartHTTPHandler(gitDomain, gitLFSLockDomain, gitCredentialValidator)
+	lfsHandler := ProvideGitLFSHandler(gitDomain, gitLFSDomain, gitLFSLockDomain, gitCredentialValidator, cfg)
 	collabhttpHandler := ProvideCollaborationHandler(collaborationDomain, cfg)
 	mediahttpHandler := ProvideMediaHandler(mediaDomain)
+	taskhttpHandler := taskhttp.NewHandler(manager, mediaDomain, taskEventPort)
+	webhookhttpHandler := webhookhttp.NewHandler(webhookDomain)
 	dependencies := &Dependencies{
 		Config:                 cfg,
 		DB:                     db,
@@ -146,10 +182,14 @@ func InitializeDependencies(cfg *config.Config) (*Dependencies, func(), error) {
 		GitDomain:              gitDomain,
 		CollaborationDomain:    collaborationDomain,
 		MediaDomain:            mediaDomain,
+		TaskManager:            manager,
+		WebhookDomain:          webhookDomain,
 		AIChatHandler:          chatHandler,
 		AIProviderAdminHandler: providerAdminHandler,
 		AIModelAdminHandler:    modelAdminHandler,
 		AIPublicHandler:        publicHandler,
+		AIAliasHandler:         aliasHandler,
+		AIRequestLogHandler:    requestLogHandler,
 		OAuthHandler:           oAuthHandler,
 		APIKeyHandler:          apiKeyHandler,
 		SystemAPIKeyHandler:    systemAPIKeyHandler,
@@ -166,8 +206,12 @@ func InitializeDependencies(cfg *config.Config) (*Dependencies, func(), error) {
 		RefundHandler:          refundHandler,
 		WebhookHandler:         webhookHandler,
 		GitHandler:             handler,
+		GitSmartHTTPHandler:    smartHTTPHandler,
+		GitLFSHandler:          lfsHandler,
 		CollaborationHandler:   collabhttpHandler,
 		MediaHandler:           mediahttpHandler,
+		TaskHandler:            taskhttpHandler,
+		WebhookEndpointHandler: webhookhttpHandler,
 	}
 	return dependencies, func() {
 	}, nil
@@ -196,12 +240,16 @@ type Dependencies struct {
 	GitDomain           inbound.GitDomain
 	CollaborationDomain inbound.CollaborationDomain
 	MediaDomain         inbound.MediaDomain
+	TaskManager         *task.Manager
+	WebhookDomain       inbound.WebhookDomain
 
 	// AI HTTP Handlers
 	AIChatHandler          *ai.ChatHandler
 	AIProviderAdminHandler *ai.ProviderAdminHandler
 	AIModelAdminHandler    *ai.ModelAdminHandler
 	AIPublicHandler        *ai.PublicHandler
+	AIAliasHandler         *ai.AliasHandler
+	AIRequestLogHandler    *ai.RequestLogHandler
 
 	// Auth HTTP Handlers
 	OAuthHandler        *authhttp.OAuthHandler
@@ -229,11 +277,19 @@ type Dependencies struct {
 	WebhookHandler *paymenthttp.WebhookHandler
 
 	// Git HTTP Handlers
-	GitHandler *githttp.Handler
+	GitHandler          *githttp.Handler
+	GitSmartHTTPHandler *githttp.SmartHTTPHandler
+	GitLFSHandler       *githttp.LFSHandler
 
 	// Collaboration HTTP Handlers
 	CollaborationHandler *collabhttp.Handler
 
 	// Media HTTP Handlers
 	MediaHandler *mediahttp.Handler
+
+	// Task HTTP Handlers
+	TaskHandler *taskhttp.Handler
+
+	// Webhook HTTP Handlers
+	WebhookEndpointHandler *webhookhttp.Handler
 }
@@ -0,0 +1,195 @@
+package ai
+
+import (
+	"context"
+	"time"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+// aliasCacheTTL bounds how stale the in-memory alias table may get on
+// replicas that did not perform the write.
+const aliasCacheTTL = 30 * time.Second
+
+// resolveAlias returns the enabled alias with the given name, or nil if the
+// name is not an alias.
+func (d *aiDomain) resolveAlias(ctx context.Context, name string) (*model.AIModelAlias, error) {
+	if d.aliasDB == nil {
+		return nil, nil
+	}
+
+	d.aliasMu.RLock()
+	fresh := d.aliasCache != nil && time.Since(d.aliasCachedAt) < aliasCacheTTL
+	alias := d.aliasCache[name]
+	d.aliasMu.RUnlock()
+
+	if fresh {
+		return alias, nil
+	}
+
+	if err := d.refreshAliasCache(ctx); err != nil {
+		return nil, err
+	}
+
+	d.aliasMu.RLock()
+	defer d.aliasMu.RUnlock()
+	return d.aliasCache[name], nil
+}
+
+// refreshAliasCache reloads enabled aliases from the database.
+func (d *aiDomain) refreshAliasCache(ctx context.Context) error {
+	aliases, err := d.aliasDB.FindEnabled(ctx)
+	if err != nil {
+		return err
+	}
+
+	cache := make(map[string]*model.AIModelAlias, len(aliases))
+	for _, a := range aliases {
+		cache[a.ID] = a
+	}
+
+	d.aliasMu.Lock()
+	d.aliasCache = cache
+	d.aliasCachedAt = time.Now()
+	d.aliasMu.Unlock()
+
+	return nil
+}
+
+// invalidateAliasCache forces the next lookup to reload aliases.
+func (d *aiDomain) invalidateAliasCache() {
+	d.aliasMu.Lock()
+	d.aliasCache = nil
+	d.aliasMu.Unlock()
+}
+
+// applyAliasInfo annotates routing info with the alias used and any notice.
+func applyAliasInfo(info *model.AIRoutingInfo, alias *model.AIModelAlias) {
+	if alias == nil {
+		return
+	}
+	info.Alias = alias.ID
+	info.Deprecation = alias.Notice(time.Now())
+}
+
+// validateAlias checks that an alias has a single existing target and does
+// not shadow a concrete model.
+func (d *aiDomain) validateAlias(ctx context.Context, alias *model.AIModelAlias) error {
+	if alias.ID == "" || alias.ID == "auto" {
+		return ErrInvalidRequest
+	}
+	if (alias.TargetModelID == "") == (alias.TargetGroupID == "") {
+		return ErrInvalidAliasTarget
+	}
+	if alias.NextTargetModelID != "" && alias.NextTargetGroupID != "" {
+		return ErrInvalidAliasTarget
+	}
+	if alias.CutoverAt != nil && alias.NextTargetModelID == "" && alias.NextTargetGroupID == "" {
+		return ErrInvalidAliasTarget
+	}
+
+	existing, err := d.modelDB.FindByID(ctx, alias.ID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return ErrAliasConflict
+	}
+
+	for _, id := range []string{alias.TargetModelID, alias.NextTargetModelID} {
+		if id == "" {
+			continue
+		}
+		m, err := d.modelDB.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			return ErrModelNotFound
+		}
+	}
+
+	for _, id := range []string{alias.TargetGroupID, alias.NextTargetGroupID} {
+		if id == "" {
+			continue
+		}
+		g, err := d.groupDB.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if g == nil {
+			return ErrGroupNotFound
+		}
+	}
+
+	return nil
+}
+
+// ===== Alias Management =====
+
+func (d *aiDomain) GetAlias(ctx context.Context, id string) (*model.AIModelAlias, error) {
+	if d.aliasDB == nil {
+		return nil, ErrAliasNotFound
+	}
+	alias, err := d.aliasDB.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if alias == nil {
+		return nil, ErrAliasNotFound
+	}
+	return alias, nil
+}
+
+func (d *aiDomain) ListAliases(ctx context.Context) ([]*model.AIModelAlias, error) {
+	if d.aliasDB == nil {
+		return nil, nil
+	}
+	return d.aliasDB.FindAll(ctx)
+}
+
+func (d *aiDomain) ListEnabledAliases(ctx context.Context) ([]*model.AIModelAlias, error) {
+	if d.aliasDB == nil {
+		return nil, nil
+	}
+	return d.aliasDB.FindEnabled(ctx)
+}
+
+func (d *aiDomain) CreateAlias(ctx context.Context, alias *model.AIModelAlias) error {
+	if d.aliasDB == nil {
+		return ErrAdapterNotFound
+	}
+	if err := d.validateAlias(ctx, alias); err != nil {
+		return err
+	}
+	if err := d.aliasDB.Create(ctx, alias); err != nil {
+		return err
+	}
+	d.invalidateAliasCache()
+	return nil
+}
+
+func (d *aiDomain) UpdateAlias(ctx context.Context, alias *model.AIModelAlias) error {
+	if d.aliasDB == nil {
+		return ErrAdapterNotFound
+	}
+	if err := d.validateAlias(ctx, alias); err != nil {
+		return err
+	}
+	if err := d.aliasDB.Update(ctx, alias); err != nil {
+		return err
+	}
+	d.invalidateAliasCache()
+	return nil
+}
+
+func (d *aiDomain) DeleteAlias(ctx context.Context, id string) error {
+	if d.aliasDB == nil {
+		return ErrAdapterNotFound
+	}
+	if err := d.aliasDB.Delete(ctx, id); err != nil {
+		return err
+	}
+	d.invalidateAliasCache()
+	return nil
+}
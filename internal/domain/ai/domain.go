@@ -58,8 +58,16 @@ type AIDomain interface {
 	UpdateGroup(ctx context.Context, group *model.AIModelGroup) error
 	DeleteGroup(ctx context.Context, id string) error
 
+	// Alias management
+	GetAlias(ctx context.Context, id string) (*model.AIModelAlias, error)
+	ListAliases(ctx context.Context) ([]*model.AIModelAlias, error)
+	CreateAlias(ctx context.Context, alias *model.AIModelAlias) error
+	UpdateAlias(ctx context.Context, alias *model.AIModelAlias) error
+	DeleteAlias(ctx context.Context, id string) error
+
 	// Public API
 	ListEnabledModels(ctx context.Context) ([]*model.AIModel, error)
+	ListEnabledAliases(ctx context.Context) ([]*model.AIModelAlias, error)
 
 	// Provider operations
 	SyncModels(ctx context.Context, providerID uuid.UUID) error
@@ -70,6 +78,12 @@ type AIDomain interface {
 	StopHealthMonitor()
 	IsProviderHealthy(providerID uuid.UUID) bool
 	IsAccountHealthy(accountID uuid.UUID) bool
+
+	// Request logs
+	SearchRequestLogs(ctx context.Context, filter *model.AIRequestLogFilter) ([]*model.AIRequestLog, int64, error)
+	GetRequestLog(ctx context.Context, id uuid.UUID) (*model.AIRequestLog, error)
+	ReplayRequest(ctx context.Context, adminID, id uuid.UUID, modelID string) (*model.AIReplayResult, error)
+	PurgeExpiredRequestLogs(ctx context.Context) (int64, error)
 }
 
 // aiDomain implements AIDomain.
@@ -79,6 +93,7 @@ type aiDomain struct {
 	modelDB    outbound.AIModelDatabasePort
 	accountDB  outbound.AIProviderAccountDatabasePort
 	groupDB    outbound.AIModelGroupDatabasePort
+	aliasDB    outbound.AIModelAliasDatabasePort
 
 	// Cache ports
 	healthCache    outbound.AIProviderHealthCachePort
@@ -88,6 +103,13 @@ type aiDomain struct {
 	vendorRegistry outbound.AIVendorRegistryPort
 	crypto         outbound.AICryptoPort
 	usageRecorder  outbound.AIUsageRecorderPort
+	imageFetcher   outbound.AIImageFetcherPort
+	imageMaxBytes  int64
+
+	// Request logging
+	requestLogDB        outbound.AIRequestLogDatabasePort
+	requestLogEnabled   bool
+	requestLogRetention time.Duration
 
 	// Routing
 	strategyChain *StrategyChain
@@ -96,6 +118,9 @@ type aiDomain struct {
 	providerCache   map[uuid.UUID]*model.AIProvider
 	modelCache      map[string]*model.AIModel
 	providerMu      sync.RWMutex
+	aliasCache      map[string]*model.AIModelAlias
+	aliasCachedAt   time.Time
+	aliasMu         sync.RWMutex
 
 	// Health monitoring
 	healthStatus    map[uuid.UUID]bool
@@ -111,12 +136,23 @@ type aiDomain struct {
 // Config holds AI domain configuration.
 type Config struct {
 	HealthCheckInterval time.Duration
+
+	// RequestLogEnabled turns on storage of full request/response bodies.
+	RequestLogEnabled bool
+	// RequestLogRetention is how long request logs are kept.
+	RequestLogRetention time.Duration
+
+	// ImageMaxBytes caps the size of each input image.
+	ImageMaxBytes int64
 }
 
 // DefaultConfig returns default configuration.
 func DefaultConfig() *Config {
 	return &Config{
 		HealthCheckInterval: 30 * time.Second,
+		RequestLogEnabled:   false,
+		RequestLogRetention: 7 * 24 * time.Hour,
+		ImageMaxBytes:       20 << 20,
 	}
 }
 
@@ -126,11 +162,14 @@ func NewAIDomain(
 	modelDB outbound.AIModelDatabasePort,
 	accountDB outbound.AIProviderAccountDatabasePort,
 	groupDB outbound.AIModelGroupDatabasePort,
+	aliasDB outbound.AIModelAliasDatabasePort,
 	healthCache outbound.AIProviderHealthCachePort,
 	embeddingCache outbound.AIEmbeddingCachePort,
 	vendorRegistry outbound.AIVendorRegistryPort,
 	crypto outbound.AICryptoPort,
 	usageRecorder outbound.AIUsageRecorderPort,
+	requestLogDB outbound.AIRequestLogDatabasePort,
+	imageFetcher outbound.AIImageFetcherPort,
 	config *Config,
 	logger *zap.Logger,
 ) AIDomain {
@@ -143,11 +182,15 @@ func NewAIDomain(
 		modelDB:        modelDB,
 		accountDB:      accountDB,
 		groupDB:        groupDB,
+		aliasDB:        aliasDB,
 		healthCache:    healthCache,
 		embeddingCache: embeddingCache,
 		vendorRegistry: vendorRegistry,
 		crypto:         crypto,
 		usageRecorder:  usageRecorder,
+		requestLogDB:   requestLogDB,
+		imageFetcher:   imageFetcher,
+		imageMaxBytes:  config.ImageMaxBytes,
 		strategyChain:  DefaultStrategyChain(),
 		providerCache:  make(map[uuid.UUID]*model.AIProvider),
 		modelCache:     make(map[string]*model.AIModel),
@@ -157,6 +200,16 @@ func NewAIDomain(
 		logger:         logger,
 	}
 
+	d.requestLogEnabled = config.RequestLogEnabled && requestLogDB != nil && crypto != nil
+	if d.imageMaxBytes <= 0 {
+		d.imageMaxBytes = DefaultConfig().ImageMaxBytes
+	}
+
+	d.requestLogRetention = config.RequestLogRetention
+	if d.requestLogRetention <= 0 {
+		d.requestLogRetention = DefaultConfig().RequestLogRetention
+	}
+
 	return d
 }
 
@@ -171,7 +224,10 @@ func (d *aiDomain) Chat(ctx context.Context, userID uuid.UUID, req *model.AIChat
 	startTime := time.Now()
 
 	// Build routing context
-	routingCtx := d.buildRoutingContext(req)
+	routingCtx, err := d.buildRoutingContext(ctx, req)
+	if err != nil {
+		return nil, err
+	}
 
 	// Route to best model
 	result, err := d.Route(ctx, routingCtx)
@@ -185,10 +241,16 @@ func (d *aiDomain) Chat(ctx context.Context, userID uuid.UUID, req *model.AIChat
 		return nil, fmt.Errorf("get adapter: %w", err)
 	}
 
+	// Inline and resize images for the selected provider
+	messages, imageTokens, err := d.prepareImages(ctx, req.Messages, result.Provider)
+	if err != nil {
+		return nil, err
+	}
+
 	// Build adapter request
 	adapterReq := &model.AIChatRequest{
 		Model:       result.Model.ID,
-		Messages:    req.Messages,
+		Messages:    messages,
 		MaxTokens:   req.MaxTokens,
 		Temperature: req.Temperature,
 		TopP:        req.TopP,
@@ -204,19 +266,25 @@ func (d *aiDomain) Chat(ctx context.Context, userID uuid.UUID, req *model.AIChat
 	if err != nil {
 		// Mark failure for health tracking
 		d.markRequestFailure(ctx, result, err)
+		d.logRequest(ctx, userID, req, result, nil, err, time.Since(startTime).Milliseconds(), 0, nil)
 		return nil, fmt.Errorf("chat failed: %w", err)
 	}
 
+	if resp.Usage != nil {
+		resp.Usage.ImageTokens = imageTokens
+	}
+
 	// Calculate latency and cost
 	latencyMs := time.Since(startTime).Milliseconds()
 	costUSD := d.calculateCost(result.Model, resp.Usage)
+	d.logRequest(ctx, userID, req, result, resp, nil, latencyMs, costUSD, nil)
 
 	// Mark success
 	d.markRequestSuccess(ctx, result, resp.Usage, costUSD)
 
 	// Record usage for billing
 	if d.usageRecorder != nil && resp.Usage != nil {
-		_ = d.usageRecorder.RecordUsage(ctx, userID, result.Model.ID, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, costUSD)
+		_ = d.usageRecorder.RecordUsage(ctx, userID, result.Model.ID, resp.Usage, costUSD)
 	}
 
 	// Add routing info
@@ -226,6 +294,7 @@ func (d *aiDomain) Chat(ctx context.Context, userID uuid.UUID, req *model.AIChat
 		LatencyMs:    latencyMs,
 		CostUSD:      costUSD,
 	}
+	applyAliasInfo(resp.Routing, routingCtx.Alias)
 
 	return resp, nil
 }
@@ -237,7 +306,10 @@ func (d *aiDomain) ChatStream(ctx context.Context, userID uuid.UUID, req *model.
 	}
 
 	// Build routing context
-	routingCtx := d.buildRoutingContext(req)
+	routingCtx, err := d.buildRoutingContext(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
 	routingCtx.RequireStream = true
 
 	// Route to best model
@@ -252,10 +324,16 @@ func (d *aiDomain) ChatStream(ctx context.Context, userID uuid.UUID, req *model.
 		return nil, nil, fmt.Errorf("get adapter: %w", err)
 	}
 
+	// Inline and resize images for the selected provider
+	messages, imageTokens, err := d.prepareImages(ctx, req.Messages, result.Provider)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Build adapter request
 	adapterReq := &model.AIChatRequest{
 		Model:       result.Model.ID,
-		Messages:    req.Messages,
+		Messages:    messages,
 		MaxTokens:   req.MaxTokens,
 		Temperature: req.Temperature,
 		TopP:        req.TopP,
@@ -277,6 +355,9 @@ func (d *aiDomain) ChatStream(ctx context.Context, userID uuid.UUID, req *model.
 		ProviderUsed: result.Provider.Name,
 		ModelUsed:    result.Model.ID,
 	}
+	applyAliasInfo(routingInfo, routingCtx.Alias)
+
+	chunks = d.trackStream(ctx, userID, req, result, chunks, imageTokens, time.Now())
 
 	return chunks, routingInfo, nil
 }
@@ -321,7 +402,7 @@ func (d *aiDomain) Embed(ctx context.Context, userID uuid.UUID, req *model.AIEmb
 		d.markRequestSuccess(ctx, result, resp.Usage, costUSD)
 
 		if d.usageRecorder != nil {
-			_ = d.usageRecorder.RecordUsage(ctx, userID, result.Model.ID, resp.Usage.PromptTokens, 0, costUSD)
+			_ = d.usageRecorder.RecordUsage(ctx, userID, result.Model.ID, resp.Usage, costUSD)
 		}
 	}
 
@@ -464,29 +545,57 @@ func (d *aiDomain) selectAccount(accounts []*model.AIProviderAccount) *model.AIP
 }
 
 // buildRoutingContext builds a routing context from a chat request.
-func (d *aiDomain) buildRoutingContext(req *model.AIChatRequest) *model.AIRoutingContext {
-	ctx := model.NewAIRoutingContext()
-	ctx.TaskType = string(model.AITaskTypeChat)
-	ctx.RequireStream = req.Stream
+// A requested model name that matches an alias is resolved to its current target.
+func (d *aiDomain) buildRoutingContext(ctx context.Context, req *model.AIChatRequest) (*model.AIRoutingContext, error) {
+	routingCtx := model.NewAIRoutingContext()
+	routingCtx.TaskType = string(model.AITaskTypeChat)
+	routingCtx.RequireStream = req.Stream
 
 	// Detect required capabilities from messages
 	for _, msg := range req.Messages {
 		if msg.HasImages() {
-			ctx.RequireVision = true
+			routingCtx.RequireVision = true
 			break
 		}
 	}
 
 	if len(req.Tools) > 0 {
-		ctx.RequireTools = true
+		routingCtx.RequireTools = true
 	}
 
-	// If specific model requested
-	if req.Model != "" && req.Model != "auto" {
-		ctx.PreferredModels = []string{req.Model}
+	if req.WantsReasoning() {
+		if req.ReasoningEffort != "" && !req.ReasoningEffort.IsValid() {
+			return nil, fmt.Errorf("%w: unknown reasoning_effort %q", ErrInvalidReasoningConfig, req.ReasoningEffort)
+		}
+		if req.Thinking != nil && req.Thinking.BudgetTokens < model.AIMinThinkingBudget {
+			return nil, fmt.Errorf("%w: thinking budget must be at least %d tokens", ErrInvalidReasoningConfig, model.AIMinThinkingBudget)
+		}
+		routingCtx.RequireReasoning = true
+	}
+
+	if req.Model == "" || req.Model == "auto" {
+		return routingCtx, nil
+	}
+
+	// Resolve alias before setting preferences
+	alias, err := d.resolveAlias(ctx, req.Model)
+	if err != nil {
+		return nil, fmt.Errorf("resolve alias: %w", err)
+	}
+	if alias == nil {
+		routingCtx.PreferredModels = []string{req.Model}
+		return routingCtx, nil
 	}
 
-	return ctx
+	routingCtx.Alias = alias
+	modelID, groupID := alias.Resolve(time.Now())
+	if groupID != "" {
+		routingCtx.GroupID = groupID
+	} else {
+		routingCtx.PreferredModels = []string{modelID}
+	}
+
+	return routingCtx, nil
 }
 
 // ===== Provider Management =====
@@ -700,6 +809,10 @@ func (d *aiDomain) StartHealthMonitor(ctx context.Context) {
 		}
 	}()
 
+	if d.requestLogEnabled {
+		go d.runRequestLogPurge(d.healthCtx)
+	}
+
 	d.logger.Info("health monitor started", zap.Duration("interval", d.healthInterval))
 }
 
@@ -835,13 +948,35 @@ func (d *aiDomain) markRequestFailure(ctx context.Context, result *model.AIRouti
 }
 
 // calculateCost calculates the cost of a request.
+// Cached prompt tokens and reasoning tokens are billed at the model's
+// dedicated rates when set.
 func (d *aiDomain) calculateCost(m *model.AIModel, usage *model.AIUsage) float64 {
 	if usage == nil {
 		return 0
 	}
 
-	inputCost := float64(usage.PromptTokens) / 1000 * m.InputCostPer1K
-	outputCost := float64(usage.CompletionTokens) / 1000 * m.OutputCostPer1K
+	cacheReadRate := m.CachedInputCostPer1K
+	if cacheReadRate <= 0 {
+		cacheReadRate = m.InputCostPer1K
+	}
+	cacheWriteRate := m.CacheWriteCostPer1K
+	if cacheWriteRate <= 0 {
+		cacheWriteRate = m.InputCostPer1K
+	}
+
+	reasoningRate := m.ReasoningCostPer1K
+	if reasoningRate <= 0 {
+		reasoningRate = m.OutputCostPer1K
+	}
+
+	uncached := max(usage.PromptTokens-usage.CacheReadTokens-usage.CacheWriteTokens, 0)
+	inputCost := float64(uncached) / 1000 * m.InputCostPer1K
+	inputCost += float64(usage.CacheReadTokens) / 1000 * cacheReadRate
+	inputCost += float64(usage.CacheWriteTokens) / 1000 * cacheWriteRate
+
+	answer := max(usage.CompletionTokens-usage.ReasoningTokens, 0)
+	outputCost := float64(answer) / 1000 * m.OutputCostPer1K
+	outputCost += float64(usage.ReasoningTokens) / 1000 * reasoningRate
 
 	return inputCost + outputCost
 }
@@ -1,7 +1,13 @@
 package ai
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/png"
+	"strings"
 	"testing"
 	"time"
 
@@ -412,11 +418,14 @@ func newTestDomain(
 		mdb,
 		adb,
 		gdb,
+		nil, // aliasDB
 		nil, // healthCache
 		nil, // embeddingCache
 		nil, // vendorRegistry
 		nil, // crypto
 		nil, // usageRecorder
+		nil, // requestLogDB
+		nil, // imageFetcher
 		DefaultConfig(),
 		logger,
 	)
@@ -782,8 +791,8 @@ func TestAIDomain_CreateAccount(t *testing.T) {
 		logger := zap.NewNop()
 
 		domain := NewAIDomain(
-			nil, nil, mockAccountDB, nil,
-			nil, nil, nil, mockCrypto, nil,
+			nil, nil, mockAccountDB, nil, nil,
+			nil, nil, nil, mockCrypto, nil, nil, nil,
 			DefaultConfig(), logger,
 		)
 
@@ -1196,6 +1205,47 @@ func TestAIDomain_CalculateCost(t *testing.T) {
 		// Expected: (1000/1000 * 0.01) + (500/1000 * 0.03) = 0.01 + 0.015 = 0.025
 		assert.InDelta(t, 0.025, cost, 0.0001)
 	})
+
+	t.Run("applies prompt cache rates", func(t *testing.T) {
+		m := createTestModel("claude", uuid.New())
+		m.InputCostPer1K = 0.003
+		m.OutputCostPer1K = 0.015
+		m.CachedInputCostPer1K = 0.0003
+		m.CacheWriteCostPer1K = 0.00375
+
+		usage := &model.AIUsage{
+			PromptTokens:     10000,
+			CompletionTokens: 1000,
+			CacheReadTokens:  6000,
+			CacheWriteTokens: 2000,
+		}
+
+		cost := domain.calculateCost(m, usage)
+
+		// 2000 uncached * 0.003 + 6000 read * 0.0003 + 2000 write * 0.00375 + 1000 out * 0.015
+		assert.InDelta(t, 0.006+0.0018+0.0075+0.015, cost, 0.000001)
+	})
+
+	t.Run("applies reasoning rate", func(t *testing.T) {
+		m := createTestModel("o3", uuid.New())
+		m.InputCostPer1K = 0.002
+		m.OutputCostPer1K = 0.008
+		m.ReasoningCostPer1K = 0.004
+
+		usage := &model.AIUsage{PromptTokens: 1000, CompletionTokens: 3000, ReasoningTokens: 2000}
+
+		// 1000 in * 0.002 + 1000 answer * 0.008 + 2000 reasoning * 0.004
+		assert.InDelta(t, 0.002+0.008+0.008, domain.calculateCost(m, usage), 0.000001)
+	})
+
+	t.Run("cached tokens fall back to input rate", func(t *testing.T) {
+		m := createTestModel("gpt-4", uuid.New())
+		m.InputCostPer1K = 0.01
+
+		usage := &model.AIUsage{PromptTokens: 1000, CacheReadTokens: 400}
+
+		assert.InDelta(t, 0.01, domain.calculateCost(m, usage), 0.000001)
+	})
 }
 
 // ===== Config Tests =====
@@ -1322,7 +1372,8 @@ func TestAIDomain_BuildRoutingContext(t *testing.T) {
 			},
 		}
 
-		ctx := domain.buildRoutingContext(req)
+		ctx, err := domain.buildRoutingContext(context.Background(), req)
+		assert.NoError(t, err)
 
 		assert.Equal(t, string(model.AITaskTypeChat), ctx.TaskType)
 		assert.False(t, ctx.RequireStream)
@@ -1342,7 +1393,8 @@ func TestAIDomain_BuildRoutingContext(t *testing.T) {
 			},
 		}
 
-		ctx := domain.buildRoutingContext(req)
+		ctx, err := domain.buildRoutingContext(context.Background(), req)
+		assert.NoError(t, err)
 
 		assert.True(t, ctx.RequireStream)
 	})
@@ -1360,7 +1412,8 @@ func TestAIDomain_BuildRoutingContext(t *testing.T) {
 			},
 		}
 
-		ctx := domain.buildRoutingContext(req)
+		ctx, err := domain.buildRoutingContext(context.Background(), req)
+		assert.NoError(t, err)
 
 		assert.True(t, ctx.RequireTools)
 	})
@@ -1375,7 +1428,8 @@ func TestAIDomain_BuildRoutingContext(t *testing.T) {
 			},
 		}
 
-		ctx := domain.buildRoutingContext(req)
+		ctx, err := domain.buildRoutingContext(context.Background(), req)
+		assert.NoError(t, err)
 
 		assert.Len(t, ctx.PreferredModels, 0)
 	})
@@ -1465,8 +1519,8 @@ func TestAIDomain_ResolveAPIKey(t *testing.T) {
 		logger := zap.NewNop()
 
 		domain := NewAIDomain(
-			mockProviderDB, mockModelDB, mockAccountDB, nil,
-			nil, nil, nil, mockCrypto, nil,
+			mockProviderDB, mockModelDB, mockAccountDB, nil, nil,
+			nil, nil, nil, mockCrypto, nil, nil, nil,
 			DefaultConfig(), logger,
 		).(*aiDomain)
 
@@ -1523,8 +1577,8 @@ func TestAIDomain_Route_WithAccountSelection(t *testing.T) {
 		logger := zap.NewNop()
 
 		domain := NewAIDomain(
-			mockProviderDB, mockModelDB, mockAccountDB, nil,
-			nil, nil, nil, mockCrypto, nil,
+			mockProviderDB, mockModelDB, mockAccountDB, nil, nil,
+			nil, nil, nil, mockCrypto, nil, nil, nil,
 			DefaultConfig(), logger,
 		)
 
@@ -1560,8 +1614,8 @@ func TestAIDomain_ProviderHealthCheck(t *testing.T) {
 		logger := zap.NewNop()
 
 		domain := NewAIDomain(
-			mockProviderDB, nil, nil, nil,
-			nil, nil, mockRegistry, nil, nil,
+			mockProviderDB, nil, nil, nil, nil,
+			nil, nil, mockRegistry, nil, nil, nil, nil,
 			DefaultConfig(), logger,
 		)
 
@@ -1585,8 +1639,8 @@ func TestAIDomain_ProviderHealthCheck(t *testing.T) {
 		logger := zap.NewNop()
 
 		domain := NewAIDomain(
-			mockProviderDB, nil, nil, nil,
-			nil, nil, mockRegistry, nil, nil,
+			mockProviderDB, nil, nil, nil, nil,
+			nil, nil, mockRegistry, nil, nil, nil, nil,
 			DefaultConfig(), logger,
 		)
 
@@ -1608,8 +1662,8 @@ func TestAIDomain_ProviderHealthCheck(t *testing.T) {
 		logger := zap.NewNop()
 
 		domain := NewAIDomain(
-			mockProviderDB, nil, nil, nil,
-			nil, nil, nil, nil, nil,
+			mockProviderDB, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil, nil, nil,
 			DefaultConfig(), logger,
 		)
 
@@ -1643,8 +1697,8 @@ func TestAIDomain_UpdateProviderHealth(t *testing.T) {
 		logger := zap.NewNop()
 
 		domain := NewAIDomain(
-			nil, nil, nil, nil,
-			mockHealthCache, nil, nil, nil, nil,
+			nil, nil, nil, nil, nil,
+			mockHealthCache, nil, nil, nil, nil, nil, nil,
 			DefaultConfig(), logger,
 		).(*aiDomain)
 
@@ -1679,8 +1733,8 @@ func TestAIDomain_Chat_Success(t *testing.T) {
 		logger := zap.NewNop()
 
 		domain := NewAIDomain(
-			mockProviderDB, mockModelDB, nil, nil,
-			nil, nil, mockRegistry, nil, nil,
+			mockProviderDB, mockModelDB, nil, nil, nil,
+			nil, nil, mockRegistry, nil, nil, nil, nil,
 			DefaultConfig(), logger,
 		)
 
@@ -1734,8 +1788,8 @@ func TestAIDomain_ChatStream_Success(t *testing.T) {
 		logger := zap.NewNop()
 
 		domain := NewAIDomain(
-			mockProviderDB, mockModelDB, nil, nil,
-			nil, nil, mockRegistry, nil, nil,
+			mockProviderDB, mockModelDB, nil, nil, nil,
+			nil, nil, mockRegistry, nil, nil, nil, nil,
 			DefaultConfig(), logger,
 		)
 
@@ -1775,6 +1829,63 @@ func TestAIDomain_ChatStream_Success(t *testing.T) {
 		for range chunks {
 		}
 	})
+
+	t.Run("records usage with image tokens", func(t *testing.T) {
+		mockProviderDB := new(MockProviderDB)
+		mockModelDB := new(MockModelDB)
+		mockRegistry := new(MockVendorRegistry)
+		mockAdapter := new(MockVendorAdapter)
+		mockRecorder := new(MockUsageRecorder)
+		fetcher := new(MockImageFetcher)
+
+		domain := NewAIDomain(
+			mockProviderDB, mockModelDB, nil, nil, nil,
+			nil, nil, mockRegistry, nil, mockRecorder, nil, fetcher,
+			DefaultConfig(), zap.NewNop(),
+		)
+
+		providerID := uuid.New()
+		provider := createTestProvider(providerID, "openai")
+		m := createTestModel("gpt-4", providerID)
+		m.Capabilities = append(m.Capabilities, string(model.AICapabilityVision))
+		userID := uuid.New()
+
+		req := &model.AIChatRequest{
+			Model:    "gpt-4",
+			Stream:   true,
+			Messages: imageMessage("https://example.com/cat.png"),
+		}
+
+		chunkChan := make(chan *model.AIChatChunk, 2)
+		chunkChan <- &model.AIChatChunk{ID: "chunk-1", Delta: &model.AIDelta{Content: "A cat"}}
+		chunkChan <- &model.AIChatChunk{ID: "chunk-1", Usage: &model.AIUsage{PromptTokens: 300, CompletionTokens: 5, TotalTokens: 305}}
+		close(chunkChan)
+
+		fetcher.On("Fetch", mock.Anything, "https://example.com/cat.png", mock.Anything).
+			Return(encodeTestPNG(t, 1024, 1024), "image/png", nil)
+		mockModelDB.On("FindByCapabilities", mock.Anything, mock.Anything).Return([]*model.AIModel{m}, nil)
+		mockProviderDB.On("FindByID", mock.Anything, providerID).Return(provider, nil)
+		mockRegistry.On("GetForProvider", provider).Return(mockAdapter, nil)
+		mockAdapter.On("ChatStream", mock.Anything, mock.Anything, m, provider, provider.APIKey).Return((<-chan *model.AIChatChunk)(chunkChan), nil)
+
+		wantImageTokens := imageTokens(model.AIProviderTypeOpenAI, 1024, 1024, "")
+		recorded := make(chan struct{})
+		mockRecorder.On("RecordUsage", mock.Anything, userID, "gpt-4", mock.MatchedBy(func(u *model.AIUsage) bool {
+			return u.PromptTokens == 300 && u.ImageTokens == wantImageTokens
+		}), mock.Anything).Return(nil).Run(func(mock.Arguments) { close(recorded) })
+
+		chunks, _, err := domain.ChatStream(context.Background(), userID, req)
+		assert.NoError(t, err)
+		for range chunks {
+		}
+
+		select {
+		case <-recorded:
+		case <-time.After(time.Second):
+			t.Fatal("usage was not recorded")
+		}
+		mockRecorder.AssertExpectations(t)
+	})
 }
 
 // ===== Embed Success Path Tests =====
@@ -1788,8 +1899,8 @@ func TestAIDomain_Embed_Success(t *testing.T) {
 		logger := zap.NewNop()
 
 		domain := NewAIDomain(
-			mockProviderDB, mockModelDB, nil, nil,
-			nil, nil, mockRegistry, nil, nil,
+			mockProviderDB, mockModelDB, nil, nil, nil,
+			nil, nil, mockRegistry, nil, nil, nil, nil,
 			DefaultConfig(), logger,
 		)
 
@@ -1827,3 +1938,622 @@ func TestAIDomain_Embed_Success(t *testing.T) {
 		assert.Len(t, resp.Embeddings, 1)
 	})
 }
+
+// ===== Request Log Tests =====
+
+type MockUsageRecorder struct {
+	mock.Mock
+}
+
+func (m *MockUsageRecorder) RecordUsage(ctx context.Context, userID uuid.UUID, modelID string, usage *model.AIUsage, cost float64) error {
+	args := m.Called(ctx, userID, modelID, usage, cost)
+	return args.Error(0)
+}
+
+type MockRequestLogDB struct {
+	mock.Mock
+}
+
+func (m *MockRequestLogDB) Create(ctx context.Context, log *model.AIRequestLog) error {
+	args := m.Called(ctx, log)
+	return args.Error(0)
+}
+
+func (m *MockRequestLogDB) FindByID(ctx context.Context, id uuid.UUID) (*model.AIRequestLog, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AIRequestLog), args.Error(1)
+}
+
+func (m *MockRequestLogDB) Find(ctx context.Context, filter *model.AIRequestLogFilter) ([]*model.AIRequestLog, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*model.AIRequestLog), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockRequestLogDB) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func requestLogConfig() *Config {
+	config := DefaultConfig()
+	config.RequestLogEnabled = true
+	config.RequestLogRetention = time.Hour
+	return config
+}
+
+func TestAIDomain_Chat_RequestLog(t *testing.T) {
+	t.Run("logs encrypted request and response when enabled", func(t *testing.T) {
+		mockProviderDB := new(MockProviderDB)
+		mockModelDB := new(MockModelDB)
+		mockRegistry := new(MockVendorRegistry)
+		mockAdapter := new(MockVendorAdapter)
+		mockCrypto := new(MockCrypto)
+		mockLogDB := new(MockRequestLogDB)
+
+		domain := NewAIDomain(
+			mockProviderDB, mockModelDB, nil, nil, nil,
+			nil, nil, mockRegistry, mockCrypto, nil, mockLogDB, nil,
+			requestLogConfig(), zap.NewNop(),
+		)
+
+		providerID := uuid.New()
+		provider := createTestProvider(providerID, "openai")
+		m := createTestModel("gpt-4", providerID)
+		userID := uuid.New()
+		apiKeyID := uuid.New()
+
+		req := &model.AIChatRequest{
+			Model:     "gpt-4",
+			Messages:  []*model.AIChatMessage{{Role: "user", Content: "Hello"}},
+			RequestID: "req-1",
+			APIKeyID:  &apiKeyID,
+		}
+		resp := &model.AIChatResponse{
+			ID:      "chat-123",
+			Model:   "gpt-4",
+			Message: &model.AIChatMessage{Role: "assistant", Content: "Hi"},
+			Usage:   &model.AIUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		}
+
+		mockModelDB.On("FindByCapabilities", mock.Anything, mock.Anything).Return([]*model.AIModel{m}, nil)
+		mockProviderDB.On("FindByID", mock.Anything, providerID).Return(provider, nil)
+		mockRegistry.On("GetForProvider", provider).Return(mockAdapter, nil)
+		mockAdapter.On("Chat", mock.Anything, mock.Anything, m, provider, provider.APIKey).Return(resp, nil)
+		mockCrypto.On("Encrypt", mock.Anything).Return("encrypted", nil)
+		mockLogDB.On("Create", mock.Anything, mock.MatchedBy(func(l *model.AIRequestLog) bool {
+			return l.RequestID == "req-1" &&
+				l.UserID == userID &&
+				l.APIKeyID != nil && *l.APIKeyID == apiKeyID &&
+				l.ModelID == "gpt-4" &&
+				l.Success &&
+				l.PromptTokens == 10 &&
+				l.EncryptedRequest == "encrypted" &&
+				l.EncryptedResponse == "encrypted" &&
+				l.ExpiresAt.After(l.CreatedAt)
+		})).Return(nil)
+
+		_, err := domain.Chat(context.Background(), userID, req)
+
+		assert.NoError(t, err)
+		mockLogDB.AssertExpectations(t)
+		mockCrypto.AssertNumberOfCalls(t, "Encrypt", 2)
+	})
+
+	t.Run("logs streamed usage from the final chunk", func(t *testing.T) {
+		mockProviderDB := new(MockProviderDB)
+		mockModelDB := new(MockModelDB)
+		mockRegistry := new(MockVendorRegistry)
+		mockAdapter := new(MockVendorAdapter)
+		mockCrypto := new(MockCrypto)
+		mockLogDB := new(MockRequestLogDB)
+
+		domain := NewAIDomain(
+			mockProviderDB, mockModelDB, nil, nil, nil,
+			nil, nil, mockRegistry, mockCrypto, nil, mockLogDB, nil,
+			requestLogConfig(), zap.NewNop(),
+		)
+
+		providerID := uuid.New()
+		provider := createTestProvider(providerID, "openai")
+		m := createTestModel("gpt-4", providerID)
+		req := &model.AIChatRequest{
+			Model:    "gpt-4",
+			Stream:   true,
+			Messages: []*model.AIChatMessage{{Role: "user", Content: "Hello"}},
+		}
+
+		chunkChan := make(chan *model.AIChatChunk, 2)
+		chunkChan <- &model.AIChatChunk{ID: "chunk-1", Delta: &model.AIDelta{Content: "Hi"}}
+		chunkChan <- &model.AIChatChunk{ID: "chunk-1", Usage: &model.AIUsage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}}
+		close(chunkChan)
+
+		mockModelDB.On("FindByCapabilities", mock.Anything, mock.Anything).Return([]*model.AIModel{m}, nil)
+		mockProviderDB.On("FindByID", mock.Anything, providerID).Return(provider, nil)
+		mockRegistry.On("GetForProvider", provider).Return(mockAdapter, nil)
+		mockAdapter.On("ChatStream", mock.Anything, mock.Anything, m, provider, provider.APIKey).Return((<-chan *model.AIChatChunk)(chunkChan), nil)
+		mockCrypto.On("Encrypt", mock.Anything).Return("encrypted", nil)
+		mockLogDB.On("Create", mock.Anything, mock.MatchedBy(func(l *model.AIRequestLog) bool {
+			return l.Stream &&
+				l.PromptTokens == 10 &&
+				l.CompletionTokens == 5 &&
+				l.CostUSD > 0.00024 && l.CostUSD < 0.00026
+		})).Return(nil)
+
+		chunks, _, err := domain.ChatStream(context.Background(), uuid.New(), req)
+		assert.NoError(t, err)
+		for range chunks {
+		}
+
+		mockLogDB.AssertExpectations(t)
+	})
+
+	t.Run("does not log when disabled", func(t *testing.T) {
+		mockProviderDB := new(MockProviderDB)
+		mockModelDB := new(MockModelDB)
+		mockRegistry := new(MockVendorRegistry)
+		mockAdapter := new(MockVendorAdapter)
+		mockCrypto := new(MockCrypto)
+		mockLogDB := new(MockRequestLogDB)
+
+		domain := NewAIDomain(
+			mockProviderDB, mockModelDB, nil, nil, nil,
+			nil, nil, mockRegistry, mockCrypto, nil, mockLogDB, nil,
+			DefaultConfig(), zap.NewNop(),
+		)
+
+		providerID := uuid.New()
+		provider := createTestProvider(providerID, "openai")
+		m := createTestModel("gpt-4", providerID)
+
+		mockModelDB.On("FindByCapabilities", mock.Anything, mock.Anything).Return([]*model.AIModel{m}, nil)
+		mockProviderDB.On("FindByID", mock.Anything, providerID).Return(provider, nil)
+		mockRegistry.On("GetForProvider", provider).Return(mockAdapter, nil)
+		mockAdapter.On("Chat", mock.Anything, mock.Anything, m, provider, provider.APIKey).
+			Return(&model.AIChatResponse{Message: &model.AIChatMessage{Role: "assistant", Content: "Hi"}}, nil)
+
+		_, err := domain.Chat(context.Background(), uuid.New(), &model.AIChatRequest{
+			Messages: []*model.AIChatMessage{{Role: "user", Content: "Hello"}},
+		})
+
+		assert.NoError(t, err)
+		mockLogDB.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAIDomain_GetRequestLog(t *testing.T) {
+	t.Run("not found", func(t *testing.T) {
+		mockLogDB := new(MockRequestLogDB)
+		mockCrypto := new(MockCrypto)
+		domain := NewAIDomain(
+			nil, nil, nil, nil, nil,
+			nil, nil, nil, mockCrypto, nil, mockLogDB, nil,
+			requestLogConfig(), zap.NewNop(),
+		)
+
+		id := uuid.New()
+		mockLogDB.On("FindByID", mock.Anything, id).Return(nil, nil)
+
+		_, err := domain.GetRequestLog(context.Background(), id)
+		assert.ErrorIs(t, err, ErrRequestLogNotFound)
+	})
+
+	t.Run("decrypts bodies", func(t *testing.T) {
+		mockLogDB := new(MockRequestLogDB)
+		mockCrypto := new(MockCrypto)
+		domain := NewAIDomain(
+			nil, nil, nil, nil, nil,
+			nil, nil, nil, mockCrypto, nil, mockLogDB, nil,
+			requestLogConfig(), zap.NewNop(),
+		)
+
+		id := uuid.New()
+		mockLogDB.On("FindByID", mock.Anything, id).Return(&model.AIRequestLog{
+			ID:                id,
+			EncryptedRequest:  "enc-req",
+			EncryptedResponse: "enc-resp",
+		}, nil)
+		mockCrypto.On("Decrypt", "enc-req").Return(`{"model":"gpt-4","messages":[{"role":"user","content":"Hello"}]}`, nil)
+		mockCrypto.On("Decrypt", "enc-resp").Return(`{"id":"chat-1","message":{"role":"assistant","content":"Hi"}}`, nil)
+
+		entry, err := domain.GetRequestLog(context.Background(), id)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "gpt-4", entry.Request.Model)
+		assert.Equal(t, "Hi", entry.Response.Message.Content)
+	})
+}
+
+func TestAIDomain_ReplayRequest(t *testing.T) {
+	t.Run("replays against requested model without billing", func(t *testing.T) {
+		mockProviderDB := new(MockProviderDB)
+		mockModelDB := new(MockModelDB)
+		mockRegistry := new(MockVendorRegistry)
+		mockAdapter := new(MockVendorAdapter)
+		mockCrypto := new(MockCrypto)
+		mockLogDB := new(MockRequestLogDB)
+
+		domain := NewAIDomain(
+			mockProviderDB, mockModelDB, nil, nil, nil,
+			nil, nil, mockRegistry, mockCrypto, nil, mockLogDB, nil,
+			requestLogConfig(), zap.NewNop(),
+		)
+
+		providerID := uuid.New()
+		provider := createTestProvider(providerID, "openai")
+		original := createTestModel("gpt-4", providerID)
+		target := createTestModel("gpt-4o", providerID)
+		logID := uuid.New()
+		adminID := uuid.New()
+		apiKeyID := uuid.New()
+
+		mockLogDB.On("FindByID", mock.Anything, logID).Return(&model.AIRequestLog{
+			ID:               logID,
+			UserID:           uuid.New(),
+			APIKeyID:         &apiKeyID,
+			ModelID:          "gpt-4",
+			EncryptedRequest: "enc-req",
+		}, nil)
+		mockCrypto.On("Decrypt", "enc-req").Return(`{"model":"gpt-4","messages":[{"role":"user","content":"Hello"}]}`, nil)
+		mockCrypto.On("Encrypt", mock.Anything).Return("encrypted", nil)
+		mockModelDB.On("FindByID", mock.Anything, "gpt-4o").Return(target, nil)
+		mockModelDB.On("FindByCapabilities", mock.Anything, mock.Anything).Return([]*model.AIModel{original, target}, nil)
+		mockProviderDB.On("FindByID", mock.Anything, providerID).Return(provider, nil)
+		mockRegistry.On("GetForProvider", provider).Return(mockAdapter, nil)
+		mockAdapter.On("Chat", mock.Anything, mock.Anything, target, provider, provider.APIKey).
+			Return(&model.AIChatResponse{Message: &model.AIChatMessage{Role: "assistant", Content: "Hi"}}, nil)
+		mockLogDB.On("Create", mock.Anything, mock.MatchedBy(func(l *model.AIRequestLog) bool {
+			return l.ReplayOf != nil && *l.ReplayOf == logID &&
+				l.UserID == adminID &&
+				l.APIKeyID == nil &&
+				l.ModelID == "gpt-4o"
+		})).Return(nil)
+
+		result, err := domain.ReplayRequest(context.Background(), adminID, logID, "gpt-4o")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "gpt-4o", result.Response.Routing.ModelUsed)
+		assert.Equal(t, logID, *result.Replay.ReplayOf)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		domain := newTestDomain(nil, nil, nil, nil)
+
+		_, err := domain.ReplayRequest(context.Background(), uuid.New(), uuid.New(), "gpt-4")
+		assert.ErrorIs(t, err, ErrRequestLoggingDisabled)
+	})
+}
+
+// ===== Alias Tests =====
+
+type MockAliasDB struct {
+	mock.Mock
+}
+
+func (m *MockAliasDB) Create(ctx context.Context, alias *model.AIModelAlias) error {
+	args := m.Called(ctx, alias)
+	return args.Error(0)
+}
+
+func (m *MockAliasDB) FindByID(ctx context.Context, id string) (*model.AIModelAlias, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AIModelAlias), args.Error(1)
+}
+
+func (m *MockAliasDB) FindAll(ctx context.Context) ([]*model.AIModelAlias, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.AIModelAlias), args.Error(1)
+}
+
+func (m *MockAliasDB) FindEnabled(ctx context.Context) ([]*model.AIModelAlias, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.AIModelAlias), args.Error(1)
+}
+
+func (m *MockAliasDB) Update(ctx context.Context, alias *model.AIModelAlias) error {
+	args := m.Called(ctx, alias)
+	return args.Error(0)
+}
+
+func (m *MockAliasDB) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func newAliasTestDomain(aliasDB *MockAliasDB, modelDB *MockModelDB, groupDB *MockGroupDB) *aiDomain {
+	var mdb outbound.AIModelDatabasePort
+	if modelDB != nil {
+		mdb = modelDB
+	}
+	var gdb outbound.AIModelGroupDatabasePort
+	if groupDB != nil {
+		gdb = groupDB
+	}
+	return NewAIDomain(
+		nil, mdb, nil, gdb, aliasDB,
+		nil, nil, nil, nil, nil, nil, nil,
+		DefaultConfig(), zap.NewNop(),
+	).(*aiDomain)
+}
+
+func TestAIDomain_BuildRoutingContext_Alias(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	t.Run("alias to model", func(t *testing.T) {
+		mockAliasDB := new(MockAliasDB)
+		mockAliasDB.On("FindEnabled", mock.Anything).Return([]*model.AIModelAlias{
+			{ID: "fast", TargetModelID: "gpt-4o-mini", Enabled: true},
+		}, nil).Once()
+		domain := newAliasTestDomain(mockAliasDB, nil, nil)
+
+		ctx, err := domain.buildRoutingContext(context.Background(), &model.AIChatRequest{Model: "fast"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"gpt-4o-mini"}, ctx.PreferredModels)
+		assert.Equal(t, "fast", ctx.Alias.ID)
+
+		// Second lookup is served from cache
+		ctx, err = domain.buildRoutingContext(context.Background(), &model.AIChatRequest{Model: "gpt-4"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"gpt-4"}, ctx.PreferredModels)
+		assert.Nil(t, ctx.Alias)
+		mockAliasDB.AssertExpectations(t)
+	})
+
+	t.Run("alias to group", func(t *testing.T) {
+		mockAliasDB := new(MockAliasDB)
+		mockAliasDB.On("FindEnabled", mock.Anything).Return([]*model.AIModelAlias{
+			{ID: "smart", TargetGroupID: "premium", Enabled: true},
+		}, nil)
+		domain := newAliasTestDomain(mockAliasDB, nil, nil)
+
+		ctx, err := domain.buildRoutingContext(context.Background(), &model.AIChatRequest{Model: "smart"})
+		assert.NoError(t, err)
+		assert.Equal(t, "premium", ctx.GroupID)
+		assert.Empty(t, ctx.PreferredModels)
+	})
+
+	t.Run("scheduled cut-over", func(t *testing.T) {
+		alias := &model.AIModelAlias{
+			ID:                "gpt-4o-latest",
+			TargetModelID:     "gpt-4o-2024-08-06",
+			NextTargetModelID: "gpt-4o-2024-11-20",
+			CutoverAt:         &past,
+		}
+		modelID, _ := alias.Resolve(time.Now())
+		assert.Equal(t, "gpt-4o-2024-11-20", modelID)
+		assert.Nil(t, alias.Notice(time.Now()))
+
+		alias.CutoverAt = &future
+		modelID, _ = alias.Resolve(time.Now())
+		assert.Equal(t, "gpt-4o-2024-08-06", modelID)
+		notice := alias.Notice(time.Now())
+		assert.NotNil(t, notice)
+		assert.False(t, notice.Deprecated)
+	})
+
+	t.Run("deprecated alias notice", func(t *testing.T) {
+		info := &model.AIRoutingInfo{}
+		applyAliasInfo(info, &model.AIModelAlias{ID: "old", TargetModelID: "gpt-4", Deprecated: true, SunsetAt: &future})
+
+		assert.Equal(t, "old", info.Alias)
+		assert.True(t, info.Deprecation.Deprecated)
+		assert.Equal(t, &future, info.Deprecation.SunsetAt)
+	})
+}
+
+func TestAIDomain_CreateAlias(t *testing.T) {
+	t.Run("rejects alias shadowing a model", func(t *testing.T) {
+		mockAliasDB := new(MockAliasDB)
+		mockModelDB := new(MockModelDB)
+		domain := newAliasTestDomain(mockAliasDB, mockModelDB, nil)
+
+		mockModelDB.On("FindByID", mock.Anything, "gpt-4").Return(createTestModel("gpt-4", uuid.New()), nil)
+
+		err := domain.CreateAlias(context.Background(), &model.AIModelAlias{ID: "gpt-4", TargetModelID: "gpt-4o"})
+		assert.ErrorIs(t, err, ErrAliasConflict)
+	})
+
+	t.Run("requires exactly one target", func(t *testing.T) {
+		domain := newAliasTestDomain(new(MockAliasDB), new(MockModelDB), nil)
+
+		err := domain.CreateAlias(context.Background(), &model.AIModelAlias{ID: "fast", TargetModelID: "a", TargetGroupID: "b"})
+		assert.ErrorIs(t, err, ErrInvalidAliasTarget)
+	})
+
+	t.Run("creates and invalidates cache", func(t *testing.T) {
+		mockAliasDB := new(MockAliasDB)
+		mockModelDB := new(MockModelDB)
+		domain := newAliasTestDomain(mockAliasDB, mockModelDB, nil)
+		domain.aliasCache = map[string]*model.AIModelAlias{}
+		domain.aliasCachedAt = time.Now()
+
+		alias := &model.AIModelAlias{ID: "fast", TargetModelID: "gpt-4o-mini"}
+		mockModelDB.On("FindByID", mock.Anything, "fast").Return(nil, nil)
+		mockModelDB.On("FindByID", mock.Anything, "gpt-4o-mini").Return(createTestModel("gpt-4o-mini", uuid.New()), nil)
+		mockAliasDB.On("Create", mock.Anything, alias).Return(nil)
+
+		err := domain.CreateAlias(context.Background(), alias)
+		assert.NoError(t, err)
+		assert.Nil(t, domain.aliasCache)
+	})
+}
+
+// ===== Image Input Tests =====
+
+type MockImageFetcher struct {
+	mock.Mock
+}
+
+func (m *MockImageFetcher) Fetch(ctx context.Context, url string, maxBytes int64) ([]byte, string, error) {
+	args := m.Called(ctx, url, maxBytes)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).([]byte), args.String(1), args.Error(2)
+}
+
+func encodeTestPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h)))
+	assert.NoError(t, err)
+	return buf.Bytes()
+}
+
+func imageMessage(url string) []*model.AIChatMessage {
+	return []*model.AIChatMessage{{
+		Role: "user",
+		Content: []any{
+			map[string]any{"type": "text", "text": "what is this?"},
+			map[string]any{"type": "image_url", "image_url": map[string]any{"url": url}},
+		},
+	}}
+}
+
+func TestAIDomain_PrepareImages(t *testing.T) {
+	newImageDomain := func(fetcher *MockImageFetcher) *aiDomain {
+		return &aiDomain{imageFetcher: fetcher, imageMaxBytes: 1 << 20, logger: zap.NewNop()}
+	}
+	anthropic := &model.AIProvider{Type: model.AIProviderTypeAnthropic}
+
+	t.Run("remote image is inlined and downscaled", func(t *testing.T) {
+		fetcher := new(MockImageFetcher)
+		fetcher.On("Fetch", mock.Anything, "https://example.com/cat.png", int64(1<<20)).
+			Return(encodeTestPNG(t, 3136, 1568), "image/png", nil).Once()
+		domain := newImageDomain(fetcher)
+
+		original := imageMessage("https://example.com/cat.png")
+		messages, tokens, err := domain.prepareImages(context.Background(), original, anthropic)
+
+		assert.NoError(t, err)
+		parts := messages[0].Content.([]any)
+		url := parts[1].(map[string]any)["image_url"].(map[string]any)["url"].(string)
+		assert.True(t, strings.HasPrefix(url, "data:image/png;base64,"))
+
+		data, err := decodeDataURL(url)
+		assert.NoError(t, err)
+		w, h, err := imageDimensions(data, "image/png")
+		assert.NoError(t, err)
+		assert.Equal(t, 1568, w)
+		assert.Equal(t, 784, h)
+		assert.Equal(t, imageTokens(model.AIProviderTypeAnthropic, 1568, 784, ""), tokens)
+
+		// The caller's messages keep the original URL.
+		origParts := original[0].Content.([]any)
+		assert.Equal(t, "https://example.com/cat.png", origParts[1].(map[string]any)["image_url"].(map[string]any)["url"])
+		fetcher.AssertExpectations(t)
+	})
+
+	t.Run("provider option overrides max dimension", func(t *testing.T) {
+		domain := newImageDomain(new(MockImageFetcher))
+		provider := &model.AIProvider{
+			Type:    model.AIProviderTypeOpenAI,
+			Options: map[string]any{"max_image_dimension": float64(64)},
+		}
+		src := "data:image/png;base64," + base64.StdEncoding.EncodeToString(encodeTestPNG(t, 128, 32))
+
+		messages, _, err := domain.prepareImages(context.Background(), imageMessage(src), provider)
+
+		assert.NoError(t, err)
+		url := messages[0].Content.([]any)[1].(map[string]any)["image_url"].(map[string]any)["url"].(string)
+		data, _ := decodeDataURL(url)
+		w, h, _ := imageDimensions(data, "image/png")
+		assert.Equal(t, 64, w)
+		assert.Equal(t, 16, h)
+	})
+
+	t.Run("unsupported type is rejected", func(t *testing.T) {
+		fetcher := new(MockImageFetcher)
+		fetcher.On("Fetch", mock.Anything, "https://example.com/page", mock.Anything).
+			Return([]byte("<html><body>not an image</body></html>"), "image/png", nil).Once()
+		domain := newImageDomain(fetcher)
+
+		_, _, err := domain.prepareImages(context.Background(), imageMessage("https://example.com/page"), anthropic)
+
+		assert.ErrorIs(t, err, ErrUnsupportedImageType)
+	})
+
+	t.Run("fetch failure", func(t *testing.T) {
+		fetcher := new(MockImageFetcher)
+		fetcher.On("Fetch", mock.Anything, "http://10.0.0.1/x.png", mock.Anything).
+			Return(nil, "", errors.New("forbidden address")).Once()
+		domain := newImageDomain(fetcher)
+
+		_, _, err := domain.prepareImages(context.Background(), imageMessage("http://10.0.0.1/x.png"), anthropic)
+
+		assert.ErrorIs(t, err, ErrImageFetchFailed)
+	})
+
+	t.Run("text-only messages are untouched", func(t *testing.T) {
+		domain := newImageDomain(new(MockImageFetcher))
+		original := []*model.AIChatMessage{{Role: "user", Content: "hello"}}
+
+		messages, tokens, err := domain.prepareImages(context.Background(), original, anthropic)
+
+		assert.NoError(t, err)
+		assert.Equal(t, original, messages)
+		assert.Zero(t, tokens)
+	})
+}
+
+func TestImageTokens(t *testing.T) {
+	assert.Equal(t, 85, imageTokens(model.AIProviderTypeOpenAI, 4096, 4096, "low"))
+	// 1024x1024 scales to 768x768: 4 tiles.
+	assert.Equal(t, 85+170*4, imageTokens(model.AIProviderTypeOpenAI, 1024, 1024, ""))
+	assert.Equal(t, 1334, imageTokens(model.AIProviderTypeAnthropic, 1000, 1000, ""))
+}
+
+func TestAIDomain_BuildRoutingContext_Reasoning(t *testing.T) {
+	domain := newTestDomain(nil, nil, nil, nil).(*aiDomain)
+	messages := []*model.AIChatMessage{{Role: "user", Content: "Prove it"}}
+
+	t.Run("effort requires reasoning capability", func(t *testing.T) {
+		ctx, err := domain.buildRoutingContext(context.Background(), &model.AIChatRequest{
+			Messages:        messages,
+			ReasoningEffort: model.AIReasoningEffortHigh,
+		})
+		assert.NoError(t, err)
+		assert.True(t, ctx.RequireReasoning)
+		assert.Contains(t, ctx.RequiredCapabilities(), model.AICapabilityReasoning)
+	})
+
+	t.Run("thinking budget requires reasoning capability", func(t *testing.T) {
+		ctx, err := domain.buildRoutingContext(context.Background(), &model.AIChatRequest{
+			Messages: messages,
+			Thinking: &model.AIThinkingConfig{BudgetTokens: 4096},
+		})
+		assert.NoError(t, err)
+		assert.True(t, ctx.RequireReasoning)
+	})
+
+	t.Run("unknown effort", func(t *testing.T) {
+		_, err := domain.buildRoutingContext(context.Background(), &model.AIChatRequest{
+			Messages:        messages,
+			ReasoningEffort: "extreme",
+		})
+		assert.ErrorIs(t, err, ErrInvalidReasoningConfig)
+	})
+
+	t.Run("budget below minimum", func(t *testing.T) {
+		_, err := domain.buildRoutingContext(context.Background(), &model.AIChatRequest{
+			Messages: messages,
+			Thinking: &model.AIThinkingConfig{BudgetTokens: 100},
+		})
+		assert.ErrorIs(t, err, ErrInvalidReasoningConfig)
+	})
+}
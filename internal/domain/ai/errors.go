@@ -27,6 +27,11 @@ var (
 	ErrGroupDisabled        = errors.New("group is disabled")
 	ErrGroupAlreadyExists   = errors.New("group already exists")
 
+	// Alias errors
+	ErrAliasNotFound      = errors.New("alias not found")
+	ErrAliasConflict      = errors.New("alias conflicts with an existing model")
+	ErrInvalidAliasTarget = errors.New("invalid alias target: exactly one of model or group is required")
+
 	// Routing errors
 	ErrNoAvailableModels    = errors.New("no available models for routing")
 	ErrRoutingFailed        = errors.New("routing failed")
@@ -37,6 +42,19 @@ var (
 	ErrEmptyMessages        = errors.New("messages cannot be empty")
 	ErrEmptyInput           = errors.New("input cannot be empty")
 
+	// Reasoning errors
+	ErrInvalidReasoningConfig = errors.New("invalid reasoning configuration")
+
+	// Image input errors
+	ErrInvalidImage         = errors.New("invalid image")
+	ErrUnsupportedImageType = errors.New("unsupported image type")
+	ErrImageTooLarge        = errors.New("image too large")
+	ErrImageFetchFailed     = errors.New("failed to fetch image")
+
+	// Request log errors
+	ErrRequestLogNotFound     = errors.New("request log not found")
+	ErrRequestLoggingDisabled = errors.New("request logging is disabled")
+
 	// Rate limit errors
 	ErrRateLimitExceeded    = errors.New("rate limit exceeded")
 	ErrQuotaExceeded        = errors.New("quota exceeded")
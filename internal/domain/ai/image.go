@@ -0,0 +1,345 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+const (
+	// defaultMaxImageDimension applies to providers without a known limit.
+	defaultMaxImageDimension = 2048
+
+	// openAIMaxImageDimension and anthropicMaxImageDimension are the sizes
+	// above which the vendors downscale images themselves.
+	openAIMaxImageDimension    = 2048
+	anthropicMaxImageDimension = 1568
+
+	// providerOptionMaxImageDimension overrides the limit per provider.
+	providerOptionMaxImageDimension = "max_image_dimension"
+)
+
+// supportedImageTypes lists the MIME types accepted for vision input.
+var supportedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// preparedImage is a validated, provider-ready image.
+type preparedImage struct {
+	data     []byte
+	mimeType string
+	width    int
+	height   int
+}
+
+// dataURL returns the image as a base64 data URL.
+func (img *preparedImage) dataURL() string {
+	return "data:" + img.mimeType + ";base64," + base64.StdEncoding.EncodeToString(img.data)
+}
+
+// prepareImages downloads, validates and resizes every image part in messages,
+// replacing each with an inline base64 data URL sized for the provider.
+// It returns the rewritten messages and the estimated image token count.
+// The input messages are not modified.
+func (d *aiDomain) prepareImages(ctx context.Context, messages []*model.AIChatMessage, provider *model.AIProvider) ([]*model.AIChatMessage, int, error) {
+	if d.imageFetcher == nil {
+		return messages, 0, nil
+	}
+
+	maxDim := maxImageDimension(provider)
+	totalTokens := 0
+	result := messages
+	rewritten := false
+
+	for i, msg := range messages {
+		parts, ok := msg.Content.([]any)
+		if !ok || !msg.HasImages() {
+			continue
+		}
+
+		newParts := make([]any, len(parts))
+		for j, part := range parts {
+			newParts[j] = part
+
+			p, ok := part.(map[string]any)
+			if !ok || p["type"] != "image_url" {
+				continue
+			}
+
+			rawURL, detail := imagePartURL(p)
+			if rawURL == "" {
+				return nil, 0, fmt.Errorf("%w: missing image URL", ErrInvalidImage)
+			}
+
+			img, err := d.loadImage(ctx, rawURL)
+			if err != nil {
+				return nil, 0, err
+			}
+			if img, err = fitImage(img, maxDim); err != nil {
+				return nil, 0, err
+			}
+
+			totalTokens += imageTokens(provider.Type, img.width, img.height, detail)
+
+			imageURL := map[string]any{"url": img.dataURL()}
+			if detail != "" {
+				imageURL["detail"] = detail
+			}
+			newParts[j] = map[string]any{
+				"type":      "image_url",
+				"image_url": imageURL,
+			}
+		}
+
+		// Copy-on-write so the caller's request (and its audit log) keeps the original URLs.
+		if !rewritten {
+			result = make([]*model.AIChatMessage, len(messages))
+			copy(result, messages)
+			rewritten = true
+		}
+		copied := *msg
+		copied.Content = newParts
+		result[i] = &copied
+	}
+
+	return result, totalTokens, nil
+}
+
+// loadImage reads an image from a data URL or fetches it remotely, then
+// validates its type and dimensions.
+func (d *aiDomain) loadImage(ctx context.Context, rawURL string) (*preparedImage, error) {
+	var data []byte
+
+	if strings.HasPrefix(rawURL, "data:") {
+		decoded, err := decodeDataURL(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		data = decoded
+	} else {
+		fetched, _, err := d.imageFetcher.Fetch(ctx, rawURL, d.imageMaxBytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrImageFetchFailed, err)
+		}
+		data = fetched
+	}
+
+	if int64(len(data)) > d.imageMaxBytes {
+		return nil, ErrImageTooLarge
+	}
+
+	// Trust the bytes rather than the declared Content-Type.
+	mimeType := http.DetectContentType(data)
+	if !supportedImageTypes[mimeType] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedImageType, mimeType)
+	}
+
+	width, height, err := imageDimensions(data, mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	return &preparedImage{data: data, mimeType: mimeType, width: width, height: height}, nil
+}
+
+// imagePartURL extracts the URL and detail level from an image_url part.
+func imagePartURL(p map[string]any) (string, string) {
+	switch v := p["image_url"].(type) {
+	case string:
+		return v, ""
+	case map[string]any:
+		u, _ := v["url"].(string)
+		detail, _ := v["detail"].(string)
+		return u, detail
+	}
+	return "", ""
+}
+
+// decodeDataURL decodes a base64 data URL.
+func decodeDataURL(raw string) ([]byte, error) {
+	header, payload, ok := strings.Cut(strings.TrimPrefix(raw, "data:"), ",")
+	if !ok || !strings.HasSuffix(header, ";base64") {
+		return nil, fmt.Errorf("%w: data URL must be base64 encoded", ErrInvalidImage)
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+	return data, nil
+}
+
+// maxImageDimension returns the longest edge a provider accepts.
+func maxImageDimension(p *model.AIProvider) int {
+	if v, ok := p.Options[providerOptionMaxImageDimension]; ok {
+		switch n := v.(type) {
+		case float64:
+			if n > 0 {
+				return int(n)
+			}
+		case int:
+			if n > 0 {
+				return n
+			}
+		}
+	}
+
+	switch p.Type {
+	case model.AIProviderTypeAnthropic:
+		return anthropicMaxImageDimension
+	case model.AIProviderTypeOpenAI:
+		return openAIMaxImageDimension
+	default:
+		return defaultMaxImageDimension
+	}
+}
+
+// fitImage downsizes img so its longest edge is at most maxDim.
+// JPEG stays JPEG; PNG and GIF are re-encoded as PNG.
+func fitImage(img *preparedImage, maxDim int) (*preparedImage, error) {
+	if img.width <= maxDim && img.height <= maxDim {
+		return img, nil
+	}
+	if img.mimeType == "image/webp" {
+		// The standard library cannot re-encode WebP.
+		return nil, fmt.Errorf("%w: webp images must be at most %dpx", ErrImageTooLarge, maxDim)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(img.data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	scale := float64(maxDim) / float64(max(img.width, img.height))
+	w := max(1, int(math.Round(float64(img.width)*scale)))
+	h := max(1, int(math.Round(float64(img.height)*scale)))
+	dst := downscale(src, w, h)
+
+	var buf bytes.Buffer
+	mimeType := img.mimeType
+	if mimeType == "image/jpeg" {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85})
+	} else {
+		mimeType = "image/png"
+		err = png.Encode(&buf, dst)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode resized image: %w", err)
+	}
+
+	return &preparedImage{data: buf.Bytes(), mimeType: mimeType, width: w, height: h}, nil
+}
+
+// downscale resizes src to w×h with a box filter.
+func downscale(src image.Image, w, h int) *image.RGBA {
+	b := src.Bounds()
+	rgba, ok := src.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(rgba, rgba.Bounds(), src, b.Min, draw.Src)
+	}
+	sw, sh := rgba.Bounds().Dx(), rgba.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		sy0, sy1 := y*sh/h, max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			sx0, sx1 := x*sw/w, max((x+1)*sw/w, x*sw/w+1)
+
+			var r, g, bl, a, n uint32
+			for sy := sy0; sy < sy1; sy++ {
+				off := rgba.PixOffset(rgba.Bounds().Min.X+sx0, rgba.Bounds().Min.Y+sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += uint32(rgba.Pix[off])
+					g += uint32(rgba.Pix[off+1])
+					bl += uint32(rgba.Pix[off+2])
+					a += uint32(rgba.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: uint8(a / n)})
+		}
+	}
+
+	return dst
+}
+
+// imageDimensions reads width and height without decoding pixel data.
+func imageDimensions(data []byte, mimeType string) (int, int, error) {
+	switch mimeType {
+	case "image/webp":
+		return webpDimensions(data)
+	case "image/gif":
+		cfg, err := gif.DecodeConfig(bytes.NewReader(data))
+		return cfg.Width, cfg.Height, err
+	default:
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		return cfg.Width, cfg.Height, err
+	}
+}
+
+// webpDimensions parses the canvas size from a WebP header.
+func webpDimensions(data []byte) (int, int, error) {
+	if len(data) < 30 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return 0, 0, fmt.Errorf("invalid webp header")
+	}
+
+	switch string(data[12:16]) {
+	case "VP8 ":
+		w := int(binary.LittleEndian.Uint16(data[26:28]) & 0x3fff)
+		h := int(binary.LittleEndian.Uint16(data[28:30]) & 0x3fff)
+		return w, h, nil
+	case "VP8L":
+		bits := binary.LittleEndian.Uint32(data[21:25])
+		w := int(bits&0x3fff) + 1
+		h := int((bits>>14)&0x3fff) + 1
+		return w, h, nil
+	case "VP8X":
+		w := int(uint32(data[24])|uint32(data[25])<<8|uint32(data[26])<<16) + 1
+		h := int(uint32(data[27])|uint32(data[28])<<8|uint32(data[29])<<16) + 1
+		return w, h, nil
+	}
+
+	return 0, 0, fmt.Errorf("unknown webp chunk %q", data[12:16])
+}
+
+// imageTokens estimates the input tokens a provider bills for an image.
+func imageTokens(providerType model.AIProviderType, width, height int, detail string) int {
+	if providerType == model.AIProviderTypeAnthropic {
+		// Anthropic: roughly one token per 750 pixels.
+		return int(math.Ceil(float64(width*height) / 750))
+	}
+
+	// OpenAI-style tiling: 85 base tokens plus 170 per 512px tile after
+	// fitting in 2048×2048 and scaling the short side down to 768.
+	if detail == "low" {
+		return 85
+	}
+
+	w, h := float64(width), float64(height)
+	if longest := math.Max(w, h); longest > 2048 {
+		w, h = w*2048/longest, h*2048/longest
+	}
+	if shortest := math.Min(w, h); shortest > 768 {
+		w, h = w*768/shortest, h*768/shortest
+	}
+	tiles := math.Ceil(w/512) * math.Ceil(h/512)
+
+	return 85 + 170*int(tiles)
+}
@@ -0,0 +1,345 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/uniedit/server/internal/model"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultRequestLogPageSize = 20
+	requestLogPurgeInterval   = time.Hour
+)
+
+// ===== Request Logging =====
+
+// logRequest stores an encrypted audit record of a chat exchange.
+// It is a no-op unless request logging is enabled, and never fails the caller.
+func (d *aiDomain) logRequest(
+	ctx context.Context,
+	userID uuid.UUID,
+	req *model.AIChatRequest,
+	result *model.AIRoutingResult,
+	resp *model.AIChatResponse,
+	reqErr error,
+	latencyMs int64,
+	costUSD float64,
+	replayOf *uuid.UUID,
+) *model.AIRequestLog {
+	if !d.requestLogEnabled {
+		return nil
+	}
+
+	now := time.Now()
+	entry := &model.AIRequestLog{
+		ID:        uuid.New(),
+		RequestID: req.RequestID,
+		UserID:    userID,
+		APIKeyID:  req.APIKeyID,
+		Stream:    req.Stream,
+		Success:   reqErr == nil,
+		LatencyMs: latencyMs,
+		CostUSD:   costUSD,
+		ReplayOf:  replayOf,
+		CreatedAt: now,
+		ExpiresAt: now.Add(d.requestLogRetention),
+	}
+	if result != nil {
+		entry.ModelID = result.Model.ID
+		providerID := result.Provider.ID
+		entry.ProviderID = &providerID
+	}
+	if reqErr != nil {
+		entry.Error = reqErr.Error()
+	}
+	if resp != nil && resp.Usage != nil {
+		entry.PromptTokens = resp.Usage.PromptTokens
+		entry.CompletionTokens = resp.Usage.CompletionTokens
+	}
+
+	encReq, err := d.encryptJSON(req)
+	if err != nil {
+		d.logger.Warn("failed to encrypt request log body", zap.Error(err))
+		return nil
+	}
+	entry.EncryptedRequest = encReq
+
+	if resp != nil {
+		encResp, err := d.encryptJSON(resp)
+		if err != nil {
+			d.logger.Warn("failed to encrypt response log body", zap.Error(err))
+			return nil
+		}
+		entry.EncryptedResponse = encResp
+	}
+
+	// Persist even if the client has already gone away.
+	if err := d.requestLogDB.Create(context.WithoutCancel(ctx), entry); err != nil {
+		d.logger.Warn("failed to store request log",
+			zap.String("request_id", entry.RequestID),
+			zap.Error(err))
+		return nil
+	}
+
+	entry.Request = req
+	entry.Response = resp
+	return entry
+}
+
+// trackStream forwards chunks to the caller and, once the upstream channel
+// closes, logs the assembled response and records its usage. Usage and cost
+// come from the final chunk; imageTokens is the estimated share of the prompt
+// spent on images, as in Chat.
+func (d *aiDomain) trackStream(
+	ctx context.Context,
+	userID uuid.UUID,
+	req *model.AIChatRequest,
+	result *model.AIRoutingResult,
+	chunks <-chan *model.AIChatChunk,
+	imageTokens int,
+	startTime time.Time,
+) <-chan *model.AIChatChunk {
+	out := make(chan *model.AIChatChunk)
+
+	go func() {
+		defer close(out)
+
+		var content, reasoning strings.Builder
+		resp := &model.AIChatResponse{Model: result.Model.ID}
+
+		for chunk := range chunks {
+			if chunk.ID != "" {
+				resp.ID = chunk.ID
+			}
+			if chunk.Delta != nil {
+				content.WriteString(chunk.Delta.Content)
+				reasoning.WriteString(chunk.Delta.Reasoning)
+			}
+			if chunk.FinishReason != "" {
+				resp.FinishReason = chunk.FinishReason
+			}
+			if chunk.Usage != nil {
+				chunk.Usage.ImageTokens = imageTokens
+				resp.Usage = chunk.Usage
+			}
+
+			select {
+			case out <- chunk:
+			case <-ctx.Done():
+				// Drain upstream so the adapter goroutine can exit.
+				for range chunks {
+				}
+				d.finishStream(ctx, userID, req, result, resp, ctx.Err(), startTime)
+				return
+			}
+		}
+
+		resp.Message = &model.AIChatMessage{Role: "assistant", Content: content.String()}
+		resp.Reasoning = reasoning.String()
+		d.finishStream(ctx, userID, req, result, resp, nil, startTime)
+	}()
+
+	return out
+}
+
+// finishStream logs a streamed request and records its usage for billing.
+// The provider charges for what it generated, so a stream the client
+// abandoned is still recorded.
+func (d *aiDomain) finishStream(
+	ctx context.Context,
+	userID uuid.UUID,
+	req *model.AIChatRequest,
+	result *model.AIRoutingResult,
+	resp *model.AIChatResponse,
+	streamErr error,
+	startTime time.Time,
+) {
+	costUSD := d.calculateCost(result.Model, resp.Usage)
+	d.logRequest(ctx, userID, req, result, resp, streamErr, time.Since(startTime).Milliseconds(), costUSD, nil)
+
+	if d.usageRecorder != nil && resp.Usage != nil {
+		_ = d.usageRecorder.RecordUsage(context.WithoutCancel(ctx), userID, result.Model.ID, resp.Usage, costUSD)
+	}
+}
+
+// encryptJSON marshals v and encrypts it with the crypto port.
+func (d *aiDomain) encryptJSON(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("marshal: %w", err)
+	}
+	return d.crypto.Encrypt(string(data))
+}
+
+// decryptJSON decrypts ciphertext and unmarshals it into v.
+func (d *aiDomain) decryptJSON(ciphertext string, v any) error {
+	plain, err := d.crypto.Decrypt(ciphertext)
+	if err != nil {
+		return fmt.Errorf("decrypt: %w", err)
+	}
+	return json.Unmarshal([]byte(plain), v)
+}
+
+// ===== Request Log Queries =====
+
+// SearchRequestLogs searches request log metadata. Bodies are not decrypted.
+func (d *aiDomain) SearchRequestLogs(ctx context.Context, filter *model.AIRequestLogFilter) ([]*model.AIRequestLog, int64, error) {
+	if d.requestLogDB == nil {
+		return nil, 0, ErrRequestLoggingDisabled
+	}
+	if filter == nil {
+		filter = &model.AIRequestLogFilter{}
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultRequestLogPageSize
+	}
+	return d.requestLogDB.Find(ctx, filter)
+}
+
+// GetRequestLog returns a request log with decrypted bodies.
+func (d *aiDomain) GetRequestLog(ctx context.Context, id uuid.UUID) (*model.AIRequestLog, error) {
+	if d.requestLogDB == nil || d.crypto == nil {
+		return nil, ErrRequestLoggingDisabled
+	}
+
+	entry, err := d.requestLogDB.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, ErrRequestLogNotFound
+	}
+
+	var req model.AIChatRequest
+	if err := d.decryptJSON(entry.EncryptedRequest, &req); err != nil {
+		return nil, fmt.Errorf("read request body: %w", err)
+	}
+	entry.Request = &req
+
+	if entry.EncryptedResponse != "" {
+		var resp model.AIChatResponse
+		if err := d.decryptJSON(entry.EncryptedResponse, &resp); err != nil {
+			return nil, fmt.Errorf("read response body: %w", err)
+		}
+		entry.Response = &resp
+	}
+
+	return entry, nil
+}
+
+// ReplayRequest re-runs a logged request on behalf of adminID through
+// routing, pinned to modelID (or the originally used model if empty).
+// Replays are logged under the admin with a reference to the original, but
+// are neither billed nor counted towards provider health.
+func (d *aiDomain) ReplayRequest(ctx context.Context, adminID, id uuid.UUID, modelID string) (*model.AIReplayResult, error) {
+	if !d.requestLogEnabled {
+		return nil, ErrRequestLoggingDisabled
+	}
+
+	original, err := d.GetRequestLog(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if modelID == "" {
+		modelID = original.ModelID
+	}
+	target, err := d.modelDB.FindByID(ctx, modelID)
+	if err != nil {
+		return nil, err
+	}
+	if target == nil || !target.Enabled {
+		return nil, ErrModelNotFound
+	}
+
+	req := *original.Request
+	req.Model = modelID
+	req.Stream = false
+	req.APIKeyID = nil
+	req.RequestID = original.RequestID
+
+	routingCtx, err := d.buildRoutingContext(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	result, err := d.Route(ctx, routingCtx)
+	if err != nil {
+		return nil, fmt.Errorf("routing failed: %w", err)
+	}
+	// The preference strategy falls back to any candidate; a replay must not.
+	if result.Model.ID != modelID {
+		return nil, ErrNoAvailableModels
+	}
+
+	adapter, err := d.vendorRegistry.GetForProvider(result.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("get adapter: %w", err)
+	}
+
+	adapterReq := req
+	if adapterReq.Messages, _, err = d.prepareImages(ctx, req.Messages, result.Provider); err != nil {
+		return nil, err
+	}
+
+	startTime := time.Now()
+	resp, chatErr := adapter.Chat(ctx, &adapterReq, result.Model, result.Provider, result.APIKey)
+	latencyMs := time.Since(startTime).Milliseconds()
+
+	var costUSD float64
+	if chatErr == nil {
+		costUSD = d.calculateCost(result.Model, resp.Usage)
+		resp.Routing = &model.AIRoutingInfo{
+			ProviderUsed: result.Provider.Name,
+			ModelUsed:    result.Model.ID,
+			LatencyMs:    latencyMs,
+			CostUSD:      costUSD,
+		}
+	}
+
+	replay := d.logRequest(ctx, adminID, &req, result, resp, chatErr, latencyMs, costUSD, &original.ID)
+	if chatErr != nil {
+		return nil, fmt.Errorf("replay failed: %w", chatErr)
+	}
+
+	return &model.AIReplayResult{
+		Original: original,
+		Replay:   replay,
+		Response: resp,
+	}, nil
+}
+
+// PurgeExpiredRequestLogs deletes request logs past their retention period.
+func (d *aiDomain) PurgeExpiredRequestLogs(ctx context.Context) (int64, error) {
+	if d.requestLogDB == nil {
+		return 0, nil
+	}
+	return d.requestLogDB.DeleteExpired(ctx, time.Now())
+}
+
+// runRequestLogPurge periodically purges expired request logs.
+func (d *aiDomain) runRequestLogPurge(ctx context.Context) {
+	ticker := time.NewTicker(requestLogPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := d.PurgeExpiredRequestLogs(ctx)
+			if err != nil {
+				d.logger.Error("failed to purge request logs", zap.Error(err))
+				continue
+			}
+			if deleted > 0 {
+				d.logger.Info("purged expired request logs", zap.Int64("count", deleted))
+			}
+		}
+	}
+}
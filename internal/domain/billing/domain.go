@@ -26,6 +26,10 @@ type BillingDomain interface {
 	GetQuotaStatus(ctx context.Context, userID uuid.UUID) (*model.QuotaStatus, error)
 	CheckQuota(ctx context.Context, userID uuid.UUID, taskType string) error
 	ConsumeQuota(ctx context.Context, userID uuid.UUID, tokens int) error
+	GetVideoSecondsRemaining(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetMediaStorageLimit(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetGitStorageLimit(ctx context.Context, userID uuid.UUID) (int64, error)
+	GetLFSStorageLimit(ctx context.Context, userID uuid.UUID) (int64, error)
 
 	// Usage operations
 	GetUsageStats(ctx context.Context, userID uuid.UUID, period string, start, end *time.Time) (*model.UsageStats, error)
@@ -48,9 +52,13 @@ type RecordUsageInput struct {
 	ModelID      string
 	InputTokens  int
 	OutputTokens int
-	CostUSD      float64
-	LatencyMs    int
-	Success      bool
+	// Prompt cache breakdown of InputTokens
+	CacheReadTokens  int
+	CacheWriteTokens int
+	VideoSeconds     int
+	CostUSD          float64
+	LatencyMs        int
+	Success          bool
 }
 
 // billingDomain implements BillingDomain.
@@ -297,6 +305,100 @@ func (d *billingDomain) ConsumeQuota(ctx context.Context, userID uuid.UUID, toke
 	return nil
 }
 
+// GetVideoSecondsRemaining returns the video seconds left in the current
+// period, or -1 if the plan has unlimited video minutes.
+func (d *billingDomain) GetVideoSecondsRemaining(ctx context.Context, userID uuid.UUID) (int64, error) {
+	sub, err := d.subscriptionDB.GetByUserIDWithPlan(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if sub == nil || !sub.IsActive() {
+		return 0, nil
+	}
+
+	plan := sub.Plan
+	if plan == nil {
+		return 0, fmt.Errorf("subscription has no plan loaded")
+	}
+	if plan.IsUnlimitedVideoMinutes() {
+		return -1, nil
+	}
+
+	used, err := d.usageDB.GetVideoSeconds(ctx, userID, sub.CurrentPeriodStart)
+	if err != nil {
+		return 0, err
+	}
+
+	remaining := int64(plan.MonthlyVideoMinutes)*60 - used
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, nil
+}
+
+// GetMediaStorageLimit returns the plan's media storage allowance in bytes,
+// or -1 if unlimited. Users without an active subscription get none.
+func (d *billingDomain) GetMediaStorageLimit(ctx context.Context, userID uuid.UUID) (int64, error) {
+	sub, err := d.subscriptionDB.GetByUserIDWithPlan(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if sub == nil || !sub.IsActive() {
+		return 0, nil
+	}
+	if sub.Plan == nil {
+		return 0, fmt.Errorf("subscription has no plan loaded")
+	}
+	if sub.Plan.IsUnlimitedMediaStorage() {
+		return -1, nil
+	}
+	return sub.Plan.MediaStorageMB * 1024 * 1024, nil
+}
+
+// GetGitStorageLimit returns the plan's git storage allowance in bytes, or
+// -1 if unlimited.
+func (d *billingDomain) GetGitStorageLimit(ctx context.Context, userID uuid.UUID) (int64, error) {
+	plan, err := d.storagePlan(ctx, userID)
+	if err != nil || plan == nil {
+		return -1, err
+	}
+	if plan.IsUnlimitedGitStorage() {
+		return -1, nil
+	}
+	return plan.GitStorageMB * 1024 * 1024, nil
+}
+
+// GetLFSStorageLimit returns the plan's LFS storage allowance in bytes, or
+// -1 if unlimited.
+func (d *billingDomain) GetLFSStorageLimit(ctx context.Context, userID uuid.UUID) (int64, error) {
+	plan, err := d.storagePlan(ctx, userID)
+	if err != nil || plan == nil {
+		return -1, err
+	}
+	if plan.IsUnlimitedLFSStorage() {
+		return -1, nil
+	}
+	return plan.LFSStorageMB * 1024 * 1024, nil
+}
+
+// storagePlan returns the plan whose repository storage allowances apply to
+// a user. Repositories outlive subscriptions, so users without an active
+// subscription fall back to the free plan rather than losing all storage;
+// nil means no plan applies.
+func (d *billingDomain) storagePlan(ctx context.Context, userID uuid.UUID) (*model.Plan, error) {
+	sub, err := d.subscriptionDB.GetByUserIDWithPlan(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub != nil && sub.IsActive() {
+		if sub.Plan == nil {
+			return nil, fmt.Errorf("subscription has no plan loaded")
+		}
+		return sub.Plan, nil
+	}
+	return d.planDB.GetByID(ctx, string(model.PlanTypeFree))
+}
+
 // --- Usage Operations ---
 
 func (d *billingDomain) GetUsageStats(ctx context.Context, userID uuid.UUID, period string, start, end *time.Time) (*model.UsageStats, error) {
@@ -328,18 +430,22 @@ func (d *billingDomain) GetUsageStats(ctx context.Context, userID uuid.UUID, per
 
 func (d *billingDomain) RecordUsage(ctx context.Context, userID uuid.UUID, input *RecordUsageInput) error {
 	record := &model.UsageRecord{
-		UserID:       userID,
-		Timestamp:    time.Now(),
-		RequestID:    input.RequestID,
-		TaskType:     input.TaskType,
-		ProviderID:   input.ProviderID,
-		ModelID:      input.ModelID,
-		InputTokens:  input.InputTokens,
-		OutputTokens: input.OutputTokens,
-		TotalTokens:  input.InputTokens + input.OutputTokens,
-		CostUSD:      input.CostUSD,
-		LatencyMs:    input.LatencyMs,
-		Success:      input.Success,
+		UserID:           userID,
+		Timestamp:        time.Now(),
+		RequestID:        input.RequestID,
+		TaskType:         input.TaskType,
+		ProviderID:       input.ProviderID,
+		ModelID:          input.ModelID,
+		InputTokens:      input.InputTokens,
+		OutputTokens:     input.OutputTokens,
+		TotalTokens:      input.InputTokens + input.OutputTokens,
+		CacheReadTokens:  input.CacheReadTokens,
+		CacheWriteTokens: input.CacheWriteTokens,
+		VideoSeconds:     input.VideoSeconds,
+		CostUSD:          input.CostUSD,
+		LatencyMs:        input.LatencyMs,
+		Success:          input.Success,
+		CacheHit:         input.CacheReadTokens > 0,
 	}
 
 	if err := d.usageDB.Create(ctx, record); err != nil {
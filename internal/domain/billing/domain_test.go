@@ -114,6 +114,11 @@ func (m *MockUsageDB) GetDailyRequests(ctx context.Context, userID uuid.UUID, da
 	return args.Get(0).(int), args.Error(1)
 }
 
+func (m *MockUsageDB) GetVideoSeconds(ctx context.Context, userID uuid.UUID, start time.Time) (int64, error) {
+	args := m.Called(ctx, userID, start)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 type MockQuotaCache struct {
 	mock.Mock
 }
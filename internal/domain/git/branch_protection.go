@@ -0,0 +1,294 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// maxBranchPatternLength bounds the length of a branch protection pattern.
+const maxBranchPatternLength = 255
+
+// ===== Branch Protection Rules =====
+
+// CreateBranchProtection adds a protection rule to a repository. Only
+// admins can manage rules.
+func (d *Domain) CreateBranchProtection(ctx context.Context, repoID, userID uuid.UUID, input *inbound.GitBranchProtectionInput) (*model.GitBranchProtection, error) {
+	if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionAdmin); err != nil {
+		return nil, err
+	}
+
+	rule := &model.GitBranchProtection{
+		ID:     uuid.New(),
+		RepoID: repoID,
+	}
+	if err := applyProtectionInput(rule, input); err != nil {
+		return nil, err
+	}
+	if err := d.checkPatternUnique(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := d.protectionDB.Create(ctx, rule); err != nil {
+		return nil, fmt.Errorf("create branch protection: %w", err)
+	}
+
+	d.logger.Info("branch protection created",
+		zap.String("repo_id", repoID.String()),
+		zap.String("pattern", rule.Pattern),
+	)
+
+	return rule, nil
+}
+
+// ListBranchProtections lists the protection rules of a repository.
+func (d *Domain) ListBranchProtections(ctx context.Context, repoID, userID uuid.UUID) ([]*model.GitBranchProtection, error) {
+	if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionRead); err != nil {
+		return nil, err
+	}
+
+	return d.protectionDB.FindByRepo(ctx, repoID)
+}
+
+// UpdateBranchProtection replaces the settings of a protection rule.
+func (d *Domain) UpdateBranchProtection(ctx context.Context, repoID, ruleID, userID uuid.UUID, input *inbound.GitBranchProtectionInput) (*model.GitBranchProtection, error) {
+	rule, err := d.findBranchProtection(ctx, repoID, ruleID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyProtectionInput(rule, input); err != nil {
+		return nil, err
+	}
+	if err := d.checkPatternUnique(ctx, rule); err != nil {
+		return nil, err
+	}
+
+	if err := d.protectionDB.Update(ctx, rule); err != nil {
+		return nil, fmt.Errorf("update branch protection: %w", err)
+	}
+
+	return rule, nil
+}
+
+// DeleteBranchProtection removes a protection rule.
+func (d *Domain) DeleteBranchProtection(ctx context.Context, repoID, ruleID, userID uuid.UUID) error {
+	rule, err := d.findBranchProtection(ctx, repoID, ruleID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := d.protectionDB.Delete(ctx, rule.ID); err != nil {
+		return fmt.Errorf("delete branch protection: %w", err)
+	}
+
+	d.logger.Info("branch protection deleted",
+		zap.String("repo_id", repoID.String()),
+		zap.String("pattern", rule.Pattern),
+	)
+
+	return nil
+}
+
+// findBranchProtection returns a rule of a repository the user administers.
+func (d *Domain) findBranchProtection(ctx context.Context, repoID, ruleID, userID uuid.UUID) (*model.GitBranchProtection, error) {
+	if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionAdmin); err != nil {
+		return nil, err
+	}
+
+	rule, err := d.protectionDB.FindByID(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil || rule.RepoID != repoID {
+		return nil, ErrBranchProtectionNotFound
+	}
+	return rule, nil
+}
+
+// checkPatternUnique ensures no other rule of the repository has the same
+// pattern.
+func (d *Domain) checkPatternUnique(ctx context.Context, rule *model.GitBranchProtection) error {
+	rules, err := d.protectionDB.FindByRepo(ctx, rule.RepoID)
+	if err != nil {
+		return fmt.Errorf("list branch protections: %w", err)
+	}
+	for _, r := range rules {
+		if r.Pattern == rule.Pattern && r.ID != rule.ID {
+			return ErrBranchProtectionExists
+		}
+	}
+	return nil
+}
+
+func applyProtectionInput(rule *model.GitBranchProtection, input *inbound.GitBranchProtectionInput) error {
+	if input == nil {
+		return ErrInvalidBranchPattern
+	}
+
+	pattern := strings.TrimSpace(input.Pattern)
+	if !validBranchPattern(pattern) {
+		return ErrInvalidBranchPattern
+	}
+	if input.RequiredApprovals < 0 || input.RequiredApprovals > maxRequiredApprovals {
+		return ErrInvalidRequiredApprovals
+	}
+
+	rule.Pattern = pattern
+	rule.BlockForcePush = input.BlockForcePush
+	rule.BlockDeletion = input.BlockDeletion
+	rule.RequirePullRequest = input.RequirePullRequest || input.RequiredApprovals > 0
+	rule.RequiredApprovals = input.RequiredApprovals
+	rule.RestrictPushToAdmins = input.RestrictPushToAdmins
+	return nil
+}
+
+// validBranchPattern reports whether p is a branch name or a glob over
+// branch names. Patterns match the short name, so refs/ prefixes are
+// rejected.
+func validBranchPattern(p string) bool {
+	if p == "" || len(p) > maxBranchPatternLength || strings.HasPrefix(p, "refs/") {
+		return false
+	}
+	_, err := path.Match(p, "")
+	return err == nil
+}
+
+// ===== Enforcement =====
+
+// CheckPush reports whether the protection rules of a repository let the
+// user apply one ref update of a push. Only branches can be protected.
+// Creating a protected branch is allowed unless pushes to it are restricted
+// to admins. The pushed objects must already be stored, since telling a
+// force-push apart needs the new commit.
+func (d *Domain) CheckPush(ctx context.Context, repoID, userID uuid.UUID, update *inbound.GitRefUpdate) error {
+	name := plumbing.ReferenceName(update.Ref)
+	if !name.IsBranch() {
+		return nil
+	}
+
+	rule, err := d.branchRule(ctx, repoID, name.Short())
+	if err != nil || rule == nil {
+		return err
+	}
+	if err := d.checkRestrictedPush(ctx, repoID, userID, rule); err != nil {
+		return err
+	}
+
+	old, new := plumbing.NewHash(update.OldSHA), plumbing.NewHash(update.NewSHA)
+	switch {
+	case new.IsZero():
+		if rule.BlockDeletion {
+			return ErrProtectedBranchDeletion
+		}
+	case old.IsZero():
+	case rule.RequirePullRequest:
+		return ErrPullRequestRequired
+	case rule.BlockForcePush:
+		ff, err := d.isFastForward(ctx, repoID, old, new)
+		if err != nil {
+			return err
+		}
+		if !ff {
+			return ErrProtectedBranchForcePush
+		}
+	}
+	return nil
+}
+
+// branchRule returns the protection that applies to a branch, combining the
+// strictest setting of every rule whose pattern matches it, or nil if the
+// branch is unprotected.
+func (d *Domain) branchRule(ctx context.Context, repoID uuid.UUID, branch string) (*model.GitBranchProtection, error) {
+	rules, err := d.protectionDB.FindByRepo(ctx, repoID)
+	if err != nil {
+		return nil, fmt.Errorf("list branch protections: %w", err)
+	}
+
+	var combined *model.GitBranchProtection
+	for _, r := range rules {
+		if !r.Matches(branch) {
+			continue
+		}
+		if combined == nil {
+			combined = &model.GitBranchProtection{RepoID: repoID, Pattern: branch}
+		}
+		combined.BlockForcePush = combined.BlockForcePush || r.BlockForcePush
+		combined.BlockDeletion = combined.BlockDeletion || r.BlockDeletion
+		combined.RequirePullRequest = combined.RequirePullRequest || r.RequirePullRequest || r.RequiredApprovals > 0
+		combined.RestrictPushToAdmins = combined.RestrictPushToAdmins || r.RestrictPushToAdmins
+		combined.RequiredApprovals = max(combined.RequiredApprovals, r.RequiredApprovals)
+	}
+	return combined, nil
+}
+
+// checkRestrictedPush ensures the user may move a branch whose pushes the
+// rule restricts to admins.
+func (d *Domain) checkRestrictedPush(ctx context.Context, repoID, userID uuid.UUID, rule *model.GitBranchProtection) error {
+	if rule == nil || !rule.RestrictPushToAdmins {
+		return nil
+	}
+
+	ok, err := d.CanAccess(ctx, repoID, &userID, model.GitPermissionAdmin)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrProtectedBranchRestricted
+	}
+	return nil
+}
+
+// checkDirectPush ensures a branch may be moved outside a pull request:
+// the commit API and pushes both land changes directly.
+func (d *Domain) checkDirectPush(ctx context.Context, repoID, userID uuid.UUID, branch string, creating bool) error {
+	rule, err := d.branchRule(ctx, repoID, branch)
+	if err != nil || rule == nil {
+		return err
+	}
+	if err := d.checkRestrictedPush(ctx, repoID, userID, rule); err != nil {
+		return err
+	}
+	if rule.RequirePullRequest && !creating {
+		return ErrPullRequestRequired
+	}
+	return nil
+}
+
+// isFastForward reports whether new descends from old.
+func (d *Domain) isFastForward(ctx context.Context, repoID uuid.UUID, old, new plumbing.Hash) (bool, error) {
+	fs, err := d.GetFilesystem(ctx, repoID)
+	if err != nil {
+		return false, err
+	}
+	st := openObjectStorage(fs)
+
+	oldCommit, err := object.GetCommit(st, old)
+	if err != nil {
+		return false, fmt.Errorf("read commit %s: %w", old, err)
+	}
+	newCommit, err := object.GetCommit(st, new)
+	if err != nil {
+		// Pointing a branch at anything but a commit isn't a fast-forward
+		return false, nil
+	}
+	return oldCommit.IsAncestor(newCommit)
+}
+
+// requiredApprovals returns the approvals a pull request into a branch
+// needs: the larger of the repository's and the branch protection's.
+func requiredApprovals(repo *model.GitRepo, rule *model.GitBranchProtection) int {
+	if rule == nil {
+		return repo.RequiredApprovals
+	}
+	return max(repo.RequiredApprovals, rule.RequiredApprovals)
+}
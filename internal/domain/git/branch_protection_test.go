@@ -0,0 +1,230 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+func TestDomain_CreateBranchProtection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newTestRepo(t))
+		deps.protectionDB.On("FindByRepo", mock.Anything, deps.repo.ID).Return(nil, nil)
+		deps.protectionDB.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		rule, err := deps.domain.CreateBranchProtection(ctx, deps.repo.ID, deps.repo.OwnerID, &inbound.GitBranchProtectionInput{
+			Pattern:           " release/* ",
+			BlockForcePush:    true,
+			RequiredApprovals: 2,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "release/*", rule.Pattern)
+		assert.Equal(t, deps.repo.ID, rule.RepoID)
+		assert.True(t, rule.BlockForcePush)
+		assert.True(t, rule.RequirePullRequest, "approvals imply a pull request")
+		assert.Equal(t, 2, rule.RequiredApprovals)
+	})
+
+	t.Run("admin only", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newTestRepo(t))
+
+		_, err := deps.domain.CreateBranchProtection(ctx, deps.repo.ID, deps.reviewer, &inbound.GitBranchProtectionInput{Pattern: "main"})
+
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newTestRepo(t))
+
+		tests := []struct {
+			name  string
+			input *inbound.GitBranchProtectionInput
+			err   error
+		}{
+			{"empty pattern", &inbound.GitBranchProtectionInput{Pattern: " "}, ErrInvalidBranchPattern},
+			{"malformed glob", &inbound.GitBranchProtectionInput{Pattern: "release/[1"}, ErrInvalidBranchPattern},
+			{"full ref name", &inbound.GitBranchProtectionInput{Pattern: "refs/heads/main"}, ErrInvalidBranchPattern},
+			{"too many approvals", &inbound.GitBranchProtectionInput{Pattern: "main", RequiredApprovals: 11}, ErrInvalidRequiredApprovals},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := deps.domain.CreateBranchProtection(ctx, deps.repo.ID, deps.repo.OwnerID, tt.input)
+				assert.ErrorIs(t, err, tt.err)
+			})
+		}
+	})
+
+	t.Run("duplicate pattern", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newTestRepo(t))
+		deps.protectionDB.On("FindByRepo", mock.Anything, deps.repo.ID).Return([]*model.GitBranchProtection{
+			{ID: uuid.New(), RepoID: deps.repo.ID, Pattern: "main"},
+		}, nil)
+
+		_, err := deps.domain.CreateBranchProtection(ctx, deps.repo.ID, deps.repo.OwnerID, &inbound.GitBranchProtectionInput{Pattern: "main"})
+
+		assert.ErrorIs(t, err, ErrBranchProtectionExists)
+	})
+}
+
+func TestDomain_UpdateBranchProtection_OtherRepo(t *testing.T) {
+	deps := newReviewTestDomain(t, newTestRepo(t))
+	rule := &model.GitBranchProtection{ID: uuid.New(), RepoID: uuid.New(), Pattern: "main"}
+	deps.protectionDB.On("FindByID", mock.Anything, rule.ID).Return(rule, nil)
+
+	_, err := deps.domain.UpdateBranchProtection(context.Background(), deps.repo.ID, rule.ID, deps.repo.OwnerID, &inbound.GitBranchProtectionInput{Pattern: "main"})
+
+	assert.ErrorIs(t, err, ErrBranchProtectionNotFound)
+}
+
+func TestDomain_BranchRule(t *testing.T) {
+	deps := newReviewTestDomain(t, newTestRepo(t))
+	deps.protectionDB.On("FindByRepo", mock.Anything, deps.repo.ID).Return([]*model.GitBranchProtection{
+		{Pattern: "release/*", BlockForcePush: true, RequiredApprovals: 1},
+		{Pattern: "release/2.*", BlockDeletion: true, RequiredApprovals: 2},
+		{Pattern: "main", RestrictPushToAdmins: true},
+	}, nil)
+	ctx := context.Background()
+
+	rule, err := deps.domain.branchRule(ctx, deps.repo.ID, "release/2.0")
+	require.NoError(t, err)
+	require.NotNil(t, rule)
+	assert.True(t, rule.BlockForcePush)
+	assert.True(t, rule.BlockDeletion)
+	assert.True(t, rule.RequirePullRequest)
+	assert.False(t, rule.RestrictPushToAdmins)
+	assert.Equal(t, 2, rule.RequiredApprovals)
+
+	rule, err = deps.domain.branchRule(ctx, deps.repo.ID, "release/1.0/hotfix")
+	require.NoError(t, err)
+	assert.Nil(t, rule, "* doesn't match across slashes")
+
+	rule, err = deps.domain.branchRule(ctx, deps.repo.ID, "feature")
+	require.NoError(t, err)
+	assert.Nil(t, rule)
+}
+
+func TestDomain_CheckPush(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	base := repo.commit("main", map[string]string{"a": "1"})
+	next := repo.commit("main", map[string]string{"a": "2"}, base)
+	rewritten := repo.commit("rewrite", map[string]string{"a": "3"}, base)
+
+	deps := newReviewTestDomain(t, repo)
+	deps.protectionDB.On("FindByRepo", mock.Anything, deps.repo.ID).Return([]*model.GitBranchProtection{
+		{Pattern: "main", BlockForcePush: true, BlockDeletion: true},
+		{Pattern: "release/*", RestrictPushToAdmins: true},
+		{Pattern: "stable", RequirePullRequest: true},
+	}, nil)
+
+	zero := plumbing.ZeroHash
+	tests := []struct {
+		name     string
+		user     uuid.UUID
+		ref      string
+		old, new plumbing.Hash
+		err      error
+	}{
+		{"fast-forward", deps.reviewer, "refs/heads/main", base, next, nil},
+		{"force-push", deps.reviewer, "refs/heads/main", next, rewritten, ErrProtectedBranchForcePush},
+		{"force-push by admin", deps.repo.OwnerID, "refs/heads/main", next, rewritten, ErrProtectedBranchForcePush},
+		{"deletion", deps.reviewer, "refs/heads/main", next, zero, ErrProtectedBranchDeletion},
+		{"restricted", deps.reviewer, "refs/heads/release/1.0", zero, next, ErrProtectedBranchRestricted},
+		{"restricted by admin", deps.repo.OwnerID, "refs/heads/release/1.0", zero, next, nil},
+		{"pull request required", deps.reviewer, "refs/heads/stable", base, next, ErrPullRequestRequired},
+		{"creation with pull request required", deps.reviewer, "refs/heads/stable", zero, next, nil},
+		{"unprotected branch", deps.reviewer, "refs/heads/feature", next, rewritten, nil},
+		{"tag", deps.reviewer, "refs/tags/main", next, zero, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := deps.domain.CheckPush(ctx, deps.repo.ID, tt.user, &inbound.GitRefUpdate{
+				Ref:    tt.ref,
+				OldSHA: tt.old.String(),
+				NewSHA: tt.new.String(),
+			})
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDomain_CommitFiles_ProtectedBranch(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("main", map[string]string{"a": "1"})
+
+	deps := newReviewTestDomain(t, repo)
+	deps.repo.DefaultBranch = "main"
+	deps.protectionDB.On("FindByRepo", mock.Anything, deps.repo.ID).Return([]*model.GitBranchProtection{
+		{Pattern: "main", RequirePullRequest: true},
+	}, nil)
+
+	_, err := deps.domain.CommitFiles(context.Background(), deps.repo.ID, deps.reviewer, &inbound.GitCommitFilesInput{
+		Message:    "Update a",
+		Operations: []*inbound.GitFileOperation{{Action: model.GitFileActionUpdate, Path: "a", Content: []byte("2")}},
+	})
+
+	assert.ErrorIs(t, err, ErrPullRequestRequired)
+}
+
+func TestDomain_MergePR_ProtectedBranch(t *testing.T) {
+	ctx := context.Background()
+
+	newDeps := func(t *testing.T, rule *model.GitBranchProtection) *reviewTestDeps {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a": "1"})
+		repo.commit("feature", map[string]string{"a": "2"}, base)
+
+		deps := newReviewTestDomain(t, repo)
+		deps.protectionDB.On("FindByRepo", mock.Anything, deps.repo.ID).Return([]*model.GitBranchProtection{rule}, nil)
+		return deps
+	}
+
+	t.Run("restricted to admins", func(t *testing.T) {
+		deps := newDeps(t, &model.GitBranchProtection{Pattern: "main", RestrictPushToAdmins: true})
+
+		_, err := deps.domain.MergePR(ctx, deps.repo.ID, 1, deps.reviewer, nil)
+
+		assert.ErrorIs(t, err, ErrProtectedBranchRestricted)
+	})
+
+	t.Run("rule requires approvals", func(t *testing.T) {
+		deps := newDeps(t, &model.GitBranchProtection{Pattern: "main", RequirePullRequest: true, RequiredApprovals: 2})
+		deps.reviewDB.On("FindReviews", mock.Anything, deps.pr.ID).Return([]*model.GitPRReview{
+			{ReviewerID: deps.reviewer, State: model.GitPRReviewStateApproved, CommitSHA: deps.head},
+		}, nil)
+
+		_, err := deps.domain.MergePR(ctx, deps.repo.ID, 1, deps.repo.OwnerID, nil)
+		assert.ErrorIs(t, err, ErrApprovalsRequired)
+
+		result, err := deps.domain.CheckMergeability(ctx, deps.repo.ID, 1, deps.reviewer)
+		require.NoError(t, err)
+		assert.False(t, result.Mergeable)
+		assert.Equal(t, 1, result.Approvals)
+		assert.Equal(t, 2, result.RequiredApprovals)
+	})
+
+	t.Run("pull request required still merges", func(t *testing.T) {
+		deps := newDeps(t, &model.GitBranchProtection{Pattern: "main", RequirePullRequest: true, BlockForcePush: true})
+		deps.prDB.On("Update", mock.Anything, deps.pr).Return(nil)
+
+		pr, err := deps.domain.MergePR(ctx, deps.repo.ID, 1, deps.reviewer, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.GitPRStatusMerged, pr.Status)
+	})
+}
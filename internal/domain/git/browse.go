@@ -0,0 +1,684 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	fdiff "github.com/go-git/go-git/v5/plumbing/format/diff"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+const (
+	defaultCommitPageSize = 30
+	maxCommitPageSize     = 100
+
+	// lfsPointerMaxSize bounds the blobs inspected for LFS pointers; real
+	// pointers are well under 200 bytes.
+	lfsPointerMaxSize = 1024
+	lfsPointerVersion = "version https://git-lfs.github.com/spec/v1"
+)
+
+// ===== Repository Browsing =====
+
+// ListRefs lists the branches and tags of a repository, or only those of
+// refType when it is set.
+func (d *Domain) ListRefs(ctx context.Context, repoID, userID uuid.UUID, refType model.GitRefType) ([]*model.GitRef, error) {
+	repo, st, err := d.openForBrowsing(ctx, repoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	iter, err := st.IterReferences()
+	if err != nil {
+		return nil, fmt.Errorf("list refs: %w", err)
+	}
+
+	refs := make([]*model.GitRef, 0)
+	err = iter.ForEach(func(r *plumbing.Reference) error {
+		if r.Type() != plumbing.HashReference {
+			return nil
+		}
+
+		var ref *model.GitRef
+		switch {
+		case r.Name().IsBranch() && refType != model.GitRefTypeTag:
+			name := r.Name().Short()
+			ref = &model.GitRef{
+				Name:      name,
+				Type:      model.GitRefTypeBranch,
+				SHA:       r.Hash().String(),
+				IsDefault: name == repo.DefaultBranch,
+			}
+		case r.Name().IsTag() && refType != model.GitRefTypeBranch:
+			commit, err := peelCommit(st, r.Hash())
+			if err != nil {
+				// Tags of trees or blobs have no commit to show
+				return nil
+			}
+			ref = &model.GitRef{
+				Name: r.Name().Short(),
+				Type: model.GitRefTypeTag,
+				SHA:  commit.Hash.String(),
+			}
+		default:
+			return nil
+		}
+
+		refs = append(refs, ref)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list refs: %w", err)
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Type != refs[j].Type {
+			return refs[i].Type == model.GitRefTypeBranch
+		}
+		return refs[i].Name < refs[j].Name
+	})
+
+	return refs, nil
+}
+
+// GetTree returns the entries of a directory at a ref. An empty path is
+// the root directory.
+func (d *Domain) GetTree(ctx context.Context, repoID, userID uuid.UUID, ref, treePath string) (*model.GitTree, error) {
+	repo, st, err := d.openForBrowsing(ctx, repoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := resolveCommit(st, ref, repo.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("read tree of %s: %w", commit.Hash, err)
+	}
+
+	treePath = cleanPath(treePath)
+	if treePath != "" {
+		entry, err := findEntry(tree, treePath)
+		if err != nil {
+			return nil, err
+		}
+		if entry.Mode != filemode.Dir {
+			return nil, ErrNotADirectory
+		}
+		if tree, err = object.GetTree(st, entry.Hash); err != nil {
+			return nil, fmt.Errorf("read tree %s: %w", entry.Hash, err)
+		}
+	}
+
+	result := &model.GitTree{
+		SHA:       tree.Hash.String(),
+		CommitSHA: commit.Hash.String(),
+		Path:      treePath,
+		Entries:   make([]*model.GitTreeEntry, 0, len(tree.Entries)),
+	}
+	for _, e := range tree.Entries {
+		entry := &model.GitTreeEntry{
+			Name: e.Name,
+			Path: path.Join(treePath, e.Name),
+			Type: entryType(e.Mode),
+			Mode: e.Mode.String(),
+			SHA:  e.Hash.String(),
+		}
+		if entry.Type == "blob" {
+			if entry.Size, err = st.EncodedObjectSize(e.Hash); err != nil {
+				return nil, fmt.Errorf("read size of %s: %w", e.Hash, err)
+			}
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+
+	return result, nil
+}
+
+// GetBlob returns the content of a file at a ref. LFS pointers are
+// resolved to the object they refer to when it is linked to the repository.
+// The caller closes the content.
+func (d *Domain) GetBlob(ctx context.Context, repoID, userID uuid.UUID, ref, filePath string) (*inbound.GitBlob, error) {
+	repo, st, err := d.openForBrowsing(ctx, repoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := resolveCommit(st, ref, repo.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("read tree of %s: %w", commit.Hash, err)
+	}
+
+	filePath = cleanPath(filePath)
+	if filePath == "" {
+		return nil, ErrNotAFile
+	}
+	entry, err := findEntry(tree, filePath)
+	if err != nil {
+		return nil, err
+	}
+	if !entry.Mode.IsFile() {
+		return nil, ErrNotAFile
+	}
+
+	blob, err := object.GetBlob(st, entry.Hash)
+	if err != nil {
+		return nil, fmt.Errorf("read blob %s: %w", entry.Hash, err)
+	}
+	result := &inbound.GitBlob{
+		Path: filePath,
+		SHA:  blob.Hash.String(),
+		Size: blob.Size,
+	}
+
+	if blob.Size > lfsPointerMaxSize {
+		if result.Content, err = blob.Reader(); err != nil {
+			return nil, fmt.Errorf("read blob %s: %w", entry.Hash, err)
+		}
+		return result, nil
+	}
+
+	data, err := readBlob(blob)
+	if err != nil {
+		return nil, err
+	}
+	if oid, ok := parseLFSPointer(data); ok && d.lfsStorage != nil && d.lfsObjDB != nil {
+		// Objects are stored once for all repositories. A pointer naming one
+		// this repository never received is shown as it is.
+		linked, err := d.lfsObjDB.IsLinked(ctx, repo.ID, oid)
+		if err != nil {
+			return nil, fmt.Errorf("check LFS object link: %w", err)
+		}
+		if !linked {
+			result.Content = io.NopCloser(bytes.NewReader(data))
+			return result, nil
+		}
+
+		exists, err := d.lfsStorage.Exists(ctx, oid)
+		if err != nil {
+			return nil, fmt.Errorf("check LFS object: %w", err)
+		}
+		if !exists {
+			return nil, ErrLFSObjectNotFound
+		}
+
+		content, size, err := d.lfsStorage.Download(ctx, oid)
+		if err != nil {
+			return nil, fmt.Errorf("download LFS object: %w", err)
+		}
+		result.Content = content
+		result.Size = size
+		result.LFSOID = oid
+		return result, nil
+	}
+
+	result.Content = io.NopCloser(bytes.NewReader(data))
+	return result, nil
+}
+
+// ListCommits returns a page of the history of a ref, newest first, and
+// whether more commits follow.
+func (d *Domain) ListCommits(ctx context.Context, repoID, userID uuid.UUID, filter *inbound.GitCommitFilter) ([]*model.GitCommit, bool, error) {
+	repo, st, err := d.openForBrowsing(ctx, repoID, userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if filter == nil {
+		filter = &inbound.GitCommitFilter{}
+	}
+	page, pageSize := filter.Page, filter.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultCommitPageSize
+	}
+	if pageSize > maxCommitPageSize {
+		pageSize = maxCommitPageSize
+	}
+
+	head, err := resolveCommit(st, filter.Ref, repo.DefaultBranch)
+	if err != nil {
+		return nil, false, err
+	}
+
+	iter := object.NewCommitPreorderIter(head, nil, nil)
+	if p := cleanPath(filter.Path); p != "" {
+		iter = object.NewCommitPathIterFromIter(func(file string) bool {
+			return file == p || strings.HasPrefix(file, p+"/")
+		}, iter, false)
+	}
+	defer iter.Close()
+
+	skip := (page - 1) * pageSize
+	commits := make([]*model.GitCommit, 0, pageSize)
+	hasMore := false
+	err = iter.ForEach(func(c *object.Commit) error {
+		if skip > 0 {
+			skip--
+			return nil
+		}
+		if len(commits) == pageSize {
+			hasMore = true
+			return storer.ErrStop
+		}
+		commits = append(commits, toGitCommit(c))
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("walk history: %w", err)
+	}
+
+	return commits, hasMore, nil
+}
+
+// GetCommit returns a commit with the files it changed relative to its
+// first parent. Past the diff limits only the first files are listed.
+func (d *Domain) GetCommit(ctx context.Context, repoID, userID uuid.UUID, sha string) (*model.GitCommit, error) {
+	repo, st, err := d.openForBrowsing(ctx, repoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	commit, err := resolveCommit(st, sha, repo.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("read parent of %s: %w", commit.Hash, err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, fmt.Errorf("read tree of %s: %w", parent.Hash, err)
+		}
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("read tree of %s: %w", commit.Hash, err)
+	}
+
+	diff, err := d.diffTrees(ctx, parentTree, tree)
+	if err != nil {
+		return nil, err
+	}
+
+	result := toGitCommit(commit)
+	result.Files = diff.files
+	result.FilesTruncated = diff.truncated
+	return result, nil
+}
+
+// Diff returns the unified diff from base to head. Either side may be a
+// branch, a tag or a commit SHA. Past the diff limits the patch is left out
+// and only per-file stats are returned.
+func (d *Domain) Diff(ctx context.Context, repoID, userID uuid.UUID, base, head string) (*model.GitDiff, error) {
+	repo, st, err := d.openForBrowsing(ctx, repoID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	baseCommit, err := resolveCommit(st, base, repo.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+	headCommit, err := resolveCommit(st, head, repo.DefaultBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	baseTree, err := baseCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("read tree of %s: %w", baseCommit.Hash, err)
+	}
+	headTree, err := headCommit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("read tree of %s: %w", headCommit.Hash, err)
+	}
+
+	diff, err := d.diffTrees(ctx, baseTree, headTree)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.GitDiff{
+		BaseSHA:   baseCommit.Hash.String(),
+		HeadSHA:   headCommit.Hash.String(),
+		Files:     diff.files,
+		Patch:     diff.patch,
+		Truncated: diff.truncated,
+	}, nil
+}
+
+// ===== Browsing Helpers =====
+
+// openForBrowsing checks read access and opens the storage of a repository.
+func (d *Domain) openForBrowsing(ctx context.Context, repoID, userID uuid.UUID) (*model.GitRepo, *filesystem.Storage, error) {
+	if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionRead); err != nil {
+		return nil, nil, err
+	}
+
+	repo, err := d.repoDB.FindByID(ctx, repoID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if repo == nil {
+		return nil, nil, ErrRepoNotFound
+	}
+
+	fs, err := d.storage.GetFilesystem(ctx, repo.StoragePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return repo, openObjectStorage(fs), nil
+}
+
+// resolveCommit resolves a branch, tag or full commit SHA to a commit. An
+// empty revision or HEAD means the default branch.
+func resolveCommit(st *filesystem.Storage, rev, defaultBranch string) (*object.Commit, error) {
+	if rev == "" || rev == "HEAD" {
+		rev = defaultBranch
+	}
+
+	names := []plumbing.ReferenceName{
+		plumbing.NewBranchReferenceName(rev),
+		plumbing.NewTagReferenceName(rev),
+	}
+	if strings.HasPrefix(rev, "refs/") {
+		names = []plumbing.ReferenceName{plumbing.ReferenceName(rev)}
+	}
+	for _, name := range names {
+		ref, err := storer.ResolveReference(st, name)
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", name, err)
+		}
+		return peelCommit(st, ref.Hash())
+	}
+
+	if isHex(rev, 40) {
+		commit, err := object.GetCommit(st, plumbing.NewHash(rev))
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return nil, ErrCommitNotFound
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read commit %s: %w", rev, err)
+		}
+		return commit, nil
+	}
+
+	return nil, ErrRefNotFound
+}
+
+// peelCommit returns the commit a hash refers to, following annotated tags.
+func peelCommit(st *filesystem.Storage, hash plumbing.Hash) (*object.Commit, error) {
+	obj, err := object.GetObject(st, hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, ErrCommitNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read object %s: %w", hash, err)
+	}
+
+	switch o := obj.(type) {
+	case *object.Commit:
+		return o, nil
+	case *object.Tag:
+		return peelCommit(st, o.Target)
+	default:
+		return nil, ErrCommitNotFound
+	}
+}
+
+// isHex reports whether s is n lowercase hex digits.
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// cleanPath normalizes a repository path to the form tree lookups use.
+func cleanPath(p string) string {
+	return strings.Trim(path.Clean("/"+p), "/")
+}
+
+func findEntry(tree *object.Tree, p string) (*object.TreeEntry, error) {
+	entry, err := tree.FindEntry(p)
+	if errors.Is(err, object.ErrEntryNotFound) || errors.Is(err, object.ErrDirectoryNotFound) {
+		return nil, ErrPathNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("find %s: %w", p, err)
+	}
+	return entry, nil
+}
+
+func entryType(mode filemode.FileMode) string {
+	switch mode {
+	case filemode.Dir:
+		return "tree"
+	case filemode.Submodule:
+		return "commit"
+	default:
+		return "blob"
+	}
+}
+
+func readBlob(blob *object.Blob) ([]byte, error) {
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("read blob %s: %w", blob.Hash, err)
+	}
+	defer r.Close()
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read blob %s: %w", blob.Hash, err)
+	}
+	return data, nil
+}
+
+// parseLFSPointer returns the oid of an LFS pointer file.
+func parseLFSPointer(data []byte) (string, bool) {
+	var oid string
+	var hasSize bool
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for i := 0; s.Scan(); i++ {
+		key, value, found := strings.Cut(s.Text(), " ")
+		if !found {
+			return "", false
+		}
+		switch {
+		case i == 0:
+			if s.Text() != lfsPointerVersion {
+				return "", false
+			}
+		case key == "oid":
+			oid = strings.TrimPrefix(value, "sha256:")
+			if oid == value || !isHex(oid, 64) {
+				return "", false
+			}
+		case key == "size":
+			if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+				return "", false
+			}
+			hasSize = true
+		}
+	}
+
+	return oid, oid != "" && hasSize
+}
+
+// errDiffTooLarge stops encoding a patch past MaxDiffBytes.
+var errDiffTooLarge = errors.New("diff too large")
+
+// treeDiff is the difference between two trees.
+type treeDiff struct {
+	files     []*model.GitFileChange
+	patch     string
+	truncated bool
+}
+
+// diffTrees returns the changes from one tree to another, detecting renames.
+// A nil from tree is empty. Past MaxDiffFiles changed files only the first
+// of them are listed, without line counts; past MaxDiffBytes of patch the
+// files keep their line counts. Either way the patch is left out and the
+// diff marked truncated.
+func (d *Domain) diffTrees(ctx context.Context, from, to *object.Tree) (*treeDiff, error) {
+	changes, err := object.DiffTreeWithOptions(ctx, from, to, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("diff trees: %w", err)
+	}
+	if len(changes) > d.cfg.MaxDiffFiles {
+		files := make([]*model.GitFileChange, 0, d.cfg.MaxDiffFiles)
+		for _, c := range changes[:d.cfg.MaxDiffFiles] {
+			files = append(files, newFileChange(c.From.Name, c.To.Name))
+		}
+		return &treeDiff{files: files, truncated: true}, nil
+	}
+
+	patch, err := changes.PatchContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("diff trees: %w", err)
+	}
+
+	diff := &treeDiff{files: fileChanges(patch)}
+	var buf strings.Builder
+	err = patch.Encode(&limitedWriter{w: &buf, remaining: d.cfg.MaxDiffBytes})
+	switch {
+	case errors.Is(err, errDiffTooLarge):
+		diff.truncated = true
+	case err != nil:
+		return nil, fmt.Errorf("encode patch: %w", err)
+	default:
+		diff.patch = buf.String()
+	}
+	return diff, nil
+}
+
+// limitedWriter fails with errDiffTooLarge once more than remaining bytes
+// are written.
+type limitedWriter struct {
+	w         io.Writer
+	remaining int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining {
+		return 0, errDiffTooLarge
+	}
+	l.remaining -= int64(len(p))
+	return l.w.Write(p)
+}
+
+// newFileChange returns a change of the file at fromPath to toPath, where
+// an empty path means the file is absent on that side.
+func newFileChange(fromPath, toPath string) *model.GitFileChange {
+	switch {
+	case fromPath == "":
+		return &model.GitFileChange{Path: toPath, Status: model.GitFileAdded}
+	case toPath == "":
+		return &model.GitFileChange{Path: fromPath, Status: model.GitFileDeleted}
+	case fromPath != toPath:
+		return &model.GitFileChange{Path: toPath, OldPath: fromPath, Status: model.GitFileRenamed}
+	default:
+		return &model.GitFileChange{Path: toPath, Status: model.GitFileModified}
+	}
+}
+
+// fileChanges summarizes the files of a patch.
+func fileChanges(patch *object.Patch) []*model.GitFileChange {
+	files := make([]*model.GitFileChange, 0, len(patch.FilePatches()))
+	for _, fp := range patch.FilePatches() {
+		var fromPath, toPath string
+		from, to := fp.Files()
+		if from != nil {
+			fromPath = from.Path()
+		}
+		if to != nil {
+			toPath = to.Path()
+		}
+
+		change := newFileChange(fromPath, toPath)
+		change.Binary = fp.IsBinary()
+		for _, chunk := range fp.Chunks() {
+			switch chunk.Type() {
+			case fdiff.Add:
+				change.Additions += countLines(chunk.Content())
+			case fdiff.Delete:
+				change.Deletions += countLines(chunk.Content())
+			}
+		}
+
+		files = append(files, change)
+	}
+	return files
+}
+
+func countLines(s string) int {
+	if s == "" {
+		return 0
+	}
+	n := strings.Count(s, "\n")
+	if !strings.HasSuffix(s, "\n") {
+		n++
+	}
+	return n
+}
+
+func toGitCommit(c *object.Commit) *model.GitCommit {
+	parents := make([]string, len(c.ParentHashes))
+	for i, h := range c.ParentHashes {
+		parents[i] = h.String()
+	}
+
+	return &model.GitCommit{
+		SHA:     c.Hash.String(),
+		Message: c.Message,
+		Author: model.GitSignature{
+			Name:  c.Author.Name,
+			Email: c.Author.Email,
+			Date:  c.Author.When,
+		},
+		Committer: model.GitSignature{
+			Name:  c.Committer.Name,
+			Email: c.Committer.Email,
+			Date:  c.Committer.When,
+		},
+		ParentSHAs: parents,
+	}
+}
@@ -0,0 +1,377 @@
+package git
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+const testLFSOID = "4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393"
+
+// newBrowseTestDomain returns a domain serving repo, its LFS mocks, the
+// repository ID and its owner.
+func newBrowseTestDomain(t *testing.T, repo *testRepo) (*Domain, *MockGitLFSStorage, *MockGitLFSObjDB, uuid.UUID, uuid.UUID) {
+	mockRepoDB := new(MockGitRepoDB)
+	mockCollabDB := new(MockGitCollabDB)
+	mockStorage := new(MockGitStorage)
+	mockLFSObjDB := new(MockGitLFSObjDB)
+	mockLFSStorage := new(MockGitLFSStorage)
+
+	domain := NewDomain(
+		mockRepoDB,
+		mockCollabDB,
+		nil,
+		nil,
+		nil,
+		mockLFSObjDB,
+		nil,
+		mockStorage,
+		mockLFSStorage,
+		nil,
+		nil,
+		nil,
+		nil,
+		zap.NewNop(),
+	)
+
+	repoID := uuid.New()
+	ownerID := uuid.New()
+	mockRepoDB.On("FindByID", mock.Anything, repoID).Return(&model.GitRepo{
+		ID:            repoID,
+		OwnerID:       ownerID,
+		Visibility:    model.GitVisibilityPrivate,
+		DefaultBranch: "main",
+		StoragePath:   "repos/test",
+	}, nil)
+	mockCollabDB.On("FindByRepoAndUser", mock.Anything, repoID, mock.Anything).Return(nil, nil)
+	mockStorage.On("GetFilesystem", mock.Anything, "repos/test").Return(repo.fs, nil)
+
+	return domain, mockLFSStorage, mockLFSObjDB, repoID, ownerID
+}
+
+func readContent(t *testing.T, blob *inbound.GitBlob) string {
+	defer blob.Content.Close()
+	data, err := io.ReadAll(blob.Content)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestDomain_ListRefs(t *testing.T) {
+	repo := newTestRepo(t)
+	first := repo.commit("main", map[string]string{"a": "1"})
+	second := repo.commit("feature", map[string]string{"a": "2"}, first)
+
+	tag, err := storeObject(repo.st, &object.Tag{
+		Name:       "v1",
+		Tagger:     object.Signature{Name: "test", When: time.Now()},
+		Message:    "v1\n",
+		TargetType: plumbing.CommitObject,
+		Target:     first,
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.st.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName("v1"), tag)))
+
+	domain, _, _, repoID, ownerID := newBrowseTestDomain(t, repo)
+
+	t.Run("all", func(t *testing.T) {
+		refs, err := domain.ListRefs(context.Background(), repoID, ownerID, "")
+
+		require.NoError(t, err)
+		assert.Equal(t, []*model.GitRef{
+			{Name: "feature", Type: model.GitRefTypeBranch, SHA: second.String()},
+			{Name: "main", Type: model.GitRefTypeBranch, SHA: first.String(), IsDefault: true},
+			{Name: "v1", Type: model.GitRefTypeTag, SHA: first.String()},
+		}, refs)
+	})
+
+	t.Run("tags_only", func(t *testing.T) {
+		refs, err := domain.ListRefs(context.Background(), repoID, ownerID, model.GitRefTypeTag)
+
+		require.NoError(t, err)
+		require.Len(t, refs, 1)
+		assert.Equal(t, "v1", refs[0].Name)
+	})
+
+	t.Run("access_denied", func(t *testing.T) {
+		_, err := domain.ListRefs(context.Background(), repoID, uuid.New(), "")
+
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+}
+
+func TestDomain_GetTree(t *testing.T) {
+	repo := newTestRepo(t)
+	head := repo.commit("main", map[string]string{"README.md": "hello", "src/main.go": "package main\n"})
+	domain, _, _, repoID, ownerID := newBrowseTestDomain(t, repo)
+
+	t.Run("root", func(t *testing.T) {
+		tree, err := domain.GetTree(context.Background(), repoID, ownerID, "", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, head.String(), tree.CommitSHA)
+		require.Len(t, tree.Entries, 2)
+		assert.Equal(t, &model.GitTreeEntry{
+			Name: "README.md",
+			Path: "README.md",
+			Type: "blob",
+			Mode: "0100644",
+			SHA:  tree.Entries[0].SHA,
+			Size: 5,
+		}, tree.Entries[0])
+		assert.Equal(t, "tree", tree.Entries[1].Type)
+	})
+
+	t.Run("subdirectory", func(t *testing.T) {
+		tree, err := domain.GetTree(context.Background(), repoID, ownerID, head.String(), "/src/")
+
+		require.NoError(t, err)
+		assert.Equal(t, "src", tree.Path)
+		require.Len(t, tree.Entries, 1)
+		assert.Equal(t, "src/main.go", tree.Entries[0].Path)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := domain.GetTree(context.Background(), repoID, ownerID, "main", "README.md")
+		assert.ErrorIs(t, err, ErrNotADirectory)
+
+		_, err = domain.GetTree(context.Background(), repoID, ownerID, "main", "missing")
+		assert.ErrorIs(t, err, ErrPathNotFound)
+
+		_, err = domain.GetTree(context.Background(), repoID, ownerID, "nope", "")
+		assert.ErrorIs(t, err, ErrRefNotFound)
+
+		_, err = domain.GetTree(context.Background(), repoID, ownerID, strings.Repeat("a", 40), "")
+		assert.ErrorIs(t, err, ErrCommitNotFound)
+	})
+}
+
+func TestDomain_GetBlob(t *testing.T) {
+	pointer := "version https://git-lfs.github.com/spec/v1\noid sha256:" + testLFSOID + "\nsize 12\n"
+
+	repo := newTestRepo(t)
+	repo.commit("main", map[string]string{"README.md": "hello", "model.bin": pointer, "dir/x": "1"})
+	domain, lfsStorage, lfsObjDB, repoID, ownerID := newBrowseTestDomain(t, repo)
+
+	t.Run("plain", func(t *testing.T) {
+		blob, err := domain.GetBlob(context.Background(), repoID, ownerID, "main", "README.md")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), blob.Size)
+		assert.Empty(t, blob.LFSOID)
+		assert.Equal(t, "hello", readContent(t, blob))
+	})
+
+	t.Run("lfs_pointer", func(t *testing.T) {
+		lfsObjDB.On("IsLinked", mock.Anything, repoID, testLFSOID).Return(true, nil).Once()
+		lfsStorage.On("Exists", mock.Anything, testLFSOID).Return(true, nil).Once()
+		lfsStorage.On("Download", mock.Anything, testLFSOID).
+			Return(io.NopCloser(strings.NewReader("real content")), int64(12), nil).Once()
+
+		blob, err := domain.GetBlob(context.Background(), repoID, ownerID, "main", "model.bin")
+
+		require.NoError(t, err)
+		assert.Equal(t, testLFSOID, blob.LFSOID)
+		assert.Equal(t, int64(12), blob.Size)
+		assert.Equal(t, "real content", readContent(t, blob))
+	})
+
+	t.Run("lfs_object_missing", func(t *testing.T) {
+		lfsObjDB.On("IsLinked", mock.Anything, repoID, testLFSOID).Return(true, nil).Once()
+		lfsStorage.On("Exists", mock.Anything, testLFSOID).Return(false, nil).Once()
+
+		_, err := domain.GetBlob(context.Background(), repoID, ownerID, "main", "model.bin")
+
+		assert.ErrorIs(t, err, ErrLFSObjectNotFound)
+	})
+
+	t.Run("lfs_object_of_another_repository", func(t *testing.T) {
+		lfsObjDB.On("IsLinked", mock.Anything, repoID, testLFSOID).Return(false, nil).Once()
+
+		blob, err := domain.GetBlob(context.Background(), repoID, ownerID, "main", "model.bin")
+
+		require.NoError(t, err)
+		assert.Empty(t, blob.LFSOID)
+		assert.Equal(t, pointer, readContent(t, blob))
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		_, err := domain.GetBlob(context.Background(), repoID, ownerID, "main", "dir")
+
+		assert.ErrorIs(t, err, ErrNotAFile)
+	})
+}
+
+func TestDomain_ListCommits(t *testing.T) {
+	repo := newTestRepo(t)
+	c1 := repo.commit("main", map[string]string{"a": "1", "docs/x": "1"})
+	c2 := repo.commit("main", map[string]string{"a": "2", "docs/x": "1"}, c1)
+	c3 := repo.commit("main", map[string]string{"a": "2", "docs/x": "2"}, c2)
+	domain, _, _, repoID, ownerID := newBrowseTestDomain(t, repo)
+
+	shas := func(commits []*model.GitCommit) []string {
+		out := make([]string, len(commits))
+		for i, c := range commits {
+			out[i] = c.SHA
+		}
+		return out
+	}
+
+	t.Run("paginated", func(t *testing.T) {
+		commits, hasMore, err := domain.ListCommits(context.Background(), repoID, ownerID,
+			&inbound.GitCommitFilter{Page: 1, PageSize: 2})
+		require.NoError(t, err)
+		assert.True(t, hasMore)
+		assert.Equal(t, []string{c3.String(), c2.String()}, shas(commits))
+
+		commits, hasMore, err = domain.ListCommits(context.Background(), repoID, ownerID,
+			&inbound.GitCommitFilter{Page: 2, PageSize: 2})
+		require.NoError(t, err)
+		assert.False(t, hasMore)
+		assert.Equal(t, []string{c1.String()}, shas(commits))
+	})
+
+	t.Run("path_filter", func(t *testing.T) {
+		commits, _, err := domain.ListCommits(context.Background(), repoID, ownerID,
+			&inbound.GitCommitFilter{Path: "docs"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{c3.String(), c1.String()}, shas(commits))
+	})
+}
+
+func TestDomain_GetCommit(t *testing.T) {
+	repo := newTestRepo(t)
+	c1 := repo.commit("main", map[string]string{"a": "1\n2\n", "gone": "x\n"})
+	c2 := repo.commit("main", map[string]string{"a": "1\n3\n4\n", "new": "y\n"}, c1)
+	domain, _, _, repoID, ownerID := newBrowseTestDomain(t, repo)
+
+	t.Run("with_parent", func(t *testing.T) {
+		commit, err := domain.GetCommit(context.Background(), repoID, ownerID, c2.String())
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{c1.String()}, commit.ParentSHAs)
+		assert.ElementsMatch(t, []*model.GitFileChange{
+			{Path: "a", Status: model.GitFileModified, Additions: 2, Deletions: 1},
+			{Path: "gone", Status: model.GitFileDeleted, Deletions: 1},
+			{Path: "new", Status: model.GitFileAdded, Additions: 1},
+		}, commit.Files)
+	})
+
+	t.Run("root", func(t *testing.T) {
+		commit, err := domain.GetCommit(context.Background(), repoID, ownerID, c1.String())
+
+		require.NoError(t, err)
+		assert.Empty(t, commit.ParentSHAs)
+		assert.Len(t, commit.Files, 2)
+	})
+}
+
+func TestDomain_Diff(t *testing.T) {
+	repo := newTestRepo(t)
+	base := repo.commit("main", map[string]string{"a": "old\n"})
+	head := repo.commit("feature", map[string]string{"a": "new\n"}, base)
+	domain, _, _, repoID, ownerID := newBrowseTestDomain(t, repo)
+
+	diff, err := domain.Diff(context.Background(), repoID, ownerID, "main", "feature")
+
+	require.NoError(t, err)
+	assert.Equal(t, base.String(), diff.BaseSHA)
+	assert.Equal(t, head.String(), diff.HeadSHA)
+	assert.Equal(t, []*model.GitFileChange{
+		{Path: "a", Status: model.GitFileModified, Additions: 1, Deletions: 1},
+	}, diff.Files)
+	assert.Contains(t, diff.Patch, "diff --git a/a b/a")
+	assert.Contains(t, diff.Patch, "-old\n+new\n")
+}
+
+func TestDomain_DiffLimits(t *testing.T) {
+	repo := newTestRepo(t)
+	base := repo.commit("main", map[string]string{"a": "1\n"})
+	repo.commit("feature", map[string]string{"a": "2\n3\n", "b": "4\n", "c": "5\n"}, base)
+	domain, _, _, repoID, ownerID := newBrowseTestDomain(t, repo)
+
+	t.Run("too_large", func(t *testing.T) {
+		domain.cfg.MaxDiffBytes = 10
+		defer func() { domain.cfg.MaxDiffBytes = DefaultConfig().MaxDiffBytes }()
+
+		diff, err := domain.Diff(context.Background(), repoID, ownerID, "main", "feature")
+
+		require.NoError(t, err)
+		assert.True(t, diff.Truncated)
+		assert.Empty(t, diff.Patch)
+		assert.ElementsMatch(t, []*model.GitFileChange{
+			{Path: "a", Status: model.GitFileModified, Additions: 2, Deletions: 1},
+			{Path: "b", Status: model.GitFileAdded, Additions: 1},
+			{Path: "c", Status: model.GitFileAdded, Additions: 1},
+		}, diff.Files)
+	})
+
+	t.Run("too_many_files", func(t *testing.T) {
+		domain.cfg.MaxDiffFiles = 2
+		defer func() { domain.cfg.MaxDiffFiles = DefaultConfig().MaxDiffFiles }()
+
+		diff, err := domain.Diff(context.Background(), repoID, ownerID, "main", "feature")
+
+		require.NoError(t, err)
+		assert.True(t, diff.Truncated)
+		assert.Empty(t, diff.Patch)
+		assert.Len(t, diff.Files, 2)
+
+		commit, err := domain.GetCommit(context.Background(), repoID, ownerID, "feature")
+
+		require.NoError(t, err)
+		assert.True(t, commit.FilesTruncated)
+		assert.Len(t, commit.Files, 2)
+	})
+
+	t.Run("within_limits", func(t *testing.T) {
+		diff, err := domain.Diff(context.Background(), repoID, ownerID, "main", "feature")
+
+		require.NoError(t, err)
+		assert.False(t, diff.Truncated)
+		assert.Contains(t, diff.Patch, "diff --git a/b b/b")
+	})
+}
+
+func TestParseLFSPointer(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantOID string
+		wantOK  bool
+	}{
+		{
+			name:    "valid",
+			data:    "version https://git-lfs.github.com/spec/v1\noid sha256:" + testLFSOID + "\nsize 12\n",
+			wantOID: testLFSOID,
+			wantOK:  true,
+		},
+		{name: "plain_text", data: "hello world\n"},
+		{name: "missing_size", data: "version https://git-lfs.github.com/spec/v1\noid sha256:" + testLFSOID + "\n"},
+		{name: "bad_oid", data: "version https://git-lfs.github.com/spec/v1\noid sha256:xyz\nsize 12\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oid, ok := parseLFSPointer([]byte(tt.data))
+
+			assert.Equal(t, tt.wantOK, ok)
+			if tt.wantOK {
+				assert.Equal(t, tt.wantOID, oid)
+			}
+		})
+	}
+}
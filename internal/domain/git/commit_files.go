@@ -0,0 +1,402 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+const gitAttributesFile = ".gitattributes"
+
+// CommitFiles writes a commit that applies file operations on top of a
+// branch, so files can be changed without a git client. Large files go to
+// LFS when the repository has it enabled.
+func (d *Domain) CommitFiles(ctx context.Context, repoID, userID uuid.UUID, input *inbound.GitCommitFilesInput) (*model.GitCommit, error) {
+	// Check write access
+	if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionWrite); err != nil {
+		return nil, err
+	}
+
+	if input == nil || len(input.Operations) == 0 {
+		return nil, ErrEmptyCommit
+	}
+	if strings.TrimSpace(input.Message) == "" {
+		return nil, ErrEmptyMessage
+	}
+
+	repo, err := d.repoDB.FindByID(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if repo == nil {
+		return nil, ErrRepoNotFound
+	}
+
+	branch := input.Branch
+	if branch == "" {
+		branch = repo.DefaultBranch
+	}
+	if plumbing.NewBranchReferenceName(branch).Validate() != nil {
+		return nil, ErrInvalidBranch
+	}
+
+	var incoming int64
+	for _, op := range input.Operations {
+		incoming += int64(len(op.Content))
+	}
+	if err := d.checkStorageQuota(ctx, repo.OwnerID, incoming); err != nil {
+		return nil, err
+	}
+
+	fs, err := d.storage.GetFilesystem(ctx, repo.StoragePath)
+	if err != nil {
+		return nil, err
+	}
+	st := openObjectStorage(fs)
+
+	files := make(map[string]object.TreeEntry)
+	parent, err := branchCommit(st, branch)
+	switch {
+	case err == nil:
+		if input.ExpectedParentSHA != "" && input.ExpectedParentSHA != parent.Hash.String() {
+			return nil, ErrTargetBranchMoved
+		}
+		if files, err = commitEntries(parent); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, ErrBranchNotFound) && input.ExpectedParentSHA == "":
+		// A branch that doesn't exist yet gets a root commit
+	default:
+		return nil, err
+	}
+	if err := d.checkDirectPush(ctx, repoID, userID, branch, parent == nil); err != nil {
+		return nil, err
+	}
+
+	writer := &fileWriter{domain: d, repo: repo, st: st, files: files}
+	if err := writer.apply(ctx, input.Operations); err != nil {
+		return nil, err
+	}
+
+	tree, err := writeTree(st, files)
+	if err != nil {
+		return nil, err
+	}
+
+	var parents []plumbing.Hash
+	var parentTree *object.Tree
+	old := plumbing.ZeroHash
+	if parent != nil {
+		if tree == parent.TreeHash {
+			return nil, ErrEmptyCommit
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, fmt.Errorf("read tree of %s: %w", parent.Hash, err)
+		}
+		parents = []plumbing.Hash{parent.Hash}
+		old = parent.Hash
+	}
+
+	message := input.Message
+	if !strings.HasSuffix(message, "\n") {
+		message += "\n"
+	}
+	head, err := writeCommit(st, tree, parents, commitSignature(input.AuthorName, input.AuthorEmail, userID), message)
+	if err != nil {
+		return nil, err
+	}
+	if err := updateBranch(st, branch, old, head); err != nil {
+		return nil, err
+	}
+
+	d.recordCommit(ctx, repo, writer.lfsUsed)
+
+	commit, err := object.GetCommit(st, head)
+	if err != nil {
+		return nil, fmt.Errorf("read commit %s: %w", head, err)
+	}
+	headTree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("read tree of %s: %w", head, err)
+	}
+	diff, err := d.diffTrees(ctx, parentTree, headTree)
+	if err != nil {
+		return nil, err
+	}
+
+	d.logger.Info("files committed",
+		zap.String("repo_id", repoID.String()),
+		zap.String("branch", branch),
+		zap.String("commit", head.String()),
+		zap.Int("operations", len(input.Operations)),
+	)
+
+	result := toGitCommit(commit)
+	result.Files = diff.files
+	result.FilesTruncated = diff.truncated
+	return result, nil
+}
+
+// recordCommit updates the push time and sizes of a repository after a
+// commit landed. The commit isn't failed over bookkeeping.
+func (d *Domain) recordCommit(ctx context.Context, repo *model.GitRepo, lfsUsed bool) {
+	if err := d.UpdatePushedAt(ctx, repo.ID); err != nil {
+		d.logger.Warn("failed to update pushed_at", zap.String("repo_id", repo.ID.String()), zap.Error(err))
+	}
+
+	size, err := d.storage.GetRepositorySize(ctx, repo.StoragePath)
+	if err != nil {
+		d.logger.Warn("failed to get repository size", zap.String("repo_id", repo.ID.String()), zap.Error(err))
+		return
+	}
+	lfsSize := repo.LFSSizeBytes
+	if lfsUsed {
+		if lfsSize, err = d.lfsObjDB.GetRepoLFSSize(ctx, repo.ID); err != nil {
+			d.logger.Warn("failed to get LFS size", zap.String("repo_id", repo.ID.String()), zap.Error(err))
+			lfsSize = repo.LFSSizeBytes
+		}
+	}
+	if err := d.UpdateRepoSize(ctx, repo.ID, size, lfsSize); err != nil {
+		d.logger.Warn("failed to update repository size", zap.String("repo_id", repo.ID.String()), zap.Error(err))
+	}
+}
+
+// fileWriter applies file operations to the files of a commit being
+// written.
+type fileWriter struct {
+	domain *Domain
+	repo   *model.GitRepo
+	st     storage.Storer
+	files  map[string]object.TreeEntry
+
+	lfsPaths []string
+	lfsUsed  bool
+}
+
+func (w *fileWriter) apply(ctx context.Context, ops []*inbound.GitFileOperation) error {
+	for _, op := range ops {
+		if !op.Action.IsValid() {
+			return ErrInvalidFileAction
+		}
+		p, ok := validFilePath(op.Path)
+		if !ok {
+			return ErrInvalidPath
+		}
+		existing, exists := w.files[p]
+
+		switch op.Action {
+		case model.GitFileActionCreate:
+			if exists {
+				return ErrFileExists
+			}
+			if err := w.write(ctx, p, op.Content, filemode.Regular); err != nil {
+				return err
+			}
+		case model.GitFileActionUpdate:
+			if !exists {
+				return ErrPathNotFound
+			}
+			if err := w.write(ctx, p, op.Content, existing.Mode); err != nil {
+				return err
+			}
+		case model.GitFileActionDelete:
+			if !exists {
+				return ErrPathNotFound
+			}
+			delete(w.files, p)
+		case model.GitFileActionMove:
+			from, ok := validFilePath(op.FromPath)
+			if !ok {
+				return ErrInvalidPath
+			}
+			moved, found := w.files[from]
+			if !found {
+				return ErrPathNotFound
+			}
+			if exists {
+				return ErrFileExists
+			}
+			delete(w.files, from)
+			w.files[p] = moved
+			if op.Content != nil {
+				if err := w.write(ctx, p, op.Content, moved.Mode); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if len(pathCollisions(w.files)) > 0 {
+		return ErrInvalidPath
+	}
+	if len(w.lfsPaths) > 0 {
+		return w.trackLFSPaths()
+	}
+	return nil
+}
+
+// write stores the content of a file, or an LFS pointer to it when the
+// file is large enough.
+func (w *fileWriter) write(ctx context.Context, p string, content []byte, mode filemode.FileMode) error {
+	d := w.domain
+	if w.repo.LFSEnabled && d.lfsStorage != nil && int64(len(content)) >= d.cfg.LFSThreshold {
+		pointer, err := w.storeLFSObject(ctx, content)
+		if err != nil {
+			return err
+		}
+		content = pointer
+		w.lfsPaths = append(w.lfsPaths, p)
+	}
+
+	hash, err := storeBlob(w.st, content)
+	if err != nil {
+		return err
+	}
+	w.files[p] = object.TreeEntry{Mode: mode, Hash: hash}
+	return nil
+}
+
+// storeLFSObject uploads content to LFS storage, links it to the
+// repository and returns the pointer file for it.
+func (w *fileWriter) storeLFSObject(ctx context.Context, content []byte) ([]byte, error) {
+	d := w.domain
+	size := int64(len(content))
+	if size > d.cfg.MaxLFSFileSize {
+		return nil, ErrLFSFileTooLarge
+	}
+
+	sum := sha256.Sum256(content)
+	oid := hex.EncodeToString(sum[:])
+
+	// Objects are shared between repositories; don't rewrite stored ones
+	exists, err := d.lfsStorage.Exists(ctx, oid)
+	if err != nil {
+		return nil, fmt.Errorf("check LFS object: %w", err)
+	}
+	if !exists {
+		if err := d.lfsStorage.Upload(ctx, oid, bytes.NewReader(content), size); err != nil {
+			return nil, fmt.Errorf("upload LFS object: %w", err)
+		}
+	}
+
+	obj := &model.GitLFSObject{
+		OID:        oid,
+		Size:       size,
+		StorageKey: d.cfg.LFSPrefix + oid,
+		CreatedAt:  time.Now(),
+	}
+	if err := d.lfsObjDB.Create(ctx, obj); err != nil {
+		return nil, fmt.Errorf("create LFS object: %w", err)
+	}
+	if err := d.lfsObjDB.Link(ctx, w.repo.ID, oid); err != nil {
+		return nil, fmt.Errorf("link LFS object: %w", err)
+	}
+	w.lfsUsed = true
+
+	return []byte(fmt.Sprintf("%s\noid sha256:%s\nsize %d\n", lfsPointerVersion, oid, size)), nil
+}
+
+// trackLFSPaths adds the files stored in LFS to .gitattributes, so git
+// clients check them out through the LFS filter.
+func (w *fileWriter) trackLFSPaths() error {
+	var attributes []byte
+	mode := filemode.Regular
+	if entry, ok := w.files[gitAttributesFile]; ok {
+		blob, err := object.GetBlob(w.st, entry.Hash)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", gitAttributesFile, err)
+		}
+		if attributes, err = readBlob(blob); err != nil {
+			return err
+		}
+		mode = entry.Mode
+	}
+
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(string(attributes), "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+
+	updated := attributes
+	for _, p := range w.lfsPaths {
+		line := lfsAttributeLine(p)
+		if existing[line] {
+			continue
+		}
+		if len(updated) > 0 && !bytes.HasSuffix(updated, []byte("\n")) {
+			updated = append(updated, '\n')
+		}
+		updated = append(updated, line+"\n"...)
+		existing[line] = true
+	}
+	if bytes.Equal(updated, attributes) {
+		return nil
+	}
+
+	hash, err := storeBlob(w.st, updated)
+	if err != nil {
+		return err
+	}
+	w.files[gitAttributesFile] = object.TreeEntry{Mode: mode, Hash: hash}
+	return nil
+}
+
+// lfsAttributeLine returns the .gitattributes line tracking one file.
+func lfsAttributeLine(p string) string {
+	return "/" + strings.ReplaceAll(p, " ", "[[:space:]]") + " filter=lfs diff=lfs merge=lfs -text"
+}
+
+// validFilePath returns the path of a file in a tree. Paths can't escape
+// the tree or point into .git.
+func validFilePath(p string) (string, bool) {
+	p = strings.Trim(p, "/")
+	if p == "" || strings.ContainsRune(p, 0) {
+		return "", false
+	}
+	for _, part := range strings.Split(p, "/") {
+		if part == "" || part == "." || part == ".." || strings.EqualFold(part, ".git") {
+			return "", false
+		}
+	}
+	return p, true
+}
+
+func storeBlob(st storage.Storer, content []byte) (plumbing.Hash, error) {
+	obj := st.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	obj.SetSize(int64(len(content)))
+
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("write blob: %w", err)
+	}
+	if _, err := io.Copy(w, bytes.NewReader(content)); err != nil {
+		_ = w.Close()
+		return plumbing.ZeroHash, fmt.Errorf("write blob: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("write blob: %w", err)
+	}
+
+	hash, err := st.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("store blob: %w", err)
+	}
+	return hash, nil
+}
@@ -0,0 +1,254 @@
+package git
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+type commitTestDeps struct {
+	domain     *Domain
+	lfsStorage *MockGitLFSStorage
+	lfsObjDB   *MockGitLFSObjDB
+	repoID     uuid.UUID
+	ownerID    uuid.UUID
+}
+
+// newCommitTestDomain returns a domain that commits into repo.
+func newCommitTestDomain(t *testing.T, repo *testRepo, lfsEnabled bool) *commitTestDeps {
+	mockRepoDB := new(MockGitRepoDB)
+	mockCollabDB := new(MockGitCollabDB)
+	mockLFSObjDB := new(MockGitLFSObjDB)
+	mockProtectionDB := new(MockGitBranchProtectionDB)
+	mockStorage := new(MockGitStorage)
+	mockLFSStorage := new(MockGitLFSStorage)
+
+	cfg := DefaultConfig()
+	cfg.LFSThreshold = 16
+
+	deps := &commitTestDeps{
+		domain: NewDomain(
+			mockRepoDB,
+			mockCollabDB,
+			nil,
+			nil,
+			mockProtectionDB,
+			mockLFSObjDB,
+			nil,
+			mockStorage,
+			mockLFSStorage,
+			nil,
+			nil,
+			nil,
+			cfg,
+			zap.NewNop(),
+		),
+		lfsStorage: mockLFSStorage,
+		lfsObjDB:   mockLFSObjDB,
+		repoID:     uuid.New(),
+		ownerID:    uuid.New(),
+	}
+
+	mockRepoDB.On("FindByID", mock.Anything, deps.repoID).Return(&model.GitRepo{
+		ID:            deps.repoID,
+		OwnerID:       deps.ownerID,
+		Visibility:    model.GitVisibilityPrivate,
+		DefaultBranch: "main",
+		StoragePath:   "repos/test",
+		LFSEnabled:    lfsEnabled,
+	}, nil)
+	mockRepoDB.On("UpdatePushedAt", mock.Anything, deps.repoID, mock.Anything).Return(nil)
+	mockRepoDB.On("UpdateSize", mock.Anything, deps.repoID, mock.Anything, mock.Anything).Return(nil)
+	mockCollabDB.On("FindByRepoAndUser", mock.Anything, deps.repoID, mock.Anything).Return(nil, nil)
+	mockProtectionDB.On("FindByRepo", mock.Anything, deps.repoID).Return(nil, nil)
+	mockStorage.On("GetFilesystem", mock.Anything, "repos/test").Return(repo.fs, nil)
+	mockStorage.On("GetRepositorySize", mock.Anything, "repos/test").Return(int64(1024), nil)
+
+	return deps
+}
+
+func TestDomain_CommitFiles(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("applies_operations", func(t *testing.T) {
+		repo := newTestRepo(t)
+		parent := repo.commit("main", map[string]string{"keep": "1", "edit": "old", "drop": "1", "from": "moved"})
+		deps := newCommitTestDomain(t, repo, false)
+
+		commit, err := deps.domain.CommitFiles(ctx, deps.repoID, deps.ownerID, &inbound.GitCommitFilesInput{
+			ExpectedParentSHA: parent.String(),
+			Message:           "Edit files",
+			AuthorName:        "Ada",
+			AuthorEmail:       "ada@example.com",
+			Operations: []*inbound.GitFileOperation{
+				{Action: model.GitFileActionCreate, Path: "dir/new", Content: []byte("new")},
+				{Action: model.GitFileActionUpdate, Path: "edit", Content: []byte("new")},
+				{Action: model.GitFileActionDelete, Path: "drop"},
+				{Action: model.GitFileActionMove, FromPath: "from", Path: "to"},
+			},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{parent.String()}, commit.ParentSHAs)
+		assert.Equal(t, "Edit files\n", commit.Message)
+		assert.Equal(t, "ada@example.com", commit.Author.Email)
+		assert.Len(t, commit.Files, 4)
+
+		head := repo.branch("main")
+		assert.Equal(t, commit.SHA, head.Hash.String())
+		assert.Equal(t, map[string]string{"keep": "1", "edit": "new", "dir/new": "new", "to": "moved"}, repo.files(head))
+	})
+
+	t.Run("root_commit", func(t *testing.T) {
+		repo := newTestRepo(t)
+		deps := newCommitTestDomain(t, repo, false)
+
+		commit, err := deps.domain.CommitFiles(ctx, deps.repoID, deps.ownerID, &inbound.GitCommitFilesInput{
+			Message:    "Initial commit",
+			Operations: []*inbound.GitFileOperation{{Action: model.GitFileActionCreate, Path: "README.md", Content: []byte("hi")}},
+		})
+
+		require.NoError(t, err)
+		assert.Empty(t, commit.ParentSHAs)
+		assert.Equal(t, deps.ownerID.String(), commit.Author.Name)
+		assert.Equal(t, map[string]string{"README.md": "hi"}, repo.files(repo.branch("main")))
+	})
+
+	t.Run("branch_moved", func(t *testing.T) {
+		repo := newTestRepo(t)
+		first := repo.commit("main", map[string]string{"a": "1"})
+		repo.commit("main", map[string]string{"a": "2"}, first)
+		deps := newCommitTestDomain(t, repo, false)
+
+		_, err := deps.domain.CommitFiles(ctx, deps.repoID, deps.ownerID, &inbound.GitCommitFilesInput{
+			ExpectedParentSHA: first.String(),
+			Message:           "Stale edit",
+			Operations:        []*inbound.GitFileOperation{{Action: model.GitFileActionUpdate, Path: "a", Content: []byte("3")}},
+		})
+
+		assert.ErrorIs(t, err, ErrTargetBranchMoved)
+	})
+
+	t.Run("invalid_operations", func(t *testing.T) {
+		repo := newTestRepo(t)
+		repo.commit("main", map[string]string{"a": "1", "dir/b": "1"})
+		deps := newCommitTestDomain(t, repo, false)
+
+		tests := []struct {
+			name    string
+			op      *inbound.GitFileOperation
+			wantErr error
+		}{
+			{"create_existing", &inbound.GitFileOperation{Action: model.GitFileActionCreate, Path: "a"}, ErrFileExists},
+			{"update_missing", &inbound.GitFileOperation{Action: model.GitFileActionUpdate, Path: "x"}, ErrPathNotFound},
+			{"delete_missing", &inbound.GitFileOperation{Action: model.GitFileActionDelete, Path: "x"}, ErrPathNotFound},
+			{"move_onto_file", &inbound.GitFileOperation{Action: model.GitFileActionMove, FromPath: "a", Path: "dir/b"}, ErrFileExists},
+			{"git_dir", &inbound.GitFileOperation{Action: model.GitFileActionCreate, Path: ".git/config"}, ErrInvalidPath},
+			{"file_under_file", &inbound.GitFileOperation{Action: model.GitFileActionCreate, Path: "a/c"}, ErrInvalidPath},
+			{"file_over_dir", &inbound.GitFileOperation{Action: model.GitFileActionCreate, Path: "dir"}, ErrInvalidPath},
+			{"unknown_action", &inbound.GitFileOperation{Action: "copy", Path: "c"}, ErrInvalidFileAction},
+			{"no_change", &inbound.GitFileOperation{Action: model.GitFileActionUpdate, Path: "a", Content: []byte("1")}, ErrEmptyCommit},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := deps.domain.CommitFiles(ctx, deps.repoID, deps.ownerID, &inbound.GitCommitFilesInput{
+					Message:    "Change",
+					Operations: []*inbound.GitFileOperation{tt.op},
+				})
+
+				assert.ErrorIs(t, err, tt.wantErr)
+			})
+		}
+	})
+
+	t.Run("access_denied", func(t *testing.T) {
+		repo := newTestRepo(t)
+		deps := newCommitTestDomain(t, repo, false)
+
+		_, err := deps.domain.CommitFiles(ctx, deps.repoID, uuid.New(), &inbound.GitCommitFilesInput{
+			Message:    "Change",
+			Operations: []*inbound.GitFileOperation{{Action: model.GitFileActionCreate, Path: "a"}},
+		})
+
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+
+	t.Run("large_files_go_to_lfs", func(t *testing.T) {
+		repo := newTestRepo(t)
+		repo.commit("main", map[string]string{".gitattributes": "*.psd filter=lfs diff=lfs merge=lfs -text"})
+		deps := newCommitTestDomain(t, repo, true)
+
+		content := []byte("binary content larger than the threshold")
+		sum := sha256.Sum256(content)
+		oid := hex.EncodeToString(sum[:])
+
+		deps.lfsStorage.On("Exists", mock.Anything, oid).Return(false, nil)
+		deps.lfsStorage.On("Upload", mock.Anything, oid, mock.Anything, int64(len(content))).
+			Run(func(args mock.Arguments) {
+				data, _ := io.ReadAll(args.Get(2).(io.Reader))
+				assert.Equal(t, content, data)
+			}).Return(nil)
+		deps.lfsObjDB.On("Create", mock.Anything, mock.MatchedBy(func(obj *model.GitLFSObject) bool {
+			return obj.OID == oid && obj.Size == int64(len(content)) && obj.StorageKey == "lfs/"+oid
+		})).Return(nil)
+		deps.lfsObjDB.On("Link", mock.Anything, deps.repoID, oid).Return(nil)
+		deps.lfsObjDB.On("GetRepoLFSSize", mock.Anything, deps.repoID).Return(int64(len(content)), nil)
+
+		_, err := deps.domain.CommitFiles(ctx, deps.repoID, deps.ownerID, &inbound.GitCommitFilesInput{
+			Message: "Add assets",
+			Operations: []*inbound.GitFileOperation{
+				{Action: model.GitFileActionCreate, Path: "assets/big file.bin", Content: content},
+				{Action: model.GitFileActionCreate, Path: "small.txt", Content: []byte("small")},
+			},
+		})
+
+		require.NoError(t, err)
+		files := repo.files(repo.branch("main"))
+		pointer, ok := parseLFSPointer([]byte(files["assets/big file.bin"]))
+		assert.True(t, ok)
+		assert.Equal(t, oid, pointer)
+		assert.Equal(t, "small", files["small.txt"])
+		assert.Equal(t,
+			"*.psd filter=lfs diff=lfs merge=lfs -text\n/assets/big[[:space:]]file.bin filter=lfs diff=lfs merge=lfs -text\n",
+			files[".gitattributes"])
+		deps.lfsObjDB.AssertExpectations(t)
+	})
+}
+
+func TestValidFilePath(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{"a/b.txt", "a/b.txt", true},
+		{"/a/b.txt/", "a/b.txt", true},
+		{"", "", false},
+		{"a//b", "", false},
+		{"a/../b", "", false},
+		{"./a", "", false},
+		{".git/HEAD", "", false},
+		{"sub/.GIT/x", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, ok := validFilePath(tt.path)
+
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -16,6 +16,23 @@ type Config struct {
 	// PresignedURLExpiry is the default expiry for presigned URLs.
 	PresignedURLExpiry time.Duration
 
+	// LFSThreshold is the size from which files committed through the API
+	// are stored in LFS, for repositories with LFS enabled.
+	LFSThreshold int64
+
+	// MaxFileSize is the largest file a push may add outside LFS.
+	MaxFileSize int64
+
+	// MaxDiffFiles is the most changed files a diff lists.
+	MaxDiffFiles int
+
+	// MaxDiffBytes is the largest patch a diff returns.
+	MaxDiffBytes int64
+
+	// LFSProxyTransfer streams LFS objects through the server instead of
+	// handing out presigned storage URLs.
+	LFSProxyTransfer bool
+
 	// DefaultBranch is the default branch name for new repositories.
 	DefaultBranch string
 
@@ -30,6 +47,10 @@ func DefaultConfig() *Config {
 		LFSPrefix:          "lfs/",
 		MaxLFSFileSize:     2 * 1024 * 1024 * 1024, // 2GB
 		PresignedURLExpiry: 1 * time.Hour,
+		LFSThreshold:       1024 * 1024,       // 1MB
+		MaxFileSize:        100 * 1024 * 1024, // 100MB
+		MaxDiffFiles:       1000,
+		MaxDiffBytes:       5 * 1024 * 1024, // 5MB
 		DefaultBranch:      "main",
 		BaseURL:            "",
 	}
@@ -49,6 +70,18 @@ func (c *Config) Validate() error {
 	if c.PresignedURLExpiry <= 0 {
 		c.PresignedURLExpiry = 1 * time.Hour
 	}
+	if c.LFSThreshold <= 0 {
+		c.LFSThreshold = 1024 * 1024
+	}
+	if c.MaxFileSize <= 0 {
+		c.MaxFileSize = 100 * 1024 * 1024
+	}
+	if c.MaxDiffFiles <= 0 {
+		c.MaxDiffFiles = 1000
+	}
+	if c.MaxDiffBytes <= 0 {
+		c.MaxDiffBytes = 5 * 1024 * 1024
+	}
 	if c.DefaultBranch == "" {
 		c.DefaultBranch = "main"
 	}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 
@@ -23,11 +24,15 @@ type Domain struct {
 	repoDB       outbound.GitRepoDatabasePort
 	collabDB     outbound.GitCollaboratorDatabasePort
 	prDB         outbound.GitPullRequestDatabasePort
+	reviewDB     outbound.GitPRReviewDatabasePort
+	protectionDB outbound.GitBranchProtectionDatabasePort
 	lfsObjDB     outbound.GitLFSObjectDatabasePort
 	lfsLockDB    outbound.GitLFSLockDatabasePort
 	storage      outbound.GitStoragePort
 	lfsStorage   outbound.GitLFSStoragePort
 	quotaChecker inbound.GitStorageQuotaChecker
+	teamAccess   outbound.GitTeamAccessPort
+	events       outbound.EventPublisherPort
 	cfg          *Config
 	logger       *zap.Logger
 }
@@ -37,11 +42,15 @@ func NewDomain(
 	repoDB outbound.GitRepoDatabasePort,
 	collabDB outbound.GitCollaboratorDatabasePort,
 	prDB outbound.GitPullRequestDatabasePort,
+	reviewDB outbound.GitPRReviewDatabasePort,
+	protectionDB outbound.GitBranchProtectionDatabasePort,
 	lfsObjDB outbound.GitLFSObjectDatabasePort,
 	lfsLockDB outbound.GitLFSLockDatabasePort,
 	storage outbound.GitStoragePort,
 	lfsStorage outbound.GitLFSStoragePort,
 	quotaChecker inbound.GitStorageQuotaChecker,
+	teamAccess outbound.GitTeamAccessPort,
+	events outbound.EventPublisherPort,
 	cfg *Config,
 	logger *zap.Logger,
 ) *Domain {
@@ -54,11 +63,15 @@ func NewDomain(
 		repoDB:       repoDB,
 		collabDB:     collabDB,
 		prDB:         prDB,
+		reviewDB:     reviewDB,
+		protectionDB: protectionDB,
 		lfsObjDB:     lfsObjDB,
 		lfsLockDB:    lfsLockDB,
 		storage:      storage,
 		lfsStorage:   lfsStorage,
 		quotaChecker: quotaChecker,
+		teamAccess:   teamAccess,
+		events:       events,
 		cfg:          cfg,
 		logger:       logger,
 	}
@@ -81,18 +94,8 @@ func (d *Domain) CreateRepo(ctx context.Context, ownerID uuid.UUID, input *inbou
 	}
 
 	// Check storage quota
-	if d.quotaChecker != nil {
-		quota, err := d.quotaChecker.GetStorageQuota(ctx, ownerID)
-		if err != nil {
-			d.logger.Warn("failed to get storage quota", zap.Error(err))
-		} else if quota > 0 {
-			used, err := d.quotaChecker.GetStorageUsed(ctx, ownerID)
-			if err != nil {
-				d.logger.Warn("failed to get storage used", zap.Error(err))
-			} else if used >= quota {
-				return nil, ErrStorageQuotaExceeded
-			}
-		}
+	if err := d.checkStorageQuota(ctx, ownerID, 0); err != nil {
+		return nil, err
 	}
 
 	// Create repository record
@@ -311,6 +314,13 @@ func (d *Domain) UpdateRepo(ctx context.Context, id uuid.UUID, userID uuid.UUID,
 		repo.LFSEnabled = *input.LFSEnabled
 	}
 
+	if input.RequiredApprovals != nil {
+		if *input.RequiredApprovals < 0 || *input.RequiredApprovals > maxRequiredApprovals {
+			return nil, ErrInvalidRequiredApprovals
+		}
+		repo.RequiredApprovals = *input.RequiredApprovals
+	}
+
 	if err := d.repoDB.Update(ctx, repo); err != nil {
 		return nil, fmt.Errorf("update repo: %w", err)
 	}
@@ -390,6 +400,17 @@ func (d *Domain) CanAccess(ctx context.Context, repoID uuid.UUID, userID *uuid.U
 		return true, nil
 	}
 
+	// Team repos grant access by team role
+	if repo.TeamID != nil && d.teamAccess != nil {
+		role, err := d.teamAccess.GetTeamRole(ctx, *repo.TeamID, *userID)
+		if err != nil {
+			return false, err
+		}
+		if granted, ok := teamPermission(role); ok && hasPermission(granted, required) {
+			return true, nil
+		}
+	}
+
 	// Check collaborator permission
 	collab, err := d.collabDB.FindByRepoAndUser(ctx, repoID, *userID)
 	if err != nil {
@@ -423,6 +444,21 @@ func hasPermission(granted, required model.GitPermission) bool {
 	return grantedLevel >= requiredLevel
 }
 
+// teamPermission maps a team role to the permission it grants on the team's
+// repositories.
+func teamPermission(role model.TeamRole) (model.GitPermission, bool) {
+	switch role {
+	case model.TeamRoleOwner, model.TeamRoleAdmin:
+		return model.GitPermissionAdmin, true
+	case model.TeamRoleMember:
+		return model.GitPermissionWrite, true
+	case model.TeamRoleGuest:
+		return model.GitPermissionRead, true
+	default:
+		return "", false
+	}
+}
+
 // ===== Collaborator Operations =====
 
 // AddCollaborator adds a collaborator to a repository.
@@ -523,8 +559,14 @@ func (d *Domain) CreatePR(ctx context.Context, repoID, authorID uuid.UUID, input
 		return nil, err
 	}
 
-	// Validate branches
-	if input.SourceBranch == input.TargetBranch {
+	// Validate branches; a fork may propose a branch of the same name
+	var sourceRepoID *uuid.UUID
+	if input.SourceRepoID != nil && *input.SourceRepoID != repoID {
+		if err := d.checkForkSource(ctx, repoID, *input.SourceRepoID, authorID); err != nil {
+			return nil, err
+		}
+		sourceRepoID = input.SourceRepoID
+	} else if input.SourceBranch == input.TargetBranch {
 		return nil, ErrSameBranch
 	}
 
@@ -540,6 +582,7 @@ func (d *Domain) CreatePR(ctx context.Context, repoID, authorID uuid.UUID, input
 		Number:       number,
 		Title:        input.Title,
 		Description:  input.Description,
+		SourceRepoID: sourceRepoID,
 		SourceBranch: input.SourceBranch,
 		TargetBranch: input.TargetBranch,
 		Status:       model.GitPRStatusOpen,
@@ -616,41 +659,217 @@ func (d *Domain) UpdatePR(ctx context.Context, repoID uuid.UUID, number int, use
 	return pr, nil
 }
 
-// MergePR merges a pull request.
-func (d *Domain) MergePR(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID) (*model.GitPullRequest, error) {
+// MergePR merges a pull request into its target branch with the requested
+// strategy and records the resulting commit.
+func (d *Domain) MergePR(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID, input *inbound.GitMergePRInput) (*model.GitPullRequest, error) {
 	// Check write access
 	if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionWrite); err != nil {
 		return nil, err
 	}
 
-	pr, err := d.prDB.FindByNumber(ctx, repoID, number)
+	if input == nil {
+		input = &inbound.GitMergePRInput{}
+	}
+	strategy := input.Strategy
+	if strategy == "" {
+		strategy = model.GitMergeStrategyMerge
+	}
+	if !strategy.IsValid() {
+		return nil, ErrInvalidMergeStrategy
+	}
+
+	pr, err := d.openPR(ctx, repoID, number)
 	if err != nil {
 		return nil, err
 	}
-	if pr == nil {
-		return nil, ErrPRNotFound
+
+	repo, err := d.GetRepo(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := d.branchRule(ctx, repoID, pr.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.checkRestrictedPush(ctx, repoID, userID, rule); err != nil {
+		return nil, err
 	}
 
-	if pr.Status.IsMerged() {
-		return nil, ErrPRAlreadyMerged
+	fs, err := d.storage.GetFilesystem(ctx, repo.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("get filesystem: %w", err)
 	}
-	if pr.Status == model.GitPRStatusClosed {
-		return nil, ErrPRAlreadyClosed
+	st := openObjectStorage(fs)
+
+	sourceRef, err := d.prSourceRef(ctx, pr, st)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := planMerge(st, sourceRef, pr.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.checkApprovals(ctx, pr, plan.source.Hash.String(), requiredApprovals(repo, rule)); err != nil {
+		return nil, err
+	}
+	if input.ExpectedTargetSHA != "" && input.ExpectedTargetSHA != plan.target.Hash.String() {
+		return nil, ErrTargetBranchMoved
+	}
+	if plan.upToDate {
+		return nil, ErrNothingToMerge
+	}
+
+	message := input.CommitMessage
+	if message == "" {
+		message = defaultMergeMessage(pr, strategy)
+	}
+
+	head, err := mergeHead(st, plan, strategy, commitSignature(input.AuthorName, input.AuthorEmail, userID), message)
+	if err != nil {
+		return nil, err
+	}
+	if err := updateBranch(st, pr.TargetBranch, plan.target.Hash, head); err != nil {
+		return nil, err
 	}
 
-	// TODO: Perform actual Git merge operation using go-git
 	now := time.Now()
 	pr.Status = model.GitPRStatusMerged
 	pr.MergedBy = &userID
 	pr.MergedAt = &now
+	pr.MergeCommitSHA = head.String()
 
 	if err := d.prDB.Update(ctx, pr); err != nil {
 		return nil, fmt.Errorf("update PR: %w", err)
 	}
 
+	d.logger.Info("pull request merged",
+		zap.String("repo_id", repoID.String()),
+		zap.Int("number", number),
+		zap.String("strategy", string(strategy)),
+		zap.String("commit", pr.MergeCommitSHA),
+	)
+
 	return pr, nil
 }
 
+// CheckMergeability reports whether a pull request can be merged without
+// changing any branch, including whether it has the approvals the
+// repository and the protection of the target branch require.
+func (d *Domain) CheckMergeability(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID) (*inbound.GitMergeability, error) {
+	if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionRead); err != nil {
+		return nil, err
+	}
+
+	pr, err := d.openPR(ctx, repoID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := d.GetRepo(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := d.branchRule(ctx, repoID, pr.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	fs, err := d.storage.GetFilesystem(ctx, repo.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("get filesystem: %w", err)
+	}
+	st := openObjectStorage(fs)
+
+	// Only read: the branch of a fork isn't copied until the merge
+	source, err := d.prSourceHead(ctx, pr, st)
+	if err != nil {
+		return nil, err
+	}
+	target, err := branchCommit(st, pr.TargetBranch)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := newMergePlan(source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &inbound.GitMergeability{
+		CanFastForward: plan.fastForward && !plan.upToDate,
+		BaseSHA:        plan.base.Hash.String(),
+		SourceSHA:      plan.source.Hash.String(),
+		TargetSHA:      plan.target.Hash.String(),
+	}
+	switch {
+	case plan.upToDate:
+	case plan.fastForward:
+		result.Mergeable = true
+	default:
+		merge, err := mergedEntries(plan)
+		if err != nil {
+			return nil, err
+		}
+		result.Conflicts = merge.conflicts
+		result.Mergeable = len(merge.conflicts) == 0
+	}
+
+	result.RequiredApprovals = requiredApprovals(repo, rule)
+	if result.RequiredApprovals > 0 {
+		reviews, err := d.reviewDB.FindReviews(ctx, pr.ID)
+		if err != nil {
+			return nil, fmt.Errorf("list reviews: %w", err)
+		}
+		result.Approvals, result.ChangesRequested = reviewVerdicts(reviews, result.SourceSHA)
+		if result.ChangesRequested || result.Approvals < result.RequiredApprovals {
+			result.Mergeable = false
+		}
+	}
+
+	return result, nil
+}
+
+// openPR returns a pull request that is still open.
+func (d *Domain) openPR(ctx context.Context, repoID uuid.UUID, number int) (*model.GitPullRequest, error) {
+	pr, err := d.prDB.FindByNumber(ctx, repoID, number)
+	if err != nil {
+		return nil, err
+	}
+	if pr == nil {
+		return nil, ErrPRNotFound
+	}
+
+	if pr.Status.IsMerged() {
+		return nil, ErrPRAlreadyMerged
+	}
+	if pr.Status == model.GitPRStatusClosed {
+		return nil, ErrPRAlreadyClosed
+	}
+
+	return pr, nil
+}
+
+func defaultMergeMessage(pr *model.GitPullRequest, strategy model.GitMergeStrategy) string {
+	var message string
+	if strategy == model.GitMergeStrategySquash {
+		message = fmt.Sprintf("%s (#%d)", pr.Title, pr.Number)
+	} else {
+		message = fmt.Sprintf("Merge pull request #%d from %s\n\n%s", pr.Number, pr.SourceBranch, pr.Title)
+	}
+	if pr.Description != "" {
+		message += "\n\n" + pr.Description
+	}
+	return message + "\n"
+}
+
+// commitSignature returns the author of commits written by the server.
+// Users without a name are identified by their ID.
+func commitSignature(name, email string, userID uuid.UUID) object.Signature {
+	if name == "" {
+		name = userID.String()
+	}
+	return object.Signature{Name: name, Email: email, When: time.Now()}
+}
+
 // ===== Storage Operations =====
 
 // GetStorageStats returns storage statistics for a repository.
@@ -699,7 +918,7 @@ func (d *Domain) GetUserStorageStats(ctx context.Context, userID uuid.UUID) (*in
 	}
 
 	remaining := int64(-1)
-	if quota > 0 {
+	if quota >= 0 {
 		remaining = quota - totalUsed
 		if remaining < 0 {
 			remaining = 0
@@ -714,6 +933,33 @@ func (d *Domain) GetUserStorageStats(ctx context.Context, userID uuid.UUID) (*in
 	}, nil
 }
 
+// checkStorageQuota fails when the owner's storage can't take incoming
+// more bytes. Quota lookups that fail don't block the caller.
+func (d *Domain) checkStorageQuota(ctx context.Context, ownerID uuid.UUID, incoming int64) error {
+	if d.quotaChecker == nil {
+		return nil
+	}
+
+	quota, err := d.quotaChecker.GetStorageQuota(ctx, ownerID)
+	if err != nil {
+		d.logger.Warn("failed to get storage quota", zap.Error(err))
+		return nil
+	}
+	if quota < 0 {
+		return nil
+	}
+
+	used, err := d.quotaChecker.GetStorageUsed(ctx, ownerID)
+	if err != nil {
+		d.logger.Warn("failed to get storage used", zap.Error(err))
+		return nil
+	}
+	if used >= quota || used+incoming > quota {
+		return ErrStorageQuotaExceeded
+	}
+	return nil
+}
+
 // GetFilesystem returns a billy.Filesystem for a repository.
 func (d *Domain) GetFilesystem(ctx context.Context, repoID uuid.UUID) (billy.Filesystem, error) {
 	repo, err := d.repoDB.FindByID(ctx, repoID)
@@ -775,5 +1021,15 @@ func convertFilter(filter *inbound.GitRepoFilter) *outbound.GitRepoFilter {
 	}
 }
 
+// --- Domain Events ---
+
+// RepoPushedEvent is published after a push to a repository is applied.
+type RepoPushedEvent struct {
+	RepoID   uuid.UUID
+	OwnerID  uuid.UUID
+	PusherID uuid.UUID
+	Refs     []*inbound.GitRefUpdate
+}
+
 // Compile-time interface check
 var _ inbound.GitDomain = (*Domain)(nil)
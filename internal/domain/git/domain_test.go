@@ -8,9 +8,11 @@ import (
 
 	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/uniedit/server/internal/model"
 	"github.com/uniedit/server/internal/port/inbound"
 	"github.com/uniedit/server/internal/port/outbound"
@@ -69,6 +71,11 @@ func (m *MockGitRepoDB) UpdatePushedAt(ctx context.Context, id uuid.UUID, pushed
 	return args.Error(0)
 }
 
+func (m *MockGitRepoDB) GetOwnerStorage(ctx context.Context, ownerID uuid.UUID) (int64, int64, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).(int64), args.Get(1).(int64), args.Error(2)
+}
+
 func (m *MockGitRepoDB) UpdateSize(ctx context.Context, id uuid.UUID, sizeBytes, lfsSizeBytes int64) error {
 	args := m.Called(ctx, id, sizeBytes, lfsSizeBytes)
 	return args.Error(0)
@@ -157,6 +164,14 @@ func (m *MockGitPRDB) FindByRepo(ctx context.Context, repoID uuid.UUID, status *
 	return args.Get(0).([]*model.GitPullRequest), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockGitPRDB) FindOpenBySourceBranch(ctx context.Context, sourceRepoID uuid.UUID, branch string) ([]*model.GitPullRequest, error) {
+	args := m.Called(ctx, sourceRepoID, branch)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.GitPullRequest), args.Error(1)
+}
+
 func (m *MockGitPRDB) Update(ctx context.Context, pr *model.GitPullRequest) error {
 	args := m.Called(ctx, pr)
 	return args.Error(0)
@@ -167,6 +182,107 @@ func (m *MockGitPRDB) GetNextNumber(ctx context.Context, repoID uuid.UUID) (int,
 	return args.Int(0), args.Error(1)
 }
 
+type MockGitPRReviewDB struct {
+	mock.Mock
+}
+
+func (m *MockGitPRReviewDB) CreateReview(ctx context.Context, review *model.GitPRReview, comments []*model.GitPRComment) error {
+	args := m.Called(ctx, review, comments)
+	return args.Error(0)
+}
+
+func (m *MockGitPRReviewDB) FindReviews(ctx context.Context, prID uuid.UUID) ([]*model.GitPRReview, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.GitPRReview), args.Error(1)
+}
+
+func (m *MockGitPRReviewDB) CreateComment(ctx context.Context, comment *model.GitPRComment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *MockGitPRReviewDB) FindCommentByID(ctx context.Context, id uuid.UUID) (*model.GitPRComment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.GitPRComment), args.Error(1)
+}
+
+func (m *MockGitPRReviewDB) FindComments(ctx context.Context, prID uuid.UUID) ([]*model.GitPRComment, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.GitPRComment), args.Error(1)
+}
+
+func (m *MockGitPRReviewDB) UpdateComment(ctx context.Context, comment *model.GitPRComment) error {
+	args := m.Called(ctx, comment)
+	return args.Error(0)
+}
+
+func (m *MockGitPRReviewDB) DeleteComment(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockGitPRReviewDB) AddReviewRequest(ctx context.Context, request *model.GitPRReviewRequest) error {
+	args := m.Called(ctx, request)
+	return args.Error(0)
+}
+
+func (m *MockGitPRReviewDB) RemoveReviewRequest(ctx context.Context, prID, reviewerID uuid.UUID) error {
+	args := m.Called(ctx, prID, reviewerID)
+	return args.Error(0)
+}
+
+func (m *MockGitPRReviewDB) FindReviewRequests(ctx context.Context, prID uuid.UUID) ([]*model.GitPRReviewRequest, error) {
+	args := m.Called(ctx, prID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.GitPRReviewRequest), args.Error(1)
+}
+
+type MockGitBranchProtectionDB struct {
+	mock.Mock
+}
+
+func (m *MockGitBranchProtectionDB) Create(ctx context.Context, rule *model.GitBranchProtection) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockGitBranchProtectionDB) FindByID(ctx context.Context, id uuid.UUID) (*model.GitBranchProtection, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.GitBranchProtection), args.Error(1)
+}
+
+func (m *MockGitBranchProtectionDB) FindByRepo(ctx context.Context, repoID uuid.UUID) ([]*model.GitBranchProtection, error) {
+	args := m.Called(ctx, repoID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.GitBranchProtection), args.Error(1)
+}
+
+func (m *MockGitBranchProtectionDB) Update(ctx context.Context, rule *model.GitBranchProtection) error {
+	args := m.Called(ctx, rule)
+	return args.Error(0)
+}
+
+func (m *MockGitBranchProtectionDB) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
 type MockGitLFSObjDB struct {
 	mock.Mock
 }
@@ -189,6 +305,11 @@ func (m *MockGitLFSObjDB) Link(ctx context.Context, repoID uuid.UUID, oid string
 	return args.Error(0)
 }
 
+func (m *MockGitLFSObjDB) IsLinked(ctx context.Context, repoID uuid.UUID, oid string) (bool, error) {
+	args := m.Called(ctx, repoID, oid)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockGitLFSObjDB) Unlink(ctx context.Context, repoID uuid.UUID, oid string) error {
 	args := m.Called(ctx, repoID, oid)
 	return args.Error(0)
@@ -273,6 +394,11 @@ func (m *MockGitStorage) GetRepositorySize(ctx context.Context, path string) (in
 	return args.Get(0).(int64), args.Error(1)
 }
 
+func (m *MockGitStorage) CopyRepository(ctx context.Context, srcPath, dstPath string) error {
+	args := m.Called(ctx, srcPath, dstPath)
+	return args.Error(0)
+}
+
 type MockGitLFSStorage struct {
 	mock.Mock
 }
@@ -300,6 +426,11 @@ func (m *MockGitLFSStorage) Delete(ctx context.Context, oid string) error {
 	return args.Error(0)
 }
 
+func (m *MockGitLFSStorage) Copy(ctx context.Context, src, dst string) error {
+	args := m.Called(ctx, src, dst)
+	return args.Error(0)
+}
+
 func (m *MockGitLFSStorage) GenerateUploadURL(ctx context.Context, oid string, size int64, expiry time.Duration) (*outbound.GitPresignedURL, error) {
 	args := m.Called(ctx, oid, size, expiry)
 	if args.Get(0) == nil {
@@ -316,6 +447,48 @@ func (m *MockGitLFSStorage) GenerateDownloadURL(ctx context.Context, oid string,
 	return args.Get(0).(*outbound.GitPresignedURL), args.Error(1)
 }
 
+type MockGitTeamAccess struct {
+	mock.Mock
+}
+
+func (m *MockGitTeamAccess) GetTeamRole(ctx context.Context, teamID, userID uuid.UUID) (model.TeamRole, error) {
+	args := m.Called(ctx, teamID, userID)
+	return args.Get(0).(model.TeamRole), args.Error(1)
+}
+
+type MockGitQuotaChecker struct {
+	mock.Mock
+}
+
+func (m *MockGitQuotaChecker) GetStorageQuota(ctx context.Context, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockGitQuotaChecker) GetStorageUsed(ctx context.Context, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockGitQuotaChecker) GetLFSStorageQuota(ctx context.Context, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockGitQuotaChecker) GetLFSStorageUsed(ctx context.Context, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) Publish(ctx context.Context, event interface{}) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
 // --- Tests ---
 
 func TestDomain_CreateRepo(t *testing.T) {
@@ -331,10 +504,14 @@ func TestDomain_CreateRepo(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
 			mockStorage,
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -374,6 +551,10 @@ func TestDomain_CreateRepo(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -410,6 +591,10 @@ func TestDomain_CreateRepo(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -441,6 +626,10 @@ func TestDomain_GetRepo(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -471,6 +660,10 @@ func TestDomain_GetRepo(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -497,10 +690,14 @@ func TestDomain_DeleteRepo(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
 			mockStorage,
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -536,6 +733,10 @@ func TestDomain_DeleteRepo(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -571,6 +772,10 @@ func TestDomain_CanAccess(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -603,6 +808,10 @@ func TestDomain_CanAccess(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -636,6 +845,10 @@ func TestDomain_CanAccess(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -675,6 +888,10 @@ func TestDomain_CanAccess(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -718,6 +935,10 @@ func TestDomain_AddCollaborator(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -751,6 +972,10 @@ func TestDomain_AddCollaborator(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -782,6 +1007,10 @@ func TestDomain_AddCollaborator(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -818,6 +1047,10 @@ func TestDomain_CreatePR(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -862,6 +1095,10 @@ func TestDomain_CreatePR(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -888,56 +1125,167 @@ func TestDomain_CreatePR(t *testing.T) {
 	})
 }
 
-func TestDomain_MergePR(t *testing.T) {
-	logger := zap.NewNop()
-
-	t.Run("success", func(t *testing.T) {
-		mockRepoDB := new(MockGitRepoDB)
-		mockCollabDB := new(MockGitCollabDB)
-		mockPRDB := new(MockGitPRDB)
+// newMergeTestDomain returns a domain serving repo as the repository of an
+// open pull request from feature into main, opened by the repository owner.
+func newMergeTestDomain(t *testing.T, repo *testRepo) (*Domain, *MockGitPRDB, *model.GitPullRequest) {
+	mockRepoDB := new(MockGitRepoDB)
+	mockPRDB := new(MockGitPRDB)
+	mockProtectionDB := new(MockGitBranchProtectionDB)
+	mockStorage := new(MockGitStorage)
+
+	domain := NewDomain(
+		mockRepoDB,
+		nil,
+		mockPRDB,
+		nil,
+		mockProtectionDB,
+		nil,
+		nil,
+		mockStorage,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		zap.NewNop(),
+	)
+
+	repoID := uuid.New()
+	ownerID := uuid.New()
+	pr := &model.GitPullRequest{
+		ID:           uuid.New(),
+		RepoID:       repoID,
+		Number:       1,
+		Title:        "Add feature",
+		Status:       model.GitPRStatusOpen,
+		SourceBranch: "feature",
+		TargetBranch: "main",
+		AuthorID:     ownerID,
+	}
 
-		domain := NewDomain(
-			mockRepoDB,
-			mockCollabDB,
-			mockPRDB,
-			nil,
-			nil,
-			nil,
-			nil,
-			nil,
-			nil,
-			logger,
-		)
+	mockRepoDB.On("FindByID", mock.Anything, repoID).Return(&model.GitRepo{
+		ID:          repoID,
+		OwnerID:     ownerID,
+		Visibility:  model.GitVisibilityPrivate,
+		StoragePath: "repos/test",
+	}, nil)
+	mockPRDB.On("FindByNumber", mock.Anything, repoID, 1).Return(pr, nil)
+	mockProtectionDB.On("FindByRepo", mock.Anything, repoID).Return(nil, nil)
+	mockStorage.On("GetFilesystem", mock.Anything, "repos/test").Return(repo.fs, nil)
+
+	return domain, mockPRDB, pr
+}
 
-		repoID := uuid.New()
-		userID := uuid.New()
-		repo := &model.GitRepo{
-			ID:         repoID,
-			OwnerID:    userID,
-			Visibility: model.GitVisibilityPrivate,
-		}
-		pr := &model.GitPullRequest{
-			ID:           uuid.New(),
-			RepoID:       repoID,
-			Number:       1,
-			Status:       model.GitPRStatusOpen,
-			SourceBranch: "feature",
-			TargetBranch: "main",
-		}
+func TestDomain_MergePR(t *testing.T) {
+	ctx := context.Background()
+
+	// diverged has feature and main both changing different files
+	diverged := func(t *testing.T) *testRepo {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a": "1", "b": "1"})
+		repo.commit("feature", map[string]string{"a": "2", "b": "1"}, base)
+		repo.commit("main", map[string]string{"a": "1", "b": "2"}, base)
+		return repo
+	}
 
-		mockRepoDB.On("FindByID", mock.Anything, repoID).Return(repo, nil)
-		mockPRDB.On("FindByNumber", mock.Anything, repoID, 1).Return(pr, nil)
-		mockPRDB.On("Update", mock.Anything, mock.AnythingOfType("*model.GitPullRequest")).Return(nil)
+	t.Run("merge commit", func(t *testing.T) {
+		repo := diverged(t)
+		domain, mockPRDB, pr := newMergeTestDomain(t, repo)
+		userID := pr.AuthorID
+		target := repo.branch("main").Hash
+		source := repo.branch("feature").Hash
+		mockPRDB.On("Update", mock.Anything, pr).Return(nil)
 
-		mergedPR, err := domain.MergePR(context.Background(), repoID, 1, userID)
+		mergedPR, err := domain.MergePR(ctx, pr.RepoID, 1, userID, &inbound.GitMergePRInput{AuthorName: "Dev", AuthorEmail: "dev@example.com"})
 
-		assert.NoError(t, err)
+		require.NoError(t, err)
 		assert.Equal(t, model.GitPRStatusMerged, mergedPR.Status)
 		assert.NotNil(t, mergedPR.MergedAt)
 		assert.Equal(t, &userID, mergedPR.MergedBy)
+
+		head := repo.branch("main")
+		assert.Equal(t, head.Hash.String(), mergedPR.MergeCommitSHA)
+		assert.Equal(t, []plumbing.Hash{target, source}, head.ParentHashes)
+		assert.Equal(t, "dev@example.com", head.Author.Email)
+		assert.Contains(t, head.Message, "Merge pull request #1 from feature")
+		assert.Equal(t, map[string]string{"a": "2", "b": "2"}, repo.files(head))
+	})
+
+	t.Run("squash", func(t *testing.T) {
+		repo := diverged(t)
+		domain, mockPRDB, pr := newMergeTestDomain(t, repo)
+		userID := pr.AuthorID
+		target := repo.branch("main").Hash
+		mockPRDB.On("Update", mock.Anything, pr).Return(nil)
+
+		_, err := domain.MergePR(ctx, pr.RepoID, 1, userID, &inbound.GitMergePRInput{Strategy: model.GitMergeStrategySquash})
+
+		require.NoError(t, err)
+		head := repo.branch("main")
+		assert.Equal(t, []plumbing.Hash{target}, head.ParentHashes)
+		assert.Equal(t, "Add feature (#1)\n", head.Message)
+		assert.Equal(t, map[string]string{"a": "2", "b": "2"}, repo.files(head))
+	})
+
+	t.Run("fast-forward", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a": "1"})
+		source := repo.commit("feature", map[string]string{"a": "2"}, base)
+		domain, mockPRDB, pr := newMergeTestDomain(t, repo)
+		userID := pr.AuthorID
+		mockPRDB.On("Update", mock.Anything, pr).Return(nil)
+
+		mergedPR, err := domain.MergePR(ctx, pr.RepoID, 1, userID, &inbound.GitMergePRInput{Strategy: model.GitMergeStrategyFastForward})
+
+		require.NoError(t, err)
+		assert.Equal(t, source, repo.branch("main").Hash)
+		assert.Equal(t, source.String(), mergedPR.MergeCommitSHA)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a": "1"})
+		repo.commit("feature", map[string]string{"a": "2"}, base)
+		target := repo.commit("main", map[string]string{"a": "3"}, base)
+		domain, mockPRDB, pr := newMergeTestDomain(t, repo)
+		userID := pr.AuthorID
+
+		mergedPR, err := domain.MergePR(ctx, pr.RepoID, 1, userID, nil)
+
+		assert.ErrorIs(t, err, ErrMergeConflict)
+		var conflict *MergeConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, []string{"a"}, conflict.Paths)
+		assert.Nil(t, mergedPR)
+		assert.Equal(t, target, repo.branch("main").Hash)
+		mockPRDB.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("target moved", func(t *testing.T) {
+		repo := diverged(t)
+		target := repo.branch("main").Hash
+		domain, mockPRDB, pr := newMergeTestDomain(t, repo)
+		userID := pr.AuthorID
+
+		_, err := domain.MergePR(ctx, pr.RepoID, 1, userID, &inbound.GitMergePRInput{ExpectedTargetSHA: plumbing.ZeroHash.String()})
+
+		assert.ErrorIs(t, err, ErrTargetBranchMoved)
+		assert.Equal(t, target, repo.branch("main").Hash)
+		mockPRDB.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid strategy", func(t *testing.T) {
+		repo := diverged(t)
+		domain, _, pr := newMergeTestDomain(t, repo)
+		userID := pr.AuthorID
+
+		_, err := domain.MergePR(ctx, pr.RepoID, 1, userID, &inbound.GitMergePRInput{Strategy: "rebase"})
+
+		assert.ErrorIs(t, err, ErrInvalidMergeStrategy)
 	})
 
 	t.Run("already merged", func(t *testing.T) {
+		logger := zap.NewNop()
 		mockRepoDB := new(MockGitRepoDB)
 		mockPRDB := new(MockGitPRDB)
 
@@ -951,6 +1299,10 @@ func TestDomain_MergePR(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -971,13 +1323,49 @@ func TestDomain_MergePR(t *testing.T) {
 		mockRepoDB.On("FindByID", mock.Anything, repoID).Return(repo, nil)
 		mockPRDB.On("FindByNumber", mock.Anything, repoID, 1).Return(pr, nil)
 
-		mergedPR, err := domain.MergePR(context.Background(), repoID, 1, userID)
+		mergedPR, err := domain.MergePR(context.Background(), repoID, 1, userID, nil)
 
 		assert.ErrorIs(t, err, ErrPRAlreadyMerged)
 		assert.Nil(t, mergedPR)
 	})
 }
 
+func TestDomain_CheckMergeability(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("clean", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a": "1"})
+		repo.commit("feature", map[string]string{"a": "2"}, base)
+		domain, _, pr := newMergeTestDomain(t, repo)
+		userID := pr.AuthorID
+
+		result, err := domain.CheckMergeability(ctx, pr.RepoID, 1, userID)
+
+		require.NoError(t, err)
+		assert.True(t, result.Mergeable)
+		assert.True(t, result.CanFastForward)
+		assert.Empty(t, result.Conflicts)
+		assert.Equal(t, base.String(), result.TargetSHA)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a": "1", "b": "1"})
+		repo.commit("feature", map[string]string{"a": "2", "b": "1"}, base)
+		repo.commit("main", map[string]string{"a": "3", "b": "1"}, base)
+		domain, _, pr := newMergeTestDomain(t, repo)
+		userID := pr.AuthorID
+
+		result, err := domain.CheckMergeability(ctx, pr.RepoID, 1, userID)
+
+		require.NoError(t, err)
+		assert.False(t, result.Mergeable)
+		assert.False(t, result.CanFastForward)
+		assert.Equal(t, []string{"a"}, result.Conflicts)
+	})
+}
+
 func TestDomain_GetStorageStats(t *testing.T) {
 	logger := zap.NewNop()
 
@@ -989,12 +1377,16 @@ func TestDomain_GetStorageStats(t *testing.T) {
 			mockRepoDB,
 			nil,
 			nil,
+			nil,
+			nil,
 			mockLFSObjDB,
 			nil,
 			nil,
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -21,10 +21,11 @@ var (
 
 // LFS errors.
 var (
-	ErrLFSNotEnabled     = errors.New("LFS is not enabled for this repository")
-	ErrLFSObjectNotFound = errors.New("LFS object not found")
-	ErrLFSFileTooLarge   = errors.New("file size exceeds maximum allowed")
-	ErrLFSQuotaExceeded  = errors.New("LFS storage quota exceeded")
+	ErrLFSNotEnabled       = errors.New("LFS is not enabled for this repository")
+	ErrLFSObjectNotFound   = errors.New("LFS object not found")
+	ErrLFSFileTooLarge     = errors.New("file size exceeds maximum allowed")
+	ErrLFSQuotaExceeded    = errors.New("LFS storage quota exceeded")
+	ErrLFSChecksumMismatch = errors.New("LFS object content does not match its oid")
 )
 
 // Lock errors.
@@ -43,8 +44,83 @@ var (
 	ErrSameBranch      = errors.New("source and target branches are the same")
 )
 
+// Merge errors.
+var (
+	ErrBranchNotFound        = errors.New("branch not found")
+	ErrInvalidMergeStrategy  = errors.New("invalid merge strategy")
+	ErrMergeConflict         = errors.New("pull request has merge conflicts")
+	ErrFastForwardImpossible = errors.New("fast-forward merge is not possible")
+	ErrNothingToMerge        = errors.New("source branch has no new commits")
+	ErrUnrelatedHistories    = errors.New("branches have no common history")
+	ErrTargetBranchMoved     = errors.New("target branch has changed")
+)
+
+// MergeConflictError is an ErrMergeConflict that lists the conflicting
+// paths.
+type MergeConflictError struct {
+	Paths []string
+}
+
+func (e *MergeConflictError) Error() string { return ErrMergeConflict.Error() }
+func (e *MergeConflictError) Unwrap() error { return ErrMergeConflict }
+
+// ConflictPaths returns the conflicting paths, sorted.
+func (e *MergeConflictError) ConflictPaths() []string { return e.Paths }
+
+// Browsing errors.
+var (
+	ErrRefNotFound    = errors.New("ref not found")
+	ErrPathNotFound   = errors.New("path not found")
+	ErrNotADirectory  = errors.New("path is not a directory")
+	ErrNotAFile       = errors.New("path is not a file")
+	ErrCommitNotFound = errors.New("commit not found")
+)
+
+// Commit errors.
+var (
+	ErrEmptyCommit       = errors.New("commit has no changes")
+	ErrEmptyMessage      = errors.New("commit message is required")
+	ErrInvalidPath       = errors.New("invalid file path")
+	ErrInvalidFileAction = errors.New("invalid file action")
+	ErrFileExists        = errors.New("file already exists")
+)
+
+// Review errors.
+var (
+	ErrInvalidReviewState       = errors.New("invalid review state")
+	ErrSelfReview               = errors.New("cannot review your own pull request")
+	ErrInvalidReviewer          = errors.New("reviewer must have write access")
+	ErrEmptyComment             = errors.New("comment body is required")
+	ErrInvalidCommentLine       = errors.New("invalid comment line")
+	ErrCommentNotFound          = errors.New("comment not found")
+	ErrNotCommentAuthor         = errors.New("not the comment author")
+	ErrInvalidRequiredApprovals = errors.New("invalid required approvals")
+	ErrApprovalsRequired        = errors.New("pull request needs more approvals")
+	ErrChangesRequested         = errors.New("changes have been requested")
+)
+
+// Branch protection errors.
+var (
+	ErrInvalidBranchPattern      = errors.New("invalid branch pattern")
+	ErrBranchProtectionExists    = errors.New("branch protection already exists")
+	ErrBranchProtectionNotFound  = errors.New("branch protection not found")
+	ErrProtectedBranchRestricted = errors.New("protected branch is restricted to admins")
+	ErrPullRequestRequired       = errors.New("protected branch requires a pull request")
+	ErrProtectedBranchForcePush  = errors.New("cannot force-push to a protected branch")
+	ErrProtectedBranchDeletion   = errors.New("cannot delete a protected branch")
+)
+
+// Fork errors.
+var (
+	ErrNotTeamAdmin = errors.New("not a team admin")
+	ErrNotAFork     = errors.New("source repository is not a fork of this repository")
+)
+
 // Git protocol errors.
 var (
 	ErrInvalidService = errors.New("invalid git service")
 	ErrPushRejected   = errors.New("push rejected")
+	ErrFileTooLarge   = errors.New("file exceeds the size limit, track it with Git LFS")
+	ErrFileLocked     = errors.New("file is locked by another user")
+	ErrPushTooLarge   = errors.New("push introduces too many commits to check, push them in smaller batches")
 )
@@ -0,0 +1,312 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// ===== Fork Operations =====
+
+// ForkRepo creates a copy of a repository owned by the user, or shared with
+// a team the user administers. Git storage is copied object by object on the
+// storage side; LFS objects are shared by linking them to the fork.
+func (d *Domain) ForkRepo(ctx context.Context, id uuid.UUID, userID uuid.UUID, input *inbound.GitForkRepoInput) (*model.GitRepo, error) {
+	if input == nil {
+		input = &inbound.GitForkRepoInput{}
+	}
+
+	if err := d.CheckAccess(ctx, id, userID, model.GitPermissionRead); err != nil {
+		return nil, err
+	}
+	parent, err := d.GetRepo(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.TeamID != nil {
+		if err := d.checkTeamAdmin(ctx, *input.TeamID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	name := input.Name
+	if name == "" {
+		name = parent.Name
+	}
+	slug := generateSlug(name)
+	if !slugRegex.MatchString(slug) {
+		return nil, ErrInvalidRepoName
+	}
+	if existing, err := d.repoDB.FindByOwnerAndSlug(ctx, userID, slug); err == nil && existing != nil {
+		return nil, ErrRepoAlreadyExists
+	}
+
+	// LFS objects are linked to the fork, not copied, so only the git
+	// objects count against the quota.
+	if err := d.checkStorageQuota(ctx, userID, parent.SizeBytes); err != nil {
+		return nil, err
+	}
+
+	forkID := uuid.New()
+	fork := &model.GitRepo{
+		ID:            forkID,
+		OwnerID:       userID,
+		TeamID:        input.TeamID,
+		Name:          name,
+		Slug:          slug,
+		RepoType:      parent.RepoType,
+		Visibility:    parent.Visibility,
+		Description:   parent.Description,
+		DefaultBranch: parent.DefaultBranch,
+		SizeBytes:     parent.SizeBytes,
+		LFSEnabled:    parent.LFSEnabled,
+		LFSSizeBytes:  parent.LFSSizeBytes,
+		StoragePath:   fmt.Sprintf("%s%s/%s/", d.cfg.RepoPrefix, userID.String(), forkID.String()),
+		ForkedFrom:    &parent.ID,
+	}
+
+	if err := d.storage.CopyRepository(ctx, parent.StoragePath, fork.StoragePath); err != nil {
+		_ = d.storage.DeleteRepository(ctx, fork.StoragePath)
+		return nil, fmt.Errorf("copy repository: %w", err)
+	}
+
+	if err := d.repoDB.Create(ctx, fork); err != nil {
+		_ = d.storage.DeleteRepository(ctx, fork.StoragePath)
+		return nil, fmt.Errorf("create repo: %w", err)
+	}
+
+	if err := d.linkLFSObjects(ctx, parent.ID, forkID); err != nil {
+		// Deleting the record drops the links made so far
+		_ = d.repoDB.Delete(ctx, forkID)
+		_ = d.storage.DeleteRepository(ctx, fork.StoragePath)
+		return nil, err
+	}
+
+	if err := d.repoDB.IncrementForks(ctx, parent.ID, 1); err != nil {
+		d.logger.Warn("failed to increment fork count",
+			zap.String("repo_id", parent.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	d.logger.Info("repository forked",
+		zap.String("repo_id", forkID.String()),
+		zap.String("parent_id", parent.ID.String()),
+		zap.String("owner_id", userID.String()),
+	)
+
+	return fork, nil
+}
+
+// checkTeamAdmin ensures the user owns or administers a team.
+func (d *Domain) checkTeamAdmin(ctx context.Context, teamID, userID uuid.UUID) error {
+	if d.teamAccess == nil {
+		return ErrNotTeamAdmin
+	}
+
+	role, err := d.teamAccess.GetTeamRole(ctx, teamID, userID)
+	if err != nil {
+		return fmt.Errorf("get team role: %w", err)
+	}
+	if role != model.TeamRoleOwner && role != model.TeamRoleAdmin {
+		return ErrNotTeamAdmin
+	}
+	return nil
+}
+
+// linkLFSObjects makes every LFS object of one repository available to
+// another without copying its content.
+func (d *Domain) linkLFSObjects(ctx context.Context, fromRepoID, toRepoID uuid.UUID) error {
+	objects, err := d.lfsObjDB.FindByRepo(ctx, fromRepoID)
+	if err != nil {
+		return fmt.Errorf("list LFS objects: %w", err)
+	}
+
+	for _, obj := range objects {
+		if err := d.lfsObjDB.Link(ctx, toRepoID, obj.OID); err != nil {
+			return fmt.Errorf("link LFS object: %w", err)
+		}
+	}
+	return nil
+}
+
+// checkForkSource ensures a pull request into repoID may come from
+// sourceRepoID: it must be a fork of repoID the author can read.
+func (d *Domain) checkForkSource(ctx context.Context, repoID, sourceRepoID, authorID uuid.UUID) error {
+	source, err := d.GetRepo(ctx, sourceRepoID)
+	if err != nil {
+		return err
+	}
+	if source.ForkedFrom == nil || *source.ForkedFrom != repoID {
+		return ErrNotAFork
+	}
+	return d.CheckAccess(ctx, sourceRepoID, authorID, model.GitPermissionRead)
+}
+
+// prSourceRef returns the ref in st that holds the head of a pull request.
+// The branch of a fork is copied into st and kept as refs/pull/<n>/head, so
+// the target repository has every object a merge needs.
+func (d *Domain) prSourceRef(ctx context.Context, pr *model.GitPullRequest, st *filesystem.Storage) (plumbing.ReferenceName, error) {
+	if pr.SourceRepoID == nil || *pr.SourceRepoID == pr.RepoID {
+		return plumbing.NewBranchReferenceName(pr.SourceBranch), nil
+	}
+
+	forkSt, err := d.prForkStorage(ctx, pr)
+	if err != nil {
+		return "", err
+	}
+	head, err := branchCommit(forkSt, pr.SourceBranch)
+	if err != nil {
+		return "", err
+	}
+
+	if err := copyObjects(forkSt, st, head.Hash); err != nil {
+		return "", err
+	}
+
+	name := plumbing.ReferenceName(fmt.Sprintf("refs/pull/%d/head", pr.Number))
+	if err := st.SetReference(plumbing.NewHashReference(name, head.Hash)); err != nil {
+		return "", fmt.Errorf("set %s: %w", name, err)
+	}
+	return name, nil
+}
+
+// prSourceHead returns the head of a pull request's source branch without
+// writing to st. The branch of a fork is read through the objects of both
+// repositories, so it can be compared with the branches of st.
+func (d *Domain) prSourceHead(ctx context.Context, pr *model.GitPullRequest, st *filesystem.Storage) (*object.Commit, error) {
+	if pr.SourceRepoID == nil || *pr.SourceRepoID == pr.RepoID {
+		return branchCommit(st, pr.SourceBranch)
+	}
+
+	forkSt, err := d.prForkStorage(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	head, err := branchCommit(forkSt, pr.SourceBranch)
+	if err != nil {
+		return nil, err
+	}
+	return object.GetCommit(&overlayObjects{EncodedObjectStorer: st, fallback: forkSt}, head.Hash)
+}
+
+// prForkStorage opens the object storage of the fork a pull request comes
+// from.
+func (d *Domain) prForkStorage(ctx context.Context, pr *model.GitPullRequest) (*filesystem.Storage, error) {
+	fork, err := d.repoDB.FindByID(ctx, *pr.SourceRepoID)
+	if err != nil {
+		return nil, err
+	}
+	if fork == nil {
+		// The fork was deleted along with the branch
+		return nil, ErrBranchNotFound
+	}
+
+	fs, err := d.storage.GetFilesystem(ctx, fork.StoragePath)
+	if err != nil {
+		return nil, fmt.Errorf("get fork filesystem: %w", err)
+	}
+	return openObjectStorage(fs), nil
+}
+
+// overlayObjects reads objects from one storage and those it lacks from
+// another. Writes go to the first.
+type overlayObjects struct {
+	storer.EncodedObjectStorer
+	fallback storer.EncodedObjectStorer
+}
+
+func (o *overlayObjects) EncodedObject(t plumbing.ObjectType, h plumbing.Hash) (plumbing.EncodedObject, error) {
+	obj, err := o.EncodedObjectStorer.EncodedObject(t, h)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return o.fallback.EncodedObject(t, h)
+	}
+	return obj, err
+}
+
+func (o *overlayObjects) HasEncodedObject(h plumbing.Hash) error {
+	if err := o.EncodedObjectStorer.HasEncodedObject(h); !errors.Is(err, plumbing.ErrObjectNotFound) {
+		return err
+	}
+	return o.fallback.HasEncodedObject(h)
+}
+
+func (o *overlayObjects) EncodedObjectSize(h plumbing.Hash) (int64, error) {
+	size, err := o.EncodedObjectStorer.EncodedObjectSize(h)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return o.fallback.EncodedObjectSize(h)
+	}
+	return size, err
+}
+
+// copyObjects copies a commit and everything reachable from it from src to
+// dst, stopping at objects dst already has. Objects are written after the
+// objects they reference, so an interrupted copy never leaves dst with an
+// object whose references are missing.
+func copyObjects(src, dst storage.Storer, commit plumbing.Hash) error {
+	type visit struct {
+		hash plumbing.Hash
+		done bool // references have been visited
+	}
+
+	seen := make(map[plumbing.Hash]bool)
+	pending := []visit{{hash: commit}}
+
+	for len(pending) > 0 {
+		v := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if !v.done && (seen[v.hash] || dst.HasEncodedObject(v.hash) == nil) {
+			continue
+		}
+
+		obj, err := src.EncodedObject(plumbing.AnyObject, v.hash)
+		if err != nil {
+			return fmt.Errorf("read object %s: %w", v.hash, err)
+		}
+		if v.done {
+			if _, err := dst.SetEncodedObject(obj); err != nil {
+				return fmt.Errorf("write object %s: %w", v.hash, err)
+			}
+			continue
+		}
+		seen[v.hash] = true
+		pending = append(pending, visit{hash: v.hash, done: true})
+
+		switch obj.Type() {
+		case plumbing.CommitObject:
+			c, err := object.DecodeCommit(src, obj)
+			if err != nil {
+				return fmt.Errorf("decode commit %s: %w", v.hash, err)
+			}
+			pending = append(pending, visit{hash: c.TreeHash})
+			for _, parent := range c.ParentHashes {
+				pending = append(pending, visit{hash: parent})
+			}
+		case plumbing.TreeObject:
+			t, err := object.DecodeTree(src, obj)
+			if err != nil {
+				return fmt.Errorf("decode tree %s: %w", v.hash, err)
+			}
+			for _, entry := range t.Entries {
+				if entry.Mode != filemode.Submodule {
+					pending = append(pending, visit{hash: entry.Hash})
+				}
+			}
+		}
+	}
+	return nil
+}
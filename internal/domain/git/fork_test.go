@@ -0,0 +1,354 @@
+package git
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+type forkTestDeps struct {
+	domain     *Domain
+	repoDB     *MockGitRepoDB
+	prDB       *MockGitPRDB
+	lfsObjDB   *MockGitLFSObjDB
+	storage    *MockGitStorage
+	teamAccess *MockGitTeamAccess
+	parent     *model.GitRepo
+}
+
+// newForkTestDomain returns a domain holding a public parent repository.
+func newForkTestDomain(t *testing.T) *forkTestDeps {
+	deps := &forkTestDeps{
+		repoDB:     new(MockGitRepoDB),
+		prDB:       new(MockGitPRDB),
+		lfsObjDB:   new(MockGitLFSObjDB),
+		storage:    new(MockGitStorage),
+		teamAccess: new(MockGitTeamAccess),
+	}
+	deps.domain = NewDomain(
+		deps.repoDB,
+		new(MockGitCollabDB),
+		deps.prDB,
+		nil,
+		nil,
+		deps.lfsObjDB,
+		nil,
+		deps.storage,
+		nil,
+		nil,
+		deps.teamAccess,
+		nil,
+		nil,
+		zap.NewNop(),
+	)
+
+	parentID := uuid.New()
+	deps.parent = &model.GitRepo{
+		ID:            parentID,
+		OwnerID:       uuid.New(),
+		Name:          "Game Assets",
+		Slug:          "game-assets",
+		RepoType:      model.GitRepoTypeCode,
+		Visibility:    model.GitVisibilityPublic,
+		DefaultBranch: "main",
+		SizeBytes:     2048,
+		LFSEnabled:    true,
+		LFSSizeBytes:  4096,
+		StoragePath:   "repos/parent/",
+	}
+	deps.repoDB.On("FindByID", mock.Anything, parentID).Return(deps.parent, nil)
+
+	return deps
+}
+
+func TestDomain_ForkRepo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		deps := newForkTestDomain(t)
+		userID := uuid.New()
+		var fork *model.GitRepo
+
+		deps.repoDB.On("FindByOwnerAndSlug", mock.Anything, userID, "game-assets").Return(nil, nil)
+		deps.storage.On("CopyRepository", mock.Anything, "repos/parent/", mock.AnythingOfType("string")).Return(nil)
+		deps.repoDB.On("Create", mock.Anything, mock.AnythingOfType("*model.GitRepo")).
+			Run(func(args mock.Arguments) { fork = args.Get(1).(*model.GitRepo) }).Return(nil)
+		deps.lfsObjDB.On("FindByRepo", mock.Anything, deps.parent.ID).
+			Return([]*model.GitLFSObject{{OID: "aaa"}, {OID: "bbb"}}, nil)
+		deps.lfsObjDB.On("Link", mock.Anything, mock.Anything, "aaa").Return(nil)
+		deps.lfsObjDB.On("Link", mock.Anything, mock.Anything, "bbb").Return(nil)
+		deps.repoDB.On("IncrementForks", mock.Anything, deps.parent.ID, 1).Return(nil)
+
+		repo, err := deps.domain.ForkRepo(ctx, deps.parent.ID, userID, nil)
+
+		require.NoError(t, err)
+		assert.Same(t, fork, repo)
+		assert.Equal(t, userID, repo.OwnerID)
+		assert.Nil(t, repo.TeamID)
+		assert.Equal(t, &deps.parent.ID, repo.ForkedFrom)
+		assert.Equal(t, "game-assets", repo.Slug)
+		assert.Equal(t, int64(2048), repo.SizeBytes)
+		assert.True(t, repo.LFSEnabled)
+		assert.Equal(t, "repos/"+userID.String()+"/"+repo.ID.String()+"/", repo.StoragePath)
+		deps.storage.AssertCalled(t, "CopyRepository", mock.Anything, "repos/parent/", repo.StoragePath)
+		deps.lfsObjDB.AssertCalled(t, "Link", mock.Anything, repo.ID, "aaa")
+		deps.lfsObjDB.AssertCalled(t, "Link", mock.Anything, repo.ID, "bbb")
+		deps.repoDB.AssertExpectations(t)
+	})
+
+	t.Run("quota counts git objects only", func(t *testing.T) {
+		deps := newForkTestDomain(t)
+		userID := uuid.New()
+		quota := new(MockGitQuotaChecker)
+		deps.domain.quotaChecker = quota
+
+		// Room for the parent's 2048 bytes of git objects but not its LFS
+		quota.On("GetStorageQuota", mock.Anything, userID).Return(int64(3000), nil)
+		quota.On("GetStorageUsed", mock.Anything, userID).Return(int64(500), nil)
+		deps.repoDB.On("FindByOwnerAndSlug", mock.Anything, userID, "game-assets").Return(nil, nil)
+		deps.storage.On("CopyRepository", mock.Anything, "repos/parent/", mock.AnythingOfType("string")).Return(nil)
+		deps.repoDB.On("Create", mock.Anything, mock.AnythingOfType("*model.GitRepo")).Return(nil)
+		deps.lfsObjDB.On("FindByRepo", mock.Anything, deps.parent.ID).Return([]*model.GitLFSObject{}, nil)
+		deps.repoDB.On("IncrementForks", mock.Anything, deps.parent.ID, 1).Return(nil)
+
+		_, err := deps.domain.ForkRepo(ctx, deps.parent.ID, userID, nil)
+		require.NoError(t, err)
+
+		quota.ExpectedCalls = nil
+		quota.On("GetStorageQuota", mock.Anything, userID).Return(int64(3000), nil)
+		quota.On("GetStorageUsed", mock.Anything, userID).Return(int64(1000), nil)
+
+		_, err = deps.domain.ForkRepo(ctx, deps.parent.ID, userID, nil)
+		assert.ErrorIs(t, err, ErrStorageQuotaExceeded)
+	})
+
+	t.Run("into team", func(t *testing.T) {
+		deps := newForkTestDomain(t)
+		userID := uuid.New()
+		teamID := uuid.New()
+
+		deps.teamAccess.On("GetTeamRole", mock.Anything, teamID, userID).Return(model.TeamRoleAdmin, nil)
+		deps.repoDB.On("FindByOwnerAndSlug", mock.Anything, userID, "shared-assets").Return(nil, nil)
+		deps.storage.On("CopyRepository", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		deps.repoDB.On("Create", mock.Anything, mock.Anything).Return(nil)
+		deps.lfsObjDB.On("FindByRepo", mock.Anything, deps.parent.ID).Return([]*model.GitLFSObject{}, nil)
+		deps.repoDB.On("IncrementForks", mock.Anything, deps.parent.ID, 1).Return(nil)
+
+		repo, err := deps.domain.ForkRepo(ctx, deps.parent.ID, userID, &inbound.GitForkRepoInput{
+			Name:   "Shared Assets",
+			TeamID: &teamID,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, &teamID, repo.TeamID)
+		assert.Equal(t, "shared-assets", repo.Slug)
+	})
+
+	t.Run("not team admin", func(t *testing.T) {
+		deps := newForkTestDomain(t)
+		userID := uuid.New()
+		teamID := uuid.New()
+
+		deps.teamAccess.On("GetTeamRole", mock.Anything, teamID, userID).Return(model.TeamRoleMember, nil)
+
+		_, err := deps.domain.ForkRepo(ctx, deps.parent.ID, userID, &inbound.GitForkRepoInput{TeamID: &teamID})
+
+		assert.ErrorIs(t, err, ErrNotTeamAdmin)
+		deps.storage.AssertNotCalled(t, "CopyRepository", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("already exists", func(t *testing.T) {
+		deps := newForkTestDomain(t)
+		userID := uuid.New()
+
+		deps.repoDB.On("FindByOwnerAndSlug", mock.Anything, userID, "game-assets").Return(&model.GitRepo{}, nil)
+
+		_, err := deps.domain.ForkRepo(ctx, deps.parent.ID, userID, nil)
+
+		assert.ErrorIs(t, err, ErrRepoAlreadyExists)
+	})
+
+	t.Run("link failure rolls back", func(t *testing.T) {
+		deps := newForkTestDomain(t)
+		userID := uuid.New()
+
+		deps.repoDB.On("FindByOwnerAndSlug", mock.Anything, userID, "game-assets").Return(nil, nil)
+		deps.storage.On("CopyRepository", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		deps.repoDB.On("Create", mock.Anything, mock.Anything).Return(nil)
+		deps.lfsObjDB.On("FindByRepo", mock.Anything, deps.parent.ID).Return([]*model.GitLFSObject{{OID: "aaa"}}, nil)
+		deps.lfsObjDB.On("Link", mock.Anything, mock.Anything, "aaa").Return(assert.AnError)
+		deps.repoDB.On("Delete", mock.Anything, mock.Anything).Return(nil)
+		deps.storage.On("DeleteRepository", mock.Anything, mock.Anything).Return(nil)
+
+		_, err := deps.domain.ForkRepo(ctx, deps.parent.ID, userID, nil)
+
+		assert.ErrorIs(t, err, assert.AnError)
+		deps.repoDB.AssertCalled(t, "Delete", mock.Anything, mock.Anything)
+		deps.storage.AssertCalled(t, "DeleteRepository", mock.Anything, mock.Anything)
+		deps.repoDB.AssertNotCalled(t, "IncrementForks", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDomain_CanAccess_Team(t *testing.T) {
+	ctx := context.Background()
+	deps := newForkTestDomain(t)
+	teamID := uuid.New()
+	repo := &model.GitRepo{ID: uuid.New(), OwnerID: uuid.New(), TeamID: &teamID, Visibility: model.GitVisibilityPrivate}
+	deps.repoDB.On("FindByID", mock.Anything, repo.ID).Return(repo, nil)
+	deps.domain.collabDB.(*MockGitCollabDB).On("FindByRepoAndUser", mock.Anything, repo.ID, mock.Anything).Return(nil, nil)
+
+	tests := []struct {
+		role     model.TeamRole
+		required model.GitPermission
+		want     bool
+	}{
+		{model.TeamRoleAdmin, model.GitPermissionAdmin, true},
+		{model.TeamRoleMember, model.GitPermissionWrite, true},
+		{model.TeamRoleMember, model.GitPermissionAdmin, false},
+		{model.TeamRoleGuest, model.GitPermissionRead, true},
+		{model.TeamRoleGuest, model.GitPermissionWrite, false},
+		{"", model.GitPermissionRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.role)+" "+string(tt.required), func(t *testing.T) {
+			userID := uuid.New()
+			deps.teamAccess.On("GetTeamRole", mock.Anything, teamID, userID).Return(tt.role, nil)
+
+			ok, err := deps.domain.CanAccess(ctx, repo.ID, &userID, tt.required)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ok)
+		})
+	}
+}
+
+func TestDomain_CreatePR_FromFork(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		deps := newForkTestDomain(t)
+		authorID := uuid.New()
+		fork := &model.GitRepo{ID: uuid.New(), OwnerID: authorID, ForkedFrom: &deps.parent.ID}
+		deps.repoDB.On("FindByID", mock.Anything, fork.ID).Return(fork, nil)
+		deps.prDB.On("GetNextNumber", mock.Anything, deps.parent.ID).Return(3, nil)
+		deps.prDB.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+		pr, err := deps.domain.CreatePR(ctx, deps.parent.ID, authorID, &inbound.GitCreatePRInput{
+			Title:        "Fix textures",
+			SourceRepoID: &fork.ID,
+			SourceBranch: "main",
+			TargetBranch: "main",
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, &fork.ID, pr.SourceRepoID)
+		assert.Equal(t, 3, pr.Number)
+	})
+
+	t.Run("not a fork", func(t *testing.T) {
+		deps := newForkTestDomain(t)
+		authorID := uuid.New()
+		other := &model.GitRepo{ID: uuid.New(), OwnerID: authorID}
+		deps.repoDB.On("FindByID", mock.Anything, other.ID).Return(other, nil)
+
+		_, err := deps.domain.CreatePR(ctx, deps.parent.ID, authorID, &inbound.GitCreatePRInput{
+			Title:        "Fix textures",
+			SourceRepoID: &other.ID,
+			SourceBranch: "main",
+			TargetBranch: "main",
+		})
+
+		assert.ErrorIs(t, err, ErrNotAFork)
+	})
+}
+
+func TestDomain_MergePR_FromFork(t *testing.T) {
+	ctx := context.Background()
+	upstream := newTestRepo(t)
+	base := upstream.commit("main", map[string]string{"a": "1"})
+
+	fork := newTestRepo(t)
+	require.NoError(t, copyObjects(upstream.st, fork.st, base))
+	fork.setBranch("main", base)
+	head := fork.commit("feature", map[string]string{"a": "1", "b": "2"}, base)
+
+	domain, mockPRDB, pr := newMergeTestDomain(t, upstream)
+	forkID := uuid.New()
+	pr.SourceRepoID = &forkID
+	domain.repoDB.(*MockGitRepoDB).On("FindByID", mock.Anything, forkID).Return(&model.GitRepo{
+		ID:          forkID,
+		StoragePath: "repos/fork",
+	}, nil)
+	domain.storage.(*MockGitStorage).On("GetFilesystem", mock.Anything, "repos/fork").Return(fork.fs, nil)
+	mockPRDB.On("Update", mock.Anything, pr).Return(nil)
+
+	_, err := domain.MergePR(ctx, pr.RepoID, 1, pr.AuthorID, &inbound.GitMergePRInput{Strategy: model.GitMergeStrategyFastForward})
+
+	require.NoError(t, err)
+	assert.Equal(t, head, upstream.branch("main").Hash)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, upstream.files(upstream.branch("main")))
+
+	ref, err := upstream.st.Reference("refs/pull/1/head")
+	require.NoError(t, err)
+	assert.Equal(t, head, ref.Hash())
+}
+
+func TestDomain_CheckMergeability_FromFork(t *testing.T) {
+	ctx := context.Background()
+	upstream := newTestRepo(t)
+	base := upstream.commit("main", map[string]string{"a": "1", "b": "1"})
+
+	fork := newTestRepo(t)
+	require.NoError(t, copyObjects(upstream.st, fork.st, base))
+	fork.setBranch("main", base)
+	head := fork.commit("feature", map[string]string{"a": "2", "b": "1"}, base)
+	// The target moved on after the fork was made
+	upstream.commit("main", map[string]string{"a": "3", "b": "1"}, base)
+
+	domain, _, pr := newMergeTestDomain(t, upstream)
+	forkID := uuid.New()
+	pr.SourceRepoID = &forkID
+	domain.repoDB.(*MockGitRepoDB).On("FindByID", mock.Anything, forkID).Return(&model.GitRepo{
+		ID:          forkID,
+		StoragePath: "repos/fork",
+	}, nil)
+	domain.storage.(*MockGitStorage).On("GetFilesystem", mock.Anything, "repos/fork").Return(fork.fs, nil)
+
+	result, err := domain.CheckMergeability(ctx, pr.RepoID, 1, pr.AuthorID)
+
+	require.NoError(t, err)
+	assert.False(t, result.Mergeable)
+	assert.Equal(t, []string{"a"}, result.Conflicts)
+	assert.Equal(t, head.String(), result.SourceSHA)
+	assert.Equal(t, base.String(), result.BaseSHA)
+
+	// Nothing was written to the target repository
+	_, err = upstream.st.Reference("refs/pull/1/head")
+	assert.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+	assert.ErrorIs(t, upstream.st.HasEncodedObject(head), plumbing.ErrObjectNotFound)
+}
+
+func TestCopyObjects(t *testing.T) {
+	src := newTestRepo(t)
+	first := src.commit("main", map[string]string{"a": "1", "dir/b": "1"})
+	second := src.commit("main", map[string]string{"a": "2", "dir/b": "1"}, first)
+
+	dst := newTestRepo(t)
+	require.NoError(t, copyObjects(src.st, dst.st, first))
+	require.NoError(t, copyObjects(src.st, dst.st, second))
+	dst.setBranch("main", second)
+
+	head := dst.branch("main")
+	assert.Equal(t, []plumbing.Hash{first}, head.ParentHashes)
+	assert.Equal(t, map[string]string{"a": "2", "dir/b": "1"}, dst.files(head))
+}
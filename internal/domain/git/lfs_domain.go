@@ -2,6 +2,8 @@ package git
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"time"
@@ -14,14 +16,19 @@ import (
 	"github.com/uniedit/server/internal/port/outbound"
 )
 
+// lfsUploadPrefix names the storage objects of uploads whose content has not
+// been verified yet.
+const lfsUploadPrefix = "tmp/"
+
 // LFSDomain implements the Git LFS domain logic.
 type LFSDomain struct {
-	repoDB     outbound.GitRepoDatabasePort
-	lfsObjDB   outbound.GitLFSObjectDatabasePort
-	lfsStorage outbound.GitLFSStoragePort
-	accessCtrl outbound.GitAccessControlPort
-	cfg        *Config
-	logger     *zap.Logger
+	repoDB       outbound.GitRepoDatabasePort
+	lfsObjDB     outbound.GitLFSObjectDatabasePort
+	lfsStorage   outbound.GitLFSStoragePort
+	accessCtrl   outbound.GitAccessControlPort
+	quotaChecker inbound.GitStorageQuotaChecker
+	cfg          *Config
+	logger       *zap.Logger
 }
 
 // NewLFSDomain creates a new LFS domain.
@@ -30,6 +37,7 @@ func NewLFSDomain(
 	lfsObjDB outbound.GitLFSObjectDatabasePort,
 	lfsStorage outbound.GitLFSStoragePort,
 	accessCtrl outbound.GitAccessControlPort,
+	quotaChecker inbound.GitStorageQuotaChecker,
 	cfg *Config,
 	logger *zap.Logger,
 ) *LFSDomain {
@@ -39,12 +47,13 @@ func NewLFSDomain(
 	_ = cfg.Validate()
 
 	return &LFSDomain{
-		repoDB:     repoDB,
-		lfsObjDB:   lfsObjDB,
-		lfsStorage: lfsStorage,
-		accessCtrl: accessCtrl,
-		cfg:        cfg,
-		logger:     logger,
+		repoDB:       repoDB,
+		lfsObjDB:     lfsObjDB,
+		lfsStorage:   lfsStorage,
+		accessCtrl:   accessCtrl,
+		quotaChecker: quotaChecker,
+		cfg:          cfg,
+		logger:       logger,
 	}
 }
 
@@ -76,6 +85,12 @@ func (d *LFSDomain) ProcessBatch(ctx context.Context, repoID, userID uuid.UUID,
 		return nil, ErrAccessDenied
 	}
 
+	if request.Operation == "upload" {
+		if err := d.checkUploadQuota(ctx, repo.OwnerID, request.Objects); err != nil {
+			return nil, err
+		}
+	}
+
 	// Process objects
 	response := &model.GitLFSBatchResponse{
 		Transfer: "basic",
@@ -83,14 +98,56 @@ func (d *LFSDomain) ProcessBatch(ctx context.Context, repoID, userID uuid.UUID,
 	}
 
 	for _, obj := range request.Objects {
-		objResp := d.processBatchObject(ctx, repoID, obj, request.Operation)
+		objResp := d.processBatchObject(ctx, repo, obj, request.Operation)
 		response.Objects = append(response.Objects, objResp)
 	}
 
 	return response, nil
 }
 
-func (d *LFSDomain) processBatchObject(ctx context.Context, repoID uuid.UUID, obj *model.GitLFSPointer, operation string) *model.GitLFSObjectResponse {
+// checkUploadQuota fails when the objects of an upload batch that aren't
+// stored yet don't fit in the owner's LFS storage. Quota lookups that fail
+// don't block the upload.
+func (d *LFSDomain) checkUploadQuota(ctx context.Context, ownerID uuid.UUID, objects []*model.GitLFSPointer) error {
+	if d.quotaChecker == nil {
+		return nil
+	}
+
+	quota, err := d.quotaChecker.GetLFSStorageQuota(ctx, ownerID)
+	if err != nil {
+		d.logger.Warn("failed to get LFS storage quota", zap.Error(err))
+		return nil
+	}
+	if quota < 0 {
+		return nil
+	}
+
+	var incoming int64
+	for _, obj := range objects {
+		existing, err := d.lfsObjDB.FindByOID(ctx, obj.OID)
+		if err != nil {
+			return fmt.Errorf("find LFS object: %w", err)
+		}
+		if existing == nil {
+			incoming += obj.Size
+		}
+	}
+	if incoming == 0 {
+		return nil
+	}
+
+	used, err := d.quotaChecker.GetLFSStorageUsed(ctx, ownerID)
+	if err != nil {
+		d.logger.Warn("failed to get LFS storage used", zap.Error(err))
+		return nil
+	}
+	if used+incoming > quota {
+		return ErrLFSQuotaExceeded
+	}
+	return nil
+}
+
+func (d *LFSDomain) processBatchObject(ctx context.Context, repo *model.GitRepo, obj *model.GitLFSPointer, operation string) *model.GitLFSObjectResponse {
 	resp := &model.GitLFSObjectResponse{
 		OID:  obj.OID,
 		Size: obj.Size,
@@ -123,8 +180,7 @@ func (d *LFSDomain) processBatchObject(ctx context.Context, repoID uuid.UUID, ob
 			return resp
 		}
 
-		// Generate download URL
-		presigned, err := d.lfsStorage.GenerateDownloadURL(ctx, obj.OID, d.cfg.PresignedURLExpiry)
+		action, err := d.downloadAction(ctx, repo, obj.OID)
 		if err != nil {
 			resp.Error = &model.GitLFSError{
 				Code:    500,
@@ -133,12 +189,8 @@ func (d *LFSDomain) processBatchObject(ctx context.Context, repoID uuid.UUID, ob
 			return resp
 		}
 
-		expiresIn := int(d.cfg.PresignedURLExpiry.Seconds())
 		resp.Actions = map[string]*model.GitLFSAction{
-			"download": {
-				Href:      presigned.URL,
-				ExpiresIn: expiresIn,
-			},
+			"download": action,
 		}
 	} else if operation == "upload" {
 		// Check if object already exists
@@ -153,15 +205,14 @@ func (d *LFSDomain) processBatchObject(ctx context.Context, repoID uuid.UUID, ob
 
 		if exists {
 			// Object exists, just link it
-			if err := d.lfsObjDB.Link(ctx, repoID, obj.OID); err != nil {
+			if err := d.lfsObjDB.Link(ctx, repo.ID, obj.OID); err != nil {
 				d.logger.Warn("failed to link existing LFS object", zap.Error(err))
 			}
 			resp.Authenticated = true
 			return resp
 		}
 
-		// Generate upload URL
-		presigned, err := d.lfsStorage.GenerateUploadURL(ctx, obj.OID, obj.Size, d.cfg.PresignedURLExpiry)
+		actions, err := d.uploadActions(ctx, repo, obj.OID, obj.Size)
 		if err != nil {
 			resp.Error = &model.GitLFSError{
 				Code:    500,
@@ -170,22 +221,67 @@ func (d *LFSDomain) processBatchObject(ctx context.Context, repoID uuid.UUID, ob
 			return resp
 		}
 
-		expiresIn := int(d.cfg.PresignedURLExpiry.Seconds())
-		resp.Actions = map[string]*model.GitLFSAction{
-			"upload": {
-				Href:      presigned.URL,
-				ExpiresIn: expiresIn,
-			},
-			"verify": {
-				Href:      d.cfg.BaseURL + "/lfs/objects/" + obj.OID + "/verify",
-				ExpiresIn: expiresIn,
-			},
-		}
+		resp.Actions = actions
 	}
 
 	return resp
 }
 
+// downloadAction returns where the client downloads an object from: the
+// server itself in proxy mode, otherwise a presigned storage URL.
+func (d *LFSDomain) downloadAction(ctx context.Context, repo *model.GitRepo, oid string) (*model.GitLFSAction, error) {
+	if d.cfg.LFSProxyTransfer {
+		return &model.GitLFSAction{Href: d.objectURL(repo, oid)}, nil
+	}
+
+	presigned, err := d.lfsStorage.GenerateDownloadURL(ctx, oid, d.cfg.PresignedURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+	return &model.GitLFSAction{
+		Href:      presigned.URL,
+		ExpiresIn: int(d.cfg.PresignedURLExpiry.Seconds()),
+	}, nil
+}
+
+// uploadActions returns where the client uploads an object to and the
+// verify action that completes the upload. Presigned uploads are staged
+// under a key of their own, named by the verify action, so nothing reaches
+// the shared object before it is hashed against its oid.
+func (d *LFSDomain) uploadActions(ctx context.Context, repo *model.GitRepo, oid string, size int64) (map[string]*model.GitLFSAction, error) {
+	verify := &model.GitLFSAction{
+		Href:      d.objectURL(repo, oid) + "/verify",
+		ExpiresIn: int(d.cfg.PresignedURLExpiry.Seconds()),
+	}
+
+	if d.cfg.LFSProxyTransfer {
+		return map[string]*model.GitLFSAction{
+			"upload": {Href: d.objectURL(repo, oid)},
+			"verify": verify,
+		}, nil
+	}
+
+	uploadID := uuid.NewString()
+	presigned, err := d.lfsStorage.GenerateUploadURL(ctx, lfsUploadPrefix+uploadID, size, d.cfg.PresignedURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+	verify.Href += "?upload=" + uploadID
+
+	return map[string]*model.GitLFSAction{
+		"upload": {
+			Href:      presigned.URL,
+			ExpiresIn: int(d.cfg.PresignedURLExpiry.Seconds()),
+		},
+		"verify": verify,
+	}, nil
+}
+
+// objectURL returns the server URL of an object in a repository's LFS API.
+func (d *LFSDomain) objectURL(repo *model.GitRepo, oid string) string {
+	return fmt.Sprintf("%s/git/%s/%s.git/info/lfs/objects/%s", d.cfg.BaseURL, repo.OwnerID, repo.Slug, oid)
+}
+
 // GetObject gets an LFS object.
 func (d *LFSDomain) GetObject(ctx context.Context, oid string) (*model.GitLFSObject, error) {
 	obj, err := d.lfsObjDB.FindByOID(ctx, oid)
@@ -226,9 +322,55 @@ func (d *LFSDomain) LinkObject(ctx context.Context, repoID uuid.UUID, oid string
 	return d.lfsObjDB.Link(ctx, repoID, oid)
 }
 
-// Upload uploads an LFS object.
-func (d *LFSDomain) Upload(ctx context.Context, oid string, reader io.Reader, size int64) error {
-	return d.lfsStorage.Upload(ctx, oid, reader, size)
+// Upload uploads an LFS object of the declared size for a repository,
+// within the size limit and the owner's LFS quota. Objects are shared
+// between repositories, so the body is staged under a temporary name and
+// only copied to its oid once it hashes to it; a bad upload never touches
+// the stored object.
+func (d *LFSDomain) Upload(ctx context.Context, repoID uuid.UUID, oid string, reader io.Reader, size int64) error {
+	repo, err := d.repoDB.FindByID(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	if repo == nil {
+		return ErrRepoNotFound
+	}
+	if size > d.cfg.MaxLFSFileSize {
+		return ErrLFSFileTooLarge
+	}
+	if err := d.checkUploadQuota(ctx, repo.OwnerID, []*model.GitLFSPointer{{OID: oid, Size: size}}); err != nil {
+		return err
+	}
+
+	// Nothing past the declared size is read
+	body := &io.LimitedReader{R: reader, N: size}
+	tmp := lfsUploadPrefix + uuid.NewString()
+	hash := sha256.New()
+	if err := d.lfsStorage.Upload(ctx, tmp, io.TeeReader(body, hash), size); err != nil {
+		return err
+	}
+	defer func() {
+		if err := d.lfsStorage.Delete(context.WithoutCancel(ctx), tmp); err != nil {
+			d.logger.Warn("failed to delete staged LFS upload", zap.String("key", tmp), zap.Error(err))
+		}
+	}()
+
+	if body.N > 0 {
+		return fmt.Errorf("size mismatch: expected %d, got %d", size, size-body.N)
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != oid {
+		return ErrLFSChecksumMismatch
+	}
+
+	// A concurrent upload may have stored the same content already.
+	exists, err := d.lfsStorage.Exists(ctx, oid)
+	if err != nil {
+		return fmt.Errorf("check object existence: %w", err)
+	}
+	if exists {
+		return nil
+	}
+	return d.lfsStorage.Copy(ctx, tmp, oid)
 }
 
 // Download downloads an LFS object.
@@ -261,9 +403,78 @@ func (d *LFSDomain) VerifyObject(ctx context.Context, oid string, expectedSize i
 	return nil
 }
 
-// GenerateUploadURL generates a presigned upload URL.
+// VerifyUpload completes a presigned upload. The staged content is hashed
+// against its oid before it is copied to the shared object and recorded,
+// with the size it actually has rather than the one the client declared.
+func (d *LFSDomain) VerifyUpload(ctx context.Context, repoID uuid.UUID, oid, uploadID string) error {
+	if _, err := uuid.Parse(uploadID); err != nil {
+		return ErrLFSObjectNotFound
+	}
+
+	repo, err := d.repoDB.FindByID(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	if repo == nil {
+		return ErrRepoNotFound
+	}
+
+	tmp := lfsUploadPrefix + uploadID
+	exists, err := d.lfsStorage.Exists(ctx, tmp)
+	if err != nil {
+		return fmt.Errorf("check staged upload: %w", err)
+	}
+	if !exists {
+		return ErrLFSObjectNotFound
+	}
+	defer func() {
+		if err := d.lfsStorage.Delete(context.WithoutCancel(ctx), tmp); err != nil {
+			d.logger.Warn("failed to delete staged LFS upload", zap.String("key", tmp), zap.Error(err))
+		}
+	}()
+
+	reader, _, err := d.lfsStorage.Download(ctx, tmp)
+	if err != nil {
+		return fmt.Errorf("read staged upload: %w", err)
+	}
+	defer reader.Close()
+
+	// Nothing past the size limit is read
+	hash := sha256.New()
+	size, err := io.Copy(hash, io.LimitReader(reader, d.cfg.MaxLFSFileSize+1))
+	if err != nil {
+		return fmt.Errorf("read staged upload: %w", err)
+	}
+	if size > d.cfg.MaxLFSFileSize {
+		return ErrLFSFileTooLarge
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != oid {
+		return ErrLFSChecksumMismatch
+	}
+	if err := d.checkUploadQuota(ctx, repo.OwnerID, []*model.GitLFSPointer{{OID: oid, Size: size}}); err != nil {
+		return err
+	}
+
+	// A concurrent upload may have stored the same content already.
+	exists, err = d.lfsStorage.Exists(ctx, oid)
+	if err != nil {
+		return fmt.Errorf("check object existence: %w", err)
+	}
+	if !exists {
+		if err := d.lfsStorage.Copy(ctx, tmp, oid); err != nil {
+			return err
+		}
+	}
+
+	return d.CreateObject(ctx, repoID, oid, size)
+}
+
+// GenerateUploadURL generates a presigned upload URL. The upload is staged
+// and only stored under its oid once VerifyUpload is called with the
+// returned upload ID.
 func (d *LFSDomain) GenerateUploadURL(ctx context.Context, oid string, size int64) (*inbound.GitPresignedURLResult, error) {
-	presigned, err := d.lfsStorage.GenerateUploadURL(ctx, oid, size, d.cfg.PresignedURLExpiry)
+	uploadID := uuid.NewString()
+	presigned, err := d.lfsStorage.GenerateUploadURL(ctx, lfsUploadPrefix+uploadID, size, d.cfg.PresignedURLExpiry)
 	if err != nil {
 		return nil, err
 	}
@@ -272,6 +483,7 @@ func (d *LFSDomain) GenerateUploadURL(ctx context.Context, oid string, size int6
 		URL:       presigned.URL,
 		Method:    presigned.Method,
 		ExpiresAt: presigned.ExpiresAt,
+		UploadID:  uploadID,
 	}, nil
 }
 
@@ -53,6 +53,7 @@ func TestLFSDomain_ProcessBatch(t *testing.T) {
 			mockLFSObjDB,
 			mockLFSStorage,
 			mockAccessCtrl,
+			nil,
 			cfg,
 			logger,
 		)
@@ -101,6 +102,7 @@ func TestLFSDomain_ProcessBatch(t *testing.T) {
 			mockLFSObjDB,
 			mockLFSStorage,
 			mockAccessCtrl,
+			nil,
 			cfg,
 			logger,
 		)
@@ -117,7 +119,9 @@ func TestLFSDomain_ProcessBatch(t *testing.T) {
 		mockAccessCtrl.On("CheckAccess", mock.Anything, userID, repoID, model.GitPermissionWrite).
 			Return(&model.GitAccessResult{Allowed: true}, nil)
 		mockLFSStorage.On("Exists", mock.Anything, oid).Return(false, nil)
-		mockLFSStorage.On("GenerateUploadURL", mock.Anything, oid, int64(1024), cfg.PresignedURLExpiry).
+		var staged string
+		mockLFSStorage.On("GenerateUploadURL", mock.Anything, mock.Anything, int64(1024), cfg.PresignedURLExpiry).
+			Run(func(args mock.Arguments) { staged = args.String(1) }).
 			Return(&outbound.GitPresignedURL{URL: "https://example.com/upload", Method: "PUT"}, nil)
 
 		request := &model.GitLFSBatchRequest{
@@ -134,7 +138,12 @@ func TestLFSDomain_ProcessBatch(t *testing.T) {
 		assert.Len(t, response.Objects, 1)
 		assert.Nil(t, response.Objects[0].Error)
 		assert.NotNil(t, response.Objects[0].Actions["upload"])
-		assert.NotNil(t, response.Objects[0].Actions["verify"])
+		require.NotNil(t, response.Objects[0].Actions["verify"])
+
+		// The upload goes to a staging key that the verify action names
+		require.True(t, strings.HasPrefix(staged, lfsUploadPrefix))
+		uploadID := strings.TrimPrefix(staged, lfsUploadPrefix)
+		assert.True(t, strings.HasSuffix(response.Objects[0].Actions["verify"].Href, "/verify?upload="+uploadID))
 	})
 
 	t.Run("upload_existing_object", func(t *testing.T) {
@@ -149,6 +158,7 @@ func TestLFSDomain_ProcessBatch(t *testing.T) {
 			mockLFSObjDB,
 			mockLFSStorage,
 			mockAccessCtrl,
+			nil,
 			cfg,
 			logger,
 		)
@@ -192,6 +202,7 @@ func TestLFSDomain_ProcessBatch(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
 			logger,
 		)
 
@@ -225,6 +236,7 @@ func TestLFSDomain_ProcessBatch(t *testing.T) {
 			nil,
 			mockAccessCtrl,
 			nil,
+			nil,
 			logger,
 		)
 
@@ -260,6 +272,7 @@ func TestLFSDomain_ProcessBatch(t *testing.T) {
 			nil,
 			nil,
 			mockAccessCtrl,
+			nil,
 			cfg,
 			logger,
 		)
@@ -302,6 +315,7 @@ func TestLFSDomain_ProcessBatch(t *testing.T) {
 			mockLFSStorage,
 			mockAccessCtrl,
 			nil,
+			nil,
 			logger,
 		)
 
@@ -332,6 +346,100 @@ func TestLFSDomain_ProcessBatch(t *testing.T) {
 		assert.NotNil(t, response.Objects[0].Error)
 		assert.Equal(t, 404, response.Objects[0].Error.Code)
 	})
+
+	t.Run("upload_proxy_transfer", func(t *testing.T) {
+		mockRepoDB := new(MockGitRepoDB)
+		mockLFSStorage := new(MockGitLFSStorage)
+		mockAccessCtrl := new(MockGitAccessControl)
+
+		cfg := DefaultConfig()
+		cfg.BaseURL = "https://git.example.com"
+		cfg.LFSProxyTransfer = true
+		domain := NewLFSDomain(
+			mockRepoDB,
+			nil,
+			mockLFSStorage,
+			mockAccessCtrl,
+			nil,
+			cfg,
+			logger,
+		)
+
+		repoID := uuid.New()
+		userID := uuid.New()
+		repo := &model.GitRepo{
+			ID:         repoID,
+			OwnerID:    userID,
+			Slug:       "assets",
+			LFSEnabled: true,
+		}
+		oid := "proxiedobject"
+
+		mockRepoDB.On("FindByID", mock.Anything, repoID).Return(repo, nil)
+		mockAccessCtrl.On("CheckAccess", mock.Anything, userID, repoID, model.GitPermissionWrite).
+			Return(&model.GitAccessResult{Allowed: true}, nil)
+		mockLFSStorage.On("Exists", mock.Anything, oid).Return(false, nil)
+
+		request := &model.GitLFSBatchRequest{
+			Operation: "upload",
+			Objects: []*model.GitLFSPointer{
+				{OID: oid, Size: 1024},
+			},
+		}
+
+		response, err := domain.ProcessBatch(context.Background(), repoID, userID, request)
+
+		require.NoError(t, err)
+		objectURL := "https://git.example.com/git/" + userID.String() + "/assets.git/info/lfs/objects/" + oid
+		assert.Equal(t, objectURL, response.Objects[0].Actions["upload"].Href)
+		assert.Equal(t, objectURL+"/verify", response.Objects[0].Actions["verify"].Href)
+		mockLFSStorage.AssertNotCalled(t, "GenerateUploadURL", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("upload_over_quota", func(t *testing.T) {
+		mockRepoDB := new(MockGitRepoDB)
+		mockLFSObjDB := new(MockGitLFSObjDB)
+		mockAccessCtrl := new(MockGitAccessControl)
+		mockQuota := new(MockGitQuotaChecker)
+
+		domain := NewLFSDomain(
+			mockRepoDB,
+			mockLFSObjDB,
+			nil,
+			mockAccessCtrl,
+			mockQuota,
+			nil,
+			logger,
+		)
+
+		repoID := uuid.New()
+		userID := uuid.New()
+		repo := &model.GitRepo{
+			ID:         repoID,
+			OwnerID:    userID,
+			LFSEnabled: true,
+		}
+
+		mockRepoDB.On("FindByID", mock.Anything, repoID).Return(repo, nil)
+		mockAccessCtrl.On("CheckAccess", mock.Anything, userID, repoID, model.GitPermissionWrite).
+			Return(&model.GitAccessResult{Allowed: true}, nil)
+		mockQuota.On("GetLFSStorageQuota", mock.Anything, userID).Return(int64(4096), nil)
+		mockQuota.On("GetLFSStorageUsed", mock.Anything, userID).Return(int64(2048), nil)
+		mockLFSObjDB.On("FindByOID", mock.Anything, "stored").Return(&model.GitLFSObject{OID: "stored", Size: 4096}, nil)
+		mockLFSObjDB.On("FindByOID", mock.Anything, "new").Return(nil, nil)
+
+		request := &model.GitLFSBatchRequest{
+			Operation: "upload",
+			Objects: []*model.GitLFSPointer{
+				{OID: "stored", Size: 4096}, // already stored, doesn't count
+				{OID: "new", Size: 3072},
+			},
+		}
+
+		_, err := domain.ProcessBatch(context.Background(), repoID, userID, request)
+
+		assert.ErrorIs(t, err, ErrLFSQuotaExceeded)
+	})
 }
 
 func TestLFSDomain_GetObject(t *testing.T) {
@@ -340,7 +448,7 @@ func TestLFSDomain_GetObject(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockLFSObjDB := new(MockGitLFSObjDB)
 
-		domain := NewLFSDomain(nil, mockLFSObjDB, nil, nil, nil, logger)
+		domain := NewLFSDomain(nil, mockLFSObjDB, nil, nil, nil, nil, logger)
 
 		oid := "abc123"
 		obj := &model.GitLFSObject{
@@ -359,7 +467,7 @@ func TestLFSDomain_GetObject(t *testing.T) {
 	t.Run("not_found", func(t *testing.T) {
 		mockLFSObjDB := new(MockGitLFSObjDB)
 
-		domain := NewLFSDomain(nil, mockLFSObjDB, nil, nil, nil, logger)
+		domain := NewLFSDomain(nil, mockLFSObjDB, nil, nil, nil, nil, logger)
 
 		mockLFSObjDB.On("FindByOID", mock.Anything, "missing").Return(nil, nil)
 
@@ -377,7 +485,7 @@ func TestLFSDomain_CreateObject(t *testing.T) {
 		mockLFSObjDB := new(MockGitLFSObjDB)
 
 		cfg := DefaultConfig()
-		domain := NewLFSDomain(nil, mockLFSObjDB, nil, nil, cfg, logger)
+		domain := NewLFSDomain(nil, mockLFSObjDB, nil, nil, nil, cfg, logger)
 
 		repoID := uuid.New()
 		oid := "newobject"
@@ -400,7 +508,7 @@ func TestLFSDomain_VerifyObject(t *testing.T) {
 		mockLFSObjDB := new(MockGitLFSObjDB)
 		mockLFSStorage := new(MockGitLFSStorage)
 
-		domain := NewLFSDomain(nil, mockLFSObjDB, mockLFSStorage, nil, nil, logger)
+		domain := NewLFSDomain(nil, mockLFSObjDB, mockLFSStorage, nil, nil, nil, logger)
 
 		oid := "verified"
 		size := int64(1024)
@@ -420,7 +528,7 @@ func TestLFSDomain_VerifyObject(t *testing.T) {
 	t.Run("object_not_found_in_storage", func(t *testing.T) {
 		mockLFSStorage := new(MockGitLFSStorage)
 
-		domain := NewLFSDomain(nil, nil, mockLFSStorage, nil, nil, logger)
+		domain := NewLFSDomain(nil, nil, mockLFSStorage, nil, nil, nil, logger)
 
 		mockLFSStorage.On("Exists", mock.Anything, "missing").Return(false, nil)
 
@@ -433,7 +541,7 @@ func TestLFSDomain_VerifyObject(t *testing.T) {
 		mockLFSObjDB := new(MockGitLFSObjDB)
 		mockLFSStorage := new(MockGitLFSStorage)
 
-		domain := NewLFSDomain(nil, mockLFSObjDB, mockLFSStorage, nil, nil, logger)
+		domain := NewLFSDomain(nil, mockLFSObjDB, mockLFSStorage, nil, nil, nil, logger)
 
 		oid := "mismatch"
 		obj := &model.GitLFSObject{
@@ -453,32 +561,265 @@ func TestLFSDomain_VerifyObject(t *testing.T) {
 
 func TestLFSDomain_Upload(t *testing.T) {
 	logger := zap.NewNop()
+	isStaged := mock.MatchedBy(func(key string) bool { return strings.HasPrefix(key, lfsUploadPrefix) })
+	repo := &model.GitRepo{ID: uuid.New(), OwnerID: uuid.New(), LFSEnabled: true}
+
+	newRepoDB := func() *MockGitRepoDB {
+		mockRepoDB := new(MockGitRepoDB)
+		mockRepoDB.On("FindByID", mock.Anything, repo.ID).Return(repo, nil)
+		return mockRepoDB
+	}
 
 	t.Run("success", func(t *testing.T) {
 		mockLFSStorage := new(MockGitLFSStorage)
 
-		domain := NewLFSDomain(nil, nil, mockLFSStorage, nil, nil, logger)
+		domain := NewLFSDomain(newRepoDB(), nil, mockLFSStorage, nil, nil, nil, logger)
 
-		oid := "uploadtest"
 		content := "test content"
+		oid := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
 		reader := strings.NewReader(content)
 		size := int64(len(content))
 
-		mockLFSStorage.On("Upload", mock.Anything, oid, mock.Anything, size).Return(nil)
+		mockLFSStorage.On("Upload", mock.Anything, isStaged, mock.Anything, size).
+			Run(drainUpload).Return(nil)
+		mockLFSStorage.On("Exists", mock.Anything, oid).Return(false, nil)
+		mockLFSStorage.On("Copy", mock.Anything, isStaged, oid).Return(nil)
+		mockLFSStorage.On("Delete", mock.Anything, isStaged).Return(nil)
+
+		err := domain.Upload(context.Background(), repo.ID, oid, reader, size)
+
+		require.NoError(t, err)
+		mockLFSStorage.AssertExpectations(t)
+		mockLFSStorage.AssertNotCalled(t, "Upload", mock.Anything, oid, mock.Anything, mock.Anything)
+	})
+
+	t.Run("already_stored", func(t *testing.T) {
+		mockLFSStorage := new(MockGitLFSStorage)
+
+		domain := NewLFSDomain(newRepoDB(), nil, mockLFSStorage, nil, nil, nil, logger)
+
+		content := "test content"
+		oid := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+		size := int64(len(content))
+
+		mockLFSStorage.On("Upload", mock.Anything, isStaged, mock.Anything, size).
+			Run(drainUpload).Return(nil)
+		mockLFSStorage.On("Exists", mock.Anything, oid).Return(true, nil)
+		mockLFSStorage.On("Delete", mock.Anything, isStaged).Return(nil)
+
+		err := domain.Upload(context.Background(), repo.ID, oid, strings.NewReader(content), size)
+
+		require.NoError(t, err)
+		mockLFSStorage.AssertNotCalled(t, "Copy", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("checksum_mismatch", func(t *testing.T) {
+		mockLFSStorage := new(MockGitLFSStorage)
+
+		domain := NewLFSDomain(newRepoDB(), nil, mockLFSStorage, nil, nil, nil, logger)
+
+		content := "tampered content"
+		oid := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+		size := int64(len(content))
+
+		mockLFSStorage.On("Upload", mock.Anything, isStaged, mock.Anything, size).
+			Run(drainUpload).Return(nil)
+		mockLFSStorage.On("Delete", mock.Anything, isStaged).Return(nil)
+
+		err := domain.Upload(context.Background(), repo.ID, oid, strings.NewReader(content), size)
 
-		err := domain.Upload(context.Background(), oid, reader, size)
+		assert.ErrorIs(t, err, ErrLFSChecksumMismatch)
+		mockLFSStorage.AssertExpectations(t)
+		mockLFSStorage.AssertNotCalled(t, "Copy", mock.Anything, mock.Anything, mock.Anything)
+		mockLFSStorage.AssertNotCalled(t, "Delete", mock.Anything, oid)
+	})
+
+	t.Run("body_longer_than_declared", func(t *testing.T) {
+		mockLFSStorage := new(MockGitLFSStorage)
+
+		domain := NewLFSDomain(newRepoDB(), nil, mockLFSStorage, nil, nil, nil, logger)
+
+		content := "test content"
+		oid := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+		size := int64(len(content))
+
+		var stored int64
+		mockLFSStorage.On("Upload", mock.Anything, isStaged, mock.Anything, size).
+			Run(func(args mock.Arguments) {
+				stored, _ = io.Copy(io.Discard, args.Get(2).(io.Reader))
+			}).Return(nil)
+		mockLFSStorage.On("Exists", mock.Anything, oid).Return(false, nil)
+		mockLFSStorage.On("Copy", mock.Anything, isStaged, oid).Return(nil)
+		mockLFSStorage.On("Delete", mock.Anything, isStaged).Return(nil)
+
+		err := domain.Upload(context.Background(), repo.ID, oid, strings.NewReader(content+strings.Repeat("x", 1024)), size)
 
 		require.NoError(t, err)
+		assert.Equal(t, size, stored)
+	})
+
+	t.Run("body_shorter_than_declared", func(t *testing.T) {
+		mockLFSStorage := new(MockGitLFSStorage)
+
+		domain := NewLFSDomain(newRepoDB(), nil, mockLFSStorage, nil, nil, nil, logger)
+
+		content := "test content"
+		oid := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+		size := int64(len(content)) + 1
+
+		mockLFSStorage.On("Upload", mock.Anything, isStaged, mock.Anything, size).
+			Run(drainUpload).Return(nil)
+		mockLFSStorage.On("Delete", mock.Anything, isStaged).Return(nil)
+
+		err := domain.Upload(context.Background(), repo.ID, oid, strings.NewReader(content), size)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "size mismatch")
+		mockLFSStorage.AssertNotCalled(t, "Copy", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("too_large", func(t *testing.T) {
+		mockLFSStorage := new(MockGitLFSStorage)
+
+		cfg := DefaultConfig()
+		cfg.MaxLFSFileSize = 4
+		domain := NewLFSDomain(newRepoDB(), nil, mockLFSStorage, nil, nil, cfg, logger)
+
+		err := domain.Upload(context.Background(), repo.ID, "oid", strings.NewReader("test content"), 12)
+
+		assert.ErrorIs(t, err, ErrLFSFileTooLarge)
+		mockLFSStorage.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("over_quota", func(t *testing.T) {
+		mockLFSStorage := new(MockGitLFSStorage)
+		mockLFSObjDB := new(MockGitLFSObjDB)
+		mockQuota := new(MockGitQuotaChecker)
+
+		domain := NewLFSDomain(newRepoDB(), mockLFSObjDB, mockLFSStorage, nil, mockQuota, nil, logger)
+
+		mockQuota.On("GetLFSStorageQuota", mock.Anything, repo.OwnerID).Return(int64(4096), nil)
+		mockQuota.On("GetLFSStorageUsed", mock.Anything, repo.OwnerID).Return(int64(4090), nil)
+		mockLFSObjDB.On("FindByOID", mock.Anything, "oid").Return(nil, nil)
+
+		err := domain.Upload(context.Background(), repo.ID, "oid", strings.NewReader("test content"), 12)
+
+		assert.ErrorIs(t, err, ErrLFSQuotaExceeded)
+		mockLFSStorage.AssertNotCalled(t, "Upload", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 	})
 }
 
+func TestLFSDomain_VerifyUpload(t *testing.T) {
+	logger := zap.NewNop()
+	repo := &model.GitRepo{ID: uuid.New(), OwnerID: uuid.New(), LFSEnabled: true}
+	uploadID := uuid.NewString()
+	staged := lfsUploadPrefix + uploadID
+
+	content := "test content"
+	oid := "6ae8a75555209fd6c44157c0aed8016e763ff435a19cf186f76863140143ff72"
+
+	newRepoDB := func() *MockGitRepoDB {
+		mockRepoDB := new(MockGitRepoDB)
+		mockRepoDB.On("FindByID", mock.Anything, repo.ID).Return(repo, nil)
+		return mockRepoDB
+	}
+	newStorage := func(body string) *MockGitLFSStorage {
+		mockLFSStorage := new(MockGitLFSStorage)
+		mockLFSStorage.On("Exists", mock.Anything, staged).Return(true, nil)
+		mockLFSStorage.On("Download", mock.Anything, staged).
+			Return(io.NopCloser(strings.NewReader(body)), int64(len(body)), nil)
+		mockLFSStorage.On("Delete", mock.Anything, staged).Return(nil)
+		return mockLFSStorage
+	}
+
+	t.Run("success", func(t *testing.T) {
+		mockLFSStorage := newStorage(content)
+		mockLFSObjDB := new(MockGitLFSObjDB)
+
+		domain := NewLFSDomain(newRepoDB(), mockLFSObjDB, mockLFSStorage, nil, nil, nil, logger)
+
+		mockLFSStorage.On("Exists", mock.Anything, oid).Return(false, nil)
+		mockLFSStorage.On("Copy", mock.Anything, staged, oid).Return(nil)
+		mockLFSObjDB.On("Create", mock.Anything, mock.MatchedBy(func(obj *model.GitLFSObject) bool {
+			return obj.OID == oid && obj.Size == int64(len(content))
+		})).Return(nil)
+		mockLFSObjDB.On("Link", mock.Anything, repo.ID, oid).Return(nil)
+
+		err := domain.VerifyUpload(context.Background(), repo.ID, oid, uploadID)
+
+		require.NoError(t, err)
+		mockLFSStorage.AssertExpectations(t)
+		mockLFSObjDB.AssertExpectations(t)
+	})
+
+	t.Run("checksum_mismatch", func(t *testing.T) {
+		mockLFSStorage := newStorage("someone else's content")
+		mockLFSObjDB := new(MockGitLFSObjDB)
+
+		domain := NewLFSDomain(newRepoDB(), mockLFSObjDB, mockLFSStorage, nil, nil, nil, logger)
+
+		err := domain.VerifyUpload(context.Background(), repo.ID, oid, uploadID)
+
+		assert.ErrorIs(t, err, ErrLFSChecksumMismatch)
+		mockLFSStorage.AssertCalled(t, "Delete", mock.Anything, staged)
+		mockLFSStorage.AssertNotCalled(t, "Copy", mock.Anything, mock.Anything, mock.Anything)
+		mockLFSObjDB.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("too_large", func(t *testing.T) {
+		mockLFSStorage := newStorage(content)
+
+		cfg := DefaultConfig()
+		cfg.MaxLFSFileSize = 4
+		domain := NewLFSDomain(newRepoDB(), nil, mockLFSStorage, nil, nil, cfg, logger)
+
+		err := domain.VerifyUpload(context.Background(), repo.ID, oid, uploadID)
+
+		assert.ErrorIs(t, err, ErrLFSFileTooLarge)
+		mockLFSStorage.AssertNotCalled(t, "Copy", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("over_quota_with_actual_size", func(t *testing.T) {
+		mockLFSStorage := newStorage(content)
+		mockLFSObjDB := new(MockGitLFSObjDB)
+		mockQuota := new(MockGitQuotaChecker)
+
+		domain := NewLFSDomain(newRepoDB(), mockLFSObjDB, mockLFSStorage, nil, mockQuota, nil, logger)
+
+		mockQuota.On("GetLFSStorageQuota", mock.Anything, repo.OwnerID).Return(int64(4096), nil)
+		mockQuota.On("GetLFSStorageUsed", mock.Anything, repo.OwnerID).Return(int64(4090), nil)
+		mockLFSObjDB.On("FindByOID", mock.Anything, oid).Return(nil, nil)
+
+		err := domain.VerifyUpload(context.Background(), repo.ID, oid, uploadID)
+
+		assert.ErrorIs(t, err, ErrLFSQuotaExceeded)
+		mockLFSStorage.AssertNotCalled(t, "Copy", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown_upload", func(t *testing.T) {
+		mockLFSStorage := new(MockGitLFSStorage)
+
+		domain := NewLFSDomain(newRepoDB(), nil, mockLFSStorage, nil, nil, nil, logger)
+
+		err := domain.VerifyUpload(context.Background(), repo.ID, oid, "../"+oid)
+
+		assert.ErrorIs(t, err, ErrLFSObjectNotFound)
+		mockLFSStorage.AssertNotCalled(t, "Exists", mock.Anything, mock.Anything)
+	})
+}
+
+// drainUpload reads the upload body like a real storage backend would.
+func drainUpload(args mock.Arguments) {
+	_, _ = io.Copy(io.Discard, args.Get(2).(io.Reader))
+}
+
 func TestLFSDomain_Download(t *testing.T) {
 	logger := zap.NewNop()
 
 	t.Run("success", func(t *testing.T) {
 		mockLFSStorage := new(MockGitLFSStorage)
 
-		domain := NewLFSDomain(nil, nil, mockLFSStorage, nil, nil, logger)
+		domain := NewLFSDomain(nil, nil, mockLFSStorage, nil, nil, nil, logger)
 
 		oid := "downloadtest"
 		content := "test content"
@@ -502,13 +843,15 @@ func TestLFSDomain_GenerateUploadURL(t *testing.T) {
 		mockLFSStorage := new(MockGitLFSStorage)
 
 		cfg := DefaultConfig()
-		domain := NewLFSDomain(nil, nil, mockLFSStorage, nil, cfg, logger)
+		domain := NewLFSDomain(nil, nil, mockLFSStorage, nil, nil, cfg, logger)
 
 		oid := "urltest"
 		size := int64(1024)
 		expiresAt := time.Now().Add(cfg.PresignedURLExpiry)
 
-		mockLFSStorage.On("GenerateUploadURL", mock.Anything, oid, size, cfg.PresignedURLExpiry).
+		mockLFSStorage.On("GenerateUploadURL", mock.Anything, mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, lfsUploadPrefix)
+		}), size, cfg.PresignedURLExpiry).
 			Return(&outbound.GitPresignedURL{
 				URL:       "https://example.com/upload",
 				Method:    "PUT",
@@ -520,6 +863,8 @@ func TestLFSDomain_GenerateUploadURL(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, "https://example.com/upload", result.URL)
 		assert.Equal(t, "PUT", result.Method)
+		assert.NotEmpty(t, result.UploadID)
+		mockLFSStorage.AssertNotCalled(t, "GenerateUploadURL", mock.Anything, oid, mock.Anything, mock.Anything)
 	})
 }
 
@@ -530,7 +875,7 @@ func TestLFSDomain_GenerateDownloadURL(t *testing.T) {
 		mockLFSStorage := new(MockGitLFSStorage)
 
 		cfg := DefaultConfig()
-		domain := NewLFSDomain(nil, nil, mockLFSStorage, nil, cfg, logger)
+		domain := NewLFSDomain(nil, nil, mockLFSStorage, nil, nil, cfg, logger)
 
 		oid := "downloadurltest"
 		expiresAt := time.Now().Add(cfg.PresignedURLExpiry)
@@ -0,0 +1,428 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+// The merge engine works on the object storage of a bare repository. Trees
+// are merged path by path without a working copy; text files changed on
+// both sides are merged line by line and conflict only where the changes
+// overlap.
+
+// mergePlan describes how a source branch relates to its target branch.
+type mergePlan struct {
+	source *object.Commit
+	target *object.Commit
+	base   *object.Commit
+
+	// fastForward is set when the target is an ancestor of the source.
+	fastForward bool
+	// upToDate is set when the source is already part of the target.
+	upToDate bool
+}
+
+// openObjectStorage opens the object and ref storage of a repository.
+func openObjectStorage(fs billy.Filesystem) *filesystem.Storage {
+	return filesystem.NewStorage(fs, cache.NewObjectLRUDefault())
+}
+
+// branchCommit returns the commit a branch points to.
+func branchCommit(st *filesystem.Storage, branch string) (*object.Commit, error) {
+	return refCommit(st, plumbing.NewBranchReferenceName(branch))
+}
+
+// refCommit returns the commit a reference points to.
+func refCommit(st *filesystem.Storage, name plumbing.ReferenceName) (*object.Commit, error) {
+	ref, err := st.Reference(name)
+	if err != nil {
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			return nil, ErrBranchNotFound
+		}
+		return nil, fmt.Errorf("read ref %s: %w", name, err)
+	}
+
+	commit, err := object.GetCommit(st, ref.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("read commit %s: %w", ref.Hash(), err)
+	}
+	return commit, nil
+}
+
+// planMerge resolves the source ref, the target branch and their merge base.
+func planMerge(st *filesystem.Storage, sourceRef plumbing.ReferenceName, targetBranch string) (*mergePlan, error) {
+	source, err := refCommit(st, sourceRef)
+	if err != nil {
+		return nil, err
+	}
+	target, err := branchCommit(st, targetBranch)
+	if err != nil {
+		return nil, err
+	}
+	return newMergePlan(source, target)
+}
+
+// newMergePlan finds the merge base of a source and a target commit.
+func newMergePlan(source, target *object.Commit) (*mergePlan, error) {
+	bases, err := source.MergeBase(target)
+	if err != nil {
+		return nil, fmt.Errorf("find merge base: %w", err)
+	}
+	if len(bases) == 0 {
+		return nil, ErrUnrelatedHistories
+	}
+
+	return &mergePlan{
+		source:      source,
+		target:      target,
+		base:        bases[0],
+		fastForward: bases[0].Hash == target.Hash,
+		upToDate:    bases[0].Hash == source.Hash,
+	}, nil
+}
+
+// mergeHead writes the commit the target branch moves to and returns its
+// hash. Fast-forwards reuse the source commit.
+func mergeHead(st storage.Storer, plan *mergePlan, strategy model.GitMergeStrategy, sig object.Signature, message string) (plumbing.Hash, error) {
+	if strategy == model.GitMergeStrategyFastForward {
+		if !plan.fastForward {
+			return plumbing.ZeroHash, ErrFastForwardImpossible
+		}
+		return plan.source.Hash, nil
+	}
+
+	tree := plan.source.TreeHash
+	if !plan.fastForward {
+		merge, err := mergedEntries(plan)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		if len(merge.conflicts) > 0 {
+			return plumbing.ZeroHash, &MergeConflictError{Paths: merge.conflicts}
+		}
+		for _, content := range merge.blobs {
+			if _, err := storeBlob(st, content); err != nil {
+				return plumbing.ZeroHash, err
+			}
+		}
+		if tree, err = writeTree(st, merge.files); err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+
+	parents := []plumbing.Hash{plan.target.Hash}
+	if strategy == model.GitMergeStrategyMerge {
+		parents = append(parents, plan.source.Hash)
+	}
+	return writeCommit(st, tree, parents, sig, message)
+}
+
+// treeMerge is the result of merging the trees of a plan.
+type treeMerge struct {
+	// files holds the merged files by path, nil on conflicts.
+	files map[string]object.TreeEntry
+	// blobs holds the content of files merged line by line by hash. They
+	// aren't stored yet.
+	blobs map[plumbing.Hash][]byte
+	// conflicts lists the conflicting paths in order.
+	conflicts []string
+}
+
+// mergedEntries merges the files of the plan's commits.
+func mergedEntries(plan *mergePlan) (*treeMerge, error) {
+	base, err := commitEntries(plan.base)
+	if err != nil {
+		return nil, err
+	}
+	ours, err := commitEntries(plan.target)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := commitEntries(plan.source)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make(map[string]struct{}, len(ours)+len(theirs))
+	for p := range base {
+		paths[p] = struct{}{}
+	}
+	for p := range ours {
+		paths[p] = struct{}{}
+	}
+	for p := range theirs {
+		paths[p] = struct{}{}
+	}
+
+	merge := &treeMerge{
+		files: make(map[string]object.TreeEntry, len(paths)),
+		blobs: make(map[plumbing.Hash][]byte),
+	}
+	for p := range paths {
+		b, inBase := base[p]
+		o, inOurs := ours[p]
+		t, inTheirs := theirs[p]
+
+		var result object.TreeEntry
+		var keep bool
+		switch {
+		case sameEntry(o, inOurs, t, inTheirs):
+			result, keep = o, inOurs
+		case sameEntry(o, inOurs, b, inBase):
+			result, keep = t, inTheirs
+		case sameEntry(t, inTheirs, b, inBase):
+			result, keep = o, inOurs
+		case inBase && inOurs && inTheirs:
+			var ok bool
+			if result, ok, err = merge.mergeFile(plan, b, o, t); err != nil {
+				return nil, err
+			}
+			if !ok {
+				merge.conflicts = append(merge.conflicts, p)
+				continue
+			}
+			keep = true
+		default:
+			merge.conflicts = append(merge.conflicts, p)
+			continue
+		}
+		if keep {
+			merge.files[p] = result
+		}
+	}
+
+	// A file on one side may have become a directory on the other
+	merge.conflicts = append(merge.conflicts, pathCollisions(merge.files)...)
+
+	if len(merge.conflicts) > 0 {
+		sort.Strings(merge.conflicts)
+		merge.files, merge.blobs = nil, nil
+	}
+	return merge, nil
+}
+
+// mergeFile merges a file changed on both sides. Modes merge like entries;
+// text content is merged line by line. Symlinks, submodules and binary
+// files changed on both sides don't merge.
+func (m *treeMerge) mergeFile(plan *mergePlan, base, ours, theirs object.TreeEntry) (object.TreeEntry, bool, error) {
+	for _, entry := range []object.TreeEntry{base, ours, theirs} {
+		if entry.Mode != filemode.Regular && entry.Mode != filemode.Executable {
+			return object.TreeEntry{}, false, nil
+		}
+	}
+
+	result := ours
+	switch {
+	case ours.Mode == base.Mode:
+		result.Mode = theirs.Mode
+	case theirs.Mode != base.Mode && theirs.Mode != ours.Mode:
+		return object.TreeEntry{}, false, nil
+	}
+
+	switch {
+	case ours.Hash == theirs.Hash, theirs.Hash == base.Hash:
+		return result, true, nil
+	case ours.Hash == base.Hash:
+		result.Hash = theirs.Hash
+		return result, true, nil
+	}
+
+	var contents [3][]byte
+	for i, side := range []struct {
+		commit *object.Commit
+		entry  object.TreeEntry
+	}{{plan.base, base}, {plan.target, ours}, {plan.source, theirs}} {
+		content, err := blobContent(side.commit, side.entry)
+		if err != nil {
+			return object.TreeEntry{}, false, err
+		}
+		if isBinary(content) {
+			return object.TreeEntry{}, false, nil
+		}
+		contents[i] = content
+	}
+
+	merged, ok := mergeLines(string(contents[0]), string(contents[1]), string(contents[2]))
+	if !ok {
+		return object.TreeEntry{}, false, nil
+	}
+	result.Hash = plumbing.ComputeHash(plumbing.BlobObject, []byte(merged))
+	m.blobs[result.Hash] = []byte(merged)
+	return result, true, nil
+}
+
+// pathCollisions returns the files that sit below another file.
+func pathCollisions(files map[string]object.TreeEntry) []string {
+	var collisions []string
+	for p := range files {
+		for dir := parentDir(p); dir != ""; dir = parentDir(dir) {
+			if _, ok := files[dir]; ok {
+				collisions = append(collisions, p)
+				break
+			}
+		}
+	}
+	return collisions
+}
+
+func sameEntry(a object.TreeEntry, aExists bool, b object.TreeEntry, bExists bool) bool {
+	if !aExists || !bExists {
+		return aExists == bExists
+	}
+	return a.Hash == b.Hash && a.Mode == b.Mode
+}
+
+func parentDir(p string) string {
+	i := strings.LastIndex(p, "/")
+	if i < 0 {
+		return ""
+	}
+	return p[:i]
+}
+
+// commitEntries lists the non-directory entries of a commit's tree by path.
+func commitEntries(commit *object.Commit) (map[string]object.TreeEntry, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("read tree of %s: %w", commit.Hash, err)
+	}
+
+	entries := make(map[string]object.TreeEntry)
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+			return nil, fmt.Errorf("walk tree of %s: %w", commit.Hash, err)
+		}
+		if entry.Mode != filemode.Dir {
+			entries[name] = entry
+		}
+	}
+}
+
+// treeNode is a directory of a tree being written.
+type treeNode struct {
+	files map[string]object.TreeEntry
+	dirs  map[string]*treeNode
+}
+
+func newTreeNode() *treeNode {
+	return &treeNode{
+		files: make(map[string]object.TreeEntry),
+		dirs:  make(map[string]*treeNode),
+	}
+}
+
+// writeTree stores the tree holding the given files and returns its hash.
+func writeTree(st storage.Storer, files map[string]object.TreeEntry) (plumbing.Hash, error) {
+	root := newTreeNode()
+	for p, entry := range files {
+		node := root
+		parts := strings.Split(p, "/")
+		for _, dir := range parts[:len(parts)-1] {
+			child, ok := node.dirs[dir]
+			if !ok {
+				child = newTreeNode()
+				node.dirs[dir] = child
+			}
+			node = child
+		}
+		entry.Name = parts[len(parts)-1]
+		node.files[entry.Name] = entry
+	}
+	return writeTreeNode(st, root)
+}
+
+func writeTreeNode(st storage.Storer, node *treeNode) (plumbing.Hash, error) {
+	entries := make([]object.TreeEntry, 0, len(node.files)+len(node.dirs))
+	for _, entry := range node.files {
+		entries = append(entries, entry)
+	}
+	for name, child := range node.dirs {
+		hash, err := writeTreeNode(st, child)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		entries = append(entries, object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: hash})
+	}
+
+	// Git orders directories as if their names ended with a slash
+	sort.Slice(entries, func(i, j int) bool {
+		return treeSortKey(entries[i]) < treeSortKey(entries[j])
+	})
+
+	return storeObject(st, &object.Tree{Entries: entries})
+}
+
+func treeSortKey(entry object.TreeEntry) string {
+	if entry.Mode == filemode.Dir {
+		return entry.Name + "/"
+	}
+	return entry.Name
+}
+
+// writeCommit stores a commit and returns its hash.
+func writeCommit(st storage.Storer, tree plumbing.Hash, parents []plumbing.Hash, sig object.Signature, message string) (plumbing.Hash, error) {
+	return storeObject(st, &object.Commit{
+		Author:       sig,
+		Committer:    sig,
+		Message:      message,
+		TreeHash:     tree,
+		ParentHashes: parents,
+	})
+}
+
+func storeObject(st storage.Storer, obj interface {
+	Encode(plumbing.EncodedObject) error
+}) (plumbing.Hash, error) {
+	encoded := st.NewEncodedObject()
+	if err := obj.Encode(encoded); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("encode object: %w", err)
+	}
+	hash, err := st.SetEncodedObject(encoded)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("store object: %w", err)
+	}
+	return hash, nil
+}
+
+// updateBranch moves a branch from old to new, failing if it no longer
+// points to old. A zero old hash creates the branch.
+func updateBranch(st storage.Storer, branch string, old, new plumbing.Hash) error {
+	name := plumbing.NewBranchReferenceName(branch)
+
+	var oldRef *plumbing.Reference
+	if old.IsZero() {
+		if _, err := st.Reference(name); err == nil {
+			return ErrTargetBranchMoved
+		}
+	} else {
+		oldRef = plumbing.NewHashReference(name, old)
+	}
+
+	err := st.CheckAndSetReference(plumbing.NewHashReference(name, new), oldRef)
+	if errors.Is(err, storage.ErrReferenceHasChanged) {
+		return ErrTargetBranchMoved
+	}
+	if err != nil {
+		return fmt.Errorf("update branch %s: %w", branch, err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// binarySniffLen is how much of a blob is searched for a NUL byte to tell
+// binary files from text, as git does.
+const binarySniffLen = 8000
+
+// hunk replaces the base lines [start, end) with lines.
+type hunk struct {
+	start, end int
+	lines      []string
+}
+
+// mergeLines merges the changes ours and theirs made to base line by line.
+// Changes that overlap or touch are a conflict unless both sides made the
+// same change.
+func mergeLines(base, ours, theirs string) (string, bool) {
+	baseLines := splitLines(base)
+	a := lineHunks(base, ours)
+	b := lineHunks(base, theirs)
+
+	var out strings.Builder
+	pos, i, j := 0, 0, 0
+	for i < len(a) || j < len(b) {
+		// Start a region at the earliest hunk and grow it over every hunk
+		// of either side that reaches into it
+		var start, end int
+		if j == len(b) || (i < len(a) && a[i].start <= b[j].start) {
+			start, end = a[i].start, a[i].end
+		} else {
+			start, end = b[j].start, b[j].end
+		}
+		ai, bj := i, j
+		for grown := true; grown; {
+			grown = false
+			for ; ai < len(a) && a[ai].start <= end; ai++ {
+				end, grown = max(end, a[ai].end), true
+			}
+			for ; bj < len(b) && b[bj].start <= end; bj++ {
+				end, grown = max(end, b[bj].end), true
+			}
+		}
+
+		writeLines(&out, baseLines[pos:start])
+		switch {
+		case bj == j:
+			out.WriteString(applyHunks(baseLines, start, end, a[i:ai]))
+		case ai == i:
+			out.WriteString(applyHunks(baseLines, start, end, b[j:bj]))
+		default:
+			oursText := applyHunks(baseLines, start, end, a[i:ai])
+			if oursText != applyHunks(baseLines, start, end, b[j:bj]) {
+				return "", false
+			}
+			out.WriteString(oursText)
+		}
+		pos, i, j = end, ai, bj
+	}
+	writeLines(&out, baseLines[pos:])
+
+	return out.String(), true
+}
+
+// lineHunks returns the changes that turn base into other.
+func lineHunks(base, other string) []hunk {
+	var hunks []hunk
+	var cur *hunk
+	pos := 0
+	for _, d := range diff.Do(base, other) {
+		lines := splitLines(d.Text)
+		if d.Type == diffmatchpatch.DiffEqual {
+			if cur != nil {
+				hunks = append(hunks, *cur)
+				cur = nil
+			}
+			pos += len(lines)
+			continue
+		}
+
+		if cur == nil {
+			cur = &hunk{start: pos, end: pos}
+		}
+		if d.Type == diffmatchpatch.DiffDelete {
+			pos += len(lines)
+			cur.end = pos
+		} else {
+			cur.lines = append(cur.lines, lines...)
+		}
+	}
+	if cur != nil {
+		hunks = append(hunks, *cur)
+	}
+	return hunks
+}
+
+// applyHunks returns the base lines [start, end) with hunks applied.
+func applyHunks(base []string, start, end int, hunks []hunk) string {
+	var out strings.Builder
+	pos := start
+	for _, h := range hunks {
+		writeLines(&out, base[pos:h.start])
+		writeLines(&out, h.lines)
+		pos = h.end
+	}
+	writeLines(&out, base[pos:end])
+	return out.String()
+}
+
+// splitLines splits s after each newline. A last line without a newline is
+// kept as is.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, line := range lines {
+		out.WriteString(line)
+	}
+}
+
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0
+}
+
+// blobContent reads the content of a file of a commit's tree.
+func blobContent(commit *object.Commit, entry object.TreeEntry) ([]byte, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("read tree of %s: %w", commit.Hash, err)
+	}
+	file, err := tree.TreeEntryFile(&entry)
+	if err != nil {
+		return nil, fmt.Errorf("read blob %s: %w", entry.Hash, err)
+	}
+	r, err := file.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("read blob %s: %w", entry.Hash, err)
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("read blob %s: %w", entry.Hash, err)
+	}
+	return content, nil
+}
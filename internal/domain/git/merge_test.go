@@ -0,0 +1,329 @@
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+// --- Test repository ---
+
+// testRepo is an in-memory bare repository for merge tests.
+type testRepo struct {
+	t  *testing.T
+	fs billy.Filesystem
+	st *filesystem.Storage
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	fs := memfs.New()
+	return &testRepo{t: t, fs: fs, st: openObjectStorage(fs)}
+}
+
+// commit writes a commit holding files on top of parents and points branch
+// at it.
+func (r *testRepo) commit(branch string, files map[string]string, parents ...plumbing.Hash) plumbing.Hash {
+	entries := make(map[string]object.TreeEntry, len(files))
+	for p, content := range files {
+		blob := r.st.NewEncodedObject()
+		blob.SetType(plumbing.BlobObject)
+		w, err := blob.Writer()
+		require.NoError(r.t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(r.t, err)
+		require.NoError(r.t, w.Close())
+
+		hash, err := r.st.SetEncodedObject(blob)
+		require.NoError(r.t, err)
+		entries[p] = object.TreeEntry{Mode: filemode.Regular, Hash: hash}
+	}
+
+	tree, err := writeTree(r.st, entries)
+	require.NoError(r.t, err)
+	hash, err := writeCommit(r.st, tree, parents, object.Signature{Name: "test", When: time.Now()}, "commit\n")
+	require.NoError(r.t, err)
+
+	r.setBranch(branch, hash)
+	return hash
+}
+
+func (r *testRepo) setBranch(branch string, hash plumbing.Hash) {
+	ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(branch), hash)
+	require.NoError(r.t, r.st.SetReference(ref))
+}
+
+func (r *testRepo) branch(branch string) *object.Commit {
+	commit, err := branchCommit(r.st, branch)
+	require.NoError(r.t, err)
+	return commit
+}
+
+// files returns the contents of a commit's files by path.
+func (r *testRepo) files(commit *object.Commit) map[string]string {
+	tree, err := commit.Tree()
+	require.NoError(r.t, err)
+
+	files := make(map[string]string)
+	require.NoError(r.t, tree.Files().ForEach(func(f *object.File) error {
+		content, err := f.Contents()
+		files[f.Name] = content
+		return err
+	}))
+	return files
+}
+
+// --- Merge engine tests ---
+
+func TestPlanMerge(t *testing.T) {
+	t.Run("fast_forward", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a": "1"})
+		repo.commit("feature", map[string]string{"a": "2"}, base)
+
+		plan, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+
+		require.NoError(t, err)
+		assert.True(t, plan.fastForward)
+		assert.False(t, plan.upToDate)
+		assert.Equal(t, base, plan.base.Hash)
+	})
+
+	t.Run("diverged", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a": "1"})
+		repo.commit("feature", map[string]string{"a": "2"}, base)
+		repo.commit("main", map[string]string{"a": "1", "b": "1"}, base)
+
+		plan, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+
+		require.NoError(t, err)
+		assert.False(t, plan.fastForward)
+		assert.False(t, plan.upToDate)
+	})
+
+	t.Run("up_to_date", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a": "1"})
+		repo.setBranch("feature", base)
+		repo.commit("main", map[string]string{"a": "2"}, base)
+
+		plan, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+
+		require.NoError(t, err)
+		assert.True(t, plan.upToDate)
+	})
+
+	t.Run("missing_branch", func(t *testing.T) {
+		repo := newTestRepo(t)
+		repo.commit("main", map[string]string{"a": "1"})
+
+		_, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+
+		assert.ErrorIs(t, err, ErrBranchNotFound)
+	})
+
+	t.Run("unrelated_histories", func(t *testing.T) {
+		repo := newTestRepo(t)
+		repo.commit("main", map[string]string{"a": "1"})
+		repo.commit("feature", map[string]string{"b": "1"})
+
+		_, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+
+		assert.ErrorIs(t, err, ErrUnrelatedHistories)
+	})
+}
+
+func TestMergedEntries(t *testing.T) {
+	t.Run("independent_changes", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a": "1", "b": "1", "dir/c": "1", "gone": "1"})
+		repo.commit("feature", map[string]string{"a": "2", "b": "1", "dir/c": "1", "gone": "1", "dir/new": "1"}, base)
+		repo.commit("main", map[string]string{"a": "1", "b": "2", "dir/c": "1"}, base)
+
+		plan, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+		require.NoError(t, err)
+		merge, err := mergedEntries(plan)
+		require.NoError(t, err)
+		assert.Empty(t, merge.conflicts)
+
+		tree, err := writeTree(repo.st, merge.files)
+		require.NoError(t, err)
+		head, err := writeCommit(repo.st, tree, nil, object.Signature{Name: "test"}, "merge\n")
+		require.NoError(t, err)
+		commit, err := object.GetCommit(repo.st, head)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]string{"a": "2", "b": "2", "dir/c": "1", "dir/new": "1"}, repo.files(commit))
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"both": "1", "deleted": "1", "ok": "1"})
+		repo.commit("feature", map[string]string{"both": "2", "deleted": "2", "ok": "2", "x/y": "1"}, base)
+		repo.commit("main", map[string]string{"both": "3", "ok": "1", "x": "1"}, base)
+
+		plan, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+		require.NoError(t, err)
+		merge, err := mergedEntries(plan)
+
+		require.NoError(t, err)
+		assert.Nil(t, merge.files)
+		assert.Equal(t, []string{"both", "deleted", "x/y"}, merge.conflicts)
+	})
+
+	t.Run("non_overlapping_edits_to_one_file", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"f": "1\n2\n3\n4\n5\n"})
+		repo.commit("feature", map[string]string{"f": "one\n2\n3\n4\n5\n"}, base)
+		repo.commit("main", map[string]string{"f": "1\n2\n3\n4\nfive\n"}, base)
+
+		plan, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+		require.NoError(t, err)
+		merge, err := mergedEntries(plan)
+		require.NoError(t, err)
+		assert.Empty(t, merge.conflicts)
+
+		entry := merge.files["f"]
+		assert.Equal(t, filemode.Regular, entry.Mode)
+		assert.Equal(t, "one\n2\n3\n4\nfive\n", string(merge.blobs[entry.Hash]))
+	})
+
+	t.Run("overlapping_edits_conflict", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"f": "1\n2\n3\n"})
+		repo.commit("feature", map[string]string{"f": "1\ntwo\n3\n"}, base)
+		repo.commit("main", map[string]string{"f": "1\nTWO\n3\n"}, base)
+
+		plan, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+		require.NoError(t, err)
+		merge, err := mergedEntries(plan)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"f"}, merge.conflicts)
+	})
+
+	t.Run("binary_files_conflict", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"f": "1\n\x00\n3\n"})
+		repo.commit("feature", map[string]string{"f": "one\n\x00\n3\n"}, base)
+		repo.commit("main", map[string]string{"f": "1\n\x00\nthree\n"}, base)
+
+		plan, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+		require.NoError(t, err)
+		merge, err := mergedEntries(plan)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"f"}, merge.conflicts)
+	})
+}
+
+func TestMergeLines(t *testing.T) {
+	tests := []struct {
+		name               string
+		base, ours, theirs string
+		want               string
+		conflict           bool
+	}{
+		{name: "separate_lines", base: "a\nb\nc\n", ours: "A\nb\nc\n", theirs: "a\nb\nC\n", want: "A\nb\nC\n"},
+		{name: "insertions", base: "a\nb\nc\n", ours: "x\na\nb\nc\n", theirs: "a\nb\nc\ny\n", want: "x\na\nb\nc\ny\n"},
+		{name: "deletion_and_edit", base: "a\nb\nc\nd\n", ours: "b\nc\nd\n", theirs: "a\nb\nc\nD\n", want: "b\nc\nD\n"},
+		{name: "same_change", base: "a\nb\nc\n", ours: "a\nB\nc\n", theirs: "a\nB\nc\n", want: "a\nB\nc\n"},
+		{name: "missing_final_newline", base: "a\nb\nc", ours: "A\nb\nc", theirs: "a\nb\nC", want: "A\nb\nC"},
+		{name: "overlapping", base: "a\nb\nc\n", ours: "a\nB\nc\n", theirs: "a\nX\nc\n", conflict: true},
+		{name: "adjacent", base: "a\nb\nc\n", ours: "A\nb\nc\n", theirs: "a\nB\nc\n", conflict: true},
+		{name: "insertions_at_same_line", base: "a\nb\n", ours: "a\nx\nb\n", theirs: "a\ny\nb\n", conflict: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := mergeLines(tt.base, tt.ours, tt.theirs)
+
+			assert.Equal(t, !tt.conflict, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWriteTree_EntryOrder(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit("main", map[string]string{"a.txt": "1", "a/b": "1", "a-b": "1"})
+
+	tree, err := repo.branch("main").Tree()
+	require.NoError(t, err)
+
+	names := make([]string, len(tree.Entries))
+	for i, entry := range tree.Entries {
+		names[i] = entry.Name
+	}
+	assert.Equal(t, []string{"a-b", "a.txt", "a"}, names)
+}
+
+func TestMergeHead(t *testing.T) {
+	sig := object.Signature{Name: "test", When: time.Now()}
+
+	t.Run("fast_forward_impossible", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a": "1"})
+		repo.commit("feature", map[string]string{"a": "2"}, base)
+		repo.commit("main", map[string]string{"a": "1", "b": "1"}, base)
+
+		plan, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+		require.NoError(t, err)
+		_, err = mergeHead(repo.st, plan, model.GitMergeStrategyFastForward, sig, "")
+
+		assert.ErrorIs(t, err, ErrFastForwardImpossible)
+	})
+
+	t.Run("merge_commit_over_fast_forward", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a": "1"})
+		source := repo.commit("feature", map[string]string{"a": "2"}, base)
+
+		plan, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+		require.NoError(t, err)
+		head, err := mergeHead(repo.st, plan, model.GitMergeStrategyMerge, sig, "merge\n")
+		require.NoError(t, err)
+
+		commit, err := object.GetCommit(repo.st, head)
+		require.NoError(t, err)
+		assert.Equal(t, []plumbing.Hash{base, source}, commit.ParentHashes)
+		assert.Equal(t, plan.source.TreeHash, commit.TreeHash)
+	})
+
+	t.Run("stores_line_merged_files", func(t *testing.T) {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"f": "1\n2\n3\n"})
+		repo.commit("feature", map[string]string{"f": "one\n2\n3\n"}, base)
+		repo.commit("main", map[string]string{"f": "1\n2\nthree\n"}, base)
+
+		plan, err := planMerge(repo.st, plumbing.NewBranchReferenceName("feature"), "main")
+		require.NoError(t, err)
+		head, err := mergeHead(repo.st, plan, model.GitMergeStrategyMerge, sig, "merge\n")
+		require.NoError(t, err)
+
+		commit, err := object.GetCommit(repo.st, head)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"f": "one\n2\nthree\n"}, repo.files(commit))
+	})
+}
+
+func TestUpdateBranch(t *testing.T) {
+	repo := newTestRepo(t)
+	first := repo.commit("main", map[string]string{"a": "1"})
+	second := repo.commit("main", map[string]string{"a": "2"}, first)
+
+	err := updateBranch(repo.st, "main", first, first)
+	assert.ErrorIs(t, err, ErrTargetBranchMoved)
+
+	require.NoError(t, updateBranch(repo.st, "main", second, first))
+	assert.Equal(t, first, repo.branch("main").Hash)
+}
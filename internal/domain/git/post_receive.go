@@ -0,0 +1,385 @@
+package git
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// maxPushScanCommits bounds the commits of one push that are inspected
+// before and after it lands. Larger pushes are rejected; after a push lands
+// only the first commits are inspected.
+const maxPushScanCommits = 1000
+
+// ===== Post-Receive Processing =====
+
+// ProcessPush runs the bookkeeping that follows an applied push: it records
+// the push time, links the LFS objects the new commits point to, recomputes
+// the repository size, closes the pull requests whose source branch was
+// deleted and publishes a RepoPushedEvent. updates lists the ref updates
+// that were applied. The refs have already moved, so every step runs even
+// when an earlier one fails; failures are logged.
+func (d *Domain) ProcessPush(ctx context.Context, repoID, pusherID uuid.UUID, updates []*inbound.GitRefUpdate) error {
+	// The push has landed even if the client hangs up now
+	ctx = context.WithoutCancel(ctx)
+
+	repo, err := d.repoDB.FindByID(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	if repo == nil {
+		return ErrRepoNotFound
+	}
+
+	logger := d.logger.With(zap.String("repo_id", repoID.String()))
+
+	if err := d.UpdatePushedAt(ctx, repoID); err != nil {
+		logger.Warn("failed to record push time", zap.Error(err))
+	}
+	if err := d.linkPushedLFSObjects(ctx, repo, updates); err != nil {
+		logger.Warn("failed to link pushed LFS objects", zap.Error(err))
+	}
+	if err := d.refreshRepoSize(ctx, repo); err != nil {
+		logger.Warn("failed to update repository size", zap.Error(err))
+	}
+	if err := d.closeDeletedBranchPRs(ctx, repo, updates); err != nil {
+		logger.Warn("failed to close pull requests of deleted branches", zap.Error(err))
+	}
+
+	if d.events != nil && len(updates) > 0 {
+		event := &RepoPushedEvent{
+			RepoID:   repo.ID,
+			OwnerID:  repo.OwnerID,
+			PusherID: pusherID,
+			Refs:     updates,
+		}
+		if err := d.events.Publish(ctx, event); err != nil {
+			logger.Warn("failed to publish push event", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// linkPushedLFSObjects links the repository to the LFS objects that pointer
+// files of the pushed commits refer to. Only objects that were uploaded are
+// linked; pointers to missing objects are left for a later upload.
+func (d *Domain) linkPushedLFSObjects(ctx context.Context, repo *model.GitRepo, updates []*inbound.GitRefUpdate) error {
+	if d.lfsObjDB == nil {
+		return nil
+	}
+
+	fs, err := d.storage.GetFilesystem(ctx, repo.StoragePath)
+	if err != nil {
+		return err
+	}
+	oids, err := pushedLFSPointers(ctx, openObjectStorage(fs), updates)
+	if err != nil {
+		return err
+	}
+
+	for _, oid := range oids {
+		obj, err := d.lfsObjDB.FindByOID(ctx, oid)
+		if err != nil {
+			return fmt.Errorf("find LFS object %s: %w", oid, err)
+		}
+		if obj == nil {
+			continue
+		}
+		if err := d.lfsObjDB.Link(ctx, repo.ID, oid); err != nil {
+			return fmt.Errorf("link LFS object %s: %w", oid, err)
+		}
+	}
+	return nil
+}
+
+// refreshRepoSize recomputes the git and LFS sizes of a repository.
+func (d *Domain) refreshRepoSize(ctx context.Context, repo *model.GitRepo) error {
+	size, err := d.storage.GetRepositorySize(ctx, repo.StoragePath)
+	if err != nil {
+		return fmt.Errorf("get repository size: %w", err)
+	}
+
+	lfsSize := repo.LFSSizeBytes
+	if d.lfsObjDB != nil {
+		if lfsSize, err = d.lfsObjDB.GetRepoLFSSize(ctx, repo.ID); err != nil {
+			return fmt.Errorf("get LFS size: %w", err)
+		}
+	}
+
+	return d.repoDB.UpdateSize(ctx, repo.ID, size, lfsSize)
+}
+
+// closeDeletedBranchPRs closes the open pull requests, including those
+// opened against upstream from a fork, whose source branch a push deleted.
+func (d *Domain) closeDeletedBranchPRs(ctx context.Context, repo *model.GitRepo, updates []*inbound.GitRefUpdate) error {
+	for _, update := range updates {
+		name := plumbing.ReferenceName(update.Ref)
+		if !name.IsBranch() || !plumbing.NewHash(update.NewSHA).IsZero() {
+			continue
+		}
+
+		prs, err := d.prDB.FindOpenBySourceBranch(ctx, repo.ID, name.Short())
+		if err != nil {
+			return fmt.Errorf("find pull requests: %w", err)
+		}
+		for _, pr := range prs {
+			now := time.Now()
+			pr.Status = model.GitPRStatusClosed
+			pr.ClosedAt = &now
+			if err := d.prDB.Update(ctx, pr); err != nil {
+				return fmt.Errorf("close pull request: %w", err)
+			}
+
+			d.logger.Info("pull request closed, source branch deleted",
+				zap.String("repo_id", pr.RepoID.String()),
+				zap.Int("number", pr.Number),
+			)
+		}
+	}
+	return nil
+}
+
+// pushedLFSPointers returns the oids of the LFS pointers added or changed by
+// the commits a push introduced.
+func pushedLFSPointers(ctx context.Context, st *filesystem.Storage, updates []*inbound.GitRefUpdate) ([]string, error) {
+	found := make(map[string]bool)
+	var oids []string
+	err := walkPushedCommits(st, updates, func(commit *object.Commit) error {
+		changes, err := commitChanges(ctx, commit)
+		if err != nil {
+			return err
+		}
+		pointers, err := lfsPointers(st, changes)
+		if err != nil {
+			return err
+		}
+		for _, oid := range pointers {
+			if !found[oid] {
+				found[oid] = true
+				oids = append(oids, oid)
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, ErrPushTooLarge) {
+		// The push has landed; link what was found
+		err = nil
+	}
+	return oids, err
+}
+
+// walkPushedCommits calls fn with each commit a push introduced. The walk
+// skips the commits reachable from the old tips of the updated refs and from
+// the tips of every other ref, which were checked when they were pushed. It
+// fails with ErrPushTooLarge when more than maxPushScanCommits commits are
+// new.
+func walkPushedCommits(st *filesystem.Storage, updates []*inbound.GitRefUpdate, fn func(*object.Commit) error) error {
+	known, err := newKnownCommits(st, updates)
+	if err != nil {
+		return err
+	}
+
+	var queue []plumbing.Hash
+	for _, update := range updates {
+		if h := plumbing.NewHash(update.NewSHA); !h.IsZero() {
+			queue = append(queue, h)
+		}
+	}
+
+	seen := make(map[plumbing.Hash]bool)
+	scanned := 0
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if seen[hash] {
+			continue
+		}
+		seen[hash] = true
+
+		commit, err := object.GetCommit(st, hash)
+		if err != nil {
+			// Tags may point at trees and blobs
+			continue
+		}
+		reachable, err := known.reachable(commit)
+		if err != nil {
+			return err
+		}
+		if reachable {
+			continue
+		}
+		if scanned == maxPushScanCommits {
+			return ErrPushTooLarge
+		}
+		scanned++
+
+		if err := fn(commit); err != nil {
+			return err
+		}
+		queue = append(queue, commit.ParentHashes...)
+	}
+	return nil
+}
+
+// knownCommits tells whether commits were part of the repository before a
+// push: reachable from the old tips of the updated refs or from the tips of
+// the others. History is walked from those tips newest first, only as far
+// back as the commits asked about, so clock skew can make a known commit
+// look new but never the reverse.
+type knownCommits struct {
+	st       storer.EncodedObjectStorer
+	reached  map[plumbing.Hash]bool
+	frontier commitHeap
+}
+
+func newKnownCommits(st *filesystem.Storage, updates []*inbound.GitRefUpdate) (*knownCommits, error) {
+	k := &knownCommits{st: st, reached: make(map[plumbing.Hash]bool)}
+
+	updated := make(map[plumbing.ReferenceName]bool)
+	for _, update := range updates {
+		updated[plumbing.ReferenceName(update.Ref)] = true
+		if h := plumbing.NewHash(update.OldSHA); !h.IsZero() {
+			k.addTip(h)
+		}
+	}
+
+	refs, err := st.IterReferences()
+	if err != nil {
+		return nil, fmt.Errorf("list references: %w", err)
+	}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() == plumbing.HashReference && !updated[ref.Name()] {
+			k.addTip(ref.Hash())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list references: %w", err)
+	}
+	return k, nil
+}
+
+// addTip marks a ref tip as known and queues the commit it points to.
+func (k *knownCommits) addTip(h plumbing.Hash) {
+	if k.reached[h] {
+		return
+	}
+	k.reached[h] = true
+
+	commit, err := object.GetCommit(k.st, h)
+	if err != nil {
+		tag, tagErr := object.GetTag(k.st, h)
+		if tagErr != nil {
+			return
+		}
+		if commit, err = tag.Commit(); err != nil {
+			// Tags may point at trees and blobs
+			return
+		}
+		k.reached[commit.Hash] = true
+	}
+	heap.Push(&k.frontier, commit)
+}
+
+// reachable reports whether commit is reachable from a known tip, walking
+// the known history down to the commit's date.
+func (k *knownCommits) reachable(commit *object.Commit) (bool, error) {
+	for k.frontier.Len() > 0 && !k.frontier[0].Committer.When.Before(commit.Committer.When) {
+		next := heap.Pop(&k.frontier).(*object.Commit)
+		for _, h := range next.ParentHashes {
+			if k.reached[h] {
+				continue
+			}
+			k.reached[h] = true
+			parent, err := object.GetCommit(k.st, h)
+			if err != nil {
+				return false, fmt.Errorf("read commit %s: %w", h, err)
+			}
+			heap.Push(&k.frontier, parent)
+		}
+	}
+	return k.reached[commit.Hash], nil
+}
+
+// commitHeap orders commits newest first.
+type commitHeap []*object.Commit
+
+func (h commitHeap) Len() int { return len(h) }
+func (h commitHeap) Less(i, j int) bool {
+	return h[i].Committer.When.After(h[j].Committer.When)
+}
+func (h commitHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *commitHeap) Push(x any)   { *h = append(*h, x.(*object.Commit)) }
+func (h *commitHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// commitChanges returns the files a commit changes relative to its first
+// parent.
+func commitChanges(ctx context.Context, commit *object.Commit) (object.Changes, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("read tree of %s: %w", commit.Hash, err)
+	}
+
+	var parentTree *object.Tree
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("read parent of %s: %w", commit.Hash, err)
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, fmt.Errorf("read tree of %s: %w", parent.Hash, err)
+		}
+	}
+
+	changes, err := object.DiffTreeWithOptions(ctx, parentTree, tree, nil)
+	if err != nil {
+		return nil, fmt.Errorf("diff %s: %w", commit.Hash, err)
+	}
+	return changes, nil
+}
+
+// lfsPointers returns the oids of the LFS pointers that changes add or
+// modify.
+func lfsPointers(st storer.EncodedObjectStorer, changes object.Changes) ([]string, error) {
+	var oids []string
+	for _, change := range changes {
+		entry := change.To.TreeEntry
+		if change.To.Name == "" || !entry.Mode.IsFile() {
+			continue
+		}
+
+		blob, err := object.GetBlob(st, entry.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("read blob %s: %w", entry.Hash, err)
+		}
+		if blob.Size > lfsPointerMaxSize {
+			continue
+		}
+		data, err := readBlob(blob)
+		if err != nil {
+			return nil, err
+		}
+		if oid, ok := parseLFSPointer(data); ok {
+			oids = append(oids, oid)
+		}
+	}
+	return oids, nil
+}
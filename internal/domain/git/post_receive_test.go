@@ -0,0 +1,115 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+func testLFSPointer(oid string) string {
+	return fmt.Sprintf("%s\noid sha256:%s\nsize 12\n", lfsPointerVersion, oid)
+}
+
+func TestDomain_ProcessPush(t *testing.T) {
+	// The client disconnected once the refs were updated
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	oldOID, newOID, missingOID := strings.Repeat("a", 64), strings.Repeat("b", 64), strings.Repeat("c", 64)
+
+	repo := newTestRepo(t)
+	base := repo.commit("main", map[string]string{"old.bin": testLFSPointer(oldOID)})
+	next := repo.commit("main", map[string]string{
+		"old.bin":     testLFSPointer(oldOID),
+		"new.bin":     testLFSPointer(newOID),
+		"missing.bin": testLFSPointer(missingOID),
+		"README.md":   "hello",
+	}, base)
+
+	repoDB := new(MockGitRepoDB)
+	prDB := new(MockGitPRDB)
+	lfsObjDB := new(MockGitLFSObjDB)
+	storage := new(MockGitStorage)
+	events := new(MockEventPublisher)
+	domain := NewDomain(
+		repoDB,
+		nil,
+		prDB,
+		nil,
+		nil,
+		lfsObjDB,
+		nil,
+		storage,
+		nil,
+		nil,
+		nil,
+		events,
+		nil,
+		zap.NewNop(),
+	)
+
+	gitRepo := &model.GitRepo{ID: uuid.New(), OwnerID: uuid.New(), StoragePath: "repos/test"}
+	pusher := uuid.New()
+	pr := &model.GitPullRequest{ID: uuid.New(), RepoID: uuid.New(), Number: 3, Status: model.GitPRStatusOpen, SourceBranch: "feature"}
+	updates := []*inbound.GitRefUpdate{
+		{Ref: "refs/heads/main", OldSHA: base.String(), NewSHA: next.String()},
+		{Ref: "refs/heads/feature", OldSHA: base.String(), NewSHA: plumbing.ZeroHash.String()},
+	}
+
+	repoDB.On("FindByID", mock.Anything, gitRepo.ID).Return(gitRepo, nil)
+	repoDB.On("UpdatePushedAt", mock.Anything, gitRepo.ID, mock.Anything).Return(nil)
+	repoDB.On("UpdateSize", mock.Anything, gitRepo.ID, int64(4096), int64(12)).Return(nil)
+	storage.On("GetFilesystem", mock.Anything, "repos/test").Return(repo.fs, nil)
+	storage.On("GetRepositorySize", mock.Anything, "repos/test").Return(int64(4096), nil)
+	lfsObjDB.On("FindByOID", mock.Anything, newOID).Return(&model.GitLFSObject{OID: newOID, Size: 12}, nil)
+	lfsObjDB.On("FindByOID", mock.Anything, missingOID).Return(nil, nil)
+	lfsObjDB.On("Link", mock.Anything, gitRepo.ID, newOID).Return(nil)
+	lfsObjDB.On("GetRepoLFSSize", mock.Anything, gitRepo.ID).Return(int64(12), nil)
+	prDB.On("FindOpenBySourceBranch", mock.Anything, gitRepo.ID, "feature").Return([]*model.GitPullRequest{pr}, nil)
+	prDB.On("Update", mock.Anything, pr).Return(nil)
+	events.On("Publish", mock.Anything, &RepoPushedEvent{
+		RepoID:   gitRepo.ID,
+		OwnerID:  gitRepo.OwnerID,
+		PusherID: pusher,
+		Refs:     updates,
+	}).Return(nil)
+
+	err := domain.ProcessPush(ctx, gitRepo.ID, pusher, updates)
+
+	require.NoError(t, err)
+	repoDB.AssertExpectations(t)
+	lfsObjDB.AssertExpectations(t)
+	lfsObjDB.AssertNotCalled(t, "FindByOID", mock.Anything, oldOID)
+	lfsObjDB.AssertNotCalled(t, "Link", mock.Anything, gitRepo.ID, missingOID)
+	events.AssertExpectations(t)
+	assert.Equal(t, model.GitPRStatusClosed, pr.Status)
+	assert.NotNil(t, pr.ClosedAt)
+}
+
+func TestPushedLFSPointers_StopsAtKnownCommits(t *testing.T) {
+	oldOID, newOID := strings.Repeat("a", 64), strings.Repeat("b", 64)
+
+	repo := newTestRepo(t)
+	base := repo.commit("main", map[string]string{"old.bin": testLFSPointer(oldOID)})
+	topic := repo.commit("topic", map[string]string{
+		"old.bin": testLFSPointer(oldOID),
+		"new.bin": testLFSPointer(newOID),
+	}, base)
+
+	oids, err := pushedLFSPointers(context.Background(), repo.st, []*inbound.GitRefUpdate{
+		{Ref: "refs/heads/topic", OldSHA: plumbing.ZeroHash.String(), NewSHA: topic.String()},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{newOID}, oids, "main was scanned when it was pushed")
+}
@@ -0,0 +1,125 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// ===== Pre-Receive Checks =====
+
+// LimitPushPack bounds the pack of a push to the git storage the repository
+// owner has left: reading past it fails with ErrStorageQuotaExceeded, so an
+// oversized pack is never stored. Quota lookups that fail don't block the
+// push.
+func (d *Domain) LimitPushPack(ctx context.Context, repoID uuid.UUID, pack io.ReadCloser) (io.ReadCloser, error) {
+	repo, err := d.repoDB.FindByID(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	if repo == nil {
+		return nil, ErrRepoNotFound
+	}
+
+	remaining, ok := d.remainingStorage(ctx, repo.OwnerID)
+	if !ok {
+		return pack, nil
+	}
+	return &quotaReader{ReadCloser: pack, remaining: remaining}, nil
+}
+
+// remainingStorage returns the git storage an owner has left, or false when
+// it is unlimited or unknown.
+func (d *Domain) remainingStorage(ctx context.Context, ownerID uuid.UUID) (int64, bool) {
+	if d.quotaChecker == nil {
+		return 0, false
+	}
+
+	quota, err := d.quotaChecker.GetStorageQuota(ctx, ownerID)
+	if err != nil {
+		d.logger.Warn("failed to get storage quota", zap.Error(err))
+		return 0, false
+	}
+	if quota < 0 {
+		return 0, false
+	}
+
+	used, err := d.quotaChecker.GetStorageUsed(ctx, ownerID)
+	if err != nil {
+		d.logger.Warn("failed to get storage used", zap.Error(err))
+		return 0, false
+	}
+	return max(quota-used, 0), true
+}
+
+// quotaReader fails once more than remaining bytes have been read.
+type quotaReader struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (r *quotaReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, ErrStorageQuotaExceeded
+	}
+	return n, err
+}
+
+// CheckPushContent reports whether the commits one ref update of a push
+// introduces respect the repository's content policy: no file larger than
+// the configured limit outside LFS, and no change to a file in locks, the
+// LFS locks other users hold. The pushed objects must already be stored.
+func (d *Domain) CheckPushContent(ctx context.Context, repoID uuid.UUID, update *inbound.GitRefUpdate, locks []*model.GitLFSLock) error {
+	if plumbing.NewHash(update.NewSHA).IsZero() {
+		return nil
+	}
+
+	fs, err := d.GetFilesystem(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	st := openObjectStorage(fs)
+
+	locked := make(map[string]bool, len(locks))
+	for _, lock := range locks {
+		locked[lock.Path] = true
+	}
+
+	return walkPushedCommits(st, []*inbound.GitRefUpdate{update}, func(commit *object.Commit) error {
+		changes, err := commitChanges(ctx, commit)
+		if err != nil {
+			return err
+		}
+
+		for _, change := range changes {
+			for _, path := range []string{change.From.Name, change.To.Name} {
+				if locked[path] {
+					return fmt.Errorf("%w: %s", ErrFileLocked, path)
+				}
+			}
+
+			entry := change.To.TreeEntry
+			if change.To.Name == "" || !entry.Mode.IsFile() {
+				continue
+			}
+			size, err := st.EncodedObjectSize(entry.Hash)
+			if err != nil {
+				return fmt.Errorf("read blob %s: %w", entry.Hash, err)
+			}
+			if size > d.cfg.MaxFileSize {
+				return fmt.Errorf("%w: %s", ErrFileTooLarge, change.To.Name)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,196 @@
+package git
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+func newPreReceiveTestDomain(t *testing.T, repo *testRepo, quota *MockGitQuotaChecker) (*Domain, *model.GitRepo) {
+	repoDB := new(MockGitRepoDB)
+	storage := new(MockGitStorage)
+	cfg := DefaultConfig()
+	cfg.MaxFileSize = 16
+
+	var quotaChecker inbound.GitStorageQuotaChecker
+	if quota != nil {
+		quotaChecker = quota
+	}
+	domain := NewDomain(
+		repoDB,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		storage,
+		nil,
+		quotaChecker,
+		nil,
+		nil,
+		cfg,
+		zap.NewNop(),
+	)
+
+	gitRepo := &model.GitRepo{ID: uuid.New(), OwnerID: uuid.New(), StoragePath: "repos/test"}
+	repoDB.On("FindByID", mock.Anything, gitRepo.ID).Return(gitRepo, nil)
+	if repo != nil {
+		storage.On("GetFilesystem", mock.Anything, "repos/test").Return(repo.fs, nil)
+	}
+	return domain, gitRepo
+}
+
+func TestDomain_LimitPushPack(t *testing.T) {
+	ctx := context.Background()
+	pack := func() io.ReadCloser { return io.NopCloser(strings.NewReader(strings.Repeat("x", 100))) }
+
+	t.Run("over quota", func(t *testing.T) {
+		quota := new(MockGitQuotaChecker)
+		domain, repo := newPreReceiveTestDomain(t, nil, quota)
+		quota.On("GetStorageQuota", mock.Anything, repo.OwnerID).Return(int64(1000), nil)
+		quota.On("GetStorageUsed", mock.Anything, repo.OwnerID).Return(int64(950), nil)
+
+		r, err := domain.LimitPushPack(ctx, repo.ID, pack())
+		require.NoError(t, err)
+
+		_, err = io.ReadAll(r)
+		assert.ErrorIs(t, err, ErrStorageQuotaExceeded)
+	})
+
+	t.Run("within quota", func(t *testing.T) {
+		quota := new(MockGitQuotaChecker)
+		domain, repo := newPreReceiveTestDomain(t, nil, quota)
+		quota.On("GetStorageQuota", mock.Anything, repo.OwnerID).Return(int64(1000), nil)
+		quota.On("GetStorageUsed", mock.Anything, repo.OwnerID).Return(int64(900), nil)
+
+		r, err := domain.LimitPushPack(ctx, repo.ID, pack())
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Len(t, data, 100)
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		quota := new(MockGitQuotaChecker)
+		domain, repo := newPreReceiveTestDomain(t, nil, quota)
+		quota.On("GetStorageQuota", mock.Anything, repo.OwnerID).Return(int64(-1), nil)
+
+		r, err := domain.LimitPushPack(ctx, repo.ID, pack())
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(r)
+		require.NoError(t, err)
+		assert.Len(t, data, 100)
+	})
+}
+
+func TestDomain_CheckPushContent(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	base := repo.commit("main", map[string]string{"a.txt": "a", "design.psd": "v1"})
+	// Pushed commits aren't referenced yet, so they are all written on main,
+	// the ref every update targets, which is then reset.
+	large := repo.commit("main", map[string]string{"a.txt": "a", "design.psd": "v1", "dump.sql": strings.Repeat("x", 17)}, base)
+	small := repo.commit("main", map[string]string{"a.txt": "a", "design.psd": "v1", "dump.sql": "x"}, base)
+	edit := repo.commit("main", map[string]string{"a.txt": "a", "design.psd": "v2"}, base)
+	repo.setBranch("main", base)
+
+	domain, gitRepo := newPreReceiveTestDomain(t, repo, nil)
+	locks := []*model.GitLFSLock{{Path: "design.psd", OwnerID: uuid.New()}}
+
+	tests := []struct {
+		name string
+		new  plumbing.Hash
+		err  error
+	}{
+		{"large file", large, ErrFileTooLarge},
+		{"small file", small, nil},
+		{"locked file", edit, ErrFileLocked},
+		{"deletion", plumbing.ZeroHash, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := domain.CheckPushContent(ctx, gitRepo.ID, &inbound.GitRefUpdate{
+				Ref:    "refs/heads/main",
+				OldSHA: base.String(),
+				NewSHA: tt.new.String(),
+			}, locks)
+			if tt.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDomain_CheckPushContent_SkipsKnownCommits(t *testing.T) {
+	repo := newTestRepo(t)
+	large := repo.commit("main", map[string]string{"dump.sql": strings.Repeat("x", 17)})
+	topic := repo.commit("topic", map[string]string{"dump.sql": strings.Repeat("x", 17), "a.txt": "a"}, large)
+
+	domain, gitRepo := newPreReceiveTestDomain(t, repo, nil)
+
+	err := domain.CheckPushContent(context.Background(), gitRepo.ID, &inbound.GitRefUpdate{
+		Ref:    "refs/heads/topic",
+		OldSHA: plumbing.ZeroHash.String(),
+		NewSHA: topic.String(),
+	}, nil)
+
+	assert.NoError(t, err, "main was checked when it was pushed")
+}
+
+func TestDomain_CheckPushContent_SkipsAncestorsOfKnownCommits(t *testing.T) {
+	repo := newTestRepo(t)
+	large := repo.commit("main", map[string]string{"dump.sql": strings.Repeat("x", 17)})
+	// The pushed branch forks off main~1
+	topic := repo.commit("main", map[string]string{"dump.sql": strings.Repeat("x", 17), "b.txt": "b"}, large)
+	repo.setBranch("main", large)
+	repo.commit("main", map[string]string{"a.txt": "a"}, large)
+
+	domain, gitRepo := newPreReceiveTestDomain(t, repo, nil)
+
+	for _, head := range []plumbing.Hash{large, topic} {
+		err := domain.CheckPushContent(context.Background(), gitRepo.ID, &inbound.GitRefUpdate{
+			Ref:    "refs/heads/topic",
+			OldSHA: plumbing.ZeroHash.String(),
+			NewSHA: head.String(),
+		}, nil)
+
+		assert.NoError(t, err, "main~1 was checked when main was pushed")
+	}
+}
+
+func TestDomain_CheckPushContent_TooManyCommits(t *testing.T) {
+	repo := newTestRepo(t)
+	base := repo.commit("main", map[string]string{"a.txt": "0"})
+	head := base
+	for i := 1; i <= maxPushScanCommits+1; i++ {
+		head = repo.commit("main", map[string]string{"a.txt": strconv.Itoa(i)}, head)
+	}
+	repo.setBranch("main", base)
+
+	domain, gitRepo := newPreReceiveTestDomain(t, repo, nil)
+
+	err := domain.CheckPushContent(context.Background(), gitRepo.ID, &inbound.GitRefUpdate{
+		Ref:    "refs/heads/main",
+		OldSHA: base.String(),
+		NewSHA: head.String(),
+	}, nil)
+
+	assert.ErrorIs(t, err, ErrPushTooLarge)
+}
@@ -0,0 +1,498 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// maxRequiredApprovals bounds the approvals a repository can require.
+const maxRequiredApprovals = 10
+
+// ===== Pull Request Comments =====
+
+// CreatePRComment comments on a pull request. Comments with a path are
+// anchored to a line of that file at a commit of the source branch.
+func (d *Domain) CreatePRComment(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID, input *inbound.GitPRCommentInput) (*model.GitPRComment, error) {
+	if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionRead); err != nil {
+		return nil, err
+	}
+
+	pr, err := d.GetPR(ctx, repoID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := newPRComment(pr, userID, nil, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.anchorComments(ctx, pr, []*model.GitPRComment{comment}); err != nil {
+		return nil, err
+	}
+
+	if err := d.reviewDB.CreateComment(ctx, comment); err != nil {
+		return nil, fmt.Errorf("create comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// ListPRComments lists the comments of a pull request, oldest first.
+// Inline comments on a commit other than the current head are outdated.
+func (d *Domain) ListPRComments(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID) ([]*model.GitPRComment, error) {
+	if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionRead); err != nil {
+		return nil, err
+	}
+
+	pr, err := d.GetPR(ctx, repoID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := d.reviewDB.FindComments(ctx, pr.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list comments: %w", err)
+	}
+
+	if pr.Status.IsOpen() && hasInlineComments(comments) {
+		fs, err := d.GetFilesystem(ctx, repoID)
+		if err != nil {
+			return nil, err
+		}
+		head, err := d.prHead(ctx, pr, openObjectStorage(fs))
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			c.Outdated = c.IsInline() && head != nil && c.CommitSHA != head.Hash.String()
+		}
+	}
+
+	return comments, nil
+}
+
+// UpdatePRComment changes the body of a comment. Only its author can edit it.
+func (d *Domain) UpdatePRComment(ctx context.Context, repoID uuid.UUID, number int, commentID, userID uuid.UUID, body string) (*model.GitPRComment, error) {
+	comment, err := d.findPRComment(ctx, repoID, number, commentID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.AuthorID != userID {
+		return nil, ErrNotCommentAuthor
+	}
+
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil, ErrEmptyComment
+	}
+	comment.Body = body
+
+	if err := d.reviewDB.UpdateComment(ctx, comment); err != nil {
+		return nil, fmt.Errorf("update comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+// DeletePRComment deletes a comment. Repository admins can delete any
+// comment.
+func (d *Domain) DeletePRComment(ctx context.Context, repoID uuid.UUID, number int, commentID, userID uuid.UUID) error {
+	comment, err := d.findPRComment(ctx, repoID, number, commentID, userID)
+	if err != nil {
+		return err
+	}
+	if comment.AuthorID != userID {
+		if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionAdmin); err != nil {
+			return ErrNotCommentAuthor
+		}
+	}
+
+	if err := d.reviewDB.DeleteComment(ctx, commentID); err != nil {
+		return fmt.Errorf("delete comment: %w", err)
+	}
+
+	return nil
+}
+
+// findPRComment returns a comment of a pull request the user can read.
+func (d *Domain) findPRComment(ctx context.Context, repoID uuid.UUID, number int, commentID, userID uuid.UUID) (*model.GitPRComment, error) {
+	if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionRead); err != nil {
+		return nil, err
+	}
+
+	pr, err := d.GetPR(ctx, repoID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	comment, err := d.reviewDB.FindCommentByID(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment == nil || comment.PRID != pr.ID {
+		return nil, ErrCommentNotFound
+	}
+
+	return comment, nil
+}
+
+// ===== Pull Request Reviews =====
+
+// SubmitReview reviews the head of an open pull request. Approving or
+// requesting changes takes write access and is not open to the author.
+func (d *Domain) SubmitReview(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID, input *inbound.GitSubmitReviewInput) (*model.GitPRReview, error) {
+	if !input.State.IsValid() {
+		return nil, ErrInvalidReviewState
+	}
+
+	required := model.GitPermissionWrite
+	if input.State == model.GitPRReviewStateCommented {
+		required = model.GitPermissionRead
+	}
+	if err := d.CheckAccess(ctx, repoID, userID, required); err != nil {
+		return nil, err
+	}
+
+	pr, err := d.openPR(ctx, repoID, number)
+	if err != nil {
+		return nil, err
+	}
+	if pr.AuthorID == userID && input.State != model.GitPRReviewStateCommented {
+		return nil, ErrSelfReview
+	}
+
+	body := strings.TrimSpace(input.Body)
+	switch {
+	case input.State == model.GitPRReviewStateChangesRequested && body == "":
+		return nil, ErrEmptyComment
+	case input.State == model.GitPRReviewStateCommented && body == "" && len(input.Comments) == 0:
+		return nil, ErrEmptyComment
+	}
+
+	fs, err := d.GetFilesystem(ctx, repoID)
+	if err != nil {
+		return nil, err
+	}
+	head, err := d.prHead(ctx, pr, openObjectStorage(fs))
+	if err != nil {
+		return nil, err
+	}
+	if head == nil {
+		return nil, ErrBranchNotFound
+	}
+
+	review := &model.GitPRReview{
+		ID:         uuid.New(),
+		PRID:       pr.ID,
+		ReviewerID: userID,
+		State:      input.State,
+		Body:       body,
+		CommitSHA:  head.Hash.String(),
+	}
+
+	comments := make([]*model.GitPRComment, 0, len(input.Comments))
+	for _, in := range input.Comments {
+		comment, err := newPRComment(pr, userID, &review.ID, in)
+		if err != nil {
+			return nil, err
+		}
+		if !comment.IsInline() {
+			return nil, ErrInvalidPath
+		}
+		comments = append(comments, comment)
+	}
+	if err := d.anchorComments(ctx, pr, comments); err != nil {
+		return nil, err
+	}
+
+	if err := d.reviewDB.CreateReview(ctx, review, comments); err != nil {
+		return nil, fmt.Errorf("create review: %w", err)
+	}
+
+	// The request, if any, has been answered
+	if err := d.reviewDB.RemoveReviewRequest(ctx, pr.ID, userID); err != nil {
+		d.logger.Warn("failed to remove review request",
+			zap.String("pr_id", pr.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	return review, nil
+}
+
+// ListReviews lists the reviews of a pull request, oldest first.
+func (d *Domain) ListReviews(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID) ([]*model.GitPRReview, error) {
+	if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionRead); err != nil {
+		return nil, err
+	}
+
+	pr, err := d.GetPR(ctx, repoID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.reviewDB.FindReviews(ctx, pr.ID)
+}
+
+// ===== Review Requests =====
+
+// RequestReviewers asks users with write access to review an open pull
+// request. The author or anyone with write access can request reviews.
+func (d *Domain) RequestReviewers(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID, reviewerIDs []uuid.UUID) ([]*model.GitPRReviewRequest, error) {
+	pr, err := d.openPR(ctx, repoID, number)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.checkPRWriter(ctx, pr, userID); err != nil {
+		return nil, err
+	}
+
+	requests := make([]*model.GitPRReviewRequest, 0, len(reviewerIDs))
+	for _, reviewerID := range reviewerIDs {
+		if reviewerID == pr.AuthorID {
+			return nil, ErrInvalidReviewer
+		}
+		ok, err := d.CanAccess(ctx, repoID, &reviewerID, model.GitPermissionWrite)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, ErrInvalidReviewer
+		}
+		requests = append(requests, &model.GitPRReviewRequest{
+			PRID:        pr.ID,
+			ReviewerID:  reviewerID,
+			RequestedBy: userID,
+		})
+	}
+
+	for _, request := range requests {
+		if err := d.reviewDB.AddReviewRequest(ctx, request); err != nil {
+			return nil, fmt.Errorf("add review request: %w", err)
+		}
+	}
+
+	return requests, nil
+}
+
+// ListReviewRequests lists the pending review requests of a pull request.
+func (d *Domain) ListReviewRequests(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID) ([]*model.GitPRReviewRequest, error) {
+	if err := d.CheckAccess(ctx, repoID, userID, model.GitPermissionRead); err != nil {
+		return nil, err
+	}
+
+	pr, err := d.GetPR(ctx, repoID, number)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.reviewDB.FindReviewRequests(ctx, pr.ID)
+}
+
+// RemoveReviewRequest withdraws a review request. Reviewers can decline
+// their own requests.
+func (d *Domain) RemoveReviewRequest(ctx context.Context, repoID uuid.UUID, number int, userID, reviewerID uuid.UUID) error {
+	pr, err := d.GetPR(ctx, repoID, number)
+	if err != nil {
+		return err
+	}
+	if reviewerID != userID {
+		if err := d.checkPRWriter(ctx, pr, userID); err != nil {
+			return err
+		}
+	}
+
+	return d.reviewDB.RemoveReviewRequest(ctx, pr.ID, reviewerID)
+}
+
+// checkPRWriter ensures the user authored the pull request or can write to
+// its repository.
+func (d *Domain) checkPRWriter(ctx context.Context, pr *model.GitPullRequest, userID uuid.UUID) error {
+	if pr.AuthorID == userID {
+		return d.CheckAccess(ctx, pr.RepoID, userID, model.GitPermissionRead)
+	}
+	return d.CheckAccess(ctx, pr.RepoID, userID, model.GitPermissionWrite)
+}
+
+// ===== Approval Requirements =====
+
+// checkApprovals ensures a pull request has the required number of
+// approvals of headSHA and no outstanding change requests.
+func (d *Domain) checkApprovals(ctx context.Context, pr *model.GitPullRequest, headSHA string, required int) error {
+	if required == 0 {
+		return nil
+	}
+
+	reviews, err := d.reviewDB.FindReviews(ctx, pr.ID)
+	if err != nil {
+		return fmt.Errorf("list reviews: %w", err)
+	}
+
+	approvals, changesRequested := reviewVerdicts(reviews, headSHA)
+	if changesRequested {
+		return ErrChangesRequested
+	}
+	if approvals < required {
+		return ErrApprovalsRequired
+	}
+	return nil
+}
+
+// reviewVerdicts counts the reviewers whose latest verdict approves headSHA
+// and reports whether any still requests changes. Approvals of an earlier
+// head don't count, since the pushes after them went unreviewed; change
+// requests stand until the reviewer approves. Comment-only reviews leave a
+// verdict unchanged. Reviews must be ordered oldest first.
+func reviewVerdicts(reviews []*model.GitPRReview, headSHA string) (int, bool) {
+	latest := make(map[uuid.UUID]*model.GitPRReview)
+	for _, r := range reviews {
+		if r.State != model.GitPRReviewStateCommented {
+			latest[r.ReviewerID] = r
+		}
+	}
+
+	approvals, changesRequested := 0, false
+	for _, r := range latest {
+		switch r.State {
+		case model.GitPRReviewStateApproved:
+			if r.CommitSHA == headSHA {
+				approvals++
+			}
+		case model.GitPRReviewStateChangesRequested:
+			changesRequested = true
+		}
+	}
+	return approvals, changesRequested
+}
+
+// ===== Comment Anchoring =====
+
+// newPRComment builds a comment from input without checking its anchor.
+func newPRComment(pr *model.GitPullRequest, authorID uuid.UUID, reviewID *uuid.UUID, input *inbound.GitPRCommentInput) (*model.GitPRComment, error) {
+	body := strings.TrimSpace(input.Body)
+	if body == "" {
+		return nil, ErrEmptyComment
+	}
+
+	comment := &model.GitPRComment{
+		ID:       uuid.New(),
+		PRID:     pr.ID,
+		ReviewID: reviewID,
+		AuthorID: authorID,
+		Body:     body,
+	}
+	if input.Path == "" {
+		return comment, nil
+	}
+
+	p, ok := validFilePath(input.Path)
+	if !ok {
+		return nil, ErrInvalidPath
+	}
+	if input.Line < 1 {
+		return nil, ErrInvalidCommentLine
+	}
+	comment.Path = p
+	comment.Line = input.Line
+	comment.CommitSHA = strings.ToLower(input.CommitSHA)
+	return comment, nil
+}
+
+// anchorComments checks that every inline comment refers to a file at a
+// commit the repository has, defaulting the commit to the pull request's
+// head.
+func (d *Domain) anchorComments(ctx context.Context, pr *model.GitPullRequest, comments []*model.GitPRComment) error {
+	if !hasInlineComments(comments) {
+		return nil
+	}
+
+	fs, err := d.GetFilesystem(ctx, pr.RepoID)
+	if err != nil {
+		return err
+	}
+	st := openObjectStorage(fs)
+
+	// Resolving the head also brings in the commits of a fork
+	head, err := d.prHead(ctx, pr, st)
+	if err != nil {
+		return err
+	}
+
+	for _, c := range comments {
+		if !c.IsInline() {
+			continue
+		}
+		if c.CommitSHA == "" {
+			if head == nil {
+				return ErrBranchNotFound
+			}
+			c.CommitSHA = head.Hash.String()
+		}
+		if err := checkCommentAnchor(st, c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCommentAnchor ensures a comment's file exists at its commit.
+func checkCommentAnchor(st *filesystem.Storage, c *model.GitPRComment) error {
+	if !isHex(c.CommitSHA, 40) {
+		return ErrCommitNotFound
+	}
+	commit, err := peelCommit(st, plumbing.NewHash(c.CommitSHA))
+	if err != nil {
+		return err
+	}
+
+	tree, err := commit.Tree()
+	if err != nil {
+		return fmt.Errorf("read tree: %w", err)
+	}
+	entry, err := findEntry(tree, c.Path)
+	if err != nil {
+		return err
+	}
+	if entry.Mode == filemode.Dir {
+		return ErrNotAFile
+	}
+	return nil
+}
+
+// prHead returns the head of a pull request's source branch, or nil if the
+// branch no longer exists.
+func (d *Domain) prHead(ctx context.Context, pr *model.GitPullRequest, st *filesystem.Storage) (*object.Commit, error) {
+	ref, err := d.prSourceRef(ctx, pr, st)
+	if err == nil {
+		var head *object.Commit
+		head, err = refCommit(st, ref)
+		if err == nil {
+			return head, nil
+		}
+	}
+	if errors.Is(err, ErrBranchNotFound) {
+		return nil, nil
+	}
+	return nil, err
+}
+
+func hasInlineComments(comments []*model.GitPRComment) bool {
+	for _, c := range comments {
+		if c.IsInline() {
+			return true
+		}
+	}
+	return false
+}
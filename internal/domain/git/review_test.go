@@ -0,0 +1,481 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+type reviewTestDeps struct {
+	domain       *Domain
+	prDB         *MockGitPRDB
+	reviewDB     *MockGitPRReviewDB
+	protectionDB *MockGitBranchProtectionDB
+	repo         *model.GitRepo
+	pr           *model.GitPullRequest
+	reviewer     uuid.UUID // collaborator with write access
+	reader       uuid.UUID // collaborator with read access
+	head         string    // feature branch head when the domain was created
+}
+
+// newReviewTestDomain returns a domain serving repo as a private repository
+// with an open pull request from feature into main, opened by its owner.
+func newReviewTestDomain(t *testing.T, repo *testRepo) *reviewTestDeps {
+	repoDB := new(MockGitRepoDB)
+	collabDB := new(MockGitCollabDB)
+	storage := new(MockGitStorage)
+	deps := &reviewTestDeps{
+		prDB:         new(MockGitPRDB),
+		reviewDB:     new(MockGitPRReviewDB),
+		protectionDB: new(MockGitBranchProtectionDB),
+		reviewer:     uuid.New(),
+		reader:       uuid.New(),
+	}
+	deps.domain = NewDomain(
+		repoDB,
+		collabDB,
+		deps.prDB,
+		deps.reviewDB,
+		deps.protectionDB,
+		nil,
+		nil,
+		storage,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		zap.NewNop(),
+	)
+
+	deps.repo = &model.GitRepo{
+		ID:          uuid.New(),
+		OwnerID:     uuid.New(),
+		Visibility:  model.GitVisibilityPrivate,
+		StoragePath: "repos/test",
+	}
+	deps.pr = &model.GitPullRequest{
+		ID:           uuid.New(),
+		RepoID:       deps.repo.ID,
+		Number:       1,
+		Title:        "Add feature",
+		Status:       model.GitPRStatusOpen,
+		SourceBranch: "feature",
+		TargetBranch: "main",
+		AuthorID:     deps.repo.OwnerID,
+	}
+
+	repoDB.On("FindByID", mock.Anything, deps.repo.ID).Return(deps.repo, nil)
+	collabDB.On("FindByRepoAndUser", mock.Anything, deps.repo.ID, deps.reviewer).Return(&model.GitRepoCollaborator{
+		RepoID: deps.repo.ID, UserID: deps.reviewer, Permission: model.GitPermissionWrite,
+	}, nil)
+	collabDB.On("FindByRepoAndUser", mock.Anything, deps.repo.ID, deps.reader).Return(&model.GitRepoCollaborator{
+		RepoID: deps.repo.ID, UserID: deps.reader, Permission: model.GitPermissionRead,
+	}, nil)
+	collabDB.On("FindByRepoAndUser", mock.Anything, deps.repo.ID, mock.Anything).Return(nil, nil)
+	deps.prDB.On("FindByNumber", mock.Anything, deps.repo.ID, 1).Return(deps.pr, nil)
+	storage.On("GetFilesystem", mock.Anything, "repos/test").Return(repo.fs, nil)
+
+	if head, err := branchCommit(repo.st, "feature"); err == nil {
+		deps.head = head.Hash.String()
+	}
+	return deps
+}
+
+func TestDomain_CreatePRComment(t *testing.T) {
+	ctx := context.Background()
+
+	newRepo := func(t *testing.T) *testRepo {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a.txt": "a"})
+		repo.commit("feature", map[string]string{"a.txt": "a", "dir/b.txt": "b"}, base)
+		return repo
+	}
+
+	t.Run("inline comment defaults to head", func(t *testing.T) {
+		repo := newRepo(t)
+		deps := newReviewTestDomain(t, repo)
+		deps.reviewDB.On("CreateComment", mock.Anything, mock.Anything).Return(nil)
+
+		comment, err := deps.domain.CreatePRComment(ctx, deps.repo.ID, 1, deps.reader, &inbound.GitPRCommentInput{
+			Body: " Typo here ",
+			Path: "dir/b.txt",
+			Line: 1,
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Typo here", comment.Body)
+		assert.Equal(t, deps.pr.ID, comment.PRID)
+		assert.Equal(t, deps.reader, comment.AuthorID)
+		assert.Equal(t, repo.branch("feature").Hash.String(), comment.CommitSHA)
+		assert.True(t, comment.IsInline())
+	})
+
+	t.Run("general comment", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newRepo(t))
+		deps.reviewDB.On("CreateComment", mock.Anything, mock.Anything).Return(nil)
+
+		comment, err := deps.domain.CreatePRComment(ctx, deps.repo.ID, 1, deps.reader, &inbound.GitPRCommentInput{Body: "LGTM"})
+
+		require.NoError(t, err)
+		assert.False(t, comment.IsInline())
+		assert.Empty(t, comment.CommitSHA)
+	})
+
+	t.Run("invalid anchors", func(t *testing.T) {
+		repo := newRepo(t)
+		deps := newReviewTestDomain(t, repo)
+		base := repo.branch("main").Hash.String()
+
+		tests := []struct {
+			name  string
+			input *inbound.GitPRCommentInput
+			err   error
+		}{
+			{"empty body", &inbound.GitPRCommentInput{Body: "  "}, ErrEmptyComment},
+			{"missing line", &inbound.GitPRCommentInput{Body: "x", Path: "a.txt"}, ErrInvalidCommentLine},
+			{"path escapes", &inbound.GitPRCommentInput{Body: "x", Path: "../a.txt", Line: 1}, ErrInvalidPath},
+			{"unknown path", &inbound.GitPRCommentInput{Body: "x", Path: "c.txt", Line: 1}, ErrPathNotFound},
+			{"directory", &inbound.GitPRCommentInput{Body: "x", Path: "dir", Line: 1}, ErrNotAFile},
+			{"path missing at commit", &inbound.GitPRCommentInput{Body: "x", Path: "dir/b.txt", Line: 1, CommitSHA: base}, ErrPathNotFound},
+			{"unknown commit", &inbound.GitPRCommentInput{Body: "x", Path: "a.txt", Line: 1, CommitSHA: "0123456789012345678901234567890123456789"}, ErrCommitNotFound},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := deps.domain.CreatePRComment(ctx, deps.repo.ID, 1, deps.reader, tt.input)
+				assert.ErrorIs(t, err, tt.err)
+			})
+		}
+		deps.reviewDB.AssertNotCalled(t, "CreateComment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("no access", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newRepo(t))
+
+		_, err := deps.domain.CreatePRComment(ctx, deps.repo.ID, 1, uuid.New(), &inbound.GitPRCommentInput{Body: "x"})
+
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+}
+
+func TestDomain_ListPRComments_Outdated(t *testing.T) {
+	ctx := context.Background()
+	repo := newTestRepo(t)
+	base := repo.commit("main", map[string]string{"a.txt": "a"})
+	first := repo.commit("feature", map[string]string{"a.txt": "b"}, base)
+	head := repo.commit("feature", map[string]string{"a.txt": "c"}, first)
+
+	deps := newReviewTestDomain(t, repo)
+	deps.reviewDB.On("FindComments", mock.Anything, deps.pr.ID).Return([]*model.GitPRComment{
+		{Body: "old", Path: "a.txt", Line: 1, CommitSHA: first.String()},
+		{Body: "current", Path: "a.txt", Line: 1, CommitSHA: head.String()},
+		{Body: "general"},
+	}, nil)
+
+	comments, err := deps.domain.ListPRComments(ctx, deps.repo.ID, 1, deps.reader)
+
+	require.NoError(t, err)
+	require.Len(t, comments, 3)
+	assert.True(t, comments[0].Outdated)
+	assert.False(t, comments[1].Outdated)
+	assert.False(t, comments[2].Outdated)
+}
+
+func TestDomain_UpdatePRComment(t *testing.T) {
+	ctx := context.Background()
+	deps := newReviewTestDomain(t, newTestRepo(t))
+	comment := &model.GitPRComment{ID: uuid.New(), PRID: deps.pr.ID, AuthorID: deps.reader, Body: "old"}
+	deps.reviewDB.On("FindCommentByID", mock.Anything, comment.ID).Return(comment, nil)
+
+	t.Run("not the author", func(t *testing.T) {
+		_, err := deps.domain.UpdatePRComment(ctx, deps.repo.ID, 1, comment.ID, deps.reviewer, "new")
+		assert.ErrorIs(t, err, ErrNotCommentAuthor)
+	})
+
+	t.Run("comment of another pull request", func(t *testing.T) {
+		other := &model.GitPRComment{ID: uuid.New(), PRID: uuid.New(), AuthorID: deps.reader}
+		deps.reviewDB.On("FindCommentByID", mock.Anything, other.ID).Return(other, nil)
+
+		_, err := deps.domain.UpdatePRComment(ctx, deps.repo.ID, 1, other.ID, deps.reader, "new")
+		assert.ErrorIs(t, err, ErrCommentNotFound)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		deps.reviewDB.On("UpdateComment", mock.Anything, comment).Return(nil)
+
+		updated, err := deps.domain.UpdatePRComment(ctx, deps.repo.ID, 1, comment.ID, deps.reader, "new")
+
+		require.NoError(t, err)
+		assert.Equal(t, "new", updated.Body)
+	})
+}
+
+func TestDomain_SubmitReview(t *testing.T) {
+	ctx := context.Background()
+
+	newRepo := func(t *testing.T) *testRepo {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a.txt": "a"})
+		repo.commit("feature", map[string]string{"a.txt": "b"}, base)
+		return repo
+	}
+
+	t.Run("approve with inline comment", func(t *testing.T) {
+		repo := newRepo(t)
+		deps := newReviewTestDomain(t, repo)
+		head := repo.branch("feature").Hash.String()
+		deps.reviewDB.On("CreateReview", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		deps.reviewDB.On("RemoveReviewRequest", mock.Anything, deps.pr.ID, deps.reviewer).Return(nil)
+
+		review, err := deps.domain.SubmitReview(ctx, deps.repo.ID, 1, deps.reviewer, &inbound.GitSubmitReviewInput{
+			State:    model.GitPRReviewStateApproved,
+			Comments: []*inbound.GitPRCommentInput{{Body: "nit", Path: "a.txt", Line: 1}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, model.GitPRReviewStateApproved, review.State)
+		assert.Equal(t, head, review.CommitSHA)
+
+		comments := deps.reviewDB.Calls[0].Arguments.Get(2).([]*model.GitPRComment)
+		require.Len(t, comments, 1)
+		assert.Equal(t, &review.ID, comments[0].ReviewID)
+		assert.Equal(t, head, comments[0].CommitSHA)
+		deps.reviewDB.AssertExpectations(t)
+	})
+
+	t.Run("author cannot approve", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newRepo(t))
+
+		_, err := deps.domain.SubmitReview(ctx, deps.repo.ID, 1, deps.pr.AuthorID, &inbound.GitSubmitReviewInput{
+			State: model.GitPRReviewStateApproved,
+		})
+
+		assert.ErrorIs(t, err, ErrSelfReview)
+	})
+
+	t.Run("reader cannot approve", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newRepo(t))
+
+		_, err := deps.domain.SubmitReview(ctx, deps.repo.ID, 1, deps.reader, &inbound.GitSubmitReviewInput{
+			State: model.GitPRReviewStateApproved,
+		})
+
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+
+	t.Run("changes requested needs a body", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newRepo(t))
+
+		_, err := deps.domain.SubmitReview(ctx, deps.repo.ID, 1, deps.reviewer, &inbound.GitSubmitReviewInput{
+			State: model.GitPRReviewStateChangesRequested,
+		})
+
+		assert.ErrorIs(t, err, ErrEmptyComment)
+	})
+
+	t.Run("review comments must be inline", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newRepo(t))
+
+		_, err := deps.domain.SubmitReview(ctx, deps.repo.ID, 1, deps.reader, &inbound.GitSubmitReviewInput{
+			State:    model.GitPRReviewStateCommented,
+			Comments: []*inbound.GitPRCommentInput{{Body: "general"}},
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidPath)
+	})
+
+	t.Run("invalid state", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newRepo(t))
+
+		_, err := deps.domain.SubmitReview(ctx, deps.repo.ID, 1, deps.reviewer, &inbound.GitSubmitReviewInput{
+			State: "dismissed",
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidReviewState)
+	})
+}
+
+func TestDomain_RequestReviewers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("success", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newTestRepo(t))
+		deps.reviewDB.On("AddReviewRequest", mock.Anything, mock.Anything).Return(nil)
+
+		requests, err := deps.domain.RequestReviewers(ctx, deps.repo.ID, 1, deps.pr.AuthorID, []uuid.UUID{deps.reviewer})
+
+		require.NoError(t, err)
+		require.Len(t, requests, 1)
+		assert.Equal(t, deps.reviewer, requests[0].ReviewerID)
+		assert.Equal(t, deps.pr.AuthorID, requests[0].RequestedBy)
+	})
+
+	t.Run("invalid reviewers", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newTestRepo(t))
+
+		for _, reviewer := range []uuid.UUID{deps.pr.AuthorID, deps.reader, uuid.New()} {
+			_, err := deps.domain.RequestReviewers(ctx, deps.repo.ID, 1, deps.pr.AuthorID, []uuid.UUID{deps.reviewer, reviewer})
+			assert.ErrorIs(t, err, ErrInvalidReviewer)
+		}
+		deps.reviewDB.AssertNotCalled(t, "AddReviewRequest", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reader cannot request", func(t *testing.T) {
+		deps := newReviewTestDomain(t, newTestRepo(t))
+
+		_, err := deps.domain.RequestReviewers(ctx, deps.repo.ID, 1, deps.reader, []uuid.UUID{deps.reviewer})
+
+		assert.ErrorIs(t, err, ErrAccessDenied)
+	})
+}
+
+func TestReviewVerdicts(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	const head, earlier = "head", "earlier"
+	review := func(reviewer uuid.UUID, state model.GitPRReviewState) *model.GitPRReview {
+		return &model.GitPRReview{ReviewerID: reviewer, State: state, CommitSHA: head}
+	}
+	reviewOf := func(sha string, reviewer uuid.UUID, state model.GitPRReviewState) *model.GitPRReview {
+		return &model.GitPRReview{ReviewerID: reviewer, State: state, CommitSHA: sha}
+	}
+
+	tests := []struct {
+		name             string
+		reviews          []*model.GitPRReview
+		approvals        int
+		changesRequested bool
+	}{
+		{"none", nil, 0, false},
+		{"two approvals", []*model.GitPRReview{
+			review(alice, model.GitPRReviewStateApproved),
+			review(bob, model.GitPRReviewStateApproved),
+		}, 2, false},
+		{"approval counted once", []*model.GitPRReview{
+			review(alice, model.GitPRReviewStateApproved),
+			review(alice, model.GitPRReviewStateApproved),
+		}, 1, false},
+		{"changes then approval", []*model.GitPRReview{
+			review(alice, model.GitPRReviewStateChangesRequested),
+			review(alice, model.GitPRReviewStateApproved),
+		}, 1, false},
+		{"approval then changes", []*model.GitPRReview{
+			review(alice, model.GitPRReviewStateApproved),
+			review(alice, model.GitPRReviewStateChangesRequested),
+			review(bob, model.GitPRReviewStateApproved),
+		}, 1, true},
+		{"comment keeps verdict", []*model.GitPRReview{
+			review(alice, model.GitPRReviewStateApproved),
+			review(alice, model.GitPRReviewStateCommented),
+		}, 1, false},
+		{"approval of an earlier head", []*model.GitPRReview{
+			reviewOf(earlier, alice, model.GitPRReviewStateApproved),
+			review(bob, model.GitPRReviewStateApproved),
+		}, 1, false},
+		{"re-approved after a push", []*model.GitPRReview{
+			reviewOf(earlier, alice, model.GitPRReviewStateApproved),
+			review(alice, model.GitPRReviewStateApproved),
+		}, 1, false},
+		{"changes requested on an earlier head", []*model.GitPRReview{
+			reviewOf(earlier, alice, model.GitPRReviewStateChangesRequested),
+		}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			approvals, changesRequested := reviewVerdicts(tt.reviews, head)
+			assert.Equal(t, tt.approvals, approvals)
+			assert.Equal(t, tt.changesRequested, changesRequested)
+		})
+	}
+}
+
+func TestDomain_MergePR_RequiredApprovals(t *testing.T) {
+	ctx := context.Background()
+
+	// reviews gets the head of the pull request
+	newDeps := func(t *testing.T, reviews func(head string) []*model.GitPRReview) *reviewTestDeps {
+		repo := newTestRepo(t)
+		base := repo.commit("main", map[string]string{"a.txt": "a"})
+		head := repo.commit("feature", map[string]string{"a.txt": "b"}, base)
+
+		deps := newReviewTestDomain(t, repo)
+		deps.repo.RequiredApprovals = 1
+		deps.reviewDB.On("FindReviews", mock.Anything, deps.pr.ID).Return(reviews(head.String()), nil)
+		deps.protectionDB.On("FindByRepo", mock.Anything, deps.repo.ID).Return(nil, nil)
+		return deps
+	}
+
+	t.Run("not enough approvals", func(t *testing.T) {
+		deps := newDeps(t, func(head string) []*model.GitPRReview {
+			return []*model.GitPRReview{
+				{ReviewerID: uuid.New(), State: model.GitPRReviewStateCommented, CommitSHA: head},
+			}
+		})
+
+		_, err := deps.domain.MergePR(ctx, deps.repo.ID, 1, deps.repo.OwnerID, nil)
+
+		assert.ErrorIs(t, err, ErrApprovalsRequired)
+	})
+
+	t.Run("changes requested", func(t *testing.T) {
+		deps := newDeps(t, func(head string) []*model.GitPRReview {
+			return []*model.GitPRReview{
+				{ReviewerID: uuid.New(), State: model.GitPRReviewStateApproved, CommitSHA: head},
+				{ReviewerID: uuid.New(), State: model.GitPRReviewStateChangesRequested, CommitSHA: head},
+			}
+		})
+
+		_, err := deps.domain.MergePR(ctx, deps.repo.ID, 1, deps.repo.OwnerID, nil)
+
+		assert.ErrorIs(t, err, ErrChangesRequested)
+	})
+
+	t.Run("approved", func(t *testing.T) {
+		deps := newDeps(t, func(head string) []*model.GitPRReview {
+			return []*model.GitPRReview{
+				{ReviewerID: uuid.New(), State: model.GitPRReviewStateApproved, CommitSHA: head},
+			}
+		})
+		deps.prDB.On("Update", mock.Anything, deps.pr).Return(nil)
+
+		pr, err := deps.domain.MergePR(ctx, deps.repo.ID, 1, deps.repo.OwnerID, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.GitPRStatusMerged, pr.Status)
+	})
+
+	t.Run("approval of an earlier head", func(t *testing.T) {
+		deps := newDeps(t, func(string) []*model.GitPRReview {
+			return []*model.GitPRReview{
+				{ReviewerID: uuid.New(), State: model.GitPRReviewStateApproved, CommitSHA: strings.Repeat("1", 40)},
+			}
+		})
+
+		_, err := deps.domain.MergePR(ctx, deps.repo.ID, 1, deps.repo.OwnerID, nil)
+		assert.ErrorIs(t, err, ErrApprovalsRequired)
+
+		result, err := deps.domain.CheckMergeability(ctx, deps.repo.ID, 1, deps.reader)
+		require.NoError(t, err)
+		assert.False(t, result.Mergeable)
+		assert.Equal(t, 0, result.Approvals)
+	})
+
+	t.Run("mergeability reports approvals", func(t *testing.T) {
+		deps := newDeps(t, func(string) []*model.GitPRReview { return nil })
+
+		result, err := deps.domain.CheckMergeability(ctx, deps.repo.ID, 1, deps.reader)
+
+		require.NoError(t, err)
+		assert.False(t, result.Mergeable)
+		assert.Equal(t, 0, result.Approvals)
+		assert.Equal(t, 1, result.RequiredApprovals)
+	})
+}
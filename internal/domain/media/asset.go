@@ -0,0 +1,306 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF for DecodeConfig
+	_ "image/jpeg" // register JPEG for DecodeConfig
+	_ "image/png"  // register PNG for DecodeConfig
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+// assetInput describes generated media to be stored.
+type assetInput struct {
+	ownerID    uuid.UUID
+	taskID     *uuid.UUID
+	kind       model.MediaCapability
+	data       []byte
+	mediaModel *model.MediaModel
+	prompt     string
+	// Provider-reported metadata, used when it cannot be read from data.
+	width    int
+	height   int
+	duration int
+}
+
+// assetsEnabled reports whether generated media is copied to our storage.
+// Without storage configured, vendor URLs are returned as-is.
+func (d *Domain) assetsEnabled() bool {
+	return d.storage != nil && d.assetDB != nil
+}
+
+// persistImages copies generated images into storage and replaces their
+// vendor URLs with presigned ones. Base64 payloads are dropped unless the
+// caller asked for them.
+func (d *Domain) persistImages(ctx context.Context, task *model.MediaTask, mediaModel *model.MediaModel, prompt string, images []*model.GeneratedImage, keepB64 bool) error {
+	taskID := task.ID
+	for _, img := range images {
+		data, err := d.loadGenerated(ctx, img.URL, img.B64JSON)
+		if err != nil {
+			return fmt.Errorf("load generated image: %w", err)
+		}
+
+		asset, err := d.storeAsset(ctx, &assetInput{
+			ownerID:    task.OwnerID,
+			taskID:     &taskID,
+			kind:       model.MediaCapabilityImage,
+			data:       data,
+			mediaModel: mediaModel,
+			prompt:     prompt,
+		})
+		if err != nil {
+			return err
+		}
+
+		url, err := d.assetURL(ctx, asset)
+		if err != nil {
+			return err
+		}
+		img.URL = url
+		img.AssetID = asset.ID.String()
+		if !keepB64 {
+			img.B64JSON = ""
+		}
+	}
+	return nil
+}
+
+// persistVideo copies a completed video into storage. The stored output
+// keeps only the asset ID; URLs are presigned whenever the task is read.
+func (d *Domain) persistVideo(ctx context.Context, task *model.MediaTask, mediaModel *model.MediaModel, prompt string, video *model.GeneratedVideo) error {
+	data, err := d.loadGenerated(ctx, video.URL, "")
+	if err != nil {
+		return fmt.Errorf("load generated video: %w", err)
+	}
+
+	taskID := task.ID
+	asset, err := d.storeAsset(ctx, &assetInput{
+		ownerID:    task.OwnerID,
+		taskID:     &taskID,
+		kind:       model.MediaCapabilityVideo,
+		data:       data,
+		mediaModel: mediaModel,
+		prompt:     prompt,
+		width:      video.Width,
+		height:     video.Height,
+		duration:   video.Duration,
+	})
+	if err != nil {
+		return err
+	}
+
+	video.AssetID = asset.ID.String()
+	video.URL = ""
+	video.FileSize = asset.SizeBytes
+	if video.Format == "" {
+		_, video.Format, _ = strings.Cut(asset.MimeType, "/")
+	}
+	return nil
+}
+
+// resolveVideoURL points a stored video at a fresh presigned URL.
+func (d *Domain) resolveVideoURL(ctx context.Context, video *model.GeneratedVideo) {
+	if video.AssetID == "" || !d.assetsEnabled() {
+		return
+	}
+
+	id, err := uuid.Parse(video.AssetID)
+	if err != nil {
+		return
+	}
+	asset, err := d.assetDB.FindByID(ctx, id)
+	if err != nil || asset == nil {
+		d.logger.Warn("Failed to load video asset",
+			zap.String("asset_id", video.AssetID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	url, err := d.assetURL(ctx, asset)
+	if err != nil {
+		d.logger.Warn("Failed to presign video asset",
+			zap.String("asset_id", video.AssetID),
+			zap.Error(err),
+		)
+		return
+	}
+	video.URL = url
+}
+
+// loadGenerated returns the bytes of generated media from its base64 payload
+// or by downloading its URL.
+func (d *Domain) loadGenerated(ctx context.Context, rawURL, b64 string) ([]byte, error) {
+	switch {
+	case b64 != "":
+		return d.decodeBase64(b64)
+	case strings.HasPrefix(rawURL, "data:"):
+		header, payload, ok := strings.Cut(strings.TrimPrefix(rawURL, "data:"), ",")
+		if !ok || !strings.HasSuffix(header, ";base64") {
+			return nil, fmt.Errorf("data URL must be base64 encoded")
+		}
+		return d.decodeBase64(payload)
+	case rawURL == "":
+		return nil, fmt.Errorf("provider returned no content")
+	case d.fetcher == nil:
+		return nil, fmt.Errorf("no media fetcher configured")
+	}
+
+	data, _, err := d.fetcher.Fetch(ctx, rawURL, d.config.MaxAssetBytes)
+	return data, err
+}
+
+// decodeBase64 decodes a base64 payload, enforcing MaxAssetBytes.
+func (d *Domain) decodeBase64(payload string) ([]byte, error) {
+	if int64(base64.StdEncoding.DecodedLen(len(payload))) > d.config.MaxAssetBytes {
+		return nil, fmt.Errorf("content exceeds %d bytes", d.config.MaxAssetBytes)
+	}
+	return base64.StdEncoding.DecodeString(payload)
+}
+
+// storeAsset uploads data under the owner's prefix and records it. Content
+// the owner already has is not stored or counted again.
+func (d *Domain) storeAsset(ctx context.Context, in *assetInput) (*model.MediaAsset, error) {
+	sum := sha256.Sum256(in.data)
+	hash := hex.EncodeToString(sum[:])
+
+	existing, err := d.assetDB.FindByOwnerAndHash(ctx, in.ownerID, hash)
+	if err != nil {
+		return nil, fmt.Errorf("find asset: %w", err)
+	}
+	if existing != nil {
+		// Generating the same content again brings it back from the trash.
+		if existing.IsDeleted() {
+			existing.DeletedAt = nil
+			existing.UpdatedAt = time.Now()
+			if err := d.assetDB.Update(ctx, existing); err != nil {
+				return nil, fmt.Errorf("restore asset: %w", err)
+			}
+		}
+		return existing, nil
+	}
+
+	size := int64(len(in.data))
+	if err := d.checkStorageQuota(ctx, in.ownerID, size); err != nil {
+		return nil, err
+	}
+
+	// Trust the bytes rather than the vendor's Content-Type.
+	mimeType := http.DetectContentType(in.data)
+	if (in.kind == model.MediaCapabilityImage && !strings.HasPrefix(mimeType, "image/")) ||
+		strings.HasPrefix(mimeType, "text/") {
+		return nil, fmt.Errorf("unexpected %s content type %q", in.kind, mimeType)
+	}
+
+	width, height := in.width, in.height
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(in.data)); err == nil {
+		width, height = cfg.Width, cfg.Height
+	}
+
+	key := d.config.AssetPrefix + in.ownerID.String() + "/" + hash + assetExtension(mimeType)
+	if err := d.storage.Put(ctx, key, bytes.NewReader(in.data), size); err != nil {
+		return nil, fmt.Errorf("store asset: %w", err)
+	}
+
+	now := time.Now()
+	asset := &model.MediaAsset{
+		ID:              uuid.New(),
+		OwnerID:         in.ownerID,
+		TaskID:          in.taskID,
+		Type:            in.kind,
+		Name:            defaultAssetName(in.prompt),
+		Tags:            []string{},
+		StorageKey:      key,
+		ContentHash:     hash,
+		MimeType:        mimeType,
+		SizeBytes:       size,
+		Width:           width,
+		Height:          height,
+		DurationSeconds: in.duration,
+		ProviderID:      in.mediaModel.ProviderID,
+		ModelID:         in.mediaModel.ID,
+		Prompt:          in.prompt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := d.assetDB.Create(ctx, asset); err != nil {
+		// A concurrent request may have stored the same content first.
+		if existing, findErr := d.assetDB.FindByOwnerAndHash(ctx, in.ownerID, hash); findErr == nil && existing != nil {
+			return existing, nil
+		}
+		return nil, fmt.Errorf("create asset: %w", err)
+	}
+
+	d.logger.Info("Media asset stored",
+		zap.String("asset_id", asset.ID.String()),
+		zap.String("owner_id", in.ownerID.String()),
+		zap.String("mime_type", mimeType),
+		zap.Int64("size", size),
+	)
+	return asset, nil
+}
+
+// checkStorageQuota verifies the owner can store size more bytes.
+func (d *Domain) checkStorageQuota(ctx context.Context, ownerID uuid.UUID, size int64) error {
+	if d.storageQuota == nil {
+		return nil
+	}
+
+	limit, err := d.storageQuota.GetMediaStorageLimit(ctx, ownerID)
+	if err != nil {
+		return fmt.Errorf("check storage quota: %w", err)
+	}
+	if limit < 0 {
+		return nil
+	}
+
+	used, err := d.assetDB.SumSizeByOwner(ctx, ownerID)
+	if err != nil {
+		return fmt.Errorf("check storage usage: %w", err)
+	}
+	if used+size > limit {
+		return ErrStorageQuotaExceeded
+	}
+	return nil
+}
+
+// assetURL returns a presigned download URL for an asset.
+func (d *Domain) assetURL(ctx context.Context, asset *model.MediaAsset) (string, error) {
+	url, err := d.storage.GetPresignedURL(ctx, asset.StorageKey, d.config.AssetURLExpiry)
+	if err != nil {
+		return "", fmt.Errorf("presign asset: %w", err)
+	}
+	return url, nil
+}
+
+// assetExtension returns the file extension for a sniffed MIME type.
+func assetExtension(mimeType string) string {
+	switch mimeType {
+	case "image/png":
+		return ".png"
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	case "video/mp4":
+		return ".mp4"
+	case "video/webm":
+		return ".webm"
+	default:
+		return ""
+	}
+}
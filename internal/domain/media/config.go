@@ -12,6 +12,35 @@ type Config struct {
 
 	// TaskTimeout is the timeout for a single task.
 	TaskTimeout time.Duration
+
+	// WorkerPollInterval is how often the worker looks for pending tasks.
+	WorkerPollInterval time.Duration
+
+	// TaskLeaseDuration is how long a running task stays with its worker
+	// without a heartbeat. After that the worker is presumed gone and any
+	// worker may take the task over.
+	TaskLeaseDuration time.Duration
+
+	// TaskHeartbeatInterval is how often a worker marks its running tasks
+	// alive. It must be well below TaskLeaseDuration.
+	TaskHeartbeatInterval time.Duration
+
+	// AssetPrefix is the storage key prefix for generated media.
+	AssetPrefix string
+
+	// AssetURLExpiry is how long presigned asset URLs stay valid.
+	AssetURLExpiry time.Duration
+
+	// MaxAssetBytes is the largest generated file that will be stored.
+	MaxAssetBytes int64
+
+	// MaxInputImageBytes is the largest image accepted for edits,
+	// variations and upscaling.
+	MaxInputImageBytes int64
+
+	// ProviderFailuresToUnhealthy is how many provider faults within the
+	// health cache's failure window take a provider out of routing.
+	ProviderFailuresToUnhealthy int
 }
 
 // DefaultConfig returns default media configuration.
@@ -20,5 +49,14 @@ func DefaultConfig() *Config {
 		VideoPollInterval:  5 * time.Second,
 		MaxConcurrentTasks: 10,
 		TaskTimeout:        30 * time.Minute,
+		WorkerPollInterval: 2 * time.Second,
+		AssetPrefix:        "media/",
+		AssetURLExpiry:     time.Hour,
+		MaxAssetBytes:      256 << 20,
+		MaxInputImageBytes: 20 << 20,
+
+		ProviderFailuresToUnhealthy: 3,
+		TaskLeaseDuration:           2 * time.Minute,
+		TaskHeartbeatInterval:       30 * time.Second,
 	}
 }
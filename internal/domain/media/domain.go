@@ -3,7 +3,11 @@ package media
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,8 +26,20 @@ type Domain struct {
 	healthCache    outbound.MediaProviderHealthCachePort
 	vendorRegistry outbound.MediaVendorRegistryPort
 	crypto         outbound.MediaCryptoPort
+	videoQuota     outbound.MediaVideoQuotaPort
+	assetDB        outbound.MediaAssetDatabasePort
+	storage        outbound.StoragePort
+	fetcher        outbound.MediaFetcherPort
+	storageQuota   outbound.MediaStorageQuotaPort
+	teamAccess     outbound.MediaTeamAccessPort
+	router         *StrategyChain
 	config         *Config
 	logger         *zap.Logger
+
+	// Worker lifecycle
+	workerMu     sync.Mutex
+	workerCancel context.CancelFunc
+	workerWG     sync.WaitGroup
 }
 
 // NewDomain creates a new media domain.
@@ -34,6 +50,12 @@ func NewDomain(
 	healthCache outbound.MediaProviderHealthCachePort,
 	vendorRegistry outbound.MediaVendorRegistryPort,
 	crypto outbound.MediaCryptoPort,
+	videoQuota outbound.MediaVideoQuotaPort,
+	assetDB outbound.MediaAssetDatabasePort,
+	storage outbound.StoragePort,
+	fetcher outbound.MediaFetcherPort,
+	storageQuota outbound.MediaStorageQuotaPort,
+	teamAccess outbound.MediaTeamAccessPort,
 	config *Config,
 	logger *zap.Logger,
 ) *Domain {
@@ -47,6 +69,13 @@ func NewDomain(
 		healthCache:    healthCache,
 		vendorRegistry: vendorRegistry,
 		crypto:         crypto,
+		videoQuota:     videoQuota,
+		assetDB:        assetDB,
+		storage:        storage,
+		fetcher:        fetcher,
+		storageQuota:   storageQuota,
+		teamAccess:     teamAccess,
+		router:         DefaultStrategyChain(),
 		config:         config,
 		logger:         logger,
 	}
@@ -58,48 +87,29 @@ func (d *Domain) GenerateImage(ctx context.Context, userID uuid.UUID, input *inb
 		return nil, ErrInvalidInput
 	}
 
-	// Find model with image capability
-	mediaModel, provider, apiKey, err := d.findModelWithCapability(ctx, input.Model, model.MediaCapabilityImage)
-	if err != nil {
-		return nil, err
-	}
-
-	// Get adapter
-	adapter, err := d.vendorRegistry.GetForProvider(provider)
-	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrNoAdapterFound, err)
-	}
-
-	// Build request
-	req := &model.ImageRequest{
-		Prompt:         input.Prompt,
-		NegativePrompt: input.NegativePrompt,
-		N:              input.N,
-		Size:           input.Size,
-		Quality:        input.Quality,
-		Style:          input.Style,
-		ResponseFormat: input.ResponseFormat,
-		Model:          mediaModel.ID,
-	}
-
-	// Execute
-	resp, err := adapter.GenerateImage(ctx, req, mediaModel, provider, apiKey)
-	if err != nil {
-		return nil, fmt.Errorf("generate image: %w", err)
-	}
-
-	d.logger.Info("Image generated",
-		zap.String("user_id", userID.String()),
-		zap.String("model", mediaModel.ID),
-		zap.Int("count", len(resp.Images)),
-	)
-
-	return &inbound.MediaImageGenerationOutput{
-		Images:    resp.Images,
-		Model:     resp.Model,
-		Usage:     resp.Usage,
-		CreatedAt: resp.CreatedAt,
-	}, nil
+	return d.runImageOperation(ctx, userID, &imageOperation{
+		name:            "generate image",
+		capability:      model.MediaCapabilityImage,
+		taskType:        TaskTypeImage,
+		modelID:         input.Model,
+		preferredModels: input.PreferredModels,
+		count:           imageCount(input.N),
+		prompt:          input.Prompt,
+		responseFormat:  input.ResponseFormat,
+		input:           input,
+		run: func(adapter outbound.MediaVendorAdapterPort, mediaModel *model.MediaModel, provider *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+			return adapter.GenerateImage(ctx, &model.ImageRequest{
+				Prompt:         input.Prompt,
+				NegativePrompt: input.NegativePrompt,
+				N:              input.N,
+				Size:           input.Size,
+				Quality:        input.Quality,
+				Style:          input.Style,
+				ResponseFormat: input.ResponseFormat,
+				Model:          mediaModel.ID,
+			}, mediaModel, provider, apiKey)
+		},
+	})
 }
 
 // GenerateVideo generates videos asynchronously.
@@ -108,6 +118,14 @@ func (d *Domain) GenerateVideo(ctx context.Context, userID uuid.UUID, input *inb
 	if input.Prompt == "" && input.InputImage == "" && input.InputVideo == "" {
 		return nil, ErrInvalidInput
 	}
+	if input.Duration < 0 {
+		return nil, ErrInvalidInput
+	}
+
+	// Reject early; the worker checks again before submitting.
+	if err := d.checkVideoQuota(ctx, userID, input.Duration); err != nil {
+		return nil, err
+	}
 
 	// Serialize input
 	inputBytes, err := json.Marshal(input)
@@ -121,7 +139,7 @@ func (d *Domain) GenerateVideo(ctx context.Context, userID uuid.UUID, input *inb
 	task := &model.MediaTask{
 		ID:        uuid.New(),
 		OwnerID:   userID,
-		Type:      "video_generation",
+		Type:      TaskTypeVideo.String(),
 		Status:    model.MediaTaskStatusPending,
 		Progress:  0,
 		Input:     &inputStr,
@@ -177,6 +195,7 @@ func (d *Domain) GetVideoStatus(ctx context.Context, userID uuid.UUID, taskID st
 	if task.Status == model.MediaTaskStatusCompleted && task.Output != nil && *task.Output != "" {
 		var video model.GeneratedVideo
 		if err := json.Unmarshal([]byte(*task.Output), &video); err == nil {
+			d.resolveVideoURL(ctx, &video)
 			resp.Video = &video
 		}
 	}
@@ -272,10 +291,16 @@ func (d *Domain) CancelTask(ctx context.Context, userID uuid.UUID, taskID uuid.U
 		return ErrTaskAlreadyCompleted
 	}
 
-	// Update status
-	if err := d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusCancelled, task.Progress, "", "cancelled by user"); err != nil {
+	// Update status unless the task finished meanwhile
+	cancelled, err := d.taskDB.TransitionStatus(ctx, taskID,
+		[]model.MediaTaskStatus{model.MediaTaskStatusPending, model.MediaTaskStatusRunning},
+		model.MediaTaskStatusCancelled, task.Progress, "", "cancelled by user")
+	if err != nil {
 		return fmt.Errorf("update task status: %w", err)
 	}
+	if !cancelled {
+		return ErrTaskAlreadyCompleted
+	}
 
 	d.logger.Info("Task cancelled",
 		zap.String("task_id", taskID.String()),
@@ -319,93 +344,224 @@ func (d *Domain) ListModelsByCapability(ctx context.Context, cap model.MediaCapa
 	return d.modelDB.FindByCapability(ctx, cap)
 }
 
-// findModelWithCapability finds a model with the given capability.
-func (d *Domain) findModelWithCapability(ctx context.Context, modelID string, capability model.MediaCapability) (*model.MediaModel, *model.MediaProvider, string, error) {
+// routeModels returns the models that can serve a request, best first. An
+// explicitly requested model is used as is; "auto" ranks every model with the
+// capability through the routing strategy chain.
+func (d *Domain) routeModels(ctx context.Context, modelID string, routing *model.MediaRoutingContext) ([]*model.MediaScoredCandidate, error) {
 	// If model specified, use it directly
 	if modelID != "" && modelID != "auto" {
-		mediaModel, err := d.modelDB.FindByID(ctx, modelID)
+		candidate, err := d.requestedCandidate(ctx, modelID, routing.Capability)
 		if err != nil {
-			return nil, nil, "", err
-		}
-		if mediaModel == nil {
-			return nil, nil, "", ErrModelNotFound
+			return nil, err
 		}
 
-		// Check capability
-		if !mediaModel.HasCapability(capability) {
-			return nil, nil, "", ErrCapabilityNotSupported
+		// Check health
+		if healthy, ok := d.providerHealth(ctx, candidate.Provider.ID); ok && !healthy {
+			return nil, ErrProviderUnhealthy
 		}
+		return []*model.MediaScoredCandidate{candidate}, nil
+	}
 
-		// Get provider
-		provider, err := d.providerDB.FindByID(ctx, mediaModel.ProviderID)
-		if err != nil {
-			return nil, nil, "", err
+	// Auto-select model
+	models, err := d.modelDB.FindByCapability(ctx, routing.Capability)
+	if err != nil {
+		return nil, err
+	}
+	if len(models) == 0 {
+		return nil, ErrModelNotFound
+	}
+
+	routing.ProviderHealth = make(map[string]bool)
+	providers := make(map[uuid.UUID]*model.MediaProvider)
+	var candidates []*model.MediaScoredCandidate
+	for _, mediaModel := range models {
+		provider, seen := providers[mediaModel.ProviderID]
+		if !seen {
+			provider, err = d.providerDB.FindByID(ctx, mediaModel.ProviderID)
+			if err != nil {
+				d.logger.Warn("Failed to load media provider",
+					zap.String("provider_id", mediaModel.ProviderID.String()),
+					zap.Error(err),
+				)
+			}
+			providers[mediaModel.ProviderID] = provider
+			if provider != nil {
+				if healthy, ok := d.providerHealth(ctx, provider.ID); ok {
+					routing.ProviderHealth[provider.ID.String()] = healthy
+				}
+			}
 		}
 		if provider == nil {
-			return nil, nil, "", ErrProviderNotFound
+			continue
 		}
+		candidates = append(candidates, model.NewMediaScoredCandidate(provider, mediaModel))
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoHealthyProvider
+	}
 
-		// Check health
-		healthy, err := d.healthCache.GetHealth(ctx, provider.ID)
-		if err != nil {
-			d.logger.Warn("Failed to get health status", zap.Error(err))
-		} else if !healthy {
-			return nil, nil, "", ErrProviderUnhealthy
-		}
+	return d.router.Rank(routing, candidates)
+}
 
-		// Decrypt API key
-		apiKey, err := d.crypto.Decrypt(provider.EncryptedKey)
-		if err != nil {
-			return nil, nil, "", fmt.Errorf("decrypt api key: %w", err)
-		}
+// requestedCandidate loads an explicitly requested model and its provider.
+func (d *Domain) requestedCandidate(ctx context.Context, modelID string, capability model.MediaCapability) (*model.MediaScoredCandidate, error) {
+	mediaModel, err := d.modelDB.FindByID(ctx, modelID)
+	if err != nil {
+		return nil, err
+	}
+	if mediaModel == nil {
+		return nil, ErrModelNotFound
+	}
 
-		return mediaModel, provider, apiKey, nil
+	// Check capability
+	if !mediaModel.HasCapability(capability) {
+		return nil, ErrCapabilityNotSupported
 	}
 
-	// Auto-select model
-	models, err := d.modelDB.FindByCapability(ctx, capability)
+	// Get provider
+	provider, err := d.providerDB.FindByID(ctx, mediaModel.ProviderID)
 	if err != nil {
-		return nil, nil, "", err
+		return nil, err
 	}
-	if len(models) == 0 {
-		return nil, nil, "", ErrModelNotFound
+	if provider == nil {
+		return nil, ErrProviderNotFound
 	}
 
-	// Filter by health
-	for _, mediaModel := range models {
-		if !mediaModel.Enabled {
-			continue
-		}
+	candidate := model.NewMediaScoredCandidate(provider, mediaModel)
+	candidate.Reasons = append(candidate.Reasons, "requested model")
+	return candidate, nil
+}
 
-		provider, err := d.providerDB.FindByID(ctx, mediaModel.ProviderID)
-		if err != nil || provider == nil || !provider.Enabled {
-			continue
-		}
+// providerHealth returns the cached health of a provider. ok is false when
+// the health is unknown.
+func (d *Domain) providerHealth(ctx context.Context, providerID uuid.UUID) (healthy, ok bool) {
+	if d.healthCache == nil {
+		return false, false
+	}
+	healthy, err := d.healthCache.GetHealth(ctx, providerID)
+	if err != nil {
+		d.logger.Warn("Failed to get health status", zap.Error(err))
+		return false, false
+	}
+	return healthy, true
+}
 
-		healthy, err := d.healthCache.GetHealth(ctx, provider.ID)
+// mediaAttempt runs a request against one routing candidate.
+type mediaAttempt func(candidate *model.MediaScoredCandidate, adapter outbound.MediaVendorAdapterPort, apiKey string) error
+
+// tryCandidates runs attempt against each candidate in turn until one
+// succeeds. Provider faults count towards taking a provider out of routing;
+// a success puts it back. It returns the candidate that succeeded and the
+// models that failed before it.
+func (d *Domain) tryCandidates(ctx context.Context, candidates []*model.MediaScoredCandidate, attempt mediaAttempt) (*model.MediaScoredCandidate, []string, error) {
+	var failed []string
+	var lastErr error
+	for _, candidate := range candidates {
+		adapter, apiKey, err := d.candidateAdapter(candidate)
 		if err != nil {
-			d.logger.Warn("Failed to get health status", zap.Error(err))
+			lastErr = err
+			failed = append(failed, candidate.Model.ID)
 			continue
 		}
-		if !healthy {
+
+		if err := attempt(candidate, adapter, apiKey); err != nil {
+			if ctx.Err() != nil {
+				return nil, nil, ctx.Err()
+			}
+			d.recordProviderFailure(ctx, candidate.Provider, err)
+			lastErr = err
+			failed = append(failed, candidate.Model.ID)
 			continue
 		}
+		d.markProviderHealthy(ctx, candidate.Provider)
 
-		// Decrypt API key
-		apiKey, err := d.crypto.Decrypt(provider.EncryptedKey)
-		if err != nil {
-			d.logger.Warn("Failed to decrypt API key", zap.Error(err))
-			continue
+		if len(failed) > 0 {
+			d.logger.Info("Media request fell back to another model",
+				zap.String("model", candidate.Model.ID),
+				zap.Strings("failed", failed),
+			)
 		}
+		return candidate, failed, nil
+	}
 
-		return mediaModel, provider, apiKey, nil
+	if lastErr == nil {
+		lastErr = ErrNoHealthyProvider
 	}
+	return nil, failed, lastErr
+}
 
-	return nil, nil, "", ErrNoHealthyProvider
+// candidateAdapter returns the vendor adapter and API key for a candidate.
+func (d *Domain) candidateAdapter(candidate *model.MediaScoredCandidate) (outbound.MediaVendorAdapterPort, string, error) {
+	provider := candidate.Provider
+
+	apiKey, err := d.crypto.Decrypt(provider.EncryptedKey)
+	if err != nil {
+		d.logger.Warn("Failed to decrypt API key",
+			zap.String("provider_id", provider.ID.String()),
+			zap.Error(err),
+		)
+		return nil, "", fmt.Errorf("decrypt api key: %w", err)
+	}
+
+	adapter, err := d.vendorRegistry.GetForProvider(provider)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrNoAdapterFound, err)
+	}
+	return adapter, apiKey, nil
 }
 
-// ExecuteVideoTask executes a video generation task.
-// This is called by a task worker.
+// recordProviderFailure counts a failed provider request in the health
+// cache and marks the provider unhealthy once it failed too often. Only
+// provider faults count; a request the provider rejected says nothing about
+// its health.
+func (d *Domain) recordProviderFailure(ctx context.Context, provider *model.MediaProvider, cause error) {
+	d.logger.Warn("Media provider request failed",
+		zap.String("provider_id", provider.ID.String()),
+		zap.Error(cause),
+	)
+	if d.healthCache == nil || !isProviderFault(cause) {
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	failures, err := d.healthCache.RecordFailure(ctx, provider.ID)
+	if err != nil {
+		d.logger.Warn("Failed to record provider failure", zap.Error(err))
+		return
+	}
+	if failures < int64(d.config.ProviderFailuresToUnhealthy) {
+		return
+	}
+	if err := d.healthCache.SetHealth(ctx, provider.ID, false); err != nil {
+		d.logger.Warn("Failed to set health status", zap.Error(err))
+	}
+}
+
+// markProviderHealthy records a successful provider request.
+func (d *Domain) markProviderHealthy(ctx context.Context, provider *model.MediaProvider) {
+	if d.healthCache == nil {
+		return
+	}
+	if err := d.healthCache.SetHealth(context.WithoutCancel(ctx), provider.ID, true); err != nil {
+		d.logger.Warn("Failed to set health status", zap.Error(err))
+	}
+}
+
+// isProviderFault reports whether a failed request points at the provider:
+// it could not be reached or answered with a server error.
+func isProviderFault(err error) bool {
+	var statusErr *outbound.MediaVendorStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// ExecuteVideoTask runs a claimed video generation task to completion.
+// A task that was already submitted to a provider resumes polling instead of
+// being submitted again. Context errors are returned without touching the
+// task so the caller can decide whether to fail or requeue it.
 func (d *Domain) ExecuteVideoTask(ctx context.Context, taskID uuid.UUID) error {
 	task, err := d.taskDB.FindByID(ctx, taskID)
 	if err != nil {
@@ -414,97 +570,246 @@ func (d *Domain) ExecuteVideoTask(ctx context.Context, taskID uuid.UUID) error {
 	if task == nil {
 		return ErrTaskNotFound
 	}
+	if TaskStatus(task.Status).IsTerminal() {
+		return nil
+	}
 
 	// Update to running
-	if err := d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusRunning, 10, "", ""); err != nil {
+	running, err := d.updateRunning(ctx, taskID, model.MediaTaskStatusRunning, max(task.Progress, 10), "", "")
+	if err != nil {
 		return fmt.Errorf("update task status: %w", err)
 	}
+	if !running {
+		return nil
+	}
 
 	// Parse input
 	var input inbound.MediaVideoGenerationInput
 	if task.Input == nil {
-		d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusFailed, 0, "", "missing input")
+		d.failTask(ctx, taskID, "missing input")
 		return fmt.Errorf("task input is nil")
 	}
 	if err := json.Unmarshal([]byte(*task.Input), &input); err != nil {
-		d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusFailed, 0, "", "invalid input")
+		d.failTask(ctx, taskID, "invalid input")
 		return fmt.Errorf("unmarshal input: %w", err)
 	}
 
-	// Find model
-	mediaModel, provider, apiKey, err := d.findModelWithCapability(ctx, input.Model, model.MediaCapabilityVideo)
-	if err != nil {
-		d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusFailed, 0, "", err.Error())
-		return err
-	}
-
-	d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusRunning, 20, "", "")
+	var (
+		chosen  *model.MediaScoredCandidate
+		adapter outbound.MediaVendorAdapterPort
+		apiKey  string
+	)
+	providerTaskID := task.ExternalTaskID
+	if providerTaskID != "" {
+		// The job already runs at the provider: resume polling it there
+		// whatever the provider's health
+		chosen, err = d.requestedCandidate(ctx, task.ModelID, model.MediaCapabilityVideo)
+		if err == nil {
+			adapter, apiKey, err = d.candidateAdapter(chosen)
+		}
+		if err != nil {
+			d.failTask(ctx, taskID, err.Error())
+			return err
+		}
+	} else {
+		candidates, err := d.routeModels(ctx, input.Model, &model.MediaRoutingContext{
+			Capability:      model.MediaCapabilityVideo,
+			Units:           float64(input.Duration),
+			PreferredModels: input.PreferredModels,
+		})
+		if err != nil {
+			d.failTask(ctx, taskID, err.Error())
+			return err
+		}
 
-	// Get adapter
-	adapter, err := d.vendorRegistry.GetForProvider(provider)
-	if err != nil {
-		d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusFailed, 0, "", err.Error())
-		return fmt.Errorf("%w: %v", ErrNoAdapterFound, err)
-	}
+		if err := d.checkVideoQuota(ctx, task.OwnerID, input.Duration); err != nil {
+			d.failTask(ctx, taskID, err.Error())
+			return err
+		}
 
-	// Build request
-	req := &model.VideoRequest{
-		Prompt:      input.Prompt,
-		InputImage:  input.InputImage,
-		InputVideo:  input.InputVideo,
-		Duration:    input.Duration,
-		AspectRatio: input.AspectRatio,
-		Resolution:  input.Resolution,
-		FPS:         input.FPS,
-		Model:       mediaModel.ID,
+		d.updateRunning(ctx, taskID, model.MediaTaskStatusRunning, 20, "", "")
+
+		chosen, _, err = d.tryCandidates(ctx, candidates, func(candidate *model.MediaScoredCandidate, a outbound.MediaVendorAdapterPort, key string) error {
+			// Submit to provider
+			resp, err := a.GenerateVideo(ctx, &model.VideoRequest{
+				Prompt:      input.Prompt,
+				InputImage:  input.InputImage,
+				InputVideo:  input.InputVideo,
+				Duration:    input.Duration,
+				AspectRatio: input.AspectRatio,
+				Resolution:  input.Resolution,
+				FPS:         input.FPS,
+				Model:       candidate.Model.ID,
+			}, candidate.Model, candidate.Provider, key)
+			if err != nil {
+				return fmt.Errorf("generate video: %w", err)
+			}
+			adapter, apiKey = a, key
+			providerTaskID = resp.TaskID
+			return nil
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			d.failTask(ctx, taskID, err.Error())
+			return err
+		}
 	}
+	mediaModel, provider := chosen.Model, chosen.Provider
 
-	// Submit to provider
-	resp, err := adapter.GenerateVideo(ctx, req, mediaModel, provider, apiKey)
-	if err != nil {
-		d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusFailed, 0, "", err.Error())
-		return fmt.Errorf("generate video: %w", err)
+	if task.ExternalTaskID == "" {
+		// Remember the provider job before polling so it is never submitted twice
+		task.ModelID = mediaModel.ID
+		task.ExternalTaskID = providerTaskID
+		recorded, err := d.taskDB.RecordExternalTask(context.WithoutCancel(ctx), taskID, mediaModel.ID, providerTaskID, 30)
+		switch {
+		case err != nil:
+			d.logger.Warn("Failed to record provider task",
+				zap.String("task_id", taskID.String()),
+				zap.Error(err),
+			)
+		case !recorded:
+			d.logTaskStopped(taskID)
+			return nil
+		}
 	}
 
-	d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusRunning, 30, "", "")
-
 	// Poll for completion
-	providerTaskID := resp.TaskID
 	for {
-		select {
-		case <-ctx.Done():
-			d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusFailed, 0, "", "context cancelled")
-			return ctx.Err()
-		default:
-		}
-
 		status, err := adapter.GetVideoStatus(ctx, providerTaskID, provider, apiKey)
 		if err != nil {
-			d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusFailed, 0, "", err.Error())
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			d.failTask(ctx, taskID, err.Error())
 			return fmt.Errorf("get video status: %w", err)
 		}
 
-		// Update progress (map 0-100 to 30-90)
-		if status.Progress > 0 {
-			mappedProgress := 30 + (status.Progress * 60 / 100)
-			d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusRunning, mappedProgress, "", "")
-		}
-
 		switch status.Status {
 		case model.VideoStateCompleted:
+			if d.assetsEnabled() && status.Video != nil {
+				if err := d.persistVideo(ctx, task, mediaModel, input.Prompt, status.Video); err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					d.failTask(ctx, taskID, err.Error())
+					return fmt.Errorf("store video: %w", err)
+				}
+			}
 			outputBytes, _ := json.Marshal(status.Video)
-			d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusCompleted, 100, string(outputBytes), "")
+			completed, err := d.updateRunning(context.WithoutCancel(ctx), taskID, model.MediaTaskStatusCompleted, 100, string(outputBytes), "")
+			if err != nil {
+				return fmt.Errorf("update task status: %w", err)
+			}
+			if !completed {
+				// Cancelled before completion: not delivered, not billed
+				d.logTaskStopped(taskID)
+				return nil
+			}
+			d.recordVideoUsage(ctx, task, mediaModel, status.Video, input.Duration)
 			d.logger.Info("Video generation completed",
 				zap.String("task_id", taskID.String()),
 			)
 			return nil
 		case model.VideoStateFailed:
-			d.taskDB.UpdateStatus(ctx, taskID, model.MediaTaskStatusFailed, 0, "", status.Error)
+			d.failTask(ctx, taskID, status.Error)
 			return fmt.Errorf("video generation failed: %s", status.Error)
 		}
 
+		// Update progress (map 0-100 to 30-90)
+		if status.Progress > 0 {
+			mappedProgress := 30 + (status.Progress * 60 / 100)
+			running, err := d.updateRunning(ctx, taskID, model.MediaTaskStatusRunning, mappedProgress, "", "")
+			if err == nil && !running {
+				d.logTaskStopped(taskID)
+				return nil
+			}
+		}
+
 		// Wait before polling again
-		time.Sleep(d.config.VideoPollInterval)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(d.config.VideoPollInterval):
+		}
+
+		// Stop polling if the user cancelled the task meanwhile
+		current, err := d.taskDB.FindByID(ctx, taskID)
+		if err == nil && current != nil && current.Status != model.MediaTaskStatusRunning {
+			d.logTaskStopped(taskID)
+			return nil
+		}
+	}
+}
+
+// updateRunning updates a running video task. It returns false if the task
+// is no longer running, e.g. because the user cancelled it, and then leaves
+// it untouched.
+func (d *Domain) updateRunning(ctx context.Context, taskID uuid.UUID, status model.MediaTaskStatus, progress int, output, errMsg string) (bool, error) {
+	return d.taskDB.TransitionStatus(ctx, taskID, []model.MediaTaskStatus{model.MediaTaskStatusRunning}, status, progress, output, errMsg)
+}
+
+func (d *Domain) logTaskStopped(taskID uuid.UUID) {
+	d.logger.Info("Video task no longer running, stopped",
+		zap.String("task_id", taskID.String()),
+	)
+}
+
+// failTask marks a running task as failed, even if ctx is already done.
+func (d *Domain) failTask(ctx context.Context, taskID uuid.UUID, errMsg string) {
+	if _, err := d.updateRunning(context.WithoutCancel(ctx), taskID, model.MediaTaskStatusFailed, 0, "", errMsg); err != nil {
+		d.logger.Error("Failed to mark task failed",
+			zap.String("task_id", taskID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// checkVideoQuota verifies the user has enough video minutes left for a
+// video of the requested length (0 if unspecified).
+func (d *Domain) checkVideoQuota(ctx context.Context, userID uuid.UUID, seconds int) error {
+	if d.videoQuota == nil {
+		return nil
+	}
+
+	remaining, err := d.videoQuota.GetVideoSecondsRemaining(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("check video quota: %w", err)
+	}
+	if remaining < 0 {
+		return nil
+	}
+	if remaining == 0 || int64(seconds) > remaining {
+		return ErrVideoQuotaExceeded
+	}
+	return nil
+}
+
+// recordVideoUsage bills the generated length to the task owner, falling back
+// to the requested duration when the provider does not report one.
+func (d *Domain) recordVideoUsage(ctx context.Context, task *model.MediaTask, mediaModel *model.MediaModel, video *model.GeneratedVideo, requested int) {
+	if d.videoQuota == nil {
+		return
+	}
+
+	seconds := requested
+	if video != nil && video.Duration > 0 {
+		seconds = video.Duration
+	}
+	if seconds <= 0 {
+		d.logger.Warn("Video duration unknown, usage not recorded",
+			zap.String("task_id", task.ID.String()),
+		)
+		return
+	}
+
+	if err := d.videoQuota.RecordVideoUsage(context.WithoutCancel(ctx), task.OwnerID, task.ID, mediaModel.ProviderID, mediaModel.ID, seconds); err != nil {
+		d.logger.Error("Failed to record video usage",
+			zap.String("task_id", task.ID.String()),
+			zap.Int("seconds", seconds),
+			zap.Error(err),
+		)
 	}
 }
 
@@ -1,13 +1,25 @@
 package media
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/uniedit/server/internal/model"
 	"github.com/uniedit/server/internal/port/inbound"
 	"github.com/uniedit/server/internal/port/outbound"
@@ -140,14 +152,24 @@ func (m *MockMediaTaskDB) FindByOwner(ctx context.Context, ownerID uuid.UUID, li
 	return args.Get(0).([]*model.MediaTask), args.Error(1)
 }
 
-func (m *MockMediaTaskDB) FindPending(ctx context.Context, limit int) ([]*model.MediaTask, error) {
-	args := m.Called(ctx, limit)
+func (m *MockMediaTaskDB) FindPending(ctx context.Context, limit int, lease time.Duration) ([]*model.MediaTask, error) {
+	args := m.Called(ctx, limit, lease)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*model.MediaTask), args.Error(1)
 }
 
+func (m *MockMediaTaskDB) Claim(ctx context.Context, id uuid.UUID, lease time.Duration) (bool, error) {
+	args := m.Called(ctx, id, lease)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMediaTaskDB) Heartbeat(ctx context.Context, id uuid.UUID) (bool, error) {
+	args := m.Called(ctx, id)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockMediaTaskDB) Update(ctx context.Context, task *model.MediaTask) error {
 	args := m.Called(ctx, task)
 	return args.Error(0)
@@ -158,6 +180,16 @@ func (m *MockMediaTaskDB) UpdateStatus(ctx context.Context, id uuid.UUID, status
 	return args.Error(0)
 }
 
+func (m *MockMediaTaskDB) TransitionStatus(ctx context.Context, id uuid.UUID, from []model.MediaTaskStatus, status model.MediaTaskStatus, progress int, output, errMsg string) (bool, error) {
+	args := m.Called(ctx, id, from, status, progress, output, errMsg)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMediaTaskDB) RecordExternalTask(ctx context.Context, id uuid.UUID, modelID, externalTaskID string, progress int) (bool, error) {
+	args := m.Called(ctx, id, modelID, externalTaskID, progress)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockMediaTaskDB) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
@@ -179,6 +211,11 @@ func (m *MockMediaHealthCache) SetHealth(ctx context.Context, providerID uuid.UU
 	return args.Error(0)
 }
 
+func (m *MockMediaHealthCache) RecordFailure(ctx context.Context, providerID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, providerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 var _ outbound.MediaProviderHealthCachePort = (*MockMediaHealthCache)(nil)
 
 type MockMediaVendorRegistry struct {
@@ -260,6 +297,30 @@ func (m *MockMediaVendorAdapter) GenerateImage(ctx context.Context, req *model.I
 	return args.Get(0).(*model.ImageResponse), args.Error(1)
 }
 
+func (m *MockMediaVendorAdapter) EditImage(ctx context.Context, req *model.ImageEditRequest, mdl *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+	args := m.Called(ctx, req, mdl, prov, apiKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ImageResponse), args.Error(1)
+}
+
+func (m *MockMediaVendorAdapter) CreateImageVariation(ctx context.Context, req *model.ImageVariationRequest, mdl *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+	args := m.Called(ctx, req, mdl, prov, apiKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ImageResponse), args.Error(1)
+}
+
+func (m *MockMediaVendorAdapter) UpscaleImage(ctx context.Context, req *model.ImageUpscaleRequest, mdl *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+	args := m.Called(ctx, req, mdl, prov, apiKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ImageResponse), args.Error(1)
+}
+
 func (m *MockMediaVendorAdapter) GenerateVideo(ctx context.Context, req *model.VideoRequest, mdl *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.VideoResponse, error) {
 	args := m.Called(ctx, req, mdl, prov, apiKey)
 	if args.Get(0) == nil {
@@ -278,6 +339,125 @@ func (m *MockMediaVendorAdapter) GetVideoStatus(ctx context.Context, taskID stri
 
 var _ outbound.MediaVendorAdapterPort = (*MockMediaVendorAdapter)(nil)
 
+type MockMediaVideoQuota struct {
+	mock.Mock
+}
+
+func (m *MockMediaVideoQuota) GetVideoSecondsRemaining(ctx context.Context, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMediaVideoQuota) RecordVideoUsage(ctx context.Context, userID, taskID, providerID uuid.UUID, modelID string, seconds int) error {
+	args := m.Called(ctx, userID, taskID, providerID, modelID, seconds)
+	return args.Error(0)
+}
+
+// MockMediaAssetDB mocks MediaAssetDatabasePort.
+type MockMediaAssetDB struct {
+	mock.Mock
+}
+
+func (m *MockMediaAssetDB) Create(ctx context.Context, asset *model.MediaAsset) error {
+	args := m.Called(ctx, asset)
+	return args.Error(0)
+}
+
+func (m *MockMediaAssetDB) FindByID(ctx context.Context, id uuid.UUID) (*model.MediaAsset, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.MediaAsset), args.Error(1)
+}
+
+func (m *MockMediaAssetDB) FindByOwnerAndHash(ctx context.Context, ownerID uuid.UUID, contentHash string) (*model.MediaAsset, error) {
+	args := m.Called(ctx, ownerID, contentHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.MediaAsset), args.Error(1)
+}
+
+func (m *MockMediaAssetDB) Find(ctx context.Context, filter *model.MediaAssetFilter) ([]*model.MediaAsset, int64, error) {
+	args := m.Called(ctx, filter)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*model.MediaAsset), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockMediaAssetDB) Update(ctx context.Context, asset *model.MediaAsset) error {
+	args := m.Called(ctx, asset)
+	return args.Error(0)
+}
+
+func (m *MockMediaAssetDB) SumSizeByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockStorage mocks StoragePort.
+type MockStorage struct {
+	mock.Mock
+}
+
+func (m *MockStorage) Put(ctx context.Context, key string, reader io.Reader, size int64) error {
+	args := m.Called(ctx, key, reader, size)
+	return args.Error(0)
+}
+
+func (m *MockStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+func (m *MockStorage) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockStorage) GetPresignedURL(ctx context.Context, key string, duration time.Duration) (string, error) {
+	args := m.Called(ctx, key, duration)
+	return args.String(0), args.Error(1)
+}
+
+// MockMediaFetcher mocks MediaFetcherPort.
+type MockMediaFetcher struct {
+	mock.Mock
+}
+
+func (m *MockMediaFetcher) Fetch(ctx context.Context, rawURL string, maxBytes int64) ([]byte, string, error) {
+	args := m.Called(ctx, rawURL, maxBytes)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]byte), args.String(1), args.Error(2)
+}
+
+// MockMediaStorageQuota mocks MediaStorageQuotaPort.
+type MockMediaStorageQuota struct {
+	mock.Mock
+}
+
+func (m *MockMediaStorageQuota) GetMediaStorageLimit(ctx context.Context, userID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockMediaTeamAccess mocks MediaTeamAccessPort.
+type MockMediaTeamAccess struct {
+	mock.Mock
+}
+
+func (m *MockMediaTeamAccess) GetTeamRole(ctx context.Context, teamID, userID uuid.UUID) (model.TeamRole, error) {
+	args := m.Called(ctx, teamID, userID)
+	return args.Get(0).(model.TeamRole), args.Error(1)
+}
+
 // --- Tests ---
 
 func TestDomain_GenerateImage(t *testing.T) {
@@ -300,6 +480,12 @@ func TestDomain_GenerateImage(t *testing.T) {
 			mockVendorReg,
 			mockCrypto,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -334,6 +520,7 @@ func TestDomain_GenerateImage(t *testing.T) {
 		mockModelDB.On("FindByID", mock.Anything, "dall-e-3").Return(mediaModel, nil)
 		mockProviderDB.On("FindByID", mock.Anything, providerID).Return(provider, nil)
 		mockHealthCache.On("GetHealth", mock.Anything, providerID).Return(true, nil)
+		mockHealthCache.On("SetHealth", mock.Anything, providerID, true).Return(nil)
 		mockCrypto.On("Decrypt", "encrypted-key").Return("sk-test-key", nil)
 		mockVendorReg.On("GetForProvider", provider).Return(mockAdapter, nil)
 		mockAdapter.On("GenerateImage", mock.Anything, mock.AnythingOfType("*model.ImageRequest"), mediaModel, provider, "sk-test-key").Return(expectedResp, nil)
@@ -355,7 +542,7 @@ func TestDomain_GenerateImage(t *testing.T) {
 	})
 
 	t.Run("empty prompt", func(t *testing.T) {
-		domain := NewDomain(nil, nil, nil, nil, nil, nil, nil, logger)
+		domain := NewDomain(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 		userID := uuid.New()
 		input := &inbound.MediaImageGenerationInput{
@@ -379,6 +566,12 @@ func TestDomain_GenerateImage(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -409,6 +602,12 @@ func TestDomain_GenerateImage(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -456,6 +655,12 @@ func TestDomain_GenerateVideo(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -477,7 +682,7 @@ func TestDomain_GenerateVideo(t *testing.T) {
 	})
 
 	t.Run("invalid input", func(t *testing.T) {
-		domain := NewDomain(nil, nil, nil, nil, nil, nil, nil, logger)
+		domain := NewDomain(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 		userID := uuid.New()
 		input := &inbound.MediaVideoGenerationInput{
@@ -505,6 +710,12 @@ func TestDomain_GetVideoStatus(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -541,6 +752,12 @@ func TestDomain_GetVideoStatus(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -566,6 +783,12 @@ func TestDomain_GetVideoStatus(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -601,6 +824,12 @@ func TestDomain_GetTask(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -643,6 +872,12 @@ func TestDomain_ListTasks(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -673,6 +908,12 @@ func TestDomain_ListTasks(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -701,6 +942,12 @@ func TestDomain_CancelTask(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -715,7 +962,7 @@ func TestDomain_CancelTask(t *testing.T) {
 		}
 
 		mockTaskDB.On("FindByID", mock.Anything, taskID).Return(task, nil)
-		mockTaskDB.On("UpdateStatus", mock.Anything, taskID, model.MediaTaskStatusCancelled, 0, "", "cancelled by user").Return(nil)
+		mockTaskDB.On("TransitionStatus", mock.Anything, taskID, mock.Anything, model.MediaTaskStatusCancelled, 0, "", "cancelled by user").Return(true, nil)
 
 		err := domain.CancelTask(context.Background(), userID, taskID)
 
@@ -723,6 +970,23 @@ func TestDomain_CancelTask(t *testing.T) {
 		mockTaskDB.AssertExpectations(t)
 	})
 
+	t.Run("task finished while cancelling", func(t *testing.T) {
+		mockTaskDB := new(MockMediaTaskDB)
+		domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
+
+		userID := uuid.New()
+		task := &model.MediaTask{ID: uuid.New(), OwnerID: userID, Status: model.MediaTaskStatusRunning, Progress: 80}
+
+		mockTaskDB.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+		mockTaskDB.On("TransitionStatus", mock.Anything, task.ID,
+			[]model.MediaTaskStatus{model.MediaTaskStatusPending, model.MediaTaskStatusRunning},
+			model.MediaTaskStatusCancelled, 80, "", "cancelled by user").Return(false, nil)
+
+		err := domain.CancelTask(context.Background(), userID, task.ID)
+
+		assert.ErrorIs(t, err, ErrTaskAlreadyCompleted)
+	})
+
 	t.Run("task already completed", func(t *testing.T) {
 		mockTaskDB := new(MockMediaTaskDB)
 
@@ -734,6 +998,12 @@ func TestDomain_CancelTask(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -764,6 +1034,12 @@ func TestDomain_CancelTask(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -799,6 +1075,12 @@ func TestDomain_GetProvider(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -830,6 +1112,12 @@ func TestDomain_GetProvider(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -857,6 +1145,12 @@ func TestDomain_ListProviders(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -888,6 +1182,12 @@ func TestDomain_GetModel(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -917,6 +1217,12 @@ func TestDomain_GetModel(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -943,6 +1249,12 @@ func TestDomain_ListModelsByCapability(t *testing.T) {
 			nil,
 			nil,
 			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
+			nil,
 			logger,
 		)
 
@@ -985,7 +1297,7 @@ func TestDomain_GetVideoStatus_Completed(t *testing.T) {
 	logger := zap.NewNop()
 	mockTaskDB := new(MockMediaTaskDB)
 
-	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, logger)
+	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	userID := uuid.New()
 	taskID := uuid.New()
@@ -1015,7 +1327,7 @@ func TestDomain_GetVideoStatus_Failed(t *testing.T) {
 	logger := zap.NewNop()
 	mockTaskDB := new(MockMediaTaskDB)
 
-	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, logger)
+	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	userID := uuid.New()
 	taskID := uuid.New()
@@ -1041,7 +1353,7 @@ func TestDomain_GetVideoStatus_Failed(t *testing.T) {
 
 func TestDomain_GetVideoStatus_InvalidTaskID(t *testing.T) {
 	logger := zap.NewNop()
-	domain := NewDomain(nil, nil, nil, nil, nil, nil, nil, logger)
+	domain := NewDomain(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	userID := uuid.New()
 
@@ -1055,7 +1367,7 @@ func TestDomain_CancelTask_AlreadyCancelled(t *testing.T) {
 	logger := zap.NewNop()
 	mockTaskDB := new(MockMediaTaskDB)
 
-	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, logger)
+	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	userID := uuid.New()
 	taskID := uuid.New()
@@ -1077,7 +1389,7 @@ func TestDomain_CancelTask_Failed(t *testing.T) {
 	logger := zap.NewNop()
 	mockTaskDB := new(MockMediaTaskDB)
 
-	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, logger)
+	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	userID := uuid.New()
 	taskID := uuid.New()
@@ -1099,7 +1411,7 @@ func TestDomain_GetTask_NotFound(t *testing.T) {
 	logger := zap.NewNop()
 	mockTaskDB := new(MockMediaTaskDB)
 
-	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, logger)
+	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	userID := uuid.New()
 	taskID := uuid.New()
@@ -1116,7 +1428,7 @@ func TestDomain_GetTask_NotOwned(t *testing.T) {
 	logger := zap.NewNop()
 	mockTaskDB := new(MockMediaTaskDB)
 
-	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, logger)
+	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	userID := uuid.New()
 	otherUserID := uuid.New()
@@ -1139,7 +1451,7 @@ func TestDomain_GenerateImage_CapabilityNotSupported(t *testing.T) {
 	logger := zap.NewNop()
 	mockModelDB := new(MockMediaModelDB)
 
-	domain := NewDomain(nil, mockModelDB, nil, nil, nil, nil, nil, logger)
+	domain := NewDomain(nil, mockModelDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	userID := uuid.New()
 	providerID := uuid.New()
@@ -1182,6 +1494,12 @@ func TestDomain_GenerateImage_AutoSelectModel(t *testing.T) {
 		mockVendorReg,
 		mockCrypto,
 		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		logger,
 	)
 
@@ -1217,6 +1535,7 @@ func TestDomain_GenerateImage_AutoSelectModel(t *testing.T) {
 	mockModelDB.On("FindByCapability", mock.Anything, model.MediaCapabilityImage).Return([]*model.MediaModel{mediaModel}, nil)
 	mockProviderDB.On("FindByID", mock.Anything, providerID).Return(provider, nil)
 	mockHealthCache.On("GetHealth", mock.Anything, providerID).Return(true, nil)
+	mockHealthCache.On("SetHealth", mock.Anything, providerID, true).Return(nil)
 	mockCrypto.On("Decrypt", "encrypted-key").Return("sk-test-key", nil)
 	mockVendorReg.On("GetForProvider", provider).Return(mockAdapter, nil)
 	mockAdapter.On("GenerateImage", mock.Anything, mock.AnythingOfType("*model.ImageRequest"), mediaModel, provider, "sk-test-key").Return(expectedResp, nil)
@@ -1237,7 +1556,7 @@ func TestDomain_FindModelWithCapability_NoModels(t *testing.T) {
 	logger := zap.NewNop()
 	mockModelDB := new(MockMediaModelDB)
 
-	domain := NewDomain(nil, mockModelDB, nil, nil, nil, nil, nil, logger)
+	domain := NewDomain(nil, mockModelDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	mockModelDB.On("FindByCapability", mock.Anything, model.MediaCapabilityImage).Return([]*model.MediaModel{}, nil)
 
@@ -1267,6 +1586,12 @@ func TestDomain_FindModelWithCapability_NoHealthyProvider(t *testing.T) {
 		nil,
 		nil,
 		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		logger,
 	)
 
@@ -1300,26 +1625,192 @@ func TestDomain_FindModelWithCapability_NoHealthyProvider(t *testing.T) {
 	assert.Nil(t, output)
 }
 
-func TestDomain_ExecuteVideoTask_Success(t *testing.T) {
-	logger := zap.NewNop()
-	mockProviderDB := new(MockMediaProviderDB)
-	mockModelDB := new(MockMediaModelDB)
-	mockTaskDB := new(MockMediaTaskDB)
-	mockHealthCache := new(MockMediaHealthCache)
-	mockVendorReg := new(MockMediaVendorRegistry)
-	mockCrypto := new(MockMediaCrypto)
-	mockAdapter := new(MockMediaVendorAdapter)
-
-	config := DefaultConfig()
-	config.VideoPollInterval = 1 * time.Millisecond // Speed up for testing
+// routingFixture wires a domain with two image models on separate providers.
+type routingFixture struct {
+	domain      *Domain
+	healthCache *MockMediaHealthCache
+	cheap       *model.MediaModel
+	pricey      *model.MediaModel
+	cheapProv   *model.MediaProvider
+	priceyProv  *model.MediaProvider
+	cheapAPI    *MockMediaVendorAdapter
+	priceyAPI   *MockMediaVendorAdapter
+}
 
-	domain := NewDomain(
-		mockProviderDB,
-		mockModelDB,
+func newRoutingFixture() *routingFixture {
+	f := &routingFixture{
+		healthCache: new(MockMediaHealthCache),
+		cheapAPI:    new(MockMediaVendorAdapter),
+		priceyAPI:   new(MockMediaVendorAdapter),
+	}
+	providerDB := new(MockMediaProviderDB)
+	modelDB := new(MockMediaModelDB)
+	vendorReg := new(MockMediaVendorRegistry)
+	crypto := new(MockMediaCrypto)
+
+	f.cheapProv = &model.MediaProvider{ID: uuid.New(), Name: "Cheap", EncryptedKey: "cheap-key", Enabled: true}
+	f.priceyProv = &model.MediaProvider{ID: uuid.New(), Name: "Pricey", EncryptedKey: "pricey-key", Enabled: true}
+	f.cheap = &model.MediaModel{
+		ID:           "cheap-image",
+		ProviderID:   f.cheapProv.ID,
+		Capabilities: []model.MediaCapability{model.MediaCapabilityImage},
+		CostPerImage: 0.01,
+		Enabled:      true,
+	}
+	f.pricey = &model.MediaModel{
+		ID:           "pricey-image",
+		ProviderID:   f.priceyProv.ID,
+		Capabilities: []model.MediaCapability{model.MediaCapabilityImage},
+		CostPerImage: 0.04,
+		Enabled:      true,
+	}
+
+	modelDB.On("FindByCapability", mock.Anything, model.MediaCapabilityImage).Return([]*model.MediaModel{f.pricey, f.cheap}, nil)
+	providerDB.On("FindByID", mock.Anything, f.cheapProv.ID).Return(f.cheapProv, nil)
+	providerDB.On("FindByID", mock.Anything, f.priceyProv.ID).Return(f.priceyProv, nil)
+	crypto.On("Decrypt", "cheap-key").Return("sk-cheap", nil)
+	crypto.On("Decrypt", "pricey-key").Return("sk-pricey", nil)
+	vendorReg.On("GetForProvider", f.cheapProv).Return(f.cheapAPI, nil)
+	vendorReg.On("GetForProvider", f.priceyProv).Return(f.priceyAPI, nil)
+	f.healthCache.On("SetHealth", mock.Anything, mock.Anything, true).Return(nil)
+
+	f.domain = NewDomain(providerDB, modelDB, nil, f.healthCache, vendorReg, crypto, nil, nil, nil, nil, nil, nil, nil, zap.NewNop())
+	return f
+}
+
+func routedImage(modelID string) *model.ImageResponse {
+	return &model.ImageResponse{
+		Images:    []*model.GeneratedImage{{URL: "https://example.com/" + modelID + ".png"}},
+		Model:     modelID,
+		CreatedAt: time.Now().Unix(),
+	}
+}
+
+func TestDomain_GenerateImage_Routing(t *testing.T) {
+	t.Run("prefers cheapest model", func(t *testing.T) {
+		f := newRoutingFixture()
+		f.healthCache.On("GetHealth", mock.Anything, mock.Anything).Return(true, nil)
+		f.cheapAPI.On("GenerateImage", mock.Anything, mock.Anything, f.cheap, f.cheapProv, "sk-cheap").Return(routedImage("cheap-image"), nil)
+
+		output, err := f.domain.GenerateImage(context.Background(), uuid.New(), &inbound.MediaImageGenerationInput{
+			Prompt: "A lighthouse",
+			Model:  "auto",
+		})
+
+		require.NoError(t, err)
+		require.NotNil(t, output.Routing)
+		assert.Equal(t, "cheap-image", output.Routing.ModelUsed)
+		assert.Equal(t, "Cheap", output.Routing.ProviderUsed)
+		assert.InDelta(t, 0.01, output.Routing.CostUSD, 1e-9)
+		assert.Empty(t, output.Routing.FallbackFrom)
+		f.priceyAPI.AssertNotCalled(t, "GenerateImage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("user preference outranks cost", func(t *testing.T) {
+		f := newRoutingFixture()
+		f.healthCache.On("GetHealth", mock.Anything, mock.Anything).Return(true, nil)
+		f.priceyAPI.On("GenerateImage", mock.Anything, mock.Anything, f.pricey, f.priceyProv, "sk-pricey").Return(routedImage("pricey-image"), nil)
+
+		output, err := f.domain.GenerateImage(context.Background(), uuid.New(), &inbound.MediaImageGenerationInput{
+			Prompt:          "A lighthouse",
+			PreferredModels: []string{"pricey-image"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "pricey-image", output.Routing.ModelUsed)
+		assert.Contains(t, output.Routing.Reason, "user preference")
+	})
+
+	t.Run("skips unhealthy provider", func(t *testing.T) {
+		f := newRoutingFixture()
+		f.healthCache.On("GetHealth", mock.Anything, f.cheapProv.ID).Return(false, nil)
+		f.healthCache.On("GetHealth", mock.Anything, f.priceyProv.ID).Return(true, nil)
+		f.priceyAPI.On("GenerateImage", mock.Anything, mock.Anything, f.pricey, f.priceyProv, "sk-pricey").Return(routedImage("pricey-image"), nil)
+
+		output, err := f.domain.GenerateImage(context.Background(), uuid.New(), &inbound.MediaImageGenerationInput{Prompt: "A lighthouse"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "pricey-image", output.Routing.ModelUsed)
+		f.cheapAPI.AssertNotCalled(t, "GenerateImage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("falls back when provider fails", func(t *testing.T) {
+		f := newRoutingFixture()
+		f.healthCache.On("GetHealth", mock.Anything, mock.Anything).Return(true, nil)
+		f.healthCache.On("RecordFailure", mock.Anything, f.cheapProv.ID).Return(int64(1), nil)
+		f.cheapAPI.On("GenerateImage", mock.Anything, mock.Anything, f.cheap, f.cheapProv, "sk-cheap").
+			Return(nil, &outbound.MediaVendorStatusError{StatusCode: http.StatusServiceUnavailable, Message: "upstream down"})
+		f.priceyAPI.On("GenerateImage", mock.Anything, mock.Anything, f.pricey, f.priceyProv, "sk-pricey").Return(routedImage("pricey-image"), nil)
+
+		output, err := f.domain.GenerateImage(context.Background(), uuid.New(), &inbound.MediaImageGenerationInput{Prompt: "A lighthouse"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "pricey-image", output.Routing.ModelUsed)
+		assert.Equal(t, []string{"cheap-image"}, output.Routing.FallbackFrom)
+		assert.InDelta(t, 0.04, output.Routing.CostUSD, 1e-9)
+		// A single fault doesn't take the provider out of routing
+		f.healthCache.AssertNotCalled(t, "SetHealth", mock.Anything, f.cheapProv.ID, false)
+		f.healthCache.AssertCalled(t, "SetHealth", mock.Anything, f.priceyProv.ID, true)
+	})
+
+	t.Run("repeated faults mark provider unhealthy", func(t *testing.T) {
+		f := newRoutingFixture()
+		f.healthCache.On("GetHealth", mock.Anything, mock.Anything).Return(true, nil)
+		f.healthCache.On("RecordFailure", mock.Anything, f.cheapProv.ID).Return(int64(3), nil)
+		f.healthCache.On("SetHealth", mock.Anything, f.cheapProv.ID, false).Return(nil)
+		f.cheapAPI.On("GenerateImage", mock.Anything, mock.Anything, f.cheap, f.cheapProv, "sk-cheap").
+			Return(nil, fmt.Errorf("execute request: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+		f.priceyAPI.On("GenerateImage", mock.Anything, mock.Anything, f.pricey, f.priceyProv, "sk-pricey").Return(routedImage("pricey-image"), nil)
+
+		_, err := f.domain.GenerateImage(context.Background(), uuid.New(), &inbound.MediaImageGenerationInput{Prompt: "A lighthouse"})
+
+		require.NoError(t, err)
+		f.healthCache.AssertCalled(t, "SetHealth", mock.Anything, f.cheapProv.ID, false)
+	})
+
+	t.Run("rejected request does not count against provider", func(t *testing.T) {
+		f := newRoutingFixture()
+		f.healthCache.On("GetHealth", mock.Anything, mock.Anything).Return(true, nil)
+		f.cheapAPI.On("GenerateImage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, &outbound.MediaVendorStatusError{StatusCode: http.StatusBadRequest, Message: "prompt rejected"})
+		f.priceyAPI.On("GenerateImage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(nil, &outbound.MediaVendorStatusError{StatusCode: http.StatusBadRequest, Message: "prompt rejected"})
+
+		output, err := f.domain.GenerateImage(context.Background(), uuid.New(), &inbound.MediaImageGenerationInput{Prompt: "A lighthouse"})
+
+		assert.Nil(t, output)
+		assert.EqualError(t, err, "generate image: unexpected status code 400: prompt rejected")
+		f.healthCache.AssertNotCalled(t, "RecordFailure", mock.Anything, mock.Anything)
+		f.healthCache.AssertNotCalled(t, "SetHealth", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDomain_ExecuteVideoTask_Success(t *testing.T) {
+	logger := zap.NewNop()
+	mockProviderDB := new(MockMediaProviderDB)
+	mockModelDB := new(MockMediaModelDB)
+	mockTaskDB := new(MockMediaTaskDB)
+	mockHealthCache := new(MockMediaHealthCache)
+	mockVendorReg := new(MockMediaVendorRegistry)
+	mockCrypto := new(MockMediaCrypto)
+	mockAdapter := new(MockMediaVendorAdapter)
+
+	config := DefaultConfig()
+	config.VideoPollInterval = 1 * time.Millisecond // Speed up for testing
+
+	domain := NewDomain(
+		mockProviderDB,
+		mockModelDB,
 		mockTaskDB,
 		mockHealthCache,
 		mockVendorReg,
 		mockCrypto,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
+		nil,
 		config,
 		logger,
 	)
@@ -1362,25 +1853,29 @@ func TestDomain_ExecuteVideoTask_Success(t *testing.T) {
 	}
 
 	mockTaskDB.On("FindByID", mock.Anything, taskID).Return(task, nil)
-	mockTaskDB.On("UpdateStatus", mock.Anything, taskID, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockTaskDB.On("TransitionStatus", mock.Anything, taskID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
 	mockModelDB.On("FindByID", mock.Anything, "video-model").Return(mediaModel, nil)
 	mockProviderDB.On("FindByID", mock.Anything, providerID).Return(provider, nil)
 	mockHealthCache.On("GetHealth", mock.Anything, providerID).Return(true, nil)
+	mockHealthCache.On("SetHealth", mock.Anything, providerID, true).Return(nil)
 	mockCrypto.On("Decrypt", "encrypted-key").Return("sk-test-key", nil)
 	mockVendorReg.On("GetForProvider", provider).Return(mockAdapter, nil)
 	mockAdapter.On("GenerateVideo", mock.Anything, mock.AnythingOfType("*model.VideoRequest"), mediaModel, provider, "sk-test-key").Return(videoResp, nil)
 	mockAdapter.On("GetVideoStatus", mock.Anything, "provider-task-123", provider, "sk-test-key").Return(completedStatus, nil)
+	mockTaskDB.On("RecordExternalTask", mock.Anything, taskID, mock.Anything, mock.Anything, 30).Return(true, nil)
 
 	err := domain.ExecuteVideoTask(context.Background(), taskID)
 
 	assert.NoError(t, err)
+	assert.Equal(t, "provider-task-123", task.ExternalTaskID)
+	assert.Equal(t, "video-model", task.ModelID)
 }
 
 func TestDomain_ExecuteVideoTask_NotFound(t *testing.T) {
 	logger := zap.NewNop()
 	mockTaskDB := new(MockMediaTaskDB)
 
-	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, logger)
+	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	taskID := uuid.New()
 	mockTaskDB.On("FindByID", mock.Anything, taskID).Return(nil, nil)
@@ -1394,7 +1889,7 @@ func TestDomain_ExecuteVideoTask_InvalidInput(t *testing.T) {
 	logger := zap.NewNop()
 	mockTaskDB := new(MockMediaTaskDB)
 
-	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, logger)
+	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, logger)
 
 	taskID := uuid.New()
 	task := &model.MediaTask{
@@ -1404,10 +1899,922 @@ func TestDomain_ExecuteVideoTask_InvalidInput(t *testing.T) {
 	}
 
 	mockTaskDB.On("FindByID", mock.Anything, taskID).Return(task, nil)
-	mockTaskDB.On("UpdateStatus", mock.Anything, taskID, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockTaskDB.On("TransitionStatus", mock.Anything, taskID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
 
 	err := domain.ExecuteVideoTask(context.Background(), taskID)
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "unmarshal input")
 }
+
+// videoTaskFixture wires a domain with one healthy video model for executor tests.
+type videoTaskFixture struct {
+	domain      *Domain
+	taskDB      *MockMediaTaskDB
+	adapter     *MockMediaVendorAdapter
+	quota       *MockMediaVideoQuota
+	healthCache *MockMediaHealthCache
+	provider    *model.MediaProvider
+	mediaModel  *model.MediaModel
+}
+
+func newVideoTaskFixture() *videoTaskFixture {
+	f := &videoTaskFixture{
+		taskDB:      new(MockMediaTaskDB),
+		adapter:     new(MockMediaVendorAdapter),
+		quota:       new(MockMediaVideoQuota),
+		healthCache: new(MockMediaHealthCache),
+	}
+	providerDB := new(MockMediaProviderDB)
+	modelDB := new(MockMediaModelDB)
+	vendorReg := new(MockMediaVendorRegistry)
+	crypto := new(MockMediaCrypto)
+
+	f.provider = &model.MediaProvider{
+		ID:           uuid.New(),
+		Type:         model.MediaProviderTypeOpenAI,
+		EncryptedKey: "encrypted-key",
+		Enabled:      true,
+	}
+	f.mediaModel = &model.MediaModel{
+		ID:           "video-model",
+		ProviderID:   f.provider.ID,
+		Capabilities: []model.MediaCapability{model.MediaCapabilityVideo},
+		Enabled:      true,
+	}
+
+	modelDB.On("FindByID", mock.Anything, "video-model").Return(f.mediaModel, nil)
+	providerDB.On("FindByID", mock.Anything, f.provider.ID).Return(f.provider, nil)
+	f.healthCache.On("GetHealth", mock.Anything, f.provider.ID).Return(true, nil)
+	f.healthCache.On("SetHealth", mock.Anything, f.provider.ID, true).Return(nil)
+	crypto.On("Decrypt", "encrypted-key").Return("sk-test-key", nil)
+	vendorReg.On("GetForProvider", f.provider).Return(f.adapter, nil)
+
+	config := DefaultConfig()
+	config.VideoPollInterval = time.Millisecond
+	config.WorkerPollInterval = time.Millisecond
+
+	f.domain = NewDomain(providerDB, modelDB, f.taskDB, f.healthCache, vendorReg, crypto, f.quota, nil, nil, nil, nil, nil, config, zap.NewNop())
+	return f
+}
+
+func newVideoTask(input string) *model.MediaTask {
+	return &model.MediaTask{
+		ID:        uuid.New(),
+		OwnerID:   uuid.New(),
+		Type:      "video_generation",
+		Status:    model.MediaTaskStatusPending,
+		Input:     common.NewString(input),
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestDomain_GenerateVideo_QuotaExceeded(t *testing.T) {
+	logger := zap.NewNop()
+
+	t.Run("no minutes left", func(t *testing.T) {
+		mockQuota := new(MockMediaVideoQuota)
+		domain := NewDomain(nil, nil, nil, nil, nil, nil, mockQuota, nil, nil, nil, nil, nil, nil, logger)
+
+		userID := uuid.New()
+		mockQuota.On("GetVideoSecondsRemaining", mock.Anything, userID).Return(int64(0), nil)
+
+		output, err := domain.GenerateVideo(context.Background(), userID, &inbound.MediaVideoGenerationInput{Prompt: "test"})
+
+		assert.ErrorIs(t, err, ErrVideoQuotaExceeded)
+		assert.Nil(t, output)
+	})
+
+	t.Run("requested duration exceeds remaining", func(t *testing.T) {
+		mockQuota := new(MockMediaVideoQuota)
+		domain := NewDomain(nil, nil, nil, nil, nil, nil, mockQuota, nil, nil, nil, nil, nil, nil, logger)
+
+		userID := uuid.New()
+		mockQuota.On("GetVideoSecondsRemaining", mock.Anything, userID).Return(int64(5), nil)
+
+		output, err := domain.GenerateVideo(context.Background(), userID, &inbound.MediaVideoGenerationInput{Prompt: "test", Duration: 10})
+
+		assert.ErrorIs(t, err, ErrVideoQuotaExceeded)
+		assert.Nil(t, output)
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		mockQuota := new(MockMediaVideoQuota)
+		mockTaskDB := new(MockMediaTaskDB)
+		domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, mockQuota, nil, nil, nil, nil, nil, nil, logger)
+
+		userID := uuid.New()
+		mockQuota.On("GetVideoSecondsRemaining", mock.Anything, userID).Return(int64(-1), nil)
+		mockTaskDB.On("Create", mock.Anything, mock.AnythingOfType("*model.MediaTask")).Return(nil)
+
+		output, err := domain.GenerateVideo(context.Background(), userID, &inbound.MediaVideoGenerationInput{Prompt: "test", Duration: 600})
+
+		assert.NoError(t, err)
+		assert.NotNil(t, output)
+	})
+}
+
+func TestDomain_ExecuteVideoTask_RecordsVideoUsage(t *testing.T) {
+	f := newVideoTaskFixture()
+	task := newVideoTask(`{"prompt":"test video","model":"video-model","duration":5}`)
+
+	f.taskDB.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+	f.taskDB.On("TransitionStatus", mock.Anything, task.ID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	f.taskDB.On("RecordExternalTask", mock.Anything, task.ID, mock.Anything, mock.Anything, 30).Return(true, nil)
+	f.quota.On("GetVideoSecondsRemaining", mock.Anything, task.OwnerID).Return(int64(60), nil)
+	f.adapter.On("GenerateVideo", mock.Anything, mock.AnythingOfType("*model.VideoRequest"), f.mediaModel, f.provider, "sk-test-key").
+		Return(&model.VideoResponse{TaskID: "provider-task-1"}, nil)
+	f.adapter.On("GetVideoStatus", mock.Anything, "provider-task-1", f.provider, "sk-test-key").
+		Return(&model.VideoStatus{Status: model.VideoStateCompleted, Video: &model.GeneratedVideo{URL: "https://example.com/v.mp4", Duration: 6}}, nil)
+	f.quota.On("RecordVideoUsage", mock.Anything, task.OwnerID, task.ID, f.provider.ID, "video-model", 6).Return(nil)
+
+	err := f.domain.ExecuteVideoTask(context.Background(), task.ID)
+
+	assert.NoError(t, err)
+	f.taskDB.AssertCalled(t, "TransitionStatus", mock.Anything, task.ID, mock.Anything, model.MediaTaskStatusCompleted, 100, mock.Anything, "")
+	f.quota.AssertExpectations(t)
+}
+
+func TestDomain_ExecuteVideoTask_QuotaExceeded(t *testing.T) {
+	f := newVideoTaskFixture()
+	task := newVideoTask(`{"prompt":"test video","model":"video-model","duration":30}`)
+
+	f.taskDB.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+	f.taskDB.On("TransitionStatus", mock.Anything, task.ID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	f.quota.On("GetVideoSecondsRemaining", mock.Anything, task.OwnerID).Return(int64(10), nil)
+
+	err := f.domain.ExecuteVideoTask(context.Background(), task.ID)
+
+	assert.ErrorIs(t, err, ErrVideoQuotaExceeded)
+	f.taskDB.AssertCalled(t, "TransitionStatus", mock.Anything, task.ID, mock.Anything, model.MediaTaskStatusFailed, 0, "", ErrVideoQuotaExceeded.Error())
+	f.adapter.AssertNotCalled(t, "GenerateVideo", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDomain_ExecuteVideoTask_ResumesSubmittedTask(t *testing.T) {
+	f := newVideoTaskFixture()
+	task := newVideoTask(`{"prompt":"test video","model":"auto"}`)
+	task.Status = model.MediaTaskStatusRunning
+	task.ModelID = "video-model"
+	task.ExternalTaskID = "provider-task-2"
+
+	f.taskDB.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+	f.taskDB.On("TransitionStatus", mock.Anything, task.ID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	f.adapter.On("GetVideoStatus", mock.Anything, "provider-task-2", f.provider, "sk-test-key").
+		Return(&model.VideoStatus{Status: model.VideoStateCompleted, Video: &model.GeneratedVideo{Duration: 4}}, nil)
+	f.quota.On("RecordVideoUsage", mock.Anything, task.OwnerID, task.ID, f.provider.ID, "video-model", 4).Return(nil)
+
+	err := f.domain.ExecuteVideoTask(context.Background(), task.ID)
+
+	assert.NoError(t, err)
+	f.adapter.AssertNotCalled(t, "GenerateVideo", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	f.quota.AssertExpectations(t)
+	// The running job is polled whatever the provider's health
+	f.healthCache.AssertNotCalled(t, "GetHealth", mock.Anything, mock.Anything)
+}
+
+func TestDomain_ExecuteVideoTask_StopsWhenCancelled(t *testing.T) {
+	f := newVideoTaskFixture()
+	task := newVideoTask(`{"prompt":"test video","model":"video-model"}`)
+	cancelled := *task
+	cancelled.Status = model.MediaTaskStatusCancelled
+
+	f.taskDB.On("FindByID", mock.Anything, task.ID).Return(task, nil).Once()
+	f.taskDB.On("FindByID", mock.Anything, task.ID).Return(&cancelled, nil)
+	f.taskDB.On("TransitionStatus", mock.Anything, task.ID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	f.taskDB.On("RecordExternalTask", mock.Anything, task.ID, mock.Anything, mock.Anything, 30).Return(true, nil)
+	f.quota.On("GetVideoSecondsRemaining", mock.Anything, task.OwnerID).Return(int64(-1), nil)
+	f.adapter.On("GenerateVideo", mock.Anything, mock.Anything, f.mediaModel, f.provider, "sk-test-key").
+		Return(&model.VideoResponse{TaskID: "provider-task-3"}, nil)
+	f.adapter.On("GetVideoStatus", mock.Anything, "provider-task-3", f.provider, "sk-test-key").
+		Return(&model.VideoStatus{Status: model.VideoStateProcessing, Progress: 50}, nil)
+
+	err := f.domain.ExecuteVideoTask(context.Background(), task.ID)
+
+	assert.NoError(t, err)
+	f.adapter.AssertNumberOfCalls(t, "GetVideoStatus", 1)
+	f.taskDB.AssertNotCalled(t, "TransitionStatus", mock.Anything, task.ID, mock.Anything, model.MediaTaskStatusCompleted, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDomain_ExecuteVideoTask_CancelledDuringPoll(t *testing.T) {
+	t.Run("progress write does not revive the task", func(t *testing.T) {
+		f := newVideoTaskFixture()
+		task := newVideoTask(`{"prompt":"test video","model":"video-model"}`)
+
+		f.taskDB.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+		f.taskDB.On("TransitionStatus", mock.Anything, task.ID, mock.Anything, model.MediaTaskStatusRunning, 60, "", "").Return(false, nil)
+		f.taskDB.On("TransitionStatus", mock.Anything, task.ID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		f.taskDB.On("RecordExternalTask", mock.Anything, task.ID, "video-model", "provider-task-5", 30).Return(true, nil)
+		f.quota.On("GetVideoSecondsRemaining", mock.Anything, task.OwnerID).Return(int64(-1), nil)
+		f.adapter.On("GenerateVideo", mock.Anything, mock.Anything, f.mediaModel, f.provider, "sk-test-key").
+			Return(&model.VideoResponse{TaskID: "provider-task-5"}, nil)
+		f.adapter.On("GetVideoStatus", mock.Anything, "provider-task-5", f.provider, "sk-test-key").
+			Return(&model.VideoStatus{Status: model.VideoStateProcessing, Progress: 50}, nil)
+
+		err := f.domain.ExecuteVideoTask(context.Background(), task.ID)
+
+		assert.NoError(t, err)
+		f.adapter.AssertNumberOfCalls(t, "GetVideoStatus", 1)
+		f.taskDB.AssertNotCalled(t, "TransitionStatus", mock.Anything, task.ID, mock.Anything, model.MediaTaskStatusFailed, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("completion after cancel is not billed", func(t *testing.T) {
+		f := newVideoTaskFixture()
+		task := newVideoTask(`{"prompt":"test video","model":"auto"}`)
+		task.Status = model.MediaTaskStatusRunning
+		task.ModelID = "video-model"
+		task.ExternalTaskID = "provider-task-6"
+
+		f.taskDB.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+		f.taskDB.On("TransitionStatus", mock.Anything, task.ID, mock.Anything, model.MediaTaskStatusCompleted, 100, mock.Anything, "").Return(false, nil)
+		f.taskDB.On("TransitionStatus", mock.Anything, task.ID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		f.adapter.On("GetVideoStatus", mock.Anything, "provider-task-6", f.provider, "sk-test-key").
+			Return(&model.VideoStatus{Status: model.VideoStateCompleted, Video: &model.GeneratedVideo{Duration: 4}}, nil)
+
+		err := f.domain.ExecuteVideoTask(context.Background(), task.ID)
+
+		assert.NoError(t, err)
+		f.quota.AssertNotCalled(t, "RecordVideoUsage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDomain_Worker(t *testing.T) {
+	t.Run("executes claimed tasks", func(t *testing.T) {
+		f := newVideoTaskFixture()
+		task := newVideoTask(`{"prompt":"test video","model":"video-model"}`)
+		done := make(chan struct{})
+
+		f.taskDB.On("FindPending", mock.Anything, 10, 2*time.Minute).Return([]*model.MediaTask{task}, nil).Once()
+		f.taskDB.On("FindPending", mock.Anything, mock.Anything, mock.Anything).Return([]*model.MediaTask{}, nil)
+		f.taskDB.On("Claim", mock.Anything, task.ID, 2*time.Minute).Return(true, nil)
+		f.taskDB.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+		f.taskDB.On("TransitionStatus", mock.Anything, task.ID, mock.Anything, model.MediaTaskStatusCompleted, 100, mock.Anything, "").
+			Return(true, nil).Run(func(mock.Arguments) { close(done) })
+		f.taskDB.On("TransitionStatus", mock.Anything, task.ID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		f.taskDB.On("RecordExternalTask", mock.Anything, task.ID, mock.Anything, mock.Anything, 30).Return(true, nil)
+		f.quota.On("GetVideoSecondsRemaining", mock.Anything, task.OwnerID).Return(int64(-1), nil)
+		f.quota.On("RecordVideoUsage", mock.Anything, task.OwnerID, task.ID, f.provider.ID, "video-model", 8).Return(nil)
+		f.adapter.On("GenerateVideo", mock.Anything, mock.Anything, f.mediaModel, f.provider, "sk-test-key").
+			Return(&model.VideoResponse{TaskID: "provider-task-4"}, nil)
+		f.adapter.On("GetVideoStatus", mock.Anything, "provider-task-4", f.provider, "sk-test-key").
+			Return(&model.VideoStatus{Status: model.VideoStateCompleted, Video: &model.GeneratedVideo{Duration: 8}}, nil)
+
+		f.domain.StartWorker(context.Background())
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("task was not executed")
+		}
+		f.domain.StopWorker()
+
+		f.quota.AssertExpectations(t)
+	})
+
+	t.Run("skips tasks claimed elsewhere", func(t *testing.T) {
+		f := newVideoTaskFixture()
+		task := newVideoTask(`{"prompt":"test video","model":"video-model"}`)
+		polled := make(chan struct{}, 1)
+
+		f.taskDB.On("FindPending", mock.Anything, mock.Anything, mock.Anything).Return([]*model.MediaTask{task}, nil).
+			Run(func(mock.Arguments) {
+				select {
+				case polled <- struct{}{}:
+				default:
+				}
+			})
+		f.taskDB.On("Claim", mock.Anything, task.ID, mock.Anything).Return(false, nil)
+
+		f.domain.StartWorker(context.Background())
+		<-polled
+		f.domain.StopWorker()
+
+		f.taskDB.AssertNotCalled(t, "FindByID", mock.Anything, task.ID)
+	})
+
+	t.Run("sends heartbeats while a task runs", func(t *testing.T) {
+		f := newVideoTaskFixture()
+		f.domain.config.TaskHeartbeatInterval = time.Millisecond
+		task := newVideoTask(`{"prompt":"test video","model":"video-model"}`)
+		task.Status = model.MediaTaskStatusRunning
+		task.ExternalTaskID = "provider-task-7"
+		task.ModelID = "video-model"
+		beat := make(chan struct{})
+		var once sync.Once
+
+		f.taskDB.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+		f.taskDB.On("Heartbeat", mock.Anything, task.ID).Return(true, nil).
+			Run(func(mock.Arguments) { once.Do(func() { close(beat) }) })
+		f.taskDB.On("TransitionStatus", mock.Anything, task.ID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		f.quota.On("RecordVideoUsage", mock.Anything, task.OwnerID, task.ID, f.provider.ID, "video-model", 4).Return(nil)
+		f.adapter.On("GetVideoStatus", mock.Anything, "provider-task-7", f.provider, "sk-test-key").
+			Return(&model.VideoStatus{Status: model.VideoStateProcessing, Progress: 50}, nil).
+			Run(func(mock.Arguments) { <-beat }).Once()
+		f.adapter.On("GetVideoStatus", mock.Anything, "provider-task-7", f.provider, "sk-test-key").
+			Return(&model.VideoStatus{Status: model.VideoStateCompleted, Video: &model.GeneratedVideo{Duration: 4}}, nil)
+
+		f.domain.runVideoTask(context.Background(), task.ID)
+
+		f.taskDB.AssertCalled(t, "Heartbeat", mock.Anything, task.ID)
+	})
+}
+
+// imageAssetFixture wires a domain whose image generations are stored as assets.
+type imageAssetFixture struct {
+	domain     *Domain
+	adapter    *MockMediaVendorAdapter
+	taskDB     *MockMediaTaskDB
+	assetDB    *MockMediaAssetDB
+	storage    *MockStorage
+	quota      *MockMediaStorageQuota
+	mediaModel *model.MediaModel
+	provider   *model.MediaProvider
+}
+
+func newImageAssetFixture() *imageAssetFixture {
+	f := &imageAssetFixture{
+		adapter: new(MockMediaVendorAdapter),
+		taskDB:  new(MockMediaTaskDB),
+		assetDB: new(MockMediaAssetDB),
+		storage: new(MockStorage),
+		quota:   new(MockMediaStorageQuota),
+	}
+	providerDB := new(MockMediaProviderDB)
+	modelDB := new(MockMediaModelDB)
+	healthCache := new(MockMediaHealthCache)
+	vendorReg := new(MockMediaVendorRegistry)
+	crypto := new(MockMediaCrypto)
+
+	f.provider = &model.MediaProvider{
+		ID:           uuid.New(),
+		Type:         model.MediaProviderTypeOpenAI,
+		EncryptedKey: "encrypted-key",
+		Enabled:      true,
+	}
+	f.mediaModel = &model.MediaModel{
+		ID:           "image-model",
+		ProviderID:   f.provider.ID,
+		Capabilities: []model.MediaCapability{model.MediaCapabilityImage},
+		Enabled:      true,
+	}
+
+	modelDB.On("FindByID", mock.Anything, "image-model").Return(f.mediaModel, nil)
+	providerDB.On("FindByID", mock.Anything, f.provider.ID).Return(f.provider, nil)
+	healthCache.On("GetHealth", mock.Anything, f.provider.ID).Return(true, nil)
+	healthCache.On("SetHealth", mock.Anything, f.provider.ID, true).Return(nil)
+	crypto.On("Decrypt", "encrypted-key").Return("sk-test-key", nil)
+	vendorReg.On("GetForProvider", f.provider).Return(f.adapter, nil)
+	f.taskDB.On("Create", mock.Anything, mock.AnythingOfType("*model.MediaTask")).Return(nil).Maybe()
+	f.taskDB.On("UpdateStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil).Maybe()
+
+	f.domain = NewDomain(providerDB, modelDB, f.taskDB, healthCache, vendorReg, crypto, nil, f.assetDB, f.storage, nil, f.quota, nil, nil, zap.NewNop())
+	return f
+}
+
+// testPNG returns an encoded w×h PNG.
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDomain_GenerateImage_StoresAssets(t *testing.T) {
+	t.Run("stores base64 image and returns presigned URL", func(t *testing.T) {
+		f := newImageAssetFixture()
+		userID := uuid.New()
+		data := testPNG(t, 64, 32)
+
+		f.quota.On("GetMediaStorageLimit", mock.Anything, userID).Return(int64(-1), nil)
+		f.adapter.On("GenerateImage", mock.Anything, mock.AnythingOfType("*model.ImageRequest"), f.mediaModel, f.provider, "sk-test-key").
+			Return(&model.ImageResponse{
+				Images: []*model.GeneratedImage{{B64JSON: base64.StdEncoding.EncodeToString(data)}},
+				Model:  "image-model",
+			}, nil)
+		f.assetDB.On("FindByOwnerAndHash", mock.Anything, userID, mock.Anything).Return(nil, nil)
+		f.storage.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "media/"+userID.String()+"/") && strings.HasSuffix(key, ".png")
+		}), mock.Anything, int64(len(data))).Return(nil)
+
+		var stored *model.MediaAsset
+		f.assetDB.On("Create", mock.Anything, mock.AnythingOfType("*model.MediaAsset")).
+			Run(func(args mock.Arguments) { stored = args.Get(1).(*model.MediaAsset) }).
+			Return(nil)
+		f.storage.On("GetPresignedURL", mock.Anything, mock.Anything, time.Hour).Return("https://storage.example.com/signed", nil)
+
+		output, err := f.domain.GenerateImage(context.Background(), userID, &inbound.MediaImageGenerationInput{
+			Prompt: "a cat",
+			Model:  "image-model",
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, output.Images, 1)
+		assert.Equal(t, "https://storage.example.com/signed", output.Images[0].URL)
+		assert.Empty(t, output.Images[0].B64JSON)
+		assert.NotEmpty(t, output.TaskID)
+		f.taskDB.AssertCalled(t, "UpdateStatus", mock.Anything, mock.Anything, model.MediaTaskStatusCompleted, 100, mock.Anything, "")
+		if assert.NotNil(t, stored) {
+			assert.Equal(t, stored.ID.String(), output.Images[0].AssetID)
+			if assert.NotNil(t, stored.TaskID) {
+				assert.Equal(t, output.TaskID, stored.TaskID.String())
+			}
+			assert.Equal(t, "a cat", stored.Name)
+			assert.Equal(t, "image/png", stored.MimeType)
+			assert.Equal(t, 64, stored.Width)
+			assert.Equal(t, 32, stored.Height)
+			assert.Equal(t, "a cat", stored.Prompt)
+			assert.Len(t, stored.ContentHash, 64)
+		}
+	})
+
+	t.Run("reuses existing asset with same content", func(t *testing.T) {
+		f := newImageAssetFixture()
+		userID := uuid.New()
+		existing := &model.MediaAsset{ID: uuid.New(), OwnerID: userID, StorageKey: "media/existing.png"}
+
+		f.quota.On("GetMediaStorageLimit", mock.Anything, userID).Return(int64(-1), nil)
+		f.adapter.On("GenerateImage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(&model.ImageResponse{
+				Images: []*model.GeneratedImage{{B64JSON: base64.StdEncoding.EncodeToString(testPNG(t, 8, 8))}},
+			}, nil)
+		f.assetDB.On("FindByOwnerAndHash", mock.Anything, userID, mock.Anything).Return(existing, nil)
+		f.storage.On("GetPresignedURL", mock.Anything, "media/existing.png", time.Hour).Return("https://storage.example.com/existing", nil)
+
+		output, err := f.domain.GenerateImage(context.Background(), userID, &inbound.MediaImageGenerationInput{
+			Prompt: "a cat",
+			Model:  "image-model",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, existing.ID.String(), output.Images[0].AssetID)
+		f.storage.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		f.assetDB.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("storage quota exhausted before generating", func(t *testing.T) {
+		f := newImageAssetFixture()
+		userID := uuid.New()
+
+		f.quota.On("GetMediaStorageLimit", mock.Anything, userID).Return(int64(1024), nil)
+		f.assetDB.On("SumSizeByOwner", mock.Anything, userID).Return(int64(1024), nil)
+
+		output, err := f.domain.GenerateImage(context.Background(), userID, &inbound.MediaImageGenerationInput{
+			Prompt: "a cat",
+			Model:  "image-model",
+		})
+
+		assert.ErrorIs(t, err, ErrStorageQuotaExceeded)
+		assert.Nil(t, output)
+		f.adapter.AssertNotCalled(t, "GenerateImage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("generated image exceeds remaining storage", func(t *testing.T) {
+		f := newImageAssetFixture()
+		userID := uuid.New()
+
+		f.quota.On("GetMediaStorageLimit", mock.Anything, userID).Return(int64(100), nil)
+		f.assetDB.On("SumSizeByOwner", mock.Anything, userID).Return(int64(50), nil)
+		f.adapter.On("GenerateImage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(&model.ImageResponse{
+				Images: []*model.GeneratedImage{{B64JSON: base64.StdEncoding.EncodeToString(testPNG(t, 32, 32))}},
+			}, nil)
+		f.assetDB.On("FindByOwnerAndHash", mock.Anything, userID, mock.Anything).Return(nil, nil)
+
+		output, err := f.domain.GenerateImage(context.Background(), userID, &inbound.MediaImageGenerationInput{
+			Prompt: "a cat",
+			Model:  "image-model",
+		})
+
+		assert.ErrorIs(t, err, ErrStorageQuotaExceeded)
+		assert.Nil(t, output)
+		f.storage.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		f.taskDB.AssertCalled(t, "UpdateStatus", mock.Anything, mock.Anything, model.MediaTaskStatusFailed, 0, "", mock.Anything)
+	})
+}
+
+func TestDomain_ExecuteVideoTask_StoresVideoAsset(t *testing.T) {
+	f := newVideoTaskFixture()
+	assetDB := new(MockMediaAssetDB)
+	storage := new(MockStorage)
+	fetcher := new(MockMediaFetcher)
+	f.domain.assetDB = assetDB
+	f.domain.storage = storage
+	f.domain.fetcher = fetcher
+
+	task := newVideoTask(`{"prompt":"test video","model":"video-model","duration":5}`)
+	mp4 := append([]byte("\x00\x00\x00\x18ftypmp42"), make([]byte, 64)...)
+
+	f.taskDB.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+	f.taskDB.On("TransitionStatus", mock.Anything, task.ID, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	f.taskDB.On("RecordExternalTask", mock.Anything, task.ID, mock.Anything, mock.Anything, 30).Return(true, nil)
+	f.quota.On("GetVideoSecondsRemaining", mock.Anything, task.OwnerID).Return(int64(-1), nil)
+	f.quota.On("RecordVideoUsage", mock.Anything, task.OwnerID, task.ID, f.provider.ID, "video-model", 5).Return(nil)
+	f.adapter.On("GenerateVideo", mock.Anything, mock.Anything, f.mediaModel, f.provider, "sk-test-key").
+		Return(&model.VideoResponse{TaskID: "provider-task-1"}, nil)
+	f.adapter.On("GetVideoStatus", mock.Anything, "provider-task-1", f.provider, "sk-test-key").
+		Return(&model.VideoStatus{Status: model.VideoStateCompleted, Video: &model.GeneratedVideo{URL: "https://vendor.example.com/v.mp4", Duration: 5, Width: 1280, Height: 720}}, nil)
+	fetcher.On("Fetch", mock.Anything, "https://vendor.example.com/v.mp4", mock.Anything).Return(mp4, "video/mp4", nil)
+	assetDB.On("FindByOwnerAndHash", mock.Anything, task.OwnerID, mock.Anything).Return(nil, nil)
+	storage.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool { return strings.HasSuffix(key, ".mp4") }), mock.Anything, int64(len(mp4))).Return(nil)
+
+	var stored *model.MediaAsset
+	assetDB.On("Create", mock.Anything, mock.AnythingOfType("*model.MediaAsset")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*model.MediaAsset) }).
+		Return(nil)
+
+	err := f.domain.ExecuteVideoTask(context.Background(), task.ID)
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, stored) {
+		assert.Equal(t, task.ID, *stored.TaskID)
+		assert.Equal(t, "video/mp4", stored.MimeType)
+		assert.Equal(t, 1280, stored.Width)
+		assert.Equal(t, 5, stored.DurationSeconds)
+	}
+	// The vendor URL must not be kept; it expires.
+	f.taskDB.AssertCalled(t, "TransitionStatus", mock.Anything, task.ID, mock.Anything, model.MediaTaskStatusCompleted, 100,
+		mock.MatchedBy(func(output string) bool {
+			return strings.Contains(output, stored.ID.String()) && !strings.Contains(output, "vendor.example.com")
+		}), "")
+}
+
+func TestDomain_GetVideoStatus_PresignsStoredVideo(t *testing.T) {
+	mockTaskDB := new(MockMediaTaskDB)
+	assetDB := new(MockMediaAssetDB)
+	storage := new(MockStorage)
+	domain := NewDomain(nil, nil, mockTaskDB, nil, nil, nil, nil, assetDB, storage, nil, nil, nil, nil, zap.NewNop())
+
+	userID := uuid.New()
+	asset := &model.MediaAsset{ID: uuid.New(), OwnerID: userID, StorageKey: "media/video.mp4"}
+	task := &model.MediaTask{
+		ID:        uuid.New(),
+		OwnerID:   userID,
+		Type:      "video_generation",
+		Status:    model.MediaTaskStatusCompleted,
+		Output:    common.NewString(`{"asset_id":"` + asset.ID.String() + `","duration":5}`),
+		CreatedAt: time.Now(),
+	}
+
+	mockTaskDB.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+	assetDB.On("FindByID", mock.Anything, asset.ID).Return(asset, nil)
+	storage.On("GetPresignedURL", mock.Anything, "media/video.mp4", time.Hour).Return("https://storage.example.com/video", nil)
+
+	output, err := domain.GetVideoStatus(context.Background(), userID, task.ID.String())
+
+	assert.NoError(t, err)
+	if assert.NotNil(t, output.Video) {
+		assert.Equal(t, "https://storage.example.com/video", output.Video.URL)
+		assert.Equal(t, asset.ID.String(), output.Video.AssetID)
+	}
+}
+
+// libraryFixture wires a domain for asset library tests.
+type libraryFixture struct {
+	domain     *Domain
+	taskDB     *MockMediaTaskDB
+	assetDB    *MockMediaAssetDB
+	teamAccess *MockMediaTeamAccess
+}
+
+func newLibraryFixture() *libraryFixture {
+	f := &libraryFixture{
+		taskDB:     new(MockMediaTaskDB),
+		assetDB:    new(MockMediaAssetDB),
+		teamAccess: new(MockMediaTeamAccess),
+	}
+	f.domain = NewDomain(nil, nil, f.taskDB, nil, nil, nil, nil, f.assetDB, nil, nil, nil, f.teamAccess, nil, zap.NewNop())
+	return f
+}
+
+func TestDomain_ListAssets(t *testing.T) {
+	t.Run("lists own assets with normalized filters", func(t *testing.T) {
+		f := newLibraryFixture()
+		userID := uuid.New()
+		folder := "/campaigns//spring/"
+		assets := []*model.MediaAsset{{ID: uuid.New(), OwnerID: userID}}
+
+		f.assetDB.On("Find", mock.Anything, mock.MatchedBy(func(filter *model.MediaAssetFilter) bool {
+			return filter.OwnerID != nil && *filter.OwnerID == userID &&
+				filter.TeamID == nil &&
+				filter.Type == model.MediaCapabilityImage &&
+				filter.Search == "cat" &&
+				assert.ObjectsAreEqual([]string{"hero", "draft"}, filter.Tags) &&
+				filter.Folder != nil && *filter.Folder == "campaigns/spring" &&
+				filter.Limit == 100
+		})).Return(assets, int64(1), nil)
+
+		output, err := f.domain.ListAssets(context.Background(), userID, &inbound.MediaAssetListInput{
+			Type:   model.MediaCapabilityImage,
+			Query:  " cat ",
+			Tags:   []string{"Hero", "draft", "hero", " "},
+			Folder: &folder,
+			Limit:  500,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), output.Total)
+		assert.Equal(t, assets, output.Assets)
+		assert.Equal(t, 100, output.Limit)
+	})
+
+	t.Run("lists team assets for members", func(t *testing.T) {
+		f := newLibraryFixture()
+		userID, teamID := uuid.New(), uuid.New()
+
+		f.teamAccess.On("GetTeamRole", mock.Anything, teamID, userID).Return(model.TeamRoleGuest, nil)
+		f.assetDB.On("Find", mock.Anything, mock.MatchedBy(func(filter *model.MediaAssetFilter) bool {
+			return filter.OwnerID == nil && filter.TeamID != nil && *filter.TeamID == teamID
+		})).Return([]*model.MediaAsset{}, int64(0), nil)
+
+		_, err := f.domain.ListAssets(context.Background(), userID, &inbound.MediaAssetListInput{TeamID: &teamID})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects non-members", func(t *testing.T) {
+		f := newLibraryFixture()
+		userID, teamID := uuid.New(), uuid.New()
+
+		f.teamAccess.On("GetTeamRole", mock.Anything, teamID, userID).Return(model.TeamRole(""), nil)
+
+		output, err := f.domain.ListAssets(context.Background(), userID, &inbound.MediaAssetListInput{TeamID: &teamID})
+
+		assert.ErrorIs(t, err, ErrAssetAccessDenied)
+		assert.Nil(t, output)
+		f.assetDB.AssertNotCalled(t, "Find", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects relative folders", func(t *testing.T) {
+		f := newLibraryFixture()
+		folder := "a/../b"
+
+		_, err := f.domain.ListAssets(context.Background(), uuid.New(), &inbound.MediaAssetListInput{Folder: &folder})
+
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
+
+func TestDomain_GetAsset(t *testing.T) {
+	t.Run("includes producing task", func(t *testing.T) {
+		f := newLibraryFixture()
+		userID := uuid.New()
+		task := &model.MediaTask{ID: uuid.New(), OwnerID: userID, Type: TaskTypeImage.String()}
+		asset := &model.MediaAsset{ID: uuid.New(), OwnerID: userID, TaskID: &task.ID}
+
+		f.assetDB.On("FindByID", mock.Anything, asset.ID).Return(asset, nil)
+		f.taskDB.On("FindByID", mock.Anything, task.ID).Return(task, nil)
+
+		got, err := f.domain.GetAsset(context.Background(), userID, asset.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, task, got.Task)
+	})
+
+	t.Run("hides unshared assets of other users", func(t *testing.T) {
+		f := newLibraryFixture()
+		asset := &model.MediaAsset{ID: uuid.New(), OwnerID: uuid.New()}
+
+		f.assetDB.On("FindByID", mock.Anything, asset.ID).Return(asset, nil)
+
+		_, err := f.domain.GetAsset(context.Background(), uuid.New(), asset.ID)
+
+		assert.ErrorIs(t, err, ErrAssetNotFound)
+	})
+}
+
+func TestDomain_UpdateAsset(t *testing.T) {
+	t.Run("team member renames, tags and moves", func(t *testing.T) {
+		f := newLibraryFixture()
+		userID, teamID := uuid.New(), uuid.New()
+		asset := &model.MediaAsset{ID: uuid.New(), OwnerID: uuid.New(), TeamID: &teamID}
+		name, folder := "  Hero shot ", "brand/hero/"
+		tags := []string{"Final"}
+
+		f.assetDB.On("FindByID", mock.Anything, asset.ID).Return(asset, nil)
+		f.teamAccess.On("GetTeamRole", mock.Anything, teamID, userID).Return(model.TeamRoleMember, nil)
+		f.assetDB.On("Update", mock.Anything, asset).Return(nil)
+
+		got, err := f.domain.UpdateAsset(context.Background(), userID, asset.ID, &inbound.MediaAssetUpdateInput{
+			Name:   &name,
+			Folder: &folder,
+			Tags:   &tags,
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Hero shot", got.Name)
+		assert.Equal(t, "brand/hero", got.Folder)
+		assert.Equal(t, []string{"final"}, []string(got.Tags))
+	})
+
+	t.Run("team guest cannot edit", func(t *testing.T) {
+		f := newLibraryFixture()
+		userID, teamID := uuid.New(), uuid.New()
+		asset := &model.MediaAsset{ID: uuid.New(), OwnerID: uuid.New(), TeamID: &teamID}
+		name := "renamed"
+
+		f.assetDB.On("FindByID", mock.Anything, asset.ID).Return(asset, nil)
+		f.teamAccess.On("GetTeamRole", mock.Anything, teamID, userID).Return(model.TeamRoleGuest, nil)
+
+		_, err := f.domain.UpdateAsset(context.Background(), userID, asset.ID, &inbound.MediaAssetUpdateInput{Name: &name})
+
+		assert.ErrorIs(t, err, ErrAssetAccessDenied)
+		f.assetDB.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("too many tags", func(t *testing.T) {
+		f := newLibraryFixture()
+		userID := uuid.New()
+		asset := &model.MediaAsset{ID: uuid.New(), OwnerID: userID}
+		tags := make([]string, maxAssetTags+1)
+		for i := range tags {
+			tags[i] = fmt.Sprintf("tag-%d", i)
+		}
+
+		f.assetDB.On("FindByID", mock.Anything, asset.ID).Return(asset, nil)
+
+		_, err := f.domain.UpdateAsset(context.Background(), userID, asset.ID, &inbound.MediaAssetUpdateInput{Tags: &tags})
+
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
+
+func TestDomain_ShareAsset(t *testing.T) {
+	t.Run("owner shares with a team they belong to", func(t *testing.T) {
+		f := newLibraryFixture()
+		userID, teamID := uuid.New(), uuid.New()
+		asset := &model.MediaAsset{ID: uuid.New(), OwnerID: userID}
+
+		f.assetDB.On("FindByID", mock.Anything, asset.ID).Return(asset, nil)
+		f.teamAccess.On("GetTeamRole", mock.Anything, teamID, userID).Return(model.TeamRoleMember, nil)
+		f.assetDB.On("Update", mock.Anything, asset).Return(nil)
+
+		got, err := f.domain.ShareAsset(context.Background(), userID, asset.ID, &teamID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &teamID, got.TeamID)
+	})
+
+	t.Run("guests cannot share into a team", func(t *testing.T) {
+		f := newLibraryFixture()
+		userID, teamID := uuid.New(), uuid.New()
+		asset := &model.MediaAsset{ID: uuid.New(), OwnerID: userID}
+
+		f.assetDB.On("FindByID", mock.Anything, asset.ID).Return(asset, nil)
+		f.teamAccess.On("GetTeamRole", mock.Anything, teamID, userID).Return(model.TeamRoleGuest, nil)
+
+		_, err := f.domain.ShareAsset(context.Background(), userID, asset.ID, &teamID)
+
+		assert.ErrorIs(t, err, ErrAssetAccessDenied)
+	})
+
+	t.Run("team admin cannot unshare someone else's asset", func(t *testing.T) {
+		f := newLibraryFixture()
+		userID, teamID := uuid.New(), uuid.New()
+		asset := &model.MediaAsset{ID: uuid.New(), OwnerID: uuid.New(), TeamID: &teamID}
+
+		f.assetDB.On("FindByID", mock.Anything, asset.ID).Return(asset, nil)
+		f.teamAccess.On("GetTeamRole", mock.Anything, teamID, userID).Return(model.TeamRoleAdmin, nil)
+
+		_, err := f.domain.ShareAsset(context.Background(), userID, asset.ID, nil)
+
+		assert.ErrorIs(t, err, ErrAssetAccessDenied)
+	})
+}
+
+func TestDomain_DeleteAndRestoreAsset(t *testing.T) {
+	f := newLibraryFixture()
+	userID := uuid.New()
+	asset := &model.MediaAsset{ID: uuid.New(), OwnerID: userID}
+
+	f.assetDB.On("FindByID", mock.Anything, asset.ID).Return(asset, nil)
+	f.assetDB.On("Update", mock.Anything, asset).Return(nil)
+
+	_, err := f.domain.RestoreAsset(context.Background(), userID, asset.ID)
+	assert.ErrorIs(t, err, ErrAssetNotDeleted)
+
+	assert.NoError(t, f.domain.DeleteAsset(context.Background(), userID, asset.ID))
+	assert.True(t, asset.IsDeleted())
+
+	// Deleting again is a no-op.
+	assert.NoError(t, f.domain.DeleteAsset(context.Background(), userID, asset.ID))
+	f.assetDB.AssertNumberOfCalls(t, "Update", 1)
+
+	restored, err := f.domain.RestoreAsset(context.Background(), userID, asset.ID)
+	assert.NoError(t, err)
+	assert.False(t, restored.IsDeleted())
+}
+
+func TestDomain_EditImage(t *testing.T) {
+	t.Run("edits upload with stored mask", func(t *testing.T) {
+		f := newImageAssetFixture()
+		f.mediaModel.Capabilities = append(f.mediaModel.Capabilities, model.MediaCapabilityImageEdit)
+		userID := uuid.New()
+		upload := testPNG(t, 16, 16)
+		mask := testPNG(t, 16, 8)
+		maskAsset := &model.MediaAsset{ID: uuid.New(), OwnerID: userID, Type: model.MediaCapabilityImage, StorageKey: "media/mask.png"}
+		existing := &model.MediaAsset{ID: uuid.New(), OwnerID: userID, StorageKey: "media/result.png"}
+
+		f.assetDB.On("FindByID", mock.Anything, maskAsset.ID).Return(maskAsset, nil)
+		f.storage.On("Get", mock.Anything, "media/mask.png").Return(io.NopCloser(bytes.NewReader(mask)), nil)
+		f.quota.On("GetMediaStorageLimit", mock.Anything, userID).Return(int64(-1), nil)
+		f.adapter.On("EditImage", mock.Anything, mock.MatchedBy(func(req *model.ImageEditRequest) bool {
+			return bytes.Equal(req.Image, upload) && bytes.Equal(req.Mask, mask) && req.Prompt == "add a hat"
+		}), f.mediaModel, f.provider, "sk-test-key").
+			Return(&model.ImageResponse{
+				Images: []*model.GeneratedImage{{B64JSON: base64.StdEncoding.EncodeToString(testPNG(t, 16, 16))}},
+			}, nil)
+		f.assetDB.On("FindByOwnerAndHash", mock.Anything, userID, mock.Anything).Return(existing, nil)
+		f.storage.On("GetPresignedURL", mock.Anything, "media/result.png", time.Hour).Return("https://storage.example.com/result", nil)
+
+		output, err := f.domain.EditImage(context.Background(), userID, &inbound.MediaImageEditInput{
+			Prompt:      "add a hat",
+			Model:       "image-model",
+			Image:       upload,
+			MaskAssetID: maskAsset.ID.String(),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, existing.ID.String(), output.Images[0].AssetID)
+		assert.NotEmpty(t, output.TaskID)
+		f.taskDB.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(task *model.MediaTask) bool {
+			return task.Type == TaskTypeImageEdit.String()
+		}))
+	})
+
+	t.Run("model without edit capability", func(t *testing.T) {
+		f := newImageAssetFixture()
+
+		output, err := f.domain.EditImage(context.Background(), uuid.New(), &inbound.MediaImageEditInput{
+			Prompt: "add a hat",
+			Model:  "image-model",
+			Image:  testPNG(t, 8, 8),
+		})
+
+		assert.ErrorIs(t, err, ErrCapabilityNotSupported)
+		assert.Nil(t, output)
+	})
+
+	t.Run("rejects non-image upload", func(t *testing.T) {
+		f := newImageAssetFixture()
+
+		_, err := f.domain.EditImage(context.Background(), uuid.New(), &inbound.MediaImageEditInput{
+			Prompt: "add a hat",
+			Image:  []byte("not an image"),
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+
+	t.Run("hides other users' assets", func(t *testing.T) {
+		f := newImageAssetFixture()
+		asset := &model.MediaAsset{ID: uuid.New(), OwnerID: uuid.New(), Type: model.MediaCapabilityImage}
+
+		f.assetDB.On("FindByID", mock.Anything, asset.ID).Return(asset, nil)
+
+		_, err := f.domain.EditImage(context.Background(), uuid.New(), &inbound.MediaImageEditInput{
+			Prompt:       "add a hat",
+			ImageAssetID: asset.ID.String(),
+		})
+
+		assert.ErrorIs(t, err, ErrAssetNotFound)
+		f.storage.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDomain_CreateImageVariation(t *testing.T) {
+	f := newImageAssetFixture()
+	f.mediaModel.Capabilities = append(f.mediaModel.Capabilities, model.MediaCapabilityImageVariation)
+	userID := uuid.New()
+	upload := testPNG(t, 16, 16)
+	existing := &model.MediaAsset{ID: uuid.New(), OwnerID: userID, StorageKey: "media/variation.png"}
+
+	f.quota.On("GetMediaStorageLimit", mock.Anything, userID).Return(int64(-1), nil)
+	f.adapter.On("CreateImageVariation", mock.Anything, mock.MatchedBy(func(req *model.ImageVariationRequest) bool {
+		return bytes.Equal(req.Image, upload) && req.N == 2
+	}), f.mediaModel, f.provider, "sk-test-key").
+		Return(&model.ImageResponse{
+			Images: []*model.GeneratedImage{{B64JSON: base64.StdEncoding.EncodeToString(testPNG(t, 16, 16))}},
+		}, nil)
+	f.assetDB.On("FindByOwnerAndHash", mock.Anything, userID, mock.Anything).Return(existing, nil)
+	f.storage.On("GetPresignedURL", mock.Anything, "media/variation.png", time.Hour).Return("https://storage.example.com/variation", nil)
+
+	output, err := f.domain.CreateImageVariation(context.Background(), userID, &inbound.MediaImageVariationInput{
+		Model: "image-model",
+		N:     2,
+		Image: upload,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://storage.example.com/variation", output.Images[0].URL)
+}
+
+func TestDomain_UpscaleImage_InvalidScale(t *testing.T) {
+	f := newImageAssetFixture()
+
+	_, err := f.domain.UpscaleImage(context.Background(), uuid.New(), &inbound.MediaImageUpscaleInput{
+		Scale: 3,
+		Image: testPNG(t, 8, 8),
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
@@ -35,4 +35,19 @@ var (
 
 	// ErrTaskAlreadyCancelled is returned when trying to cancel a cancelled task.
 	ErrTaskAlreadyCancelled = errors.New("task already cancelled")
+
+	// ErrVideoQuotaExceeded is returned when the owner's plan has no video minutes left.
+	ErrVideoQuotaExceeded = errors.New("monthly video minutes exceeded")
+
+	// ErrStorageQuotaExceeded is returned when storing an asset would exceed the plan's media storage.
+	ErrStorageQuotaExceeded = errors.New("media storage quota exceeded")
+
+	// ErrAssetNotFound is returned when an asset is not found.
+	ErrAssetNotFound = errors.New("media asset not found")
+
+	// ErrAssetAccessDenied is returned when the user's team role doesn't allow the change.
+	ErrAssetAccessDenied = errors.New("media asset access denied")
+
+	// ErrAssetNotDeleted is returned when restoring an asset that isn't in the trash.
+	ErrAssetNotDeleted = errors.New("media asset is not deleted")
 )
@@ -0,0 +1,303 @@
+package media
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+	"github.com/uniedit/server/internal/port/outbound"
+)
+
+// imageOperation is a synchronous image request against a vendor adapter.
+type imageOperation struct {
+	name            string // used in errors and logs
+	capability      model.MediaCapability
+	taskType        TaskType
+	modelID         string
+	preferredModels []string
+	count           int // expected number of images, for cost ranking
+	prompt          string
+	responseFormat  string
+	input           any // recorded on the task
+	run             func(adapter outbound.MediaVendorAdapterPort, mediaModel *model.MediaModel, provider *model.MediaProvider, apiKey string) (*model.ImageResponse, error)
+}
+
+// EditImage edits an image from a prompt, optionally within a mask.
+func (d *Domain) EditImage(ctx context.Context, userID uuid.UUID, input *inbound.MediaImageEditInput) (*inbound.MediaImageGenerationOutput, error) {
+	if input.Prompt == "" {
+		return nil, ErrInvalidInput
+	}
+
+	image, err := d.loadInputImage(ctx, userID, input.Image, input.ImageAssetID)
+	if err != nil {
+		return nil, err
+	}
+	var mask []byte
+	if len(input.Mask) > 0 || input.MaskAssetID != "" {
+		if mask, err = d.loadInputImage(ctx, userID, input.Mask, input.MaskAssetID); err != nil {
+			return nil, err
+		}
+	}
+
+	return d.runImageOperation(ctx, userID, &imageOperation{
+		name:            "edit image",
+		capability:      model.MediaCapabilityImageEdit,
+		taskType:        TaskTypeImageEdit,
+		modelID:         input.Model,
+		preferredModels: input.PreferredModels,
+		count:           imageCount(input.N),
+		prompt:          input.Prompt,
+		responseFormat:  input.ResponseFormat,
+		input:           input,
+		run: func(adapter outbound.MediaVendorAdapterPort, mediaModel *model.MediaModel, provider *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+			return adapter.EditImage(ctx, &model.ImageEditRequest{
+				Image:          image,
+				Mask:           mask,
+				Prompt:         input.Prompt,
+				N:              input.N,
+				Size:           input.Size,
+				ResponseFormat: input.ResponseFormat,
+				Model:          mediaModel.ID,
+			}, mediaModel, provider, apiKey)
+		},
+	})
+}
+
+// CreateImageVariation generates variations of an image.
+func (d *Domain) CreateImageVariation(ctx context.Context, userID uuid.UUID, input *inbound.MediaImageVariationInput) (*inbound.MediaImageGenerationOutput, error) {
+	image, err := d.loadInputImage(ctx, userID, input.Image, input.ImageAssetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.runImageOperation(ctx, userID, &imageOperation{
+		name:            "create image variation",
+		capability:      model.MediaCapabilityImageVariation,
+		taskType:        TaskTypeImageVariation,
+		modelID:         input.Model,
+		preferredModels: input.PreferredModels,
+		count:           imageCount(input.N),
+		responseFormat:  input.ResponseFormat,
+		input:           input,
+		run: func(adapter outbound.MediaVendorAdapterPort, mediaModel *model.MediaModel, provider *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+			return adapter.CreateImageVariation(ctx, &model.ImageVariationRequest{
+				Image:          image,
+				N:              input.N,
+				Size:           input.Size,
+				ResponseFormat: input.ResponseFormat,
+				Model:          mediaModel.ID,
+			}, mediaModel, provider, apiKey)
+		},
+	})
+}
+
+// UpscaleImage increases the resolution of an image by 2x (default) or 4x.
+func (d *Domain) UpscaleImage(ctx context.Context, userID uuid.UUID, input *inbound.MediaImageUpscaleInput) (*inbound.MediaImageGenerationOutput, error) {
+	if input.Scale == 0 {
+		input.Scale = 2
+	}
+	if input.Scale != 2 && input.Scale != 4 {
+		return nil, ErrInvalidInput
+	}
+
+	image, err := d.loadInputImage(ctx, userID, input.Image, input.ImageAssetID)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.runImageOperation(ctx, userID, &imageOperation{
+		name:            "upscale image",
+		capability:      model.MediaCapabilityImageUpscale,
+		taskType:        TaskTypeImageUpscale,
+		modelID:         input.Model,
+		preferredModels: input.PreferredModels,
+		count:           1,
+		responseFormat:  input.ResponseFormat,
+		input:           input,
+		run: func(adapter outbound.MediaVendorAdapterPort, mediaModel *model.MediaModel, provider *model.MediaProvider, apiKey string) (*model.ImageResponse, error) {
+			return adapter.UpscaleImage(ctx, &model.ImageUpscaleRequest{
+				Image:          image,
+				Scale:          input.Scale,
+				ResponseFormat: input.ResponseFormat,
+				Model:          mediaModel.ID,
+			}, mediaModel, provider, apiKey)
+		},
+	})
+}
+
+// runImageOperation routes the operation to the best model for its
+// capability, falling back to the next one when a provider fails, and stores
+// the resulting images.
+func (d *Domain) runImageOperation(ctx context.Context, userID uuid.UUID, op *imageOperation) (*inbound.MediaImageGenerationOutput, error) {
+	candidates, err := d.routeModels(ctx, op.modelID, &model.MediaRoutingContext{
+		Capability:      op.capability,
+		Units:           float64(op.count),
+		PreferredModels: op.preferredModels,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Don't pay for images the user has no room to keep
+	if d.assetsEnabled() {
+		if err := d.checkStorageQuota(ctx, userID, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	start := time.Now()
+	var resp *model.ImageResponse
+	chosen, failed, err := d.tryCandidates(ctx, candidates, func(candidate *model.MediaScoredCandidate, adapter outbound.MediaVendorAdapterPort, apiKey string) error {
+		var err error
+		resp, err = op.run(adapter, candidate.Model, candidate.Provider, apiKey)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op.name, err)
+	}
+	mediaModel := chosen.Model
+
+	d.logger.Info("Image generated",
+		zap.String("operation", op.name),
+		zap.String("user_id", userID.String()),
+		zap.String("model", mediaModel.ID),
+		zap.Int("count", len(resp.Images)),
+	)
+
+	output := &inbound.MediaImageGenerationOutput{
+		Images:    resp.Images,
+		Model:     resp.Model,
+		Usage:     resp.Usage,
+		CreatedAt: resp.CreatedAt,
+		Routing: &model.MediaRoutingInfo{
+			ProviderUsed: chosen.Provider.Name,
+			ModelUsed:    mediaModel.ID,
+			Reason:       strings.Join(chosen.Reasons, "; "),
+			FallbackFrom: failed,
+			LatencyMs:    time.Since(start).Milliseconds(),
+			CostUSD:      mediaModel.CostPerImage * float64(len(resp.Images)),
+		},
+	}
+
+	if d.assetsEnabled() {
+		task, err := d.recordImageTask(ctx, userID, op, mediaModel, resp.Images)
+		if err != nil {
+			return nil, err
+		}
+		output.TaskID = task.ID.String()
+	}
+
+	return output, nil
+}
+
+// imageCount returns the number of images a request asks for.
+func imageCount(n int) int {
+	if n <= 0 {
+		return 1
+	}
+	return n
+}
+
+// recordImageTask stores generated images as assets linked to a completed
+// task, so the library can trace them back to their request.
+func (d *Domain) recordImageTask(ctx context.Context, userID uuid.UUID, op *imageOperation, mediaModel *model.MediaModel, images []*model.GeneratedImage) (*model.MediaTask, error) {
+	inputBytes, err := json.Marshal(op.input)
+	if err != nil {
+		return nil, fmt.Errorf("marshal input: %w", err)
+	}
+
+	now := time.Now()
+	inputStr := string(inputBytes)
+	task := &model.MediaTask{
+		ID:        uuid.New(),
+		OwnerID:   userID,
+		Type:      op.taskType.String(),
+		Status:    model.MediaTaskStatusRunning,
+		Input:     &inputStr,
+		ModelID:   mediaModel.ID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := d.taskDB.Create(ctx, task); err != nil {
+		return nil, fmt.Errorf("create task: %w", err)
+	}
+
+	if err := d.persistImages(ctx, task, mediaModel, op.prompt, images, op.responseFormat == "b64_json"); err != nil {
+		if updateErr := d.taskDB.UpdateStatus(ctx, task.ID, model.MediaTaskStatusFailed, 0, "", err.Error()); updateErr != nil {
+			d.logger.Warn("Failed to mark image task failed",
+				zap.String("task_id", task.ID.String()),
+				zap.Error(updateErr),
+			)
+		}
+		return nil, err
+	}
+
+	// Keep only asset references; URLs are presigned on read.
+	stored := make([]model.GeneratedImage, len(images))
+	for i, img := range images {
+		stored[i] = model.GeneratedImage{AssetID: img.AssetID, RevisedPrompt: img.RevisedPrompt}
+	}
+	outputBytes, err := json.Marshal(stored)
+	if err != nil {
+		return nil, fmt.Errorf("marshal output: %w", err)
+	}
+	if err := d.taskDB.UpdateStatus(ctx, task.ID, model.MediaTaskStatusCompleted, 100, string(outputBytes), ""); err != nil {
+		return nil, fmt.Errorf("update task status: %w", err)
+	}
+	return task, nil
+}
+
+// loadInputImage returns an uploaded image, or the content of a stored
+// image asset the user can view.
+func (d *Domain) loadInputImage(ctx context.Context, userID uuid.UUID, data []byte, assetID string) ([]byte, error) {
+	if len(data) == 0 {
+		if assetID == "" || !d.assetsEnabled() {
+			return nil, ErrInvalidInput
+		}
+		id, err := uuid.Parse(assetID)
+		if err != nil {
+			return nil, ErrInvalidInput
+		}
+
+		asset, err := d.loadAsset(ctx, userID, id, assetAccessView)
+		if err != nil {
+			return nil, err
+		}
+		if asset.IsDeleted() {
+			return nil, ErrAssetNotFound
+		}
+		if asset.Type != model.MediaCapabilityImage {
+			return nil, ErrInvalidInput
+		}
+
+		reader, err := d.storage.Get(ctx, asset.StorageKey)
+		if err != nil {
+			return nil, fmt.Errorf("read asset: %w", err)
+		}
+		defer reader.Close()
+
+		data, err = io.ReadAll(io.LimitReader(reader, d.config.MaxInputImageBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("read asset: %w", err)
+		}
+	}
+
+	if int64(len(data)) > d.config.MaxInputImageBytes {
+		return nil, ErrInvalidInput
+	}
+	switch http.DetectContentType(data) {
+	case "image/png", "image/jpeg", "image/webp":
+		return data, nil
+	default:
+		return nil, ErrInvalidInput
+	}
+}
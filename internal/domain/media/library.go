@@ -0,0 +1,384 @@
+package media
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+)
+
+// Asset metadata limits.
+const (
+	maxAssetNameLength   = 255
+	maxAssetFolderLength = 1024
+	maxAssetTags         = 20
+	maxAssetTagLength    = 50
+)
+
+// assetAccess is what a user may do with an asset. Levels are cumulative.
+type assetAccess int
+
+const (
+	assetAccessNone   assetAccess = iota
+	assetAccessView               // team guests
+	assetAccessEdit               // team members: rename, tag, move
+	assetAccessManage             // team admins: delete and restore
+	assetAccessOwner              // the asset's owner: share and unshare
+)
+
+// ListAssets lists the user's assets, or a team's shared assets.
+func (d *Domain) ListAssets(ctx context.Context, userID uuid.UUID, input *inbound.MediaAssetListInput) (*inbound.MediaAssetListOutput, error) {
+	if input.Limit <= 0 {
+		input.Limit = 20
+	}
+	if input.Limit > 100 {
+		input.Limit = 100
+	}
+	if input.Offset < 0 {
+		input.Offset = 0
+	}
+
+	filter := &model.MediaAssetFilter{
+		Type:    input.Type,
+		ModelID: input.Model,
+		Search:  strings.TrimSpace(input.Query),
+		Since:   input.Since,
+		Until:   input.Until,
+		Deleted: input.Deleted,
+		Limit:   input.Limit,
+		Offset:  input.Offset,
+	}
+
+	if input.TeamID != nil {
+		role, err := d.teamRole(ctx, *input.TeamID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if role == "" {
+			return nil, ErrAssetAccessDenied
+		}
+		filter.TeamID = input.TeamID
+	} else {
+		filter.OwnerID = &userID
+	}
+
+	if len(input.Tags) > 0 {
+		tags, err := normalizeTags(input.Tags)
+		if err != nil {
+			return nil, err
+		}
+		filter.Tags = tags
+	}
+	if input.Folder != nil {
+		folder, err := normalizeFolder(*input.Folder)
+		if err != nil {
+			return nil, err
+		}
+		filter.Folder = &folder
+	}
+
+	assets, total, err := d.assetDB.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("find assets: %w", err)
+	}
+	for _, asset := range assets {
+		if err := d.presignAsset(ctx, asset); err != nil {
+			return nil, err
+		}
+	}
+
+	return &inbound.MediaAssetListOutput{
+		Assets: assets,
+		Total:  total,
+		Limit:  input.Limit,
+		Offset: input.Offset,
+	}, nil
+}
+
+// GetAsset returns an asset with the task that produced it.
+func (d *Domain) GetAsset(ctx context.Context, userID uuid.UUID, assetID uuid.UUID) (*model.MediaAsset, error) {
+	asset, err := d.loadAsset(ctx, userID, assetID, assetAccessView)
+	if err != nil {
+		return nil, err
+	}
+
+	if asset.TaskID != nil {
+		task, err := d.taskDB.FindByID(ctx, *asset.TaskID)
+		if err != nil {
+			d.logger.Warn("Failed to load asset task",
+				zap.String("asset_id", asset.ID.String()),
+				zap.Error(err),
+			)
+		}
+		asset.Task = task
+	}
+
+	if err := d.presignAsset(ctx, asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// UpdateAsset renames, tags or moves an asset.
+func (d *Domain) UpdateAsset(ctx context.Context, userID uuid.UUID, assetID uuid.UUID, input *inbound.MediaAssetUpdateInput) (*model.MediaAsset, error) {
+	asset, err := d.loadAsset(ctx, userID, assetID, assetAccessEdit)
+	if err != nil {
+		return nil, err
+	}
+	if asset.IsDeleted() {
+		return nil, ErrAssetNotFound
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if utf8.RuneCountInString(name) > maxAssetNameLength {
+			return nil, ErrInvalidInput
+		}
+		asset.Name = name
+	}
+	if input.Folder != nil {
+		folder, err := normalizeFolder(*input.Folder)
+		if err != nil {
+			return nil, err
+		}
+		asset.Folder = folder
+	}
+	if input.Tags != nil {
+		tags, err := normalizeTags(*input.Tags)
+		if err != nil {
+			return nil, err
+		}
+		asset.Tags = tags
+	}
+
+	asset.UpdatedAt = time.Now()
+	if err := d.assetDB.Update(ctx, asset); err != nil {
+		return nil, fmt.Errorf("update asset: %w", err)
+	}
+
+	if err := d.presignAsset(ctx, asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// ShareAsset shares an asset with a team, or unshares it when teamID is nil.
+// Only the owner can share, and only with teams they can contribute to.
+func (d *Domain) ShareAsset(ctx context.Context, userID uuid.UUID, assetID uuid.UUID, teamID *uuid.UUID) (*model.MediaAsset, error) {
+	asset, err := d.loadAsset(ctx, userID, assetID, assetAccessOwner)
+	if err != nil {
+		return nil, err
+	}
+	if asset.IsDeleted() {
+		return nil, ErrAssetNotFound
+	}
+
+	if teamID != nil {
+		role, err := d.teamRole(ctx, *teamID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if roleAssetAccess(role) < assetAccessEdit {
+			return nil, ErrAssetAccessDenied
+		}
+	}
+
+	asset.TeamID = teamID
+	asset.UpdatedAt = time.Now()
+	if err := d.assetDB.Update(ctx, asset); err != nil {
+		return nil, fmt.Errorf("update asset: %w", err)
+	}
+
+	if err := d.presignAsset(ctx, asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// DeleteAsset moves an asset to the trash. The stored file is kept, and
+// still counts towards the owner's storage, until the asset is purged.
+func (d *Domain) DeleteAsset(ctx context.Context, userID uuid.UUID, assetID uuid.UUID) error {
+	asset, err := d.loadAsset(ctx, userID, assetID, assetAccessManage)
+	if err != nil {
+		return err
+	}
+	if asset.IsDeleted() {
+		return nil
+	}
+
+	now := time.Now()
+	asset.DeletedAt = &now
+	asset.UpdatedAt = now
+	if err := d.assetDB.Update(ctx, asset); err != nil {
+		return fmt.Errorf("update asset: %w", err)
+	}
+
+	d.logger.Info("Media asset deleted",
+		zap.String("asset_id", asset.ID.String()),
+		zap.String("user_id", userID.String()),
+	)
+	return nil
+}
+
+// RestoreAsset restores an asset from the trash.
+func (d *Domain) RestoreAsset(ctx context.Context, userID uuid.UUID, assetID uuid.UUID) (*model.MediaAsset, error) {
+	asset, err := d.loadAsset(ctx, userID, assetID, assetAccessManage)
+	if err != nil {
+		return nil, err
+	}
+	if !asset.IsDeleted() {
+		return nil, ErrAssetNotDeleted
+	}
+
+	asset.DeletedAt = nil
+	asset.UpdatedAt = time.Now()
+	if err := d.assetDB.Update(ctx, asset); err != nil {
+		return nil, fmt.Errorf("update asset: %w", err)
+	}
+
+	if err := d.presignAsset(ctx, asset); err != nil {
+		return nil, err
+	}
+	return asset, nil
+}
+
+// loadAsset finds an asset the user may act on at the given level. Assets
+// the user cannot see at all are reported as not found.
+func (d *Domain) loadAsset(ctx context.Context, userID, assetID uuid.UUID, need assetAccess) (*model.MediaAsset, error) {
+	asset, err := d.assetDB.FindByID(ctx, assetID)
+	if err != nil {
+		return nil, fmt.Errorf("find asset: %w", err)
+	}
+	if asset == nil {
+		return nil, ErrAssetNotFound
+	}
+
+	access, err := d.assetAccess(ctx, userID, asset)
+	if err != nil {
+		return nil, err
+	}
+	if access == assetAccessNone {
+		return nil, ErrAssetNotFound
+	}
+	if access < need {
+		return nil, ErrAssetAccessDenied
+	}
+	return asset, nil
+}
+
+// assetAccess resolves what the user may do with an asset.
+func (d *Domain) assetAccess(ctx context.Context, userID uuid.UUID, asset *model.MediaAsset) (assetAccess, error) {
+	if asset.OwnerID == userID {
+		return assetAccessOwner, nil
+	}
+	if asset.TeamID == nil {
+		return assetAccessNone, nil
+	}
+
+	role, err := d.teamRole(ctx, *asset.TeamID, userID)
+	if err != nil {
+		return assetAccessNone, err
+	}
+	return roleAssetAccess(role), nil
+}
+
+// teamRole returns the user's role in a team, or an empty role if team
+// sharing is unavailable.
+func (d *Domain) teamRole(ctx context.Context, teamID, userID uuid.UUID) (model.TeamRole, error) {
+	if d.teamAccess == nil {
+		return "", nil
+	}
+	role, err := d.teamAccess.GetTeamRole(ctx, teamID, userID)
+	if err != nil {
+		return "", fmt.Errorf("get team role: %w", err)
+	}
+	return role, nil
+}
+
+// roleAssetAccess maps a team role to its access on the team's assets.
+func roleAssetAccess(role model.TeamRole) assetAccess {
+	switch role {
+	case model.TeamRoleOwner, model.TeamRoleAdmin:
+		return assetAccessManage
+	case model.TeamRoleMember:
+		return assetAccessEdit
+	case model.TeamRoleGuest:
+		return assetAccessView
+	default:
+		return assetAccessNone
+	}
+}
+
+// presignAsset fills in the asset's download URL when storage is configured.
+func (d *Domain) presignAsset(ctx context.Context, asset *model.MediaAsset) error {
+	if d.storage == nil {
+		return nil
+	}
+	url, err := d.assetURL(ctx, asset)
+	if err != nil {
+		return err
+	}
+	asset.URL = url
+	return nil
+}
+
+// normalizeFolder cleans a slash-separated folder path. Empty segments are
+// dropped; relative segments are rejected.
+func normalizeFolder(folder string) (string, error) {
+	var segments []string
+	for _, segment := range strings.Split(folder, "/") {
+		segment = strings.TrimSpace(segment)
+		switch segment {
+		case "":
+			continue
+		case ".", "..":
+			return "", ErrInvalidInput
+		}
+		segments = append(segments, segment)
+	}
+
+	cleaned := strings.Join(segments, "/")
+	if len(cleaned) > maxAssetFolderLength {
+		return "", ErrInvalidInput
+	}
+	return cleaned, nil
+}
+
+// normalizeTags lowercases, trims and deduplicates tags.
+func normalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxAssetTagLength {
+			return nil, ErrInvalidInput
+		}
+		seen[tag] = true
+		result = append(result, tag)
+	}
+
+	if len(result) > maxAssetTags {
+		return nil, ErrInvalidInput
+	}
+	return result, nil
+}
+
+// defaultAssetName names a new asset after the start of its prompt.
+func defaultAssetName(prompt string) string {
+	const maxRunes = 80
+	name := strings.Join(strings.Fields(prompt), " ")
+	if utf8.RuneCountInString(name) <= maxRunes {
+		return name
+	}
+	return strings.TrimSpace(string([]rune(name)[:maxRunes])) + "…"
+}
@@ -0,0 +1,305 @@
+package media
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+// Strategy defines the interface for media routing strategies.
+type Strategy interface {
+	// Name returns the strategy name.
+	Name() string
+
+	// Priority returns the priority (higher = runs first).
+	Priority() int
+
+	// Filter filters candidates.
+	Filter(ctx *model.MediaRoutingContext, candidates []*model.MediaScoredCandidate) []*model.MediaScoredCandidate
+
+	// Score scores candidates.
+	Score(ctx *model.MediaRoutingContext, candidates []*model.MediaScoredCandidate) []*model.MediaScoredCandidate
+}
+
+// BaseStrategy provides common functionality for strategies.
+type BaseStrategy struct {
+	name     string
+	priority int
+}
+
+// NewBaseStrategy creates a new base strategy.
+func NewBaseStrategy(name string, priority int) *BaseStrategy {
+	return &BaseStrategy{
+		name:     name,
+		priority: priority,
+	}
+}
+
+// Name returns the strategy name.
+func (s *BaseStrategy) Name() string {
+	return s.name
+}
+
+// Priority returns the strategy priority.
+func (s *BaseStrategy) Priority() int {
+	return s.priority
+}
+
+// Filter is a no-op filter (override in implementations).
+func (s *BaseStrategy) Filter(ctx *model.MediaRoutingContext, candidates []*model.MediaScoredCandidate) []*model.MediaScoredCandidate {
+	return candidates
+}
+
+// Score is a no-op scorer (override in implementations).
+func (s *BaseStrategy) Score(ctx *model.MediaRoutingContext, candidates []*model.MediaScoredCandidate) []*model.MediaScoredCandidate {
+	return candidates
+}
+
+// StrategyChain executes strategies in priority order.
+type StrategyChain struct {
+	strategies []Strategy
+}
+
+// NewStrategyChain creates a new strategy chain.
+func NewStrategyChain(strategies ...Strategy) *StrategyChain {
+	// Sort by priority (descending)
+	sorted := make([]Strategy, len(strategies))
+	copy(sorted, strategies)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Priority() > sorted[j].Priority()
+	})
+
+	return &StrategyChain{strategies: sorted}
+}
+
+// Rank runs the strategy chain and returns the remaining candidates, best
+// first, so callers can fall back to the next one when a provider fails.
+func (c *StrategyChain) Rank(ctx *model.MediaRoutingContext, candidates []*model.MediaScoredCandidate) ([]*model.MediaScoredCandidate, error) {
+	if len(candidates) == 0 {
+		return nil, ErrModelNotFound
+	}
+
+	result := candidates
+	for _, strategy := range c.strategies {
+		result = strategy.Filter(ctx, result)
+		if len(result) == 0 {
+			return nil, fmt.Errorf("%w: no candidates after %s filter", ErrNoHealthyProvider, strategy.Name())
+		}
+
+		result = strategy.Score(ctx, result)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+
+	return result, nil
+}
+
+// DefaultStrategyChain creates a chain with all default strategies.
+func DefaultStrategyChain() *StrategyChain {
+	return NewStrategyChain(
+		NewUserPreferenceStrategy(),
+		NewHealthFilterStrategy(),
+		NewCapabilityFilterStrategy(),
+		NewCostOptimizationStrategy(),
+		NewLoadBalancingStrategy(),
+	)
+}
+
+// ==================== User Preference Strategy ====================
+
+const (
+	userPreferenceName     = "user_preference"
+	userPreferencePriority = 100
+)
+
+// UserPreferenceStrategy ranks the models the user asked for first. Other
+// models stay in the chain as fallbacks.
+type UserPreferenceStrategy struct {
+	*BaseStrategy
+}
+
+// NewUserPreferenceStrategy creates a new user preference strategy.
+func NewUserPreferenceStrategy() *UserPreferenceStrategy {
+	return &UserPreferenceStrategy{
+		BaseStrategy: NewBaseStrategy(userPreferenceName, userPreferencePriority),
+	}
+}
+
+// Score gives higher scores to preferred models, in preference order.
+func (s *UserPreferenceStrategy) Score(ctx *model.MediaRoutingContext, candidates []*model.MediaScoredCandidate) []*model.MediaScoredCandidate {
+	if len(ctx.PreferredModels) == 0 {
+		return candidates
+	}
+
+	preferenceOrder := make(map[string]int)
+	for i, m := range ctx.PreferredModels {
+		preferenceOrder[m] = len(ctx.PreferredModels) - i
+	}
+
+	for _, c := range candidates {
+		if order, ok := preferenceOrder[c.Model.ID]; ok {
+			// Outweighs any cost difference
+			bonus := float64(order) * 100
+			c.AddScore(userPreferenceName, bonus, "user preference")
+		}
+	}
+
+	return candidates
+}
+
+// ==================== Health Filter Strategy ====================
+
+const (
+	healthFilterName     = "health_filter"
+	healthFilterPriority = 90
+)
+
+// HealthFilterStrategy filters out unhealthy providers.
+type HealthFilterStrategy struct {
+	*BaseStrategy
+}
+
+// NewHealthFilterStrategy creates a new health filter strategy.
+func NewHealthFilterStrategy() *HealthFilterStrategy {
+	return &HealthFilterStrategy{
+		BaseStrategy: NewBaseStrategy(healthFilterName, healthFilterPriority),
+	}
+}
+
+// Filter removes candidates whose provider is known to be unhealthy.
+// Unknown health counts as healthy.
+func (s *HealthFilterStrategy) Filter(ctx *model.MediaRoutingContext, candidates []*model.MediaScoredCandidate) []*model.MediaScoredCandidate {
+	if len(ctx.ProviderHealth) == 0 {
+		return candidates
+	}
+
+	var result []*model.MediaScoredCandidate
+	for _, c := range candidates {
+		if healthy, ok := ctx.ProviderHealth[c.Provider.ID.String()]; !ok || healthy {
+			result = append(result, c)
+		}
+	}
+
+	return result
+}
+
+// ==================== Capability Filter Strategy ====================
+
+const (
+	capabilityFilterName     = "capability_filter"
+	capabilityFilterPriority = 80
+)
+
+// CapabilityFilterStrategy filters out disabled models and models without the
+// requested capability.
+type CapabilityFilterStrategy struct {
+	*BaseStrategy
+}
+
+// NewCapabilityFilterStrategy creates a new capability filter strategy.
+func NewCapabilityFilterStrategy() *CapabilityFilterStrategy {
+	return &CapabilityFilterStrategy{
+		BaseStrategy: NewBaseStrategy(capabilityFilterName, capabilityFilterPriority),
+	}
+}
+
+// Filter removes candidates that can't serve the request.
+func (s *CapabilityFilterStrategy) Filter(ctx *model.MediaRoutingContext, candidates []*model.MediaScoredCandidate) []*model.MediaScoredCandidate {
+	var result []*model.MediaScoredCandidate
+	for _, c := range candidates {
+		if !c.Model.Enabled || !c.Provider.Enabled {
+			continue
+		}
+		if ctx.Capability != "" && !c.Model.HasCapability(ctx.Capability) {
+			continue
+		}
+		result = append(result, c)
+	}
+
+	return result
+}
+
+// ==================== Cost Optimization Strategy ====================
+
+const (
+	costOptimizationName     = "cost_optimization"
+	costOptimizationPriority = 50
+)
+
+// CostOptimizationStrategy scores models based on the expected cost of the
+// request.
+type CostOptimizationStrategy struct {
+	*BaseStrategy
+}
+
+// NewCostOptimizationStrategy creates a new cost optimization strategy.
+func NewCostOptimizationStrategy() *CostOptimizationStrategy {
+	return &CostOptimizationStrategy{
+		BaseStrategy: NewBaseStrategy(costOptimizationName, costOptimizationPriority),
+	}
+}
+
+// Score gives higher scores to cheaper models. Models without a price are
+// left unscored.
+func (s *CostOptimizationStrategy) Score(ctx *model.MediaRoutingContext, candidates []*model.MediaScoredCandidate) []*model.MediaScoredCandidate {
+	units := ctx.Units
+	if units <= 0 {
+		units = 1
+	}
+
+	// Find min cost
+	minCost := float64(-1)
+	for _, c := range candidates {
+		cost := c.Model.UnitCost(ctx.Capability) * units
+		if cost > 0 && (minCost < 0 || cost < minCost) {
+			minCost = cost
+		}
+	}
+
+	if minCost <= 0 {
+		return candidates
+	}
+
+	for _, c := range candidates {
+		cost := c.Model.UnitCost(ctx.Capability) * units
+		if cost <= 0 {
+			continue
+		}
+		bonus := (minCost / cost) * 20
+		c.AddScore(costOptimizationName, bonus, fmt.Sprintf("estimated cost $%.4f", cost))
+	}
+
+	return candidates
+}
+
+// ==================== Load Balancing Strategy ====================
+
+const (
+	loadBalancingName     = "load_balancing"
+	loadBalancingPriority = 10
+)
+
+// LoadBalancingStrategy spreads requests across equally scored models.
+type LoadBalancingStrategy struct {
+	*BaseStrategy
+}
+
+// NewLoadBalancingStrategy creates a new load balancing strategy.
+func NewLoadBalancingStrategy() *LoadBalancingStrategy {
+	return &LoadBalancingStrategy{
+		BaseStrategy: NewBaseStrategy(loadBalancingName, loadBalancingPriority),
+	}
+}
+
+// Score adds a small random jitter to break ties.
+func (s *LoadBalancingStrategy) Score(ctx *model.MediaRoutingContext, candidates []*model.MediaScoredCandidate) []*model.MediaScoredCandidate {
+	for _, c := range candidates {
+		c.AddScore(loadBalancingName, rand.Float64()*0.1, "")
+	}
+
+	return candidates
+}
@@ -0,0 +1,137 @@
+package media
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/uniedit/server/internal/model"
+)
+
+// ===== Test Helpers =====
+
+func newTestCandidate(modelID string, costPerImage, costPerSecond float64, caps ...model.MediaCapability) *model.MediaScoredCandidate {
+	provider := &model.MediaProvider{ID: uuid.New(), Name: modelID + "-provider", Enabled: true}
+	return model.NewMediaScoredCandidate(provider, &model.MediaModel{
+		ID:            modelID,
+		ProviderID:    provider.ID,
+		Capabilities:  caps,
+		CostPerImage:  costPerImage,
+		CostPerSecond: costPerSecond,
+		Enabled:       true,
+	})
+}
+
+func candidateIDs(candidates []*model.MediaScoredCandidate) []string {
+	ids := make([]string, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.Model.ID
+	}
+	return ids
+}
+
+// ===== Strategy Tests =====
+
+func TestHealthFilterStrategy(t *testing.T) {
+	s := NewHealthFilterStrategy()
+	healthy := newTestCandidate("healthy", 0, 0)
+	unhealthy := newTestCandidate("unhealthy", 0, 0)
+	unknown := newTestCandidate("unknown", 0, 0)
+
+	ctx := &model.MediaRoutingContext{ProviderHealth: map[string]bool{
+		healthy.Provider.ID.String():   true,
+		unhealthy.Provider.ID.String(): false,
+	}}
+	result := s.Filter(ctx, []*model.MediaScoredCandidate{healthy, unhealthy, unknown})
+
+	assert.Equal(t, []string{"healthy", "unknown"}, candidateIDs(result))
+}
+
+func TestCapabilityFilterStrategy(t *testing.T) {
+	s := NewCapabilityFilterStrategy()
+	image := newTestCandidate("image", 0, 0, model.MediaCapabilityImage)
+	video := newTestCandidate("video", 0, 0, model.MediaCapabilityVideo)
+	disabled := newTestCandidate("disabled", 0, 0, model.MediaCapabilityImage)
+	disabled.Model.Enabled = false
+
+	ctx := &model.MediaRoutingContext{Capability: model.MediaCapabilityImage}
+	result := s.Filter(ctx, []*model.MediaScoredCandidate{image, video, disabled})
+
+	assert.Equal(t, []string{"image"}, candidateIDs(result))
+}
+
+func TestCostOptimizationStrategy(t *testing.T) {
+	s := NewCostOptimizationStrategy()
+
+	t.Run("image cost", func(t *testing.T) {
+		cheap := newTestCandidate("cheap", 0.01, 0)
+		pricey := newTestCandidate("pricey", 0.04, 0)
+		unpriced := newTestCandidate("unpriced", 0, 0)
+
+		ctx := &model.MediaRoutingContext{Capability: model.MediaCapabilityImage, Units: 2}
+		s.Score(ctx, []*model.MediaScoredCandidate{cheap, pricey, unpriced})
+
+		assert.InDelta(t, 20, cheap.Score, 1e-9)
+		assert.InDelta(t, 5, pricey.Score, 1e-9)
+		assert.Zero(t, unpriced.Score)
+		assert.Contains(t, cheap.Reasons, "estimated cost $0.0200")
+	})
+
+	t.Run("video cost uses seconds", func(t *testing.T) {
+		cheap := newTestCandidate("cheap", 1, 0.05)
+		pricey := newTestCandidate("pricey", 0.01, 0.1)
+
+		ctx := &model.MediaRoutingContext{Capability: model.MediaCapabilityVideo, Units: 10}
+		s.Score(ctx, []*model.MediaScoredCandidate{cheap, pricey})
+
+		assert.Greater(t, cheap.Score, pricey.Score)
+	})
+}
+
+func TestUserPreferenceStrategy(t *testing.T) {
+	s := NewUserPreferenceStrategy()
+	first := newTestCandidate("first", 0, 0)
+	second := newTestCandidate("second", 0, 0)
+	other := newTestCandidate("other", 0, 0)
+
+	ctx := &model.MediaRoutingContext{PreferredModels: []string{"first", "second"}}
+	s.Score(ctx, []*model.MediaScoredCandidate{first, second, other})
+
+	assert.Greater(t, first.Score, second.Score)
+	assert.Greater(t, second.Score, other.Score)
+}
+
+// ===== Strategy Chain Tests =====
+
+func TestStrategyChain_Rank(t *testing.T) {
+	t.Run("orders by score", func(t *testing.T) {
+		pricey := newTestCandidate("pricey", 0.04, 0, model.MediaCapabilityImage)
+		cheap := newTestCandidate("cheap", 0.01, 0, model.MediaCapabilityImage)
+		preferred := newTestCandidate("preferred", 0.08, 0, model.MediaCapabilityImage)
+
+		ctx := &model.MediaRoutingContext{
+			Capability:      model.MediaCapabilityImage,
+			PreferredModels: []string{"preferred"},
+		}
+		result, err := DefaultStrategyChain().Rank(ctx, []*model.MediaScoredCandidate{pricey, cheap, preferred})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"preferred", "cheap", "pricey"}, candidateIDs(result))
+	})
+
+	t.Run("no candidates", func(t *testing.T) {
+		_, err := DefaultStrategyChain().Rank(&model.MediaRoutingContext{}, nil)
+		assert.ErrorIs(t, err, ErrModelNotFound)
+	})
+
+	t.Run("all filtered out", func(t *testing.T) {
+		c := newTestCandidate("down", 0, 0, model.MediaCapabilityImage)
+		ctx := &model.MediaRoutingContext{
+			Capability:     model.MediaCapabilityImage,
+			ProviderHealth: map[string]bool{c.Provider.ID.String(): false},
+		}
+
+		_, err := DefaultStrategyChain().Rank(ctx, []*model.MediaScoredCandidate{c})
+		assert.ErrorIs(t, err, ErrNoHealthyProvider)
+	})
+}
@@ -36,9 +36,12 @@ func (s TaskStatus) IsTerminal() bool {
 type TaskType string
 
 const (
-	TaskTypeImage TaskType = "image_generation"
-	TaskTypeVideo TaskType = "video_generation"
-	TaskTypeAudio TaskType = "audio_generation"
+	TaskTypeImage          TaskType = "image_generation"
+	TaskTypeImageEdit      TaskType = "image_edit"
+	TaskTypeImageVariation TaskType = "image_variation"
+	TaskTypeImageUpscale   TaskType = "image_upscale"
+	TaskTypeVideo          TaskType = "video_generation"
+	TaskTypeAudio          TaskType = "audio_generation"
 )
 
 // String returns the string representation of the task type.
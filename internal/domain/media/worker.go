@@ -0,0 +1,191 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+// StartWorker starts executing pending video tasks in the background,
+// running at most MaxConcurrentTasks at a time.
+func (d *Domain) StartWorker(ctx context.Context) {
+	d.workerMu.Lock()
+	defer d.workerMu.Unlock()
+
+	if d.workerCancel != nil {
+		return
+	}
+
+	ctx, d.workerCancel = context.WithCancel(ctx)
+	d.workerWG.Add(1)
+	go d.runWorker(ctx)
+
+	d.logger.Info("Media worker started",
+		zap.Int("concurrency", d.config.MaxConcurrentTasks),
+		zap.Duration("poll_interval", d.config.WorkerPollInterval),
+	)
+}
+
+// StopWorker stops the worker and waits for in-flight tasks to be handed
+// back to the queue.
+func (d *Domain) StopWorker() {
+	d.workerMu.Lock()
+	cancel := d.workerCancel
+	d.workerCancel = nil
+	d.workerMu.Unlock()
+
+	if cancel == nil {
+		return
+	}
+	cancel()
+	d.workerWG.Wait()
+}
+
+// runWorker polls for pending tasks until ctx is cancelled.
+func (d *Domain) runWorker(ctx context.Context) {
+	defer d.workerWG.Done()
+
+	slots := make(chan struct{}, max(1, d.config.MaxConcurrentTasks))
+	ticker := time.NewTicker(d.config.WorkerPollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.dispatchPendingTasks(ctx, slots)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchPendingTasks claims up to one pending video task per free slot and
+// executes each in its own goroutine.
+func (d *Domain) dispatchPendingTasks(ctx context.Context, slots chan struct{}) {
+	free := cap(slots) - len(slots)
+	if free == 0 {
+		return
+	}
+
+	tasks, err := d.taskDB.FindPending(ctx, free, d.config.TaskLeaseDuration)
+	if err != nil {
+		if ctx.Err() == nil {
+			d.logger.Error("Failed to find pending tasks", zap.Error(err))
+		}
+		return
+	}
+
+	for _, task := range tasks {
+		if task.Type != TaskTypeVideo.String() {
+			continue
+		}
+
+		// Another replica may have taken it since FindPending
+		claimed, err := d.taskDB.Claim(ctx, task.ID, d.config.TaskLeaseDuration)
+		if err != nil {
+			d.logger.Error("Failed to claim task",
+				zap.String("task_id", task.ID.String()),
+				zap.Error(err),
+			)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		slots <- struct{}{}
+		d.workerWG.Add(1)
+		go func(taskID uuid.UUID) {
+			defer d.workerWG.Done()
+			defer func() { <-slots }()
+			d.runVideoTask(ctx, taskID)
+		}(task.ID)
+	}
+}
+
+// runVideoTask executes a claimed task under TaskTimeout, sending heartbeats
+// while it runs. If the worker is shutting down, the task goes back to
+// pending so the next worker resumes it; if the worker dies, the task is
+// taken over once its lease runs out.
+func (d *Domain) runVideoTask(workerCtx context.Context, taskID uuid.UUID) {
+	ctx, cancel := context.WithTimeout(workerCtx, d.config.TaskTimeout)
+	defer cancel()
+
+	heartbeatCtx, stopHeartbeat := context.WithCancel(ctx)
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		d.heartbeatTask(heartbeatCtx, taskID)
+	}()
+
+	err := d.ExecuteVideoTask(ctx, taskID)
+	stopHeartbeat()
+	<-heartbeatDone
+
+	switch {
+	case err == nil:
+	case workerCtx.Err() != nil:
+		d.requeueTask(context.WithoutCancel(ctx), taskID)
+	case errors.Is(err, context.DeadlineExceeded):
+		d.failTask(ctx, taskID, "task timed out")
+		d.logger.Warn("Video task timed out",
+			zap.String("task_id", taskID.String()),
+			zap.Duration("timeout", d.config.TaskTimeout),
+		)
+	default:
+		d.logger.Warn("Video task failed",
+			zap.String("task_id", taskID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// heartbeatTask marks a running task alive every TaskHeartbeatInterval
+// until ctx is done or the task stops running.
+func (d *Domain) heartbeatTask(ctx context.Context, taskID uuid.UUID) {
+	ticker := time.NewTicker(d.config.TaskHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		alive, err := d.taskDB.Heartbeat(ctx, taskID)
+		if err != nil {
+			if ctx.Err() == nil {
+				d.logger.Warn("Failed to send task heartbeat",
+					zap.String("task_id", taskID.String()),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+		if !alive {
+			return
+		}
+	}
+}
+
+// requeueTask returns a running task to pending, keeping its progress and
+// provider job so it is not submitted again.
+func (d *Domain) requeueTask(ctx context.Context, taskID uuid.UUID) {
+	task, err := d.taskDB.FindByID(ctx, taskID)
+	if err != nil || task == nil || task.Status != model.MediaTaskStatusRunning {
+		return
+	}
+	if _, err := d.updateRunning(ctx, taskID, model.MediaTaskStatusPending, task.Progress, "", ""); err != nil {
+		d.logger.Error("Failed to requeue task",
+			zap.String("task_id", taskID.String()),
+			zap.Error(err),
+		)
+	}
+}
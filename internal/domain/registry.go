@@ -99,24 +99,35 @@ type OutboundPorts struct {
 	AIVendorRegistry outbound.AIVendorRegistryPort
 	AICrypto         outbound.AICryptoPort
 	AIUsageRecorder  outbound.AIUsageRecorderPort
+	AIRequestLogDB   outbound.AIRequestLogDatabasePort
+	AIAliasDB        outbound.AIModelAliasDatabasePort
+	AIImageFetcher   outbound.AIImageFetcherPort
 
 	// Git ports
 	GitRepoDB       outbound.GitRepoDatabasePort
 	GitCollabDB     outbound.GitCollaboratorDatabasePort
 	GitPRDB         outbound.GitPullRequestDatabasePort
+	GitPRReviewDB   outbound.GitPRReviewDatabasePort
+	GitProtectionDB outbound.GitBranchProtectionDatabasePort
 	GitLFSObjDB     outbound.GitLFSObjectDatabasePort
 	GitLFSLockDB    outbound.GitLFSLockDatabasePort
 	GitStorage      outbound.GitStoragePort
 	GitLFSStorage   outbound.GitLFSStoragePort
 	GitAccessCtrl   outbound.GitAccessControlPort
+	GitTeamAccess   outbound.GitTeamAccessPort
 
 	// Media ports
-	MediaProviderDB  outbound.MediaProviderDatabasePort
-	MediaModelDB     outbound.MediaModelDatabasePort
-	MediaTaskDB      outbound.MediaTaskDatabasePort
-	MediaHealthCache outbound.MediaProviderHealthCachePort
-	MediaVendorReg   outbound.MediaVendorRegistryPort
-	MediaCrypto      outbound.MediaCryptoPort
+	MediaProviderDB   outbound.MediaProviderDatabasePort
+	MediaModelDB      outbound.MediaModelDatabasePort
+	MediaTaskDB       outbound.MediaTaskDatabasePort
+	MediaHealthCache  outbound.MediaProviderHealthCachePort
+	MediaVendorReg    outbound.MediaVendorRegistryPort
+	MediaCrypto       outbound.MediaCryptoPort
+	MediaVideoQuota   outbound.MediaVideoQuotaPort
+	MediaAssetDB      outbound.MediaAssetDatabasePort
+	MediaFetcher      outbound.MediaFetcherPort
+	MediaStorageQuota outbound.MediaStorageQuotaPort
+	MediaTeamAccess   outbound.MediaTeamAccessPort
 
 	// Collaboration ports
 	CollabTeamDB       outbound.TeamDatabasePort
@@ -261,11 +272,14 @@ func NewDomain(ports *OutboundPorts, authConfig *AuthConfig, paymentConfig *Paym
 			ports.AIModelDB,
 			ports.AIAccountDB,
 			ports.AIGroupDB,
+			ports.AIAliasDB,
 			ports.AIHealthCache,
 			ports.AIEmbeddingCache,
 			ports.AIVendorRegistry,
 			ports.AICrypto,
 			ports.AIUsageRecorder,
+			ports.AIRequestLogDB,
+			ports.AIImageFetcher,
 			aiConfig,
 			logger.Named("ai"),
 		),
@@ -273,11 +287,15 @@ func NewDomain(ports *OutboundPorts, authConfig *AuthConfig, paymentConfig *Paym
 			ports.GitRepoDB,
 			ports.GitCollabDB,
 			ports.GitPRDB,
+			ports.GitPRReviewDB,
+			ports.GitProtectionDB,
 			ports.GitLFSObjDB,
 			ports.GitLFSLockDB,
 			ports.GitStorage,
 			ports.GitLFSStorage,
 			nil, // quotaChecker - will be set after billing domain is available
+			ports.GitTeamAccess,
+			ports.EventPublisher,
 			gitConfig,
 			logger.Named("git"),
 		),
@@ -286,6 +304,7 @@ func NewDomain(ports *OutboundPorts, authConfig *AuthConfig, paymentConfig *Paym
 			ports.GitLFSObjDB,
 			ports.GitLFSStorage,
 			ports.GitAccessCtrl,
+			nil, // quotaChecker - will be set after billing domain is available
 			gitConfig,
 			logger.Named("git.lfs"),
 		),
@@ -302,6 +321,12 @@ func NewDomain(ports *OutboundPorts, authConfig *AuthConfig, paymentConfig *Paym
 			ports.MediaHealthCache,
 			ports.MediaVendorReg,
 			ports.MediaCrypto,
+			ports.MediaVideoQuota,
+			ports.MediaAssetDB,
+			ports.Storage,
+			ports.MediaFetcher,
+			ports.MediaStorageQuota,
+			ports.MediaTeamAccess,
 			mediaConfig,
 			logger.Named("media"),
 		),
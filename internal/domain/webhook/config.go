@@ -0,0 +1,38 @@
+package webhook
+
+import "time"
+
+// Config holds webhook domain configuration.
+type Config struct {
+	// MaxEndpointsPerUser caps how many endpoints a user can add.
+	MaxEndpointsPerUser int
+
+	// MaxAttempts is how many times a delivery is attempted before it is
+	// marked failed.
+	MaxAttempts int
+
+	// RetryBackoff is the delay before the second attempt; it doubles for
+	// each further attempt up to MaxRetryBackoff.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+
+	// DisableAfterFailures disables an endpoint after this many failed
+	// attempts in a row, across all of its deliveries.
+	DisableAfterFailures int
+
+	// MaxResponseBodyBytes is how much of each response is kept in the
+	// delivery log.
+	MaxResponseBodyBytes int
+}
+
+// DefaultConfig returns default webhook configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		MaxEndpointsPerUser:  10,
+		MaxAttempts:          8,
+		RetryBackoff:         30 * time.Second,
+		MaxRetryBackoff:      2 * time.Hour,
+		DisableAfterFailures: 20,
+		MaxResponseBodyBytes: 1024,
+	}
+}
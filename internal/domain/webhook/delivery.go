@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+// Delivery request headers.
+const (
+	HeaderEventID    = "X-Webhook-ID"
+	HeaderDeliveryID = "X-Webhook-Delivery"
+	HeaderEventType  = "X-Webhook-Event"
+	HeaderTimestamp  = "X-Webhook-Timestamp"
+	HeaderSignature  = "X-Webhook-Signature"
+
+	userAgent = "UniEdit-Webhooks/1.0"
+)
+
+// Sign returns the signature of a delivery body sent at timestamp:
+// "sha256=" followed by the hex HMAC-SHA256 of "<timestamp>.<body>" keyed
+// with the endpoint secret. Receivers recompute it to check the request
+// came from us, and reject old timestamps to stop replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver makes one attempt at a delivery. It returns an error wrapping
+// ErrDeliveryFailed when the attempt failed and should be retried; any
+// other error means the delivery is over.
+func (d *Domain) Deliver(ctx context.Context, deliveryID uuid.UUID) error {
+	delivery, err := d.deliveryDB.FindByID(ctx, deliveryID)
+	if err != nil {
+		return fmt.Errorf("find delivery: %w", err)
+	}
+	if delivery == nil {
+		return ErrDeliveryNotFound
+	}
+	if delivery.Status != model.WebhookDeliveryStatusPending {
+		return nil
+	}
+
+	endpoint, err := d.endpointDB.FindByID(ctx, delivery.EndpointID)
+	if err != nil {
+		return fmt.Errorf("find endpoint: %w", err)
+	}
+	if endpoint == nil || !endpoint.Active {
+		delivery.Status = model.WebhookDeliveryStatusFailed
+		if err := d.deliveryDB.Update(ctx, delivery); err != nil {
+			return fmt.Errorf("update delivery: %w", err)
+		}
+		return ErrEndpointDisabled
+	}
+
+	secret, err := d.crypto.Decrypt(endpoint.EncryptedSecret)
+	if err != nil {
+		return fmt.Errorf("decrypt secret: %w", err)
+	}
+
+	body := []byte(delivery.Payload)
+	start := time.Now()
+	headers := map[string]string{
+		"Content-Type":   "application/json",
+		"User-Agent":     userAgent,
+		HeaderEventID:    delivery.EventID.String(),
+		HeaderDeliveryID: delivery.ID.String(),
+		HeaderEventType:  delivery.EventType,
+		HeaderTimestamp:  strconv.FormatInt(start.Unix(), 10),
+		HeaderSignature:  Sign(secret, start.Unix(), body),
+	}
+
+	status, response, sendErr := d.sender.Send(ctx, endpoint.URL, headers, body)
+
+	attempt := model.WebhookDeliveryAttempt{
+		Number:      len(delivery.Attempts) + 1,
+		StatusCode:  status,
+		DurationMs:  time.Since(start).Milliseconds(),
+		AttemptedAt: start,
+	}
+	if len(response) > d.config.MaxResponseBodyBytes {
+		response = response[:d.config.MaxResponseBodyBytes]
+	}
+	attempt.ResponseBody = string(response)
+	if sendErr != nil {
+		attempt.Error = sendErr.Error()
+	}
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	delivery.ResponseStatus = status
+
+	if sendErr == nil && status >= 200 && status < 300 {
+		return d.recordSuccess(ctx, delivery, endpoint)
+	}
+	return d.recordFailure(ctx, delivery, endpoint, sendErr)
+}
+
+// recordSuccess marks a delivery succeeded and resets its endpoint's
+// failure count.
+func (d *Domain) recordSuccess(ctx context.Context, delivery *model.WebhookDelivery, endpoint *model.WebhookEndpoint) error {
+	now := time.Now()
+	delivery.Status = model.WebhookDeliveryStatusSucceeded
+	delivery.DeliveredAt = &now
+	if err := d.deliveryDB.Update(ctx, delivery); err != nil {
+		return fmt.Errorf("update delivery: %w", err)
+	}
+
+	if err := d.endpointDB.RecordSuccess(ctx, endpoint.ID); err != nil {
+		d.logger.Warn("Failed to record webhook success",
+			zap.String("endpoint_id", endpoint.ID.String()),
+			zap.Error(err),
+		)
+	}
+	return nil
+}
+
+// recordFailure logs a failed attempt, disables the endpoint once it has
+// failed too often in a row, and decides whether to retry.
+func (d *Domain) recordFailure(ctx context.Context, delivery *model.WebhookDelivery, endpoint *model.WebhookEndpoint, sendErr error) error {
+	failures, err := d.endpointDB.RecordFailure(ctx, endpoint.ID)
+	if err != nil {
+		d.logger.Warn("Failed to record webhook failure",
+			zap.String("endpoint_id", endpoint.ID.String()),
+			zap.Error(err),
+		)
+	}
+
+	disabled := false
+	if failures >= d.config.DisableAfterFailures {
+		reason := fmt.Sprintf("disabled after %d consecutive failed deliveries", failures)
+		if err := d.endpointDB.Disable(ctx, endpoint.ID, reason); err != nil {
+			d.logger.Warn("Failed to disable webhook endpoint",
+				zap.String("endpoint_id", endpoint.ID.String()),
+				zap.Error(err),
+			)
+		} else {
+			disabled = true
+			d.logger.Info("Webhook endpoint disabled",
+				zap.String("endpoint_id", endpoint.ID.String()),
+				zap.Int("failures", failures),
+			)
+		}
+	}
+
+	final := disabled || len(delivery.Attempts) >= d.config.MaxAttempts
+	if final {
+		delivery.Status = model.WebhookDeliveryStatusFailed
+	}
+	if err := d.deliveryDB.Update(ctx, delivery); err != nil {
+		return fmt.Errorf("update delivery: %w", err)
+	}
+
+	cause := sendErr
+	if cause == nil {
+		cause = fmt.Errorf("endpoint responded with status %d", delivery.ResponseStatus)
+	}
+	if disabled {
+		return errors.Join(ErrEndpointDisabled, cause)
+	}
+	if final {
+		return fmt.Errorf("giving up after %d attempts: %w", len(delivery.Attempts), cause)
+	}
+	return fmt.Errorf("%w: %w", ErrDeliveryFailed, cause)
+}
@@ -0,0 +1,391 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+	"github.com/uniedit/server/internal/port/outbound"
+	"github.com/uniedit/server/internal/utils/random"
+)
+
+// taskEventNamespace derives stable event IDs from task transitions, so a
+// transition reported twice is delivered once.
+var taskEventNamespace = uuid.MustParse("6f1c2d0e-8a4b-4c57-9e8b-2b7f0a3c5d19")
+
+// Domain implements webhook endpoint management and event delivery.
+type Domain struct {
+	endpointDB outbound.WebhookEndpointDatabasePort
+	deliveryDB outbound.WebhookDeliveryDatabasePort
+	queue      outbound.WebhookQueuePort
+	sender     outbound.WebhookSenderPort
+	crypto     outbound.WebhookCryptoPort
+	config     *Config
+	logger     *zap.Logger
+}
+
+// NewDomain creates a new webhook domain.
+func NewDomain(
+	endpointDB outbound.WebhookEndpointDatabasePort,
+	deliveryDB outbound.WebhookDeliveryDatabasePort,
+	queue outbound.WebhookQueuePort,
+	sender outbound.WebhookSenderPort,
+	crypto outbound.WebhookCryptoPort,
+	config *Config,
+	logger *zap.Logger,
+) *Domain {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	return &Domain{
+		endpointDB: endpointDB,
+		deliveryDB: deliveryDB,
+		queue:      queue,
+		sender:     sender,
+		crypto:     crypto,
+		config:     config,
+		logger:     logger,
+	}
+}
+
+// CreateEndpoint adds an endpoint and returns its signing secret.
+func (d *Domain) CreateEndpoint(ctx context.Context, userID uuid.UUID, input *inbound.WebhookEndpointCreateInput) (*inbound.WebhookEndpointOutput, error) {
+	if err := validateURL(input.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := normalizeEventTypes(input.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := d.endpointDB.CountByOwner(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("count endpoints: %w", err)
+	}
+	if count >= int64(d.config.MaxEndpointsPerUser) {
+		return nil, ErrTooManyEndpoints
+	}
+
+	secret, encrypted, err := d.newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := &model.WebhookEndpoint{
+		ID:              uuid.New(),
+		OwnerID:         userID,
+		URL:             input.URL,
+		Description:     input.Description,
+		EventTypes:      eventTypes,
+		EncryptedSecret: encrypted,
+		Active:          true,
+	}
+	if err := d.endpointDB.Create(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("create endpoint: %w", err)
+	}
+
+	d.logger.Info("Webhook endpoint created",
+		zap.String("endpoint_id", endpoint.ID.String()),
+		zap.String("user_id", userID.String()),
+	)
+
+	return &inbound.WebhookEndpointOutput{WebhookEndpoint: endpoint, Secret: secret}, nil
+}
+
+// ListEndpoints lists the user's endpoints.
+func (d *Domain) ListEndpoints(ctx context.Context, userID uuid.UUID) ([]*model.WebhookEndpoint, error) {
+	return d.endpointDB.FindByOwner(ctx, userID)
+}
+
+// GetEndpoint returns one of the user's endpoints.
+func (d *Domain) GetEndpoint(ctx context.Context, userID, endpointID uuid.UUID) (*model.WebhookEndpoint, error) {
+	endpoint, err := d.endpointDB.FindByID(ctx, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint == nil || endpoint.OwnerID != userID {
+		return nil, ErrEndpointNotFound
+	}
+	return endpoint, nil
+}
+
+// UpdateEndpoint changes an endpoint's URL, events or active state.
+func (d *Domain) UpdateEndpoint(ctx context.Context, userID, endpointID uuid.UUID, input *inbound.WebhookEndpointUpdateInput) (*model.WebhookEndpoint, error) {
+	endpoint, err := d.GetEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.URL != nil {
+		if err := validateURL(*input.URL); err != nil {
+			return nil, err
+		}
+		endpoint.URL = *input.URL
+	}
+	if input.Description != nil {
+		endpoint.Description = *input.Description
+	}
+	if input.EventTypes != nil {
+		eventTypes, err := normalizeEventTypes(*input.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.EventTypes = eventTypes
+	}
+	if input.Active != nil {
+		endpoint.Active = *input.Active
+		if endpoint.Active {
+			// Give a re-enabled endpoint a fresh failure budget.
+			endpoint.DisabledReason = ""
+			endpoint.ConsecutiveFailures = 0
+		}
+	}
+
+	if err := d.endpointDB.Update(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("update endpoint: %w", err)
+	}
+	return endpoint, nil
+}
+
+// DeleteEndpoint deletes an endpoint and its delivery log.
+func (d *Domain) DeleteEndpoint(ctx context.Context, userID, endpointID uuid.UUID) error {
+	if _, err := d.GetEndpoint(ctx, userID, endpointID); err != nil {
+		return err
+	}
+	if err := d.endpointDB.Delete(ctx, endpointID); err != nil {
+		return fmt.Errorf("delete endpoint: %w", err)
+	}
+
+	d.logger.Info("Webhook endpoint deleted",
+		zap.String("endpoint_id", endpointID.String()),
+		zap.String("user_id", userID.String()),
+	)
+	return nil
+}
+
+// RotateSecret replaces an endpoint's signing secret. Deliveries already
+// queued are signed with the new secret.
+func (d *Domain) RotateSecret(ctx context.Context, userID, endpointID uuid.UUID) (*inbound.WebhookEndpointOutput, error) {
+	endpoint, err := d.GetEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, encrypted, err := d.newSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint.EncryptedSecret = encrypted
+	if err := d.endpointDB.Update(ctx, endpoint); err != nil {
+		return nil, fmt.Errorf("update endpoint: %w", err)
+	}
+
+	return &inbound.WebhookEndpointOutput{WebhookEndpoint: endpoint, Secret: secret}, nil
+}
+
+// ListDeliveries lists an endpoint's delivery log.
+func (d *Domain) ListDeliveries(ctx context.Context, userID, endpointID uuid.UUID, limit, offset int) (*inbound.WebhookDeliveryListOutput, error) {
+	if _, err := d.GetEndpoint(ctx, userID, endpointID); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	deliveries, total, err := d.deliveryDB.FindByEndpoint(ctx, endpointID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	return &inbound.WebhookDeliveryListOutput{
+		Deliveries: deliveries,
+		Total:      total,
+		Limit:      limit,
+		Offset:     offset,
+	}, nil
+}
+
+// GetDelivery returns a delivery with its attempts.
+func (d *Domain) GetDelivery(ctx context.Context, userID, endpointID, deliveryID uuid.UUID) (*model.WebhookDelivery, error) {
+	if _, err := d.GetEndpoint(ctx, userID, endpointID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := d.deliveryDB.FindByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery == nil || delivery.EndpointID != endpointID {
+		return nil, ErrDeliveryNotFound
+	}
+	return delivery, nil
+}
+
+// Redeliver sends a delivery's event again as a new delivery with the same
+// event ID and payload, so receivers can recognise it.
+func (d *Domain) Redeliver(ctx context.Context, userID, endpointID, deliveryID uuid.UUID) (*model.WebhookDelivery, error) {
+	endpoint, err := d.GetEndpoint(ctx, userID, endpointID)
+	if err != nil {
+		return nil, err
+	}
+	if !endpoint.Active {
+		return nil, ErrEndpointDisabled
+	}
+
+	original, err := d.deliveryDB.FindByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original == nil || original.EndpointID != endpointID {
+		return nil, ErrDeliveryNotFound
+	}
+
+	delivery := &model.WebhookDelivery{
+		ID:           uuid.New(),
+		EndpointID:   endpointID,
+		EventID:      original.EventID,
+		EventType:    original.EventType,
+		Payload:      original.Payload,
+		Status:       model.WebhookDeliveryStatusPending,
+		RedeliveryOf: &original.ID,
+	}
+	if _, err := d.deliveryDB.Create(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("create delivery: %w", err)
+	}
+	if err := d.queue.Enqueue(ctx, delivery.ID); err != nil {
+		return nil, fmt.Errorf("enqueue delivery: %w", err)
+	}
+	return delivery, nil
+}
+
+// Dispatch queues a delivery of the event to each of the owner's active
+// endpoints subscribed to its type. An event already delivered to an
+// endpoint is not queued for it again.
+func (d *Domain) Dispatch(ctx context.Context, event *model.AccountEvent) error {
+	endpoints, err := d.endpointDB.FindByOwner(ctx, event.OwnerID)
+	if err != nil {
+		return fmt.Errorf("find endpoints: %w", err)
+	}
+
+	var payload []byte
+	for _, endpoint := range endpoints {
+		if !endpoint.Active || !endpoint.Subscribes(event.Type) {
+			continue
+		}
+
+		if payload == nil {
+			if event.CreatedAt.IsZero() {
+				event.CreatedAt = time.Now()
+			}
+			if payload, err = json.Marshal(event); err != nil {
+				return fmt.Errorf("marshal event: %w", err)
+			}
+		}
+
+		delivery := &model.WebhookDelivery{
+			ID:         uuid.New(),
+			EndpointID: endpoint.ID,
+			EventID:    event.ID,
+			EventType:  event.Type,
+			Payload:    string(payload),
+			Status:     model.WebhookDeliveryStatusPending,
+		}
+		created, err := d.deliveryDB.Create(ctx, delivery)
+		if err != nil {
+			return fmt.Errorf("create delivery: %w", err)
+		}
+		if !created {
+			continue
+		}
+		if err := d.queue.Enqueue(ctx, delivery.ID); err != nil {
+			return fmt.Errorf("enqueue delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// HandleTaskEvent dispatches task.completed and task.failed events when a
+// task finishes. Cancelled tasks are not reported, since the user
+// cancelled them.
+func (d *Domain) HandleTaskEvent(ctx context.Context, event *model.TaskEvent) error {
+	var eventType string
+	switch event.Status {
+	case "completed":
+		eventType = model.AccountEventTaskCompleted
+	case "failed", "dead":
+		eventType = model.AccountEventTaskFailed
+	default:
+		return nil
+	}
+
+	return d.Dispatch(ctx, &model.AccountEvent{
+		ID:        uuid.NewSHA1(taskEventNamespace, []byte(event.TaskID.String()+":"+event.Status)),
+		Type:      eventType,
+		OwnerID:   event.OwnerID,
+		CreatedAt: event.UpdatedAt,
+		Data:      event,
+	})
+}
+
+// newSecret generates a signing secret and its encrypted form.
+func (d *Domain) newSecret() (string, string, error) {
+	token, err := random.Hex(32)
+	if err != nil {
+		return "", "", fmt.Errorf("generate secret: %w", err)
+	}
+	secret := "whsec_" + token
+	encrypted, err := d.crypto.Encrypt(secret)
+	if err != nil {
+		return "", "", fmt.Errorf("encrypt secret: %w", err)
+	}
+	return secret, encrypted, nil
+}
+
+// validateURL checks that an endpoint URL is an absolute HTTP(S) URL.
+// Whether it points at a public address is checked when sending, since
+// DNS can change.
+func validateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || u.User != nil {
+		return ErrInvalidURL
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return ErrInvalidURL
+	}
+	return nil
+}
+
+// normalizeEventTypes validates event types and removes duplicates.
+func normalizeEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, ErrInvalidEventType
+	}
+
+	result := make([]string, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		if t != model.WebhookAllEvents && !slices.Contains(model.AccountEventTypes, t) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidEventType, t)
+		}
+		if !slices.Contains(result, t) {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+// Compile-time interface check
+var _ inbound.WebhookDomain = (*Domain)(nil)
@@ -0,0 +1,501 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/uniedit/server/internal/model"
+	"github.com/uniedit/server/internal/port/inbound"
+	"github.com/uniedit/server/internal/port/outbound"
+	"go.uber.org/zap"
+)
+
+// --- Mock implementations ---
+
+type MockEndpointDB struct {
+	mock.Mock
+}
+
+func (m *MockEndpointDB) Create(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+	args := m.Called(ctx, endpoint)
+	return args.Error(0)
+}
+
+func (m *MockEndpointDB) FindByID(ctx context.Context, id uuid.UUID) (*model.WebhookEndpoint, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockEndpointDB) FindByOwner(ctx context.Context, ownerID uuid.UUID) ([]*model.WebhookEndpoint, error) {
+	args := m.Called(ctx, ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookEndpoint), args.Error(1)
+}
+
+func (m *MockEndpointDB) CountByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error) {
+	args := m.Called(ctx, ownerID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockEndpointDB) Update(ctx context.Context, endpoint *model.WebhookEndpoint) error {
+	args := m.Called(ctx, endpoint)
+	return args.Error(0)
+}
+
+func (m *MockEndpointDB) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockEndpointDB) RecordSuccess(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockEndpointDB) RecordFailure(ctx context.Context, id uuid.UUID) (int, error) {
+	args := m.Called(ctx, id)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockEndpointDB) Disable(ctx context.Context, id uuid.UUID, reason string) error {
+	args := m.Called(ctx, id, reason)
+	return args.Error(0)
+}
+
+var _ outbound.WebhookEndpointDatabasePort = (*MockEndpointDB)(nil)
+
+type MockDeliveryDB struct {
+	mock.Mock
+}
+
+func (m *MockDeliveryDB) Create(ctx context.Context, delivery *model.WebhookDelivery) (bool, error) {
+	args := m.Called(ctx, delivery)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockDeliveryDB) FindByID(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockDeliveryDB) FindByEndpoint(ctx context.Context, endpointID uuid.UUID, limit, offset int) ([]*model.WebhookDelivery, int64, error) {
+	args := m.Called(ctx, endpointID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, 0, args.Error(2)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockDeliveryDB) Update(ctx context.Context, delivery *model.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+var _ outbound.WebhookDeliveryDatabasePort = (*MockDeliveryDB)(nil)
+
+type MockQueue struct {
+	mock.Mock
+}
+
+func (m *MockQueue) Enqueue(ctx context.Context, deliveryID uuid.UUID) error {
+	args := m.Called(ctx, deliveryID)
+	return args.Error(0)
+}
+
+var _ outbound.WebhookQueuePort = (*MockQueue)(nil)
+
+type MockSender struct {
+	mock.Mock
+}
+
+func (m *MockSender) Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, []byte, error) {
+	args := m.Called(ctx, url, headers, body)
+	if args.Get(1) == nil {
+		return args.Int(0), nil, args.Error(2)
+	}
+	return args.Int(0), args.Get(1).([]byte), args.Error(2)
+}
+
+var _ outbound.WebhookSenderPort = (*MockSender)(nil)
+
+// prefixCrypto "encrypts" by adding a prefix, which is enough to check that
+// secrets are never stored in the clear.
+type prefixCrypto struct{}
+
+func (prefixCrypto) Encrypt(plaintext string) (string, error) {
+	return "enc:" + plaintext, nil
+}
+
+func (prefixCrypto) Decrypt(ciphertext string) (string, error) {
+	return ciphertext[len("enc:"):], nil
+}
+
+// --- Test helpers ---
+
+type testMocks struct {
+	endpointDB *MockEndpointDB
+	deliveryDB *MockDeliveryDB
+	queue      *MockQueue
+	sender     *MockSender
+}
+
+func newTestDomain(config *Config) (*Domain, *testMocks) {
+	m := &testMocks{
+		endpointDB: new(MockEndpointDB),
+		deliveryDB: new(MockDeliveryDB),
+		queue:      new(MockQueue),
+		sender:     new(MockSender),
+	}
+	d := NewDomain(m.endpointDB, m.deliveryDB, m.queue, m.sender, prefixCrypto{}, config, zap.NewNop())
+	return d, m
+}
+
+func newTestEndpoint(ownerID uuid.UUID, eventTypes ...string) *model.WebhookEndpoint {
+	return &model.WebhookEndpoint{
+		ID:              uuid.New(),
+		OwnerID:         ownerID,
+		URL:             "https://example.com/hooks",
+		EventTypes:      eventTypes,
+		EncryptedSecret: "enc:whsec_test",
+		Active:          true,
+	}
+}
+
+func newTestDelivery(endpointID uuid.UUID) *model.WebhookDelivery {
+	return &model.WebhookDelivery{
+		ID:         uuid.New(),
+		EndpointID: endpointID,
+		EventID:    uuid.New(),
+		EventType:  model.AccountEventPaymentSucceeded,
+		Payload:    `{"type":"payment.succeeded"}`,
+		Status:     model.WebhookDeliveryStatusPending,
+	}
+}
+
+// --- Tests ---
+
+func TestCreateEndpoint(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("returns secret and stores it encrypted", func(t *testing.T) {
+		d, m := newTestDomain(nil)
+		m.endpointDB.On("CountByOwner", ctx, userID).Return(int64(0), nil)
+		m.endpointDB.On("Create", ctx, mock.AnythingOfType("*model.WebhookEndpoint")).Return(nil)
+
+		out, err := d.CreateEndpoint(ctx, userID, &inbound.WebhookEndpointCreateInput{
+			URL:        "https://example.com/hooks",
+			EventTypes: []string{model.AccountEventTaskCompleted, model.AccountEventTaskCompleted},
+		})
+
+		assert.NoError(t, err)
+		assert.Contains(t, out.Secret, "whsec_")
+		assert.Equal(t, "enc:"+out.Secret, out.EncryptedSecret)
+		assert.Equal(t, []string{model.AccountEventTaskCompleted}, []string(out.EventTypes))
+		assert.True(t, out.Active)
+	})
+
+	t.Run("rejects unknown event type", func(t *testing.T) {
+		d, _ := newTestDomain(nil)
+
+		_, err := d.CreateEndpoint(ctx, userID, &inbound.WebhookEndpointCreateInput{
+			URL:        "https://example.com/hooks",
+			EventTypes: []string{"video.exploded"},
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidEventType)
+	})
+
+	t.Run("rejects non-HTTP URL", func(t *testing.T) {
+		d, _ := newTestDomain(nil)
+
+		_, err := d.CreateEndpoint(ctx, userID, &inbound.WebhookEndpointCreateInput{
+			URL:        "ftp://example.com/hooks",
+			EventTypes: []string{model.WebhookAllEvents},
+		})
+
+		assert.ErrorIs(t, err, ErrInvalidURL)
+	})
+
+	t.Run("enforces endpoint limit", func(t *testing.T) {
+		d, m := newTestDomain(nil)
+		m.endpointDB.On("CountByOwner", ctx, userID).Return(int64(10), nil)
+
+		_, err := d.CreateEndpoint(ctx, userID, &inbound.WebhookEndpointCreateInput{
+			URL:        "https://example.com/hooks",
+			EventTypes: []string{model.WebhookAllEvents},
+		})
+
+		assert.ErrorIs(t, err, ErrTooManyEndpoints)
+	})
+}
+
+func TestGetEndpoint_OtherOwner(t *testing.T) {
+	ctx := context.Background()
+	d, m := newTestDomain(nil)
+	endpoint := newTestEndpoint(uuid.New(), model.WebhookAllEvents)
+	m.endpointDB.On("FindByID", ctx, endpoint.ID).Return(endpoint, nil)
+
+	_, err := d.GetEndpoint(ctx, uuid.New(), endpoint.ID)
+
+	assert.ErrorIs(t, err, ErrEndpointNotFound)
+}
+
+func TestUpdateEndpoint_ReenableResetsFailures(t *testing.T) {
+	ctx := context.Background()
+	d, m := newTestDomain(nil)
+	endpoint := newTestEndpoint(uuid.New(), model.WebhookAllEvents)
+	endpoint.Active = false
+	endpoint.DisabledReason = "disabled after 20 consecutive failed deliveries"
+	endpoint.ConsecutiveFailures = 20
+	m.endpointDB.On("FindByID", ctx, endpoint.ID).Return(endpoint, nil)
+	m.endpointDB.On("Update", ctx, endpoint).Return(nil)
+
+	active := true
+	out, err := d.UpdateEndpoint(ctx, endpoint.OwnerID, endpoint.ID, &inbound.WebhookEndpointUpdateInput{Active: &active})
+
+	assert.NoError(t, err)
+	assert.True(t, out.Active)
+	assert.Empty(t, out.DisabledReason)
+	assert.Zero(t, out.ConsecutiveFailures)
+}
+
+func TestDispatch(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+
+	t.Run("queues subscribed active endpoints only", func(t *testing.T) {
+		d, m := newTestDomain(nil)
+		subscribed := newTestEndpoint(ownerID, model.AccountEventPaymentSucceeded)
+		wildcard := newTestEndpoint(ownerID, model.WebhookAllEvents)
+		other := newTestEndpoint(ownerID, model.AccountEventTaskCompleted)
+		inactive := newTestEndpoint(ownerID, model.WebhookAllEvents)
+		inactive.Active = false
+		m.endpointDB.On("FindByOwner", ctx, ownerID).
+			Return([]*model.WebhookEndpoint{subscribed, wildcard, other, inactive}, nil)
+
+		var created []*model.WebhookDelivery
+		m.deliveryDB.On("Create", ctx, mock.AnythingOfType("*model.WebhookDelivery")).
+			Run(func(args mock.Arguments) {
+				created = append(created, args.Get(1).(*model.WebhookDelivery))
+			}).
+			Return(true, nil)
+		m.queue.On("Enqueue", ctx, mock.Anything).Return(nil)
+
+		event := &model.AccountEvent{
+			ID:      uuid.New(),
+			Type:    model.AccountEventPaymentSucceeded,
+			OwnerID: ownerID,
+			Data:    map[string]any{"amount": 100},
+		}
+		err := d.Dispatch(ctx, event)
+
+		assert.NoError(t, err)
+		assert.Len(t, created, 2)
+		assert.Equal(t, subscribed.ID, created[0].EndpointID)
+		assert.Equal(t, wildcard.ID, created[1].EndpointID)
+		m.queue.AssertNumberOfCalls(t, "Enqueue", 2)
+
+		var payload map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(created[0].Payload), &payload))
+		assert.Equal(t, event.ID.String(), payload["id"])
+		assert.Equal(t, model.AccountEventPaymentSucceeded, payload["type"])
+		assert.NotContains(t, payload, "owner_id")
+	})
+
+	t.Run("skips events already delivered", func(t *testing.T) {
+		d, m := newTestDomain(nil)
+		endpoint := newTestEndpoint(ownerID, model.WebhookAllEvents)
+		m.endpointDB.On("FindByOwner", ctx, ownerID).Return([]*model.WebhookEndpoint{endpoint}, nil)
+		m.deliveryDB.On("Create", ctx, mock.Anything).Return(false, nil)
+
+		err := d.Dispatch(ctx, &model.AccountEvent{ID: uuid.New(), Type: model.AccountEventTaskFailed, OwnerID: ownerID})
+
+		assert.NoError(t, err)
+		m.queue.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandleTaskEvent(t *testing.T) {
+	ctx := context.Background()
+	ownerID := uuid.New()
+	taskID := uuid.New()
+
+	t.Run("same transition gets same event ID", func(t *testing.T) {
+		d, m := newTestDomain(nil)
+		endpoint := newTestEndpoint(ownerID, model.AccountEventTaskCompleted)
+		m.endpointDB.On("FindByOwner", ctx, ownerID).Return([]*model.WebhookEndpoint{endpoint}, nil)
+
+		var eventIDs []uuid.UUID
+		m.deliveryDB.On("Create", ctx, mock.Anything).
+			Run(func(args mock.Arguments) {
+				delivery := args.Get(1).(*model.WebhookDelivery)
+				assert.Equal(t, model.AccountEventTaskCompleted, delivery.EventType)
+				eventIDs = append(eventIDs, delivery.EventID)
+			}).
+			Return(true, nil)
+		m.queue.On("Enqueue", ctx, mock.Anything).Return(nil)
+
+		event := &model.TaskEvent{TaskID: taskID, OwnerID: ownerID, Status: "completed", UpdatedAt: time.Now()}
+		assert.NoError(t, d.HandleTaskEvent(ctx, event))
+		assert.NoError(t, d.HandleTaskEvent(ctx, event))
+
+		assert.Len(t, eventIDs, 2)
+		assert.Equal(t, eventIDs[0], eventIDs[1])
+	})
+
+	t.Run("ignores non-terminal and cancelled tasks", func(t *testing.T) {
+		d, m := newTestDomain(nil)
+
+		for _, status := range []string{"pending", "running", "cancelled"} {
+			err := d.HandleTaskEvent(ctx, &model.TaskEvent{TaskID: taskID, OwnerID: ownerID, Status: status})
+			assert.NoError(t, err)
+		}
+		m.endpointDB.AssertNotCalled(t, "FindByOwner", mock.Anything, mock.Anything)
+	})
+}
+
+func TestDeliver(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("signs request and records success", func(t *testing.T) {
+		d, m := newTestDomain(nil)
+		endpoint := newTestEndpoint(uuid.New(), model.WebhookAllEvents)
+		delivery := newTestDelivery(endpoint.ID)
+		m.deliveryDB.On("FindByID", ctx, delivery.ID).Return(delivery, nil)
+		m.endpointDB.On("FindByID", ctx, endpoint.ID).Return(endpoint, nil)
+		m.sender.On("Send", ctx, endpoint.URL, mock.Anything, []byte(delivery.Payload)).
+			Run(func(args mock.Arguments) {
+				headers := args.Get(2).(map[string]string)
+				ts, err := strconv.ParseInt(headers[HeaderTimestamp], 10, 64)
+				assert.NoError(t, err)
+				assert.Equal(t, Sign("whsec_test", ts, []byte(delivery.Payload)), headers[HeaderSignature])
+				assert.Equal(t, delivery.EventID.String(), headers[HeaderEventID])
+			}).
+			Return(200, []byte("ok"), nil)
+		m.deliveryDB.On("Update", ctx, delivery).Return(nil)
+		m.endpointDB.On("RecordSuccess", ctx, endpoint.ID).Return(nil)
+
+		err := d.Deliver(ctx, delivery.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.WebhookDeliveryStatusSucceeded, delivery.Status)
+		assert.NotNil(t, delivery.DeliveredAt)
+		assert.Len(t, delivery.Attempts, 1)
+		assert.Equal(t, 200, delivery.Attempts[0].StatusCode)
+		assert.Equal(t, "ok", delivery.Attempts[0].ResponseBody)
+	})
+
+	t.Run("failed attempt is retryable", func(t *testing.T) {
+		d, m := newTestDomain(nil)
+		endpoint := newTestEndpoint(uuid.New(), model.WebhookAllEvents)
+		delivery := newTestDelivery(endpoint.ID)
+		m.deliveryDB.On("FindByID", ctx, delivery.ID).Return(delivery, nil)
+		m.endpointDB.On("FindByID", ctx, endpoint.ID).Return(endpoint, nil)
+		m.sender.On("Send", ctx, endpoint.URL, mock.Anything, mock.Anything).Return(500, []byte("boom"), nil)
+		m.endpointDB.On("RecordFailure", ctx, endpoint.ID).Return(1, nil)
+		m.deliveryDB.On("Update", ctx, delivery).Return(nil)
+
+		err := d.Deliver(ctx, delivery.ID)
+
+		assert.ErrorIs(t, err, ErrDeliveryFailed)
+		assert.Equal(t, model.WebhookDeliveryStatusPending, delivery.Status)
+		assert.Equal(t, 500, delivery.ResponseStatus)
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		d, m := newTestDomain(&Config{MaxAttempts: 2, DisableAfterFailures: 100, MaxResponseBodyBytes: 10})
+		endpoint := newTestEndpoint(uuid.New(), model.WebhookAllEvents)
+		delivery := newTestDelivery(endpoint.ID)
+		delivery.Attempts = []model.WebhookDeliveryAttempt{{Number: 1, Error: "timeout"}}
+		m.deliveryDB.On("FindByID", ctx, delivery.ID).Return(delivery, nil)
+		m.endpointDB.On("FindByID", ctx, endpoint.ID).Return(endpoint, nil)
+		m.sender.On("Send", ctx, endpoint.URL, mock.Anything, mock.Anything).
+			Return(0, nil, errors.New("connection refused"))
+		m.endpointDB.On("RecordFailure", ctx, endpoint.ID).Return(2, nil)
+		m.deliveryDB.On("Update", ctx, delivery).Return(nil)
+
+		err := d.Deliver(ctx, delivery.ID)
+
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrDeliveryFailed)
+		assert.Equal(t, model.WebhookDeliveryStatusFailed, delivery.Status)
+		assert.Equal(t, "connection refused", delivery.Attempts[1].Error)
+	})
+
+	t.Run("disables endpoint after repeated failures", func(t *testing.T) {
+		d, m := newTestDomain(nil)
+		endpoint := newTestEndpoint(uuid.New(), model.WebhookAllEvents)
+		delivery := newTestDelivery(endpoint.ID)
+		m.deliveryDB.On("FindByID", ctx, delivery.ID).Return(delivery, nil)
+		m.endpointDB.On("FindByID", ctx, endpoint.ID).Return(endpoint, nil)
+		m.sender.On("Send", ctx, endpoint.URL, mock.Anything, mock.Anything).Return(404, nil, nil)
+		m.endpointDB.On("RecordFailure", ctx, endpoint.ID).Return(20, nil)
+		m.endpointDB.On("Disable", ctx, endpoint.ID, mock.AnythingOfType("string")).Return(nil)
+		m.deliveryDB.On("Update", ctx, delivery).Return(nil)
+
+		err := d.Deliver(ctx, delivery.ID)
+
+		assert.ErrorIs(t, err, ErrEndpointDisabled)
+		assert.NotErrorIs(t, err, ErrDeliveryFailed)
+		assert.Equal(t, model.WebhookDeliveryStatusFailed, delivery.Status)
+		m.endpointDB.AssertCalled(t, "Disable", ctx, endpoint.ID, mock.AnythingOfType("string"))
+	})
+
+	t.Run("skips finished deliveries", func(t *testing.T) {
+		d, m := newTestDomain(nil)
+		delivery := newTestDelivery(uuid.New())
+		delivery.Status = model.WebhookDeliveryStatusSucceeded
+		m.deliveryDB.On("FindByID", ctx, delivery.ID).Return(delivery, nil)
+
+		assert.NoError(t, d.Deliver(ctx, delivery.ID))
+		m.sender.AssertNotCalled(t, "Send", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestRedeliver(t *testing.T) {
+	ctx := context.Background()
+	d, m := newTestDomain(nil)
+	endpoint := newTestEndpoint(uuid.New(), model.WebhookAllEvents)
+	original := newTestDelivery(endpoint.ID)
+	original.Status = model.WebhookDeliveryStatusFailed
+	m.endpointDB.On("FindByID", ctx, endpoint.ID).Return(endpoint, nil)
+	m.deliveryDB.On("FindByID", ctx, original.ID).Return(original, nil)
+	m.deliveryDB.On("Create", ctx, mock.Anything).Return(true, nil)
+	m.queue.On("Enqueue", ctx, mock.Anything).Return(nil)
+
+	delivery, err := d.Redeliver(ctx, endpoint.OwnerID, endpoint.ID, original.ID)
+
+	assert.NoError(t, err)
+	assert.NotEqual(t, original.ID, delivery.ID)
+	assert.Equal(t, original.EventID, delivery.EventID)
+	assert.Equal(t, original.Payload, delivery.Payload)
+	assert.Equal(t, &original.ID, delivery.RedeliveryOf)
+	assert.Equal(t, model.WebhookDeliveryStatusPending, delivery.Status)
+	m.queue.AssertCalled(t, "Enqueue", ctx, delivery.ID)
+}
+
+func TestSign(t *testing.T) {
+	sig := Sign("secret", 1700000000, []byte(`{}`))
+
+	assert.Equal(t, "sha256=", sig[:7])
+	assert.Len(t, sig, 7+64)
+	assert.Equal(t, sig, Sign("secret", 1700000000, []byte(`{}`)))
+	assert.NotEqual(t, sig, Sign("secret", 1700000001, []byte(`{}`)))
+	assert.NotEqual(t, sig, Sign("other", 1700000000, []byte(`{}`)))
+}
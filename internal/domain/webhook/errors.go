@@ -0,0 +1,29 @@
+package webhook
+
+import "errors"
+
+var (
+	// ErrEndpointNotFound is returned when an endpoint does not exist or
+	// belongs to another user.
+	ErrEndpointNotFound = errors.New("webhook endpoint not found")
+
+	// ErrDeliveryNotFound is returned when a delivery does not exist.
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+
+	// ErrEndpointDisabled is returned when delivering to a disabled endpoint.
+	ErrEndpointDisabled = errors.New("webhook endpoint is disabled")
+
+	// ErrTooManyEndpoints is returned when the user has no endpoints left.
+	ErrTooManyEndpoints = errors.New("webhook endpoint limit reached")
+
+	// ErrInvalidURL is returned for endpoint URLs that are not absolute
+	// HTTP(S) URLs.
+	ErrInvalidURL = errors.New("invalid webhook URL")
+
+	// ErrInvalidEventType is returned for unknown event types.
+	ErrInvalidEventType = errors.New("invalid webhook event type")
+
+	// ErrDeliveryFailed is returned when an attempt fails and the delivery
+	// will be retried. Other delivery errors are final.
+	ErrDeliveryFailed = errors.New("webhook delivery failed")
+)
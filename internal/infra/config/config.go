@@ -121,6 +121,14 @@ type AIConfig struct {
 	AccountPoolScheduler     string        `mapstructure:"account_pool_scheduler"`      // round_robin, weighted, priority
 	AccountPoolCacheTTL      time.Duration `mapstructure:"account_pool_cache_ttl"`
 	AccountPoolEncryptionKey string        `mapstructure:"account_pool_encryption_key"` // Base64 encoded 32-byte key
+
+	// Request audit log configuration
+	RequestLogEnabled   bool          `mapstructure:"request_log_enabled"`   // Store encrypted request/response bodies
+	RequestLogRetention time.Duration `mapstructure:"request_log_retention"` // How long to keep request logs
+
+	// Image input configuration
+	ImageMaxBytes     int64         `mapstructure:"image_max_bytes"`     // Max size of a single input image
+	ImageFetchTimeout time.Duration `mapstructure:"image_fetch_timeout"` // Timeout for downloading remote images
 }
 
 // AuthConfig holds authentication configuration.
@@ -160,6 +168,9 @@ type GitConfig struct {
 	LFSPrefix      string        `mapstructure:"lfs_prefix"`        // R2 prefix for LFS objects (default: "lfs/")
 	LFSURLExpiry   time.Duration `mapstructure:"lfs_url_expiry"`    // Presigned URL expiry (default: 1h)
 	LFSMaxFileSize int64         `mapstructure:"lfs_max_file_size"` // Max LFS file size in bytes (default: 100GB)
+	LFSProxy       bool          `mapstructure:"lfs_proxy"`         // Stream LFS objects through the server instead of presigned URLs (default: false)
+	LFSThreshold   int64         `mapstructure:"lfs_threshold"`     // Files committed through the API from this size go to LFS (default: 1MB)
+	MaxFileSize    int64         `mapstructure:"max_file_size"`     // Largest file a push may add outside LFS (default: 100MB)
 }
 
 // LogConfig holds logging configuration.
@@ -366,6 +377,10 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("ai.embedding_cache_ttl", 24*time.Hour)
 	v.SetDefault("ai.account_pool_scheduler", "round_robin")
 	v.SetDefault("ai.account_pool_cache_ttl", 5*time.Minute)
+	v.SetDefault("ai.request_log_enabled", false)
+	v.SetDefault("ai.request_log_retention", 7*24*time.Hour)
+	v.SetDefault("ai.image_max_bytes", 20<<20)
+	v.SetDefault("ai.image_fetch_timeout", 10*time.Second)
 
 	// Auth defaults
 	v.SetDefault("auth.access_token_expiry", 15*time.Minute)
@@ -385,6 +400,9 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("git.lfs_prefix", "lfs/")
 	v.SetDefault("git.lfs_url_expiry", time.Hour)
 	v.SetDefault("git.lfs_max_file_size", 100*1024*1024*1024) // 100GB
+	v.SetDefault("git.lfs_proxy", false)
+	v.SetDefault("git.lfs_threshold", 1024*1024)     // 1MB
+	v.SetDefault("git.max_file_size", 100*1024*1024) // 100MB
 
 	// Feature flags defaults
 	v.SetDefault("features.use_new_architecture", true)
@@ -1,6 +1,10 @@
 package events
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // Payment event type constants.
 const (
@@ -8,6 +12,16 @@ const (
 	PaymentFailedType    = "PaymentFailed"
 )
 
+// Subscription event type constants.
+const (
+	SubscriptionUpdatedType = "SubscriptionUpdated"
+)
+
+// Repository event type constants.
+const (
+	RepoPushedType = "RepoPushed"
+)
+
 // Order type constants for event handlers.
 const (
 	OrderTypeTopup        = "topup"
@@ -108,3 +122,84 @@ func NewPaymentFailedEvent(
 		Provider:       provider,
 	}
 }
+
+// SubscriptionUpdatedEvent is emitted when a subscription is created,
+// cancelled or changed by the payment provider.
+type SubscriptionUpdatedEvent struct {
+	BaseEvent
+
+	// SubscriptionID is the unique identifier of the subscription.
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+
+	// UserID is the ID of the subscribed user.
+	UserID uuid.UUID `json:"user_id"`
+
+	// PlanID is the subscribed plan.
+	PlanID string `json:"plan_id"`
+
+	// Status is the subscription status after the change.
+	Status string `json:"status"`
+
+	// CurrentPeriodEnd is when the current billing period ends.
+	CurrentPeriodEnd time.Time `json:"current_period_end"`
+
+	// CancelAtPeriodEnd is set when the subscription ends with the period.
+	CancelAtPeriodEnd bool `json:"cancel_at_period_end"`
+}
+
+// NewSubscriptionUpdatedEvent creates a new SubscriptionUpdatedEvent.
+func NewSubscriptionUpdatedEvent(
+	subscriptionID, userID uuid.UUID,
+	planID, status string,
+	currentPeriodEnd time.Time,
+	cancelAtPeriodEnd bool,
+) *SubscriptionUpdatedEvent {
+	return &SubscriptionUpdatedEvent{
+		BaseEvent:         NewBaseEvent(SubscriptionUpdatedType, subscriptionID, "Subscription"),
+		SubscriptionID:    subscriptionID,
+		UserID:            userID,
+		PlanID:            planID,
+		Status:            status,
+		CurrentPeriodEnd:  currentPeriodEnd,
+		CancelAtPeriodEnd: cancelAtPeriodEnd,
+	}
+}
+
+// RepoRefChange is one ref moved by a push. A zero OldSHA means the ref was
+// created and a zero NewSHA that it was deleted.
+type RepoRefChange struct {
+	Ref    string `json:"ref"`
+	OldSHA string `json:"old_sha"`
+	NewSHA string `json:"new_sha"`
+}
+
+// RepoPushedEvent is emitted after a push to a git repository is applied.
+type RepoPushedEvent struct {
+	BaseEvent
+
+	// RepoID is the ID of the repository pushed to.
+	RepoID uuid.UUID `json:"repo_id"`
+
+	// OwnerID is the ID of the repository owner.
+	OwnerID uuid.UUID `json:"owner_id"`
+
+	// PusherID is the ID of the user who pushed.
+	PusherID uuid.UUID `json:"pusher_id"`
+
+	// Refs lists the refs the push moved.
+	Refs []RepoRefChange `json:"refs"`
+}
+
+// NewRepoPushedEvent creates a new RepoPushedEvent.
+func NewRepoPushedEvent(
+	repoID, ownerID, pusherID uuid.UUID,
+	refs []RepoRefChange,
+) *RepoPushedEvent {
+	return &RepoPushedEvent{
+		BaseEvent: NewBaseEvent(RepoPushedType, repoID, "Repository"),
+		RepoID:    repoID,
+		OwnerID:   ownerID,
+		PusherID:  pusherID,
+		Refs:      refs,
+	}
+}
@@ -2,7 +2,9 @@ package task
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -10,6 +12,10 @@ import (
 	"go.uber.org/zap"
 )
 
+// subscriberQueueSize is how many updates may wait for a SubscribeAll
+// callback before further ones are dropped.
+const subscriberQueueSize = 256
+
 // Executor defines the function signature for task executors.
 // Executors should update task output via the provided callback.
 type Executor func(ctx context.Context, task *Task, onProgress func(progress int, output map[string]any)) error
@@ -25,24 +31,32 @@ type ExternalTaskPoller interface {
 type Manager struct {
 	mu sync.RWMutex
 
-	repo      Repository
-	executors map[string]Executor
-	pollers   map[string]ExternalTaskPoller
-	logger    *zap.Logger
+	repo          Repository
+	executors     map[string]Executor
+	pollers       map[string]ExternalTaskPoller
+	retryPolicies map[string]*RetryPolicy
+	logger        *zap.Logger
 
 	// Configuration
-	config *Config
+	config   *Config
+	workerID string
 
-	// Concurrency control
-	semaphore     chan struct{}
-	maxConcurrent int
+	// Concurrency control: tasks this worker currently holds, by type
+	active      map[string]int
+	activeTotal int
+	leases      map[uuid.UUID]context.CancelFunc
 
-	// Progress subscriptions
-	subscribers map[uuid.UUID][]func(*Task)
+	// Progress subscriptions, keyed by task and then by subscription
+	subscribers    map[uuid.UUID]map[uint64]func(*Task)
+	allSubscribers map[uint64]chan *Task
+	nextSubID      uint64
 
 	// Lifecycle
-	stopCh chan struct{}
-	wg     sync.WaitGroup
+	runCtx    context.Context
+	runCancel context.CancelFunc
+	wakeCh    chan struct{}
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
 }
 
 // Config contains manager configuration.
@@ -51,16 +65,43 @@ type Config struct {
 	PollInterval    time.Duration `json:"poll_interval" yaml:"poll_interval"`
 	PollTimeout     time.Duration `json:"poll_timeout" yaml:"poll_timeout"`
 	MaxPollAttempts int           `json:"max_poll_attempts" yaml:"max_poll_attempts"`
+
+	// WorkerID identifies this replica in task leases. Defaults to hostname-pid.
+	WorkerID string `json:"worker_id" yaml:"worker_id"`
+	// LeaseDuration is how long a claimed task stays owned without a heartbeat.
+	LeaseDuration time.Duration `json:"lease_duration" yaml:"lease_duration"`
+	// HeartbeatInterval is how often held leases are renewed.
+	HeartbeatInterval time.Duration `json:"heartbeat_interval" yaml:"heartbeat_interval"`
+	// ClaimInterval is how often the queue is checked for claimable tasks.
+	ClaimInterval time.Duration `json:"claim_interval" yaml:"claim_interval"`
+	// TypeConcurrency caps concurrent tasks per type on this worker.
+	// Types without an entry are limited only by MaxConcurrent.
+	TypeConcurrency map[string]int `json:"type_concurrency" yaml:"type_concurrency"`
+	// RetryPolicy applies to task types without a registered policy.
+	RetryPolicy *RetryPolicy `json:"-" yaml:"-"`
 }
 
 // DefaultConfig returns the default manager configuration.
 func DefaultConfig() *Config {
 	return &Config{
-		MaxConcurrent:   10,
-		PollInterval:    5 * time.Second,
-		PollTimeout:     30 * time.Minute,
-		MaxPollAttempts: 360, // 30 minutes at 5 second intervals
+		MaxConcurrent:     10,
+		PollInterval:      5 * time.Second,
+		PollTimeout:       30 * time.Minute,
+		MaxPollAttempts:   360, // 30 minutes at 5 second intervals
+		LeaseDuration:     30 * time.Second,
+		HeartbeatInterval: 10 * time.Second,
+		ClaimInterval:     time.Second,
+		RetryPolicy:       DefaultRetryPolicy(),
+	}
+}
+
+// defaultWorkerID returns an identifier unique to this process.
+func defaultWorkerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "worker"
 	}
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8])
 }
 
 // NewManager creates a new task manager.
@@ -71,47 +112,70 @@ func NewManager(repo Repository, logger *zap.Logger, config *Config) *Manager {
 	if logger == nil {
 		logger = zap.NewNop()
 	}
+	workerID := config.WorkerID
+	if workerID == "" {
+		workerID = defaultWorkerID()
+	}
+
+	runCtx, runCancel := context.WithCancel(context.Background())
 
 	return &Manager{
-		repo:          repo,
-		executors:     make(map[string]Executor),
-		pollers:       make(map[string]ExternalTaskPoller),
-		logger:        logger.Named("task-manager"),
-		config:        config,
-		semaphore:     make(chan struct{}, config.MaxConcurrent),
-		maxConcurrent: config.MaxConcurrent,
-		subscribers:   make(map[uuid.UUID][]func(*Task)),
-		stopCh:        make(chan struct{}),
+		repo:           repo,
+		executors:      make(map[string]Executor),
+		pollers:        make(map[string]ExternalTaskPoller),
+		retryPolicies:  make(map[string]*RetryPolicy),
+		logger:         logger.Named("task-manager"),
+		config:         config,
+		workerID:       workerID,
+		active:         make(map[string]int),
+		leases:         make(map[uuid.UUID]context.CancelFunc),
+		subscribers:    make(map[uuid.UUID]map[uint64]func(*Task)),
+		allSubscribers: make(map[uint64]chan *Task),
+		runCtx:         runCtx,
+		runCancel:      runCancel,
+		wakeCh:         make(chan struct{}, 1),
+		stopCh:         make(chan struct{}),
 	}
 }
 
-// Start starts the task manager and recovers pending tasks.
+// Start starts claiming tasks from the shared queue. Pending tasks, and
+// running tasks whose worker stopped renewing its lease, are picked up by
+// whichever replica claims them first.
 func (m *Manager) Start(ctx context.Context) error {
 	m.logger.Info("starting task manager",
+		zap.String("worker_id", m.workerID),
 		zap.Int("max_concurrent", m.config.MaxConcurrent),
+		zap.Duration("lease_duration", m.config.LeaseDuration),
 		zap.Duration("poll_interval", m.config.PollInterval))
 
-	// Recover pending tasks
-	if err := m.recoverPendingTasks(ctx); err != nil {
-		return fmt.Errorf("recover pending tasks: %w", err)
-	}
-
-	// Recover external polling tasks
-	if err := m.recoverExternalTasks(ctx); err != nil {
-		return fmt.Errorf("recover external tasks: %w", err)
-	}
+	m.wg.Add(2)
+	go m.claimLoop()
+	go m.heartbeatLoop()
 
 	return nil
 }
 
-// Stop stops the task manager gracefully.
+// Stop stops the task manager gracefully. Tasks still running are
+// interrupted and their leases released so another replica resumes them.
 func (m *Manager) Stop() {
 	m.logger.Info("stopping task manager")
 	close(m.stopCh)
+	m.runCancel()
 	m.wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := m.repo.ReleaseLeases(ctx, m.workerID); err != nil {
+		m.logger.Warn("failed to release task leases", zap.Error(err))
+	}
 	m.logger.Info("task manager stopped")
 }
 
+// WorkerID returns the identifier this manager uses for task leases.
+func (m *Manager) WorkerID() string {
+	return m.workerID
+}
+
 // RegisterExecutor registers a task executor for a specific task type.
 func (m *Manager) RegisterExecutor(taskType string, executor Executor) {
 	m.mu.Lock()
@@ -128,6 +192,24 @@ func (m *Manager) RegisterPoller(taskType string, poller ExternalTaskPoller) {
 	m.logger.Debug("registered poller", zap.String("task_type", taskType))
 }
 
+// RegisterRetryPolicy sets the retry policy for a task type.
+func (m *Manager) RegisterRetryPolicy(taskType string, policy *RetryPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.retryPolicies[taskType] = policy
+}
+
+// retryPolicy returns the policy for a task type, falling back to the
+// configured default. A nil result means failures are never retried.
+func (m *Manager) retryPolicy(taskType string) *RetryPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if policy, ok := m.retryPolicies[taskType]; ok {
+		return policy
+	}
+	return m.config.RetryPolicy
+}
+
 // Submit submits a new task for immediate execution.
 func (m *Manager) Submit(ctx context.Context, ownerID uuid.UUID, req *SubmitRequest) (*Task, error) {
 	task := &Task{
@@ -151,9 +233,8 @@ func (m *Manager) Submit(ctx context.Context, ownerID uuid.UUID, req *SubmitRequ
 		zap.String("type", task.Type),
 		zap.String("owner_id", ownerID.String()))
 
-	// Start execution in background
-	m.wg.Add(1)
-	go m.executeTask(task)
+	// Any replica may claim it; check the queue now rather than on the next tick
+	m.wake()
 
 	return task, nil
 }
@@ -173,7 +254,7 @@ func (m *Manager) SubmitExternal(ctx context.Context, ownerID uuid.UUID, req *Ex
 		UpdatedAt:      time.Now(),
 	}
 
-	if err := m.repo.Create(ctx, task); err != nil {
+	if err := m.repo.CreateLeased(ctx, task, m.workerID, m.config.LeaseDuration); err != nil {
 		return nil, fmt.Errorf("create task: %w", err)
 	}
 
@@ -183,8 +264,7 @@ func (m *Manager) SubmitExternal(ctx context.Context, ownerID uuid.UUID, req *Ex
 		zap.String("external_task_id", req.ExternalTaskID))
 
 	// Start polling in background
-	m.wg.Add(1)
-	go m.pollExternalTask(task)
+	m.run(task)
 
 	return task, nil
 }
@@ -226,26 +306,57 @@ func (m *Manager) Cancel(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// ListDead lists tasks whose retries are exhausted.
+func (m *Manager) ListDead(ctx context.Context, filter *Filter) ([]*Task, error) {
+	if filter == nil {
+		filter = &Filter{}
+	}
+	status := StatusDead
+	filter.Status = &status
+	if filter.OrderBy == "" {
+		filter.OrderBy = "updated_at"
+	}
+	return m.repo.List(ctx, filter)
+}
+
+// Requeue gives a dead task a fresh attempt budget and queues it again.
+func (m *Manager) Requeue(ctx context.Context, id uuid.UUID) (*Task, error) {
+	task, err := m.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != StatusDead {
+		return nil, ErrTaskNotDead
+	}
+
+	if err := m.repo.Requeue(ctx, id); err != nil {
+		return nil, err
+	}
+
+	m.logger.Info("dead task requeued", zap.String("task_id", id.String()))
+	m.wake()
+	return m.repo.Get(ctx, id)
+}
+
 // Subscribe subscribes to task progress updates.
 // Returns an unsubscribe function.
 func (m *Manager) Subscribe(id uuid.UUID, callback func(*Task)) func() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	m.subscribers[id] = append(m.subscribers[id], callback)
+	m.nextSubID++
+	subID := m.nextSubID
+	if m.subscribers[id] == nil {
+		m.subscribers[id] = make(map[uint64]func(*Task))
+	}
+	m.subscribers[id][subID] = callback
 
 	// Return unsubscribe function
 	return func() {
 		m.mu.Lock()
 		defer m.mu.Unlock()
 
-		subs := m.subscribers[id]
-		for i, sub := range subs {
-			if &sub == &callback {
-				m.subscribers[id] = append(subs[:i], subs[i+1:]...)
-				break
-			}
-		}
+		delete(m.subscribers[id], subID)
 		// Clean up empty subscriber lists
 		if len(m.subscribers[id]) == 0 {
 			delete(m.subscribers, id)
@@ -253,74 +364,226 @@ func (m *Manager) Subscribe(id uuid.UUID, callback func(*Task)) func() {
 	}
 }
 
-// recoverPendingTasks recovers pending tasks after restart.
-func (m *Manager) recoverPendingTasks(ctx context.Context) error {
-	tasks, err := m.repo.ListPendingOrRunning(ctx)
-	if err != nil {
-		return fmt.Errorf("list pending tasks: %w", err)
+// SubscribeAll subscribes to updates of every task this manager runs.
+// Each subscriber gets copies of the updates in order on its own goroutine,
+// so callbacks may block, e.g. on I/O. Updates are dropped while
+// subscriberQueueSize of them are waiting for a slow callback.
+// Returns an unsubscribe function.
+func (m *Manager) SubscribeAll(callback func(*Task)) func() {
+	queue := make(chan *Task, subscriberQueueSize)
+	go func() {
+		for task := range queue {
+			callback(task)
+		}
+	}()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextSubID++
+	subID := m.nextSubID
+	m.allSubscribers[subID] = queue
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			delete(m.allSubscribers, subID)
+			close(queue)
+		})
 	}
+}
 
-	m.logger.Info("recovering pending tasks", zap.Int("count", len(tasks)))
+// wake nudges the claim loop without blocking.
+func (m *Manager) wake() {
+	select {
+	case m.wakeCh <- struct{}{}:
+	default:
+	}
+}
 
-	for _, task := range tasks {
-		// Skip tasks with external IDs (handled by recoverExternalTasks)
-		if task.ExternalTaskID != "" {
+// claimLoop claims tasks for registered types until the manager stops.
+func (m *Manager) claimLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.config.ClaimInterval)
+	defer ticker.Stop()
+
+	for {
+		m.claimTasks()
+
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+		case <-m.wakeCh:
+		}
+	}
+}
+
+// claimTasks claims as many tasks per type as this worker has free slots.
+func (m *Manager) claimTasks() {
+	for _, taskType := range m.taskTypes() {
+		free := m.freeSlots(taskType)
+		if free == 0 {
 			continue
 		}
 
-		// Reset running tasks to pending
-		if task.Status == StatusRunning {
-			task.Status = StatusPending
-			task.UpdatedAt = time.Now()
-			if err := m.repo.Update(ctx, task); err != nil {
-				m.logger.Warn("failed to reset task status",
-					zap.String("task_id", task.ID.String()),
+		tasks, err := m.repo.Claim(m.runCtx, m.workerID, taskType, free, m.config.LeaseDuration)
+		if err != nil {
+			if m.runCtx.Err() == nil {
+				m.logger.Error("failed to claim tasks",
+					zap.String("task_type", taskType),
 					zap.Error(err))
-				continue
 			}
+			continue
 		}
 
-		// Re-queue for execution
-		m.wg.Add(1)
-		go m.executeTask(task)
+		for _, task := range tasks {
+			m.logger.Debug("task claimed",
+				zap.String("task_id", task.ID.String()),
+				zap.String("type", task.Type))
+			m.run(task)
+		}
 	}
-
-	return nil
 }
 
-// recoverExternalTasks recovers external tasks that were polling when server stopped.
-func (m *Manager) recoverExternalTasks(ctx context.Context) error {
-	tasks, err := m.repo.ListByExternalTaskID(ctx)
-	if err != nil {
-		return fmt.Errorf("list external tasks: %w", err)
+// taskTypes returns the types this worker can execute or poll.
+func (m *Manager) taskTypes() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	types := make([]string, 0, len(m.executors)+len(m.pollers))
+	seen := make(map[string]bool, cap(types))
+	for t := range m.executors {
+		seen[t] = true
+		types = append(types, t)
+	}
+	for t := range m.pollers {
+		if !seen[t] {
+			types = append(types, t)
+		}
 	}
+	return types
+}
 
-	m.logger.Info("recovering external tasks", zap.Int("count", len(tasks)))
+// freeSlots returns how many more tasks of a type this worker may run.
+func (m *Manager) freeSlots(taskType string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 
-	for _, task := range tasks {
-		if task.Status == StatusRunning && task.ExternalTaskID != "" {
-			// Resume polling
-			m.wg.Add(1)
-			go m.pollExternalTask(task)
-		}
+	free := m.config.MaxConcurrent - m.activeTotal
+	if limit, ok := m.config.TypeConcurrency[taskType]; ok && limit-m.active[taskType] < free {
+		free = limit - m.active[taskType]
 	}
+	return max(free, 0)
+}
 
-	return nil
+// run executes or polls a task held under this worker's lease.
+func (m *Manager) run(task *Task) {
+	ctx, cancel := context.WithCancel(m.runCtx)
+
+	m.mu.Lock()
+	m.active[task.Type]++
+	m.activeTotal++
+	m.leases[task.ID] = cancel
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer func() {
+			cancel()
+			m.mu.Lock()
+			m.active[task.Type]--
+			m.activeTotal--
+			delete(m.leases, task.ID)
+			m.mu.Unlock()
+			m.wake()
+		}()
+
+		if task.ExternalTaskID != "" {
+			m.pollExternalTask(ctx, task)
+		} else {
+			m.executeTask(ctx, task)
+		}
+	}()
 }
 
-// executeTask executes a task.
-func (m *Manager) executeTask(task *Task) {
+// heartbeatLoop renews the leases of all held tasks. A task whose lease
+// cannot be renewed (reclaimed elsewhere or cancelled) is interrupted.
+func (m *Manager) heartbeatLoop() {
 	defer m.wg.Done()
 
-	// Acquire semaphore
-	select {
-	case <-m.stopCh:
-		return
-	case m.semaphore <- struct{}{}:
-		defer func() { <-m.semaphore }()
+	ticker := time.NewTicker(m.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopCh:
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.RLock()
+		held := make(map[uuid.UUID]context.CancelFunc, len(m.leases))
+		for id, cancel := range m.leases {
+			held[id] = cancel
+		}
+		m.mu.RUnlock()
+
+		for id, cancel := range held {
+			err := m.repo.RenewLease(m.runCtx, id, m.workerID, m.config.LeaseDuration)
+			if errors.Is(err, ErrLeaseLost) {
+				m.logger.Warn("task lease lost, stopping task", zap.String("task_id", id.String()))
+				cancel()
+			} else if err != nil && m.runCtx.Err() == nil {
+				m.logger.Warn("failed to renew task lease",
+					zap.String("task_id", id.String()),
+					zap.Error(err))
+			}
+		}
 	}
+}
 
-	ctx := context.Background()
+// saveTask persists a task this worker holds the lease for.
+func (m *Manager) saveTask(ctx context.Context, task *Task) error {
+	if task.LeaseOwner == "" {
+		return m.repo.Update(ctx, task)
+	}
+	return m.repo.UpdateLeased(ctx, task, m.workerID)
+}
+
+// saveProgress persists the progress of a task this worker holds. If the
+// lease was lost in the meantime the task is interrupted and false is
+// returned, leaving the task to its new owner.
+func (m *Manager) saveProgress(ctx context.Context, task *Task) bool {
+	err := m.saveTask(ctx, task)
+	if errors.Is(err, ErrLeaseLost) {
+		m.logger.Warn("task lease lost, stopping task", zap.String("task_id", task.ID.String()))
+		m.mu.RLock()
+		cancel, ok := m.leases[task.ID]
+		m.mu.RUnlock()
+		if ok {
+			cancel()
+		}
+		return false
+	}
+	if err != nil {
+		m.logger.Warn("failed to update task progress",
+			zap.String("task_id", task.ID.String()),
+			zap.Error(err))
+	}
+	return true
+}
+
+// executeTask executes a claimed task. ctx is cancelled when the manager
+// stops or the lease is lost, in which case the task is left for the next
+// owner instead of being failed.
+func (m *Manager) executeTask(ctx context.Context, task *Task) {
+	// Status writes must outlive ctx so a finished task is recorded
+	dbCtx := context.WithoutCancel(ctx)
 
 	// Get executor
 	m.mu.RLock()
@@ -328,20 +591,21 @@ func (m *Manager) executeTask(task *Task) {
 	m.mu.RUnlock()
 
 	if !ok {
-		m.failTask(ctx, task, "unknown_task_type", "no executor registered for task type: "+task.Type)
+		m.failTask(dbCtx, task, "unknown_task_type", "no executor registered for task type: "+task.Type)
 		return
 	}
 
-	// Update status to running
-	task.Status = StatusRunning
-	task.UpdatedAt = time.Now()
-	if err := m.repo.Update(ctx, task); err != nil {
-		m.logger.Error("failed to update task status",
-			zap.String("task_id", task.ID.String()),
-			zap.Error(err))
+	// A task reclaimed after repeated worker crashes would otherwise loop forever
+	policy := m.retryPolicy(task.Type)
+	if policy != nil && policy.MaxAttempts > 0 && task.Attempts > policy.MaxAttempts {
+		m.endTask(dbCtx, task, StatusDead, "retries_exhausted",
+			fmt.Sprintf("task was claimed %d times without finishing", task.Attempts))
 		return
 	}
+
+	// Claim already marked the task running
 	m.notifySubscribers(task)
+	startedAt := time.Now()
 
 	// Execute with progress callback
 	onProgress := func(progress int, output map[string]any) {
@@ -350,23 +614,30 @@ func (m *Manager) executeTask(task *Task) {
 			task.Output = output
 		}
 		task.UpdatedAt = time.Now()
-		_ = m.repo.UpdateStatus(ctx, task.ID, task.Status, progress)
-		m.notifySubscribers(task)
+		if m.saveProgress(dbCtx, task) {
+			m.notifySubscribers(task)
+		}
 	}
 
 	if err := executor(ctx, task, onProgress); err != nil {
-		m.failTask(ctx, task, "execution_failed", err.Error())
+		if ctx.Err() != nil {
+			m.logger.Debug("task interrupted", zap.String("task_id", task.ID.String()))
+			return
+		}
+		m.handleFailure(dbCtx, task, startedAt, err)
 		return
 	}
 
 	// Complete
+	m.recordAttempt(task, startedAt, nil)
 	task.Status = StatusCompleted
 	task.Progress = 100
 	now := time.Now()
 	task.CompletedAt = &now
 	task.UpdatedAt = now
+	task.LeaseExpiresAt = nil
 
-	if err := m.repo.Update(ctx, task); err != nil {
+	if err := m.saveTask(dbCtx, task); err != nil {
 		m.logger.Error("failed to update completed task",
 			zap.String("task_id", task.ID.String()),
 			zap.Error(err))
@@ -377,11 +648,10 @@ func (m *Manager) executeTask(task *Task) {
 	m.notifySubscribers(task)
 }
 
-// pollExternalTask polls an external task until completion.
-func (m *Manager) pollExternalTask(task *Task) {
-	defer m.wg.Done()
-
-	ctx := context.Background()
+// pollExternalTask polls an external task until completion or until ctx is
+// cancelled, in which case another worker resumes polling.
+func (m *Manager) pollExternalTask(ctx context.Context, task *Task) {
+	dbCtx := context.WithoutCancel(ctx)
 
 	// Get poller for task type
 	m.mu.RLock()
@@ -389,7 +659,7 @@ func (m *Manager) pollExternalTask(task *Task) {
 	m.mu.RUnlock()
 
 	if !ok {
-		m.failTask(ctx, task, "unknown_poller", "no poller registered for task type: "+task.Type)
+		m.failTask(dbCtx, task, "unknown_poller", "no poller registered for task type: "+task.Type)
 		return
 	}
 
@@ -403,17 +673,17 @@ func (m *Manager) pollExternalTask(task *Task) {
 
 	for {
 		select {
-		case <-m.stopCh:
+		case <-ctx.Done():
 			return
 
 		case <-timeout.C:
-			m.failTask(ctx, task, "timeout", "task polling timed out")
+			m.failTask(dbCtx, task, "timeout", "task polling timed out")
 			return
 
 		case <-ticker.C:
 			attempts++
 			if attempts > m.config.MaxPollAttempts {
-				m.failTask(ctx, task, "max_attempts", "exceeded maximum poll attempts")
+				m.failTask(dbCtx, task, "max_attempts", "exceeded maximum poll attempts")
 				return
 			}
 
@@ -433,7 +703,9 @@ func (m *Manager) pollExternalTask(task *Task) {
 			if progress != task.Progress {
 				task.Progress = progress
 				task.UpdatedAt = time.Now()
-				_ = m.repo.UpdateStatus(ctx, task.ID, task.Status, progress)
+				if !m.saveProgress(dbCtx, task) {
+					return
+				}
 				m.notifySubscribers(task)
 			}
 
@@ -445,8 +717,9 @@ func (m *Manager) pollExternalTask(task *Task) {
 				now := time.Now()
 				task.CompletedAt = &now
 				task.UpdatedAt = now
+				task.LeaseExpiresAt = nil
 
-				if err := m.repo.Update(ctx, task); err != nil {
+				if err := m.saveTask(dbCtx, task); err != nil {
 					m.logger.Error("failed to update completed external task",
 						zap.String("task_id", task.ID.String()),
 						zap.Error(err))
@@ -463,16 +736,73 @@ func (m *Manager) pollExternalTask(task *Task) {
 	}
 }
 
+// handleFailure records a failed attempt and then schedules a retry with
+// backoff, marks the task dead once retries are exhausted, or fails it
+// outright if the error is not retryable.
+func (m *Manager) handleFailure(ctx context.Context, task *Task, startedAt time.Time, err error) {
+	taskErr := &Error{Code: "execution_failed", Message: err.Error()}
+	m.recordAttempt(task, startedAt, taskErr)
+
+	policy := m.retryPolicy(task.Type)
+	switch {
+	case policy == nil || !policy.classify(err):
+		m.failTask(ctx, task, taskErr.Code, taskErr.Message)
+
+	case policy.ShouldRetry(err, task.Attempts):
+		delay := policy.Backoff(task.Attempts)
+		next := time.Now().Add(delay)
+		task.Status = StatusPending
+		task.Error = taskErr
+		task.NextRunAt = &next
+		task.LeaseExpiresAt = nil
+		task.UpdatedAt = time.Now()
+
+		if err := m.saveTask(ctx, task); err != nil {
+			m.logger.Error("failed to schedule task retry",
+				zap.String("task_id", task.ID.String()),
+				zap.Error(err))
+			return
+		}
+
+		m.logger.Info("task scheduled for retry",
+			zap.String("task_id", task.ID.String()),
+			zap.Int("attempt", task.Attempts),
+			zap.Duration("backoff", delay),
+			zap.String("error", taskErr.Message))
+		m.notifySubscribers(task)
+
+	default:
+		m.endTask(ctx, task, StatusDead, "retries_exhausted", taskErr.Message)
+	}
+}
+
+// recordAttempt appends the current execution to the task's history.
+func (m *Manager) recordAttempt(task *Task, startedAt time.Time, taskErr *Error) {
+	task.AttemptHistory = append(task.AttemptHistory, Attempt{
+		Number:     task.Attempts,
+		WorkerID:   m.workerID,
+		StartedAt:  startedAt,
+		FinishedAt: time.Now(),
+		Error:      taskErr,
+	})
+}
+
 // failTask marks a task as failed.
 func (m *Manager) failTask(ctx context.Context, task *Task, code, message string) {
-	task.Status = StatusFailed
+	m.endTask(ctx, task, StatusFailed, code, message)
+}
+
+// endTask moves a task to a terminal error state.
+func (m *Manager) endTask(ctx context.Context, task *Task, status Status, code, message string) {
+	task.Status = status
 	task.Error = &Error{
 		Code:    code,
 		Message: message,
 	}
 	task.UpdatedAt = time.Now()
+	task.LeaseExpiresAt = nil
 
-	if err := m.repo.Update(ctx, task); err != nil {
+	if err := m.saveTask(ctx, task); err != nil {
 		m.logger.Error("failed to update failed task",
 			zap.String("task_id", task.ID.String()),
 			zap.Error(err))
@@ -480,6 +810,7 @@ func (m *Manager) failTask(ctx context.Context, task *Task, code, message string
 
 	m.logger.Warn("task failed",
 		zap.String("task_id", task.ID.String()),
+		zap.String("status", string(status)),
 		zap.String("code", code),
 		zap.String("message", message))
 	m.notifySubscribers(task)
@@ -488,8 +819,23 @@ func (m *Manager) failTask(ctx context.Context, task *Task, code, message string
 // notifySubscribers notifies all subscribers of a task update.
 func (m *Manager) notifySubscribers(task *Task) {
 	m.mu.RLock()
-	subs := make([]func(*Task), len(m.subscribers[task.ID]))
-	copy(subs, m.subscribers[task.ID])
+	subs := make([]func(*Task), 0, len(m.subscribers[task.ID]))
+	for _, sub := range m.subscribers[task.ID] {
+		subs = append(subs, sub)
+	}
+	// Queues are only closed under the write lock
+	if len(m.allSubscribers) > 0 {
+		snapshot := *task
+		for _, queue := range m.allSubscribers {
+			select {
+			case queue <- &snapshot:
+			default:
+				m.logger.Warn("task subscriber is behind, dropping update",
+					zap.String("task_id", task.ID.String()),
+					zap.String("status", string(task.Status)))
+			}
+		}
+	}
 	m.mu.RUnlock()
 
 	for _, sub := range subs {
@@ -0,0 +1,333 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+// ===== Mock Implementations =====
+
+type MockRepository struct {
+	mock.Mock
+}
+
+func (m *MockRepository) Create(ctx context.Context, task *Task) error {
+	return m.Called(ctx, task).Error(0)
+}
+
+func (m *MockRepository) Get(ctx context.Context, id uuid.UUID) (*Task, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Task), args.Error(1)
+}
+
+func (m *MockRepository) GetByExternalID(ctx context.Context, externalID string) (*Task, error) {
+	args := m.Called(ctx, externalID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*Task), args.Error(1)
+}
+
+func (m *MockRepository) List(ctx context.Context, filter *Filter) ([]*Task, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).([]*Task), args.Error(1)
+}
+
+func (m *MockRepository) Update(ctx context.Context, task *Task) error {
+	return m.Called(ctx, task).Error(0)
+}
+
+func (m *MockRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status Status, progress int) error {
+	return m.Called(ctx, id, status, progress).Error(0)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockRepository) ListPendingOrRunning(ctx context.Context) ([]*Task, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*Task), args.Error(1)
+}
+
+func (m *MockRepository) ListByExternalTaskID(ctx context.Context) ([]*Task, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*Task), args.Error(1)
+}
+
+func (m *MockRepository) CountByOwnerAndStatus(ctx context.Context, ownerID uuid.UUID, status Status) (int64, error) {
+	args := m.Called(ctx, ownerID, status)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) Claim(ctx context.Context, workerID, taskType string, limit int, lease time.Duration) ([]*Task, error) {
+	args := m.Called(ctx, workerID, taskType, limit, lease)
+	return args.Get(0).([]*Task), args.Error(1)
+}
+
+func (m *MockRepository) RenewLease(ctx context.Context, id uuid.UUID, workerID string, lease time.Duration) error {
+	return m.Called(ctx, id, workerID, lease).Error(0)
+}
+
+func (m *MockRepository) UpdateLeased(ctx context.Context, task *Task, workerID string) error {
+	// Record a copy; the manager keeps mutating the task.
+	saved := *task
+	return m.Called(ctx, &saved, workerID).Error(0)
+}
+
+func (m *MockRepository) CreateLeased(ctx context.Context, task *Task, workerID string, lease time.Duration) error {
+	return m.Called(ctx, task, workerID, lease).Error(0)
+}
+
+func (m *MockRepository) ReleaseLeases(ctx context.Context, workerID string) error {
+	return m.Called(ctx, workerID).Error(0)
+}
+
+func (m *MockRepository) Requeue(ctx context.Context, id uuid.UUID) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+// ===== Test Helpers =====
+
+func newTestManager(repo Repository) *Manager {
+	config := DefaultConfig()
+	config.WorkerID = "worker-1"
+	config.RetryPolicy = &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Multiplier:     2,
+	}
+	return NewManager(repo, zap.NewNop(), config)
+}
+
+func newClaimedTask(attempts int) *Task {
+	expires := time.Now().Add(time.Minute)
+	return &Task{
+		ID:             uuid.New(),
+		OwnerID:        uuid.New(),
+		Type:           "test",
+		Status:         StatusRunning,
+		LeaseOwner:     "worker-1",
+		LeaseExpiresAt: &expires,
+		Attempts:       attempts,
+	}
+}
+
+func savedWithStatus(status Status) any {
+	return mock.MatchedBy(func(t *Task) bool { return t.Status == status })
+}
+
+// ===== Retry Tests =====
+
+func TestManager_HandleFailure(t *testing.T) {
+	errTransient := errors.New("upstream unavailable")
+
+	t.Run("schedules a retry with backoff", func(t *testing.T) {
+		repo := new(MockRepository)
+		m := newTestManager(repo)
+		task := newClaimedTask(2)
+		repo.On("UpdateLeased", mock.Anything, mock.MatchedBy(func(t *Task) bool {
+			return t.Status == StatusPending &&
+				t.NextRunAt != nil &&
+				t.NextRunAt.Sub(time.Now()) > time.Second &&
+				t.Error.Message == errTransient.Error()
+		}), "worker-1").Return(nil)
+
+		m.handleFailure(context.Background(), task, time.Now(), errTransient)
+
+		repo.AssertExpectations(t)
+		assert.Len(t, task.AttemptHistory, 1)
+		assert.Equal(t, 2, task.AttemptHistory[0].Number)
+	})
+
+	t.Run("dead after the last attempt", func(t *testing.T) {
+		repo := new(MockRepository)
+		m := newTestManager(repo)
+		task := newClaimedTask(3)
+		repo.On("UpdateLeased", mock.Anything, savedWithStatus(StatusDead), "worker-1").Return(nil)
+
+		m.handleFailure(context.Background(), task, time.Now(), errTransient)
+
+		repo.AssertExpectations(t)
+		assert.Equal(t, "retries_exhausted", task.Error.Code)
+	})
+
+	t.Run("permanent error fails immediately", func(t *testing.T) {
+		repo := new(MockRepository)
+		m := newTestManager(repo)
+		task := newClaimedTask(1)
+		repo.On("UpdateLeased", mock.Anything, savedWithStatus(StatusFailed), "worker-1").Return(nil)
+
+		m.handleFailure(context.Background(), task, time.Now(), Permanent(errTransient))
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestManager_ExecuteTask_ClaimedTooOften(t *testing.T) {
+	repo := new(MockRepository)
+	m := newTestManager(repo)
+	executed := false
+	m.RegisterExecutor("test", func(ctx context.Context, task *Task, onProgress func(int, map[string]any)) error {
+		executed = true
+		return nil
+	})
+	repo.On("UpdateLeased", mock.Anything, savedWithStatus(StatusDead), "worker-1").Return(nil)
+
+	m.executeTask(context.Background(), newClaimedTask(4))
+
+	repo.AssertExpectations(t)
+	assert.False(t, executed)
+}
+
+// ===== Lease Tests =====
+
+func TestManager_SubmitExternal_LeasesInRepository(t *testing.T) {
+	repo := new(MockRepository)
+	m := newTestManager(repo)
+	repo.On("CreateLeased", mock.Anything, mock.MatchedBy(func(t *Task) bool {
+		return t.Status == StatusRunning && t.ExternalTaskID == "ext-1" && t.LeaseExpiresAt == nil
+	}), "worker-1", m.config.LeaseDuration).Return(errors.New("db down"))
+
+	_, err := m.SubmitExternal(context.Background(), uuid.New(), &ExternalSubmitRequest{
+		SubmitRequest:  SubmitRequest{Type: "test"},
+		ExternalTaskID: "ext-1",
+	})
+
+	assert.ErrorContains(t, err, "db down")
+	repo.AssertExpectations(t)
+}
+
+func TestManager_LostLease(t *testing.T) {
+	t.Run("progress write aborts the worker", func(t *testing.T) {
+		repo := new(MockRepository)
+		m := newTestManager(repo)
+		interrupted := make(chan struct{})
+		m.RegisterExecutor("test", func(ctx context.Context, task *Task, onProgress func(int, map[string]any)) error {
+			onProgress(50, nil)
+			<-ctx.Done()
+			close(interrupted)
+			return ctx.Err()
+		})
+		repo.On("UpdateLeased", mock.Anything, savedWithStatus(StatusRunning), "worker-1").Return(ErrLeaseLost)
+
+		m.run(newClaimedTask(1))
+
+		select {
+		case <-interrupted:
+		case <-time.After(time.Second):
+			t.Fatal("executor was not interrupted")
+		}
+		m.wg.Wait()
+		// Neither completed nor failed: the new owner's state stands.
+		repo.AssertNumberOfCalls(t, "UpdateLeased", 1)
+	})
+
+	t.Run("failed renewal aborts the worker", func(t *testing.T) {
+		repo := new(MockRepository)
+		m := newTestManager(repo)
+		m.config.HeartbeatInterval = 10 * time.Millisecond
+		interrupted := make(chan struct{})
+		m.RegisterExecutor("test", func(ctx context.Context, task *Task, onProgress func(int, map[string]any)) error {
+			<-ctx.Done()
+			close(interrupted)
+			return ctx.Err()
+		})
+		task := newClaimedTask(1)
+		repo.On("RenewLease", mock.Anything, task.ID, "worker-1", m.config.LeaseDuration).Return(ErrLeaseLost)
+		repo.On("ReleaseLeases", mock.Anything, "worker-1").Return(nil)
+
+		m.wg.Add(1)
+		go m.heartbeatLoop()
+		m.run(task)
+
+		select {
+		case <-interrupted:
+		case <-time.After(time.Second):
+			t.Fatal("executor was not interrupted")
+		}
+		m.Stop()
+		repo.AssertNotCalled(t, "UpdateLeased", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// ===== Subscription Tests =====
+
+func TestManager_SubscribeAll(t *testing.T) {
+	t.Run("slow subscribers do not block the worker", func(t *testing.T) {
+		m := newTestManager(new(MockRepository))
+		release := make(chan struct{})
+		received := make(chan *Task, 2)
+		unsubscribe := m.SubscribeAll(func(t *Task) {
+			<-release
+			received <- t
+		})
+		defer unsubscribe()
+
+		task := newClaimedTask(1)
+		task.Progress = 10
+		notified := make(chan struct{})
+		go func() {
+			m.notifySubscribers(task)
+			task.Progress = 20
+			m.notifySubscribers(task)
+			close(notified)
+		}()
+
+		select {
+		case <-notified:
+		case <-time.After(time.Second):
+			t.Fatal("notifySubscribers blocked on a subscriber")
+		}
+		close(release)
+
+		// Updates arrive in order, as they were when sent
+		assert.Equal(t, 10, (<-received).Progress)
+		assert.Equal(t, 20, (<-received).Progress)
+	})
+
+	t.Run("full queue drops updates", func(t *testing.T) {
+		m := newTestManager(new(MockRepository))
+		release := make(chan struct{})
+		var count atomic.Int32
+		unsubscribe := m.SubscribeAll(func(*Task) {
+			<-release
+			count.Add(1)
+		})
+
+		task := newClaimedTask(1)
+		for range subscriberQueueSize + 10 {
+			m.notifySubscribers(task)
+		}
+		close(release)
+		unsubscribe()
+
+		// The queued updates, and maybe the one being handled, still arrive
+		assert.Eventually(t, func() bool { return int(count.Load()) >= subscriberQueueSize }, time.Second, 5*time.Millisecond)
+		assert.LessOrEqual(t, int(count.Load()), subscriberQueueSize+1)
+	})
+
+	t.Run("unsubscribed callbacks get no more updates", func(t *testing.T) {
+		m := newTestManager(new(MockRepository))
+		received := make(chan *Task, 1)
+		unsubscribe := m.SubscribeAll(func(t *Task) { received <- t })
+
+		unsubscribe()
+		unsubscribe()
+		m.notifySubscribers(newClaimedTask(1))
+
+		assert.Never(t, func() bool { return len(received) > 0 }, 50*time.Millisecond, 5*time.Millisecond)
+	})
+}
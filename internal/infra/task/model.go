@@ -3,9 +3,12 @@
 package task
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/model"
 )
 
 // Status represents the status of a task.
@@ -17,6 +20,9 @@ const (
 	StatusCompleted Status = "completed"
 	StatusFailed    Status = "failed"
 	StatusCancelled Status = "cancelled"
+	// StatusDead marks a task whose retries are exhausted. It stays dead
+	// until an operator requeues it.
+	StatusDead Status = "dead"
 )
 
 // Error represents a task error.
@@ -26,6 +32,15 @@ type Error struct {
 	Details any    `json:"details,omitempty"`
 }
 
+// Attempt records one execution of a task.
+type Attempt struct {
+	Number     int       `json:"number"`
+	WorkerID   string    `json:"worker_id"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Error      *Error    `json:"error,omitempty"`
+}
+
 // Task represents a generic async task.
 type Task struct {
 	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
@@ -41,6 +56,17 @@ type Task struct {
 	CreatedAt      time.Time      `json:"created_at"`
 	UpdatedAt      time.Time      `json:"updated_at"`
 	CompletedAt    *time.Time     `json:"completed_at,omitempty"`
+
+	// Lease held by the worker executing the task. A running task whose
+	// lease has expired may be claimed by another worker.
+	LeaseOwner     string     `json:"lease_owner,omitempty" gorm:"column:lease_owner"`
+	LeaseExpiresAt *time.Time `json:"lease_expires_at,omitempty" gorm:"column:lease_expires_at"`
+	HeartbeatAt    *time.Time `json:"heartbeat_at,omitempty" gorm:"column:heartbeat_at"`
+
+	// Retry state. Attempts counts claims, so a worker crash also uses one.
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	NextRunAt      *time.Time `json:"next_run_at,omitempty" gorm:"column:next_run_at"`
+	AttemptHistory []Attempt  `json:"attempt_history,omitempty" gorm:"type:jsonb;serializer:json"`
 }
 
 // TableName returns the table name for Task.
@@ -50,7 +76,7 @@ func (Task) TableName() string {
 
 // IsTerminal checks if the task is in a terminal state.
 func (t *Task) IsTerminal() bool {
-	return t.Status == StatusCompleted || t.Status == StatusFailed || t.Status == StatusCancelled
+	return t.Status == StatusCompleted || t.Status == StatusFailed || t.Status == StatusCancelled || t.Status == StatusDead
 }
 
 // IsPending checks if the task is pending.
@@ -63,6 +89,26 @@ func (t *Task) IsRunning() bool {
 	return t.Status == StatusRunning
 }
 
+// Event returns the task's current state as a task event.
+func (t *Task) Event() *model.TaskEvent {
+	event := &model.TaskEvent{
+		TaskID:    t.ID,
+		OwnerID:   t.OwnerID,
+		Source:    model.TaskEventSourceTask,
+		Type:      t.Type,
+		Status:    string(t.Status),
+		Progress:  t.Progress,
+		UpdatedAt: t.UpdatedAt,
+	}
+	if len(t.Output) > 0 {
+		event.Output, _ = json.Marshal(t.Output)
+	}
+	if t.Error != nil {
+		event.Error = t.Error.Message
+	}
+	return event
+}
+
 // Filter represents task filter options.
 type Filter struct {
 	OwnerID  *uuid.UUID
@@ -4,14 +4,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
 	// ErrTaskNotFound is returned when a task is not found.
 	ErrTaskNotFound = errors.New("task not found")
+
+	// ErrLeaseLost is returned when a worker no longer holds a task's lease.
+	ErrLeaseLost = errors.New("task lease lost")
+
+	// ErrTaskNotDead is returned when requeueing a task that is not dead.
+	ErrTaskNotDead = errors.New("task is not dead")
 )
 
 // Repository defines the interface for task data access.
@@ -26,6 +34,16 @@ type Repository interface {
 	ListPendingOrRunning(ctx context.Context) ([]*Task, error)
 	ListByExternalTaskID(ctx context.Context) ([]*Task, error)
 	CountByOwnerAndStatus(ctx context.Context, ownerID uuid.UUID, status Status) (int64, error)
+
+	// Lease operations
+	CreateLeased(ctx context.Context, task *Task, workerID string, lease time.Duration) error
+	Claim(ctx context.Context, workerID, taskType string, limit int, lease time.Duration) ([]*Task, error)
+	RenewLease(ctx context.Context, id uuid.UUID, workerID string, lease time.Duration) error
+	UpdateLeased(ctx context.Context, task *Task, workerID string) error
+	ReleaseLeases(ctx context.Context, workerID string) error
+
+	// Requeue moves a dead task back to pending with a fresh attempt budget.
+	Requeue(ctx context.Context, id uuid.UUID) error
 }
 
 type repository struct {
@@ -190,3 +208,164 @@ func (r *repository) ListByExternalTaskID(ctx context.Context) ([]*Task, error)
 	}
 	return tasks, nil
 }
+
+// leaseExpiry returns a SQL expression for the database clock plus lease,
+// so replicas with skewed clocks agree on expiry.
+func leaseExpiry(lease time.Duration) clause.Expr {
+	return gorm.Expr("NOW() + make_interval(secs => ?)", lease.Seconds())
+}
+
+// CreateLeased creates a task already held by workerID. The lease expiry is
+// set from the database clock, like Claim does.
+func (r *repository) CreateLeased(ctx context.Context, task *Task, workerID string, lease time.Duration) error {
+	task.LeaseOwner = workerID
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(task).Error; err != nil {
+			return err
+		}
+		return tx.Model(&Task{}).
+			Where("id = ?", task.ID).
+			Updates(map[string]any{
+				"lease_expires_at": leaseExpiry(lease),
+				"heartbeat_at":     gorm.Expr("NOW()"),
+			}).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	expires := now.Add(lease)
+	task.LeaseExpiresAt = &expires
+	task.HeartbeatAt = &now
+	return nil
+}
+
+// Claim atomically takes up to limit tasks of a type for workerID. It picks
+// pending tasks and running tasks whose lease has expired, skipping rows
+// locked by concurrent claimers.
+func (r *repository) Claim(ctx context.Context, workerID, taskType string, limit int, lease time.Duration) ([]*Task, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	var tasks []*Task
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("type = ?", taskType).
+			Where("(status = ? AND (next_run_at IS NULL OR next_run_at <= NOW())) OR "+
+				"(status = ? AND (lease_expires_at IS NULL OR lease_expires_at < NOW()))",
+				StatusPending, StatusRunning).
+			Order("created_at ASC").
+			Limit(limit).
+			Find(&tasks).Error
+		if err != nil || len(tasks) == 0 {
+			return err
+		}
+
+		ids := make([]uuid.UUID, len(tasks))
+		for i, t := range tasks {
+			ids[i] = t.ID
+		}
+
+		return tx.Model(&Task{}).
+			Where("id IN ?", ids).
+			Updates(map[string]any{
+				"status":           StatusRunning,
+				"lease_owner":      workerID,
+				"lease_expires_at": leaseExpiry(lease),
+				"heartbeat_at":     gorm.Expr("NOW()"),
+				"updated_at":       gorm.Expr("NOW()"),
+				"attempts":         gorm.Expr("attempts + 1"),
+				"next_run_at":      nil,
+			}).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("claim tasks: %w", err)
+	}
+
+	now := time.Now()
+	expires := now.Add(lease)
+	for _, t := range tasks {
+		t.Status = StatusRunning
+		t.LeaseOwner = workerID
+		t.LeaseExpiresAt = &expires
+		t.HeartbeatAt = &now
+		t.Attempts++
+		t.NextRunAt = nil
+	}
+	return tasks, nil
+}
+
+// RenewLease extends a running task's lease. It returns ErrLeaseLost if the
+// task is no longer running under workerID.
+func (r *repository) RenewLease(ctx context.Context, id uuid.UUID, workerID string, lease time.Duration) error {
+	result := r.db.WithContext(ctx).
+		Model(&Task{}).
+		Where("id = ? AND lease_owner = ? AND status = ?", id, workerID, StatusRunning).
+		Updates(map[string]any{
+			"lease_expires_at": leaseExpiry(lease),
+			"heartbeat_at":     gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("renew lease: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// UpdateLeased saves a task only while workerID still holds its lease and it
+// has not been cancelled, so a worker that lost its lease cannot overwrite
+// the new owner's state.
+func (r *repository) UpdateLeased(ctx context.Context, task *Task, workerID string) error {
+	result := r.db.WithContext(ctx).
+		Model(task).
+		Where("lease_owner = ? AND status = ?", workerID, StatusRunning).
+		Select("*").
+		Updates(task)
+	if result.Error != nil {
+		return fmt.Errorf("update task: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// ReleaseLeases expires every lease held by workerID so other workers can
+// claim its running tasks immediately.
+func (r *repository) ReleaseLeases(ctx context.Context, workerID string) error {
+	err := r.db.WithContext(ctx).
+		Model(&Task{}).
+		Where("lease_owner = ? AND status = ?", workerID, StatusRunning).
+		Update("lease_expires_at", gorm.Expr("NOW()")).Error
+	if err != nil {
+		return fmt.Errorf("release leases: %w", err)
+	}
+	return nil
+}
+
+// Requeue moves a dead task back to pending with a fresh attempt budget.
+// Its attempt history is kept.
+func (r *repository) Requeue(ctx context.Context, id uuid.UUID) error {
+	result := r.db.WithContext(ctx).
+		Model(&Task{}).
+		Where("id = ? AND status = ?", id, StatusDead).
+		Updates(map[string]any{
+			"status":      StatusPending,
+			"attempts":    0,
+			"next_run_at": nil,
+			"error":       nil,
+			"lease_owner": nil,
+			"updated_at":  gorm.Expr("NOW()"),
+		})
+	if result.Error != nil {
+		return fmt.Errorf("requeue task: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrTaskNotDead
+	}
+	return nil
+}
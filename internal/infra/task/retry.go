@@ -0,0 +1,99 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how failed executions of a task type are retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of executions, including the first.
+	// 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the second attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts.
+	MaxBackoff time.Duration
+	// Multiplier grows the delay after each attempt.
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction (0-1) in either
+	// direction so retries of many tasks do not line up.
+	Jitter float64
+	// Retryable classifies executor errors. Nil retries everything except
+	// errors wrapped with Permanent.
+	Retryable func(error) bool
+}
+
+// DefaultRetryPolicy returns the policy used for task types without one.
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     10 * time.Minute,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// Backoff returns the delay before the attempt following attempt n (1-based).
+func (p *RetryPolicy) Backoff(n int) time.Duration {
+	if n < 1 {
+		n = 1
+	}
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(n-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// ShouldRetry reports whether err after attempt n warrants another attempt.
+func (p *RetryPolicy) ShouldRetry(err error, n int) bool {
+	return n < p.MaxAttempts && p.classify(err)
+}
+
+// classify reports whether err is retryable under this policy.
+func (p *RetryPolicy) classify(err error) bool {
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// permanentError marks an error that retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so the task fails immediately instead of retrying.
+// Executors should use it for invalid input and other deterministic failures.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable reports whether err is worth retrying under the default
+// classification: everything except Permanent errors and cancellation.
+func IsRetryable(err error) bool {
+	var perm *permanentError
+	if errors.As(err, &perm) {
+		return false
+	}
+	return !errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,81 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := &RetryPolicy{
+		InitialBackoff: 10 * time.Second,
+		MaxBackoff:     time.Minute,
+		Multiplier:     2,
+	}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 10 * time.Second},
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{3, 40 * time.Second},
+		{4, time.Minute},
+		{20, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt %d", tt.attempt), func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.Backoff(tt.attempt))
+		})
+	}
+
+	t.Run("multiplier below one keeps the delay constant", func(t *testing.T) {
+		policy := &RetryPolicy{InitialBackoff: time.Second, Multiplier: 0.5}
+		assert.Equal(t, time.Second, policy.Backoff(5))
+	})
+
+	t.Run("jitter stays within bounds", func(t *testing.T) {
+		policy := &RetryPolicy{InitialBackoff: 10 * time.Second, Multiplier: 2, MaxBackoff: time.Minute, Jitter: 0.2}
+		for i := 0; i < 100; i++ {
+			delay := policy.Backoff(10)
+			assert.GreaterOrEqual(t, delay, 48*time.Second)
+			assert.LessOrEqual(t, delay, 72*time.Second)
+		}
+	})
+}
+
+func TestRetryPolicy_ShouldRetry(t *testing.T) {
+	errTransient := errors.New("upstream unavailable")
+	policy := &RetryPolicy{MaxAttempts: 3}
+
+	tests := []struct {
+		name    string
+		policy  *RetryPolicy
+		err     error
+		attempt int
+		want    bool
+	}{
+		{"first failure", policy, errTransient, 1, true},
+		{"attempts left", policy, errTransient, 2, true},
+		{"attempts exhausted", policy, errTransient, 3, false},
+		{"permanent error", policy, Permanent(errTransient), 1, false},
+		{"wrapped permanent error", policy, fmt.Errorf("execute: %w", Permanent(errTransient)), 1, false},
+		{"cancelled", policy, context.Canceled, 1, false},
+		{"retries disabled", &RetryPolicy{MaxAttempts: 1}, errTransient, 1, false},
+		{
+			"custom classification",
+			&RetryPolicy{MaxAttempts: 3, Retryable: func(err error) bool { return !errors.Is(err, errTransient) }},
+			errTransient, 1, false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.ShouldRetry(tt.err, tt.attempt))
+		})
+	}
+}
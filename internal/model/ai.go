@@ -1,6 +1,7 @@
 package model
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
@@ -34,8 +35,27 @@ const (
 	AICapabilityImage     AICapability = "image_generation"
 	AICapabilityVideo     AICapability = "video_generation"
 	AICapabilityAudio     AICapability = "audio_generation"
+	AICapabilityReasoning AICapability = "reasoning"
 )
 
+// AIReasoningEffort is a provider-neutral reasoning depth.
+type AIReasoningEffort string
+
+const (
+	AIReasoningEffortLow    AIReasoningEffort = "low"
+	AIReasoningEffortMedium AIReasoningEffort = "medium"
+	AIReasoningEffortHigh   AIReasoningEffort = "high"
+)
+
+// IsValid checks if the effort level is known.
+func (e AIReasoningEffort) IsValid() bool {
+	switch e {
+	case AIReasoningEffortLow, AIReasoningEffortMedium, AIReasoningEffortHigh:
+		return true
+	}
+	return false
+}
+
 // ===== Entity Models =====
 
 // AIRateLimitConfig defines rate limiting parameters.
@@ -79,10 +99,14 @@ type AIModel struct {
 	MaxOutputTokens int            `json:"max_output_tokens" gorm:"column:max_output_tokens;not null"`
 	InputCostPer1K  float64        `json:"input_cost_per_1k" gorm:"column:input_cost_per_1k;type:decimal(10,6)"`
 	OutputCostPer1K float64        `json:"output_cost_per_1k" gorm:"column:output_cost_per_1k;type:decimal(10,6)"`
-	Enabled         bool           `json:"enabled" gorm:"default:true"`
-	Options         map[string]any `json:"options" gorm:"type:jsonb;serializer:json"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
+	// Prompt cache pricing; zero means cached tokens are billed at InputCostPer1K.
+	CachedInputCostPer1K float64        `json:"cached_input_cost_per_1k" gorm:"column:cached_input_cost_per_1k;type:decimal(10,6)"`
+	CacheWriteCostPer1K  float64        `json:"cache_write_cost_per_1k" gorm:"column:cache_write_cost_per_1k;type:decimal(10,6)"`
+	ReasoningCostPer1K   float64        `json:"reasoning_cost_per_1k" gorm:"column:reasoning_cost_per_1k;type:decimal(10,6)"` // Zero bills reasoning at OutputCostPer1K
+	Enabled              bool           `json:"enabled" gorm:"default:true"`
+	Options              map[string]any `json:"options" gorm:"type:jsonb;serializer:json"`
+	CreatedAt            time.Time      `json:"created_at"`
+	UpdatedAt            time.Time      `json:"updated_at"`
 
 	// Relations
 	Provider *AIProvider `json:"provider,omitempty" gorm:"foreignKey:ProviderID"`
@@ -293,6 +317,70 @@ func (g *AIModelGroup) HasModel(modelID string) bool {
 	return false
 }
 
+// AIModelAlias maps a stable name (e.g. "fast", "gpt-4o-latest") to a concrete
+// model or a model group. A scheduled cut-over switches the target at CutoverAt.
+type AIModelAlias struct {
+	ID                 string     `json:"id" gorm:"primaryKey"`
+	Description        string     `json:"description,omitempty"`
+	TargetModelID      string     `json:"target_model_id,omitempty" gorm:"column:target_model_id"`
+	TargetGroupID      string     `json:"target_group_id,omitempty" gorm:"column:target_group_id"`
+	NextTargetModelID  string     `json:"next_target_model_id,omitempty" gorm:"column:next_target_model_id"`
+	NextTargetGroupID  string     `json:"next_target_group_id,omitempty" gorm:"column:next_target_group_id"`
+	CutoverAt          *time.Time `json:"cutover_at,omitempty"`
+	Deprecated         bool       `json:"deprecated" gorm:"default:false"`
+	DeprecationMessage string     `json:"deprecation_message,omitempty"`
+	SunsetAt           *time.Time `json:"sunset_at,omitempty"`
+	Enabled            bool       `json:"enabled" gorm:"default:true"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name for AIModelAlias.
+func (AIModelAlias) TableName() string {
+	return "ai_model_aliases"
+}
+
+// Resolve returns the model or group the alias points to at the given time.
+// Exactly one of modelID and groupID is non-empty.
+func (a *AIModelAlias) Resolve(now time.Time) (modelID, groupID string) {
+	if a.CutoverAt != nil && !now.Before(*a.CutoverAt) && (a.NextTargetModelID != "" || a.NextTargetGroupID != "") {
+		return a.NextTargetModelID, a.NextTargetGroupID
+	}
+	return a.TargetModelID, a.TargetGroupID
+}
+
+// Notice returns the deprecation or pending cut-over notice for the alias, if any.
+func (a *AIModelAlias) Notice(now time.Time) *AIDeprecationNotice {
+	if a.Deprecated {
+		msg := a.DeprecationMessage
+		if msg == "" {
+			msg = fmt.Sprintf("model alias %q is deprecated", a.ID)
+		}
+		return &AIDeprecationNotice{Deprecated: true, Message: msg, SunsetAt: a.SunsetAt}
+	}
+
+	if a.CutoverAt != nil && now.Before(*a.CutoverAt) {
+		next := a.NextTargetModelID
+		if next == "" {
+			next = a.NextTargetGroupID
+		}
+		if next != "" {
+			return &AIDeprecationNotice{
+				Message: fmt.Sprintf("model alias %q will point to %q from %s", a.ID, next, a.CutoverAt.UTC().Format(time.RFC3339)),
+			}
+		}
+	}
+
+	return nil
+}
+
+// AIDeprecationNotice describes a deprecation or upcoming change for a requested model name.
+type AIDeprecationNotice struct {
+	Deprecated bool       `json:"deprecated"`
+	Message    string     `json:"message"`
+	SunsetAt   *time.Time `json:"sunset_at,omitempty"`
+}
+
 // ===== Request/Response Types =====
 
 // AIChatRequest represents a chat completion request.
@@ -307,16 +395,44 @@ type AIChatRequest struct {
 	ToolChoice  any               `json:"tool_choice,omitempty"`
 	Stream      bool              `json:"stream,omitempty"`
 	Metadata    map[string]any    `json:"metadata,omitempty"`
+
+	// Reasoning controls; either may be set and is translated per provider.
+	ReasoningEffort AIReasoningEffort `json:"reasoning_effort,omitempty"`
+	Thinking        *AIThinkingConfig `json:"thinking,omitempty"`
+
 	UserID      uuid.UUID         `json:"-"` // Set by service layer
+	APIKeyID    *uuid.UUID        `json:"-"` // Set by service layer when authenticated via API key
+	RequestID   string            `json:"-"` // Set by service layer from X-Request-ID
+}
+
+// AIMinThinkingBudget is the smallest thinking budget providers accept.
+const AIMinThinkingBudget = 1024
+
+// AIThinkingConfig requests extended thinking with a token budget.
+type AIThinkingConfig struct {
+	BudgetTokens int `json:"budget_tokens"`
+}
+
+// WantsReasoning reports whether the request asks for reasoning.
+func (r *AIChatRequest) WantsReasoning() bool {
+	return r.ReasoningEffort != "" || r.Thinking != nil
 }
 
 // AIChatMessage represents a chat message.
 type AIChatMessage struct {
-	Role       string        `json:"role"`
-	Content    any           `json:"content"` // string or []AIContentPart
-	Name       string        `json:"name,omitempty"`
-	ToolCallID string        `json:"tool_call_id,omitempty"`
-	ToolCalls  []*AIToolCall `json:"tool_calls,omitempty"`
+	Role         string          `json:"role"`
+	Content      any             `json:"content"` // string or []AIContentPart
+	Name         string          `json:"name,omitempty"`
+	ToolCallID   string          `json:"tool_call_id,omitempty"`
+	ToolCalls    []*AIToolCall   `json:"tool_calls,omitempty"`
+	CacheControl *AICacheControl `json:"cache_control,omitempty"` // Prompt cache breakpoint after this message
+}
+
+// AICacheControl marks a prompt cache breakpoint.
+// Providers that cache automatically ignore it.
+type AICacheControl struct {
+	Type string `json:"type"`          // ephemeral
+	TTL  string `json:"ttl,omitempty"` // e.g. 5m, 1h
 }
 
 // GetTextContent extracts text content from a message.
@@ -358,9 +474,10 @@ func (m *AIChatMessage) HasImages() bool {
 
 // AIContentPart represents a multimodal content part.
 type AIContentPart struct {
-	Type     string      `json:"type"` // text, image_url
-	Text     string      `json:"text,omitempty"`
-	ImageURL *AIImageURL `json:"image_url,omitempty"`
+	Type         string          `json:"type"` // text, image_url
+	Text         string          `json:"text,omitempty"`
+	ImageURL     *AIImageURL     `json:"image_url,omitempty"`
+	CacheControl *AICacheControl `json:"cache_control,omitempty"`
 }
 
 // AIImageURL represents an image URL reference.
@@ -401,6 +518,7 @@ type AIChatResponse struct {
 	Model        string          `json:"model"`
 	Message      *AIChatMessage  `json:"message"`
 	FinishReason string          `json:"finish_reason"`
+	Reasoning    string          `json:"reasoning,omitempty"` // Thinking text, when the provider returns it
 	Usage        *AIUsage        `json:"usage"`
 	Routing      *AIRoutingInfo  `json:"_routing,omitempty"`
 }
@@ -411,12 +529,14 @@ type AIChatChunk struct {
 	Model        string   `json:"model"`
 	Delta        *AIDelta `json:"delta"`
 	FinishReason string   `json:"finish_reason,omitempty"`
+	Usage        *AIUsage `json:"usage,omitempty"` // Set on the final chunk when the provider reports it
 }
 
 // AIDelta represents incremental content.
 type AIDelta struct {
 	Role      string        `json:"role,omitempty"`
 	Content   string        `json:"content,omitempty"`
+	Reasoning string        `json:"reasoning,omitempty"` // Thinking delta
 	ToolCalls []*AIToolCall `json:"tool_calls,omitempty"`
 }
 
@@ -425,14 +545,24 @@ type AIUsage struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
 	TotalTokens      int `json:"total_tokens"`
+	ImageTokens      int `json:"image_tokens,omitempty"` // Estimated share of PromptTokens spent on images
+
+	// Prompt cache breakdown; both are included in PromptTokens.
+	CacheReadTokens  int `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+
+	// ReasoningTokens is the share of CompletionTokens spent thinking.
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
 }
 
 // AIRoutingInfo contains routing metadata.
 type AIRoutingInfo struct {
-	ProviderUsed string  `json:"provider_used"`
-	ModelUsed    string  `json:"model_used"`
-	LatencyMs    int64   `json:"latency_ms"`
-	CostUSD      float64 `json:"cost_usd"`
+	ProviderUsed string               `json:"provider_used"`
+	ModelUsed    string               `json:"model_used"`
+	LatencyMs    int64                `json:"latency_ms"`
+	CostUSD      float64              `json:"cost_usd"`
+	Alias        string               `json:"alias,omitempty"`
+	Deprecation  *AIDeprecationNotice `json:"deprecation,omitempty"`
 }
 
 // AIEmbedRequest represents an embedding request.
@@ -462,8 +592,9 @@ type AIRoutingContext struct {
 	// Required capabilities
 	RequireStream bool
 	RequireTools  bool
-	RequireVision bool
-	RequireJSON   bool
+	RequireVision    bool
+	RequireJSON      bool
+	RequireReasoning bool
 
 	// Context window requirements
 	MinContextWindow int
@@ -485,6 +616,9 @@ type AIRoutingContext struct {
 	// Group override
 	GroupID string
 
+	// Alias the requested model name resolved through, if any
+	Alias *AIModelAlias
+
 	// Additional metadata
 	Metadata map[string]any
 }
@@ -516,6 +650,9 @@ func (c *AIRoutingContext) RequiredCapabilities() []AICapability {
 	if c.RequireJSON {
 		caps = append(caps, AICapabilityJSON)
 	}
+	if c.RequireReasoning {
+		caps = append(caps, AICapabilityReasoning)
+	}
 
 	return caps
 }
@@ -577,3 +714,57 @@ func (r *AIChatRequest) RequiresCapability(cap AICapability) bool {
 	}
 	return false
 }
+
+// ===== Request Log Types =====
+
+// AIRequestLog is an audit record of a chat request and its response.
+// Bodies are stored encrypted; Request and Response are only populated
+// after decryption by the domain layer.
+type AIRequestLog struct {
+	ID                uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	RequestID         string     `json:"request_id" gorm:"index"`
+	UserID            uuid.UUID  `json:"user_id" gorm:"type:uuid;not null"`
+	APIKeyID          *uuid.UUID `json:"api_key_id,omitempty" gorm:"type:uuid"`
+	ModelID           string     `json:"model_id"`
+	ProviderID        *uuid.UUID `json:"provider_id,omitempty" gorm:"type:uuid"`
+	Stream            bool       `json:"stream"`
+	EncryptedRequest  string     `json:"-" gorm:"column:encrypted_request;not null"`
+	EncryptedResponse string     `json:"-" gorm:"column:encrypted_response"`
+	Success           bool       `json:"success"`
+	Error             string     `json:"error,omitempty"`
+	PromptTokens      int        `json:"prompt_tokens"`
+	CompletionTokens  int        `json:"completion_tokens"`
+	CostUSD           float64    `json:"cost_usd" gorm:"column:cost_usd;type:decimal(12,6)"`
+	LatencyMs         int64      `json:"latency_ms"`
+	ReplayOf          *uuid.UUID `json:"replay_of,omitempty" gorm:"type:uuid"`
+	CreatedAt         time.Time  `json:"created_at"`
+	ExpiresAt         time.Time  `json:"expires_at"`
+
+	// Decrypted bodies (not persisted)
+	Request  *AIChatRequest  `json:"request,omitempty" gorm:"-"`
+	Response *AIChatResponse `json:"response,omitempty" gorm:"-"`
+}
+
+// TableName returns the table name for AIRequestLog.
+func (AIRequestLog) TableName() string {
+	return "ai_request_logs"
+}
+
+// AIRequestLogFilter defines search criteria for request logs.
+type AIRequestLogFilter struct {
+	RequestID string
+	UserID    *uuid.UUID
+	APIKeyID  *uuid.UUID
+	ModelID   string
+	Since     *time.Time
+	Until     *time.Time
+	Limit     int
+	Offset    int
+}
+
+// AIReplayResult compares a logged request with its replay.
+type AIReplayResult struct {
+	Original *AIRequestLog   `json:"original"`
+	Replay   *AIRequestLog   `json:"replay"`
+	Response *AIChatResponse `json:"response,omitempty"`
+}
@@ -106,8 +106,9 @@ type Plan struct {
 	MonthlyEmbeddingTokens int64 `json:"monthly_embedding_tokens" gorm:"default:0"`
 
 	// Storage quotas
-	GitStorageMB int64 `json:"git_storage_mb" gorm:"default:-1"`
-	LFSStorageMB int64 `json:"lfs_storage_mb" gorm:"default:-1"`
+	GitStorageMB   int64 `json:"git_storage_mb" gorm:"default:-1"`
+	LFSStorageMB   int64 `json:"lfs_storage_mb" gorm:"default:-1"`
+	MediaStorageMB int64 `json:"media_storage_mb" gorm:"default:-1"`
 
 	// Team quota
 	MaxTeamMembers int `json:"max_team_members" gorm:"default:5"`
@@ -131,6 +132,26 @@ func (p *Plan) IsUnlimitedRequests() bool {
 	return p.DailyRequests == -1
 }
 
+// IsUnlimitedVideoMinutes returns true if the plan has unlimited video minutes.
+func (p *Plan) IsUnlimitedVideoMinutes() bool {
+	return p.MonthlyVideoMinutes == -1
+}
+
+// IsUnlimitedMediaStorage returns true if media storage is unlimited.
+func (p *Plan) IsUnlimitedMediaStorage() bool {
+	return p.MediaStorageMB == -1
+}
+
+// IsUnlimitedGitStorage returns true if git storage is unlimited.
+func (p *Plan) IsUnlimitedGitStorage() bool {
+	return p.GitStorageMB == -1
+}
+
+// IsUnlimitedLFSStorage returns true if LFS storage is unlimited.
+func (p *Plan) IsUnlimitedLFSStorage() bool {
+	return p.LFSStorageMB == -1
+}
+
 // GetEffectiveChatTokenLimit returns the effective chat token limit.
 func (p *Plan) GetEffectiveChatTokenLimit() int64 {
 	if p.MonthlyChatTokens == 0 {
@@ -157,6 +178,7 @@ type PlanResponse struct {
 	MonthlyEmbeddingTokens int64    `json:"monthly_embedding_tokens"`
 	GitStorageMB           int64    `json:"git_storage_mb"`
 	LFSStorageMB           int64    `json:"lfs_storage_mb"`
+	MediaStorageMB         int64    `json:"media_storage_mb"`
 	MaxTeamMembers         int      `json:"max_team_members"`
 }
 
@@ -179,6 +201,7 @@ func (p *Plan) ToResponse() *PlanResponse {
 		MonthlyEmbeddingTokens: p.MonthlyEmbeddingTokens,
 		GitStorageMB:           p.GitStorageMB,
 		LFSStorageMB:           p.LFSStorageMB,
+		MediaStorageMB:         p.MediaStorageMB,
 		MaxTeamMembers:         p.MaxTeamMembers,
 	}
 }
@@ -269,10 +292,15 @@ type UsageRecord struct {
 	InputTokens  int        `json:"input_tokens" gorm:"not null;default:0"`
 	OutputTokens int        `json:"output_tokens" gorm:"not null;default:0"`
 	TotalTokens  int        `json:"total_tokens" gorm:"not null;default:0"`
-	CostUSD      float64    `json:"cost_usd" gorm:"type:decimal(10,6);not null"`
-	LatencyMs    int        `json:"latency_ms" gorm:"not null"`
-	Success      bool       `json:"success" gorm:"not null"`
-	CacheHit     bool       `json:"cache_hit" gorm:"not null;default:false"`
+	// Prompt cache breakdown of InputTokens
+	CacheReadTokens  int `json:"cache_read_tokens" gorm:"not null;default:0"`
+	CacheWriteTokens int `json:"cache_write_tokens" gorm:"not null;default:0"`
+	// Seconds of generated video, billed against MonthlyVideoMinutes
+	VideoSeconds int     `json:"video_seconds" gorm:"not null;default:0"`
+	CostUSD      float64 `json:"cost_usd" gorm:"type:decimal(10,6);not null"`
+	LatencyMs    int     `json:"latency_ms" gorm:"not null"`
+	Success      bool    `json:"success" gorm:"not null"`
+	CacheHit     bool    `json:"cache_hit" gorm:"not null;default:false"`
 }
 
 // TableName returns the database table name.
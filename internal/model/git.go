@@ -1,6 +1,7 @@
 package model
 
 import (
+	"path"
 	"time"
 
 	"github.com/google/uuid"
@@ -48,24 +49,26 @@ func (p GitPermission) CanAdmin() bool {
 
 // GitRepo represents a Git repository.
 type GitRepo struct {
-	ID            uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	OwnerID       uuid.UUID     `json:"owner_id" gorm:"type:uuid;not null;index"`
-	Name          string        `json:"name" gorm:"not null"`
-	Slug          string        `json:"slug" gorm:"not null;index"`
-	RepoType      GitRepoType   `json:"repo_type" gorm:"column:repo_type;not null;default:code"`
-	Visibility    GitVisibility `json:"visibility" gorm:"not null;default:private"`
-	Description   string        `json:"description,omitempty"`
-	DefaultBranch string        `json:"default_branch" gorm:"default:main"`
-	SizeBytes     int64         `json:"size_bytes" gorm:"default:0"`
-	LFSEnabled    bool          `json:"lfs_enabled" gorm:"default:false"`
-	LFSSizeBytes  int64         `json:"lfs_size_bytes" gorm:"default:0"`
-	StoragePath   string        `json:"-" gorm:"not null"` // R2/S3 prefix
-	StarsCount    int           `json:"stars_count" gorm:"default:0"`
-	ForksCount    int           `json:"forks_count" gorm:"default:0"`
-	ForkedFrom    *uuid.UUID    `json:"forked_from,omitempty" gorm:"type:uuid"`
-	CreatedAt     time.Time     `json:"created_at"`
-	UpdatedAt     time.Time     `json:"updated_at"`
-	PushedAt      *time.Time    `json:"pushed_at,omitempty"`
+	ID                uuid.UUID     `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	OwnerID           uuid.UUID     `json:"owner_id" gorm:"type:uuid;not null;index"`
+	Name              string        `json:"name" gorm:"not null"`
+	Slug              string        `json:"slug" gorm:"not null;index"`
+	RepoType          GitRepoType   `json:"repo_type" gorm:"column:repo_type;not null;default:code"`
+	Visibility        GitVisibility `json:"visibility" gorm:"not null;default:private"`
+	Description       string        `json:"description,omitempty"`
+	DefaultBranch     string        `json:"default_branch" gorm:"default:main"`
+	SizeBytes         int64         `json:"size_bytes" gorm:"default:0"`
+	LFSEnabled        bool          `json:"lfs_enabled" gorm:"default:false"`
+	LFSSizeBytes      int64         `json:"lfs_size_bytes" gorm:"default:0"`
+	StoragePath       string        `json:"-" gorm:"not null"` // R2/S3 prefix
+	StarsCount        int           `json:"stars_count" gorm:"default:0"`
+	ForksCount        int           `json:"forks_count" gorm:"default:0"`
+	ForkedFrom        *uuid.UUID    `json:"forked_from,omitempty" gorm:"type:uuid"`
+	TeamID            *uuid.UUID    `json:"team_id,omitempty" gorm:"type:uuid;index"` // team whose members share access
+	RequiredApprovals int           `json:"required_approvals" gorm:"default:0"`      // approvals needed to merge a pull request
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+	PushedAt          *time.Time    `json:"pushed_at,omitempty"`
 
 	// Relations (not loaded by default)
 	Collaborators []*GitRepoCollaborator `json:"collaborators,omitempty" gorm:"foreignKey:RepoID"`
@@ -131,20 +134,22 @@ func (s GitPRStatus) IsMerged() bool {
 
 // GitPullRequest represents a pull request.
 type GitPullRequest struct {
-	ID           uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
-	RepoID       uuid.UUID   `json:"repo_id" gorm:"type:uuid;not null;index"`
-	Number       int         `json:"number" gorm:"not null"`
-	Title        string      `json:"title" gorm:"not null"`
-	Description  string      `json:"description,omitempty"`
-	SourceBranch string      `json:"source_branch" gorm:"not null"`
-	TargetBranch string      `json:"target_branch" gorm:"not null"`
-	Status       GitPRStatus `json:"status" gorm:"not null;default:open"`
-	AuthorID     uuid.UUID   `json:"author_id" gorm:"type:uuid;not null"`
-	MergedBy     *uuid.UUID  `json:"merged_by,omitempty" gorm:"type:uuid"`
-	MergedAt     *time.Time  `json:"merged_at,omitempty"`
-	ClosedAt     *time.Time  `json:"closed_at,omitempty"`
-	CreatedAt    time.Time   `json:"created_at"`
-	UpdatedAt    time.Time   `json:"updated_at"`
+	ID             uuid.UUID   `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	RepoID         uuid.UUID   `json:"repo_id" gorm:"type:uuid;not null;index"`
+	Number         int         `json:"number" gorm:"not null"`
+	Title          string      `json:"title" gorm:"not null"`
+	Description    string      `json:"description,omitempty"`
+	SourceRepoID   *uuid.UUID  `json:"source_repo_id,omitempty" gorm:"type:uuid"` // set when opened from a fork
+	SourceBranch   string      `json:"source_branch" gorm:"not null"`
+	TargetBranch   string      `json:"target_branch" gorm:"not null"`
+	Status         GitPRStatus `json:"status" gorm:"not null;default:open"`
+	AuthorID       uuid.UUID   `json:"author_id" gorm:"type:uuid;not null"`
+	MergedBy       *uuid.UUID  `json:"merged_by,omitempty" gorm:"type:uuid"`
+	MergedAt       *time.Time  `json:"merged_at,omitempty"`
+	MergeCommitSHA string      `json:"merge_commit_sha,omitempty" gorm:"size:40"`
+	ClosedAt       *time.Time  `json:"closed_at,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
 }
 
 // TableName returns the database table name.
@@ -152,6 +157,128 @@ func (GitPullRequest) TableName() string {
 	return "pull_requests"
 }
 
+// GitMergeStrategy represents how a pull request is merged.
+type GitMergeStrategy string
+
+const (
+	GitMergeStrategyMerge       GitMergeStrategy = "merge"
+	GitMergeStrategySquash      GitMergeStrategy = "squash"
+	GitMergeStrategyFastForward GitMergeStrategy = "fast-forward"
+)
+
+// IsValid checks if the merge strategy is valid.
+func (s GitMergeStrategy) IsValid() bool {
+	switch s {
+	case GitMergeStrategyMerge, GitMergeStrategySquash, GitMergeStrategyFastForward:
+		return true
+	}
+	return false
+}
+
+// ===== Pull Request Review Types =====
+
+// GitPRReviewState represents the verdict of a pull request review.
+type GitPRReviewState string
+
+const (
+	GitPRReviewStateApproved         GitPRReviewState = "approved"
+	GitPRReviewStateChangesRequested GitPRReviewState = "changes_requested"
+	GitPRReviewStateCommented        GitPRReviewState = "commented"
+)
+
+// IsValid checks if the review state is valid.
+func (s GitPRReviewState) IsValid() bool {
+	switch s {
+	case GitPRReviewStateApproved, GitPRReviewStateChangesRequested, GitPRReviewStateCommented:
+		return true
+	}
+	return false
+}
+
+// GitPRReview represents a review of a pull request at a commit.
+type GitPRReview struct {
+	ID         uuid.UUID        `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	PRID       uuid.UUID        `json:"pr_id" gorm:"column:pr_id;type:uuid;not null;index"`
+	ReviewerID uuid.UUID        `json:"reviewer_id" gorm:"type:uuid;not null"`
+	State      GitPRReviewState `json:"state" gorm:"not null"`
+	Body       string           `json:"body,omitempty"`
+	CommitSHA  string           `json:"commit_sha" gorm:"size:40;not null"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// TableName returns the database table name.
+func (GitPRReview) TableName() string {
+	return "pull_request_reviews"
+}
+
+// GitPRComment represents a comment on a pull request. Inline comments are
+// anchored to a line of a file at a commit, so they stay meaningful after
+// the source branch is force-pushed.
+type GitPRComment struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	PRID      uuid.UUID  `json:"pr_id" gorm:"column:pr_id;type:uuid;not null;index"`
+	ReviewID  *uuid.UUID `json:"review_id,omitempty" gorm:"type:uuid"`
+	AuthorID  uuid.UUID  `json:"author_id" gorm:"type:uuid;not null"`
+	Body      string     `json:"body" gorm:"not null"`
+	Path      string     `json:"path,omitempty"`
+	Line      int        `json:"line,omitempty"`
+	CommitSHA string     `json:"commit_sha,omitempty" gorm:"size:40"`
+	Outdated  bool       `json:"outdated" gorm:"-"` // anchored to a commit that is no longer the head
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// TableName returns the database table name.
+func (GitPRComment) TableName() string {
+	return "pull_request_comments"
+}
+
+// IsInline returns true if the comment is anchored to a file.
+func (c *GitPRComment) IsInline() bool {
+	return c.Path != ""
+}
+
+// GitPRReviewRequest represents a pending request for a user's review.
+type GitPRReviewRequest struct {
+	PRID        uuid.UUID `json:"pr_id" gorm:"column:pr_id;type:uuid;primaryKey"`
+	ReviewerID  uuid.UUID `json:"reviewer_id" gorm:"type:uuid;primaryKey"`
+	RequestedBy uuid.UUID `json:"requested_by" gorm:"type:uuid;not null"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// TableName returns the database table name.
+func (GitPRReviewRequest) TableName() string {
+	return "pull_request_review_requests"
+}
+
+// ===== Branch Protection Types =====
+
+// GitBranchProtection represents a protection rule for the branches whose
+// name matches Pattern, a branch name or a glob such as release/*.
+type GitBranchProtection struct {
+	ID                   uuid.UUID `json:"id" gorm:"type:uuid;primaryKey;default:gen_random_uuid()"`
+	RepoID               uuid.UUID `json:"repo_id" gorm:"type:uuid;not null;uniqueIndex:idx_git_branch_protections_pattern"`
+	Pattern              string    `json:"pattern" gorm:"size:255;not null;uniqueIndex:idx_git_branch_protections_pattern"`
+	BlockForcePush       bool      `json:"block_force_push" gorm:"not null"`
+	BlockDeletion        bool      `json:"block_deletion" gorm:"not null"`
+	RequirePullRequest   bool      `json:"require_pull_request" gorm:"not null"`
+	RequiredApprovals    int       `json:"required_approvals" gorm:"not null"`
+	RestrictPushToAdmins bool      `json:"restrict_push_to_admins" gorm:"not null"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
+
+// TableName returns the database table name.
+func (GitBranchProtection) TableName() string {
+	return "git_branch_protections"
+}
+
+// Matches returns true if the rule applies to a branch.
+func (p *GitBranchProtection) Matches(branch string) bool {
+	ok, err := path.Match(p.Pattern, branch)
+	return err == nil && ok
+}
+
 // ===== LFS Types =====
 
 // GitLFSObject represents an LFS object (content-addressable).
@@ -214,6 +341,113 @@ func (s GitService) IsWrite() bool {
 	return s == GitServiceReceivePack
 }
 
+// ===== Repository Browsing Types =====
+
+// GitRefType represents the kind of a ref.
+type GitRefType string
+
+const (
+	GitRefTypeBranch GitRefType = "branch"
+	GitRefTypeTag    GitRefType = "tag"
+)
+
+// GitRef represents a branch or tag.
+type GitRef struct {
+	Name      string     `json:"name"`
+	Type      GitRefType `json:"type"`
+	SHA       string     `json:"sha"` // commit the ref points to
+	IsDefault bool       `json:"is_default,omitempty"`
+}
+
+// GitTreeEntry represents an entry of a tree.
+type GitTreeEntry struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Type string `json:"type"` // blob, tree or commit (submodule)
+	Mode string `json:"mode"`
+	SHA  string `json:"sha"`
+	Size int64  `json:"size,omitempty"` // blobs only
+}
+
+// GitTree represents the contents of a directory at a commit.
+type GitTree struct {
+	SHA       string          `json:"sha"`
+	CommitSHA string          `json:"commit_sha"`
+	Path      string          `json:"path"`
+	Entries   []*GitTreeEntry `json:"entries"`
+}
+
+// GitSignature represents the author or committer of a commit.
+type GitSignature struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+// GitCommit represents a commit.
+type GitCommit struct {
+	SHA        string           `json:"sha"`
+	Message    string           `json:"message"`
+	Author     GitSignature     `json:"author"`
+	Committer  GitSignature     `json:"committer"`
+	ParentSHAs []string         `json:"parent_shas"`
+	Files      []*GitFileChange `json:"files,omitempty"` // single commit details only
+
+	// FilesTruncated is set when the commit changed too many files to list
+	// them all.
+	FilesTruncated bool `json:"files_truncated,omitempty"`
+}
+
+// GitFileChangeStatus represents how a file changed.
+type GitFileChangeStatus string
+
+const (
+	GitFileAdded    GitFileChangeStatus = "added"
+	GitFileModified GitFileChangeStatus = "modified"
+	GitFileDeleted  GitFileChangeStatus = "deleted"
+	GitFileRenamed  GitFileChangeStatus = "renamed"
+)
+
+// GitFileAction represents a change to a file committed through the API.
+type GitFileAction string
+
+const (
+	GitFileActionCreate GitFileAction = "create"
+	GitFileActionUpdate GitFileAction = "update"
+	GitFileActionDelete GitFileAction = "delete"
+	GitFileActionMove   GitFileAction = "move"
+)
+
+// IsValid checks if the file action is valid.
+func (a GitFileAction) IsValid() bool {
+	switch a {
+	case GitFileActionCreate, GitFileActionUpdate, GitFileActionDelete, GitFileActionMove:
+		return true
+	}
+	return false
+}
+
+// GitFileChange represents a file changed between two trees.
+type GitFileChange struct {
+	Path      string              `json:"path"`
+	OldPath   string              `json:"old_path,omitempty"` // renames only
+	Status    GitFileChangeStatus `json:"status"`
+	Additions int                 `json:"additions"`
+	Deletions int                 `json:"deletions"`
+	Binary    bool                `json:"binary,omitempty"`
+}
+
+// GitDiff represents the changes between two commits.
+type GitDiff struct {
+	BaseSHA string           `json:"base_sha"`
+	HeadSHA string           `json:"head_sha"`
+	Files   []*GitFileChange `json:"files"`
+	Patch   string           `json:"patch"` // unified diff, empty if truncated
+
+	// Truncated is set when the diff is past the size or file count limits.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
 // ===== Request/Response Types for LFS =====
 
 // GitLFSBatchRequest represents an LFS batch API request.
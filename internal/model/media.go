@@ -1,9 +1,11 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // MediaProviderType represents the type of media provider.
@@ -19,9 +21,12 @@ const (
 type MediaCapability string
 
 const (
-	MediaCapabilityImage MediaCapability = "image"
-	MediaCapabilityVideo MediaCapability = "video"
-	MediaCapabilityAudio MediaCapability = "audio"
+	MediaCapabilityImage          MediaCapability = "image"
+	MediaCapabilityImageEdit      MediaCapability = "image_edit"      // edits and inpainting with a mask
+	MediaCapabilityImageVariation MediaCapability = "image_variation" // variations of an existing image
+	MediaCapabilityImageUpscale   MediaCapability = "image_upscale"
+	MediaCapabilityVideo          MediaCapability = "video"
+	MediaCapabilityAudio          MediaCapability = "audio"
 )
 
 // MediaProvider represents a media provider configuration.
@@ -32,6 +37,7 @@ type MediaProvider struct {
 	BaseURL      string            `json:"base_url"`
 	EncryptedKey string            `json:"-"` // Never expose in JSON
 	Enabled      bool              `json:"enabled"`
+	Options      map[string]any    `json:"options" gorm:"type:jsonb;serializer:json"` // adapter-specific settings
 	CreatedAt    time.Time         `json:"created_at"`
 	UpdatedAt    time.Time         `json:"updated_at"`
 }
@@ -43,13 +49,15 @@ func (MediaProvider) TableName() string {
 
 // MediaModel represents a media generation model.
 type MediaModel struct {
-	ID           string            `json:"id" gorm:"primaryKey"`
-	ProviderID   uuid.UUID         `json:"provider_id"`
-	Name         string            `json:"name"`
-	Capabilities []MediaCapability `json:"capabilities" gorm:"type:jsonb;serializer:json"`
-	Enabled      bool              `json:"enabled"`
-	CreatedAt    time.Time         `json:"created_at"`
-	UpdatedAt    time.Time         `json:"updated_at"`
+	ID            string            `json:"id" gorm:"primaryKey"`
+	ProviderID    uuid.UUID         `json:"provider_id"`
+	Name          string            `json:"name"`
+	Capabilities  []MediaCapability `json:"capabilities" gorm:"type:jsonb;serializer:json"`
+	CostPerImage  float64           `json:"cost_per_image"`  // USD per generated image
+	CostPerSecond float64           `json:"cost_per_second"` // USD per second of video
+	Enabled       bool              `json:"enabled"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
 }
 
 // TableName returns the table name.
@@ -67,6 +75,70 @@ func (m *MediaModel) HasCapability(cap MediaCapability) bool {
 	return false
 }
 
+// UnitCost returns the model's price for one unit of output for a
+// capability: a second of video, or an image for everything else.
+func (m *MediaModel) UnitCost(cap MediaCapability) float64 {
+	if cap == MediaCapabilityVideo {
+		return m.CostPerSecond
+	}
+	return m.CostPerImage
+}
+
+// ===== Routing Types =====
+
+// MediaRoutingContext contains the context for media routing decisions.
+type MediaRoutingContext struct {
+	// Capability every candidate must support
+	Capability MediaCapability
+
+	// Expected output: number of images, or seconds of video
+	Units float64
+
+	// Models the user asked for, most preferred first
+	PreferredModels []string
+
+	// Health status keyed by provider ID
+	ProviderHealth map[string]bool
+}
+
+// MediaScoredCandidate represents a model and its provider with scoring information.
+type MediaScoredCandidate struct {
+	Provider       *MediaProvider
+	Model          *MediaModel
+	Score          float64
+	ScoreBreakdown map[string]float64
+	Reasons        []string
+}
+
+// NewMediaScoredCandidate creates a new scored candidate.
+func NewMediaScoredCandidate(p *MediaProvider, m *MediaModel) *MediaScoredCandidate {
+	return &MediaScoredCandidate{
+		Provider:       p,
+		Model:          m,
+		ScoreBreakdown: make(map[string]float64),
+		Reasons:        make([]string, 0),
+	}
+}
+
+// AddScore adds a score from a strategy.
+func (c *MediaScoredCandidate) AddScore(strategy string, score float64, reason string) {
+	c.Score += score
+	c.ScoreBreakdown[strategy] = score
+	if reason != "" {
+		c.Reasons = append(c.Reasons, reason)
+	}
+}
+
+// MediaRoutingInfo describes how a media request was routed.
+type MediaRoutingInfo struct {
+	ProviderUsed string   `json:"provider_used"`
+	ModelUsed    string   `json:"model_used"`
+	Reason       string   `json:"reason,omitempty"`
+	FallbackFrom []string `json:"fallback_from,omitempty"` // models that failed before this one
+	LatencyMs    int64    `json:"latency_ms"`
+	CostUSD      float64  `json:"cost_usd"`
+}
+
 // MediaTaskStatus represents the status of a media task.
 type MediaTaskStatus string
 
@@ -80,16 +152,20 @@ const (
 
 // MediaTask represents an async media generation task.
 type MediaTask struct {
-	ID        uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey"`
-	OwnerID   uuid.UUID       `json:"owner_id" gorm:"type:uuid;index"`
-	Type      string          `json:"type"` // image_generation, video_generation
-	Status    MediaTaskStatus `json:"status"`
-	Progress  int             `json:"progress"`
-	Input     *string         `json:"input" gorm:"type:jsonb"`  // JSON serialized, nullable
-	Output    *string         `json:"output" gorm:"type:jsonb"` // JSON serialized, nullable
-	Error     string          `json:"error,omitempty"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
+	ID       uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey"`
+	OwnerID  uuid.UUID       `json:"owner_id" gorm:"type:uuid;index"`
+	Type     string          `json:"type"` // image_generation, video_generation
+	Status   MediaTaskStatus `json:"status"`
+	Progress int             `json:"progress"`
+	Input    *string         `json:"input" gorm:"type:jsonb"`  // JSON serialized, nullable
+	Output   *string         `json:"output" gorm:"type:jsonb"` // JSON serialized, nullable
+	Error    string          `json:"error,omitempty"`
+	// Set once the task has been submitted to a provider, so a restarted
+	// worker resumes polling instead of submitting again.
+	ModelID        string    `json:"model_id,omitempty"`
+	ExternalTaskID string    `json:"external_task_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 // TableName returns the table name.
@@ -97,6 +173,81 @@ func (MediaTask) TableName() string {
 	return "media_tasks"
 }
 
+// Event returns the task's current state as a task event.
+func (t *MediaTask) Event() *TaskEvent {
+	event := &TaskEvent{
+		TaskID:    t.ID,
+		OwnerID:   t.OwnerID,
+		Source:    TaskEventSourceMedia,
+		Type:      t.Type,
+		Status:    string(t.Status),
+		Progress:  t.Progress,
+		Error:     t.Error,
+		UpdatedAt: t.UpdatedAt,
+	}
+	if t.Output != nil && json.Valid([]byte(*t.Output)) {
+		event.Output = json.RawMessage(*t.Output)
+	}
+	return event
+}
+
+// MediaAsset is a generated image or video kept in our object storage.
+// Assets are deduplicated per owner by content hash.
+type MediaAsset struct {
+	ID              uuid.UUID       `json:"id" gorm:"type:uuid;primaryKey"`
+	OwnerID         uuid.UUID       `json:"owner_id" gorm:"type:uuid;index"`
+	TeamID          *uuid.UUID      `json:"team_id,omitempty" gorm:"type:uuid;index"` // shared with this team
+	TaskID          *uuid.UUID      `json:"task_id,omitempty" gorm:"type:uuid"`
+	Type            MediaCapability `json:"type"`
+	Name            string          `json:"name"`
+	Folder          string          `json:"folder"` // slash-separated path, empty for the root
+	Tags            pq.StringArray  `json:"tags" gorm:"type:text[]"`
+	StorageKey      string          `json:"-"`
+	ContentHash     string          `json:"content_hash"` // hex SHA-256
+	MimeType        string          `json:"mime_type"`
+	SizeBytes       int64           `json:"size_bytes"`
+	Width           int             `json:"width,omitempty"`
+	Height          int             `json:"height,omitempty"`
+	DurationSeconds int             `json:"duration_seconds,omitempty"`
+	ProviderID      uuid.UUID       `json:"provider_id" gorm:"type:uuid"`
+	ModelID         string          `json:"model_id"`
+	Prompt          string          `json:"prompt,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	DeletedAt       *time.Time      `json:"deleted_at,omitempty" gorm:"index"`
+
+	// URL is a presigned download URL, filled in when the asset is returned.
+	URL string `json:"url,omitempty" gorm:"-"`
+	// Task is the generation task that produced the asset, if loaded.
+	Task *MediaTask `json:"task,omitempty" gorm:"-"`
+}
+
+// TableName returns the table name.
+func (MediaAsset) TableName() string {
+	return "media_assets"
+}
+
+// IsDeleted returns true if the asset is in the trash.
+func (a *MediaAsset) IsDeleted() bool {
+	return a.DeletedAt != nil
+}
+
+// MediaAssetFilter represents media asset query filters.
+type MediaAssetFilter struct {
+	OwnerID *uuid.UUID
+	TeamID  *uuid.UUID
+	Type    MediaCapability
+	ModelID string
+	Search  string   // matched against name and prompt
+	Tags    []string // assets must carry all of them
+	Folder  *string
+	Since   *time.Time
+	Until   *time.Time
+	Deleted bool // list the trash instead of live assets
+	Limit   int
+	Offset  int
+}
+
 // ImageRequest represents an image generation request.
 type ImageRequest struct {
 	Prompt         string `json:"prompt"`
@@ -109,6 +260,35 @@ type ImageRequest struct {
 	Model          string `json:"model,omitempty"`
 }
 
+// ImageEditRequest represents an image edit request. Transparent areas of
+// the mask (or of the image, without a mask) are regenerated.
+type ImageEditRequest struct {
+	Image          []byte `json:"-"`
+	Mask           []byte `json:"-"`
+	Prompt         string `json:"prompt"`
+	N              int    `json:"n,omitempty"`
+	Size           string `json:"size,omitempty"`
+	ResponseFormat string `json:"response_format,omitempty"`
+	Model          string `json:"model,omitempty"`
+}
+
+// ImageVariationRequest represents an image variation request.
+type ImageVariationRequest struct {
+	Image          []byte `json:"-"`
+	N              int    `json:"n,omitempty"`
+	Size           string `json:"size,omitempty"`
+	ResponseFormat string `json:"response_format,omitempty"`
+	Model          string `json:"model,omitempty"`
+}
+
+// ImageUpscaleRequest represents an image upscale request.
+type ImageUpscaleRequest struct {
+	Image          []byte `json:"-"`
+	Scale          int    `json:"scale,omitempty"` // 2 or 4
+	ResponseFormat string `json:"response_format,omitempty"`
+	Model          string `json:"model,omitempty"`
+}
+
 // ImageResponse represents an image generation response.
 type ImageResponse struct {
 	Images    []*GeneratedImage `json:"images"`
@@ -122,6 +302,7 @@ type GeneratedImage struct {
 	URL           string `json:"url,omitempty"`
 	B64JSON       string `json:"b64_json,omitempty"`
 	RevisedPrompt string `json:"revised_prompt,omitempty"`
+	AssetID       string `json:"asset_id,omitempty"`
 }
 
 // ImageUsage represents token/credit usage for image generation.
@@ -182,6 +363,7 @@ type GeneratedVideo struct {
 	FPS      int    `json:"fps"`
 	FileSize int64  `json:"file_size,omitempty"`
 	Format   string `json:"format,omitempty"`
+	AssetID  string `json:"asset_id,omitempty"`
 }
 
 // VideoUsage represents usage for video generation.
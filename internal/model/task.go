@@ -0,0 +1,41 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskEventSource identifies the store a task event comes from.
+type TaskEventSource string
+
+const (
+	TaskEventSourceTask  TaskEventSource = "task"  // generic tasks run by the task manager
+	TaskEventSourceMedia TaskEventSource = "media" // media generation tasks
+)
+
+// TaskEvent is a change in an async task's status, progress or output,
+// pushed to clients watching the task.
+type TaskEvent struct {
+	TaskID    uuid.UUID       `json:"task_id"`
+	OwnerID   uuid.UUID       `json:"owner_id"`
+	Source    TaskEventSource `json:"source"`
+	Type      string          `json:"type"`
+	Status    string          `json:"status"`
+	Progress  int             `json:"progress"`
+	Output    json.RawMessage `json:"output,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// IsTerminal reports whether the task has finished, so no further events
+// are expected. Dead tasks only change again if an operator requeues them.
+func (e *TaskEvent) IsTerminal() bool {
+	switch e.Status {
+	case "completed", "failed", "cancelled", "dead":
+		return true
+	default:
+		return false
+	}
+}
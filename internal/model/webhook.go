@@ -0,0 +1,110 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Account event types that webhook endpoints can subscribe to.
+const (
+	AccountEventTaskCompleted       = "task.completed"
+	AccountEventTaskFailed          = "task.failed"
+	AccountEventPaymentSucceeded    = "payment.succeeded"
+	AccountEventPaymentFailed       = "payment.failed"
+	AccountEventSubscriptionUpdated = "subscription.updated"
+)
+
+// AccountEventTypes lists every account event type.
+var AccountEventTypes = []string{
+	AccountEventTaskCompleted,
+	AccountEventTaskFailed,
+	AccountEventPaymentSucceeded,
+	AccountEventPaymentFailed,
+	AccountEventSubscriptionUpdated,
+}
+
+// WebhookAllEvents subscribes an endpoint to every event type, including
+// types added later.
+const WebhookAllEvents = "*"
+
+// AccountEvent is something that happened to a user's account, delivered
+// to their webhook endpoints.
+type AccountEvent struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	OwnerID   uuid.UUID `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// WebhookEndpoint is a URL that receives signed deliveries of a user's
+// account events.
+type WebhookEndpoint struct {
+	ID              uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	OwnerID         uuid.UUID      `json:"owner_id" gorm:"type:uuid;index"`
+	URL             string         `json:"url"`
+	Description     string         `json:"description"`
+	EventTypes      pq.StringArray `json:"event_types" gorm:"type:text[]"`
+	EncryptedSecret string         `json:"-"`
+	Active          bool           `json:"active"`
+	// DisabledReason says why the endpoint was disabled automatically.
+	DisabledReason      string     `json:"disabled_reason,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastDeliveryAt      *time.Time `json:"last_delivery_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// TableName returns the table name.
+func (WebhookEndpoint) TableName() string {
+	return "webhook_endpoints"
+}
+
+// Subscribes reports whether the endpoint receives events of the given type.
+func (e *WebhookEndpoint) Subscribes(eventType string) bool {
+	return slices.Contains(e.EventTypes, WebhookAllEvents) || slices.Contains(e.EventTypes, eventType)
+}
+
+// WebhookDeliveryStatus represents the status of a webhook delivery.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDeliveryAttempt records one request made for a delivery.
+type WebhookDeliveryAttempt struct {
+	Number       int       `json:"number"`
+	StatusCode   int       `json:"status_code,omitempty"`
+	ResponseBody string    `json:"response_body,omitempty"` // truncated
+	Error        string    `json:"error,omitempty"`
+	DurationMs   int64     `json:"duration_ms"`
+	AttemptedAt  time.Time `json:"attempted_at"`
+}
+
+// WebhookDelivery is one event sent to one endpoint, with every attempt
+// made to send it.
+type WebhookDelivery struct {
+	ID             uuid.UUID                `json:"id" gorm:"type:uuid;primaryKey"`
+	EndpointID     uuid.UUID                `json:"endpoint_id" gorm:"type:uuid;index"`
+	EventID        uuid.UUID                `json:"event_id" gorm:"type:uuid"`
+	EventType      string                   `json:"event_type"`
+	Payload        string                   `json:"payload" gorm:"type:jsonb"` // the exact request body
+	Status         WebhookDeliveryStatus    `json:"status"`
+	ResponseStatus int                      `json:"response_status"` // of the last attempt
+	Attempts       []WebhookDeliveryAttempt `json:"attempts" gorm:"type:jsonb;serializer:json"`
+	RedeliveryOf   *uuid.UUID               `json:"redelivery_of,omitempty" gorm:"type:uuid"`
+	DeliveredAt    *time.Time               `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+// TableName returns the table name.
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -113,6 +113,40 @@ type AIModelGroupHttpPort interface {
 	DeleteGroup(c *gin.Context)
 }
 
+// ===== Model Alias Ports =====
+
+// AIModelAliasHttpPort defines model alias HTTP handler interface.
+type AIModelAliasHttpPort interface {
+	// ListAliases handles GET /admin/ai/aliases.
+	ListAliases(c *gin.Context)
+
+	// GetAlias handles GET /admin/ai/aliases/:id.
+	GetAlias(c *gin.Context)
+
+	// CreateAlias handles POST /admin/ai/aliases.
+	CreateAlias(c *gin.Context)
+
+	// UpdateAlias handles PUT /admin/ai/aliases/:id.
+	UpdateAlias(c *gin.Context)
+
+	// DeleteAlias handles DELETE /admin/ai/aliases/:id.
+	DeleteAlias(c *gin.Context)
+}
+
+// ===== Request Log Ports =====
+
+// AIRequestLogHttpPort defines request audit log HTTP handler interface.
+type AIRequestLogHttpPort interface {
+	// ListRequestLogs handles GET /admin/ai/request-logs.
+	ListRequestLogs(c *gin.Context)
+
+	// GetRequestLog handles GET /admin/ai/request-logs/:id.
+	GetRequestLog(c *gin.Context)
+
+	// ReplayRequest handles POST /admin/ai/request-logs/:id/replay.
+	ReplayRequest(c *gin.Context)
+}
+
 // ===== Public API Ports =====
 
 // AIPublicHttpPort defines public AI API handler interface (for listing available models).
@@ -22,6 +22,7 @@ type GitDomain interface {
 	ListPublicRepos(ctx context.Context, filter *GitRepoFilter) ([]*model.GitRepo, int64, error)
 	UpdateRepo(ctx context.Context, id uuid.UUID, userID uuid.UUID, input *GitUpdateRepoInput) (*model.GitRepo, error)
 	DeleteRepo(ctx context.Context, id uuid.UUID, userID uuid.UUID) error
+	ForkRepo(ctx context.Context, id uuid.UUID, userID uuid.UUID, input *GitForkRepoInput) (*model.GitRepo, error)
 
 	// Access control
 	CheckAccess(ctx context.Context, repoID, userID uuid.UUID, required model.GitPermission) error
@@ -38,7 +39,35 @@ type GitDomain interface {
 	GetPR(ctx context.Context, repoID uuid.UUID, number int) (*model.GitPullRequest, error)
 	ListPRs(ctx context.Context, repoID uuid.UUID, status *model.GitPRStatus, limit, offset int) ([]*model.GitPullRequest, int64, error)
 	UpdatePR(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID, input *GitUpdatePRInput) (*model.GitPullRequest, error)
-	MergePR(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID) (*model.GitPullRequest, error)
+	MergePR(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID, input *GitMergePRInput) (*model.GitPullRequest, error)
+	CheckMergeability(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID) (*GitMergeability, error)
+
+	// Pull request reviews
+	CreatePRComment(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID, input *GitPRCommentInput) (*model.GitPRComment, error)
+	ListPRComments(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID) ([]*model.GitPRComment, error)
+	UpdatePRComment(ctx context.Context, repoID uuid.UUID, number int, commentID, userID uuid.UUID, body string) (*model.GitPRComment, error)
+	DeletePRComment(ctx context.Context, repoID uuid.UUID, number int, commentID, userID uuid.UUID) error
+	SubmitReview(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID, input *GitSubmitReviewInput) (*model.GitPRReview, error)
+	ListReviews(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID) ([]*model.GitPRReview, error)
+	RequestReviewers(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID, reviewerIDs []uuid.UUID) ([]*model.GitPRReviewRequest, error)
+	ListReviewRequests(ctx context.Context, repoID uuid.UUID, number int, userID uuid.UUID) ([]*model.GitPRReviewRequest, error)
+	RemoveReviewRequest(ctx context.Context, repoID uuid.UUID, number int, userID, reviewerID uuid.UUID) error
+
+	// Branch protection
+	CreateBranchProtection(ctx context.Context, repoID, userID uuid.UUID, input *GitBranchProtectionInput) (*model.GitBranchProtection, error)
+	ListBranchProtections(ctx context.Context, repoID, userID uuid.UUID) ([]*model.GitBranchProtection, error)
+	UpdateBranchProtection(ctx context.Context, repoID, ruleID, userID uuid.UUID, input *GitBranchProtectionInput) (*model.GitBranchProtection, error)
+	DeleteBranchProtection(ctx context.Context, repoID, ruleID, userID uuid.UUID) error
+	CheckPush(ctx context.Context, repoID, userID uuid.UUID, update *GitRefUpdate) error
+
+	// Repository browsing
+	ListRefs(ctx context.Context, repoID, userID uuid.UUID, refType model.GitRefType) ([]*model.GitRef, error)
+	GetTree(ctx context.Context, repoID, userID uuid.UUID, ref, path string) (*model.GitTree, error)
+	GetBlob(ctx context.Context, repoID, userID uuid.UUID, ref, path string) (*GitBlob, error)
+	ListCommits(ctx context.Context, repoID, userID uuid.UUID, filter *GitCommitFilter) ([]*model.GitCommit, bool, error)
+	GetCommit(ctx context.Context, repoID, userID uuid.UUID, sha string) (*model.GitCommit, error)
+	Diff(ctx context.Context, repoID, userID uuid.UUID, base, head string) (*model.GitDiff, error)
+	CommitFiles(ctx context.Context, repoID, userID uuid.UUID, input *GitCommitFilesInput) (*model.GitCommit, error)
 
 	// Storage operations
 	GetStorageStats(ctx context.Context, repoID uuid.UUID) (*GitStorageStats, error)
@@ -50,6 +79,9 @@ type GitDomain interface {
 	// Git protocol helpers
 	UpdatePushedAt(ctx context.Context, repoID uuid.UUID) error
 	UpdateRepoSize(ctx context.Context, repoID uuid.UUID, sizeBytes, lfsSizeBytes int64) error
+	LimitPushPack(ctx context.Context, repoID uuid.UUID, pack io.ReadCloser) (io.ReadCloser, error)
+	CheckPushContent(ctx context.Context, repoID uuid.UUID, update *GitRefUpdate, locks []*model.GitLFSLock) error
+	ProcessPush(ctx context.Context, repoID, pusherID uuid.UUID, updates []*GitRefUpdate) error
 }
 
 // ===== LFS Domain Port =====
@@ -65,9 +97,10 @@ type GitLFSDomain interface {
 	LinkObject(ctx context.Context, repoID uuid.UUID, oid string) error
 
 	// Storage operations
-	Upload(ctx context.Context, oid string, reader io.Reader, size int64) error
+	Upload(ctx context.Context, repoID uuid.UUID, oid string, reader io.Reader, size int64) error
 	Download(ctx context.Context, oid string) (io.ReadCloser, int64, error)
 	VerifyObject(ctx context.Context, oid string, expectedSize int64) error
+	VerifyUpload(ctx context.Context, repoID uuid.UUID, oid, uploadID string) error
 
 	// Presigned URL generation
 	GenerateUploadURL(ctx context.Context, oid string, size int64) (*GitPresignedURLResult, error)
@@ -99,11 +132,18 @@ type GitCreateRepoInput struct {
 
 // GitUpdateRepoInput represents input for updating a repository.
 type GitUpdateRepoInput struct {
-	Name          string
-	Description   string
-	Visibility    model.GitVisibility
-	DefaultBranch string
-	LFSEnabled    *bool
+	Name              string
+	Description       string
+	Visibility        model.GitVisibility
+	DefaultBranch     string
+	LFSEnabled        *bool
+	RequiredApprovals *int
+}
+
+// GitForkRepoInput represents input for forking a repository.
+type GitForkRepoInput struct {
+	Name   string     // defaults to the parent's name
+	TeamID *uuid.UUID // fork into a team the user administers
 }
 
 // GitRepoFilter represents repository query filters.
@@ -119,6 +159,7 @@ type GitRepoFilter struct {
 type GitCreatePRInput struct {
 	Title        string
 	Description  string
+	SourceRepoID *uuid.UUID // a fork of the repository; nil for the repository itself
 	SourceBranch string
 	TargetBranch string
 }
@@ -130,6 +171,42 @@ type GitUpdatePRInput struct {
 	Status      string // "open" or "closed"
 }
 
+// GitMergePRInput represents input for merging a pull request.
+type GitMergePRInput struct {
+	Strategy          model.GitMergeStrategy // defaults to a merge commit
+	CommitMessage     string                 // defaults to a message built from the pull request
+	ExpectedTargetSHA string                 // if set, the merge fails when the target branch moved
+	AuthorName        string
+	AuthorEmail       string
+}
+
+// GitCommitFilter represents commit log query filters.
+type GitCommitFilter struct {
+	Ref      string // defaults to the default branch
+	Path     string // only commits touching this file or directory
+	Page     int
+	PageSize int
+}
+
+// GitCommitFilesInput represents input for committing files without a git
+// client.
+type GitCommitFilesInput struct {
+	Branch            string // defaults to the default branch
+	ExpectedParentSHA string // if set, the commit fails when the branch moved
+	Message           string
+	AuthorName        string
+	AuthorEmail       string
+	Operations        []*GitFileOperation
+}
+
+// GitFileOperation represents one file change of a commit.
+type GitFileOperation struct {
+	Action   model.GitFileAction
+	Path     string
+	FromPath string // move only
+	Content  []byte // create and update; optional for move
+}
+
 // ===== Output Types =====
 
 // GitStorageStats represents repository storage statistics.
@@ -154,6 +231,69 @@ type GitPresignedURLResult struct {
 	Method    string
 	ExpiresAt time.Time
 	Headers   map[string]string
+	// UploadID names a staged upload that still has to be verified.
+	UploadID string
+}
+
+// GitMergeability represents whether a pull request can be merged.
+type GitMergeability struct {
+	Mergeable      bool
+	CanFastForward bool
+	Conflicts      []string
+	BaseSHA        string
+	SourceSHA      string
+	TargetSHA      string
+
+	// Review requirements of the repository
+	Approvals         int
+	RequiredApprovals int
+	ChangesRequested  bool
+}
+
+// GitPRCommentInput represents input for commenting on a pull request.
+// Setting Path makes the comment inline; CommitSHA defaults to the head of
+// the source branch.
+type GitPRCommentInput struct {
+	Body      string
+	Path      string
+	Line      int
+	CommitSHA string
+}
+
+// GitSubmitReviewInput represents input for reviewing a pull request.
+type GitSubmitReviewInput struct {
+	State    model.GitPRReviewState
+	Body     string
+	Comments []*GitPRCommentInput
+}
+
+// GitBranchProtectionInput represents input for creating or replacing a
+// branch protection rule. Requiring approvals also requires a pull request.
+type GitBranchProtectionInput struct {
+	Pattern              string
+	BlockForcePush       bool
+	BlockDeletion        bool
+	RequirePullRequest   bool
+	RequiredApprovals    int
+	RestrictPushToAdmins bool
+}
+
+// GitRefUpdate represents one ref update of a push. A zero SHA stands for a
+// ref that doesn't exist before or after the push.
+type GitRefUpdate struct {
+	Ref    string // full name, e.g. refs/heads/main
+	OldSHA string
+	NewSHA string
+}
+
+// GitBlob represents the content of a file. Files stored in LFS are
+// resolved to the object the pointer refers to.
+type GitBlob struct {
+	Path    string
+	SHA     string
+	Size    int64
+	LFSOID  string // set when the content comes from LFS storage
+	Content io.ReadCloser
 }
 
 // GitVerifyLocksResult represents the result of verifying locks.
@@ -163,13 +303,28 @@ type GitVerifyLocksResult struct {
 	NextCursor string
 }
 
+// ===== Credential Validator Port =====
+
+// GitCredentialValidator resolves HTTP Basic credentials sent by git clients.
+type GitCredentialValidator interface {
+	// ValidateGitCredentials returns the user the credentials belong to.
+	// The password may be a personal access token or a system API key.
+	ValidateGitCredentials(ctx context.Context, username, password string) (uuid.UUID, error)
+}
+
 // ===== Quota Checker Port =====
 
 // GitStorageQuotaChecker defines storage quota checking operations.
 type GitStorageQuotaChecker interface {
-	// GetStorageQuota returns the storage quota for a user (-1 for unlimited).
+	// GetStorageQuota returns the git storage quota for a user (-1 for unlimited).
 	GetStorageQuota(ctx context.Context, userID uuid.UUID) (int64, error)
 
-	// GetStorageUsed returns the storage used by a user.
+	// GetStorageUsed returns the git storage used by a user.
 	GetStorageUsed(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// GetLFSStorageQuota returns the LFS storage quota for a user (-1 for unlimited).
+	GetLFSStorageQuota(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// GetLFSStorageUsed returns the LFS storage used by a user.
+	GetLFSStorageUsed(ctx context.Context, userID uuid.UUID) (int64, error)
 }
@@ -2,6 +2,7 @@ package inbound
 
 import (
 	"context"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -20,6 +21,9 @@ type MediaImageGenerationInput struct {
 	Style          string `json:"style,omitempty"`
 	Model          string `json:"model,omitempty"`
 	ResponseFormat string `json:"response_format,omitempty"`
+
+	// Models to try first when model is "auto", most preferred first.
+	PreferredModels []string `json:"preferred_models,omitempty"`
 }
 
 // MediaImageGenerationOutput represents an image generation response.
@@ -29,6 +33,54 @@ type MediaImageGenerationOutput struct {
 	Usage     *model.ImageUsage       `json:"usage,omitempty"`
 	CreatedAt int64                   `json:"created_at"`
 	TaskID    string                  `json:"task_id,omitempty"`
+
+	// Routing metadata
+	Routing *model.MediaRoutingInfo `json:"_routing,omitempty"`
+}
+
+// MediaImageEditInput represents an image edit or inpainting request. The
+// image and mask are uploaded files or references to stored assets.
+type MediaImageEditInput struct {
+	Prompt         string `json:"prompt" form:"prompt" binding:"required"`
+	ImageAssetID   string `json:"image_asset_id,omitempty" form:"image_asset_id"`
+	MaskAssetID    string `json:"mask_asset_id,omitempty" form:"mask_asset_id"`
+	N              int    `json:"n,omitempty" form:"n"`
+	Size           string `json:"size,omitempty" form:"size"`
+	Model          string `json:"model,omitempty" form:"model"`
+	ResponseFormat string `json:"response_format,omitempty" form:"response_format"`
+
+	PreferredModels []string `json:"preferred_models,omitempty" form:"preferred_models"`
+
+	// Uploaded files, set by the HTTP adapter.
+	Image []byte `json:"-" form:"-"`
+	Mask  []byte `json:"-" form:"-"`
+}
+
+// MediaImageVariationInput represents an image variation request.
+type MediaImageVariationInput struct {
+	ImageAssetID   string `json:"image_asset_id,omitempty" form:"image_asset_id"`
+	N              int    `json:"n,omitempty" form:"n"`
+	Size           string `json:"size,omitempty" form:"size"`
+	Model          string `json:"model,omitempty" form:"model"`
+	ResponseFormat string `json:"response_format,omitempty" form:"response_format"`
+
+	PreferredModels []string `json:"preferred_models,omitempty" form:"preferred_models"`
+
+	// Uploaded file, set by the HTTP adapter.
+	Image []byte `json:"-" form:"-"`
+}
+
+// MediaImageUpscaleInput represents an image upscale request.
+type MediaImageUpscaleInput struct {
+	ImageAssetID   string `json:"image_asset_id,omitempty" form:"image_asset_id"`
+	Scale          int    `json:"scale,omitempty" form:"scale"`
+	Model          string `json:"model,omitempty" form:"model"`
+	ResponseFormat string `json:"response_format,omitempty" form:"response_format"`
+
+	PreferredModels []string `json:"preferred_models,omitempty" form:"preferred_models"`
+
+	// Uploaded file, set by the HTTP adapter.
+	Image []byte `json:"-" form:"-"`
 }
 
 // MediaVideoGenerationInput represents a video generation request.
@@ -41,6 +93,9 @@ type MediaVideoGenerationInput struct {
 	Resolution  string `json:"resolution,omitempty"`
 	FPS         int    `json:"fps,omitempty"`
 	Model       string `json:"model,omitempty"`
+
+	// Models to try first when model is "auto", most preferred first.
+	PreferredModels []string `json:"preferred_models,omitempty"`
 }
 
 // MediaVideoGenerationOutput represents a video generation response.
@@ -65,6 +120,41 @@ type MediaTaskOutput struct {
 	UpdatedAt int64                 `json:"updated_at"`
 }
 
+// MediaAssetListInput represents asset library filters.
+type MediaAssetListInput struct {
+	TeamID  *uuid.UUID // list a team's shared assets instead of the user's own
+	Type    model.MediaCapability
+	Model   string
+	Query   string
+	Tags    []string
+	Folder  *string
+	Since   *time.Time
+	Until   *time.Time
+	Deleted bool
+	Limit   int
+	Offset  int
+}
+
+// MediaAssetListOutput represents a page of assets.
+type MediaAssetListOutput struct {
+	Assets []*model.MediaAsset `json:"assets"`
+	Total  int64               `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+// MediaAssetUpdateInput represents asset metadata changes. Nil fields are left unchanged.
+type MediaAssetUpdateInput struct {
+	Name   *string   `json:"name,omitempty"`
+	Folder *string   `json:"folder,omitempty"`
+	Tags   *[]string `json:"tags,omitempty"`
+}
+
+// MediaAssetShareInput represents sharing an asset with a team.
+type MediaAssetShareInput struct {
+	TeamID uuid.UUID `json:"team_id" binding:"required"`
+}
+
 // --- Domain Interface ---
 
 // MediaDomain defines the media domain service interface.
@@ -72,6 +162,15 @@ type MediaDomain interface {
 	// GenerateImage generates images synchronously.
 	GenerateImage(ctx context.Context, userID uuid.UUID, input *MediaImageGenerationInput) (*MediaImageGenerationOutput, error)
 
+	// EditImage edits an image from a prompt, optionally within a mask.
+	EditImage(ctx context.Context, userID uuid.UUID, input *MediaImageEditInput) (*MediaImageGenerationOutput, error)
+
+	// CreateImageVariation generates variations of an image.
+	CreateImageVariation(ctx context.Context, userID uuid.UUID, input *MediaImageVariationInput) (*MediaImageGenerationOutput, error)
+
+	// UpscaleImage increases the resolution of an image.
+	UpscaleImage(ctx context.Context, userID uuid.UUID, input *MediaImageUpscaleInput) (*MediaImageGenerationOutput, error)
+
 	// GenerateVideo generates videos (async via task).
 	GenerateVideo(ctx context.Context, userID uuid.UUID, input *MediaVideoGenerationInput) (*MediaVideoGenerationOutput, error)
 
@@ -87,6 +186,26 @@ type MediaDomain interface {
 	// CancelTask cancels a task.
 	CancelTask(ctx context.Context, userID uuid.UUID, taskID uuid.UUID) error
 
+	// --- Asset library ---
+
+	// ListAssets lists the user's assets, or a team's shared assets.
+	ListAssets(ctx context.Context, userID uuid.UUID, input *MediaAssetListInput) (*MediaAssetListOutput, error)
+
+	// GetAsset returns an asset with the task that produced it.
+	GetAsset(ctx context.Context, userID uuid.UUID, assetID uuid.UUID) (*model.MediaAsset, error)
+
+	// UpdateAsset renames, tags or moves an asset.
+	UpdateAsset(ctx context.Context, userID uuid.UUID, assetID uuid.UUID, input *MediaAssetUpdateInput) (*model.MediaAsset, error)
+
+	// ShareAsset shares an asset with a team, or unshares it when teamID is nil.
+	ShareAsset(ctx context.Context, userID uuid.UUID, assetID uuid.UUID, teamID *uuid.UUID) (*model.MediaAsset, error)
+
+	// DeleteAsset moves an asset to the trash.
+	DeleteAsset(ctx context.Context, userID uuid.UUID, assetID uuid.UUID) error
+
+	// RestoreAsset restores an asset from the trash.
+	RestoreAsset(ctx context.Context, userID uuid.UUID, assetID uuid.UUID) (*model.MediaAsset, error)
+
 	// --- Provider management (admin) ---
 
 	// GetProvider returns a provider by ID.
@@ -102,6 +221,14 @@ type MediaDomain interface {
 
 	// ListModelsByCapability lists models with a capability.
 	ListModelsByCapability(ctx context.Context, cap model.MediaCapability) ([]*model.MediaModel, error)
+
+	// --- Background execution ---
+
+	// StartWorker starts executing pending tasks in the background.
+	StartWorker(ctx context.Context)
+
+	// StopWorker stops the background worker.
+	StopWorker()
 }
 
 // --- HTTP Port Interfaces ---
@@ -111,6 +238,15 @@ type MediaHttpPort interface {
 	// GenerateImage handles image generation requests.
 	GenerateImage(c *gin.Context)
 
+	// EditImage handles image edit and inpainting requests.
+	EditImage(c *gin.Context)
+
+	// CreateImageVariation handles image variation requests.
+	CreateImageVariation(c *gin.Context)
+
+	// UpscaleImage handles image upscale requests.
+	UpscaleImage(c *gin.Context)
+
 	// GenerateVideo handles video generation requests.
 	GenerateVideo(c *gin.Context)
 
@@ -125,6 +261,27 @@ type MediaHttpPort interface {
 
 	// CancelTask handles task cancellation requests.
 	CancelTask(c *gin.Context)
+
+	// ListAssets handles asset listing requests.
+	ListAssets(c *gin.Context)
+
+	// GetAsset handles asset retrieval requests.
+	GetAsset(c *gin.Context)
+
+	// UpdateAsset handles asset metadata updates.
+	UpdateAsset(c *gin.Context)
+
+	// ShareAsset handles sharing an asset with a team.
+	ShareAsset(c *gin.Context)
+
+	// UnshareAsset handles removing an asset from its team.
+	UnshareAsset(c *gin.Context)
+
+	// DeleteAsset handles moving an asset to the trash.
+	DeleteAsset(c *gin.Context)
+
+	// RestoreAsset handles restoring an asset from the trash.
+	RestoreAsset(c *gin.Context)
 }
 
 // MediaAdminHttpPort defines media admin HTTP handlers.
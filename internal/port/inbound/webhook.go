@@ -0,0 +1,123 @@
+package inbound
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+// --- Request/Response Types ---
+
+// WebhookEndpointCreateInput represents a request to add a webhook endpoint.
+type WebhookEndpointCreateInput struct {
+	URL         string   `json:"url" binding:"required,url,max=2048"`
+	Description string   `json:"description" binding:"max=500"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1"`
+}
+
+// WebhookEndpointUpdateInput represents endpoint changes. Nil fields are
+// left unchanged. Setting Active re-enables an automatically disabled
+// endpoint.
+type WebhookEndpointUpdateInput struct {
+	URL         *string   `json:"url,omitempty" binding:"omitempty,url,max=2048"`
+	Description *string   `json:"description,omitempty" binding:"omitempty,max=500"`
+	EventTypes  *[]string `json:"event_types,omitempty"`
+	Active      *bool     `json:"active,omitempty"`
+}
+
+// WebhookEndpointOutput represents an endpoint. Secret is only set when
+// the endpoint is created or its secret rotated.
+type WebhookEndpointOutput struct {
+	*model.WebhookEndpoint
+	Secret string `json:"secret,omitempty"`
+}
+
+// WebhookDeliveryListOutput represents a page of deliveries.
+type WebhookDeliveryListOutput struct {
+	Deliveries []*model.WebhookDelivery `json:"deliveries"`
+	Total      int64                    `json:"total"`
+	Limit      int                      `json:"limit"`
+	Offset     int                      `json:"offset"`
+}
+
+// --- Domain Interface ---
+
+// WebhookDomain defines user webhook endpoints and event delivery.
+type WebhookDomain interface {
+	// CreateEndpoint adds an endpoint and returns its signing secret.
+	CreateEndpoint(ctx context.Context, userID uuid.UUID, input *WebhookEndpointCreateInput) (*WebhookEndpointOutput, error)
+
+	// ListEndpoints lists the user's endpoints.
+	ListEndpoints(ctx context.Context, userID uuid.UUID) ([]*model.WebhookEndpoint, error)
+
+	// GetEndpoint returns one of the user's endpoints.
+	GetEndpoint(ctx context.Context, userID, endpointID uuid.UUID) (*model.WebhookEndpoint, error)
+
+	// UpdateEndpoint changes an endpoint's URL, events or active state.
+	UpdateEndpoint(ctx context.Context, userID, endpointID uuid.UUID, input *WebhookEndpointUpdateInput) (*model.WebhookEndpoint, error)
+
+	// DeleteEndpoint deletes an endpoint and its delivery log.
+	DeleteEndpoint(ctx context.Context, userID, endpointID uuid.UUID) error
+
+	// RotateSecret replaces an endpoint's signing secret.
+	RotateSecret(ctx context.Context, userID, endpointID uuid.UUID) (*WebhookEndpointOutput, error)
+
+	// ListDeliveries lists an endpoint's delivery log.
+	ListDeliveries(ctx context.Context, userID, endpointID uuid.UUID, limit, offset int) (*WebhookDeliveryListOutput, error)
+
+	// GetDelivery returns a delivery with its attempts.
+	GetDelivery(ctx context.Context, userID, endpointID, deliveryID uuid.UUID) (*model.WebhookDelivery, error)
+
+	// Redeliver sends a delivery's event again as a new delivery.
+	Redeliver(ctx context.Context, userID, endpointID, deliveryID uuid.UUID) (*model.WebhookDelivery, error)
+
+	// --- Event intake ---
+
+	// Dispatch queues a delivery of the event to each of the owner's
+	// active endpoints subscribed to its type.
+	Dispatch(ctx context.Context, event *model.AccountEvent) error
+
+	// HandleTaskEvent dispatches task.completed and task.failed events when
+	// a task finishes.
+	HandleTaskEvent(ctx context.Context, event *model.TaskEvent) error
+
+	// --- Delivery ---
+
+	// Deliver makes one attempt at a delivery.
+	Deliver(ctx context.Context, deliveryID uuid.UUID) error
+}
+
+// --- HTTP Port Interfaces ---
+
+// WebhookEndpointHttpPort defines webhook endpoint HTTP handlers.
+type WebhookEndpointHttpPort interface {
+	// CreateEndpoint handles adding an endpoint.
+	CreateEndpoint(c *gin.Context)
+
+	// ListEndpoints handles listing endpoints.
+	ListEndpoints(c *gin.Context)
+
+	// GetEndpoint handles endpoint retrieval.
+	GetEndpoint(c *gin.Context)
+
+	// UpdateEndpoint handles endpoint changes.
+	UpdateEndpoint(c *gin.Context)
+
+	// DeleteEndpoint handles endpoint deletion.
+	DeleteEndpoint(c *gin.Context)
+
+	// RotateSecret handles signing secret rotation.
+	RotateSecret(c *gin.Context)
+
+	// ListDeliveries handles listing an endpoint's delivery log.
+	ListDeliveries(c *gin.Context)
+
+	// GetDelivery handles delivery retrieval.
+	GetDelivery(c *gin.Context)
+
+	// Redeliver handles manual redelivery.
+	Redeliver(c *gin.Context)
+}
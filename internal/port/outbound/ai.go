@@ -137,6 +137,46 @@ type AIModelGroupDatabasePort interface {
 	Delete(ctx context.Context, id string) error
 }
 
+// ===== Model Alias Database Port =====
+
+// AIModelAliasDatabasePort defines model alias persistence operations.
+type AIModelAliasDatabasePort interface {
+	// Create creates a new alias.
+	Create(ctx context.Context, alias *model.AIModelAlias) error
+
+	// FindByID finds an alias by ID.
+	FindByID(ctx context.Context, id string) (*model.AIModelAlias, error)
+
+	// FindAll finds all aliases.
+	FindAll(ctx context.Context) ([]*model.AIModelAlias, error)
+
+	// FindEnabled finds all enabled aliases.
+	FindEnabled(ctx context.Context) ([]*model.AIModelAlias, error)
+
+	// Update updates an alias.
+	Update(ctx context.Context, alias *model.AIModelAlias) error
+
+	// Delete deletes an alias.
+	Delete(ctx context.Context, id string) error
+}
+
+// ===== Request Log Database Port =====
+
+// AIRequestLogDatabasePort defines request audit log persistence operations.
+type AIRequestLogDatabasePort interface {
+	// Create creates a new request log entry.
+	Create(ctx context.Context, log *model.AIRequestLog) error
+
+	// FindByID finds a request log by ID.
+	FindByID(ctx context.Context, id uuid.UUID) (*model.AIRequestLog, error)
+
+	// Find finds request logs matching the filter.
+	Find(ctx context.Context, filter *model.AIRequestLogFilter) ([]*model.AIRequestLog, int64, error)
+
+	// DeleteExpired deletes logs that expired before the given time.
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
 // ===== Cache Ports =====
 
 // AIProviderHealthCachePort defines provider health status caching.
@@ -224,10 +264,21 @@ type AICryptoPort interface {
 	Decrypt(ciphertext string) (string, error)
 }
 
+// ===== Image Fetch Port =====
+
+// AIImageFetcherPort downloads remote images referenced by chat messages.
+// Implementations must refuse to connect to private or internal addresses.
+type AIImageFetcherPort interface {
+	// Fetch downloads the image at url, failing if it exceeds maxBytes.
+	// It returns the raw bytes and the Content-Type reported by the server.
+	Fetch(ctx context.Context, url string, maxBytes int64) ([]byte, string, error)
+}
+
 // ===== Usage Recording Port =====
 
 // AIUsageRecorderPort defines usage recording for billing integration.
 type AIUsageRecorderPort interface {
 	// RecordUsage records AI usage for billing.
-	RecordUsage(ctx context.Context, userID uuid.UUID, modelID string, promptTokens, completionTokens int, cost float64) error
+	// Usage carries the prompt cache split alongside the token totals.
+	RecordUsage(ctx context.Context, userID uuid.UUID, modelID string, usage *model.AIUsage, cost float64) error
 }
@@ -57,6 +57,9 @@ type UsageRecordDatabasePort interface {
 
 	// GetDailyRequests gets request count for a day.
 	GetDailyRequests(ctx context.Context, userID uuid.UUID, date time.Time) (int, error)
+
+	// GetVideoSeconds gets seconds of video generated since start.
+	GetVideoSeconds(ctx context.Context, userID uuid.UUID, start time.Time) (int64, error)
 }
 
 // QuotaCachePort defines quota caching operations (Redis).
@@ -41,6 +41,10 @@ type GitRepoDatabasePort interface {
 	// UpdateSize updates repository size statistics.
 	UpdateSize(ctx context.Context, id uuid.UUID, sizeBytes, lfsSizeBytes int64) error
 
+	// GetOwnerStorage sums the git and LFS sizes of the repositories a user
+	// owns.
+	GetOwnerStorage(ctx context.Context, ownerID uuid.UUID) (sizeBytes, lfsSizeBytes int64, err error)
+
 	// IncrementStars increments the star count.
 	IncrementStars(ctx context.Context, id uuid.UUID, delta int) error
 
@@ -96,6 +100,10 @@ type GitPullRequestDatabasePort interface {
 	// FindByRepo lists pull requests for a repository.
 	FindByRepo(ctx context.Context, repoID uuid.UUID, status *model.GitPRStatus, limit, offset int) ([]*model.GitPullRequest, int64, error)
 
+	// FindOpenBySourceBranch lists the open pull requests, in any
+	// repository, whose source is a branch of the given repository.
+	FindOpenBySourceBranch(ctx context.Context, sourceRepoID uuid.UUID, branch string) ([]*model.GitPullRequest, error)
+
 	// Update updates a pull request.
 	Update(ctx context.Context, pr *model.GitPullRequest) error
 
@@ -103,6 +111,63 @@ type GitPullRequestDatabasePort interface {
 	GetNextNumber(ctx context.Context, repoID uuid.UUID) (int, error)
 }
 
+// ===== Pull Request Review Database Port =====
+
+// GitPRReviewDatabasePort defines pull request review, comment and review
+// request persistence operations.
+type GitPRReviewDatabasePort interface {
+	// CreateReview creates a review together with its inline comments.
+	CreateReview(ctx context.Context, review *model.GitPRReview, comments []*model.GitPRComment) error
+
+	// FindReviews lists the reviews of a pull request, oldest first.
+	FindReviews(ctx context.Context, prID uuid.UUID) ([]*model.GitPRReview, error)
+
+	// CreateComment creates a comment.
+	CreateComment(ctx context.Context, comment *model.GitPRComment) error
+
+	// FindCommentByID finds a comment by ID.
+	FindCommentByID(ctx context.Context, id uuid.UUID) (*model.GitPRComment, error)
+
+	// FindComments lists the comments of a pull request, oldest first.
+	FindComments(ctx context.Context, prID uuid.UUID) ([]*model.GitPRComment, error)
+
+	// UpdateComment updates a comment.
+	UpdateComment(ctx context.Context, comment *model.GitPRComment) error
+
+	// DeleteComment deletes a comment.
+	DeleteComment(ctx context.Context, id uuid.UUID) error
+
+	// AddReviewRequest requests a review (idempotent).
+	AddReviewRequest(ctx context.Context, request *model.GitPRReviewRequest) error
+
+	// RemoveReviewRequest removes a pending review request.
+	RemoveReviewRequest(ctx context.Context, prID, reviewerID uuid.UUID) error
+
+	// FindReviewRequests lists the pending review requests of a pull request.
+	FindReviewRequests(ctx context.Context, prID uuid.UUID) ([]*model.GitPRReviewRequest, error)
+}
+
+// ===== Branch Protection Database Port =====
+
+// GitBranchProtectionDatabasePort defines branch protection rule persistence
+// operations.
+type GitBranchProtectionDatabasePort interface {
+	// Create creates a rule.
+	Create(ctx context.Context, rule *model.GitBranchProtection) error
+
+	// FindByID finds a rule by ID.
+	FindByID(ctx context.Context, id uuid.UUID) (*model.GitBranchProtection, error)
+
+	// FindByRepo lists the rules of a repository ordered by pattern.
+	FindByRepo(ctx context.Context, repoID uuid.UUID) ([]*model.GitBranchProtection, error)
+
+	// Update updates a rule.
+	Update(ctx context.Context, rule *model.GitBranchProtection) error
+
+	// Delete deletes a rule.
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
 // ===== LFS Object Database Port =====
 
 // GitLFSObjectDatabasePort defines LFS object persistence operations.
@@ -116,6 +181,9 @@ type GitLFSObjectDatabasePort interface {
 	// Link links an LFS object to a repository.
 	Link(ctx context.Context, repoID uuid.UUID, oid string) error
 
+	// IsLinked checks if an LFS object is linked to a repository.
+	IsLinked(ctx context.Context, repoID uuid.UUID, oid string) (bool, error)
+
 	// Unlink unlinks an LFS object from a repository.
 	Unlink(ctx context.Context, repoID uuid.UUID, oid string) error
 
@@ -161,6 +229,9 @@ type GitStoragePort interface {
 
 	// GetRepositorySize calculates the size of a repository's storage.
 	GetRepositorySize(ctx context.Context, storagePath string) (int64, error)
+
+	// CopyRepository copies all storage of a repository to another path.
+	CopyRepository(ctx context.Context, srcPath, dstPath string) error
 }
 
 // ===== LFS Storage Port =====
@@ -179,6 +250,9 @@ type GitLFSStoragePort interface {
 	// Delete deletes an LFS object.
 	Delete(ctx context.Context, oid string) error
 
+	// Copy copies the object stored under src to dst.
+	Copy(ctx context.Context, src, dst string) error
+
 	// GenerateUploadURL generates a presigned upload URL.
 	GenerateUploadURL(ctx context.Context, oid string, size int64, expiry time.Duration) (*GitPresignedURL, error)
 
@@ -203,3 +277,12 @@ type GitAccessControlPort interface {
 	// CheckPublicAccess checks if a repository is publicly accessible.
 	CheckPublicAccess(ctx context.Context, repoID uuid.UUID) (bool, error)
 }
+
+// ===== Team Access Port =====
+
+// GitTeamAccessPort resolves team roles for repositories owned by a team.
+type GitTeamAccessPort interface {
+	// GetTeamRole returns the user's role in a team, or an empty role if
+	// the user is not a member.
+	GetTeamRole(ctx context.Context, teamID, userID uuid.UUID) (model.TeamRole, error)
+}
@@ -2,6 +2,8 @@ package outbound
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/uniedit/server/internal/model"
@@ -63,8 +65,17 @@ type MediaTaskDatabasePort interface {
 	// FindByOwner finds tasks by owner.
 	FindByOwner(ctx context.Context, ownerID uuid.UUID, limit, offset int) ([]*model.MediaTask, error)
 
-	// FindPending finds pending tasks.
-	FindPending(ctx context.Context, limit int) ([]*model.MediaTask, error)
+	// FindPending finds pending tasks, and running tasks without a
+	// heartbeat for longer than lease whose worker is presumed gone.
+	FindPending(ctx context.Context, limit int, lease time.Duration) ([]*model.MediaTask, error)
+
+	// Claim atomically moves a pending or abandoned running task to
+	// running. It returns false if another worker claimed it first.
+	Claim(ctx context.Context, id uuid.UUID, lease time.Duration) (bool, error)
+
+	// Heartbeat marks a running task as alive. It returns false if the task
+	// is no longer running.
+	Heartbeat(ctx context.Context, id uuid.UUID) (bool, error)
 
 	// Update updates a task.
 	Update(ctx context.Context, task *model.MediaTask) error
@@ -72,6 +83,15 @@ type MediaTaskDatabasePort interface {
 	// UpdateStatus updates task status and progress.
 	UpdateStatus(ctx context.Context, id uuid.UUID, status model.MediaTaskStatus, progress int, output, errMsg string) error
 
+	// TransitionStatus updates task status and progress like UpdateStatus,
+	// but only while the task is in one of the from statuses. It returns
+	// false if the task had moved on, e.g. it was cancelled meanwhile.
+	TransitionStatus(ctx context.Context, id uuid.UUID, from []model.MediaTaskStatus, status model.MediaTaskStatus, progress int, output, errMsg string) (bool, error)
+
+	// RecordExternalTask records the provider job a running task was
+	// submitted to. It returns false if the task is no longer running.
+	RecordExternalTask(ctx context.Context, id uuid.UUID, modelID, externalTaskID string, progress int) (bool, error)
+
 	// Delete deletes a task.
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -81,8 +101,24 @@ type MediaProviderHealthCachePort interface {
 	// GetHealth gets provider health status.
 	GetHealth(ctx context.Context, providerID uuid.UUID) (bool, error)
 
-	// SetHealth sets provider health status.
+	// SetHealth sets provider health status. Marking a provider healthy
+	// also clears its recorded failures.
 	SetHealth(ctx context.Context, providerID uuid.UUID, healthy bool) error
+
+	// RecordFailure counts a failed provider request and returns the
+	// number of failures within the current failure window.
+	RecordFailure(ctx context.Context, providerID uuid.UUID) (int64, error)
+}
+
+// MediaVendorStatusError is returned by vendor adapters when a provider
+// answers with an error status.
+type MediaVendorStatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *MediaVendorStatusError) Error() string {
+	return fmt.Sprintf("unexpected status code %d: %s", e.StatusCode, e.Message)
 }
 
 // MediaVendorAdapterPort defines the interface for media vendor adapters.
@@ -99,6 +135,15 @@ type MediaVendorAdapterPort interface {
 	// GenerateImage generates images from a text prompt.
 	GenerateImage(ctx context.Context, req *model.ImageRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error)
 
+	// EditImage edits an image from a prompt, optionally within a mask.
+	EditImage(ctx context.Context, req *model.ImageEditRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error)
+
+	// CreateImageVariation generates variations of an image.
+	CreateImageVariation(ctx context.Context, req *model.ImageVariationRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error)
+
+	// UpscaleImage increases the resolution of an image.
+	UpscaleImage(ctx context.Context, req *model.ImageUpscaleRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.ImageResponse, error)
+
 	// GenerateVideo generates videos from input.
 	GenerateVideo(ctx context.Context, req *model.VideoRequest, m *model.MediaModel, prov *model.MediaProvider, apiKey string) (*model.VideoResponse, error)
 
@@ -132,3 +177,54 @@ type MediaCryptoPort interface {
 	// Decrypt decrypts a ciphertext string.
 	Decrypt(ciphertext string) (string, error)
 }
+
+// MediaVideoQuotaPort defines video minute accounting against the owner's plan.
+type MediaVideoQuotaPort interface {
+	// GetVideoSecondsRemaining returns the seconds left this period, or -1 if unlimited.
+	GetVideoSecondsRemaining(ctx context.Context, userID uuid.UUID) (int64, error)
+
+	// RecordVideoUsage records seconds of generated video for a task.
+	RecordVideoUsage(ctx context.Context, userID, taskID, providerID uuid.UUID, modelID string, seconds int) error
+}
+
+// MediaAssetDatabasePort defines media asset persistence.
+type MediaAssetDatabasePort interface {
+	// Create creates a new asset.
+	Create(ctx context.Context, asset *model.MediaAsset) error
+
+	// FindByID finds an asset by ID.
+	FindByID(ctx context.Context, id uuid.UUID) (*model.MediaAsset, error)
+
+	// FindByOwnerAndHash finds an owner's asset with the given content hash.
+	FindByOwnerAndHash(ctx context.Context, ownerID uuid.UUID, contentHash string) (*model.MediaAsset, error)
+
+	// Find finds assets matching a filter and returns the total count.
+	Find(ctx context.Context, filter *model.MediaAssetFilter) ([]*model.MediaAsset, int64, error)
+
+	// Update updates an asset.
+	Update(ctx context.Context, asset *model.MediaAsset) error
+
+	// SumSizeByOwner returns the total bytes stored for an owner, including
+	// assets in the trash.
+	SumSizeByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error)
+}
+
+// MediaFetcherPort downloads generated media from provider URLs.
+type MediaFetcherPort interface {
+	// Fetch downloads rawURL, failing if the body exceeds maxBytes.
+	// It returns the body and the declared Content-Type.
+	Fetch(ctx context.Context, rawURL string, maxBytes int64) ([]byte, string, error)
+}
+
+// MediaStorageQuotaPort defines the media storage allowance of the owner's plan.
+type MediaStorageQuotaPort interface {
+	// GetMediaStorageLimit returns the storage limit in bytes, or -1 if unlimited.
+	GetMediaStorageLimit(ctx context.Context, userID uuid.UUID) (int64, error)
+}
+
+// MediaTeamAccessPort resolves team membership for shared assets.
+type MediaTeamAccessPort interface {
+	// GetTeamRole returns the user's role in a team, or an empty role if
+	// they are not a member.
+	GetTeamRole(ctx context.Context, teamID, userID uuid.UUID) (model.TeamRole, error)
+}
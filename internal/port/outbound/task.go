@@ -0,0 +1,20 @@
+package outbound
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+// TaskEventPort delivers task events to subscribers on every replica.
+type TaskEventPort interface {
+	// Publish sends an event to the subscribers of its task.
+	Publish(ctx context.Context, event *model.TaskEvent) error
+
+	// Subscribe returns a task's events until the returned function is
+	// called or ctx is done. Events published after Subscribe returns are
+	// delivered.
+	Subscribe(ctx context.Context, taskID uuid.UUID) (<-chan *model.TaskEvent, func(), error)
+}
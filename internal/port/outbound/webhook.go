@@ -0,0 +1,82 @@
+package outbound
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/model"
+)
+
+// WebhookEndpointDatabasePort defines webhook endpoint persistence.
+type WebhookEndpointDatabasePort interface {
+	// Create creates a new endpoint.
+	Create(ctx context.Context, endpoint *model.WebhookEndpoint) error
+
+	// FindByID finds an endpoint by ID.
+	FindByID(ctx context.Context, id uuid.UUID) (*model.WebhookEndpoint, error)
+
+	// FindByOwner lists an owner's endpoints, newest first.
+	FindByOwner(ctx context.Context, ownerID uuid.UUID) ([]*model.WebhookEndpoint, error)
+
+	// CountByOwner counts an owner's endpoints.
+	CountByOwner(ctx context.Context, ownerID uuid.UUID) (int64, error)
+
+	// Update updates an endpoint.
+	Update(ctx context.Context, endpoint *model.WebhookEndpoint) error
+
+	// Delete deletes an endpoint and its deliveries.
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	// RecordSuccess resets the endpoint's consecutive failure count.
+	RecordSuccess(ctx context.Context, id uuid.UUID) error
+
+	// RecordFailure increments the endpoint's consecutive failure count and
+	// returns the new count.
+	RecordFailure(ctx context.Context, id uuid.UUID) (int, error)
+
+	// Disable deactivates an endpoint, recording why.
+	Disable(ctx context.Context, id uuid.UUID, reason string) error
+}
+
+// WebhookDeliveryDatabasePort defines webhook delivery persistence.
+type WebhookDeliveryDatabasePort interface {
+	// Create creates a new delivery. It returns false, without error, if the
+	// event was already delivered to the endpoint and this is not a
+	// redelivery.
+	Create(ctx context.Context, delivery *model.WebhookDelivery) (bool, error)
+
+	// FindByID finds a delivery by ID.
+	FindByID(ctx context.Context, id uuid.UUID) (*model.WebhookDelivery, error)
+
+	// FindByEndpoint lists an endpoint's deliveries, newest first, and
+	// returns the total count.
+	FindByEndpoint(ctx context.Context, endpointID uuid.UUID, limit, offset int) ([]*model.WebhookDelivery, int64, error)
+
+	// Update updates a delivery.
+	Update(ctx context.Context, delivery *model.WebhookDelivery) error
+}
+
+// WebhookQueuePort schedules delivery attempts. Failed attempts are retried
+// with backoff while the delivery reports a retryable error.
+type WebhookQueuePort interface {
+	// Enqueue schedules the first attempt of a delivery.
+	Enqueue(ctx context.Context, deliveryID uuid.UUID) error
+}
+
+// WebhookSenderPort makes webhook requests.
+type WebhookSenderPort interface {
+	// Send posts body to url with the given headers. It returns the response
+	// status and the start of the response body; a non-2xx status is not an
+	// error.
+	Send(ctx context.Context, url string, headers map[string]string, body []byte) (int, []byte, error)
+}
+
+// WebhookCryptoPort defines encryption for endpoint signing secrets.
+type WebhookCryptoPort interface {
+	// Encrypt encrypts a plaintext string.
+	Encrypt(plaintext string) (string, error)
+
+	// Decrypt decrypts a ciphertext string.
+	Decrypt(ciphertext string) (string, error)
+}
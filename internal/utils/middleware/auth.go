@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
@@ -66,6 +67,50 @@ func Auth(validator JWTValidator, optional bool) gin.HandlerFunc {
 	}
 }
 
+// AdminChecker defines the interface for checking whether a user is an admin.
+type AdminChecker interface {
+	IsAdmin(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// RequireAdmin returns a middleware that only lets admins through.
+// It must run after RequireAuth.
+func RequireAdmin(checker AdminChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := GetUserID(c)
+		if userID == uuid.Nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": gin.H{
+					"code":    "UNAUTHORIZED",
+					"message": "Authorization header required",
+				},
+			})
+			return
+		}
+
+		isAdmin, err := checker.IsAdmin(c.Request.Context(), userID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": gin.H{
+					"code":    "INTERNAL_ERROR",
+					"message": "Failed to check admin status",
+				},
+			})
+			return
+		}
+		if !isAdmin {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": gin.H{
+					"code":    "FORBIDDEN",
+					"message": "Admin access required",
+				},
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // RequireAuth returns a middleware that requires a valid JWT token.
 func RequireAuth(validator JWTValidator) gin.HandlerFunc {
 	return Auth(validator, false)
@@ -1,6 +1,11 @@
 package middleware
 
 import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/uniedit/server/internal/model"
 	"github.com/uniedit/server/internal/port/outbound"
 )
 
@@ -20,5 +25,28 @@ func (v *AuthDomainValidator) ValidateToken(token string) (*outbound.JWTClaims,
 	return v.validateFunc(token)
 }
 
-// Compile-time check
-var _ JWTValidator = (*AuthDomainValidator)(nil)
+// UserDomainAdminChecker wraps user.UserDomain to implement AdminChecker interface.
+type UserDomainAdminChecker struct {
+	getUserFunc func(ctx context.Context, id uuid.UUID) (*model.User, error)
+}
+
+// NewUserDomainAdminChecker creates a new UserDomainAdminChecker.
+// The getUserFunc should be user.UserDomain.GetUser.
+func NewUserDomainAdminChecker(getUserFunc func(ctx context.Context, id uuid.UUID) (*model.User, error)) *UserDomainAdminChecker {
+	return &UserDomainAdminChecker{getUserFunc: getUserFunc}
+}
+
+// IsAdmin implements AdminChecker interface.
+func (c *UserDomainAdminChecker) IsAdmin(ctx context.Context, userID uuid.UUID) (bool, error) {
+	u, err := c.getUserFunc(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return u != nil && u.IsAdmin, nil
+}
+
+// Compile-time checks
+var (
+	_ JWTValidator = (*AuthDomainValidator)(nil)
+	_ AdminChecker = (*UserDomainAdminChecker)(nil)
+)
@@ -2,13 +2,17 @@ package middleware
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/uniedit/server/internal/model"
 	"github.com/uniedit/server/internal/utils/logger"
 )
 
@@ -279,3 +283,53 @@ func TestDefaultCORSConfig(t *testing.T) {
 	assert.Contains(t, cfg.AllowHeaders, "Content-Type")
 	assert.False(t, cfg.AllowCredentials)
 }
+
+func TestRequireAdmin(t *testing.T) {
+	adminID := uuid.New()
+	memberID := uuid.New()
+	checker := NewUserDomainAdminChecker(func(ctx context.Context, id uuid.UUID) (*model.User, error) {
+		switch id {
+		case adminID:
+			return &model.User{ID: id, IsAdmin: true}, nil
+		case memberID:
+			return &model.User{ID: id}, nil
+		}
+		return nil, errors.New("user not found")
+	})
+
+	serve := func(userID uuid.UUID) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			if userID != uuid.Nil {
+				c.Set(UserIDKey, userID)
+			}
+			c.Next()
+		})
+		router.Use(RequireAdmin(checker))
+		router.GET("/admin", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/admin", nil))
+		return w
+	}
+
+	t.Run("allows admins", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(adminID).Code)
+	})
+
+	t.Run("forbids other users", func(t *testing.T) {
+		w := serve(memberID)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "FORBIDDEN")
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(uuid.Nil).Code)
+	})
+
+	t.Run("fails closed when the lookup fails", func(t *testing.T) {
+		assert.Equal(t, http.StatusInternalServerError, serve(uuid.New()).Code)
+	})
+}
@@ -0,0 +1,28 @@
+-- AI request audit log (opt-in, bodies encrypted at rest)
+CREATE TABLE IF NOT EXISTS ai_request_logs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    request_id VARCHAR(100),
+    user_id UUID NOT NULL,
+    api_key_id UUID,
+    model_id VARCHAR(100),
+    provider_id UUID,
+    stream BOOLEAN NOT NULL DEFAULT false,
+    encrypted_request TEXT NOT NULL,
+    encrypted_response TEXT,
+    success BOOLEAN NOT NULL DEFAULT true,
+    error TEXT,
+    prompt_tokens INT NOT NULL DEFAULT 0,
+    completion_tokens INT NOT NULL DEFAULT 0,
+    cost_usd DECIMAL(12,6) NOT NULL DEFAULT 0,
+    latency_ms BIGINT NOT NULL DEFAULT 0,
+    replay_of UUID REFERENCES ai_request_logs(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_ai_request_logs_request_id ON ai_request_logs(request_id);
+CREATE INDEX idx_ai_request_logs_user_created ON ai_request_logs(user_id, created_at DESC);
+CREATE INDEX idx_ai_request_logs_api_key ON ai_request_logs(api_key_id) WHERE api_key_id IS NOT NULL;
+CREATE INDEX idx_ai_request_logs_model_created ON ai_request_logs(model_id, created_at DESC);
+CREATE INDEX idx_ai_request_logs_created ON ai_request_logs(created_at DESC);
+CREATE INDEX idx_ai_request_logs_expires ON ai_request_logs(expires_at);
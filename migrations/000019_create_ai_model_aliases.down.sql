@@ -0,0 +1 @@
+DROP TABLE IF EXISTS ai_model_aliases;
@@ -0,0 +1,20 @@
+-- AI model aliases (stable names pointing at a model or group)
+-- Targets are validated by the AI domain; empty string means unset.
+CREATE TABLE IF NOT EXISTS ai_model_aliases (
+    id VARCHAR(100) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    target_model_id VARCHAR(100) NOT NULL DEFAULT '',
+    target_group_id VARCHAR(100) NOT NULL DEFAULT '',
+    next_target_model_id VARCHAR(100) NOT NULL DEFAULT '',
+    next_target_group_id VARCHAR(100) NOT NULL DEFAULT '',
+    cutover_at TIMESTAMPTZ,
+    deprecated BOOLEAN NOT NULL DEFAULT false,
+    deprecation_message TEXT NOT NULL DEFAULT '',
+    sunset_at TIMESTAMPTZ,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ai_model_aliases_enabled ON ai_model_aliases(enabled);
+CREATE INDEX idx_ai_model_aliases_target_model ON ai_model_aliases(target_model_id);
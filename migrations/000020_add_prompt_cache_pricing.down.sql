@@ -0,0 +1,13 @@
+-- Remove prompt cache pricing and cache token counts
+
+ALTER TABLE usage_records
+DROP COLUMN IF EXISTS cache_write_tokens;
+
+ALTER TABLE usage_records
+DROP COLUMN IF EXISTS cache_read_tokens;
+
+ALTER TABLE ai_models
+DROP COLUMN IF EXISTS cache_write_cost_per_1k;
+
+ALTER TABLE ai_models
+DROP COLUMN IF EXISTS cached_input_cost_per_1k;
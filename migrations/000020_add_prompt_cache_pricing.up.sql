@@ -0,0 +1,25 @@
+-- Add prompt cache pricing to ai_models and cache token counts to usage_records
+
+-- Per-1K token rates for cache reads and writes (0 = bill at input_cost_per_1k)
+ALTER TABLE ai_models
+ADD COLUMN IF NOT EXISTS cached_input_cost_per_1k DECIMAL(10, 6) NOT NULL DEFAULT 0;
+
+ALTER TABLE ai_models
+ADD COLUMN IF NOT EXISTS cache_write_cost_per_1k DECIMAL(10, 6) NOT NULL DEFAULT 0;
+
+-- Cached share of input_tokens
+ALTER TABLE usage_records
+ADD COLUMN IF NOT EXISTS cache_read_tokens INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE usage_records
+ADD COLUMN IF NOT EXISTS cache_write_tokens INTEGER NOT NULL DEFAULT 0;
+
+-- Seed published cache rates for the default models
+UPDATE ai_models SET cached_input_cost_per_1k = input_cost_per_1k / 2
+WHERE id IN ('gpt-4o', 'gpt-4o-mini') AND cached_input_cost_per_1k = 0;
+
+UPDATE ai_models
+SET cached_input_cost_per_1k = input_cost_per_1k / 10,
+    cache_write_cost_per_1k = input_cost_per_1k * 1.25
+WHERE id IN ('claude-3-5-sonnet-20241022', 'claude-3-5-haiku-20241022', 'claude-3-opus-20240229')
+  AND cached_input_cost_per_1k = 0;
@@ -0,0 +1,3 @@
+-- Remove reasoning token pricing
+ALTER TABLE ai_models
+DROP COLUMN IF EXISTS reasoning_cost_per_1k;
@@ -0,0 +1,3 @@
+-- Add reasoning token pricing to ai_models (0 = bill at output_cost_per_1k)
+ALTER TABLE ai_models
+ADD COLUMN IF NOT EXISTS reasoning_cost_per_1k DECIMAL(10, 6) NOT NULL DEFAULT 0;
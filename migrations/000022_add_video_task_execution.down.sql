@@ -0,0 +1,9 @@
+-- Remove video task execution tracking
+DROP INDEX IF EXISTS idx_media_tasks_pending;
+
+ALTER TABLE usage_records
+DROP COLUMN IF EXISTS video_seconds;
+
+ALTER TABLE media_tasks
+DROP COLUMN IF EXISTS external_task_id,
+DROP COLUMN IF EXISTS model_id;
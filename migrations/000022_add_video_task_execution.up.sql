@@ -0,0 +1,10 @@
+-- Track provider submission on media tasks so workers can resume polling
+ALTER TABLE media_tasks
+ADD COLUMN IF NOT EXISTS model_id VARCHAR(255),
+ADD COLUMN IF NOT EXISTS external_task_id VARCHAR(255);
+
+-- Generated video length, billed against plans.monthly_video_minutes
+ALTER TABLE usage_records
+ADD COLUMN IF NOT EXISTS video_seconds INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_media_tasks_pending ON media_tasks(created_at) WHERE status = 'pending';
@@ -0,0 +1,8 @@
+-- Remove task leases
+DROP INDEX IF EXISTS idx_tasks_lease_owner;
+DROP INDEX IF EXISTS idx_tasks_claim;
+
+ALTER TABLE tasks
+DROP COLUMN IF EXISTS heartbeat_at,
+DROP COLUMN IF EXISTS lease_expires_at,
+DROP COLUMN IF EXISTS lease_owner;
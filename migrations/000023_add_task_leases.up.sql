@@ -0,0 +1,10 @@
+-- Lease-based ownership for generic async tasks so several server replicas
+-- can share one queue
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS lease_owner VARCHAR(255),
+ADD COLUMN IF NOT EXISTS lease_expires_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS heartbeat_at TIMESTAMPTZ;
+
+-- Claim scans: pending tasks and running tasks with expired leases, per type
+CREATE INDEX IF NOT EXISTS idx_tasks_claim ON tasks(type, status, created_at) WHERE status IN ('pending', 'running');
+CREATE INDEX IF NOT EXISTS idx_tasks_lease_owner ON tasks(lease_owner) WHERE status = 'running';
@@ -0,0 +1,7 @@
+-- Remove task retry bookkeeping
+DROP INDEX IF EXISTS idx_tasks_dead;
+
+ALTER TABLE tasks
+DROP COLUMN IF EXISTS attempt_history,
+DROP COLUMN IF EXISTS next_run_at,
+DROP COLUMN IF EXISTS attempts;
@@ -0,0 +1,8 @@
+-- Retry bookkeeping for generic async tasks: attempt counts, scheduled
+-- retries and per-attempt history. Exhausted tasks end in status 'dead'.
+ALTER TABLE tasks
+ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS next_run_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS attempt_history JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_tasks_dead ON tasks(updated_at DESC) WHERE status = 'dead';
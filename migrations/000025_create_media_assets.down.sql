@@ -0,0 +1,5 @@
+-- Drop generated media assets
+ALTER TABLE plans
+DROP COLUMN IF EXISTS media_storage_mb;
+
+DROP TABLE IF EXISTS media_assets;
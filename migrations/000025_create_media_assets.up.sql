@@ -0,0 +1,26 @@
+-- Generated images and videos copied into our object storage, deduplicated
+-- per owner by content hash
+CREATE TABLE IF NOT EXISTS media_assets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id UUID NOT NULL,
+    task_id UUID REFERENCES media_tasks(id) ON DELETE SET NULL,
+    type VARCHAR(20) NOT NULL,
+    storage_key VARCHAR(512) NOT NULL,
+    content_hash VARCHAR(64) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    width INT NOT NULL DEFAULT 0,
+    height INT NOT NULL DEFAULT 0,
+    duration_seconds INT NOT NULL DEFAULT 0,
+    provider_id UUID,
+    model_id VARCHAR(255),
+    prompt TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_media_assets_owner_hash ON media_assets(owner_id, content_hash);
+CREATE INDEX IF NOT EXISTS idx_media_assets_owner_created ON media_assets(owner_id, created_at DESC);
+
+-- Media storage quota per plan (-1 = unlimited)
+ALTER TABLE plans
+ADD COLUMN IF NOT EXISTS media_storage_mb BIGINT NOT NULL DEFAULT -1;
@@ -0,0 +1,12 @@
+-- Remove media asset library columns
+DROP INDEX IF EXISTS idx_media_assets_deleted_at;
+DROP INDEX IF EXISTS idx_media_assets_tags;
+DROP INDEX IF EXISTS idx_media_assets_team_created;
+
+ALTER TABLE media_assets
+DROP COLUMN IF EXISTS deleted_at,
+DROP COLUMN IF EXISTS updated_at,
+DROP COLUMN IF EXISTS tags,
+DROP COLUMN IF EXISTS folder,
+DROP COLUMN IF EXISTS name,
+DROP COLUMN IF EXISTS team_id;
@@ -0,0 +1,12 @@
+-- Media asset library: names, folders, tags, team sharing and soft delete
+ALTER TABLE media_assets
+ADD COLUMN IF NOT EXISTS team_id UUID REFERENCES teams(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS name VARCHAR(255) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS folder VARCHAR(1024) NOT NULL DEFAULT '',
+ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}',
+ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_media_assets_team_created ON media_assets(team_id, created_at DESC) WHERE team_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_media_assets_tags ON media_assets USING GIN(tags);
+CREATE INDEX IF NOT EXISTS idx_media_assets_deleted_at ON media_assets(deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Remove media provider options
+ALTER TABLE media_providers
+DROP COLUMN IF EXISTS options;
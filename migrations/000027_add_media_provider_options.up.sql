@@ -0,0 +1,4 @@
+-- Adapter-specific media provider settings, e.g. endpoint paths and field
+-- mappings for generic async video APIs
+ALTER TABLE media_providers
+ADD COLUMN IF NOT EXISTS options JSONB NOT NULL DEFAULT '{}';
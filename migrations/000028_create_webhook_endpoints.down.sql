@@ -0,0 +1,3 @@
+-- Drop webhook endpoints and deliveries
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhook_endpoints;
@@ -0,0 +1,4 @@
+-- Remove media model pricing
+ALTER TABLE media_models
+DROP COLUMN IF EXISTS cost_per_second,
+DROP COLUMN IF EXISTS cost_per_image;
@@ -0,0 +1,4 @@
+-- Per-unit pricing used to rank media models during routing
+ALTER TABLE media_models
+ADD COLUMN IF NOT EXISTS cost_per_image DECIMAL(10, 6) NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS cost_per_second DECIMAL(10, 6) NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+-- Remove pull request merge commits
+ALTER TABLE pull_requests
+DROP COLUMN IF EXISTS merge_commit_sha;
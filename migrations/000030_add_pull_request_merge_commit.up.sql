@@ -0,0 +1,3 @@
+-- Commit a merged pull request produced on its target branch
+ALTER TABLE pull_requests
+ADD COLUMN IF NOT EXISTS merge_commit_sha VARCHAR(40);
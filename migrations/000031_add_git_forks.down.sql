@@ -0,0 +1,9 @@
+-- Remove fork support
+ALTER TABLE pull_requests
+DROP COLUMN IF EXISTS source_repo_id;
+
+DROP INDEX IF EXISTS idx_git_repos_forked_from;
+DROP INDEX IF EXISTS idx_git_repos_team;
+
+ALTER TABLE git_repos
+DROP COLUMN IF EXISTS team_id;
@@ -0,0 +1,12 @@
+-- Repositories forked into a team grant access to its members
+ALTER TABLE git_repos
+ADD COLUMN IF NOT EXISTS team_id UUID REFERENCES teams(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_git_repos_team ON git_repos(team_id);
+CREATE INDEX IF NOT EXISTS idx_git_repos_forked_from ON git_repos(forked_from);
+
+-- Pull requests opened from a fork record the repository their source branch
+-- lives in. No foreign key: a pull request from a deleted fork must not turn
+-- into one from the upstream branch of the same name.
+ALTER TABLE pull_requests
+ADD COLUMN IF NOT EXISTS source_repo_id UUID;
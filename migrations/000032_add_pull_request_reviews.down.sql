@@ -0,0 +1,8 @@
+-- Remove pull request reviews
+DROP TABLE IF EXISTS pull_request_review_requests;
+DROP TRIGGER IF EXISTS update_pull_request_comments_updated_at ON pull_request_comments;
+DROP TABLE IF EXISTS pull_request_comments;
+DROP TABLE IF EXISTS pull_request_reviews;
+
+ALTER TABLE git_repos
+DROP COLUMN IF EXISTS required_approvals;
@@ -0,0 +1,47 @@
+-- Approvals a pull request needs before it can be merged (0 disables the check)
+ALTER TABLE git_repos
+ADD COLUMN IF NOT EXISTS required_approvals INT NOT NULL DEFAULT 0;
+
+-- Pull request reviews
+CREATE TABLE IF NOT EXISTS pull_request_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pr_id UUID NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    reviewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    state VARCHAR(50) NOT NULL,  -- approved, changes_requested, commented
+    body TEXT,
+    commit_sha VARCHAR(40) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_pull_request_reviews_pr ON pull_request_reviews(pr_id);
+
+-- Pull request comments; inline comments are anchored to a file and line at a commit
+CREATE TABLE IF NOT EXISTS pull_request_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    pr_id UUID NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    review_id UUID REFERENCES pull_request_reviews(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    path TEXT,
+    line INT,
+    commit_sha VARCHAR(40),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_pull_request_comments_pr ON pull_request_comments(pr_id);
+
+CREATE TRIGGER update_pull_request_comments_updated_at
+    BEFORE UPDATE ON pull_request_comments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Pending review requests
+CREATE TABLE IF NOT EXISTS pull_request_review_requests (
+    pr_id UUID NOT NULL REFERENCES pull_requests(id) ON DELETE CASCADE,
+    reviewer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    requested_by UUID NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (pr_id, reviewer_id)
+);
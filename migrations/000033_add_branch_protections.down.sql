@@ -0,0 +1,3 @@
+-- Remove branch protection rules
+DROP TRIGGER IF EXISTS update_git_branch_protections_updated_at ON git_branch_protections;
+DROP TABLE IF EXISTS git_branch_protections;
@@ -0,0 +1,20 @@
+-- Branch protection rules; a rule applies to every branch its pattern matches
+CREATE TABLE IF NOT EXISTS git_branch_protections (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    repo_id UUID NOT NULL REFERENCES git_repos(id) ON DELETE CASCADE,
+    pattern VARCHAR(255) NOT NULL,  -- branch name or glob, e.g. main or release/*
+    block_force_push BOOLEAN NOT NULL DEFAULT FALSE,
+    block_deletion BOOLEAN NOT NULL DEFAULT FALSE,
+    require_pull_request BOOLEAN NOT NULL DEFAULT FALSE,
+    required_approvals INT NOT NULL DEFAULT 0,
+    restrict_push_to_admins BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (repo_id, pattern)
+);
+
+CREATE TRIGGER update_git_branch_protections_updated_at
+    BEFORE UPDATE ON git_branch_protections
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,2 @@
+-- Remove the running media task index
+DROP INDEX IF EXISTS idx_media_tasks_running;
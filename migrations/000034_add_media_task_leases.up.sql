@@ -0,0 +1,2 @@
+-- Find running media tasks whose worker stopped sending heartbeats
+CREATE INDEX IF NOT EXISTS idx_media_tasks_running ON media_tasks(updated_at) WHERE status = 'running';